@@ -339,7 +339,7 @@ func seedPurchaseOrders(db *gorm.DB) {
 			OrderDate:        time.Now().AddDate(0, 0, -rand.Intn(30)),
 			ExpectedDelivery: time.Now().AddDate(0, 0, rand.Intn(7)),
 			Status:           status,
-			TotalAmount:      float64(rand.Intn(5000000) + 1000000),
+			TotalAmount:      models.Rupiah(int64(rand.Intn(5000000) + 1000000)),
 			CreatedBy:        kepalaSPPG.ID,
 		}
 
@@ -356,14 +356,14 @@ func seedPurchaseOrders(db *gorm.DB) {
 		for j := 0; j < numItems; j++ {
 			ing := ingredients[rand.Intn(len(ingredients))]
 			qty := float64(rand.Intn(50) + 10)
-			price := float64(rand.Intn(50000) + 10000)
+			price := models.Rupiah(int64(rand.Intn(50000) + 10000))
 
 			poItem := models.PurchaseOrderItem{
 				POID:         po.ID,
 				IngredientID: ing.ID,
 				Quantity:     qty,
 				UnitPrice:    price,
-				Subtotal:     qty * price,
+				Subtotal:     price.Mul(qty),
 			}
 			db.FirstOrCreate(&poItem, models.PurchaseOrderItem{POID: po.ID, IngredientID: ing.ID})
 		}
@@ -616,11 +616,11 @@ func seedKitchenAssets(db *gorm.DB) {
 	log.Println("Seeding kitchen assets...")
 
 	assets := []models.KitchenAsset{
-		{AssetCode: "AST-001", Name: "Kompor Gas Industri", Category: "Peralatan Masak", PurchaseDate: time.Now().AddDate(-2, 0, 0), PurchasePrice: 5000000, CurrentValue: 3500000, DepreciationRate: 15, Condition: "good", Location: "Dapur Utama"},
-		{AssetCode: "AST-002", Name: "Oven Gas Besar", Category: "Peralatan Masak", PurchaseDate: time.Now().AddDate(-3, 0, 0), PurchasePrice: 8000000, CurrentValue: 4000000, DepreciationRate: 15, Condition: "good", Location: "Dapur Utama"},
-		{AssetCode: "AST-003", Name: "Freezer Industrial", Category: "Pendingin", PurchaseDate: time.Now().AddDate(-1, 0, 0), PurchasePrice: 12000000, CurrentValue: 10000000, DepreciationRate: 10, Condition: "good", Location: "Gudang"},
-		{AssetCode: "AST-004", Name: "Rice Cooker Kapasitas Besar", Category: "Peralatan Masak", PurchaseDate: time.Now().AddDate(-1, 0, 0), PurchasePrice: 3000000, CurrentValue: 2500000, DepreciationRate: 20, Condition: "good", Location: "Dapur Utama"},
-		{AssetCode: "AST-005", Name: "Timbangan Digital", Category: "Alat Ukur", PurchaseDate: time.Now().AddDate(-4, 0, 0), PurchasePrice: 1500000, CurrentValue: 500000, DepreciationRate: 25, Condition: "fair", Location: "Gudang"},
+		{AssetCode: "AST-001", Name: "Kompor Gas Industri", Category: "Peralatan Masak", PurchaseDate: time.Now().AddDate(-2, 0, 0), PurchasePrice: models.Rupiah(5000000), CurrentValue: models.Rupiah(3500000), DepreciationRate: 15, Condition: "good", Location: "Dapur Utama"},
+		{AssetCode: "AST-002", Name: "Oven Gas Besar", Category: "Peralatan Masak", PurchaseDate: time.Now().AddDate(-3, 0, 0), PurchasePrice: models.Rupiah(8000000), CurrentValue: models.Rupiah(4000000), DepreciationRate: 15, Condition: "good", Location: "Dapur Utama"},
+		{AssetCode: "AST-003", Name: "Freezer Industrial", Category: "Pendingin", PurchaseDate: time.Now().AddDate(-1, 0, 0), PurchasePrice: models.Rupiah(12000000), CurrentValue: models.Rupiah(10000000), DepreciationRate: 10, Condition: "good", Location: "Gudang"},
+		{AssetCode: "AST-004", Name: "Rice Cooker Kapasitas Besar", Category: "Peralatan Masak", PurchaseDate: time.Now().AddDate(-1, 0, 0), PurchasePrice: models.Rupiah(3000000), CurrentValue: models.Rupiah(2500000), DepreciationRate: 20, Condition: "good", Location: "Dapur Utama"},
+		{AssetCode: "AST-005", Name: "Timbangan Digital", Category: "Alat Ukur", PurchaseDate: time.Now().AddDate(-4, 0, 0), PurchasePrice: models.Rupiah(1500000), CurrentValue: models.Rupiah(500000), DepreciationRate: 25, Condition: "fair", Location: "Gudang"},
 	}
 
 	for i := range assets {
@@ -631,7 +631,7 @@ func seedKitchenAssets(db *gorm.DB) {
 			AssetID:         assets[i].ID,
 			MaintenanceDate: time.Now().AddDate(0, -rand.Intn(6), 0),
 			Description:     "Perawatan rutin dan pembersihan",
-			Cost:            float64(rand.Intn(500000) + 100000),
+			Cost:            models.Rupiah(int64(rand.Intn(500000) + 100000)),
 			PerformedBy:     "Teknisi A",
 		}
 		db.Create(&maintenance)
@@ -658,7 +658,7 @@ func seedCashFlowEntries(db *gorm.DB) {
 			Date:          time.Now().AddDate(0, 0, -rand.Intn(90)),
 			Category:      categories[rand.Intn(len(categories))],
 			Type:          entryType,
-			Amount:        float64(rand.Intn(10000000) + 1000000),
+			Amount:        models.Rupiah(int64(rand.Intn(10000000) + 1000000)),
 			Description:   fmt.Sprintf("Transaksi %d", i+1),
 			Reference:     fmt.Sprintf("REF-%06d", i+1),
 			CreatedBy:     kepalaSPPG.ID,
@@ -681,8 +681,8 @@ func seedBudgetTargets(db *gorm.DB) {
 				Year:     year,
 				Month:    month,
 				Category: cat,
-				Target:   float64(rand.Intn(50000000) + 10000000),
-				Actual:   float64(rand.Intn(40000000) + 5000000),
+				Target:   models.Rupiah(int64(rand.Intn(50000000) + 10000000)),
+				Actual:   models.Rupiah(int64(rand.Intn(40000000) + 5000000)),
 			}
 			db.FirstOrCreate(&target, models.BudgetTarget{Year: year, Month: month, Category: cat})
 		}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"time"
@@ -9,8 +10,12 @@ import (
 	"github.com/erp-sppg/backend/internal/cache"
 	"github.com/erp-sppg/backend/internal/config"
 	"github.com/erp-sppg/backend/internal/database"
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/firebase"
+	"github.com/erp-sppg/backend/internal/realtime"
 	"github.com/erp-sppg/backend/internal/router"
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/erp-sppg/backend/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -34,40 +39,79 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// `server migrate ...` manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	// Initialize database
 	db, err := database.Initialize(cfg)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
-	// Run database migrations
+	// Apply pending versioned migrations
 	if err := database.Migrate(db); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
-	// Initialize Redis cache (optional)
+	// Initialize the cache (optional). Redis is used when configured and
+	// reachable, otherwise an in-process LRU cache.
 	var cacheService *cache.CacheService
 	if cfg.EnableCache {
-		redisCache, err := cache.NewRedisCache(cache.CacheConfig{
-			Host:     cfg.RedisHost,
-			Port:     cfg.RedisPort,
-			Password: cfg.RedisPassword,
-			DB:       cfg.RedisDB,
-		})
+		var backend cache.Cache
+		if cfg.CacheBackend == cache.BackendRedis {
+			redisCache, err := cache.NewRedisCache(cache.CacheConfig{
+				Host:     cfg.RedisHost,
+				Port:     cfg.RedisPort,
+				Password: cfg.RedisPassword,
+				DB:       cfg.RedisDB,
+			})
+			if err != nil {
+				log.Printf("Warning: Failed to initialize Redis cache: %v", err)
+				log.Println("Falling back to the in-process cache...")
+			} else {
+				backend = redisCache
+				log.Println("Redis cache initialized successfully")
+			}
+		}
+		if backend == nil {
+			backend = cache.NewMemoryCache(cfg.CacheMaxEntries)
+			log.Println("In-process cache initialized")
+		}
+		cacheService = cache.NewCacheService(backend)
+
+		// Cached data is invalidated by the domain events that change it
+		cacheService.SubscribeInvalidation(events.Default())
+	}
+
+	// Initialize the realtime backend. Firebase is optional: when it is not
+	// configured or cannot be initialized the built-in WebSocket/SSE hub is used.
+	var publisher realtime.RealtimePublisher
+	if cfg.RealtimeBackend == realtime.BackendFirebase {
+		firebasePublisher, err := initFirebasePublisher(cfg)
 		if err != nil {
-			log.Printf("Warning: Failed to initialize Redis cache: %v", err)
-			log.Println("Continuing without cache...")
+			log.Printf("Warning: Failed to initialize Firebase: %v", err)
+			log.Println("Falling back to the built-in realtime hub...")
 		} else {
-			cacheService = cache.NewCacheService(redisCache)
-			log.Println("Redis cache initialized successfully")
+			publisher = firebasePublisher
+			log.Println("Realtime updates are published to Firebase")
 		}
 	}
+	if publisher == nil {
+		publisher = realtime.NewHub()
+		log.Println("Realtime hub serving /api/v1/realtime/ws and /api/v1/realtime/sse")
+	}
 
-	// Initialize Firebase
-	firebaseApp, err := firebase.Initialize(cfg)
+	// Initialize the blob store for uploaded files
+	uploader, err := initStorage(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize Firebase: %v", err)
+		log.Fatalf("Failed to initialize file storage: %v", err)
 	}
+	log.Printf("Uploaded files are stored in the %s blob store", cfg.StorageBackend)
 
 	// Start database performance monitoring
 	perfMonitor := database.NewPerformanceMonitor(db)
@@ -76,11 +120,42 @@ func main() {
 	
 	go perfMonitor.StartPerformanceMonitoring(ctx, 5*time.Minute)
 
+	// Post monthly asset depreciation once a month has ended
+	go services.NewAssetService(db).StartDepreciationScheduler(ctx, time.Hour)
+
+	// Remind responsible users of due and overdue preventive maintenance
+	notificationService, err := services.NewNotificationService(db, publisher)
+	if err != nil {
+		log.Printf("Warning: Failed to initialize notifications: %v", err)
+	}
+	go services.NewMaintenancePlanService(db, notificationService).StartReminderScheduler(ctx, time.Hour)
+
+	// Deliver the realtime syncs recorded in the outbox, with retries
+	outbox := services.NewOutboxService(db)
+	monitoringSync, _ := services.NewMonitoringService(db, publisher)
+	monitoringSync.RegisterOutboxHandlers(outbox)
+	cleaningSync, _ := services.NewCleaningService(db, publisher)
+	cleaningSync.RegisterOutboxHandlers(outbox)
+	webhooks := services.NewWebhookService(db)
+	webhooks.RegisterOutboxHandlers(outbox)
+
+	// Modules react to each other's domain events; events for durable
+	// subscribers are kept in the outbox and delivered by its dispatcher
+	bus := events.Default()
+	outbox.AttachEventBus(bus)
+	monitoringSync.SubscribeEvents(bus)
+	services.NewInventoryService(db).SubscribeEvents(bus)
+	if notificationService != nil {
+		notificationService.SubscribeEvents(bus)
+	}
+	webhooks.SubscribeEvents(bus)
+	go outbox.StartDispatcher(ctx, time.Second)
+
 	// Setup Gin mode
 	gin.SetMode(cfg.GinMode)
 
 	// Initialize router
-	r := router.Setup(db, firebaseApp, cfg, cacheService)
+	r := router.Setup(db, publisher, cfg, cacheService, uploader)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -93,3 +168,38 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// initStorage creates the configured blob store and the uploader on top of it
+func initStorage(cfg *config.Config) (*storage.Uploader, error) {
+	var store storage.BlobStore
+	switch cfg.StorageBackend {
+	case storage.BackendLocal:
+		store = storage.NewLocalStore(cfg.StorageLocalDir, storage.NewURLSigner(cfg.StorageSigningKey, cfg.StoragePublicURL))
+	case storage.BackendS3:
+		s3Store, err := storage.NewS3Store(storage.S3Config{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3Bucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			UsePathStyle: cfg.S3UsePathStyle,
+		})
+		if err != nil {
+			return nil, err
+		}
+		store = s3Store
+	default:
+		return nil, fmt.Errorf("STORAGE_BACKEND tidak dikenal: %q", cfg.StorageBackend)
+	}
+
+	return storage.NewUploader(store, cfg.UploadStripGPS, time.Duration(cfg.StorageURLTTLMinutes)*time.Minute), nil
+}
+
+// initFirebasePublisher connects to the Firebase Realtime Database
+func initFirebasePublisher(cfg *config.Config) (*realtime.FirebasePublisher, error) {
+	firebaseApp, err := firebase.Initialize(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return realtime.NewFirebasePublisher(context.Background(), firebaseApp)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/erp-sppg/backend/internal/config"
+	"github.com/erp-sppg/backend/internal/database"
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/migrations"
+	"gorm.io/driver/sqlite"
+)
+
+const migrateUsage = `Usage: server migrate <command>
+
+Commands:
+  up [N]                      apply all pending migrations, or the next N
+  down [N]                    revert the last migration, or the last N
+  status                      list migrations and when they were applied
+  create [-from-models] NAME  write empty up/down files for a new migration;
+                              -from-models fills them with the full schema of
+                              the current models, as used for the baseline`
+
+// runMigrate handles `server migrate ...` and returns without starting the server
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", migrateUsage)
+	}
+
+	if args[0] == "create" {
+		return createMigration(cfg, args[1:])
+	}
+
+	steps := 0
+	switch args[0] {
+	case "up", "down":
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("jumlah migrasi harus bilangan positif: %s", args[1])
+			}
+			steps = n
+		}
+	case "status":
+	default:
+		return fmt.Errorf("perintah migrate tidak dikenal: %s\n\n%s", args[0], migrateUsage)
+	}
+
+	db, err := database.Initialize(cfg)
+	if err != nil {
+		return err
+	}
+	files, err := migrations.For(db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	migrator, err := database.NewMigrator(db, files)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(steps)
+		for _, migration := range applied {
+			fmt.Printf("applied  %d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return err
+	case "down":
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	default:
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	}
+}
+
+// createMigration writes the files of a new migration to migrations/<driver>
+func createMigration(cfg *config.Config, args []string) error {
+	dialector := database.Dialector(cfg)
+	flags := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+	fromModels := flags.Bool("from-models", false, "fill the migration with the schema of the current models")
+	dir := flags.String("dir", filepath.Join("migrations", dialector.Name()), "directory of the migration files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("%s", migrateUsage)
+	}
+
+	var up, down []string
+	if *fromModels {
+		// The SQLite driver opens the database file even in dry-run mode;
+		// a throwaway in-memory database gives the same SQL
+		if dialector.Name() == "sqlite" {
+			dialector = sqlite.Open(":memory:")
+		}
+		var err error
+		up, down, err = database.SchemaSQL(dialector, models.AllModels()...)
+		if err != nil {
+			return err
+		}
+	}
+
+	upPath, downPath, err := database.CreateMigrationFiles(*dir, flags.Arg(0), up, down)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created %s\ncreated %s\n", upPath, downPath)
+	return nil
+}
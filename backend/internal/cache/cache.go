@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"time"
+)
+
+// Cache backends
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+)
+
+// Cache stores JSON-encoded values with an expiration. Values can be tagged
+// so that a group of keys is invalidated together.
+type Cache interface {
+	// Get decodes the value stored at key into dest, or returns ErrCacheMiss
+	Get(key string, dest interface{}) error
+	// Set stores a value with expiration
+	Set(key string, value interface{}, expiration time.Duration) error
+	// SetNX stores a value only if the key does not exist yet and reports
+	// whether it was stored
+	SetNX(key string, value interface{}, expiration time.Duration) (bool, error)
+	// SetWithTags stores a value and associates it with the tags
+	SetWithTags(key string, value interface{}, tags []string, expiration time.Duration) error
+	// Delete removes a key
+	Delete(key string) error
+	// DeletePattern removes the keys matching a glob pattern such as "user:12*"
+	DeletePattern(pattern string) error
+	// InvalidateByTag removes all keys associated with a tag
+	InvalidateByTag(tag string) error
+	// GetStats returns backend specific statistics
+	GetStats() (map[string]string, error)
+	// Close releases the backend
+	Close() error
+}
+
+var (
+	_ Cache = (*RedisCache)(nil)
+	_ Cache = (*MemoryCache)(nil)
+)
@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryCacheEntries is the capacity of the in-process cache when none is configured
+const DefaultMemoryCacheEntries = 10000
+
+// MemoryCache is an in-process LRU cache used when Redis is not available.
+// It is local to one server process, which suits single-instance deployments.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front is most recently used
+	entries    map[string]*list.Element
+	tags       map[string]map[string]struct{}
+	evictions  uint64
+	expired    uint64
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	tags      []string
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an in-process LRU cache holding at most maxEntries keys
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryCacheEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		tags:       make(map[string]map[string]struct{}),
+	}
+}
+
+// Get retrieves a value from cache
+func (mc *MemoryCache) Get(key string, dest interface{}) error {
+	mc.mu.Lock()
+	elem, ok := mc.entries[key]
+	if !ok {
+		mc.mu.Unlock()
+		return ErrCacheMiss
+	}
+	entry := elem.Value.(*memoryEntry)
+	if mc.isExpired(entry) {
+		mc.removeElement(elem)
+		mc.expired++
+		mc.mu.Unlock()
+		return ErrCacheMiss
+	}
+	mc.order.MoveToFront(elem)
+	value := entry.value
+	mc.mu.Unlock()
+
+	return json.Unmarshal(value, dest)
+}
+
+// Set stores a value in cache with expiration
+func (mc *MemoryCache) Set(key string, value interface{}, expiration time.Duration) error {
+	return mc.SetWithTags(key, value, nil, expiration)
+}
+
+// SetNX stores a value only if the key does not exist yet
+func (mc *MemoryCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if elem, ok := mc.entries[key]; ok && !mc.isExpired(elem.Value.(*memoryEntry)) {
+		return false, nil
+	}
+	mc.store(key, jsonValue, nil, expiration)
+	return true, nil
+}
+
+// SetWithTags stores a value with tags for group invalidation
+func (mc *MemoryCache) SetWithTags(key string, value interface{}, tags []string, expiration time.Duration) error {
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.store(key, jsonValue, tags, expiration)
+	return nil
+}
+
+// Delete removes a key from cache
+func (mc *MemoryCache) Delete(key string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if elem, ok := mc.entries[key]; ok {
+		mc.removeElement(elem)
+	}
+	return nil
+}
+
+// DeletePattern removes all keys matching a glob pattern
+func (mc *MemoryCache) DeletePattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for key, elem := range mc.entries {
+		if matched, _ := path.Match(pattern, key); matched {
+			mc.removeElement(elem)
+		}
+	}
+	return nil
+}
+
+// InvalidateByTag removes all keys associated with a tag
+func (mc *MemoryCache) InvalidateByTag(tag string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for key := range mc.tags[tag] {
+		if elem, ok := mc.entries[key]; ok {
+			mc.removeElement(elem)
+		}
+	}
+	delete(mc.tags, tag)
+	return nil
+}
+
+// GetStats returns cache statistics
+func (mc *MemoryCache) GetStats() (map[string]string, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return map[string]string{
+		"entries":     strconv.Itoa(len(mc.entries)),
+		"max_entries": strconv.Itoa(mc.maxEntries),
+		"evictions":   strconv.FormatUint(mc.evictions, 10),
+		"expired":     strconv.FormatUint(mc.expired, 10),
+	}, nil
+}
+
+// Close releases the cached values
+func (mc *MemoryCache) Close() error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.order.Init()
+	mc.entries = make(map[string]*list.Element)
+	mc.tags = make(map[string]map[string]struct{})
+	return nil
+}
+
+// store inserts or replaces a key and evicts the least recently used keys
+// beyond capacity. The caller holds the lock.
+func (mc *MemoryCache) store(key string, value []byte, tags []string, expiration time.Duration) {
+	if elem, ok := mc.entries[key]; ok {
+		mc.removeElement(elem)
+	}
+
+	entry := &memoryEntry{key: key, value: value, tags: tags}
+	if expiration > 0 {
+		entry.expiresAt = time.Now().Add(expiration)
+	}
+	mc.entries[key] = mc.order.PushFront(entry)
+	for _, tag := range tags {
+		if mc.tags[tag] == nil {
+			mc.tags[tag] = make(map[string]struct{})
+		}
+		mc.tags[tag][key] = struct{}{}
+	}
+
+	for len(mc.entries) > mc.maxEntries {
+		mc.removeElement(mc.order.Back())
+		mc.evictions++
+	}
+}
+
+// removeElement drops an entry and its tag associations. The caller holds the lock.
+func (mc *MemoryCache) removeElement(elem *list.Element) {
+	entry := elem.Value.(*memoryEntry)
+	mc.order.Remove(elem)
+	delete(mc.entries, entry.key)
+	for _, tag := range entry.tags {
+		delete(mc.tags[tag], entry.key)
+		if len(mc.tags[tag]) == 0 {
+			delete(mc.tags, tag)
+		}
+	}
+}
+
+func (mc *MemoryCache) isExpired(entry *memoryEntry) bool {
+	return !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt)
+}
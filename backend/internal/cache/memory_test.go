@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	mc := NewMemoryCache(2)
+	require.NoError(t, mc.Set("a", 1, time.Minute))
+	require.NoError(t, mc.Set("b", 2, time.Minute))
+
+	// Reading "a" makes "b" the least recently used key
+	var value int
+	require.NoError(t, mc.Get("a", &value))
+	require.NoError(t, mc.Set("c", 3, time.Minute))
+
+	assert.ErrorIs(t, mc.Get("b", &value), ErrCacheMiss)
+	require.NoError(t, mc.Get("a", &value))
+	assert.Equal(t, 1, value)
+	require.NoError(t, mc.Get("c", &value))
+	assert.Equal(t, 3, value)
+
+	stats, err := mc.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, "2", stats["entries"])
+	assert.Equal(t, "1", stats["evictions"])
+}
+
+func TestMemoryCache_ExpirationAndSetNX(t *testing.T) {
+	mc := NewMemoryCache(10)
+	require.NoError(t, mc.Set("short", "x", time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	var value string
+	assert.ErrorIs(t, mc.Get("short", &value), ErrCacheMiss)
+
+	stored, err := mc.SetNX("lock", "first", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, stored)
+	stored, err = mc.SetNX("lock", "second", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, stored)
+	require.NoError(t, mc.Get("lock", &value))
+	assert.Equal(t, "first", value)
+}
+
+func TestMemoryCache_TagsAndPatterns(t *testing.T) {
+	mc := NewMemoryCache(10)
+	require.NoError(t, mc.SetWithTags("inventory:items", []int{1}, []string{InventoryTag}, time.Minute))
+	require.NoError(t, mc.SetWithTags("http:1", "resp", []string{HTTPCacheTag, InventoryTag}, time.Minute))
+	require.NoError(t, mc.SetWithTags("menu:recipes", []int{2}, []string{MenuTag}, time.Minute))
+	require.NoError(t, mc.Set("notifications:12:list", 1, time.Minute))
+	require.NoError(t, mc.Set("notifications:13:list", 1, time.Minute))
+
+	require.NoError(t, mc.InvalidateByTag(InventoryTag))
+	var value interface{}
+	assert.ErrorIs(t, mc.Get("inventory:items", &value), ErrCacheMiss)
+	assert.ErrorIs(t, mc.Get("http:1", &value), ErrCacheMiss)
+	assert.NoError(t, mc.Get("menu:recipes", &value))
+
+	require.NoError(t, mc.DeletePattern("notifications:12*"))
+	assert.ErrorIs(t, mc.Get("notifications:12:list", &value), ErrCacheMiss)
+	assert.NoError(t, mc.Get("notifications:13:list", &value))
+}
+
+func TestCacheService_MetricsAndEventInvalidation(t *testing.T) {
+	cs := NewCacheService(NewMemoryCache(100))
+	bus := events.NewBus()
+	cs.SubscribeInvalidation(bus)
+
+	require.NoError(t, cs.SetCachedResponseWithTags("http:inventory", "stock", []string{InventoryTag}, time.Minute))
+	require.NoError(t, cs.SetCachedResponseWithTags("http:suppliers", "suppliers", []string{SupplierTag}, time.Minute))
+
+	var response string
+	require.NoError(t, cs.GetCachedResponse("http:inventory", &response))
+	assert.ErrorIs(t, cs.GetCachedResponse("http:unknown", &response), ErrCacheMiss)
+
+	// Stock changes only drop the inventory data
+	bus.Publish(events.StockChanged{IngredientIDs: []uint{1}, Reason: "in"})
+	assert.ErrorIs(t, cs.GetCachedResponse("http:inventory", &response), ErrCacheMiss)
+	require.NoError(t, cs.GetCachedResponse("http:suppliers", &response))
+
+	bus.Publish(events.PurchaseOrderApproved{PurchaseOrderID: 3, SupplierID: 1})
+	assert.ErrorIs(t, cs.GetCachedResponse("http:suppliers", &response), ErrCacheMiss)
+
+	metrics := cs.GetMetrics()
+	assert.Equal(t, BackendMemory, metrics.Backend)
+	assert.Equal(t, uint64(2), metrics.Hits)
+	assert.Equal(t, uint64(3), metrics.Misses)
+	assert.InDelta(t, 0.4, metrics.HitRatio, 0.001)
+	assert.NotZero(t, metrics.Invalidations)
+}
@@ -58,6 +58,17 @@ func (rc *RedisCache) Set(key string, value interface{}, expiration time.Duratio
 	return rc.client.Set(rc.ctx, key, jsonValue, expiration).Err()
 }
 
+// SetNX stores a value only if the key does not exist yet. It reports
+// whether the value was stored.
+func (rc *RedisCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	return rc.client.SetNX(rc.ctx, key, jsonValue, expiration).Result()
+}
+
 // Get retrieves a value from cache
 func (rc *RedisCache) Get(key string, dest interface{}) error {
 	val, err := rc.client.Get(rc.ctx, key).Result()
@@ -254,6 +265,7 @@ const (
 	FinancialTag    = "financial"
 	NotificationTag = "notification"
 	UserTag         = "user"
+	HTTPCacheTag    = "http_cache"
 )
 
 // Common cache durations
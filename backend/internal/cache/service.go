@@ -1,43 +1,88 @@
 package cache
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"sync/atomic"
 	"time"
 
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
 )
 
-// CacheService provides high-level caching operations
+// CacheService provides high-level caching operations on top of a Cache
+// backend and counts hits and misses
 type CacheService struct {
-	redis *RedisCache
+	cache         Cache
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	errors        atomic.Uint64
+	invalidations atomic.Uint64
 }
 
 // NewCacheService creates a new cache service
-func NewCacheService(redis *RedisCache) *CacheService {
+func NewCacheService(cache Cache) *CacheService {
 	return &CacheService{
-		redis: redis,
+		cache: cache,
 	}
 }
 
+// Redis returns the underlying Redis cache, or nil when another backend is used
+func (cs *CacheService) Redis() *RedisCache {
+	redis, _ := cs.cache.(*RedisCache)
+	return redis
+}
+
+// Backend returns the name of the cache backend
+func (cs *CacheService) Backend() string {
+	if cs.Redis() != nil {
+		return BackendRedis
+	}
+	return BackendMemory
+}
+
+// get reads a value and records the hit or miss
+func (cs *CacheService) get(key string, dest interface{}) error {
+	err := cs.cache.Get(key, dest)
+	switch {
+	case err == nil:
+		cs.hits.Add(1)
+	case errors.Is(err, ErrCacheMiss):
+		cs.misses.Add(1)
+	default:
+		// A broken or undecodable entry is served from the source like a miss
+		cs.errors.Add(1)
+		cs.misses.Add(1)
+	}
+	return err
+}
+
+// invalidateTag removes the keys of a tag and records the invalidation
+func (cs *CacheService) invalidateTag(tag string) error {
+	cs.invalidations.Add(1)
+	return cs.cache.InvalidateByTag(tag)
+}
+
 // Dashboard caching methods
 
 // SetDashboardData caches dashboard data for a specific role and date
 func (cs *CacheService) SetDashboardData(userRole string, date string, data map[string]interface{}) error {
 	key := GenerateDashboardKey(userRole, date)
-	return cs.redis.SetWithTags(key, data, []string{DashboardTag}, MediumCacheDuration)
+	return cs.cache.SetWithTags(key, data, []string{DashboardTag}, MediumCacheDuration)
 }
 
 // GetDashboardData retrieves cached dashboard data
 func (cs *CacheService) GetDashboardData(userRole string, date string) (map[string]interface{}, error) {
 	key := GenerateDashboardKey(userRole, date)
 	var data map[string]interface{}
-	err := cs.redis.Get(key, &data)
+	err := cs.get(key, &data)
 	return data, err
 }
 
 // InvalidateDashboardCache invalidates all dashboard cache
 func (cs *CacheService) InvalidateDashboardCache() error {
-	return cs.redis.InvalidateByTag(DashboardTag)
+	return cs.invalidateTag(DashboardTag)
 }
 
 // Inventory caching methods
@@ -45,34 +90,34 @@ func (cs *CacheService) InvalidateDashboardCache() error {
 // SetInventoryItems caches inventory items
 func (cs *CacheService) SetInventoryItems(items []models.InventoryItem) error {
 	key := GenerateInventoryKey("items")
-	return cs.redis.SetWithTags(key, items, []string{InventoryTag}, MediumCacheDuration)
+	return cs.cache.SetWithTags(key, items, []string{InventoryTag}, MediumCacheDuration)
 }
 
 // GetInventoryItems retrieves cached inventory items
 func (cs *CacheService) GetInventoryItems() ([]models.InventoryItem, error) {
 	key := GenerateInventoryKey("items")
 	var items []models.InventoryItem
-	err := cs.redis.Get(key, &items)
+	err := cs.get(key, &items)
 	return items, err
 }
 
 // SetLowStockItems caches low stock items
 func (cs *CacheService) SetLowStockItems(items []models.InventoryItem) error {
 	key := GenerateInventoryKey("low_stock")
-	return cs.redis.SetWithTags(key, items, []string{InventoryTag}, ShortCacheDuration)
+	return cs.cache.SetWithTags(key, items, []string{InventoryTag}, ShortCacheDuration)
 }
 
 // GetLowStockItems retrieves cached low stock items
 func (cs *CacheService) GetLowStockItems() ([]models.InventoryItem, error) {
 	key := GenerateInventoryKey("low_stock")
 	var items []models.InventoryItem
-	err := cs.redis.Get(key, &items)
+	err := cs.get(key, &items)
 	return items, err
 }
 
 // InvalidateInventoryCache invalidates all inventory cache
 func (cs *CacheService) InvalidateInventoryCache() error {
-	return cs.redis.InvalidateByTag(InventoryTag)
+	return cs.invalidateTag(InventoryTag)
 }
 
 // Menu caching methods
@@ -80,34 +125,34 @@ func (cs *CacheService) InvalidateInventoryCache() error {
 // SetMenuPlan caches menu plan for a specific date
 func (cs *CacheService) SetMenuPlan(date string, menuPlan *models.MenuPlan) error {
 	key := GenerateMenuKey(date)
-	return cs.redis.SetWithTags(key, menuPlan, []string{MenuTag}, LongCacheDuration)
+	return cs.cache.SetWithTags(key, menuPlan, []string{MenuTag}, LongCacheDuration)
 }
 
 // GetMenuPlan retrieves cached menu plan
 func (cs *CacheService) GetMenuPlan(date string) (*models.MenuPlan, error) {
 	key := GenerateMenuKey(date)
 	var menuPlan models.MenuPlan
-	err := cs.redis.Get(key, &menuPlan)
+	err := cs.get(key, &menuPlan)
 	return &menuPlan, err
 }
 
 // SetRecipes caches active recipes
 func (cs *CacheService) SetRecipes(recipes []models.Recipe) error {
 	key := GenerateMenuKey("recipes")
-	return cs.redis.SetWithTags(key, recipes, []string{MenuTag}, LongCacheDuration)
+	return cs.cache.SetWithTags(key, recipes, []string{MenuTag}, LongCacheDuration)
 }
 
 // GetRecipes retrieves cached recipes
 func (cs *CacheService) GetRecipes() ([]models.Recipe, error) {
 	key := GenerateMenuKey("recipes")
 	var recipes []models.Recipe
-	err := cs.redis.Get(key, &recipes)
+	err := cs.get(key, &recipes)
 	return recipes, err
 }
 
 // InvalidateMenuCache invalidates all menu cache
 func (cs *CacheService) InvalidateMenuCache() error {
-	return cs.redis.InvalidateByTag(MenuTag)
+	return cs.invalidateTag(MenuTag)
 }
 
 // Supplier caching methods
@@ -115,34 +160,34 @@ func (cs *CacheService) InvalidateMenuCache() error {
 // SetSupplierPerformance caches supplier performance data
 func (cs *CacheService) SetSupplierPerformance(supplierID uint, data map[string]interface{}) error {
 	key := fmt.Sprintf("%sperformance:%d", SupplierCachePrefix, supplierID)
-	return cs.redis.SetWithTags(key, data, []string{SupplierTag}, LongCacheDuration)
+	return cs.cache.SetWithTags(key, data, []string{SupplierTag}, LongCacheDuration)
 }
 
 // GetSupplierPerformance retrieves cached supplier performance data
 func (cs *CacheService) GetSupplierPerformance(supplierID uint) (map[string]interface{}, error) {
 	key := fmt.Sprintf("%sperformance:%d", SupplierCachePrefix, supplierID)
 	var data map[string]interface{}
-	err := cs.redis.Get(key, &data)
+	err := cs.get(key, &data)
 	return data, err
 }
 
 // SetActiveSuppliers caches active suppliers
 func (cs *CacheService) SetActiveSuppliers(suppliers []models.Supplier) error {
 	key := fmt.Sprintf("%sactive", SupplierCachePrefix)
-	return cs.redis.SetWithTags(key, suppliers, []string{SupplierTag}, LongCacheDuration)
+	return cs.cache.SetWithTags(key, suppliers, []string{SupplierTag}, LongCacheDuration)
 }
 
 // GetActiveSuppliers retrieves cached active suppliers
 func (cs *CacheService) GetActiveSuppliers() ([]models.Supplier, error) {
 	key := fmt.Sprintf("%sactive", SupplierCachePrefix)
 	var suppliers []models.Supplier
-	err := cs.redis.Get(key, &suppliers)
+	err := cs.get(key, &suppliers)
 	return suppliers, err
 }
 
 // InvalidateSupplierCache invalidates all supplier cache
 func (cs *CacheService) InvalidateSupplierCache() error {
-	return cs.redis.InvalidateByTag(SupplierTag)
+	return cs.invalidateTag(SupplierTag)
 }
 
 // Financial caching methods
@@ -150,34 +195,34 @@ func (cs *CacheService) InvalidateSupplierCache() error {
 // SetFinancialReport caches financial report data
 func (cs *CacheService) SetFinancialReport(reportType, period string, data map[string]interface{}) error {
 	key := GenerateFinancialKey(reportType, period)
-	return cs.redis.SetWithTags(key, data, []string{FinancialTag}, MediumCacheDuration)
+	return cs.cache.SetWithTags(key, data, []string{FinancialTag}, MediumCacheDuration)
 }
 
 // GetFinancialReport retrieves cached financial report data
 func (cs *CacheService) GetFinancialReport(reportType, period string) (map[string]interface{}, error) {
 	key := GenerateFinancialKey(reportType, period)
 	var data map[string]interface{}
-	err := cs.redis.Get(key, &data)
+	err := cs.get(key, &data)
 	return data, err
 }
 
 // SetCashFlowSummary caches cash flow summary
 func (cs *CacheService) SetCashFlowSummary(period string, summary map[string]interface{}) error {
 	key := fmt.Sprintf("%scash_flow_summary:%s", FinancialCachePrefix, period)
-	return cs.redis.SetWithTags(key, summary, []string{FinancialTag}, MediumCacheDuration)
+	return cs.cache.SetWithTags(key, summary, []string{FinancialTag}, MediumCacheDuration)
 }
 
 // GetCashFlowSummary retrieves cached cash flow summary
 func (cs *CacheService) GetCashFlowSummary(period string) (map[string]interface{}, error) {
 	key := fmt.Sprintf("%scash_flow_summary:%s", FinancialCachePrefix, period)
 	var summary map[string]interface{}
-	err := cs.redis.Get(key, &summary)
+	err := cs.get(key, &summary)
 	return summary, err
 }
 
 // InvalidateFinancialCache invalidates all financial cache
 func (cs *CacheService) InvalidateFinancialCache() error {
-	return cs.redis.InvalidateByTag(FinancialTag)
+	return cs.invalidateTag(FinancialTag)
 }
 
 // Notification caching methods
@@ -185,40 +230,40 @@ func (cs *CacheService) InvalidateFinancialCache() error {
 // SetUserNotifications caches user notifications
 func (cs *CacheService) SetUserNotifications(userID uint, notifications []models.Notification) error {
 	key := GenerateNotificationKey(userID)
-	return cs.redis.SetWithTags(key, notifications, []string{NotificationTag}, ShortCacheDuration)
+	return cs.cache.SetWithTags(key, notifications, []string{NotificationTag}, ShortCacheDuration)
 }
 
 // GetUserNotifications retrieves cached user notifications
 func (cs *CacheService) GetUserNotifications(userID uint) ([]models.Notification, error) {
 	key := GenerateNotificationKey(userID)
 	var notifications []models.Notification
-	err := cs.redis.Get(key, &notifications)
+	err := cs.get(key, &notifications)
 	return notifications, err
 }
 
 // SetUnreadNotificationCount caches unread notification count for a user
 func (cs *CacheService) SetUnreadNotificationCount(userID uint, count int64) error {
 	key := fmt.Sprintf("%sunread_count:%d", NotificationCachePrefix, userID)
-	return cs.redis.SetWithTags(key, count, []string{NotificationTag}, ShortCacheDuration)
+	return cs.cache.SetWithTags(key, count, []string{NotificationTag}, ShortCacheDuration)
 }
 
 // GetUnreadNotificationCount retrieves cached unread notification count
 func (cs *CacheService) GetUnreadNotificationCount(userID uint) (int64, error) {
 	key := fmt.Sprintf("%sunread_count:%d", NotificationCachePrefix, userID)
 	var count int64
-	err := cs.redis.Get(key, &count)
+	err := cs.get(key, &count)
 	return count, err
 }
 
 // InvalidateUserNotifications invalidates notifications for a specific user
 func (cs *CacheService) InvalidateUserNotifications(userID uint) error {
 	pattern := fmt.Sprintf("%s%d*", NotificationCachePrefix, userID)
-	return cs.redis.DeletePattern(pattern)
+	return cs.cache.DeletePattern(pattern)
 }
 
 // InvalidateNotificationCache invalidates all notification cache
 func (cs *CacheService) InvalidateNotificationCache() error {
-	return cs.redis.InvalidateByTag(NotificationTag)
+	return cs.invalidateTag(NotificationTag)
 }
 
 // User caching methods
@@ -226,38 +271,44 @@ func (cs *CacheService) InvalidateNotificationCache() error {
 // SetUserProfile caches user profile data
 func (cs *CacheService) SetUserProfile(userID uint, user *models.User) error {
 	key := GenerateUserKey(userID)
-	return cs.redis.SetWithTags(key, user, []string{UserTag}, LongCacheDuration)
+	return cs.cache.SetWithTags(key, user, []string{UserTag}, LongCacheDuration)
 }
 
 // GetUserProfile retrieves cached user profile data
 func (cs *CacheService) GetUserProfile(userID uint) (*models.User, error) {
 	key := GenerateUserKey(userID)
 	var user models.User
-	err := cs.redis.Get(key, &user)
+	err := cs.get(key, &user)
 	return &user, err
 }
 
 // InvalidateUserProfile invalidates cache for a specific user
 func (cs *CacheService) InvalidateUserProfile(userID uint) error {
 	key := GenerateUserKey(userID)
-	return cs.redis.Delete(key)
+	return cs.cache.Delete(key)
 }
 
 // InvalidateUserCache invalidates all user cache
 func (cs *CacheService) InvalidateUserCache() error {
-	return cs.redis.InvalidateByTag(UserTag)
+	return cs.invalidateTag(UserTag)
 }
 
 // General utility methods
 
 // GetCachedResponse retrieves a cached HTTP response
 func (cs *CacheService) GetCachedResponse(key string, response interface{}) error {
-	return cs.redis.Get(key, response)
+	return cs.get(key, response)
 }
 
 // SetCachedResponse stores an HTTP response in cache
 func (cs *CacheService) SetCachedResponse(key string, response interface{}, duration time.Duration) error {
-	return cs.redis.SetWithTags(key, response, []string{"http_cache"}, duration)
+	return cs.cache.SetWithTags(key, response, []string{HTTPCacheTag}, duration)
+}
+
+// SetCachedResponseWithTags stores an HTTP response in cache, invalidated
+// together with the domain data of the tags
+func (cs *CacheService) SetCachedResponseWithTags(key string, response interface{}, tags []string, duration time.Duration) error {
+	return cs.cache.SetWithTags(key, response, append([]string{HTTPCacheTag}, tags...), duration)
 }
 
 // WarmupCache preloads frequently accessed data into cache
@@ -273,10 +324,10 @@ func (cs *CacheService) WarmupCache() error {
 
 // ClearAllCache clears all cached data
 func (cs *CacheService) ClearAllCache() error {
-	tags := []string{DashboardTag, InventoryTag, MenuTag, SupplierTag, FinancialTag, NotificationTag, UserTag}
+	tags := []string{DashboardTag, InventoryTag, MenuTag, SupplierTag, FinancialTag, NotificationTag, UserTag, HTTPCacheTag}
 	
 	for _, tag := range tags {
-		if err := cs.redis.InvalidateByTag(tag); err != nil {
+		if err := cs.invalidateTag(tag); err != nil {
 			return err
 		}
 	}
@@ -286,7 +337,60 @@ func (cs *CacheService) ClearAllCache() error {
 
 // GetCacheStats returns cache statistics
 func (cs *CacheService) GetCacheStats() (map[string]string, error) {
-	return cs.redis.GetStats()
+	return cs.cache.GetStats()
+}
+
+// CacheMetrics are the cache counters since startup
+type CacheMetrics struct {
+	Backend       string            `json:"backend"`
+	Hits          uint64            `json:"hits"`
+	Misses        uint64            `json:"misses"`
+	HitRatio      float64           `json:"hit_ratio"`
+	Errors        uint64            `json:"errors"`
+	Invalidations uint64            `json:"invalidations"`
+	BackendStats  map[string]string `json:"backend_stats,omitempty"`
+}
+
+// GetMetrics returns the hit and miss counters and the backend statistics
+func (cs *CacheService) GetMetrics() CacheMetrics {
+	metrics := CacheMetrics{
+		Backend:       cs.Backend(),
+		Hits:          cs.hits.Load(),
+		Misses:        cs.misses.Load(),
+		Errors:        cs.errors.Load(),
+		Invalidations: cs.invalidations.Load(),
+	}
+	if total := metrics.Hits + metrics.Misses; total > 0 {
+		metrics.HitRatio = float64(metrics.Hits) / float64(total)
+	}
+	if stats, err := cs.cache.GetStats(); err == nil {
+		metrics.BackendStats = stats
+	}
+	return metrics
+}
+
+// SubscribeInvalidation invalidates cached data when the domain events that
+// change it are published
+func (cs *CacheService) SubscribeInvalidation(bus *events.Bus) {
+	bus.Subscribe(events.StockChangedEvent, invalidateOn(cs.InvalidateOnInventoryChange))
+	bus.Subscribe(events.MenuApprovedEvent, invalidateOn(cs.InvalidateOnMenuChange))
+	bus.Subscribe(events.RecipeChangedEvent, invalidateOn(cs.InvalidateOnMenuChange))
+	bus.Subscribe(events.SupplierChangedEvent, invalidateOn(cs.InvalidateOnSupplierChange))
+	// Supplier performance counts approved orders and on-time receipts
+	bus.Subscribe(events.PurchaseOrderApprovedEvent, invalidateOn(cs.InvalidateOnSupplierChange, cs.InvalidateOnFinancialChange))
+	bus.Subscribe(events.GoodsReceivedEvent, invalidateOn(cs.InvalidateOnSupplierChange))
+}
+
+// invalidateOn adapts invalidation helpers to an event handler. A failed
+// invalidation is logged; the cached data then expires with its TTL.
+func invalidateOn(invalidate ...func() error) events.Handler {
+	return func(event events.Event) {
+		for _, fn := range invalidate {
+			if err := fn(); err != nil {
+				log.Printf("[CACHE] failed to invalidate on %s: %v", event.EventName(), err)
+			}
+		}
+	}
 }
 
 // Cache invalidation helpers for common operations
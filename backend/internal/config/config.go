@@ -12,6 +12,8 @@ type Config struct {
 	GinMode string
 
 	// Database
+	DBDriver   string // "postgres" or "sqlite"
+	DBPath     string // database file when DBDriver is "sqlite"
 	DBHost     string
 	DBPort     string
 	DBUser     string
@@ -28,11 +30,30 @@ type Config struct {
 	FirebaseDatabaseURL     string
 	StorageBucket           string
 
+	// Realtime
+	RealtimeBackend string // "firebase" or "hub" (built-in WebSocket/SSE server)
+
+	// File storage
+	StorageBackend       string // "local" or "s3"
+	StorageLocalDir      string
+	StorageSigningKey    string
+	StorageURLTTLMinutes int
+	StoragePublicURL     string // base URL of the API for signed local URLs, empty for relative URLs
+	UploadStripGPS       []string
+	S3Endpoint           string
+	S3Region             string
+	S3Bucket             string
+	S3AccessKey          string
+	S3SecretKey          string
+	S3UsePathStyle       bool
+
 	// CORS
 	AllowedOrigins []string
 
 	// Session
-	SessionTimeoutMinutes int
+	SessionTimeoutMinutes int // inactivity timeout, 0 disables it
+	AccessTokenTTLMinutes int
+	RefreshTokenTTLDays   int
 
 	// Security
 	EnableHTTPS           bool
@@ -43,6 +64,7 @@ type Config struct {
 	RateLimitWindow       int // minutes
 	AdminWhitelistIPs     []string
 	EnableCSRFProtection  bool
+	IdempotencyTTLHours   int // how long responses to Idempotency-Key requests are replayed
 
 	// Redis Cache
 	RedisHost     string
@@ -50,39 +72,71 @@ type Config struct {
 	RedisPassword string
 	RedisDB       int
 	EnableCache   bool
+
+	// Cache
+	CacheBackend    string // "redis" (falls back to memory when unreachable) or "memory"
+	CacheMaxEntries int    // capacity of the in-process cache
 }
 
 func Load() *Config {
 	jwtExpiryHours, _ := strconv.Atoi(getEnv("JWT_EXPIRY_HOURS", "24"))
 	sessionTimeout, _ := strconv.Atoi(getEnv("SESSION_TIMEOUT_MINUTES", "30"))
+	accessTokenTTL, _ := strconv.Atoi(getEnv("ACCESS_TOKEN_TTL_MINUTES", "15"))
+	refreshTokenTTL, _ := strconv.Atoi(getEnv("REFRESH_TOKEN_TTL_DAYS", "30"))
 	maxRequestSize, _ := strconv.ParseInt(getEnv("MAX_REQUEST_SIZE", "10485760"), 10, 64) // 10MB default
 	authRateLimit, _ := strconv.Atoi(getEnv("AUTH_RATE_LIMIT", "5"))
 	apiRateLimit, _ := strconv.Atoi(getEnv("API_RATE_LIMIT", "100"))
 	rateLimitWindow, _ := strconv.Atoi(getEnv("RATE_LIMIT_WINDOW", "1"))
 	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	cacheMaxEntries, _ := strconv.Atoi(getEnv("CACHE_MAX_ENTRIES", "10000"))
+	idempotencyTTL, _ := strconv.Atoi(getEnv("IDEMPOTENCY_TTL_HOURS", "24"))
+	storageURLTTL, _ := strconv.Atoi(getEnv("STORAGE_URL_TTL_MINUTES", "15"))
+	jwtSecret := getEnv("JWT_SECRET", "change-this-secret")
 
 	allowedOrigins := strings.Split(getEnv("ALLOWED_ORIGINS", "http://localhost:5173,http://localhost:5174"), ",")
 	adminWhitelistIPs := strings.Split(getEnv("ADMIN_WHITELIST_IPS", ""), ",")
 	if len(adminWhitelistIPs) == 1 && adminWhitelistIPs[0] == "" {
 		adminWhitelistIPs = []string{}
 	}
+	// Images in these upload categories lose their GPS location; "none" keeps it everywhere
+	stripGPSCategories := strings.Split(getEnv("UPLOAD_STRIP_GPS", "invoices,cooperation-letters,recipes,receipts,reviews"), ",")
+	if len(stripGPSCategories) == 1 && stripGPSCategories[0] == "none" {
+		stripGPSCategories = []string{}
+	}
 
 	return &Config{
 		Port:                    getEnv("PORT", "8080"),
 		GinMode:                 getEnv("GIN_MODE", "debug"),
+		DBDriver:                getEnv("DB_DRIVER", "postgres"),
+		DBPath:                  getEnv("DB_PATH", "./erp_sppg.db"),
 		DBHost:                  getEnv("DB_HOST", "localhost"),
 		DBPort:                  getEnv("DB_PORT", "5432"),
 		DBUser:                  getEnv("DB_USER", "postgres"),
 		DBPassword:              getEnv("DB_PASSWORD", "postgres"),
 		DBName:                  getEnv("DB_NAME", "erp_sppg"),
 		DBSSLMode:               getEnv("DB_SSLMODE", "disable"),
-		JWTSecret:               getEnv("JWT_SECRET", "change-this-secret"),
+		JWTSecret:               jwtSecret,
 		JWTExpiryHours:          jwtExpiryHours,
 		FirebaseCredentialsPath: getEnv("FIREBASE_CREDENTIALS_PATH", "./firebase-credentials.json"),
 		FirebaseDatabaseURL:     getEnv("FIREBASE_DATABASE_URL", ""),
 		StorageBucket:           getEnv("STORAGE_BUCKET", ""),
+		RealtimeBackend:         getEnv("REALTIME_BACKEND", "firebase"),
+		StorageBackend:          getEnv("STORAGE_BACKEND", "local"),
+		StorageLocalDir:         getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+		StorageSigningKey:       getEnv("STORAGE_SIGNING_KEY", jwtSecret),
+		StorageURLTTLMinutes:    storageURLTTL,
+		StoragePublicURL:        strings.TrimRight(getEnv("STORAGE_PUBLIC_URL", ""), "/"),
+		UploadStripGPS:          stripGPSCategories,
+		S3Endpoint:              getEnv("S3_ENDPOINT", ""),
+		S3Region:                getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:                getEnv("S3_BUCKET", ""),
+		S3AccessKey:             getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:             getEnv("S3_SECRET_KEY", ""),
+		S3UsePathStyle:          getEnv("S3_USE_PATH_STYLE", "false") == "true",
 		AllowedOrigins:          allowedOrigins,
 		SessionTimeoutMinutes:   sessionTimeout,
+		AccessTokenTTLMinutes:   accessTokenTTL,
+		RefreshTokenTTLDays:     refreshTokenTTL,
 		EnableHTTPS:             getEnv("ENABLE_HTTPS", "false") == "true",
 		MaxRequestSize:          maxRequestSize,
 		EnableRateLimit:         getEnv("ENABLE_RATE_LIMIT", "true") == "true",
@@ -91,11 +145,14 @@ func Load() *Config {
 		RateLimitWindow:         rateLimitWindow,
 		AdminWhitelistIPs:       adminWhitelistIPs,
 		EnableCSRFProtection:    getEnv("ENABLE_CSRF_PROTECTION", "true") == "true",
+		IdempotencyTTLHours:     idempotencyTTL,
 		RedisHost:               getEnv("REDIS_HOST", "localhost"),
 		RedisPort:               getEnv("REDIS_PORT", "6379"),
 		RedisPassword:           getEnv("REDIS_PASSWORD", ""),
 		RedisDB:                 redisDB,
 		EnableCache:             getEnv("ENABLE_CACHE", "true") == "true",
+		CacheBackend:            getEnv("CACHE_BACKEND", "redis"),
+		CacheMaxEntries:         cacheMaxEntries,
 	}
 }
 
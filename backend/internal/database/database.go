@@ -11,7 +11,19 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-func Initialize(cfg *config.Config) (*gorm.DB, error) {
+// sqliteOptions are the connection options of a SQLite database file: foreign
+// keys are enforced, writers wait for each other instead of failing, and
+// transactions take the write lock up front so two of them cannot deadlock
+// upgrading their read locks
+const sqliteOptions = "_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"
+
+// Dialector returns the GORM dialector for the configured database. It does
+// not connect yet.
+func Dialector(cfg *config.Config) gorm.Dialector {
+	if cfg.DBDriver == "sqlite" {
+		return SQLiteDialector(cfg.DBPath + "?" + sqliteOptions)
+	}
+
 	var dsn string
 	if cfg.DBPassword == "" {
 		dsn = fmt.Sprintf(
@@ -33,11 +45,20 @@ func Initialize(cfg *config.Config) (*gorm.DB, error) {
 			cfg.DBSSLMode,
 		)
 	}
+	return postgres.Open(dsn)
+}
+
+func Initialize(cfg *config.Config) (*gorm.DB, error) {
+	if cfg.DBDriver != "postgres" && cfg.DBDriver != "sqlite" {
+		return nil, fmt.Errorf("DB_DRIVER tidak dikenal: %s (postgres atau sqlite)", cfg.DBDriver)
+	}
 
 	// Configure GORM with optimizations
 	gormConfig := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
-		// Disable foreign key constraints for better performance (handle in application)
+		// Foreign keys come from the versioned migrations. AutoMigrate only runs
+		// to bring a pre-migration database up to the baseline, where orphaned
+		// rows would make the constraints fail.
 		DisableForeignKeyConstraintWhenMigrating: true,
 		// Prepare statements for better performance
 		PrepareStmt: true,
@@ -45,7 +66,7 @@ func Initialize(cfg *config.Config) (*gorm.DB, error) {
 		SkipDefaultTransaction: true,
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	db, err := gorm.Open(Dialector(cfg), gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -62,7 +83,7 @@ func Initialize(cfg *config.Config) (*gorm.DB, error) {
 	sqlDB.SetConnMaxLifetime(time.Hour)         // Connection max lifetime
 	sqlDB.SetConnMaxIdleTime(10 * time.Minute)  // Connection max idle time
 
-	log.Println("Database connection established with optimized settings")
+	log.Printf("Database connection established with optimized settings (%s)", cfg.DBDriver)
 
 	return db, nil
 }
@@ -0,0 +1,454 @@
+package database
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// baselineMigration is the name of the migration that creates the schema as it
+// was when versioned migrations were introduced
+const baselineMigration = "baseline"
+
+// migrationLockKey is the Postgres advisory lock held while migrating, so
+// servers starting together do not apply the same migration twice
+const migrationLockKey = 72390149
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change, read from the files
+// <version>_<name>.up.sql and <version>_<name>.down.sql
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration has been applied, and when
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// schemaMigration records an applied migration
+type schemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// Migrator applies and reverts versioned SQL migrations and records them in
+// the schema_migrations table
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the migrations in files
+func NewMigrator(db *gorm.DB, files fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// LoadMigrations reads the migrations in files, ordered by version
+func LoadMigrations(files fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("nama file migrasi %s tidak valid, gunakan <versi>_<nama>.up.sql atau .down.sql", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("versi migrasi %d dipakai oleh %s dan %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("migrasi %d_%s tidak memiliki file up", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies pending migrations in order, all of them when steps is zero or
+// less, and returns the ones applied. Each migration runs in its own
+// transaction together with its schema_migrations record.
+func (m *Migrator) Up(steps int) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(func(db *gorm.DB) error {
+		done, err := m.appliedVersions(db)
+		if err != nil {
+			return err
+		}
+
+		if len(done) == 0 {
+			adopted, err := m.adoptLegacySchema(db)
+			if err != nil {
+				return err
+			}
+			if adopted != nil {
+				done[adopted.Version] = time.Now()
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+
+			log.Printf("[MIGRATE] applying %d_%s", migration.Version, migration.Name)
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := execMigrationSQL(tx, migration.Up); err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migrasi %d_%s gagal: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the latest applied migrations, one when steps is zero or less,
+// and returns the ones reverted
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	var reverted []Migration
+	err := m.withLock(func(db *gorm.DB) error {
+		done, err := m.appliedVersions(db)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if strings.TrimSpace(migration.Down) == "" {
+				return fmt.Errorf("migrasi %d_%s tidak memiliki file down", migration.Version, migration.Name)
+			}
+
+			log.Printf("[MIGRATE] reverting %d_%s", migration.Version, migration.Name)
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := execMigrationSQL(tx, migration.Down); err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("pembatalan migrasi %d_%s gagal: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every known migration with the time it was applied, if it was
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	if err := m.db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, err
+	}
+	done, err := m.appliedVersions(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Migration: migration}
+		if appliedAt, ok := done[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// withLock runs fn on one connection holding the migration lock
+func (m *Migrator) withLock(fn func(db *gorm.DB) error) error {
+	return m.db.Connection(func(conn *gorm.DB) error {
+		// A new session on the connection, so statements do not share conditions
+		db := conn.Session(&gorm.Session{})
+		if db.Dialector.Name() == "postgres" {
+			if err := db.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+				return fmt.Errorf("gagal mengunci migrasi: %w", err)
+			}
+			defer db.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+		}
+
+		if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+			return err
+		}
+		return fn(db)
+	})
+}
+
+// appliedVersions returns the applied migrations with the time they were applied
+func (m *Migrator) appliedVersions(db *gorm.DB) (map[int64]time.Time, error) {
+	var records []schemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[int64]time.Time, len(records))
+	for _, record := range records {
+		done[record.Version] = record.AppliedAt
+	}
+	return done, nil
+}
+
+// adoptLegacySchema brings a database that was created by AutoMigrate, before
+// versioned migrations, up to the baseline and records the baseline as
+// applied. Only the baseline is adopted, so the migrations after it still run
+// against the schema they were written for. Empty databases are left to the
+// baseline.
+func (m *Migrator) adoptLegacySchema(db *gorm.DB) (*Migration, error) {
+	var baseline *Migration
+	for i := range m.migrations {
+		if m.migrations[i].Name == baselineMigration {
+			baseline = &m.migrations[i]
+			break
+		}
+	}
+	if baseline == nil || !db.Migrator().HasTable(&models.User{}) {
+		return nil, nil
+	}
+
+	log.Printf("[MIGRATE] existing schema found, adopting %d_%s", baseline.Version, baseline.Name)
+
+	// What Migrate did on every start before versioned migrations
+	if err := MigrateMoneyToMinorUnits(db); err != nil {
+		return nil, err
+	}
+	if err := applyLegacyBaseline(db, baseline.Up); err != nil {
+		return nil, fmt.Errorf("adopsi %d_%s gagal: %w", baseline.Version, baseline.Name, err)
+	}
+
+	if err := db.Create(&schemaMigration{Version: baseline.Version, Name: baseline.Name, AppliedAt: time.Now()}).Error; err != nil {
+		return nil, err
+	}
+	return baseline, nil
+}
+
+var (
+	createTablePattern   = regexp.MustCompile("(?s)^CREATE TABLE [\"`]?(\\w+)[\"`]? \\((.*)\\)$")
+	createIndexPattern   = regexp.MustCompile(`^CREATE (UNIQUE )?INDEX (IF NOT EXISTS )?`)
+	addConstraintPattern = regexp.MustCompile("^ALTER TABLE [\"`]?(\\w+)[\"`]? ADD CONSTRAINT [\"`]?(\\w+)[\"`]? ")
+)
+
+// applyLegacyBaseline runs the baseline on a database created by AutoMigrate
+// without failing on what it already has: missing tables, columns and indexes
+// are created, existing ones are left alone. Rows pointing at records that no
+// longer exist make a foreign key fail; it is skipped with a warning so the
+// server still starts, and can be added once the rows are cleaned up.
+func applyLegacyBaseline(db *gorm.DB, sql string) error {
+	for _, statement := range splitStatements(sql) {
+		if match := createTablePattern.FindStringSubmatch(statement); match != nil {
+			if db.Migrator().HasTable(match[1]) {
+				if err := addMissingColumns(db, match[1], match[2]); err != nil {
+					return err
+				}
+				continue
+			}
+		} else if createIndexPattern.MatchString(statement) {
+			statement = createIndexPattern.ReplaceAllString(statement, "CREATE ${1}INDEX IF NOT EXISTS ")
+		} else if match := addConstraintPattern.FindStringSubmatch(statement); match != nil {
+			if db.Migrator().HasConstraint(match[1], match[2]) {
+				continue
+			}
+			if err := db.Exec(statement).Error; err != nil {
+				log.Printf("Warning: Failed to create foreign key %s on %s, check for orphaned rows: %v", match[2], match[1], err)
+			}
+			continue
+		}
+
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addMissingColumns adds the columns of a baseline CREATE TABLE body that the
+// existing table does not have yet
+func addMissingColumns(db *gorm.DB, table, body string) error {
+	for _, definition := range splitDefinitions(body) {
+		upper := strings.ToUpper(definition)
+		if strings.HasPrefix(upper, "PRIMARY KEY") || strings.HasPrefix(upper, "CONSTRAINT") ||
+			strings.HasPrefix(upper, "UNIQUE") || strings.HasPrefix(upper, "CHECK") || strings.HasPrefix(upper, "FOREIGN KEY") {
+			continue
+		}
+
+		column := strings.Trim(strings.Fields(definition)[0], "\"`")
+		if db.Migrator().HasColumn(table, column) {
+			continue
+		}
+		log.Printf("[MIGRATE] adding %s.%s", table, column)
+		if err := db.Exec("ALTER TABLE ? ADD COLUMN "+definition, clause.Table{Name: table}).Error; err != nil {
+			return fmt.Errorf("kolom %s.%s: %w", table, column, err)
+		}
+	}
+	return nil
+}
+
+// splitDefinitions splits the body of a CREATE TABLE at the commas between
+// column and constraint definitions, skipping those inside parentheses or
+// quoted strings
+func splitDefinitions(body string) []string {
+	var (
+		definitions []string
+		depth       int
+		quoted      bool
+		start       int
+	)
+	for i, r := range body {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			definitions = append(definitions, strings.TrimSpace(body[start:i]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(body[start:]); rest != "" {
+		definitions = append(definitions, rest)
+	}
+	return definitions
+}
+
+// execMigrationSQL runs the statements of a migration file one at a time
+func execMigrationSQL(tx *gorm.DB, sql string) error {
+	for _, statement := range splitStatements(sql) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements splits a migration file into statements. A statement ends
+// with a semicolon at the end of a line, outside $$-quoted function bodies;
+// lines starting with -- are comments.
+func splitStatements(sql string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		inDollar   bool
+	)
+	scanner := bufio.NewScanner(strings.NewReader(sql))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if !inDollar && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.Count(line, "$$")%2 == 1 {
+			inDollar = !inDollar
+		}
+		if !inDollar && strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// CreateMigrationFiles writes the up and down files of a new migration to dir,
+// versioned with the current time, and returns their paths
+func CreateMigrationFiles(dir, name string, up, down []string) (string, string, error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+	if !regexp.MustCompile(`^[a-z0-9_]+$`).MatchString(name) {
+		return "", "", errors.New("nama migrasi hanya boleh berisi huruf kecil, angka dan garis bawah")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+
+	version := time.Now().UTC().Format("20060102150405")
+	upPath := filepath.Join(dir, fmt.Sprintf("%s_%s.up.sql", version, name))
+	downPath := filepath.Join(dir, fmt.Sprintf("%s_%s.down.sql", version, name))
+	if err := os.WriteFile(upPath, []byte(migrationFileContent(up)), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte(migrationFileContent(down)), 0o644); err != nil {
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
+
+func migrationFileContent(statements []string) string {
+	if len(statements) == 0 {
+		return "-- Write the migration here; end every statement with a semicolon\n"
+	}
+	return strings.Join(statements, ";\n") + ";\n"
+}
@@ -0,0 +1,267 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupMigratorTestDB(t *testing.T) *gorm.DB {
+	// Same as Initialize: foreign keys come from the migrations, not AutoMigrate
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "migrate.db")), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func migrationFiles(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fsys
+}
+
+var testMigrations = map[string]string{
+	"20250101000000_create_kitchens.up.sql":   "CREATE TABLE kitchens (id INTEGER PRIMARY KEY, name TEXT NOT NULL);\n",
+	"20250101000000_create_kitchens.down.sql": "DROP TABLE kitchens;\n",
+	"20250102000000_add_kitchen_city.up.sql": `-- City of the kitchen
+ALTER TABLE kitchens ADD COLUMN city TEXT;
+CREATE INDEX idx_kitchens_city ON kitchens(city);
+`,
+	"20250102000000_add_kitchen_city.down.sql": "DROP INDEX idx_kitchens_city;\nALTER TABLE kitchens DROP COLUMN city;\n",
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	db := setupMigratorTestDB(t)
+	migrator, err := NewMigrator(db, migrationFiles(testMigrations))
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(0)
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+	assert.Equal(t, "create_kitchens", applied[0].Name)
+	assert.True(t, db.Migrator().HasColumn("kitchens", "city"))
+
+	// Nothing left to apply
+	applied, err = migrator.Up(0)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	reverted, err := migrator.Down(0)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, int64(20250102000000), reverted[0].Version)
+	assert.False(t, db.Migrator().HasColumn("kitchens", "city"))
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+
+	applied, err = migrator.Up(1)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "add_kitchen_city", applied[0].Name)
+
+	reverted, err = migrator.Down(5)
+	require.NoError(t, err)
+	assert.Len(t, reverted, 2)
+	assert.False(t, db.Migrator().HasTable("kitchens"))
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	db := setupMigratorTestDB(t)
+	files := migrationFiles(map[string]string{
+		"20250101000000_create_kitchens.up.sql":   "CREATE TABLE kitchens (id INTEGER PRIMARY KEY);\n",
+		"20250102000000_broken.up.sql":            "CREATE TABLE sinks (id INTEGER PRIMARY KEY);\nINSERT INTO missing_table VALUES (1);\n",
+		"20250103000000_never_reached.up.sql":     "CREATE TABLE stoves (id INTEGER PRIMARY KEY);\n",
+		"20250101000000_create_kitchens.down.sql": "DROP TABLE kitchens;\n",
+	})
+	migrator, err := NewMigrator(db, files)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "20250102000000_broken")
+	assert.Len(t, applied, 1)
+	assert.True(t, db.Migrator().HasTable("kitchens"))
+	assert.False(t, db.Migrator().HasTable("sinks"), "the failed migration leaves nothing behind")
+	assert.False(t, db.Migrator().HasTable("stoves"))
+
+	var count int64
+	db.Model(&schemaMigration{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestMigrator_AdoptsLegacySchema(t *testing.T) {
+	db := setupMigratorTestDB(t)
+	// A database created by AutoMigrate before versioned migrations, from
+	// models older than the baseline
+	require.NoError(t, db.Exec("CREATE TABLE users (id integer PRIMARY KEY AUTOINCREMENT, email text NOT NULL)").Error)
+	require.NoError(t, db.Exec("INSERT INTO users (email) VALUES ('a@sppg.id')").Error)
+
+	files := migrationFiles(map[string]string{
+		"20250101000000_baseline.up.sql": "CREATE TABLE `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`email` text NOT NULL,`full_name` text DEFAULT 'tanpa nama, -',`price` decimal(10,2));\n" +
+			"CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`);\n" +
+			"CREATE TABLE `kitchens` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer,CONSTRAINT `fk_kitchens_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));\n",
+		"20250101000000_baseline.down.sql": "DROP TABLE kitchens;\nDROP TABLE users;\n",
+		// Written against the baseline, so it fails if adoption already added the column
+		"20250102000000_add_nickname.up.sql":   "ALTER TABLE users ADD COLUMN nickname text;\nCREATE TABLE stoves (id integer PRIMARY KEY);\n",
+		"20250102000000_add_nickname.down.sql": "DROP TABLE stoves;\nALTER TABLE users DROP COLUMN nickname;\n",
+	})
+	migrator, err := NewMigrator(db, files)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(0)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "add_nickname", applied[0].Name)
+	assert.True(t, db.Migrator().HasColumn("users", "full_name"), "missing baseline columns are added")
+	assert.True(t, db.Migrator().HasColumn("users", "price"))
+	assert.True(t, db.Migrator().HasColumn("users", "nickname"))
+	assert.True(t, db.Migrator().HasIndex("users", "idx_users_email"))
+	assert.True(t, db.Migrator().HasTable("kitchens"), "missing baseline tables are created")
+	assert.True(t, db.Migrator().HasTable("stoves"))
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.NotNil(t, statuses[1].AppliedAt)
+
+	var fullName string
+	require.NoError(t, db.Raw("SELECT full_name FROM users WHERE email = 'a@sppg.id'").Scan(&fullName).Error)
+	assert.Equal(t, "tanpa nama, -", fullName, "existing rows are kept")
+
+	applied, err = migrator.Up(0)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestSplitDefinitions(t *testing.T) {
+	assert.Equal(t, []string{
+		"`id` integer PRIMARY KEY",
+		"`price` decimal(10,2)",
+		"`note` text DEFAULT 'a, b'",
+		"CONSTRAINT `fk` FOREIGN KEY (`a`,`b`) REFERENCES `t`(`a`,`b`)",
+	}, splitDefinitions("`id` integer PRIMARY KEY,`price` decimal(10,2), `note` text DEFAULT 'a, b',CONSTRAINT `fk` FOREIGN KEY (`a`,`b`) REFERENCES `t`(`a`,`b`)"))
+}
+
+func TestLoadMigrations_RejectsInvalidFiles(t *testing.T) {
+	_, err := LoadMigrations(migrationFiles(map[string]string{"create_kitchens.sql": "SELECT 1;"}))
+	assert.Error(t, err)
+
+	_, err = LoadMigrations(migrationFiles(map[string]string{"20250101000000_orphan.down.sql": "SELECT 1;"}))
+	assert.Error(t, err)
+
+	_, err = LoadMigrations(migrationFiles(map[string]string{
+		"20250101000000_one.up.sql": "SELECT 1;",
+		"20250101000000_two.up.sql": "SELECT 2;",
+	}))
+	assert.Error(t, err)
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements(`-- comment
+CREATE TABLE a (id INT);
+
+CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+  NEW.updated_at = now();
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+INSERT INTO a VALUES (1)`)
+
+	require.Len(t, statements, 3)
+	assert.Equal(t, "CREATE TABLE a (id INT)", statements[0])
+	assert.True(t, strings.HasPrefix(statements[1], "CREATE FUNCTION touch()"))
+	assert.True(t, strings.HasSuffix(statements[1], "LANGUAGE plpgsql"))
+	assert.Equal(t, "INSERT INTO a VALUES (1)", statements[2])
+}
+
+func TestSchemaSQL_Postgres(t *testing.T) {
+	dialector := postgres.New(postgres.Config{DSN: "host=localhost dbname=erp_sppg"})
+	up, down, err := SchemaSQL(dialector, &models.StokOpnameItem{}, &models.StokOpnameForm{}, &models.Ingredient{}, &models.User{})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(up[0], `CREATE TABLE "stok_opname_items"`))
+	assert.Contains(t, up, `ALTER TABLE "stok_opname_items" ADD CONSTRAINT "fk_stok_opname_items_ingredient" FOREIGN KEY ("ingredient_id") REFERENCES "ingredients"("id")`)
+	// Foreign keys come after every table
+	for i, statement := range up {
+		if strings.HasPrefix(statement, "ALTER TABLE") {
+			for _, rest := range up[i:] {
+				assert.False(t, strings.HasPrefix(rest, "CREATE TABLE"))
+			}
+			break
+		}
+	}
+	assert.Equal(t, []string{
+		`DROP TABLE IF EXISTS "users" CASCADE`,
+		`DROP TABLE IF EXISTS "ingredients" CASCADE`,
+		`DROP TABLE IF EXISTS "stok_opname_forms" CASCADE`,
+		`DROP TABLE IF EXISTS "stok_opname_items" CASCADE`,
+	}, down)
+
+	again, _, err := SchemaSQL(dialector, &models.StokOpnameItem{}, &models.StokOpnameForm{}, &models.Ingredient{}, &models.User{})
+	require.NoError(t, err)
+	assert.Equal(t, up, again, "the output is stable")
+}
+
+func TestSchemaSQL_SQLite(t *testing.T) {
+	up, _, err := SchemaSQL(sqlite.Open(":memory:"), &models.StokOpnameItem{}, &models.StokOpnameForm{}, &models.Ingredient{}, &models.User{})
+	require.NoError(t, err)
+
+	// SQLite cannot add constraints later, so they are part of the table
+	for _, statement := range up {
+		assert.False(t, strings.HasPrefix(statement, "ALTER TABLE"))
+	}
+	assert.True(t, strings.HasPrefix(up[0], "CREATE TABLE `stok_opname_items`"))
+	assert.Contains(t, up[0], ",CONSTRAINT `fk_stok_opname_forms_items` FOREIGN KEY (`form_id`) REFERENCES `stok_opname_forms`(`id`) ON DELETE CASCADE,CONSTRAINT `fk_stok_opname_items_ingredient`")
+}
+
+func TestEmbeddedMigrations_SQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "erp_sppg.db")+"?"+sqliteOptions), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+		Logger:                                   logger.Discard,
+	})
+	require.NoError(t, err)
+	files, err := migrations.For("sqlite")
+	require.NoError(t, err)
+	migrator, err := NewMigrator(db, files)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(0)
+	require.NoError(t, err)
+	require.NotEmpty(t, applied)
+	assert.Equal(t, "baseline", applied[0].Name)
+
+	wifi := models.WiFiConfig{SSID: "SPPG", BSSID: "aa:bb", AllowedIPs: models.StringList{"192.168.1.10", "192.168.1.11"}, IsActive: true}
+	require.NoError(t, db.Create(&wifi).Error)
+	var stored models.WiFiConfig
+	require.NoError(t, db.First(&stored, wifi.ID).Error)
+	assert.Equal(t, wifi.AllowedIPs, stored.AllowedIPs)
+
+	// Foreign keys are enforced
+	err = db.Create(&models.AuditTrail{UserID: 999, Action: "create", Entity: "user"}).Error
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "FOREIGN KEY")
+
+	reverted, err := migrator.Down(len(applied))
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(applied))
+	assert.False(t, db.Migrator().HasTable(&models.User{}))
+	assert.False(t, db.Migrator().HasTable(&models.WiFiConfig{}))
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// moneyColumns lists the monetary fields that were stored as floating point
+// rupiah before amounts became integer sen (models.Money)
+var moneyColumns = []struct {
+	model  interface{}
+	fields []string
+}{
+	{&models.KitchenAsset{}, []string{"PurchasePrice", "CurrentValue", "SalvageValue", "AccumulatedDepreciation", "DisposalProceeds", "DisposalGainLoss"}},
+	{&models.DepreciationRun{}, []string{"TotalAmount"}},
+	{&models.DepreciationRunLine{}, []string{"Amount", "BookValueBefore", "BookValueAfter"}},
+	{&models.AssetMaintenance{}, []string{"Cost"}},
+	{&models.MaintenancePlan{}, []string{"EstimatedCost"}},
+	{&models.CashFlowEntry{}, []string{"Amount"}},
+	{&models.BudgetTarget{}, []string{"Target", "Actual"}},
+	{&models.BudgetAlert{}, []string{"Actual", "Target"}},
+	{&models.JournalEntry{}, []string{"TotalAmount"}},
+	{&models.JournalLine{}, []string{"Debit", "Credit"}},
+	{&models.SupplierInvoice{}, []string{"Subtotal", "TaxAmount", "TotalAmount", "ReceivedAmount", "PaidAmount"}},
+	{&models.SupplierInvoiceItem{}, []string{"UnitPrice", "Subtotal", "POUnitPrice"}},
+	{&models.SupplierPayment{}, []string{"Amount"}},
+	{&models.FundingTranche{}, []string{"Amount"}},
+	{&models.BankStatement{}, []string{"OpeningBalance", "ClosingBalance", "TotalCredit", "TotalDebit"}},
+	{&models.BankStatementLine{}, []string{"Amount", "Balance"}},
+	{&models.PettyCashFund{}, []string{"FloatAmount", "Balance"}},
+	{&models.PettyCashExpense{}, []string{"Amount"}},
+	{&models.PettyCashReplenishment{}, []string{"Amount"}},
+	{&models.PurchaseOrder{}, []string{"TotalAmount"}},
+	{&models.PurchaseOrderItem{}, []string{"UnitPrice", "Subtotal"}},
+}
+
+// MigrateMoneyToMinorUnits converts monetary columns still holding floating
+// point rupiah to integer sen. It must run before AutoMigrate and is a no-op
+// for columns that are already integers, so it is safe on every start.
+func MigrateMoneyToMinorUnits(db *gorm.DB) error {
+	for _, entry := range moneyColumns {
+		if !db.Migrator().HasTable(entry.model) {
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(entry.model); err != nil {
+			return err
+		}
+		columnTypes, err := db.Migrator().ColumnTypes(entry.model)
+		if err != nil {
+			return err
+		}
+		types := make(map[string]string, len(columnTypes))
+		for _, columnType := range columnTypes {
+			types[columnType.Name()] = strings.ToLower(columnType.DatabaseTypeName())
+		}
+
+		for _, name := range entry.fields {
+			field := stmt.Schema.LookUpField(name)
+			if field == nil {
+				return fmt.Errorf("kolom uang %s.%s tidak ditemukan", stmt.Schema.Table, name)
+			}
+			if !isFloatColumnType(types[field.DBName]) {
+				continue
+			}
+
+			log.Printf("Converting %s.%s to integer sen...", stmt.Table, field.DBName)
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				return convertMoneyColumn(tx, entry.model, stmt.Table, field.Name, field.DBName)
+			}); err != nil {
+				return fmt.Errorf("konversi %s.%s gagal: %w", stmt.Table, field.DBName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// convertMoneyColumn multiplies a column by 100 and changes its type to an
+// integer in one step, so a failure leaves the rupiah values untouched
+func convertMoneyColumn(tx *gorm.DB, model interface{}, table, fieldName, column string) error {
+	if tx.Dialector.Name() == "postgres" {
+		return tx.Exec("ALTER TABLE ? ALTER COLUMN ? TYPE bigint USING ROUND(? * 100)::bigint",
+			clause.Table{Name: table}, clause.Column{Name: column}, clause.Column{Name: column}).Error
+	}
+
+	if err := tx.Exec("UPDATE ? SET ? = CAST(ROUND(? * 100) AS INTEGER) WHERE ? IS NOT NULL",
+		clause.Table{Name: table}, clause.Column{Name: column}, clause.Column{Name: column}, clause.Column{Name: column}).Error; err != nil {
+		return err
+	}
+	return tx.Migrator().AlterColumn(model, fieldName)
+}
+
+// isFloatColumnType reports whether a database column type holds fractional numbers
+func isFloatColumnType(databaseType string) bool {
+	switch databaseType {
+	case "real", "float", "float4", "float8", "double", "double precision", "numeric", "decimal":
+		return true
+	}
+	return false
+}
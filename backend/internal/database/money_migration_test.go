@@ -0,0 +1,75 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// legacyCashFlowEntry is the cash flow table as it was with float rupiah amounts
+type legacyCashFlowEntry struct {
+	ID            uint      `gorm:"primaryKey"`
+	TransactionID string    `gorm:"uniqueIndex;size:50;not null"`
+	Date          time.Time `gorm:"index;not null"`
+	Category      string    `gorm:"size:50;not null;index"`
+	Type          string    `gorm:"size:20;not null;index"`
+	Amount        float64   `gorm:"not null"`
+	CreatedBy     uint      `gorm:"not null;index"`
+}
+
+func (legacyCashFlowEntry) TableName() string { return "cash_flow_entries" }
+
+// legacyJournalLine is the journal line table as it was with float rupiah amounts
+type legacyJournalLine struct {
+	ID             uint    `gorm:"primaryKey"`
+	JournalEntryID uint    `gorm:"index;not null"`
+	AccountID      uint    `gorm:"index;not null"`
+	Debit          float64 `gorm:"not null;default:0"`
+	Credit         float64 `gorm:"not null;default:0"`
+}
+
+func (legacyJournalLine) TableName() string { return "journal_lines" }
+
+func TestMigrateMoneyToMinorUnits(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "money.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&legacyCashFlowEntry{}, &legacyJournalLine{}))
+
+	amounts := []float64{1500000, 1234.56, 0.1 + 0.2, 99.995}
+	for i, amount := range amounts {
+		require.NoError(t, db.Create(&legacyCashFlowEntry{
+			TransactionID: string(rune('A' + i)), Date: time.Now(), Category: "operasional", Type: "expense", Amount: amount, CreatedBy: 1,
+		}).Error)
+	}
+	require.NoError(t, db.Create(&legacyJournalLine{JournalEntryID: 1, AccountID: 1, Debit: 2500.5}).Error)
+	require.NoError(t, db.Create(&legacyJournalLine{JournalEntryID: 1, AccountID: 2, Credit: 2500.5}).Error)
+
+	require.NoError(t, MigrateMoneyToMinorUnits(db))
+	require.NoError(t, db.AutoMigrate(&models.CashFlowEntry{}, &models.JournalLine{}))
+
+	var entries []models.CashFlowEntry
+	require.NoError(t, db.Order("id").Find(&entries).Error)
+	require.Len(t, entries, 4)
+	assert.Equal(t, models.Rupiah(1500000), entries[0].Amount)
+	assert.Equal(t, models.Money(123456), entries[1].Amount)
+	assert.Equal(t, models.Money(30), entries[2].Amount)
+	assert.Equal(t, models.Money(10000), entries[3].Amount)
+
+	var lines []models.JournalLine
+	require.NoError(t, db.Order("id").Find(&lines).Error)
+	require.Len(t, lines, 2)
+	assert.Equal(t, models.Money(250050), lines[0].Debit)
+	assert.Equal(t, models.Money(0), lines[0].Credit)
+	assert.Equal(t, models.Money(250050), lines[1].Credit)
+
+	// Converted columns are integers now, so running again changes nothing
+	require.NoError(t, MigrateMoneyToMinorUnits(db))
+	require.NoError(t, db.Order("id").Find(&entries).Error)
+	assert.Equal(t, models.Money(123456), entries[1].Amount)
+}
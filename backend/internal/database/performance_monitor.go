@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -10,6 +11,10 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrStatisticsUnavailable is returned for statistics that only PostgreSQL
+// collects (pg_stat_*), e.g. when running on SQLite
+var ErrStatisticsUnavailable = errors.New("statistik database hanya tersedia di PostgreSQL")
+
 // PerformanceMonitor monitors database performance metrics
 type PerformanceMonitor struct {
 	db *gorm.DB
@@ -20,6 +25,13 @@ func NewPerformanceMonitor(db *gorm.DB) *PerformanceMonitor {
 	return &PerformanceMonitor{db: db}
 }
 
+// Enabled reports whether the database collects the statistics the monitor
+// reads. Only PostgreSQL does; on other databases only the connection pool
+// stats are available.
+func (pm *PerformanceMonitor) Enabled() bool {
+	return pm.db.Dialector.Name() == "postgres"
+}
+
 // ConnectionStats represents database connection statistics
 type ConnectionStats struct {
 	MaxOpenConnections int `json:"max_open_connections"`
@@ -64,6 +76,10 @@ func (pm *PerformanceMonitor) GetConnectionStats() (*ConnectionStats, error) {
 
 // GetSlowQueries returns queries that are running longer than threshold
 func (pm *PerformanceMonitor) GetSlowQueries(thresholdSeconds int) ([]map[string]interface{}, error) {
+	if !pm.Enabled() {
+		return nil, ErrStatisticsUnavailable
+	}
+
 	var slowQueries []map[string]interface{}
 	
 	query := `
@@ -107,6 +123,10 @@ func (pm *PerformanceMonitor) GetSlowQueries(thresholdSeconds int) ([]map[string
 
 // GetTableStats returns statistics for all tables
 func (pm *PerformanceMonitor) GetTableStats() ([]TableStats, error) {
+	if !pm.Enabled() {
+		return nil, ErrStatisticsUnavailable
+	}
+
 	var stats []TableStats
 	
 	query := `
@@ -155,6 +175,10 @@ func (pm *PerformanceMonitor) GetTableStats() ([]TableStats, error) {
 
 // GetCacheHitRatio returns the database cache hit ratio
 func (pm *PerformanceMonitor) GetCacheHitRatio() (float64, error) {
+	if !pm.Enabled() {
+		return 0, ErrStatisticsUnavailable
+	}
+
 	var cacheHitRatio float64
 	
 	query := `
@@ -173,6 +197,10 @@ func (pm *PerformanceMonitor) GetCacheHitRatio() (float64, error) {
 
 // GetIndexUsage returns index usage statistics
 func (pm *PerformanceMonitor) GetIndexUsage() ([]map[string]interface{}, error) {
+	if !pm.Enabled() {
+		return nil, ErrStatisticsUnavailable
+	}
+
 	var indexStats []map[string]interface{}
 	
 	query := `
@@ -216,6 +244,10 @@ func (pm *PerformanceMonitor) GetIndexUsage() ([]map[string]interface{}, error)
 
 // GetUnusedIndexes returns indexes that are not being used
 func (pm *PerformanceMonitor) GetUnusedIndexes() ([]map[string]interface{}, error) {
+	if !pm.Enabled() {
+		return nil, ErrStatisticsUnavailable
+	}
+
 	var unusedIndexes []map[string]interface{}
 	
 	query := `
@@ -273,6 +305,10 @@ func (pm *PerformanceMonitor) VacuumTables() error {
 
 // GetLockingQueries returns queries that are causing locks
 func (pm *PerformanceMonitor) GetLockingQueries() ([]map[string]interface{}, error) {
+	if !pm.Enabled() {
+		return nil, ErrStatisticsUnavailable
+	}
+
 	var lockingQueries []map[string]interface{}
 	
 	query := `
@@ -329,6 +365,11 @@ func (pm *PerformanceMonitor) GetLockingQueries() ([]map[string]interface{}, err
 
 // StartPerformanceMonitoring starts a background goroutine to monitor performance
 func (pm *PerformanceMonitor) StartPerformanceMonitoring(ctx context.Context, interval time.Duration) {
+	if !pm.Enabled() {
+		log.Printf("Performance monitoring disabled: not supported on %s", pm.db.Dialector.Name())
+		return
+	}
+
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	
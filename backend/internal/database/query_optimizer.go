@@ -47,12 +47,13 @@ func (qo *QueryOptimizer) GetMenuPlanWithItems(menuPlanID uint) (*models.MenuPla
 // GetDeliveryTasksWithDetails fetches delivery tasks with all related data
 func (qo *QueryOptimizer) GetDeliveryTasksWithDetails(driverID uint, date time.Time) ([]models.DeliveryTask, error) {
 	var tasks []models.DeliveryTask
+	dayStart, dayEnd := localDayRange(date)
 	
 	err := qo.db.
 		Preload("School").
 		Preload("Driver").
 		Preload("MenuItems.Recipe").
-		Where("driver_id = ? AND DATE(task_date) = DATE(?)", driverID, date).
+		Where("driver_id = ? AND task_date >= ? AND task_date < ?", driverID, dayStart, dayEnd).
 		Order("route_order ASC").
 		Find(&tasks).Error
 	
@@ -232,20 +233,21 @@ func (qo *QueryOptimizer) GetNotificationsForUser(userID uint, limit, offset int
 // GetDashboardData fetches aggregated data for dashboard in optimized queries
 func (qo *QueryOptimizer) GetDashboardData(date time.Time) (map[string]interface{}, error) {
 	data := make(map[string]interface{})
+	dayStart, dayEnd := localDayRange(date)
 	
 	// Get today's menu items count
 	var menuItemsCount int64
-	qo.db.Model(&models.MenuItem{}).Where("DATE(date) = DATE(?)", date).Count(&menuItemsCount)
+	qo.db.Model(&models.MenuItem{}).Where("date >= ? AND date < ?", dayStart, dayEnd).Count(&menuItemsCount)
 	data["menu_items_count"] = menuItemsCount
 	
 	// Get pending delivery tasks count
 	var pendingDeliveries int64
-	qo.db.Model(&models.DeliveryTask{}).Where("DATE(task_date) = DATE(?) AND status = 'pending'", date).Count(&pendingDeliveries)
+	qo.db.Model(&models.DeliveryTask{}).Where("task_date >= ? AND task_date < ? AND status = 'pending'", dayStart, dayEnd).Count(&pendingDeliveries)
 	data["pending_deliveries"] = pendingDeliveries
 	
 	// Get completed delivery tasks count
 	var completedDeliveries int64
-	qo.db.Model(&models.DeliveryTask{}).Where("DATE(task_date) = DATE(?) AND status = 'completed'", date).Count(&completedDeliveries)
+	qo.db.Model(&models.DeliveryTask{}).Where("task_date >= ? AND task_date < ? AND status = 'completed'", dayStart, dayEnd).Count(&completedDeliveries)
 	data["completed_deliveries"] = completedDeliveries
 	
 	// Get low stock items count
@@ -333,4 +335,11 @@ func (qo *QueryOptimizer) GetSupplierPerformanceData(supplierID uint) (map[strin
 	}
 	
 	return data, nil
-}
\ No newline at end of file
+}
+
+// localDayRange returns the local midnights that start the calendar day of t
+// and the day after it
+func localDayRange(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	return start, start.AddDate(0, 0, 1)
+}
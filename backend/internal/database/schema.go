@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// statementRecorder is a GORM logger that keeps the SQL of every statement
+type statementRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *statementRecorder) LogMode(logger.LogLevel) logger.Interface { return r }
+
+func (r *statementRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// take returns the statements recorded since the last call
+func (r *statementRecorder) take() []string {
+	statements := r.statements
+	r.statements = nil
+	return statements
+}
+
+// SchemaSQL returns the statements that create the tables of the given models
+// on an empty database, and the statements that drop them again. Nothing is
+// executed: GORM runs in dry-run mode and the SQL is recorded, so the dialector
+// does not need a reachable database.
+//
+// Tables come first, each followed by its indexes, and the foreign keys last,
+// so the order of the models does not matter. SQLite cannot add constraints to
+// an existing table, so there the foreign keys are part of CREATE TABLE; it
+// does not check that the referenced table exists yet. The output is sorted to
+// stay stable between runs.
+func SchemaSQL(dialector gorm.Dialector, models ...interface{}) (up, down []string, err error) {
+	inlineForeignKeys := dialector.Name() == "sqlite"
+	recorder := &statementRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		DryRun:                                   true,
+		DisableAutomaticPing:                     true,
+		DisableForeignKeyConstraintWhenMigrating: !inlineForeignKeys,
+		Logger:                                   recorder,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		constraints = make(map[string]string)
+		tables      []string
+	)
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, nil, err
+		}
+
+		if err := db.Migrator().CreateTable(model); err != nil {
+			return nil, nil, fmt.Errorf("tabel %s: %w", stmt.Table, err)
+		}
+		statements := recorder.take()
+		if len(statements) == 0 {
+			return nil, nil, fmt.Errorf("tabel %s tidak menghasilkan SQL", stmt.Table)
+		}
+		// The constraints and indexes of a table come from maps; sort them
+		statements[0] = sortTableConstraints(statements[0])
+		sort.Strings(statements[1:])
+		up = append(up, statements...)
+		tables = append(tables, stmt.Table)
+		if inlineForeignKeys {
+			continue
+		}
+
+		// The same constraints CreateTable adds when foreign keys are enabled
+		for _, rel := range stmt.Schema.Relationships.Relations {
+			if rel.Field.IgnoreMigration {
+				continue
+			}
+			constraint := rel.ParseConstraint()
+			if constraint == nil || constraint.Schema != stmt.Schema {
+				continue
+			}
+			sql, vars := constraint.Build()
+			if err := db.Exec("ALTER TABLE ? ADD "+sql, append([]interface{}{clause.Table{Name: stmt.Table}}, vars...)...).Error; err != nil {
+				return nil, nil, fmt.Errorf("constraint %s: %w", constraint.Name, err)
+			}
+			constraints[stmt.Table+"."+constraint.Name] = recorder.take()[0]
+		}
+	}
+
+	keys := make([]string, 0, len(constraints))
+	for key := range constraints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		up = append(up, constraints[key])
+	}
+
+	for i := len(tables) - 1; i >= 0; i-- {
+		if err := db.Migrator().DropTable(tables[i]); err != nil {
+			return nil, nil, err
+		}
+		for _, statement := range recorder.take() {
+			// SQLite wraps drops in foreign key pragmas; only the drop is needed
+			if strings.HasPrefix(statement, "DROP ") {
+				down = append(down, statement)
+			}
+		}
+	}
+	return up, down, nil
+}
+
+// sortTableConstraints sorts the CONSTRAINT clauses at the end of a CREATE
+// TABLE statement
+func sortTableConstraints(createTable string) string {
+	const separator = ",CONSTRAINT "
+	start := strings.Index(createTable, separator)
+	if start < 0 || !strings.HasSuffix(createTable, ")") {
+		return createTable
+	}
+	constraints := strings.Split(createTable[start+len(separator):len(createTable)-1], separator)
+	sort.Strings(constraints)
+	return createTable[:start] + separator + strings.Join(constraints, separator) + ")"
+}
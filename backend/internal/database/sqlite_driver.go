@@ -0,0 +1,69 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// sqliteDriverName is the SQLite driver used by the server and the tests
+const sqliteDriverName = "sqlite3_local"
+
+func init() {
+	sql.Register(sqliteDriverName, &localSQLiteDriver{})
+}
+
+// SQLiteDialector returns the GORM dialector of a SQLite database that stores
+// and reads every time in the local zone
+func SQLiteDialector(dsn string) gorm.Dialector {
+	return sqlite.New(sqlite.Config{DriverName: sqliteDriverName, DSN: dsn})
+}
+
+// localSQLiteDriver keeps every time as text in the local zone. SQLite compares
+// times as strings, so values written with different zone offsets would not
+// sort by instant and a filter like "delivery_date >= ? AND delivery_date < ?"
+// would miss rows. The local zone of a server (Asia/Jakarta) has no daylight
+// saving, so its offset never changes.
+type localSQLiteDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+// Open opens the connection with _loc=auto so times are read in local time
+func (d *localSQLiteDriver) Open(dsn string) (driver.Conn, error) {
+	if !strings.Contains(dsn, "_loc=") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + "_loc=auto"
+	}
+
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &localSQLiteConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+type localSQLiteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+// CheckNamedValue converts arguments like database/sql does by default and
+// moves times to the local zone
+func (c *localSQLiteConn) CheckNamedValue(nv *driver.NamedValue) error {
+	value, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+	if t, ok := value.(time.Time); ok {
+		value = t.In(time.Local)
+	}
+	nv.Value = value
+	return nil
+}
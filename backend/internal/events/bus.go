@@ -0,0 +1,243 @@
+// Package events is an in-process publish/subscribe bus for domain events.
+// Services publish an event with the change it describes; other modules
+// subscribe at startup to react to it.
+//
+// A subscriber chooses how it is delivered:
+//   - Subscribe runs the handler in the publishing goroutine, before Publish
+//     returns, for reactions that must be visible to the caller
+//   - SubscribeAsync runs the handler in its own goroutine
+//   - SubscribeDurable saves the event in the Store (the outbox) and the
+//     handler runs from there, retried until it succeeds
+//
+// A change made in a transaction hands the event to PublishTx inside the
+// transaction, so the event is saved for durable subscribers exactly when
+// the change commits, and to Notify once it is committed.
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Event is a domain event published by a service
+type Event interface {
+	// EventName identifies the event type, e.g. "stock.changed"
+	EventName() string
+}
+
+// Handler reacts to a published event
+type Handler func(Event)
+
+// DurableHandler reacts to an event delivered from the Store. Returning an
+// error schedules a retry.
+type DurableHandler func(ctx context.Context, event Event) error
+
+// Store persists events for durable subscribers so they survive a restart
+// and are retried on failure. Save writes through tx, or through the store's
+// own connection when tx is nil. The store calls Bus.Deliver to run the
+// subscriber.
+type Store interface {
+	Save(tx *gorm.DB, subscriber string, event Event) error
+}
+
+type subscription struct {
+	handler Handler
+	async   bool
+}
+
+type durableSubscription struct {
+	subscriber string
+	handler    DurableHandler
+}
+
+// Bus delivers published events to the handlers subscribed to their name
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]subscription
+	durable  map[string][]durableSubscription
+	store    Store
+	pending  sync.WaitGroup
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[string][]subscription),
+		durable:  make(map[string][]durableSubscription),
+	}
+}
+
+// Subscribe registers a handler that runs synchronously when the named
+// event is published
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], subscription{handler: handler})
+}
+
+// SubscribeAsync registers a handler that runs in its own goroutine, so a
+// slow handler does not hold up the publisher
+func (b *Bus) SubscribeAsync(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], subscription{handler: handler, async: true})
+}
+
+// SubscribeDurable registers a handler that receives the named event through
+// the store. The subscriber name identifies the handler in the store and must
+// be unique per event name.
+func (b *Bus) SubscribeDurable(name, subscriber string, handler DurableHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.durable[name] = append(b.durable[name], durableSubscription{subscriber: subscriber, handler: handler})
+}
+
+// SetStore sets where events for durable subscribers are saved. Without a
+// store durable handlers run synchronously and are not retried.
+func (b *Bus) SetStore(store Store) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.store = store
+}
+
+// Publish delivers the event to its handlers in the order they subscribed
+// and saves it for the durable subscribers. Use it for changes that are not
+// made in a transaction; see PublishTx for the others.
+func (b *Bus) Publish(event Event) {
+	b.Notify(event)
+	if err := b.PublishTx(nil, event); err != nil {
+		log.Printf("[EVENTS] %v", err)
+	}
+}
+
+// PublishTx saves the event for the durable subscribers through tx, the
+// transaction of the change the event describes. An error should roll the
+// transaction back. The caller passes the event to Notify after the commit.
+func (b *Bus) PublishTx(tx *gorm.DB, event Event) error {
+	b.mu.RLock()
+	durable := b.durable[event.EventName()]
+	store := b.store
+	b.mu.RUnlock()
+
+	if store == nil {
+		return nil
+	}
+	for _, sub := range durable {
+		if err := store.Save(tx, sub.subscriber, event); err != nil {
+			return fmt.Errorf("failed to save %s for %s: %w", event.EventName(), sub.subscriber, err)
+		}
+	}
+	return nil
+}
+
+// Notify delivers the event to its synchronous and asynchronous handlers in
+// the order they subscribed. Without a store the durable handlers run here
+// too. A panicking handler is logged and does not affect the publisher or
+// the other handlers.
+func (b *Bus) Notify(event Event) {
+	b.mu.RLock()
+	handlers := b.handlers[event.EventName()]
+	durable := b.durable[event.EventName()]
+	store := b.store
+	b.mu.RUnlock()
+
+	for _, sub := range handlers {
+		if sub.async {
+			b.pending.Add(1)
+			go func(handler Handler) {
+				defer b.pending.Done()
+				dispatch(handler, event)
+			}(sub.handler)
+			continue
+		}
+		dispatch(sub.handler, event)
+	}
+
+	if store != nil {
+		return
+	}
+	for _, sub := range durable {
+		if err := runDurable(context.Background(), sub.handler, event); err != nil {
+			log.Printf("[EVENTS] %s failed to handle %s: %v", sub.subscriber, event.EventName(), err)
+		}
+	}
+}
+
+// Deliver runs the durable subscriber for an event loaded from the store
+func (b *Bus) Deliver(ctx context.Context, subscriber string, event Event) error {
+	b.mu.RLock()
+	durable := b.durable[event.EventName()]
+	b.mu.RUnlock()
+
+	for _, sub := range durable {
+		if sub.subscriber == subscriber {
+			return runDurable(ctx, sub.handler, event)
+		}
+	}
+	return fmt.Errorf("subscriber %s untuk event %s belum terdaftar", subscriber, event.EventName())
+}
+
+// Wait blocks until the asynchronous handlers started so far have finished
+func (b *Bus) Wait() {
+	b.pending.Wait()
+}
+
+func dispatch(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[EVENTS] handler for %s panicked: %v", event.EventName(), r)
+		}
+	}()
+	handler(event)
+}
+
+// runDurable turns a panicking durable handler into a failed delivery
+func runDurable(ctx context.Context, handler DurableHandler, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// defaultBus is the bus services publish to
+var defaultBus = NewBus()
+
+// Default returns the application-wide bus
+func Default() *Bus {
+	return defaultBus
+}
+
+// Publish publishes the event on the application-wide bus
+func Publish(event Event) {
+	defaultBus.Publish(event)
+}
+
+// PublishTx saves the events for the durable subscribers of the
+// application-wide bus within tx
+func PublishTx(tx *gorm.DB, events ...Event) error {
+	for _, event := range events {
+		if err := defaultBus.PublishTx(tx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Notify delivers the events to the in-memory handlers of the
+// application-wide bus
+func Notify(events ...Event) {
+	for _, event := range events {
+		defaultBus.Notify(event)
+	}
+}
+
+// Subscribe registers a synchronous handler on the application-wide bus
+func Subscribe(name string, handler Handler) {
+	defaultBus.Subscribe(name, handler)
+}
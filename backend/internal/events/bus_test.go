@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestBus_DeliversToSubscribersInOrder(t *testing.T) {
+	bus := NewBus()
+	var received []string
+
+	bus.Subscribe(StockChangedEvent, func(e Event) {
+		received = append(received, "first:"+e.(StockChanged).Reason)
+	})
+	bus.Subscribe(StockChangedEvent, func(e Event) {
+		received = append(received, "second:"+e.(StockChanged).Reason)
+	})
+	bus.Subscribe(MenuApprovedEvent, func(e Event) {
+		received = append(received, "menu")
+	})
+
+	bus.Publish(StockChanged{Reason: "in"})
+
+	assert.Equal(t, []string{"first:in", "second:in"}, received)
+}
+
+func TestBus_PanickingHandlerDoesNotStopOthers(t *testing.T) {
+	bus := NewBus()
+	delivered := false
+
+	bus.Subscribe(MenuApprovedEvent, func(Event) { panic("boom") })
+	bus.Subscribe(MenuApprovedEvent, func(Event) { delivered = true })
+
+	assert.NotPanics(t, func() { bus.Publish(MenuApproved{MenuPlanID: 1}) })
+	assert.True(t, delivered)
+}
+
+func TestBus_AsyncHandlersRunInTheBackground(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	done := false
+
+	bus.SubscribeAsync(CookingStartedEvent, func(Event) {
+		<-release
+		done = true
+	})
+
+	// Publish returns while the handler is still blocked
+	bus.Publish(CookingStarted{MenuItemID: 1})
+	close(release)
+	bus.Wait()
+
+	assert.True(t, done)
+}
+
+type memoryStore struct {
+	saved  []string
+	events []Event
+	txs    []*gorm.DB
+}
+
+func (s *memoryStore) Save(tx *gorm.DB, subscriber string, event Event) error {
+	s.saved = append(s.saved, subscriber)
+	s.events = append(s.events, event)
+	s.txs = append(s.txs, tx)
+	return nil
+}
+
+func TestBus_DurableHandlersAreDeliveredFromTheStore(t *testing.T) {
+	bus := NewBus()
+	var received []StokOpnameApproved
+	bus.SubscribeDurable(StokOpnameApprovedEvent, "notifications", func(_ context.Context, e Event) error {
+		received = append(received, e.(StokOpnameApproved))
+		return nil
+	})
+
+	// Without a store the handler runs right away
+	bus.Publish(StokOpnameApproved{FormID: 1})
+	require.Len(t, received, 1)
+
+	store := &memoryStore{}
+	bus.SetStore(store)
+	bus.Publish(StokOpnameApproved{FormID: 2, FormNumber: "SO-002"})
+	assert.Len(t, received, 1, "the store delivers the event later")
+	require.Equal(t, []string{"notifications"}, store.saved)
+
+	// The stored JSON decodes to the same event type
+	data, err := json.Marshal(store.events[0])
+	require.NoError(t, err)
+	decoded, err := Decode(StokOpnameApprovedEvent, data)
+	require.NoError(t, err)
+	require.NoError(t, bus.Deliver(context.Background(), "notifications", decoded))
+	require.Len(t, received, 2)
+	assert.Equal(t, "SO-002", received[1].FormNumber)
+
+	assert.Error(t, bus.Deliver(context.Background(), "webhooks", decoded))
+	_, err = Decode("unknown.event", data)
+	assert.Error(t, err)
+}
+
+func TestBus_PublishTxSavesDurableEventsInTheTransaction(t *testing.T) {
+	bus := NewBus()
+	store := &memoryStore{}
+	bus.SetStore(store)
+
+	var synced, durable int
+	bus.Subscribe(DeliveryCompletedEvent, func(Event) { synced++ })
+	bus.SubscribeDurable(DeliveryCompletedEvent, "webhooks", func(context.Context, Event) error {
+		durable++
+		return nil
+	})
+
+	tx := &gorm.DB{}
+	require.NoError(t, bus.PublishTx(tx, DeliveryCompleted{DeliveryRecordID: 1}))
+	assert.Equal(t, []string{"webhooks"}, store.saved)
+	assert.Same(t, tx, store.txs[0])
+	assert.Zero(t, synced, "in-memory handlers wait for the commit")
+
+	// After the commit Notify only runs the in-memory handlers
+	bus.Notify(DeliveryCompleted{DeliveryRecordID: 1})
+	assert.Equal(t, 1, synced)
+	assert.Len(t, store.saved, 1, "the event is not saved twice")
+	assert.Zero(t, durable)
+}
@@ -0,0 +1,219 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event names
+const (
+	StockChangedEvent          = "stock.changed"
+	StockBelowThresholdEvent   = "stock.below_threshold"
+	MenuApprovedEvent          = "menu.approved"
+	RecipeChangedEvent         = "recipe.changed"
+	SupplierChangedEvent       = "supplier.changed"
+	PurchaseOrderApprovedEvent = "purchase_order.approved"
+	GoodsReceivedEvent         = "goods.received"
+	StokOpnameApprovedEvent    = "stok_opname.approved"
+	CookingStartedEvent        = "cooking.started"
+	CookingCompletedEvent      = "cooking.completed"
+	PackingStartedEvent        = "packing.started"
+	PackingCompletedEvent      = "packing.completed"
+	DeliveryArrivedEvent       = "delivery.arrived"
+	DeliveryCompletedEvent     = "delivery.completed"
+)
+
+// registry decodes the stored JSON of each event name
+var registry = map[string]func([]byte) (Event, error){
+	StockChangedEvent:          decoder[StockChanged],
+	StockBelowThresholdEvent:   decoder[StockBelowThreshold],
+	MenuApprovedEvent:          decoder[MenuApproved],
+	RecipeChangedEvent:         decoder[RecipeChanged],
+	SupplierChangedEvent:       decoder[SupplierChanged],
+	PurchaseOrderApprovedEvent: decoder[PurchaseOrderApproved],
+	GoodsReceivedEvent:         decoder[GoodsReceived],
+	StokOpnameApprovedEvent:    decoder[StokOpnameApproved],
+	CookingStartedEvent:        decoder[CookingStarted],
+	CookingCompletedEvent:      decoder[CookingCompleted],
+	PackingStartedEvent:        decoder[PackingStarted],
+	PackingCompletedEvent:      decoder[PackingCompleted],
+	DeliveryArrivedEvent:       decoder[DeliveryArrived],
+	DeliveryCompletedEvent:     decoder[DeliveryCompleted],
+}
+
+func decoder[T Event](data []byte) (Event, error) {
+	var event T
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Decode turns the JSON of a stored event back into the typed event, the same
+// value type that was published
+func Decode(name string, data []byte) (Event, error) {
+	decode, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("event %s tidak dikenal", name)
+	}
+	event, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca event %s: %w", name, err)
+	}
+	return event, nil
+}
+
+// StockChanged is published when ingredient stock levels or thresholds change
+type StockChanged struct {
+	IngredientIDs []uint `json:"ingredient_ids"`
+	Reason        string `json:"reason"` // movement type or source, e.g. "in", "stok_opname", "threshold"
+	Reference     string `json:"reference"`
+}
+
+// EventName implements Event
+func (StockChanged) EventName() string { return StockChangedEvent }
+
+// StockBelowThreshold is published when a stock change leaves an ingredient
+// below its minimum threshold
+type StockBelowThreshold struct {
+	IngredientID   uint    `json:"ingredient_id"`
+	IngredientName string  `json:"ingredient_name"`
+	Quantity       float64 `json:"quantity"`
+	MinThreshold   float64 `json:"min_threshold"`
+}
+
+// EventName implements Event
+func (StockBelowThreshold) EventName() string { return StockBelowThresholdEvent }
+
+// MenuApproved is published when a weekly menu plan is approved
+type MenuApproved struct {
+	MenuPlanID uint `json:"menu_plan_id"`
+	ApprovedBy uint `json:"approved_by"`
+}
+
+// EventName implements Event
+func (MenuApproved) EventName() string { return MenuApprovedEvent }
+
+// RecipeChanged is published when a recipe or ingredient master data changes.
+// RecipeID is zero for ingredient changes.
+type RecipeChanged struct {
+	RecipeID uint `json:"recipe_id"`
+}
+
+// EventName implements Event
+func (RecipeChanged) EventName() string { return RecipeChangedEvent }
+
+// SupplierChanged is published when supplier master data or its rating changes
+type SupplierChanged struct {
+	SupplierID uint `json:"supplier_id"`
+}
+
+// EventName implements Event
+func (SupplierChanged) EventName() string { return SupplierChangedEvent }
+
+// PurchaseOrderApproved is published when a purchase order is approved
+type PurchaseOrderApproved struct {
+	PurchaseOrderID uint `json:"purchase_order_id"`
+	SupplierID      uint `json:"supplier_id"`
+	ApprovedBy      uint `json:"approved_by"`
+}
+
+// EventName implements Event
+func (PurchaseOrderApproved) EventName() string { return PurchaseOrderApprovedEvent }
+
+// GoodsReceived is published when a goods receipt has been recorded and its
+// items added to stock
+type GoodsReceived struct {
+	GoodsReceiptID  uint   `json:"goods_receipt_id"`
+	PurchaseOrderID uint   `json:"purchase_order_id"`
+	SupplierID      uint   `json:"supplier_id"`
+	IngredientIDs   []uint `json:"ingredient_ids"`
+}
+
+// EventName implements Event
+func (GoodsReceived) EventName() string { return GoodsReceivedEvent }
+
+// StokOpnameApproved is published when a stok opname form is approved and
+// its stock adjustments applied
+type StokOpnameApproved struct {
+	FormID        uint   `json:"form_id"`
+	FormNumber    string `json:"form_number"`
+	CreatedBy     uint   `json:"created_by"`
+	ApprovedBy    uint   `json:"approved_by"`
+	IngredientIDs []uint `json:"ingredient_ids"` // items whose stock was adjusted
+}
+
+// EventName implements Event
+func (StokOpnameApproved) EventName() string { return StokOpnameApprovedEvent }
+
+// CookingStarted is published when the kitchen starts cooking a menu item
+// and its semi-finished goods have been deducted
+type CookingStarted struct {
+	MenuItemID uint      `json:"menu_item_id"`
+	RecipeID   uint      `json:"recipe_id"`
+	Date       time.Time `json:"date"`
+	UserID     uint      `json:"user_id"`
+}
+
+// EventName implements Event
+func (CookingStarted) EventName() string { return CookingStartedEvent }
+
+// CookingCompleted is published when a menu item is cooked and ready for packing
+type CookingCompleted struct {
+	MenuItemID uint      `json:"menu_item_id"`
+	RecipeID   uint      `json:"recipe_id"`
+	Date       time.Time `json:"date"`
+	UserID     uint      `json:"user_id"`
+}
+
+// EventName implements Event
+func (CookingCompleted) EventName() string { return CookingCompletedEvent }
+
+// PackingStarted is published when packing starts for a school
+type PackingStarted struct {
+	SchoolID uint      `json:"school_id"`
+	Date     time.Time `json:"date"`
+	UserID   uint      `json:"user_id"`
+}
+
+// EventName implements Event
+func (PackingStarted) EventName() string { return PackingStartedEvent }
+
+// PackingCompleted is published when the portions for a school are packed
+// and ready for driver pickup
+type PackingCompleted struct {
+	SchoolID uint      `json:"school_id"`
+	Date     time.Time `json:"date"`
+	UserID   uint      `json:"user_id"`
+}
+
+// EventName implements Event
+func (PackingCompleted) EventName() string { return PackingCompletedEvent }
+
+// DeliveryArrived is published when the driver reports arriving at the school
+type DeliveryArrived struct {
+	DeliveryRecordID uint      `json:"delivery_record_id"`
+	SchoolID         uint      `json:"school_id"`
+	DriverID         *uint     `json:"driver_id,omitempty"`
+	ArrivedAt        time.Time `json:"arrived_at"`
+}
+
+// EventName implements Event
+func (DeliveryArrived) EventName() string { return DeliveryArrivedEvent }
+
+// DeliveryCompleted is published when the school has received a delivery
+// (sudah_diterima_pihak_sekolah); the portions were served to the school
+type DeliveryCompleted struct {
+	DeliveryRecordID uint      `json:"delivery_record_id"`
+	SchoolID         uint      `json:"school_id"`
+	DriverID         *uint     `json:"driver_id,omitempty"`
+	DeliveryDate     time.Time `json:"delivery_date"`
+	Portions         int       `json:"portions"`
+	PortionsSmall    int       `json:"portions_small"`
+	PortionsLarge    int       `json:"portions_large"`
+	ReceivedAt       time.Time `json:"received_at"`
+}
+
+// EventName implements Event
+func (DeliveryCompleted) EventName() string { return DeliveryCompletedEvent }
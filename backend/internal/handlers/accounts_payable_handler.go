@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountsPayableHandler handles supplier invoice, payment and AP aging endpoints
+type AccountsPayableHandler struct {
+	apService *services.AccountsPayableService
+}
+
+// NewAccountsPayableHandler creates a new accounts payable handler
+func NewAccountsPayableHandler(apService *services.AccountsPayableService) *AccountsPayableHandler {
+	return &AccountsPayableHandler{
+		apService: apService,
+	}
+}
+
+// SupplierInvoiceItemRequest represents an invoice line
+type SupplierInvoiceItemRequest struct {
+	IngredientID uint         `json:"ingredient_id" binding:"required"`
+	Quantity     float64      `json:"quantity" binding:"required,gt=0"`
+	UnitPrice    models.Money `json:"unit_price" binding:"gte=0"`
+}
+
+// CreateSupplierInvoiceRequest represents create supplier invoice request
+type CreateSupplierInvoiceRequest struct {
+	GRNID         uint                         `json:"grn_id" binding:"required"`
+	InvoiceNumber string                       `json:"invoice_number" binding:"required,max=100"`
+	InvoiceDate   string                       `json:"invoice_date" binding:"required"`
+	DueDate       string                       `json:"due_date"` // defaults to the supplier payment term
+	TaxAmount     models.Money                 `json:"tax_amount" binding:"gte=0"`
+	InvoicePhoto  string                       `json:"invoice_photo"`
+	Notes         string                       `json:"notes"`
+	Items         []SupplierInvoiceItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// SupplierInvoiceReasonRequest represents an approval or cancellation reason
+type SupplierInvoiceReasonRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RecordSupplierPaymentRequest represents a supplier payment
+type RecordSupplierPaymentRequest struct {
+	Amount        models.Money `json:"amount" binding:"required,gt=0"`
+	PaymentDate   string       `json:"payment_date"`
+	Method        string       `json:"method" binding:"required,oneof=transfer cash"`
+	BankReference string       `json:"bank_reference"`
+	BankAccount   string       `json:"bank_account"`
+	Notes         string       `json:"notes"`
+}
+
+// GetInvoices lists supplier invoices
+func (h *AccountsPayableHandler) GetInvoices(c *gin.Context) {
+	supplierID, _ := strconv.ParseUint(c.Query("supplier_id"), 10, 32)
+
+	invoices, err := h.apService.GetInvoices(uint(supplierID), c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    invoices,
+	})
+}
+
+// GetInvoice returns a supplier invoice with its match result and payments
+func (h *AccountsPayableHandler) GetInvoice(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := h.apService.GetInvoice(id)
+	if err != nil {
+		respondAccountsPayableError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    invoice,
+	})
+}
+
+// CreateInvoice records a supplier invoice and runs the three-way match
+func (h *AccountsPayableHandler) CreateInvoice(c *gin.Context) {
+	var req CreateSupplierInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	invoiceDate, err := time.ParseInLocation("2006-01-02", req.InvoiceDate, time.Local)
+	if err != nil {
+		respondInvalidAPDate(c, "invoice_date")
+		return
+	}
+	var dueDate time.Time
+	if req.DueDate != "" {
+		if dueDate, err = time.ParseInLocation("2006-01-02", req.DueDate, time.Local); err != nil {
+			respondInvalidAPDate(c, "due_date")
+			return
+		}
+	}
+
+	userID, _ := c.Get("user_id")
+	invoice := &models.SupplierInvoice{
+		GRNID:         req.GRNID,
+		InvoiceNumber: req.InvoiceNumber,
+		InvoiceDate:   invoiceDate,
+		DueDate:       dueDate,
+		TaxAmount:     req.TaxAmount,
+		InvoicePhoto:  req.InvoicePhoto,
+		Notes:         req.Notes,
+	}
+	for _, item := range req.Items {
+		invoice.Items = append(invoice.Items, models.SupplierInvoiceItem{
+			IngredientID: item.IngredientID,
+			Quantity:     item.Quantity,
+			UnitPrice:    item.UnitPrice,
+		})
+	}
+
+	if err := h.apService.CreateInvoice(invoice, userID.(uint)); err != nil {
+		respondAccountsPayableError(c, err)
+		return
+	}
+
+	message := "Faktur supplier berhasil dicatat dan sesuai dengan PO dan penerimaan barang"
+	if invoice.Status == services.InvoiceStatusOnHold {
+		message = "Faktur supplier dicatat namun ditahan karena tidak sesuai dengan PO atau penerimaan barang"
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": message,
+		"data":    invoice,
+	})
+}
+
+// ApproveInvoice releases an invoice on hold for payment
+func (h *AccountsPayableHandler) ApproveInvoice(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req SupplierInvoiceReasonRequest
+	_ = c.ShouldBindJSON(&req)
+
+	userID, _ := c.Get("user_id")
+	invoice, err := h.apService.ApproveInvoice(id, req.Reason, userID.(uint))
+	if err != nil {
+		respondAccountsPayableError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Faktur supplier disetujui untuk dibayar",
+		"data":    invoice,
+	})
+}
+
+// CancelInvoice cancels an unpaid invoice
+func (h *AccountsPayableHandler) CancelInvoice(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req SupplierInvoiceReasonRequest
+	_ = c.ShouldBindJSON(&req)
+
+	userID, _ := c.Get("user_id")
+	if err := h.apService.CancelInvoice(id, req.Reason, userID.(uint)); err != nil {
+		respondAccountsPayableError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Faktur supplier berhasil dibatalkan",
+	})
+}
+
+// RecordPayment records a full or partial payment of an invoice
+func (h *AccountsPayableHandler) RecordPayment(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req RecordSupplierPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	var paymentDate time.Time
+	if req.PaymentDate != "" {
+		var err error
+		if paymentDate, err = time.ParseInLocation("2006-01-02", req.PaymentDate, time.Local); err != nil {
+			respondInvalidAPDate(c, "payment_date")
+			return
+		}
+	}
+
+	userID, _ := c.Get("user_id")
+	payment := &models.SupplierPayment{
+		Amount:        req.Amount,
+		PaymentDate:   paymentDate,
+		Method:        req.Method,
+		BankReference: req.BankReference,
+		BankAccount:   req.BankAccount,
+		Notes:         req.Notes,
+	}
+	if err := h.apService.RecordPayment(id, payment, userID.(uint)); err != nil {
+		respondAccountsPayableError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Pembayaran supplier berhasil dicatat",
+		"data":    payment,
+	})
+}
+
+// GetPayments lists supplier payments
+func (h *AccountsPayableHandler) GetPayments(c *gin.Context) {
+	supplierID, _ := strconv.ParseUint(c.Query("supplier_id"), 10, 32)
+
+	var startDate, endDate *time.Time
+	if startStr := c.Query("start_date"); startStr != "" {
+		if sd, err := time.ParseInLocation("2006-01-02", startStr, time.Local); err == nil {
+			startDate = &sd
+		}
+	}
+	if endStr := c.Query("end_date"); endStr != "" {
+		if ed, err := time.ParseInLocation("2006-01-02", endStr, time.Local); err == nil {
+			endDate = &ed
+		}
+	}
+
+	payments, err := h.apService.GetPayments(uint(supplierID), startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    payments,
+	})
+}
+
+// GetAgingReport returns the accounts payable aging per supplier
+func (h *AccountsPayableHandler) GetAgingReport(c *gin.Context) {
+	asOf, ok := parseLedgerDate(c, "as_of", time.Now())
+	if !ok {
+		return
+	}
+
+	report, err := h.apService.GetAgingReport(asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// respondInvalidAPDate reports a malformed date field
+func respondInvalidAPDate(c *gin.Context, field string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"success":    false,
+		"error_code": "INVALID_DATE",
+		"message":    "Format " + field + " tidak valid (gunakan YYYY-MM-DD)",
+	})
+}
+
+// respondAccountsPayableError maps accounts payable service errors to HTTP responses
+func respondAccountsPayableError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrSupplierInvoiceNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "SUPPLIER_INVOICE_NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case services.ErrGRNNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "GRN_NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case services.ErrDuplicateSupplierInvoice, services.ErrGRNAlreadyInvoiced:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "DUPLICATE_SUPPLIER_INVOICE",
+			"message":    err.Error(),
+		})
+	case services.ErrInvoiceNotOnHold, services.ErrInvoiceNotPayable, services.ErrInvoiceHasPayments, services.ErrInvoiceAlreadyCancelled:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "INVALID_STATE",
+			"message":    err.Error(),
+		})
+	case services.ErrInvoiceWithoutItems, services.ErrInvalidInvoiceAmount, services.ErrInvalidInvoiceDueDate,
+		services.ErrApprovalReasonRequired, services.ErrInvalidPaymentAmount, services.ErrInvalidPaymentMethod,
+		services.ErrBankReferenceRequired:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
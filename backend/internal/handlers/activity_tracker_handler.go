@@ -1,23 +1,28 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/erp-sppg/backend/internal/services"
+	"github.com/erp-sppg/backend/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
 // ActivityTrackerHandler handles HTTP requests for Activity Tracker
 type ActivityTrackerHandler struct {
-	service *services.ActivityTrackerService
+	service  *services.ActivityTrackerService
+	uploader *storage.Uploader
 }
 
 // NewActivityTrackerHandler creates a new ActivityTrackerHandler instance
-func NewActivityTrackerHandler(service *services.ActivityTrackerService) *ActivityTrackerHandler {
+func NewActivityTrackerHandler(service *services.ActivityTrackerService, uploader *storage.Uploader) *ActivityTrackerHandler {
 	return &ActivityTrackerHandler{
-		service: service,
+		service:  service,
+		uploader: uploader,
 	}
 }
 
@@ -227,10 +232,6 @@ func (h *ActivityTrackerHandler) AttachStageMedia(c *gin.Context) {
 		return
 	}
 	
-	// Check file size limits (10MB for photos, 50MB for videos)
-	const maxPhotoSize = 10 * 1024 * 1024  // 10MB
-	const maxVideoSize = 50 * 1024 * 1024  // 50MB
-	
 	// Get media type
 	mediaType := c.PostForm("media_type")
 	if mediaType != "photo" && mediaType != "video" {
@@ -241,31 +242,39 @@ func (h *ActivityTrackerHandler) AttachStageMedia(c *gin.Context) {
 		return
 	}
 	
-	// Validate file size based on media type
-	if mediaType == "photo" && file.Size > maxPhotoSize {
-		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
-			"success": false,
-			"error":   "photo file size exceeds 10MB limit",
-		})
-		return
+	// Photos are limited to 10MB and videos to 50MB by their upload category
+	category := storage.CategoryStagePhoto
+	if mediaType == "video" {
+		category = storage.CategoryStageVideo
 	}
-	
-	if mediaType == "video" && file.Size > maxVideoSize {
-		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
-			"success": false,
-			"error":   "video file size exceeds 50MB limit",
-		})
+	stored, err := h.uploader.SaveMultipart(c.Request.Context(), category, fmt.Sprintf("order-%d-stage-%d", orderID, stage), file)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrFileTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("%s file size exceeds %dMB limit", mediaType, storage.Policies[category].MaxSize>>20),
+			})
+		case errors.Is(err, storage.ErrFileTypeNotAllowed):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "unsupported " + mediaType + " format",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "failed to store media",
+			})
+		}
 		return
 	}
-	
-	// TODO: Upload file to cloud storage (Firebase Storage or S3)
-	// For now, we'll just return a mock URL
-	mediaURL := "https://storage.example.com/" + file.Filename
-	thumbnailURL := mediaURL // TODO: Generate actual thumbnail
+	mediaURL := stored.Ref
+	thumbnailURL := stored.ThumbnailRef
 	
 	// Call service to attach media
-	err = h.service.AttachStageMedia(c.Request.Context(), uint(orderID), stage, mediaURL, mediaType)
+	err = h.service.AttachStageMedia(c.Request.Context(), uint(orderID), stage, mediaURL, thumbnailURL, mediaType)
 	if err != nil {
+		h.uploader.Delete(c.Request.Context(), category, mediaURL)
 		if err.Error() == "order not found" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"success": false,
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AssetAuditHandler handles asset label and physical asset audit endpoints
+type AssetAuditHandler struct {
+	assetAuditService *services.AssetAuditService
+}
+
+// NewAssetAuditHandler creates a new asset audit handler
+func NewAssetAuditHandler(assetAuditService *services.AssetAuditService) *AssetAuditHandler {
+	return &AssetAuditHandler{
+		assetAuditService: assetAuditService,
+	}
+}
+
+// StartAssetAuditRequest represents the start of an asset audit
+type StartAssetAuditRequest struct {
+	Location string `json:"location"` // empty to audit every active asset
+	Notes    string `json:"notes"`
+}
+
+// ScanAssetRequest represents a scanned asset label
+type ScanAssetRequest struct {
+	AssetCode string `json:"asset_code" binding:"required"`
+	Location  string `json:"location"`
+	Condition string `json:"condition"`
+	Notes     string `json:"notes"`
+}
+
+// RejectAssetAuditRequest represents the rejection of an asset audit
+type RejectAssetAuditRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// GetAssetLabels returns a PDF sheet of QR code labels.
+// Query params: ids (comma separated), category, location
+func (h *AssetAuditHandler) GetAssetLabels(c *gin.Context) {
+	filter := services.AssetLabelFilter{
+		Category: c.Query("category"),
+		Location: c.Query("location"),
+	}
+	if ids := c.Query("ids"); ids != "" {
+		for _, value := range strings.Split(ids, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"success":    false,
+					"error_code": "INVALID_ID",
+					"message":    "Daftar ID aset tidak valid",
+				})
+				return
+			}
+			filter.AssetIDs = append(filter.AssetIDs, uint(id))
+		}
+	}
+
+	data, err := h.assetAuditService.GenerateAssetLabels(filter)
+	if err != nil {
+		respondAssetAuditError(c, err)
+		return
+	}
+
+	filename := "label_aset_" + time.Now().Format("20060102_150405") + ".pdf"
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// StartAudit starts an asset audit
+func (h *AssetAuditHandler) StartAudit(c *gin.Context) {
+	var req StartAssetAuditRequest
+	if !bindAssetAuditJSON(c, &req) {
+		return
+	}
+
+	audit := &models.AssetAudit{
+		Location: req.Location,
+		Notes:    req.Notes,
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.assetAuditService.StartAudit(audit, userID.(uint)); err != nil {
+		respondAssetAuditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Audit aset berhasil dimulai",
+		"data":    audit,
+	})
+}
+
+// GetAudits lists asset audits. Query params: status
+func (h *AssetAuditHandler) GetAudits(c *gin.Context) {
+	audits, err := h.assetAuditService.GetAudits(c.Query("status"))
+	if err != nil {
+		respondAssetAuditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    audits,
+	})
+}
+
+// GetAudit returns an asset audit with its items
+func (h *AssetAuditHandler) GetAudit(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	audit, err := h.assetAuditService.GetAudit(id)
+	if err != nil {
+		respondAssetAuditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    audit,
+	})
+}
+
+// GetAuditReport returns the found, moved and missing assets of an audit
+func (h *AssetAuditHandler) GetAuditReport(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.assetAuditService.GetAuditReport(id)
+	if err != nil {
+		respondAssetAuditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// ScanAsset records a scanned asset label in an audit
+func (h *AssetAuditHandler) ScanAsset(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req ScanAssetRequest
+	if !bindAssetAuditJSON(c, &req) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	item, err := h.assetAuditService.ScanAsset(id, services.AssetScan{
+		AssetCode: req.AssetCode,
+		Location:  req.Location,
+		Condition: req.Condition,
+		Notes:     req.Notes,
+	}, userID.(uint))
+	if err != nil {
+		respondAssetAuditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Aset berhasil dipindai",
+		"data":    item,
+	})
+}
+
+// SubmitAudit submits an audit for approval
+func (h *AssetAuditHandler) SubmitAudit(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	audit, err := h.assetAuditService.SubmitAudit(id, userID.(uint))
+	if err != nil {
+		respondAssetAuditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Audit aset berhasil diajukan",
+		"data":    audit,
+	})
+}
+
+// ApproveAudit approves an audit and applies its findings to the assets
+func (h *AssetAuditHandler) ApproveAudit(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	audit, err := h.assetAuditService.ApproveAudit(id, userID.(uint))
+	if err != nil {
+		respondAssetAuditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Audit aset berhasil disetujui",
+		"data":    audit,
+	})
+}
+
+// RejectAudit rejects an audit
+func (h *AssetAuditHandler) RejectAudit(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req RejectAssetAuditRequest
+	if !bindAssetAuditJSON(c, &req) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	audit, err := h.assetAuditService.RejectAudit(id, userID.(uint), req.Reason)
+	if err != nil {
+		respondAssetAuditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Audit aset ditolak",
+		"data":    audit,
+	})
+}
+
+// bindAssetAuditJSON binds a JSON body, responding with a validation error on failure
+func bindAssetAuditJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// respondAssetAuditError maps asset audit service errors to HTTP responses
+func respondAssetAuditError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAssetAuditNotFound), errors.Is(err, services.ErrAssetNotFound),
+		errors.Is(err, services.ErrNoAssetLabels):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrUnauthorized), errors.Is(err, services.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error_code": "FORBIDDEN",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrAssetAuditNotInProgress), errors.Is(err, services.ErrAssetAuditNotSubmitted),
+		errors.Is(err, services.ErrAssetAuditInProgress), errors.Is(err, services.ErrAssetNotActive):
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "INVALID_STATE",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrNoAssetsToAudit), errors.Is(err, services.ErrNoAssetsScanned),
+		errors.Is(err, services.ErrInvalidAssetCondition), errors.Is(err, services.ErrAuditLocationRequired),
+		errors.Is(err, services.ErrRejectionReasonRequired):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+	default:
+		log.Printf("[ASSET AUDIT] error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
@@ -2,11 +2,11 @@ package handlers
 
 import (
 	"net/http"
-	"os"
 	"strconv"
 
-	"github.com/erp-sppg/backend/internal/middleware"
+	"github.com/erp-sppg/backend/internal/models"
 	"github.com/erp-sppg/backend/internal/services"
+	"github.com/erp-sppg/backend/internal/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -18,38 +18,33 @@ type AuthHandler struct {
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(db *gorm.DB, jwtSecret string) *AuthHandler {
+func NewAuthHandler(db *gorm.DB, authService *services.AuthService) *AuthHandler {
 	return &AuthHandler{
-		authService:  services.NewAuthService(db, jwtSecret),
+		authService:  authService,
 		auditService: services.NewAuditTrailService(db),
 	}
 }
 
-// getSessionTimeoutMinutes returns the session timeout from env or default
-func getSessionTimeoutMinutes() int {
-	timeoutStr := os.Getenv("SESSION_TIMEOUT_MINUTES")
-	if timeoutStr == "" {
-		return 30
-	}
-	timeout, err := strconv.Atoi(timeoutStr)
-	if err != nil {
-		return 30
-	}
-	return timeout
-}
-
 // LoginRequest represents login request body
 type LoginRequest struct {
 	Identifier string `json:"identifier" binding:"required"` // NIK or Email
 	Password   string `json:"password" binding:"required"`
+	DeviceName string `json:"device_name"`
 }
 
 // LoginResponse represents login response
 type LoginResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	Token   string `json:"token,omitempty"`
-	User    *struct {
+	Success            bool   `json:"success"`
+	Message            string `json:"message"`
+	Token              string `json:"token,omitempty"`
+	RefreshToken       string `json:"refresh_token,omitempty"`
+	ExpiresIn          int    `json:"expires_in,omitempty"`
+	SessionID          string `json:"session_id,omitempty"`
+	MustChangePassword bool   `json:"must_change_password"`
+	TwoFactorRequired  bool   `json:"two_factor_required,omitempty"`
+	TwoFactorSetup     bool   `json:"two_factor_setup,omitempty"`
+	ChallengeToken     string `json:"challenge_token,omitempty"`
+	User               *struct {
 		ID       uint   `json:"id"`
 		NIK      string `json:"nik"`
 		Email    string `json:"email"`
@@ -77,7 +72,32 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Authenticate user
-	user, token, err := h.authService.Login(req.Identifier, req.Password)
+	user, tokens, err := h.authService.LoginWithDevice(req.Identifier, req.Password, services.DeviceInfo{
+		UserAgent:  c.Request.UserAgent(),
+		DeviceName: req.DeviceName,
+		IPAddress:  c.ClientIP(),
+	})
+	if err == services.ErrTwoFactorRequired {
+		// Password is correct, the client must now send the TOTP or recovery code
+		challenge, err := h.authService.IssueTwoFactorChallenge(user, req.DeviceName)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success":    false,
+				"error_code": "INTERNAL_ERROR",
+				"message":    "Terjadi kesalahan pada server",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, LoginResponse{
+			Success:           true,
+			Message:           "Masukkan kode verifikasi dua langkah",
+			ExpiresIn:         int(services.TwoFactorChallengeTTL.Seconds()),
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		})
+		return
+	}
 	if err != nil {
 		if err == services.ErrInvalidCredentials {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -97,6 +117,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			return
 		}
 
+		if err == services.ErrAccountLocked {
+			c.JSON(http.StatusLocked, gin.H{
+				"success":    false,
+				"error_code": "ACCOUNT_LOCKED",
+				"message":    "Akun Anda terkunci karena terlalu banyak percobaan login gagal. Silakan coba lagi nanti atau hubungi administrator",
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success":    false,
 			"error_code": "INTERNAL_ERROR",
@@ -105,19 +134,35 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	h.respondLoginSuccess(c, user, tokens)
+}
+
+// respondLoginSuccess records the login and writes the token pair response
+func (h *AuthHandler) respondLoginSuccess(c *gin.Context, user *models.User, tokens *services.TokenPair) {
 	// Record login in audit trail
 	ipAddress := c.ClientIP()
 	h.auditService.RecordLogin(user.ID, ipAddress)
 
-	// Create session for the user
-	sessionManager := middleware.GetSessionManager(getSessionTimeoutMinutes())
-	sessionManager.UpdateActivity(user.ID)
-
 	// Return success response
+	message := "Login berhasil"
+	if user.MustChangePassword {
+		message = "Login berhasil. Anda harus mengganti password sebelum melanjutkan"
+	}
+
+	twoFactorSetup := !user.TwoFactorEnabled && h.authService.TwoFactor().IsRequiredForRole(user.Role)
+	if twoFactorSetup && !user.MustChangePassword {
+		message = "Login berhasil. Anda harus mengaktifkan verifikasi dua langkah sebelum melanjutkan"
+	}
+
 	c.JSON(http.StatusOK, LoginResponse{
-		Success: true,
-		Message: "Login berhasil",
-		Token:   token,
+		Success:            true,
+		Message:            message,
+		Token:              tokens.AccessToken,
+		RefreshToken:       tokens.RefreshToken,
+		ExpiresIn:          tokens.ExpiresIn,
+		SessionID:          tokens.SessionID,
+		MustChangePassword: user.MustChangePassword,
+		TwoFactorSetup:     twoFactorSetup,
 		User: &struct {
 			ID       uint   `json:"id"`
 			NIK      string `json:"nik"`
@@ -134,6 +179,67 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
+// TwoFactorLoginRequest represents the second step of a 2FA login
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // TOTP or recovery code
+}
+
+// VerifyTwoFactorLogin completes a login for users with 2FA enabled
+func (h *AuthHandler) VerifyTwoFactorLogin(c *gin.Context) {
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Challenge token dan kode verifikasi harus diisi",
+		})
+		return
+	}
+
+	user, tokens, err := h.authService.CompleteTwoFactorLogin(req.ChallengeToken, req.Code, services.DeviceInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	})
+	if err != nil {
+		switch err {
+		case services.ErrInvalidTwoFactorChallenge:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success":    false,
+				"error_code": "INVALID_CHALLENGE",
+				"message":    "Sesi verifikasi sudah kadaluarsa. Silakan login kembali",
+			})
+		case services.ErrInvalidTwoFactorCode:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success":    false,
+				"error_code": "INVALID_2FA_CODE",
+				"message":    "Kode verifikasi salah",
+			})
+		case services.ErrAccountLocked:
+			c.JSON(http.StatusLocked, gin.H{
+				"success":    false,
+				"error_code": "ACCOUNT_LOCKED",
+				"message":    "Akun Anda terkunci karena terlalu banyak percobaan login gagal. Silakan coba lagi nanti atau hubungi administrator",
+			})
+		case services.ErrUserInactive:
+			c.JSON(http.StatusForbidden, gin.H{
+				"success":    false,
+				"error_code": "USER_INACTIVE",
+				"message":    "Akun Anda tidak aktif. Silakan hubungi administrator",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success":    false,
+				"error_code": "INTERNAL_ERROR",
+				"message":    "Terjadi kesalahan pada server",
+			})
+		}
+		return
+	}
+
+	h.respondLoginSuccess(c, user, tokens)
+}
+
 // Logout handles user logout
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Get user ID from context (set by JWT middleware)
@@ -147,6 +253,16 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
+	// Revoke the server-side session so the refresh token stops working
+	if err := h.authService.Logout(c.GetString("session_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
 	// Record logout in audit trail
 	ipAddress := c.ClientIP()
 	h.auditService.RecordLogout(userID.(uint), ipAddress)
@@ -157,47 +273,163 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	})
 }
 
-// RefreshToken handles token refresh
+// RefreshTokenRequest represents refresh token request body
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshToken exchanges a refresh token for a new token pair.
+// The presented refresh token is consumed and cannot be used again.
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	// Get token from Authorization header
-	authHeader := c.GetHeader("Authorization")
-	if authHeader == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
 			"success":    false,
-			"error_code": "UNAUTHORIZED",
-			"message":    "Token tidak ditemukan",
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Refresh token harus diisi",
 		})
 		return
 	}
 
-	// Extract token
-	var tokenString string
-	if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-		tokenString = authHeader[7:]
-	} else {
+	tokens, err := h.authService.RefreshToken(req.RefreshToken)
+	if err != nil {
+		switch err {
+		case services.ErrRefreshTokenReused:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success":    false,
+				"error_code": "REFRESH_TOKEN_REUSED",
+				"message":    "Refresh token sudah pernah digunakan. Sesi dicabut, silakan login kembali",
+			})
+		case services.ErrSessionRevoked:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success":    false,
+				"error_code": "SESSION_REVOKED",
+				"message":    "Sesi Anda telah dicabut. Silakan login kembali.",
+			})
+		case services.ErrSessionExpired, services.ErrRefreshTokenExpired:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success":    false,
+				"error_code": "SESSION_EXPIRED",
+				"message":    "Sesi Anda telah berakhir. Silakan login kembali.",
+			})
+		case services.ErrUserInactive:
+			c.JSON(http.StatusForbidden, gin.H{
+				"success":    false,
+				"error_code": "USER_INACTIVE",
+				"message":    "Akun Anda tidak aktif. Silakan hubungi administrator",
+			})
+		case services.ErrInvalidRefreshToken, services.ErrUserNotFound:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success":    false,
+				"error_code": "INVALID_TOKEN",
+				"message":    "Refresh token tidak valid",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success":    false,
+				"error_code": "INTERNAL_ERROR",
+				"message":    "Terjadi kesalahan pada server",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"message":       "Token berhasil diperbarui",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"session_id":    tokens.SessionID,
+	})
+}
+
+// GetSessions lists the current user's active sessions
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success":    false,
 			"error_code": "UNAUTHORIZED",
-			"message":    "Format token tidak valid",
+			"message":    "Autentikasi diperlukan",
 		})
 		return
 	}
 
-	// Refresh token
-	newToken, err := h.authService.RefreshToken(tokenString)
+	sessions, err := h.authService.Sessions().GetActiveSessions(userID.(uint))
 	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	currentSessionID := c.GetString("session_id")
+	data := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		data = append(data, gin.H{
+			"session_id":       session.SessionID,
+			"device_name":      session.DeviceName,
+			"user_agent":       session.UserAgent,
+			"ip_address":       session.IPAddress,
+			"last_activity_at": session.LastActivityAt,
+			"expires_at":       session.ExpiresAt,
+			"created_at":       session.CreatedAt,
+			"is_current":       session.SessionID == currentSessionID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// RevokeSession signs out one of the current user's sessions
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success":    false,
-			"error_code": "INVALID_TOKEN",
-			"message":    "Token tidak valid atau sudah kadaluarsa",
+			"error_code": "UNAUTHORIZED",
+			"message":    "Autentikasi diperlukan",
 		})
 		return
 	}
 
+	sessionID := c.Param("id")
+	err := h.authService.Sessions().RevokeUserSession(userID.(uint), sessionID, services.SessionRevokedByUser)
+	if err != nil {
+		switch err {
+		case services.ErrSessionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"success":    false,
+				"error_code": "SESSION_NOT_FOUND",
+				"message":    "Sesi tidak ditemukan",
+			})
+		case services.ErrSessionRevoked:
+			c.JSON(http.StatusConflict, gin.H{
+				"success":    false,
+				"error_code": "SESSION_REVOKED",
+				"message":    "Sesi sudah dicabut",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success":    false,
+				"error_code": "INTERNAL_ERROR",
+				"message":    "Terjadi kesalahan pada server",
+			})
+		}
+		return
+	}
+
+	h.auditService.RecordAction(userID.(uint), "revoke_session", "user_session", sessionID, nil, nil, c.ClientIP())
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Token berhasil diperbarui",
-		"token":   newToken,
+		"message": "Sesi berhasil dicabut",
 	})
 }
 
@@ -237,13 +469,310 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"user": gin.H{
-			"id":           user.ID,
-			"nik":          user.NIK,
-			"email":        user.Email,
-			"full_name":    user.FullName,
-			"phone_number": user.PhoneNumber,
-			"role":         user.Role,
-			"is_active":    user.IsActive,
+			"id":                   user.ID,
+			"nik":                  user.NIK,
+			"email":                user.Email,
+			"full_name":            user.FullName,
+			"phone_number":         user.PhoneNumber,
+			"role":                 user.Role,
+			"is_active":            user.IsActive,
+			"must_change_password": user.MustChangePassword,
+			"two_factor_enabled":   user.TwoFactorEnabled,
+		},
+	})
+}
+
+// ChangePasswordRequest represents change password request body
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// ChangePassword changes the current user's password according to the password policy
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error_code": "UNAUTHORIZED",
+			"message":    "Autentikasi diperlukan",
+		})
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Password saat ini dan password baru harus diisi",
+		})
+		return
+	}
+
+	token, err := h.authService.ChangePassword(userID.(uint), c.GetString("session_id"), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		switch err {
+		case services.ErrCurrentPassword:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "INVALID_CURRENT_PASSWORD",
+				"message":    "Password saat ini salah",
+			})
+		case services.ErrPasswordReused:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "PASSWORD_REUSED",
+				"message":    err.Error(),
+			})
+		case utils.ErrPasswordTooShort, utils.ErrPasswordTooWeak:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "WEAK_PASSWORD",
+				"message":    err.Error(),
+			})
+		case services.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"success":    false,
+				"error_code": "USER_NOT_FOUND",
+				"message":    "Pengguna tidak ditemukan",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success":    false,
+				"error_code": "INTERNAL_ERROR",
+				"message":    "Terjadi kesalahan pada server",
+			})
+		}
+		return
+	}
+
+	h.auditService.RecordAction(userID.(uint), "change_password", "user", strconv.FormatUint(uint64(userID.(uint)), 10), nil, nil, c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password berhasil diubah",
+		"token":   token,
+	})
+}
+
+// TwoFactorCodeRequest carries a TOTP or recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// GetTwoFactorStatus returns the 2FA state of the current user
+func (h *AuthHandler) GetTwoFactorStatus(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.authService.TwoFactor().GetStatus(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// SetupTwoFactor starts TOTP enrollment and returns the secret and otpauth URI
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.authService.TwoFactor().BeginEnrollment(user)
+	if err != nil {
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Pindai QR code dengan aplikasi authenticator lalu konfirmasi dengan kode yang muncul",
+		"data":    enrollment,
+	})
+}
+
+// ConfirmTwoFactor enables 2FA and returns the recovery codes and a new access token
+func (h *AuthHandler) ConfirmTwoFactor(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Kode verifikasi harus diisi",
+		})
+		return
+	}
+
+	codes, err := h.authService.TwoFactor().ConfirmEnrollment(user, req.Code)
+	if err != nil {
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	// The old token still says enrollment is pending
+	token, err := h.authService.ReissueAccessToken(user.ID, c.GetString("session_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Verifikasi dua langkah berhasil diaktifkan. Simpan kode pemulihan di tempat yang aman",
+		"token":   token,
+		"data": gin.H{
+			"recovery_codes": codes,
+		},
+	})
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Kode verifikasi harus diisi",
+		})
+		return
+	}
+
+	codes, err := h.authService.TwoFactor().RegenerateRecoveryCodes(user, req.Code)
+	if err != nil {
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Kode pemulihan baru berhasil dibuat",
+		"data": gin.H{
+			"recovery_codes": codes,
 		},
 	})
 }
+
+// DisableTwoFactor turns 2FA off for the current user if the role allows it
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Kode verifikasi harus diisi",
+		})
+		return
+	}
+
+	if err := h.authService.TwoFactor().Disable(user, req.Code); err != nil {
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Verifikasi dua langkah berhasil dinonaktifkan",
+	})
+}
+
+// currentUser loads the authenticated user or writes the error response
+func (h *AuthHandler) currentUser(c *gin.Context) (*models.User, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error_code": "UNAUTHORIZED",
+			"message":    "Autentikasi diperlukan",
+		})
+		return nil, false
+	}
+
+	user, err := h.authService.GetUserByID(userID.(uint))
+	if err != nil {
+		if err == services.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success":    false,
+				"error_code": "USER_NOT_FOUND",
+				"message":    "Pengguna tidak ditemukan",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return nil, false
+	}
+
+	return user, true
+}
+
+// respondTwoFactorError maps 2FA service errors to responses
+func (h *AuthHandler) respondTwoFactorError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrInvalidTwoFactorCode:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_2FA_CODE",
+			"message":    "Kode verifikasi salah",
+		})
+	case services.ErrTwoFactorAlreadyEnabled:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "2FA_ALREADY_ENABLED",
+			"message":    err.Error(),
+		})
+	case services.ErrTwoFactorNotEnrolled:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "2FA_NOT_ENROLLED",
+			"message":    err.Error(),
+		})
+	case services.ErrTwoFactorEnforced:
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error_code": "2FA_REQUIRED_FOR_ROLE",
+			"message":    err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
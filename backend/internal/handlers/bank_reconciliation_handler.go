@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// maxBankStatementSize limits the size of an uploaded bank statement file
+const maxBankStatementSize = 10 << 20
+
+// BankReconciliationHandler handles bank statement import and reconciliation endpoints
+type BankReconciliationHandler struct {
+	reconciliationService *services.BankReconciliationService
+}
+
+// NewBankReconciliationHandler creates a new bank reconciliation handler
+func NewBankReconciliationHandler(reconciliationService *services.BankReconciliationService) *BankReconciliationHandler {
+	return &BankReconciliationHandler{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// MatchBankLineRequest represents a manual match of a bank line with a book item
+type MatchBankLineRequest struct {
+	BookType string `json:"book_type" binding:"required,oneof=cash_flow supplier_payment"`
+	BookID   uint   `json:"book_id" binding:"required"`
+}
+
+// ImportStatement imports a bank statement file (CSV/XLSX export or MT940).
+// Form fields: file, format=auto|csv|mt940, bank_name, account_number.
+func (h *BankReconciliationHandler) ImportStatement(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "File mutasi rekening diperlukan",
+		})
+		return
+	}
+	if fileHeader.Size > maxBankStatementSize {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "FILE_TOO_LARGE",
+			"message":    "Ukuran file mutasi rekening maksimal 10 MB",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_FILE",
+			"message":    "Gagal membuka file mutasi rekening",
+		})
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxBankStatementSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_FILE",
+			"message":    "Gagal membaca file mutasi rekening",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	result, err := h.reconciliationService.ImportStatement(fileHeader.Filename, content,
+		c.DefaultPostForm("format", services.BankStatementFormatAuto), c.PostForm("bank_name"), c.PostForm("account_number"), userID.(uint))
+	if err != nil {
+		respondBankReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Mutasi rekening berhasil diimpor",
+		"data":    result,
+	})
+}
+
+// GetStatements lists imported bank statements
+func (h *BankReconciliationHandler) GetStatements(c *gin.Context) {
+	statements, err := h.reconciliationService.GetStatements(c.Query("account_number"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    statements,
+	})
+}
+
+// GetStatement returns a bank statement with its lines
+func (h *BankReconciliationHandler) GetStatement(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	statement, err := h.reconciliationService.GetStatement(id)
+	if err != nil {
+		respondBankReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    statement,
+	})
+}
+
+// DeleteStatement deletes an imported bank statement
+func (h *BankReconciliationHandler) DeleteStatement(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.reconciliationService.DeleteStatement(id, userID.(uint)); err != nil {
+		respondBankReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Mutasi rekening berhasil dihapus",
+	})
+}
+
+// AutoMatchStatement re-runs the automatic matching of a statement
+func (h *BankReconciliationHandler) AutoMatchStatement(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+	if _, err := h.reconciliationService.GetStatement(id); err != nil {
+		respondBankReconciliationError(c, err)
+		return
+	}
+
+	matched, err := h.reconciliationService.AutoMatch(id)
+	if err != nil {
+		respondBankReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Pencocokan otomatis selesai",
+		"data":    gin.H{"auto_matched": matched},
+	})
+}
+
+// GetLines lists bank statement lines.
+// Query params: statement_id, account_number, status=matched|unmatched, start_date, end_date
+func (h *BankReconciliationHandler) GetLines(c *gin.Context) {
+	statementID, _ := strconv.ParseUint(c.Query("statement_id"), 10, 32)
+	filter := services.BankLineFilter{
+		StatementID:   uint(statementID),
+		AccountNumber: c.Query("account_number"),
+		MatchStatus:   c.Query("status"),
+	}
+	if startStr := c.Query("start_date"); startStr != "" {
+		if sd, err := time.ParseInLocation("2006-01-02", startStr, time.Local); err == nil {
+			filter.StartDate = &sd
+		}
+	}
+	if endStr := c.Query("end_date"); endStr != "" {
+		if ed, err := time.ParseInLocation("2006-01-02", endStr, time.Local); err == nil {
+			filter.EndDate = &ed
+		}
+	}
+
+	lines, err := h.reconciliationService.GetLines(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    lines,
+	})
+}
+
+// GetMatchCandidates proposes book items for a bank line
+func (h *BankReconciliationHandler) GetMatchCandidates(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	candidates, err := h.reconciliationService.GetMatchCandidates(id)
+	if err != nil {
+		respondBankReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    candidates,
+	})
+}
+
+// MatchLine manually matches a bank line with a cash flow entry or supplier payment
+func (h *BankReconciliationHandler) MatchLine(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req MatchBankLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	line, err := h.reconciliationService.MatchLine(id, req.BookType, req.BookID, userID.(uint))
+	if err != nil {
+		respondBankReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Baris mutasi rekening berhasil dicocokkan",
+		"data":    line,
+	})
+}
+
+// UnmatchLine releases the match of a bank line
+func (h *BankReconciliationHandler) UnmatchLine(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.reconciliationService.UnmatchLine(id, userID.(uint)); err != nil {
+		respondBankReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Pencocokan baris mutasi rekening berhasil dibatalkan",
+	})
+}
+
+// GetReconciliationReport returns the monthly bank reconciliation.
+// Query params: start_date, end_date (default the current month), account_number
+func (h *BankReconciliationHandler) GetReconciliationReport(c *gin.Context) {
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	startDate, ok := parseLedgerDate(c, "start_date", monthStart)
+	if !ok {
+		return
+	}
+	endDate, ok := parseLedgerDate(c, "end_date", monthStart.AddDate(0, 1, -1))
+	if !ok {
+		return
+	}
+
+	report, err := h.reconciliationService.GetReconciliationReport(c.Query("account_number"), startDate, endDate)
+	if err != nil {
+		respondBankReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// respondBankReconciliationError maps bank reconciliation service errors to HTTP responses
+func respondBankReconciliationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrBankStatementNotFound), errors.Is(err, services.ErrBankStatementLineNotFound),
+		errors.Is(err, services.ErrBookItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrBankStatementAlreadyLoaded):
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "DUPLICATE_BANK_STATEMENT",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrBankLineAlreadyMatched), errors.Is(err, services.ErrBankLineNotMatched),
+		errors.Is(err, services.ErrBookItemAlreadyMatched):
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "INVALID_STATE",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrUnsupportedBankStatementFormat), errors.Is(err, services.ErrUnreadableBankStatement),
+		errors.Is(err, services.ErrEmptyBankStatement),
+		errors.Is(err, services.ErrBankStatementHeaderNotFound), errors.Is(err, services.ErrInvalidBankStatementRow):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_FILE",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidBookItemType), errors.Is(err, services.ErrPayableEntryNotMatchable),
+		errors.Is(err, services.ErrBankMatchDirectionMismatch), errors.Is(err, services.ErrBankMatchAmountMismatch),
+		errors.Is(err, services.ErrInvalidDateRange):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+	default:
+		log.Printf("[BANK RECONCILIATION] error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
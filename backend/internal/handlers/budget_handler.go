@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// BudgetHandler handles budget target endpoints
+type BudgetHandler struct {
+	budgetService *services.BudgetService
+}
+
+// NewBudgetHandler creates a new budget handler
+func NewBudgetHandler(budgetService *services.BudgetService) *BudgetHandler {
+	return &BudgetHandler{
+		budgetService: budgetService,
+	}
+}
+
+// CreateBudgetTargetRequest represents create budget target request.
+// Month 0 creates the yearly budget of the category.
+type CreateBudgetTargetRequest struct {
+	Year     int          `json:"year" binding:"required,gte=2000"`
+	Month    *int         `json:"month" binding:"required,gte=0,lte=12"`
+	Category string       `json:"category" binding:"required"`
+	Target   models.Money `json:"target" binding:"gte=0"`
+	Notes    string       `json:"notes"`
+}
+
+// UpdateBudgetTargetRequest represents update budget target request
+type UpdateBudgetTargetRequest struct {
+	Target models.Money `json:"target" binding:"gte=0"`
+	Notes  string       `json:"notes"`
+}
+
+// GetBudgetTargets lists budget targets with live realisation and forecast
+func (h *BudgetHandler) GetBudgetTargets(c *gin.Context) {
+	year := time.Now().Year()
+	if yearStr := c.Query("year"); yearStr != "" {
+		parsed, err := strconv.Atoi(yearStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "VALIDATION_ERROR",
+				"message":    "Tahun tidak valid",
+			})
+			return
+		}
+		year = parsed
+	}
+
+	var month *int
+	if monthStr := c.Query("month"); monthStr != "" {
+		parsed, err := strconv.Atoi(monthStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "VALIDATION_ERROR",
+				"message":    "Bulan tidak valid",
+			})
+			return
+		}
+		month = &parsed
+	}
+
+	targets, err := h.budgetService.GetBudgetTargets(year, month, c.Query("category"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    targets,
+	})
+}
+
+// GetBudgetSummary returns the totals of one period (month=0 for the yearly budget)
+func (h *BudgetHandler) GetBudgetSummary(c *gin.Context) {
+	now := time.Now()
+	year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(now.Year())))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Tahun tidak valid",
+		})
+		return
+	}
+	month, err := strconv.Atoi(c.DefaultQuery("month", strconv.Itoa(int(now.Month()))))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Bulan tidak valid",
+		})
+		return
+	}
+
+	summary, err := h.budgetService.GetBudgetSummary(year, month)
+	if err != nil {
+		respondBudgetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    summary,
+	})
+}
+
+// GetBudgetTarget returns a single budget target
+func (h *BudgetHandler) GetBudgetTarget(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_ID",
+			"message":    "ID tidak valid",
+		})
+		return
+	}
+
+	target, err := h.budgetService.GetBudgetTarget(uint(id))
+	if err != nil {
+		respondBudgetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    target,
+	})
+}
+
+// CreateBudgetTarget creates a budget target
+func (h *BudgetHandler) CreateBudgetTarget(c *gin.Context) {
+	var req CreateBudgetTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	target := &models.BudgetTarget{
+		Year:     req.Year,
+		Month:    *req.Month,
+		Category: req.Category,
+		Target:   req.Target,
+		Notes:    req.Notes,
+	}
+	if err := h.budgetService.CreateBudgetTarget(target, userID.(uint)); err != nil {
+		respondBudgetError(c, err)
+		return
+	}
+
+	status, err := h.budgetService.GetBudgetTarget(target.ID)
+	if err != nil {
+		respondBudgetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Target anggaran berhasil dibuat",
+		"data":    status,
+	})
+}
+
+// UpdateBudgetTarget updates the amount and notes of a budget target
+func (h *BudgetHandler) UpdateBudgetTarget(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_ID",
+			"message":    "ID tidak valid",
+		})
+		return
+	}
+
+	var req UpdateBudgetTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	status, err := h.budgetService.UpdateBudgetTarget(uint(id), req.Target, req.Notes, userID.(uint))
+	if err != nil {
+		respondBudgetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Target anggaran berhasil diperbarui",
+		"data":    status,
+	})
+}
+
+// DeleteBudgetTarget deletes a budget target
+func (h *BudgetHandler) DeleteBudgetTarget(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_ID",
+			"message":    "ID tidak valid",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.budgetService.DeleteBudgetTarget(uint(id), userID.(uint)); err != nil {
+		respondBudgetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Target anggaran berhasil dihapus",
+	})
+}
+
+// ImportBudgetTargets creates or updates budget targets from an XLSX or CSV
+// file with the columns Tahun, Bulan, Kategori, Target and Catatan
+func (h *BudgetHandler) ImportBudgetTargets(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "File impor diperlukan",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_FILE",
+			"message":    "Gagal membuka file impor",
+		})
+		return
+	}
+	defer file.Close()
+
+	rows, parseErrors, err := h.budgetService.ParseImportFile(fileHeader.Filename, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_FILE",
+			"message":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	result, err := h.budgetService.ImportBudgetTargets(rows, parseErrors, dryRun, userID.(uint))
+	if err != nil {
+		if err == services.ErrImportValidationFailed {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"success":    false,
+				"error_code": "IMPORT_VALIDATION_FAILED",
+				"message":    "Terdapat baris yang tidak valid, tidak ada target anggaran yang disimpan",
+				"data":       result,
+			})
+			return
+		}
+		log.Printf("[IMPORT BUDGET] Import error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	message := "Target anggaran berhasil diimpor"
+	if dryRun {
+		message = "Validasi berhasil, semua baris dapat diimpor"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+		"data":    result,
+	})
+}
+
+// respondBudgetError maps budget service errors to HTTP responses
+func respondBudgetError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrBudgetTargetNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "BUDGET_TARGET_NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case services.ErrDuplicateBudgetTarget:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "DUPLICATE_BUDGET_TARGET",
+			"message":    err.Error(),
+		})
+	case services.ErrInvalidBudgetPeriod, services.ErrInvalidBudgetAmount, services.ErrInvalidCategory:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
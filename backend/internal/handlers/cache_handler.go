@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/erp-sppg/backend/internal/cache"
+	"github.com/gin-gonic/gin"
+)
+
+// CacheHandler exposes cache metrics to administrators
+type CacheHandler struct {
+	cacheService *cache.CacheService
+}
+
+// NewCacheHandler creates a new cache handler; cacheService is nil when caching is disabled
+func NewCacheHandler(cacheService *cache.CacheService) *CacheHandler {
+	return &CacheHandler{cacheService: cacheService}
+}
+
+// GetStats returns the hit and miss counters of the cache
+// GET /api/v1/system/cache/stats
+func (h *CacheHandler) GetStats(c *gin.Context) {
+	if h.cacheService == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    gin.H{"enabled": false},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"enabled": true,
+			"metrics": h.cacheService.GetMetrics(),
+		},
+	})
+}
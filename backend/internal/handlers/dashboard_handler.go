@@ -6,7 +6,7 @@ import (
 	"net/http"
 	"time"
 
-	firebase "firebase.google.com/go/v4"
+	"github.com/erp-sppg/backend/internal/realtime"
 	"github.com/erp-sppg/backend/internal/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -18,8 +18,8 @@ type DashboardHandler struct {
 }
 
 // NewDashboardHandler creates a new dashboard handler
-func NewDashboardHandler(db *gorm.DB, firebaseApp *firebase.App) (*DashboardHandler, error) {
-	dashboardService, err := services.NewDashboardService(db, firebaseApp)
+func NewDashboardHandler(db *gorm.DB, publisher realtime.RealtimePublisher) (*DashboardHandler, error) {
+	dashboardService, err := services.NewDashboardService(db, publisher)
 	if err != nil {
 		return nil, err
 	}
@@ -238,7 +238,7 @@ func (h *DashboardHandler) SyncDashboardToFirebase(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success":    false,
 			"error_code": "SYNC_ERROR",
-			"message":    "Gagal melakukan sinkronisasi ke Firebase",
+			"message":    "Gagal melakukan sinkronisasi realtime",
 			"details":    err.Error(),
 		})
 		return
@@ -246,7 +246,7 @@ func (h *DashboardHandler) SyncDashboardToFirebase(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Dashboard berhasil disinkronkan ke Firebase",
+		"message": "Dashboard berhasil disinkronkan ke klien realtime",
 	})
 }
 
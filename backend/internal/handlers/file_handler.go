@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// FileHandler serves uploaded files through short-lived signed URLs
+type FileHandler struct {
+	uploader *storage.Uploader
+	// signer verifies URLs of files served by the API itself; nil when the
+	// store signs its own URLs (S3)
+	signer *storage.URLSigner
+}
+
+// NewFileHandler creates a new file handler
+func NewFileHandler(uploader *storage.Uploader) *FileHandler {
+	h := &FileHandler{uploader: uploader}
+	if local, ok := uploader.Store().(*storage.LocalStore); ok {
+		h.signer = local.Signer()
+	}
+	return h
+}
+
+// ServeFile streams a stored file to holders of a valid signed URL
+// GET /api/v1/files/*key?expires=...&signature=...
+func (h *FileHandler) ServeFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if h.signer == nil || !storage.ValidKey(key) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "NOT_FOUND",
+			"message":    storage.ErrNotFound.Error(),
+		})
+		return
+	}
+
+	if err := h.signer.Verify(key, c.Query("expires"), c.Query("signature"), time.Now()); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error_code": "INVALID_SIGNATURE",
+			"message":    err.Error(),
+		})
+		return
+	}
+
+	file, err := h.uploader.Store().Open(c.Request.Context(), key)
+	if err != nil {
+		respondFileError(c, err)
+		return
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "inline")
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, file); err != nil {
+		log.Printf("[STORAGE] serving %s failed: %v", key, err)
+	}
+}
+
+// SignFile returns short-lived URLs for a stored file and its thumbnail
+// GET /api/v1/storage/sign?ref=/api/v1/files/epod/x.jpg&thumbnail_ref=...
+func (h *FileHandler) SignFile(c *gin.Context) {
+	signed, err := h.uploader.SignWithThumbnail(c.Request.Context(), c.Query("ref"), c.Query("thumbnail_ref"))
+	if err != nil {
+		respondFileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    signed,
+	})
+}
+
+// OpenFile redirects to a short-lived URL of a stored file, so the stored
+// reference can be used directly as the source of images and links
+// GET /api/v1/storage/open?ref=/api/v1/files/epod/x.jpg&access_token=...
+func (h *FileHandler) OpenFile(c *gin.Context) {
+	signed, err := h.uploader.Sign(c.Request.Context(), c.Query("ref"))
+	if err != nil {
+		respondFileError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, signed.URL)
+}
+
+func respondFileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, storage.ErrInvalidKey):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_FILE_REF",
+			"message":    "Referensi berkas tidak valid",
+		})
+	default:
+		log.Printf("[STORAGE] error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
+
+// respondUploadError reports an upload that was rejected or could not be stored
+func respondUploadError(c *gin.Context, category string, err error) {
+	policy := storage.Policies[category]
+	switch {
+	case errors.Is(err, storage.ErrFileTooLarge):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "FILE_TOO_LARGE",
+			"message":    fmt.Sprintf("Ukuran file maksimal %dMB", policy.MaxSize>>20),
+		})
+	case errors.Is(err, storage.ErrFileTypeNotAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_FILE_TYPE",
+			"message":    "Format file harus " + policy.AllowedFormats(),
+		})
+	default:
+		log.Printf("[STORAGE] upload to %s failed: %v", category, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "UPLOAD_FAILED",
+			"message":    "Gagal menyimpan file",
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFileTestRouter(t *testing.T) (*storage.Uploader, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+
+	store := storage.NewLocalStore(t.TempDir(), storage.NewURLSigner("test-secret", ""))
+	uploader := storage.NewUploader(store, storage.DefaultStripGPSCategories, time.Minute)
+	handler := NewFileHandler(uploader)
+	recipes := &RecipeHandler{uploader: uploader}
+
+	router := gin.New()
+	router.GET("/api/v1/files/*key", handler.ServeFile)
+	router.GET("/api/v1/storage/sign", handler.SignFile)
+	router.GET("/api/v1/storage/open", handler.OpenFile)
+	router.POST("/api/v1/recipes/upload-photo", recipes.UploadRecipePhoto)
+	router.DELETE("/api/v1/recipes/delete-photo", recipes.DeleteRecipePhoto)
+	return uploader, router
+}
+
+func uploadRecipePhoto(t *testing.T, router *gin.Engine, filename string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("photo", filename)
+	require.NoError(t, err)
+	part.Write(data)
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/recipes/upload-photo", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFileHandler_UploadSignAndServe(t *testing.T) {
+	_, router := setupFileTestRouter(t)
+
+	var photo bytes.Buffer
+	require.NoError(t, png.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 400, 200))))
+	w := uploadRecipePhoto(t, router, "nasi-goreng.png", photo.Bytes())
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var uploaded struct {
+		PhotoURL     string `json:"photo_url"`
+		ThumbnailURL string `json:"thumbnail_url"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &uploaded))
+	assert.Contains(t, uploaded.PhotoURL, storage.FilesPath+"recipes/")
+	assert.NotEmpty(t, uploaded.ThumbnailURL)
+
+	// The stored reference alone does not serve the file
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, uploaded.PhotoURL, nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/storage/sign?"+url.Values{
+		"ref":           {uploaded.PhotoURL},
+		"thumbnail_ref": {uploaded.ThumbnailURL},
+	}.Encode(), nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var signed struct {
+		Data storage.SignedFile `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &signed))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, signed.Data.URL, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, photo.Bytes(), w.Body.Bytes())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, signed.Data.ThumbnailURL, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+
+	// A signature is only valid for its own file
+	signedURL, err := url.Parse(signed.Data.URL)
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, storage.FilesPath+"recipes/other.png?"+signedURL.RawQuery, nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/storage/open?ref="+url.QueryEscape(uploaded.PhotoURL), nil))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Contains(t, w.Header().Get("Location"), "signature=")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/recipes/delete-photo?photo_url="+url.QueryEscape(uploaded.PhotoURL), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, signed.Data.URL, nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestFileHandler_RejectsInvalidUploadsAndRefs(t *testing.T) {
+	uploader, router := setupFileTestRouter(t)
+
+	w := uploadRecipePhoto(t, router, "photo.jpg", []byte("<script>alert(1)</script>"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_FILE_TYPE")
+
+	w = uploadRecipePhoto(t, router, "photo.jpg", make([]byte, 3<<20))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "FILE_TOO_LARGE")
+
+	for _, ref := range []string{"", "https://example.com/a.jpg", "/api/v1/files/../../etc/passwd"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/storage/sign?ref="+url.QueryEscape(ref), nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, ref)
+	}
+
+	// Recipe photos cannot be used to delete files of other categories
+	invoice, err := uploader.Save(context.Background(), storage.CategoryInvoice, "invoice", ".pdf", []byte("%PDF-1.4\n"))
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/recipes/delete-photo?photo_url="+url.QueryEscape(invoice.Ref), nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	file, err := uploader.Open(context.Background(), invoice.Ref)
+	require.NoError(t, err)
+	file.Close()
+}
@@ -19,10 +19,13 @@ type FinancialHandler struct {
 }
 
 // NewFinancialHandler creates a new financial handler
-func NewFinancialHandler(db *gorm.DB) *FinancialHandler {
+func NewFinancialHandler(db *gorm.DB, budgetService *services.BudgetService) *FinancialHandler {
+	cashFlowService := services.NewCashFlowService(db)
+	cashFlowService.SetBudgetService(budgetService)
+
 	return &FinancialHandler{
 		assetService:           services.NewAssetService(db),
-		cashFlowService:        services.NewCashFlowService(db),
+		cashFlowService:        cashFlowService,
 		financialReportService: services.NewFinancialReportService(db),
 	}
 }
@@ -31,14 +34,19 @@ func NewFinancialHandler(db *gorm.DB) *FinancialHandler {
 
 // CreateAssetRequest represents create asset request
 type CreateAssetRequest struct {
-	AssetCode        string  `json:"asset_code" binding:"required"`
-	Name             string  `json:"name" binding:"required"`
-	Category         string  `json:"category"`
-	PurchaseDate     string  `json:"purchase_date" binding:"required"`
-	PurchasePrice    float64 `json:"purchase_price" binding:"required,gte=0"`
-	DepreciationRate float64 `json:"depreciation_rate" binding:"gte=0,lte=100"`
-	Condition        string  `json:"condition" binding:"required,oneof=good fair poor"`
-	Location         string  `json:"location"`
+	AssetCode        string       `json:"asset_code" binding:"required"`
+	Name             string       `json:"name" binding:"required"`
+	Category         string       `json:"category"`
+	PurchaseDate     string       `json:"purchase_date" binding:"required"`
+	PurchasePrice    models.Money `json:"purchase_price" binding:"required,gte=0"`
+	DepreciationRate float64      `json:"depreciation_rate" binding:"gte=0,lte=100"`
+	Condition        string       `json:"condition" binding:"required,oneof=good fair poor"`
+	Location         string       `json:"location"`
+	// Depreciation method: straight_line (default), declining_balance or units_of_production
+	DepreciationMethod string       `json:"depreciation_method" binding:"omitempty,oneof=straight_line declining_balance units_of_production"`
+	UsefulLifeMonths   int          `json:"useful_life_months" binding:"gte=0"`
+	SalvageValue       models.Money `json:"salvage_value" binding:"gte=0"`
+	TotalUnits         float64      `json:"total_units" binding:"gte=0"`
 }
 
 // CreateAsset creates a new kitchen asset
@@ -74,9 +82,17 @@ func (h *FinancialHandler) CreateAsset(c *gin.Context) {
 		DepreciationRate: req.DepreciationRate,
 		Condition:        req.Condition,
 		Location:         req.Location,
+
+		DepreciationMethod: req.DepreciationMethod,
+		UsefulLifeMonths:   req.UsefulLifeMonths,
+		SalvageValue:       req.SalvageValue,
+		TotalUnits:         req.TotalUnits,
 	}
 
 	if err := h.assetService.CreateAsset(asset); err != nil {
+		if respondAssetDepreciationError(c, err) {
+			return
+		}
 		if err == services.ErrDuplicateAssetCode {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"success":    false,
@@ -211,9 +227,17 @@ func (h *FinancialHandler) UpdateAsset(c *gin.Context) {
 		DepreciationRate: req.DepreciationRate,
 		Condition:        req.Condition,
 		Location:         req.Location,
+
+		DepreciationMethod: req.DepreciationMethod,
+		UsefulLifeMonths:   req.UsefulLifeMonths,
+		SalvageValue:       req.SalvageValue,
+		TotalUnits:         req.TotalUnits,
 	}
 
 	if err := h.assetService.UpdateAsset(uint(id), asset); err != nil {
+		if respondAssetDepreciationError(c, err) {
+			return
+		}
 		if err == services.ErrAssetNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"success":    false,
@@ -284,10 +308,10 @@ func (h *FinancialHandler) DeleteAsset(c *gin.Context) {
 
 // AddMaintenanceRequest represents add maintenance request
 type AddMaintenanceRequest struct {
-	MaintenanceDate string  `json:"maintenance_date" binding:"required"`
-	Description     string  `json:"description"`
-	Cost            float64 `json:"cost" binding:"gte=0"`
-	PerformedBy     string  `json:"performed_by"`
+	MaintenanceDate string       `json:"maintenance_date" binding:"required"`
+	Description     string       `json:"description"`
+	Cost            models.Money `json:"cost" binding:"gte=0"`
+	PerformedBy     string       `json:"performed_by"`
 }
 
 // AddMaintenance adds a maintenance record for an asset
@@ -418,16 +442,289 @@ func (h *FinancialHandler) GetDepreciationSchedule(c *gin.Context) {
 	})
 }
 
+// DisposeAssetRequest represents the disposal or sale of an asset
+type DisposeAssetRequest struct {
+	DisposalDate string       `json:"disposal_date" binding:"required"`
+	Proceeds     models.Money `json:"proceeds" binding:"gte=0"` // sale price, 0 for a write-off
+	Notes        string       `json:"notes"`
+}
+
+// DisposeAsset records the disposal or sale of an asset with its gain or loss
+func (h *FinancialHandler) DisposeAsset(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req DisposeAssetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	disposalDate, err := time.ParseInLocation("2006-01-02", req.DisposalDate, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_DATE",
+			"message":    "Format tanggal tidak valid (gunakan YYYY-MM-DD)",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	asset, err := h.assetService.DisposeAsset(id, services.AssetDisposal{
+		Date:     disposalDate,
+		Proceeds: req.Proceeds,
+		Notes:    req.Notes,
+	}, userID.(uint))
+	if err != nil {
+		respondAssetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Pelepasan aset berhasil dicatat",
+		"asset":   asset,
+	})
+}
+
+// RecordAssetUsageRequest represents the output of an asset in a period
+type RecordAssetUsageRequest struct {
+	UsageDate string  `json:"usage_date" binding:"required"`
+	Units     float64 `json:"units" binding:"required,gt=0"`
+	Notes     string  `json:"notes"`
+}
+
+// RecordAssetUsage records asset usage for the units-of-production method
+func (h *FinancialHandler) RecordAssetUsage(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req RecordAssetUsageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	usageDate, err := time.ParseInLocation("2006-01-02", req.UsageDate, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_DATE",
+			"message":    "Format tanggal tidak valid (gunakan YYYY-MM-DD)",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	usage := &models.AssetUsageLog{UsageDate: usageDate, Units: req.Units, Notes: req.Notes}
+	if err := h.assetService.RecordUsage(id, usage, userID.(uint)); err != nil {
+		respondAssetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Pemakaian aset berhasil dicatat",
+		"usage":   usage,
+	})
+}
+
+// GetAssetUsage lists the usage records of an asset
+func (h *FinancialHandler) GetAssetUsage(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	logs, err := h.assetService.GetUsageLogs(id)
+	if err != nil {
+		respondAssetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"usage":   logs,
+	})
+}
+
+// GetDepreciationRuns lists depreciation runs. Query params: year
+func (h *FinancialHandler) GetDepreciationRuns(c *gin.Context) {
+	year, _ := strconv.Atoi(c.Query("year"))
+	runs, err := h.assetService.GetDepreciationRuns(year)
+	if err != nil {
+		respondAssetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"runs":    runs,
+	})
+}
+
+// GetDepreciationRun returns a depreciation run with its asset lines
+func (h *FinancialHandler) GetDepreciationRun(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	run, err := h.assetService.GetDepreciationRun(id)
+	if err != nil {
+		respondAssetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"run":     run,
+	})
+}
+
+// RunDepreciation posts the monthly depreciation of all active assets
+func (h *FinancialHandler) RunDepreciation(c *gin.Context) {
+	var req PostDepreciationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	result, err := h.assetService.RunDepreciation(req.Year, req.Month, userID.(uint))
+	if err != nil {
+		respondAssetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Penyusutan periode berhasil diposting",
+		"result":  result,
+	})
+}
+
+// ReverseDepreciationRun reverses the latest depreciation run
+func (h *FinancialHandler) ReverseDepreciationRun(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req ReverseJournalEntryRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "VALIDATION_ERROR",
+				"message":    "Data tidak valid",
+				"details":    err.Error(),
+			})
+			return
+		}
+	}
+
+	userID, _ := c.Get("user_id")
+	run, err := h.assetService.ReverseDepreciationRun(id, req.Reason, userID.(uint))
+	if err != nil {
+		respondAssetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Penyusutan periode berhasil dibatalkan",
+		"run":     run,
+	})
+}
+
+// respondAssetDepreciationError responds to invalid depreciation settings and
+// reports whether the error was handled
+func respondAssetDepreciationError(c *gin.Context, err error) bool {
+	switch err {
+	case services.ErrInvalidDepreciationMethod, services.ErrUsefulLifeRequired,
+		services.ErrTotalUnitsRequired, services.ErrInvalidSalvageValue:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+		return true
+	case services.ErrAssetNotActive:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "ASSET_NOT_ACTIVE",
+			"message":    err.Error(),
+		})
+		return true
+	}
+	return false
+}
+
+// respondAssetError maps asset disposal, usage and depreciation run errors to HTTP responses
+func respondAssetError(c *gin.Context, err error) {
+	if respondAssetDepreciationError(c, err) {
+		return
+	}
+
+	switch err {
+	case services.ErrAssetNotFound, services.ErrDepreciationRunNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case services.ErrDepreciationRunReversed, services.ErrDepreciationRunNotLatest, services.ErrDepreciationRunAssetClosed:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "INVALID_STATE",
+			"message":    err.Error(),
+		})
+	case services.ErrInvalidDisposal, services.ErrInvalidUsageUnits, services.ErrInvalidLedgerPeriod:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
+
 // Cash Flow Endpoints
 
 // CreateCashFlowRequest represents create cash flow request
 type CreateCashFlowRequest struct {
-	Date        string  `json:"date"`
-	Category    string  `json:"category" binding:"required,oneof=bahan_baku gaji utilitas operasional"`
-	Type        string  `json:"type" binding:"required,oneof=income expense"`
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
-	Description string  `json:"description"`
-	Reference   string  `json:"reference"`
+	Date        string       `json:"date"`
+	Category    string       `json:"category" binding:"required,oneof=bahan_baku gaji utilitas operasional"`
+	Type        string       `json:"type" binding:"required,oneof=income expense"`
+	Amount      models.Money `json:"amount" binding:"required,gt=0"`
+	Description string       `json:"description"`
+	Reference   string       `json:"reference"`
 }
 
 // CreateCashFlow creates a new cash flow entry
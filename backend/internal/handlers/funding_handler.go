@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// FundingHandler handles government funding tranche and LPJ endpoints
+type FundingHandler struct {
+	fundingService *services.FundingService
+}
+
+// NewFundingHandler creates a new funding handler
+func NewFundingHandler(fundingService *services.FundingService) *FundingHandler {
+	return &FundingHandler{
+		fundingService: fundingService,
+	}
+}
+
+// FundingTrancheSchoolRequest represents a school a tranche is meant for
+type FundingTrancheSchoolRequest struct {
+	SchoolID       uint `json:"school_id" binding:"required"`
+	TargetPortions int  `json:"target_portions" binding:"gte=0"`
+}
+
+// FundingTrancheRequest represents create or update funding tranche request
+type FundingTrancheRequest struct {
+	Source          string                        `json:"source" binding:"required,max=150"`
+	ReferenceNumber string                        `json:"reference_number" binding:"max=100"`
+	ReceivedDate    string                        `json:"received_date" binding:"required"`
+	Amount          models.Money                  `json:"amount" binding:"required,gt=0"`
+	PeriodStart     string                        `json:"period_start" binding:"required"`
+	PeriodEnd       string                        `json:"period_end" binding:"required"`
+	TargetPortions  int                           `json:"target_portions" binding:"gte=0"`
+	Description     string                        `json:"description"`
+	Schools         []FundingTrancheSchoolRequest `json:"schools" binding:"dive"`
+}
+
+// AssignTrancheExpensesRequest represents expenses to account to a tranche
+type AssignTrancheExpensesRequest struct {
+	EntryIDs []uint `json:"entry_ids" binding:"required,min=1"`
+}
+
+// GetTranches lists funding tranches
+func (h *FundingHandler) GetTranches(c *gin.Context) {
+	year, _ := strconv.Atoi(c.Query("year"))
+
+	tranches, err := h.fundingService.GetTranches(year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tranches,
+	})
+}
+
+// GetTranche returns a funding tranche
+func (h *FundingHandler) GetTranche(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	tranche, err := h.fundingService.GetTranche(id)
+	if err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tranche,
+	})
+}
+
+// CreateTranche records a received funding tranche
+func (h *FundingHandler) CreateTranche(c *gin.Context) {
+	tranche, ok := bindFundingTranche(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.fundingService.CreateTranche(tranche, userID.(uint)); err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Tranche dana berhasil dicatat",
+		"data":    tranche,
+	})
+}
+
+// UpdateTranche updates a funding tranche
+func (h *FundingHandler) UpdateTranche(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+	updates, ok := bindFundingTranche(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	tranche, err := h.fundingService.UpdateTranche(id, updates, userID.(uint))
+	if err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Tranche dana berhasil diperbarui",
+		"data":    tranche,
+	})
+}
+
+// DeleteTranche deletes a funding tranche
+func (h *FundingHandler) DeleteTranche(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.fundingService.DeleteTranche(id, userID.(uint)); err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Tranche dana berhasil dihapus",
+	})
+}
+
+// AssignExpenses accounts cash flow expenses to a tranche
+func (h *FundingHandler) AssignExpenses(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req AssignTrancheExpensesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	assigned, err := h.fundingService.AssignExpenses(id, req.EntryIDs, userID.(uint))
+	if err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Pengeluaran berhasil dialokasikan ke tranche dana",
+		"data":    gin.H{"assigned": assigned},
+	})
+}
+
+// UnassignExpense removes an expense from a tranche
+func (h *FundingHandler) UnassignExpense(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+	entryID, err := strconv.ParseUint(c.Param("entry_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_ID",
+			"message":    "ID tidak valid",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.fundingService.UnassignExpense(id, uint(entryID), userID.(uint)); err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Alokasi pengeluaran berhasil dilepas",
+	})
+}
+
+// GetLPJ returns the accountability report of a tranche
+func (h *FundingHandler) GetLPJ(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.fundingService.GetLPJ(id)
+	if err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// ExportLPJ downloads the LPJ of a tranche as PDF or Excel
+func (h *FundingHandler) ExportLPJ(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+	format := services.ExportFormat(c.DefaultQuery("format", "pdf"))
+
+	userID, _ := c.Get("user_id")
+	data, filename, err := h.fundingService.ExportLPJ(id, format, userID.(uint))
+	if err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	contentType := "application/pdf"
+	if format == services.ExportFormatExcel {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// bindFundingTranche binds and converts a funding tranche request
+func bindFundingTranche(c *gin.Context) (*models.FundingTranche, bool) {
+	var req FundingTrancheRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return nil, false
+	}
+
+	dates := make(map[string]time.Time, 3)
+	for field, value := range map[string]string{
+		"received_date": req.ReceivedDate,
+		"period_start":  req.PeriodStart,
+		"period_end":    req.PeriodEnd,
+	} {
+		date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "INVALID_DATE",
+				"message":    "Format " + field + " tidak valid (gunakan YYYY-MM-DD)",
+			})
+			return nil, false
+		}
+		dates[field] = date
+	}
+
+	tranche := &models.FundingTranche{
+		Source:          req.Source,
+		ReferenceNumber: req.ReferenceNumber,
+		ReceivedDate:    dates["received_date"],
+		Amount:          req.Amount,
+		PeriodStart:     dates["period_start"],
+		PeriodEnd:       dates["period_end"],
+		TargetPortions:  req.TargetPortions,
+		Description:     req.Description,
+	}
+	for _, school := range req.Schools {
+		tranche.Schools = append(tranche.Schools, models.FundingTrancheSchool{
+			SchoolID:       school.SchoolID,
+			TargetPortions: school.TargetPortions,
+		})
+	}
+	return tranche, true
+}
+
+// respondFundingError maps funding service errors to HTTP responses
+func respondFundingError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrFundingTrancheNotFound, services.ErrSchoolNotFound, services.ErrCashFlowNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case services.ErrExpenseNotInTranche:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "INVALID_STATE",
+			"message":    err.Error(),
+		})
+	case services.ErrInvalidFundingPeriod, services.ErrInvalidFundingAmount, services.ErrInvalidTargetPortions,
+		services.ErrDuplicateTrancheSchool, services.ErrExpenseNotAssignable, services.ErrUnsupportedExportFormat:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
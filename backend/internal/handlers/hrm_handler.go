@@ -15,7 +15,9 @@ import (
 
 // HRMHandler handles Human Resource Management endpoints
 type HRMHandler struct {
+	authService       *services.AuthService
 	employeeService   *services.EmployeeService
+	importService     *services.EmployeeImportService
 	attendanceService *services.AttendanceService
 	auditService      *services.AuditTrailService
 }
@@ -24,7 +26,9 @@ type HRMHandler struct {
 func NewHRMHandler(db *gorm.DB, authService *services.AuthService) *HRMHandler {
 	employeeService := services.NewEmployeeService(db, authService)
 	return &HRMHandler{
+		authService:       authService,
 		employeeService:   employeeService,
+		importService:     services.NewEmployeeImportService(db, employeeService),
 		attendanceService: services.NewAttendanceService(db, employeeService),
 		auditService:      services.NewAuditTrailService(db),
 	}
@@ -91,6 +95,14 @@ func (h *HRMHandler) CreateEmployee(c *gin.Context) {
 		user, password, err = h.employeeService.CreateEmployee(employee, req.Role)
 	}
 	if err != nil {
+		if err == services.ErrInvalidRole {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "INVALID_ROLE",
+				"message":    "Role tidak valid",
+			})
+			return
+		}
 		if err == services.ErrDuplicateNIK {
 			c.JSON(http.StatusConflict, gin.H{
 				"success":    false,
@@ -301,6 +313,156 @@ func (h *HRMHandler) DeactivateEmployee(c *gin.Context) {
 	})
 }
 
+// ResetPassword generates a new random password that must be changed on next login
+func (h *HRMHandler) ResetPassword(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_ID",
+			"message":    "ID tidak valid",
+		})
+		return
+	}
+
+	password, err := h.employeeService.ResetPassword(uint(id))
+	if err != nil {
+		if err == services.ErrEmployeeNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success":    false,
+				"error_code": "EMPLOYEE_NOT_FOUND",
+				"message":    "Karyawan tidak ditemukan",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	// Record in audit trail
+	userID, _ := c.Get("user_id")
+	h.auditService.RecordAction(userID.(uint), "reset_password", "employee", strconv.Itoa(int(id)), "", "", c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password berhasil direset. Karyawan wajib mengganti password saat login berikutnya",
+		"data": gin.H{
+			"credentials": gin.H{
+				"password": password,
+			},
+		},
+	})
+}
+
+// UnlockAccount clears the lockout of an employee's user account
+func (h *HRMHandler) UnlockAccount(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_ID",
+			"message":    "ID tidak valid",
+		})
+		return
+	}
+
+	employee, err := h.employeeService.GetEmployeeByID(uint(id))
+	if err != nil {
+		if err == services.ErrEmployeeNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success":    false,
+				"error_code": "EMPLOYEE_NOT_FOUND",
+				"message":    "Karyawan tidak ditemukan",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	if err := h.authService.UnlockAccount(employee.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	// Record in audit trail
+	userID, _ := c.Get("user_id")
+	h.auditService.RecordAction(userID.(uint), "unlock", "user", strconv.FormatUint(uint64(employee.UserID), 10),
+		map[string]interface{}{"locked_until": employee.User.LockedUntil},
+		map[string]interface{}{"locked_until": nil},
+		c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Akun karyawan berhasil dibuka",
+	})
+}
+
+// ResetTwoFactor removes the 2FA enrollment of an employee who lost the
+// authenticator device and has no recovery codes left
+func (h *HRMHandler) ResetTwoFactor(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_ID",
+			"message":    "ID tidak valid",
+		})
+		return
+	}
+
+	employee, err := h.employeeService.GetEmployeeByID(uint(id))
+	if err != nil {
+		if err == services.ErrEmployeeNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success":    false,
+				"error_code": "EMPLOYEE_NOT_FOUND",
+				"message":    "Karyawan tidak ditemukan",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	if err := h.authService.TwoFactor().Reset(employee.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	// Record in audit trail
+	userID, _ := c.Get("user_id")
+	h.auditService.RecordAction(userID.(uint), "reset_2fa", "user", strconv.FormatUint(uint64(employee.UserID), 10),
+		map[string]interface{}{"two_factor_enabled": employee.User.TwoFactorEnabled},
+		map[string]interface{}{"two_factor_enabled": false},
+		c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Verifikasi dua langkah karyawan berhasil direset",
+	})
+}
+
 // GetEmployeeStats retrieves employee statistics
 func (h *HRMHandler) GetEmployeeStats(c *gin.Context) {
 	stats, err := h.employeeService.GetEmployeeStats()
@@ -319,6 +481,141 @@ func (h *HRMHandler) GetEmployeeStats(c *gin.Context) {
 	})
 }
 
+// ImportEmployees onboards employees in bulk from an XLSX or CSV file.
+// Query params: dry_run=true validates only; format=json|pdf|excel selects
+// whether the credential sheet is returned as JSON or as a printable file.
+func (h *HRMHandler) ImportEmployees(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "pdf" && format != "excel" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_FORMAT",
+			"message":    "Format tidak valid (gunakan json, pdf atau excel)",
+		})
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "File impor diperlukan",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_FILE",
+			"message":    "Gagal membuka file impor",
+		})
+		return
+	}
+	defer file.Close()
+
+	rows, err := h.importService.ParseImportFile(fileHeader.Filename, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_FILE",
+			"message":    err.Error(),
+		})
+		return
+	}
+
+	result, err := h.importService.ImportEmployees(rows, dryRun)
+	if err != nil {
+		if err == services.ErrImportValidationFailed {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"success":    false,
+				"error_code": "IMPORT_VALIDATION_FAILED",
+				"message":    "Terdapat baris yang tidak valid, tidak ada karyawan yang dibuat",
+				"data":       result,
+			})
+			return
+		}
+		log.Printf("[IMPORT EMPLOYEE] Import error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Validasi berhasil, semua baris dapat diimpor",
+			"data":    result,
+		})
+		return
+	}
+
+	// Record in audit trail
+	userID, _ := c.Get("user_id")
+	importedIDs := make([]uint, 0, len(result.Credentials))
+	for _, cred := range result.Credentials {
+		importedIDs = append(importedIDs, cred.EmployeeID)
+	}
+	h.auditService.RecordAction(userID.(uint), "import", "employee", "", nil, map[string]interface{}{
+		"file":         fileHeader.Filename,
+		"imported":     result.Imported,
+		"employee_ids": importedIDs,
+	}, c.ClientIP())
+
+	if format == "json" {
+		c.JSON(http.StatusCreated, gin.H{
+			"success": true,
+			"message": "Karyawan berhasil diimpor",
+			"data":    result,
+		})
+		return
+	}
+
+	generatedBy := "User " + strconv.FormatUint(uint64(userID.(uint)), 10)
+	buf, err := h.importService.GenerateCredentialSheet(result.Credentials, format, generatedBy)
+	if err != nil {
+		log.Printf("[IMPORT EMPLOYEE] Credential sheet error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "EXPORT_FAILED",
+			"message":    "Karyawan berhasil diimpor, tetapi gagal membuat lembar kredensial",
+		})
+		return
+	}
+
+	contentType := "application/pdf"
+	filename := "kredensial-karyawan-" + time.Now().Format("20060102-150405") + ".pdf"
+	if format == "excel" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		filename = "kredensial-karyawan-" + time.Now().Format("20060102-150405") + ".xlsx"
+	}
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusCreated, contentType, buf.Bytes())
+}
+
+// DownloadImportTemplate returns the XLSX template for bulk employee import
+func (h *HRMHandler) DownloadImportTemplate(c *gin.Context) {
+	buf, err := h.importService.GenerateImportTemplate()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	contentType := "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	c.Header("Content-Disposition", "attachment; filename=template-impor-karyawan.xlsx")
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
 // Attendance Endpoints
 
 // CheckInRequest represents check-in request
@@ -0,0 +1,518 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// LedgerHandler handles chart of accounts, journal and ledger report endpoints
+type LedgerHandler struct {
+	ledgerService *services.LedgerService
+}
+
+// NewLedgerHandler creates a new ledger handler
+func NewLedgerHandler(ledgerService *services.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService: ledgerService,
+	}
+}
+
+// CreateAccountRequest represents create account request
+type CreateAccountRequest struct {
+	Code          string `json:"code" binding:"required,max=20"`
+	Name          string `json:"name" binding:"required"`
+	Type          string `json:"type" binding:"required,oneof=asset liability equity revenue expense"`
+	NormalBalance string `json:"normal_balance" binding:"omitempty,oneof=debit credit"`
+	Category      string `json:"category"`
+	Description   string `json:"description"`
+}
+
+// UpdateAccountRequest represents update account request
+type UpdateAccountRequest struct {
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+// JournalLineRequest represents a journal line
+type JournalLineRequest struct {
+	AccountID   uint         `json:"account_id" binding:"required"`
+	Debit       models.Money `json:"debit" binding:"gte=0"`
+	Credit      models.Money `json:"credit" binding:"gte=0"`
+	Description string       `json:"description"`
+}
+
+// CreateJournalEntryRequest represents a manual journal entry
+type CreateJournalEntryRequest struct {
+	Date        string               `json:"date" binding:"required"`
+	Description string               `json:"description" binding:"required"`
+	Reference   string               `json:"reference"`
+	Lines       []JournalLineRequest `json:"lines" binding:"required,min=2,dive"`
+}
+
+// ReverseJournalEntryRequest represents a journal reversal request
+type ReverseJournalEntryRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PostDepreciationRequest represents a monthly depreciation run
+type PostDepreciationRequest struct {
+	Year  int `json:"year" binding:"required,gte=2000"`
+	Month int `json:"month" binding:"required,gte=1,lte=12"`
+}
+
+// GetAccounts lists the chart of accounts
+func (h *LedgerHandler) GetAccounts(c *gin.Context) {
+	accounts, err := h.ledgerService.GetAccounts(c.Query("type"), c.Query("include_inactive") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    accounts,
+	})
+}
+
+// GetAccount returns a single account
+func (h *LedgerHandler) GetAccount(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.ledgerService.GetAccount(id)
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    account,
+	})
+}
+
+// CreateAccount adds an account to the chart of accounts
+func (h *LedgerHandler) CreateAccount(c *gin.Context) {
+	var req CreateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	account := &models.Account{
+		Code:          req.Code,
+		Name:          req.Name,
+		Type:          req.Type,
+		NormalBalance: req.NormalBalance,
+		Category:      req.Category,
+		Description:   req.Description,
+	}
+	if err := h.ledgerService.CreateAccount(account, userID.(uint)); err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Akun berhasil dibuat",
+		"data":    account,
+	})
+}
+
+// UpdateAccount updates an account
+func (h *LedgerHandler) UpdateAccount(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	existing, err := h.ledgerService.GetAccount(id)
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+	isActive := existing.IsActive
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	userID, _ := c.Get("user_id")
+	account, err := h.ledgerService.UpdateAccount(id, req.Name, req.Description, req.Category, isActive, userID.(uint))
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Akun berhasil diperbarui",
+		"data":    account,
+	})
+}
+
+// GetJournalEntries lists journal entries
+func (h *LedgerHandler) GetJournalEntries(c *gin.Context) {
+	var startDate, endDate *time.Time
+	if startStr := c.Query("start_date"); startStr != "" {
+		if sd, err := time.ParseInLocation("2006-01-02", startStr, time.Local); err == nil {
+			startDate = &sd
+		}
+	}
+	if endStr := c.Query("end_date"); endStr != "" {
+		if ed, err := time.ParseInLocation("2006-01-02", endStr, time.Local); err == nil {
+			endDate = &ed
+		}
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	entries, err := h.ledgerService.GetJournalEntries(c.Query("source_type"), startDate, endDate, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    entries,
+	})
+}
+
+// GetJournalEntry returns a single journal entry with its lines
+func (h *LedgerHandler) GetJournalEntry(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.ledgerService.GetJournalEntry(id)
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    entry,
+	})
+}
+
+// CreateJournalEntry posts a manual journal entry
+func (h *LedgerHandler) CreateJournalEntry(c *gin.Context) {
+	var req CreateJournalEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_DATE",
+			"message":    "Format tanggal tidak valid (gunakan YYYY-MM-DD)",
+		})
+		return
+	}
+
+	entry := &models.JournalEntry{
+		Date:        date,
+		Description: req.Description,
+		Reference:   req.Reference,
+	}
+	for _, line := range req.Lines {
+		entry.Lines = append(entry.Lines, models.JournalLine{
+			AccountID:   line.AccountID,
+			Debit:       line.Debit,
+			Credit:      line.Credit,
+			Description: line.Description,
+		})
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.ledgerService.CreateJournalEntry(entry, userID.(uint)); err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	created, err := h.ledgerService.GetJournalEntry(entry.ID)
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Jurnal berhasil diposting",
+		"data":    created,
+	})
+}
+
+// ReverseJournalEntry cancels a journal entry with a reversing entry
+func (h *LedgerHandler) ReverseJournalEntry(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req ReverseJournalEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	reversal, err := h.ledgerService.ReverseJournalEntry(id, req.Reason, userID.(uint))
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Jurnal berhasil dibatalkan",
+		"data":    reversal,
+	})
+}
+
+// GetTrialBalance returns the trial balance at a date (default today)
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	asOf, ok := parseLedgerDate(c, "as_of", time.Now())
+	if !ok {
+		return
+	}
+
+	report, err := h.ledgerService.GetTrialBalance(asOf)
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// GetGeneralLedger returns the postings of an account (default: current month)
+func (h *LedgerHandler) GetGeneralLedger(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	startDate, ok := parseLedgerDate(c, "start_date", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local))
+	if !ok {
+		return
+	}
+	endDate, ok := parseLedgerDate(c, "end_date", now)
+	if !ok {
+		return
+	}
+
+	ledger, err := h.ledgerService.GetGeneralLedger(id, startDate, endDate)
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    ledger,
+	})
+}
+
+// GetBalanceSheet returns the balance sheet at a date (default today)
+func (h *LedgerHandler) GetBalanceSheet(c *gin.Context) {
+	asOf, ok := parseLedgerDate(c, "as_of", time.Now())
+	if !ok {
+		return
+	}
+
+	sheet, err := h.ledgerService.GetBalanceSheet(asOf)
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sheet,
+	})
+}
+
+// GetIncomeStatement returns revenue and expense of a period (default: current month)
+func (h *LedgerHandler) GetIncomeStatement(c *gin.Context) {
+	now := time.Now()
+	startDate, ok := parseLedgerDate(c, "start_date", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local))
+	if !ok {
+		return
+	}
+	endDate, ok := parseLedgerDate(c, "end_date", now)
+	if !ok {
+		return
+	}
+
+	statement, err := h.ledgerService.GetIncomeStatement(startDate, endDate)
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    statement,
+	})
+}
+
+// PostDepreciation posts the monthly depreciation of all assets
+func (h *LedgerHandler) PostDepreciation(c *gin.Context) {
+	var req PostDepreciationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	result, err := h.ledgerService.PostDepreciation(req.Year, req.Month, userID.(uint))
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Penyusutan berhasil diposting",
+		"data":    result,
+	})
+}
+
+// parseLedgerID reads the :id parameter
+func parseLedgerID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_ID",
+			"message":    "ID tidak valid",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseLedgerDate reads an optional YYYY-MM-DD query parameter
+func parseLedgerDate(c *gin.Context, key string, defaultValue time.Time) (time.Time, bool) {
+	value := c.Query(key)
+	if value == "" {
+		return defaultValue, true
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_DATE",
+			"message":    "Format " + key + " tidak valid (gunakan YYYY-MM-DD)",
+		})
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// respondLedgerError maps ledger service errors to HTTP responses
+func respondLedgerError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrAccountNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "ACCOUNT_NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case services.ErrJournalNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "JOURNAL_NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case services.ErrDuplicateAccountCode:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "DUPLICATE_ACCOUNT_CODE",
+			"message":    err.Error(),
+		})
+	case services.ErrJournalAlreadyReversed, services.ErrJournalNotReversible, services.ErrSystemAccount:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "INVALID_STATE",
+			"message":    err.Error(),
+		})
+	case services.ErrUnbalancedJournal:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "UNBALANCED_JOURNAL",
+			"message":    err.Error(),
+		})
+	case services.ErrInvalidAccountType, services.ErrInactiveAccount, services.ErrJournalTooFewLines,
+		services.ErrInvalidJournalLine, services.ErrInvalidLedgerPeriod, services.ErrInvalidDateRange:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
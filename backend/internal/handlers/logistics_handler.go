@@ -1,18 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/erp-sppg/backend/internal/models"
 	"github.com/erp-sppg/backend/internal/services"
+	"github.com/erp-sppg/backend/internal/storage"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -22,15 +21,17 @@ type LogisticsHandler struct {
 	deliveryTaskService    *services.DeliveryTaskService
 	epodService            *services.EPODService
 	omprengTrackingService *services.OmprengTrackingService
+	uploader               *storage.Uploader
 }
 
 // NewLogisticsHandler creates a new logistics handler
-func NewLogisticsHandler(db *gorm.DB) *LogisticsHandler {
+func NewLogisticsHandler(db *gorm.DB, uploader *storage.Uploader) *LogisticsHandler {
 	return &LogisticsHandler{
 		schoolService:          services.NewSchoolService(db),
 		deliveryTaskService:    services.NewDeliveryTaskService(db),
 		epodService:            services.NewEPODService(db),
 		omprengTrackingService: services.NewOmprengTrackingService(db),
+		uploader:               uploader,
 	}
 }
 
@@ -952,46 +953,12 @@ func (h *LogisticsHandler) UploadEPODPhoto(c *gin.Context) {
 			return
 		}
 
-		// Validate file size (max 5MB)
-		if file.Size > 5*1024*1024 {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"success":    false,
-				"error_code": "FILE_TOO_LARGE",
-				"message":    "Ukuran file terlalu besar. Maksimal 5MB.",
-			})
-			return
-		}
-
-		// Generate unique filename
-		ext := filepath.Ext(file.Filename)
-		if ext == "" {
-			ext = ".jpg"
-		}
-		filename := fmt.Sprintf("epod-photo-%d-%d%s", id, time.Now().UnixNano(), ext)
-		uploadPath := filepath.Join("uploads", "epod", filename)
-
-		// Ensure directory exists
-		if err := os.MkdirAll(filepath.Dir(uploadPath), 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success":    false,
-				"error_code": "INTERNAL_ERROR",
-				"message":    "Gagal membuat direktori upload",
-			})
-			return
-		}
-
-		// Save file
-		if err := c.SaveUploadedFile(file, uploadPath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success":    false,
-				"error_code": "INTERNAL_ERROR",
-				"message":    "Gagal menyimpan file",
-			})
+		stored, err := h.uploader.SaveMultipart(c.Request.Context(), storage.CategoryEPODPhoto, fmt.Sprintf("epod-photo-%d", id), file)
+		if err != nil {
+			respondUploadError(c, storage.CategoryEPODPhoto, err)
 			return
 		}
-
-		// Generate URL
-		photoURL = fmt.Sprintf("/uploads/epod/%s", filename)
+		photoURL = stored.Ref
 	} else {
 		// Handle JSON request
 		var req UploadEPODPhotoRequest
@@ -1075,46 +1042,12 @@ func (h *LogisticsHandler) UploadEPODSignature(c *gin.Context) {
 			return
 		}
 
-		// Validate file size (max 2MB for signatures)
-		if file.Size > 2*1024*1024 {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"success":    false,
-				"error_code": "FILE_TOO_LARGE",
-				"message":    "Ukuran file terlalu besar. Maksimal 2MB.",
-			})
-			return
-		}
-
-		// Generate unique filename
-		ext := filepath.Ext(file.Filename)
-		if ext == "" {
-			ext = ".png"
-		}
-		filename := fmt.Sprintf("epod-signature-%d-%d%s", id, time.Now().UnixNano(), ext)
-		uploadPath := filepath.Join("uploads", "epod", filename)
-
-		// Ensure directory exists
-		if err := os.MkdirAll(filepath.Dir(uploadPath), 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success":    false,
-				"error_code": "INTERNAL_ERROR",
-				"message":    "Gagal membuat direktori upload",
-			})
-			return
-		}
-
-		// Save file
-		if err := c.SaveUploadedFile(file, uploadPath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success":    false,
-				"error_code": "INTERNAL_ERROR",
-				"message":    "Gagal menyimpan file",
-			})
+		stored, err := h.uploader.SaveMultipart(c.Request.Context(), storage.CategoryEPODSignature, fmt.Sprintf("epod-signature-%d", id), file)
+		if err != nil {
+			respondUploadError(c, storage.CategoryEPODSignature, err)
 			return
 		}
-
-		// Generate URL
-		signatureURL = fmt.Sprintf("/uploads/epod/%s", filename)
+		signatureURL = stored.Ref
 	} else {
 		// Handle JSON request
 		var req UploadEPODSignatureRequest
@@ -1346,66 +1279,16 @@ func (h *LogisticsHandler) UploadCooperationLetter(c *gin.Context) {
 		return
 	}
 
-	// Validate file size (max 5MB for documents)
-	if file.Size > 5*1024*1024 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success":    false,
-			"error_code": "FILE_TOO_LARGE",
-			"message":    "Ukuran file maksimal 5MB",
-		})
-		return
-	}
-
-	// Validate file type (PDF, DOC, DOCX, JPG, PNG)
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	allowedExts := map[string]bool{
-		".pdf":  true,
-		".doc":  true,
-		".docx": true,
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-	}
-	if !allowedExts[ext] {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success":    false,
-			"error_code": "INVALID_FILE_TYPE",
-			"message":    "Format file harus PDF, DOC, DOCX, JPG, JPEG, atau PNG",
-		})
-		return
-	}
-
-	// Create uploads directory if not exists
-	uploadDir := "./uploads/cooperation-letters"
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error_code": "INTERNAL_ERROR",
-			"message":    "Gagal membuat direktori upload",
-		})
-		return
-	}
-
-	// Generate unique filename
-	filename := fmt.Sprintf("%s_%d%s", uuid.New().String(), time.Now().Unix(), ext)
-	filePath := filepath.Join(uploadDir, filename)
-
-	// Save file
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error_code": "INTERNAL_ERROR",
-			"message":    "Gagal menyimpan file",
-		})
+	stored, err := h.uploader.SaveMultipart(c.Request.Context(), storage.CategoryCooperationLetter, "cooperation-letter", file)
+	if err != nil {
+		respondUploadError(c, storage.CategoryCooperationLetter, err)
 		return
 	}
 
-	// Return URL
-	fileURL := fmt.Sprintf("/uploads/cooperation-letters/%s", filename)
 	c.JSON(http.StatusOK, gin.H{
 		"success":  true,
 		"message":  "File berhasil diupload",
-		"file_url": fileURL,
+		"file_url": stored.Ref,
 	})
 }
 
@@ -1421,9 +1304,9 @@ func (h *LogisticsHandler) DeleteCooperationLetter(c *gin.Context) {
 		return
 	}
 
-	// Extract filename from URL
-	parts := strings.Split(fileURL, "/")
-	if len(parts) < 2 {
+	// Only cooperation letters can be deleted here
+	key, ok := storage.KeyFromRef(fileURL)
+	if !ok || !strings.HasPrefix(key, storage.CategoryCooperationLetter+"/") {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success":    false,
 			"error_code": "INVALID_FILE_URL",
@@ -1432,21 +1315,24 @@ func (h *LogisticsHandler) DeleteCooperationLetter(c *gin.Context) {
 		return
 	}
 
-	filename := parts[len(parts)-1]
-	filePath := filepath.Join("./uploads/cooperation-letters", filename)
-
 	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success":    false,
-			"error_code": "FILE_NOT_FOUND",
-			"message":    "File tidak ditemukan",
-		})
+	file, err := h.uploader.Open(c.Request.Context(), fileURL)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success":    false,
+				"error_code": "FILE_NOT_FOUND",
+				"message":    "File tidak ditemukan",
+			})
+			return
+		}
+		respondFileError(c, err)
 		return
 	}
+	file.Close()
 
 	// Delete file
-	if err := os.Remove(filePath); err != nil {
+	if err := h.uploader.Delete(c.Request.Context(), storage.CategoryCooperationLetter, fileURL); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success":    false,
 			"error_code": "INTERNAL_ERROR",
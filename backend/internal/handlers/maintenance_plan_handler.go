@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenancePlanHandler handles preventive maintenance endpoints
+type MaintenancePlanHandler struct {
+	maintenancePlanService *services.MaintenancePlanService
+}
+
+// NewMaintenancePlanHandler creates a new maintenance plan handler
+func NewMaintenancePlanHandler(maintenancePlanService *services.MaintenancePlanService) *MaintenancePlanHandler {
+	return &MaintenancePlanHandler{
+		maintenancePlanService: maintenancePlanService,
+	}
+}
+
+// MaintenancePlanRequest represents a create or update maintenance plan request
+type MaintenancePlanRequest struct {
+	AssetID           uint         `json:"asset_id" binding:"required"`
+	Name              string       `json:"name" binding:"required"`
+	Description       string       `json:"description"`
+	IntervalType      string       `json:"interval_type" binding:"required,oneof=days usage"`
+	IntervalDays      int          `json:"interval_days" binding:"gte=0"`
+	IntervalUnits     float64      `json:"interval_units" binding:"gte=0"`
+	IsSafetyCritical  bool         `json:"is_safety_critical"`
+	EstimatedCost     models.Money `json:"estimated_cost" binding:"gte=0"`
+	ResponsibleUserID uint         `json:"responsible_user_id" binding:"required"`
+	StartDate         string       `json:"start_date"` // YYYY-MM-DD, defaults to today
+	IsActive          *bool        `json:"is_active"`
+}
+
+// CompleteMaintenanceRequest represents the completion of a maintenance task
+type CompleteMaintenanceRequest struct {
+	Date        string       `json:"date"` // YYYY-MM-DD, defaults to today
+	Cost        models.Money `json:"cost" binding:"gte=0"`
+	PerformedBy string       `json:"performed_by"`
+	Description string       `json:"description"`
+}
+
+// CreatePlan creates a maintenance plan
+func (h *MaintenancePlanHandler) CreatePlan(c *gin.Context) {
+	var req MaintenancePlanRequest
+	if !bindMaintenanceJSON(c, &req) {
+		return
+	}
+
+	plan := maintenancePlanFromRequest(&req)
+	if req.StartDate != "" {
+		startDate, err := time.ParseInLocation("2006-01-02", req.StartDate, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "INVALID_DATE",
+				"message":    "Format start_date tidak valid (gunakan YYYY-MM-DD)",
+			})
+			return
+		}
+		plan.StartDate = startDate
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.maintenancePlanService.CreatePlan(plan, userID.(uint)); err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Rencana pemeliharaan berhasil dibuat",
+		"data":    plan,
+	})
+}
+
+// GetPlans lists maintenance plans. Query params: asset_id, responsible_user_id
+func (h *MaintenancePlanHandler) GetPlans(c *gin.Context) {
+	assetID, _ := strconv.ParseUint(c.Query("asset_id"), 10, 32)
+	responsibleUserID, _ := strconv.ParseUint(c.Query("responsible_user_id"), 10, 32)
+
+	plans, err := h.maintenancePlanService.GetPlans(uint(assetID), uint(responsibleUserID))
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    plans,
+	})
+}
+
+// GetPlan returns a maintenance plan
+func (h *MaintenancePlanHandler) GetPlan(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	plan, err := h.maintenancePlanService.GetPlan(id)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    plan,
+	})
+}
+
+// UpdatePlan updates a maintenance plan
+func (h *MaintenancePlanHandler) UpdatePlan(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req MaintenancePlanRequest
+	if !bindMaintenanceJSON(c, &req) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	plan, err := h.maintenancePlanService.UpdatePlan(id, maintenancePlanFromRequest(&req), userID.(uint))
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Rencana pemeliharaan berhasil diperbarui",
+		"data":    plan,
+	})
+}
+
+// GetPlanHistory lists the maintenance records completing a plan
+func (h *MaintenancePlanHandler) GetPlanHistory(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	records, err := h.maintenancePlanService.GetPlanHistory(id)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    records,
+	})
+}
+
+// CompletePlanTask completes the current task of a plan, recording the
+// maintenance and its cost
+func (h *MaintenancePlanHandler) CompletePlanTask(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req CompleteMaintenanceRequest
+	if !bindMaintenanceJSON(c, &req) {
+		return
+	}
+
+	completion := services.MaintenanceCompletion{
+		Cost:        req.Cost,
+		PerformedBy: req.PerformedBy,
+		Description: req.Description,
+	}
+	if req.Date != "" {
+		date, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "INVALID_DATE",
+				"message":    "Format tanggal tidak valid (gunakan YYYY-MM-DD)",
+			})
+			return
+		}
+		completion.Date = date
+	}
+
+	userID, _ := c.Get("user_id")
+	maintenance, err := h.maintenancePlanService.CompleteTask(id, completion, userID.(uint))
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Pemeliharaan berhasil dicatat",
+		"data":    maintenance,
+	})
+}
+
+// GetCalendar returns the due, overdue and upcoming maintenance tasks.
+// Query params: start_date, end_date (default: today to 30 days ahead),
+// asset_id, mine (only the tasks of the current user)
+func (h *MaintenancePlanHandler) GetCalendar(c *gin.Context) {
+	today := time.Now()
+	startDate, ok := parseLedgerDate(c, "start_date", today)
+	if !ok {
+		return
+	}
+	endDate, ok := parseLedgerDate(c, "end_date", startDate.AddDate(0, 0, 30))
+	if !ok {
+		return
+	}
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_DATE_RANGE",
+			"message":    "Tanggal akhir tidak boleh sebelum tanggal awal",
+		})
+		return
+	}
+
+	assetID, _ := strconv.ParseUint(c.Query("asset_id"), 10, 32)
+	filter := services.MaintenanceCalendarFilter{AssetID: uint(assetID)}
+	if c.Query("mine") == "true" {
+		userID, _ := c.Get("user_id")
+		filter.ResponsibleUserID = userID.(uint)
+	}
+
+	tasks, err := h.maintenancePlanService.GetCalendar(startDate, endDate, filter)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tasks,
+	})
+}
+
+// GetAssetAlerts returns the assets in poor condition or overdue for
+// safety-critical maintenance
+func (h *MaintenancePlanHandler) GetAssetAlerts(c *gin.Context) {
+	alerts, err := h.maintenancePlanService.GetAssetAlerts()
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    alerts,
+	})
+}
+
+// maintenancePlanFromRequest maps a plan request to the model
+func maintenancePlanFromRequest(req *MaintenancePlanRequest) *models.MaintenancePlan {
+	plan := &models.MaintenancePlan{
+		AssetID:           req.AssetID,
+		Name:              req.Name,
+		Description:       req.Description,
+		IntervalType:      req.IntervalType,
+		IntervalDays:      req.IntervalDays,
+		IntervalUnits:     req.IntervalUnits,
+		IsSafetyCritical:  req.IsSafetyCritical,
+		EstimatedCost:     req.EstimatedCost,
+		ResponsibleUserID: req.ResponsibleUserID,
+		IsActive:          true,
+	}
+	if req.IsActive != nil {
+		plan.IsActive = *req.IsActive
+	}
+	return plan
+}
+
+// bindMaintenanceJSON binds a JSON body, responding with a validation error on failure
+func bindMaintenanceJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// respondMaintenanceError maps maintenance plan service errors to HTTP responses
+func respondMaintenanceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMaintenancePlanNotFound), errors.Is(err, services.ErrAssetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrMaintenancePlanInactive), errors.Is(err, services.ErrAssetNotActive):
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "INVALID_STATE",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrMaintenancePlanNameRequired), errors.Is(err, services.ErrInvalidMaintenanceInterval),
+		errors.Is(err, services.ErrInvalidMaintenanceResponsible), errors.Is(err, services.ErrInvalidMaintenanceCompletion):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+	default:
+		log.Printf("[MAINTENANCE] error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
@@ -13,13 +13,15 @@ import (
 // MonitoringHandler handles logistics monitoring HTTP requests
 type MonitoringHandler struct {
 	monitoringService *services.MonitoringService
+	statusAuthorizer  *middleware.StatusCategoryAuthorizer
 }
 
 // NewMonitoringHandler creates a new monitoring handler instance
 // Requirements: 1.1
-func NewMonitoringHandler(monitoringService *services.MonitoringService) *MonitoringHandler {
+func NewMonitoringHandler(monitoringService *services.MonitoringService, statusAuthorizer *middleware.StatusCategoryAuthorizer) *MonitoringHandler {
 	return &MonitoringHandler{
 		monitoringService: monitoringService,
+		statusAuthorizer:  statusAuthorizer,
 	}
 }
 
@@ -221,7 +223,8 @@ func (h *MonitoringHandler) UpdateStatus(c *gin.Context) {
 	// - Driver can update delivery statuses
 	// - Cleaning staff can update cleaning statuses
 	// - kepala_sppg and kepala_yayasan can override any status
-	if !middleware.ValidateStatusUpdatePermission(userRole.(string), req.Status) {
+	// The defaults can be changed per role or per user in the permission tables
+	if !h.statusAuthorizer.CheckStatusUpdatePermission(userID.(uint), userRole.(string), req.Status) {
 		c.JSON(http.StatusForbidden, gin.H{
 			"success":    false,
 			"error_code": "FORBIDDEN",
@@ -4,7 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
-	firebase "firebase.google.com/go/v4"
+	"github.com/erp-sppg/backend/internal/realtime"
 	"github.com/erp-sppg/backend/internal/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -16,8 +16,8 @@ type NotificationHandler struct {
 }
 
 // NewNotificationHandler creates a new notification handler
-func NewNotificationHandler(db *gorm.DB, firebaseApp *firebase.App) (*NotificationHandler, error) {
-	notificationService, err := services.NewNotificationService(db, firebaseApp)
+func NewNotificationHandler(db *gorm.DB, publisher realtime.RealtimePublisher) (*NotificationHandler, error) {
+	notificationService, err := services.NewNotificationService(db, publisher)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OutboxHandler lets administrators inspect and replay outbox events
+type OutboxHandler struct {
+	outboxService *services.OutboxService
+	auditService  *services.AuditTrailService
+}
+
+// NewOutboxHandler creates a new outbox handler
+func NewOutboxHandler(db *gorm.DB) *OutboxHandler {
+	return &OutboxHandler{
+		outboxService: services.NewOutboxService(db),
+		auditService:  services.NewAuditTrailService(db),
+	}
+}
+
+// GetOutboxEventsRequest represents the query parameters for listing outbox events
+type GetOutboxEventsRequest struct {
+	Page      int    `form:"page,default=1" binding:"min=1"`
+	PageSize  int    `form:"page_size,default=20" binding:"min=1,max=100"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending processing delivered dead"`
+	EventType string `form:"event_type"`
+}
+
+// GetEvents lists outbox events, newest first
+// GET /api/v1/system/outbox?status=dead&event_type=realtime.delivery_record
+func (h *OutboxHandler) GetEvents(c *gin.Context) {
+	var req GetOutboxEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Parameter tidak valid",
+		})
+		return
+	}
+
+	events, total, err := h.outboxService.ListEvents(req.Status, req.EventType, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		respondOutboxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"data":        events,
+		"total":       total,
+		"page":        req.Page,
+		"page_size":   req.PageSize,
+		"total_pages": (total + int64(req.PageSize) - 1) / int64(req.PageSize),
+	})
+}
+
+// GetStats counts the outbox events per status
+// GET /api/v1/system/outbox/stats
+func (h *OutboxHandler) GetStats(c *gin.Context) {
+	stats, err := h.outboxService.GetStats()
+	if err != nil {
+		respondOutboxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// ReplayEvent puts a dead or pending event back in the queue
+// POST /api/v1/system/outbox/:id/replay
+func (h *OutboxHandler) ReplayEvent(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.outboxService.ReplayEvent(id)
+	if err != nil {
+		respondOutboxError(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	h.auditService.RecordAction(userID.(uint), "replay", "outbox_event", fmt.Sprint(id), nil, nil, c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Event dijadwalkan ulang untuk dikirim",
+		"data":    event,
+	})
+}
+
+// ReplayDeadEvents puts every dead-lettered event back in the queue
+// POST /api/v1/system/outbox/replay-dead
+func (h *OutboxHandler) ReplayDeadEvents(c *gin.Context) {
+	count, err := h.outboxService.ReplayDeadEvents()
+	if err != nil {
+		respondOutboxError(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	h.auditService.RecordAction(userID.(uint), "replay", "outbox_event", "dead", nil, gin.H{"count": count}, c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("%d event dijadwalkan ulang untuk dikirim", count),
+		"data":    gin.H{"replayed": count},
+	})
+}
+
+func respondOutboxError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrOutboxEventNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrOutboxEventNotReplayable):
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "INVALID_STATE",
+			"message":    err.Error(),
+		})
+	default:
+		log.Printf("[OUTBOX] error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
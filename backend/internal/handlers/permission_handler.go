@@ -0,0 +1,412 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionHandler handles role and permission HTTP requests
+type PermissionHandler struct {
+	permissionService *services.PermissionService
+}
+
+// NewPermissionHandler creates a new permission handler
+func NewPermissionHandler(permissionService *services.PermissionService) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: permissionService,
+	}
+}
+
+// CreateRoleRequest represents create role request
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	DisplayName string   `json:"display_name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest represents update role request
+type UpdateRoleRequest struct {
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+// SetRolePermissionsRequest represents the full permission list of a role
+type SetRolePermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// SetUserOverrideRequest represents a per-user permission override
+type SetUserOverrideRequest struct {
+	Permission string `json:"permission" binding:"required"`
+	Granted    bool   `json:"granted"`
+	Reason     string `json:"reason"`
+}
+
+// GetMyPermissions returns the effective permissions of the current user
+func (h *PermissionHandler) GetMyPermissions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userRole, _ := c.Get("user_role")
+
+	permissions, err := h.permissionService.GetEffectivePermissions(userID.(uint), userRole.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    permissions,
+	})
+}
+
+// GetRoles lists all roles with their permissions
+func (h *PermissionHandler) GetRoles(c *gin.Context) {
+	roles, err := h.permissionService.ListRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    roles,
+	})
+}
+
+// GetRole returns a single role
+func (h *PermissionHandler) GetRole(c *gin.Context) {
+	id, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	role, err := h.permissionService.GetRole(id)
+	if err != nil {
+		respondPermissionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    role,
+	})
+}
+
+// CreateRole creates a custom role
+func (h *PermissionHandler) CreateRole(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	role := &models.Role{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+	}
+	if err := h.permissionService.CreateRole(role, req.Permissions, userID.(uint)); err != nil {
+		respondPermissionError(c, err)
+		return
+	}
+
+	created, err := h.permissionService.GetRole(role.ID)
+	if err != nil {
+		respondPermissionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Role berhasil dibuat",
+		"data":    created,
+	})
+}
+
+// UpdateRole updates the display name, description or active flag of a role
+func (h *PermissionHandler) UpdateRole(c *gin.Context) {
+	id, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	existing, err := h.permissionService.GetRole(id)
+	if err != nil {
+		respondPermissionError(c, err)
+		return
+	}
+	isActive := existing.IsActive
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	userID, _ := c.Get("user_id")
+	role, err := h.permissionService.UpdateRole(id, req.DisplayName, req.Description, isActive, userID.(uint))
+	if err != nil {
+		respondPermissionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Role berhasil diperbarui",
+		"data":    role,
+	})
+}
+
+// DeleteRole deletes an unused custom role
+func (h *PermissionHandler) DeleteRole(c *gin.Context) {
+	id, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.permissionService.DeleteRole(id, userID.(uint)); err != nil {
+		respondPermissionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Role berhasil dihapus",
+	})
+}
+
+// SetRolePermissions replaces the permissions granted to a role
+func (h *PermissionHandler) SetRolePermissions(c *gin.Context) {
+	id, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	var req SetRolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.permissionService.SetRolePermissions(id, req.Permissions, userID.(uint)); err != nil {
+		respondPermissionError(c, err)
+		return
+	}
+
+	role, err := h.permissionService.GetRole(id)
+	if err != nil {
+		respondPermissionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Permission role berhasil diperbarui",
+		"data":    role,
+	})
+}
+
+// GetPermissions lists every permission
+func (h *PermissionHandler) GetPermissions(c *gin.Context) {
+	permissions, err := h.permissionService.ListPermissions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    permissions,
+	})
+}
+
+// GetUserOverrides lists the permission overrides of a user
+func (h *PermissionHandler) GetUserOverrides(c *gin.Context) {
+	targetID, ok := parseOverrideUserID(c)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.permissionService.ListUserOverrides(targetID)
+	if err != nil {
+		respondPermissionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    overrides,
+	})
+}
+
+// SetUserOverride grants or revokes a permission for a single user
+func (h *PermissionHandler) SetUserOverride(c *gin.Context) {
+	targetID, ok := parseOverrideUserID(c)
+	if !ok {
+		return
+	}
+
+	var req SetUserOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	override, err := h.permissionService.SetUserOverride(targetID, req.Permission, req.Granted, req.Reason, userID.(uint))
+	if err != nil {
+		respondPermissionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Override permission berhasil disimpan",
+		"data":    override,
+	})
+}
+
+// RemoveUserOverride removes a user's override so the role permission applies again
+func (h *PermissionHandler) RemoveUserOverride(c *gin.Context) {
+	targetID, ok := parseOverrideUserID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.permissionService.RemoveUserOverride(targetID, c.Param("code"), userID.(uint)); err != nil {
+		respondPermissionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Override permission berhasil dihapus",
+	})
+}
+
+// parseRoleID reads the :id parameter
+func parseRoleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_ID",
+			"message":    "ID tidak valid",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseOverrideUserID reads the :user_id parameter
+func parseOverrideUserID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INVALID_ID",
+			"message":    "ID pengguna tidak valid",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondPermissionError maps permission service errors to HTTP responses
+func respondPermissionError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrRoleNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "ROLE_NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case services.ErrPermissionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "PERMISSION_NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case services.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "USER_NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case services.ErrRoleAlreadyExists:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "ROLE_EXISTS",
+			"message":    err.Error(),
+		})
+	case services.ErrSystemRole:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "SYSTEM_ROLE",
+			"message":    err.Error(),
+		})
+	case services.ErrRoleInUse:
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "ROLE_IN_USE",
+			"message":    err.Error(),
+		})
+	case services.ErrInvalidRoleName:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
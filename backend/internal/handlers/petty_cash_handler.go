@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/erp-sppg/backend/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// PettyCashHandler handles petty cash endpoints
+type PettyCashHandler struct {
+	pettyCashService *services.PettyCashService
+	uploader         *storage.Uploader
+}
+
+// NewPettyCashHandler creates a new petty cash handler
+func NewPettyCashHandler(pettyCashService *services.PettyCashService, uploader *storage.Uploader) *PettyCashHandler {
+	return &PettyCashHandler{
+		pettyCashService: pettyCashService,
+		uploader:         uploader,
+	}
+}
+
+// PettyCashFundRequest represents a create or update petty cash fund request
+type PettyCashFundRequest struct {
+	Name        string       `json:"name" binding:"required"`
+	CustodianID uint         `json:"custodian_id" binding:"required"`
+	FloatAmount models.Money `json:"float_amount" binding:"required,gt=0"`
+	IsActive    *bool        `json:"is_active"`
+}
+
+// PettyCashReplenishmentRequest represents a replenishment request
+type PettyCashReplenishmentRequest struct {
+	Notes string `json:"notes"`
+}
+
+// RejectReplenishmentRequest represents the rejection of a replenishment
+type RejectReplenishmentRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// CreateFund creates a petty cash fund
+func (h *PettyCashHandler) CreateFund(c *gin.Context) {
+	var req PettyCashFundRequest
+	if !bindPettyCashJSON(c, &req) {
+		return
+	}
+
+	fund := &models.PettyCashFund{
+		Name:        req.Name,
+		CustodianID: req.CustodianID,
+		FloatAmount: req.FloatAmount,
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.pettyCashService.CreateFund(fund, userID.(uint)); err != nil {
+		respondPettyCashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Dana kas kecil berhasil dibuat",
+		"data":    fund,
+	})
+}
+
+// GetFunds lists petty cash funds. Query params: custodian_id
+func (h *PettyCashHandler) GetFunds(c *gin.Context) {
+	custodianID, _ := strconv.ParseUint(c.Query("custodian_id"), 10, 32)
+	funds, err := h.pettyCashService.GetFunds(uint(custodianID))
+	if err != nil {
+		respondPettyCashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    funds,
+	})
+}
+
+// GetFund returns a petty cash fund
+func (h *PettyCashHandler) GetFund(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	fund, err := h.pettyCashService.GetFund(id)
+	if err != nil {
+		respondPettyCashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fund,
+	})
+}
+
+// UpdateFund updates a petty cash fund
+func (h *PettyCashHandler) UpdateFund(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req PettyCashFundRequest
+	if !bindPettyCashJSON(c, &req) {
+		return
+	}
+
+	updates := &models.PettyCashFund{
+		Name:        req.Name,
+		CustodianID: req.CustodianID,
+		FloatAmount: req.FloatAmount,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		updates.IsActive = *req.IsActive
+	}
+	userID, _ := c.Get("user_id")
+	fund, err := h.pettyCashService.UpdateFund(id, updates, userID.(uint))
+	if err != nil {
+		respondPettyCashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Dana kas kecil berhasil diperbarui",
+		"data":    fund,
+	})
+}
+
+// CreateExpense records a petty cash expense with its receipt photo.
+// Form fields: receipt_photo (file), expense_date, category, description,
+// amount, ingredient_id, quantity.
+func (h *PettyCashHandler) CreateExpense(c *gin.Context) {
+	fundID, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	expense := &models.PettyCashExpense{
+		Category:    c.PostForm("category"),
+		Description: c.PostForm("description"),
+	}
+	var err error
+	if expense.Amount, err = models.ParseMoney(c.PostForm("amount")); err != nil {
+		respondPettyCashValidation(c, "Jumlah pengeluaran tidak valid")
+		return
+	}
+	if dateStr := c.PostForm("expense_date"); dateStr != "" {
+		if expense.ExpenseDate, err = time.ParseInLocation("2006-01-02", dateStr, time.Local); err != nil {
+			respondPettyCashValidation(c, "Format expense_date tidak valid (gunakan YYYY-MM-DD)")
+			return
+		}
+	}
+	if ingredientStr := c.PostForm("ingredient_id"); ingredientStr != "" {
+		ingredientID, err := strconv.ParseUint(ingredientStr, 10, 32)
+		if err != nil {
+			respondPettyCashValidation(c, "ID bahan baku tidak valid")
+			return
+		}
+		id := uint(ingredientID)
+		expense.IngredientID = &id
+		if expense.Quantity, err = strconv.ParseFloat(c.PostForm("quantity"), 64); err != nil {
+			respondPettyCashValidation(c, "Jumlah bahan tidak valid")
+			return
+		}
+	}
+	if strings.TrimSpace(expense.Description) == "" {
+		respondPettyCashValidation(c, "Keterangan pengeluaran wajib diisi")
+		return
+	}
+
+	file, err := c.FormFile("receipt_photo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "NO_FILE",
+			"message":    "Foto nota wajib dilampirkan",
+		})
+		return
+	}
+	stored, err := h.uploader.SaveMultipart(c.Request.Context(), storage.CategoryPettyCashReceipt, fmt.Sprintf("receipt-%d", fundID), file)
+	if err != nil {
+		respondUploadError(c, storage.CategoryPettyCashReceipt, err)
+		return
+	}
+	expense.ReceiptPhoto = stored.Ref
+
+	userID, _ := c.Get("user_id")
+	if err := h.pettyCashService.RecordExpense(fundID, expense, userID.(uint)); err != nil {
+		h.uploader.Delete(c.Request.Context(), storage.CategoryPettyCashReceipt, stored.Ref)
+		respondPettyCashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Pengeluaran kas kecil berhasil dicatat",
+		"data":    expense,
+	})
+}
+
+// GetExpenses lists the expenses of a fund. Query params: start_date, end_date
+func (h *PettyCashHandler) GetExpenses(c *gin.Context) {
+	fundID, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var startDate, endDate *time.Time
+	if startStr := c.Query("start_date"); startStr != "" {
+		if sd, err := time.ParseInLocation("2006-01-02", startStr, time.Local); err == nil {
+			startDate = &sd
+		}
+	}
+	if endStr := c.Query("end_date"); endStr != "" {
+		if ed, err := time.ParseInLocation("2006-01-02", endStr, time.Local); err == nil {
+			endDate = &ed
+		}
+	}
+
+	expenses, err := h.pettyCashService.GetExpenses(fundID, startDate, endDate)
+	if err != nil {
+		respondPettyCashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    expenses,
+	})
+}
+
+// RequestReplenishment requests a top-up of a fund for its unreplenished expenses
+func (h *PettyCashHandler) RequestReplenishment(c *gin.Context) {
+	fundID, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req PettyCashReplenishmentRequest
+	if c.Request.ContentLength > 0 && !bindPettyCashJSON(c, &req) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	replenishment, err := h.pettyCashService.RequestReplenishment(fundID, req.Notes, userID.(uint))
+	if err != nil {
+		respondPettyCashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Permintaan penggantian kas kecil berhasil diajukan",
+		"data":    replenishment,
+	})
+}
+
+// GetReplenishments lists replenishment requests. Query params: fund_id, status
+func (h *PettyCashHandler) GetReplenishments(c *gin.Context) {
+	fundID, _ := strconv.ParseUint(c.Query("fund_id"), 10, 32)
+	replenishments, err := h.pettyCashService.GetReplenishments(uint(fundID), c.Query("status"))
+	if err != nil {
+		respondPettyCashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    replenishments,
+	})
+}
+
+// GetReplenishment returns a replenishment request with its expenses
+func (h *PettyCashHandler) GetReplenishment(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	replenishment, err := h.pettyCashService.GetReplenishment(id)
+	if err != nil {
+		respondPettyCashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    replenishment,
+	})
+}
+
+// ApproveReplenishment approves a replenishment request
+func (h *PettyCashHandler) ApproveReplenishment(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	replenishment, err := h.pettyCashService.ApproveReplenishment(id, userID.(uint))
+	if err != nil {
+		respondPettyCashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Penggantian kas kecil berhasil disetujui",
+		"data":    replenishment,
+	})
+}
+
+// RejectReplenishment rejects a replenishment request
+func (h *PettyCashHandler) RejectReplenishment(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req RejectReplenishmentRequest
+	if !bindPettyCashJSON(c, &req) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	replenishment, err := h.pettyCashService.RejectReplenishment(id, userID.(uint), req.Reason)
+	if err != nil {
+		respondPettyCashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Penggantian kas kecil ditolak",
+		"data":    replenishment,
+	})
+}
+
+// bindPettyCashJSON binds a JSON request body, responding with a validation error on failure
+func bindPettyCashJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// respondPettyCashValidation responds with a validation error message
+func respondPettyCashValidation(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"success":    false,
+		"error_code": "VALIDATION_ERROR",
+		"message":    message,
+	})
+}
+
+// respondPettyCashError maps petty cash service errors to HTTP responses
+func respondPettyCashError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPettyCashFundNotFound), errors.Is(err, services.ErrPettyCashReplenishmentNotFound),
+		errors.Is(err, services.ErrIngredientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrNotPettyCashCustodian), errors.Is(err, services.ErrUnauthorized),
+		errors.Is(err, services.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error_code": "FORBIDDEN",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrPettyCashFundInactive), errors.Is(err, services.ErrReplenishmentPending),
+		errors.Is(err, services.ErrReplenishmentNotPending), errors.Is(err, services.ErrNothingToReplenish):
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "INVALID_STATE",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrInsufficientPettyCash):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "INSUFFICIENT_BALANCE",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidPettyCashFloat), errors.Is(err, services.ErrPettyCashFloatBelowSpent),
+		errors.Is(err, services.ErrInvalidPettyCashCustodian), errors.Is(err, services.ErrInvalidPettyCashAmount),
+		errors.Is(err, services.ErrReceiptPhotoRequired), errors.Is(err, services.ErrInvalidPettyCashQuantity),
+		errors.Is(err, services.ErrRejectionReasonRequired), errors.Is(err, services.ErrInvalidCategory):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+	default:
+		log.Printf("[PETTY CASH] error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/realtime"
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// realtimeHeartbeat keeps idle connections open through proxies
+const realtimeHeartbeat = 25 * time.Second
+
+// realtimeTopicPermissions maps the first segment of a topic to the
+// permission needed to subscribe to it
+var realtimeTopicPermissions = map[string]string{
+	"kds":        "kitchen_display",
+	"monitoring": "monitoring",
+	"delivery":   "monitoring",
+	"cleaning":   "cleaning",
+	"dashboard":  "dashboard",
+	"inventory":  "inventory",
+}
+
+// RealtimeHandler serves the topics of the built-in realtime hub over
+// WebSocket and Server-Sent Events
+type RealtimeHandler struct {
+	hub               *realtime.Hub
+	permissionService *services.PermissionService
+	upgrader          websocket.Upgrader
+}
+
+// realtimeClientMessage is a subscription request sent over a WebSocket
+type realtimeClientMessage struct {
+	Action string `json:"action"`
+	Topic  string `json:"topic"`
+}
+
+// NewRealtimeHandler creates a new realtime handler. WebSocket connections
+// from browsers are only accepted from the allowed CORS origins.
+func NewRealtimeHandler(hub *realtime.Hub, permissionService *services.PermissionService, allowedOrigins []string) *RealtimeHandler {
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		origins[strings.TrimSpace(origin)] = true
+	}
+
+	return &RealtimeHandler{
+		hub:               hub,
+		permissionService: permissionService,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 4096,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || origins["*"] || origins[origin]
+			},
+		},
+	}
+}
+
+// CanSubscribe reports whether a user may receive the events of a topic.
+// Personal notification topics are only open to their owner.
+func (h *RealtimeHandler) CanSubscribe(userID uint, role, topic string) bool {
+	segments := strings.Split(realtime.NormalizePath(topic), "/")
+	if segments[0] == "" {
+		return false
+	}
+
+	if segments[0] == realtime.TopicNotifications {
+		if len(segments) < 2 {
+			return false
+		}
+		if owner, err := strconv.ParseUint(segments[1], 10, 64); err == nil {
+			return uint(owner) == userID
+		}
+		// Shared notification channels such as notifications/logistics
+		return h.permissionService.HasPermission(userID, role, "monitoring")
+	}
+
+	permission, ok := realtimeTopicPermissions[segments[0]]
+	return ok && h.permissionService.HasPermission(userID, role, permission)
+}
+
+// authorizeTopics checks every topic of the request and writes the error
+// response when one is missing or forbidden
+func (h *RealtimeHandler) authorizeTopics(c *gin.Context, topics []string) bool {
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(uint)
+	role := c.GetString("user_role")
+
+	for _, topic := range topics {
+		if !h.CanSubscribe(id, role, topic) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success":    false,
+				"error_code": "FORBIDDEN",
+				"message":    fmt.Sprintf("Anda tidak memiliki izin untuk topik %s", topic),
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// StreamEvents streams the events of one or more topics as Server-Sent Events
+// GET /api/v1/realtime/sse?topic=kds/cooking/2026-03-02&topic=notifications/7
+func (h *RealtimeHandler) StreamEvents(c *gin.Context) {
+	topics := c.QueryArray("topic")
+	if len(topics) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Minimal satu topic wajib diisi",
+		})
+		return
+	}
+	if !h.authorizeTopics(c, topics) {
+		return
+	}
+
+	events, closeAll := h.subscribeAll(topics)
+	defer closeAll()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(realtimeHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-events:
+			if !ok {
+				// Dropped by the hub; the client reconnects for a fresh snapshot
+				return
+			}
+			data, _ := json.Marshal(event)
+			if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// ServeWebSocket upgrades to a WebSocket on which the client subscribes with
+// {"action":"subscribe","topic":"..."} and unsubscribes with
+// {"action":"unsubscribe","topic":"..."}. Topics may also be given as query
+// parameters.
+// GET /api/v1/realtime/ws
+func (h *RealtimeHandler) ServeWebSocket(c *gin.Context) {
+	topics := c.QueryArray("topic")
+	if !h.authorizeTopics(c, topics) {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(uint)
+	role := c.GetString("user_role")
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written the error response
+		return
+	}
+	defer conn.Close()
+
+	session := newRealtimeSession(h.hub)
+	defer session.closeAll()
+	for _, topic := range topics {
+		session.subscribe(topic)
+	}
+
+	// Reader: subscription requests from the client
+	go func() {
+		defer session.stop()
+		for {
+			var message realtimeClientMessage
+			if err := conn.ReadJSON(&message); err != nil {
+				return
+			}
+			topic := realtime.NormalizePath(message.Topic)
+			switch message.Action {
+			case "subscribe":
+				if !h.CanSubscribe(id, role, topic) {
+					session.send(realtime.Event{Type: "error", Topic: topic, Data: realtimeErrorData("Anda tidak memiliki izin untuk topik ini")})
+					continue
+				}
+				session.subscribe(topic)
+			case "unsubscribe":
+				session.unsubscribe(topic)
+			default:
+				session.send(realtime.Event{Type: "error", Topic: topic, Data: realtimeErrorData("Aksi tidak dikenal")})
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(realtimeHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-session.done:
+			return
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+		case event := <-session.events:
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// subscribeAll merges the events of several topics into one channel, which is
+// closed as soon as the hub drops any of the subscriptions
+func (h *RealtimeHandler) subscribeAll(topics []string) (<-chan realtime.Event, func()) {
+	session := newRealtimeSession(h.hub)
+	for _, topic := range topics {
+		session.subscribe(topic)
+	}
+
+	events := make(chan realtime.Event)
+	go func() {
+		defer close(events)
+		for {
+			select {
+			case <-session.done:
+				return
+			case event := <-session.events:
+				select {
+				case events <- event:
+				case <-session.done:
+					return
+				}
+			}
+		}
+	}()
+	return events, session.closeAll
+}
+
+// realtimeSession tracks the subscriptions of one connection
+type realtimeSession struct {
+	hub    *realtime.Hub
+	events chan realtime.Event
+	done   chan struct{}
+
+	mu            sync.Mutex
+	subscriptions map[string]*realtime.Subscription
+	stopOnce      sync.Once
+}
+
+func newRealtimeSession(hub *realtime.Hub) *realtimeSession {
+	return &realtimeSession{
+		hub:           hub,
+		events:        make(chan realtime.Event, 16),
+		done:          make(chan struct{}),
+		subscriptions: make(map[string]*realtime.Subscription),
+	}
+}
+
+func (s *realtimeSession) subscribe(topic string) {
+	topic = realtime.NormalizePath(topic)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.subscriptions[topic]; exists {
+		return
+	}
+
+	sub := s.hub.Subscribe(topic)
+	s.subscriptions[topic] = sub
+	go func() {
+		for event := range sub.Events {
+			if !s.send(event) {
+				return
+			}
+		}
+		// Closed by unsubscribe, or dropped by the hub for falling behind
+		s.mu.Lock()
+		current := s.subscriptions[topic]
+		s.mu.Unlock()
+		if current == sub {
+			s.stop()
+		}
+	}()
+}
+
+func (s *realtimeSession) unsubscribe(topic string) {
+	s.mu.Lock()
+	sub, exists := s.subscriptions[topic]
+	delete(s.subscriptions, topic)
+	s.mu.Unlock()
+	if exists {
+		sub.Close()
+	}
+}
+
+func (s *realtimeSession) send(event realtime.Event) bool {
+	select {
+	case s.events <- event:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+func (s *realtimeSession) stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+func (s *realtimeSession) closeAll() {
+	s.stop()
+	s.mu.Lock()
+	subscriptions := s.subscriptions
+	s.subscriptions = make(map[string]*realtime.Subscription)
+	s.mu.Unlock()
+	for _, sub := range subscriptions {
+		sub.Close()
+	}
+}
+
+func realtimeErrorData(message string) json.RawMessage {
+	data, _ := json.Marshal(gin.H{"message": message})
+	return data
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/realtime"
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupRealtimeTestServer serves the realtime endpoints; the X-Test-User and
+// X-Test-Role headers stand in for the JWT middleware
+func setupRealtimeTestServer(t *testing.T) (*realtime.Hub, *RealtimeHandler, *httptest.Server) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Role{}, &models.Permission{}, &models.RolePermission{},
+		&models.UserPermissionOverride{}, &models.AuditTrail{}))
+
+	permissionService := services.NewPermissionService(db)
+	require.NoError(t, permissionService.SeedDefaults())
+
+	hub := realtime.NewHub()
+	handler := NewRealtimeHandler(hub, permissionService, []string{"http://localhost:5173"})
+
+	router := gin.New()
+	group := router.Group("/api/v1/realtime", func(c *gin.Context) {
+		var userID uint
+		switch c.GetHeader("X-Test-User") {
+		case "7":
+			userID = 7
+		case "8":
+			userID = 8
+		}
+		c.Set("user_id", userID)
+		c.Set("user_role", c.GetHeader("X-Test-Role"))
+		c.Next()
+	})
+	group.GET("/sse", handler.StreamEvents)
+	group.GET("/ws", handler.ServeWebSocket)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return hub, handler, server
+}
+
+func TestRealtimeHandler_CanSubscribe(t *testing.T) {
+	_, handler, _ := setupRealtimeTestServer(t)
+
+	assert.True(t, handler.CanSubscribe(1, "chef", "kds/cooking/2026-03-02"))
+	assert.False(t, handler.CanSubscribe(1, "driver", "kds/cooking/2026-03-02"))
+	assert.True(t, handler.CanSubscribe(1, "driver", "monitoring/deliveries/2026-03-02"))
+	assert.False(t, handler.CanSubscribe(1, "kebersihan", "monitoring/deliveries/2026-03-02"))
+	assert.True(t, handler.CanSubscribe(1, "kebersihan", "cleaning/pending"))
+
+	// Personal notifications are only open to their owner
+	assert.True(t, handler.CanSubscribe(7, "chef", "notifications/7"))
+	assert.False(t, handler.CanSubscribe(8, "kepala_sppg", "notifications/7"))
+	assert.False(t, handler.CanSubscribe(7, "chef", "notifications"))
+	assert.True(t, handler.CanSubscribe(7, "driver", "notifications/logistics"))
+
+	assert.False(t, handler.CanSubscribe(1, "kepala_sppg", ""))
+	assert.False(t, handler.CanSubscribe(1, "kepala_sppg", "unknown/topic"))
+}
+
+func TestRealtimeHandler_StreamEventsRejectsForbiddenTopic(t *testing.T) {
+	_, _, server := setupRealtimeTestServer(t)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/realtime/sse?topic=notifications/7", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Test-User", "8")
+	req.Header.Set("X-Test-Role", "chef")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	req, err = http.NewRequest(http.MethodGet, server.URL+"/api/v1/realtime/sse", nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRealtimeHandler_StreamEvents(t *testing.T) {
+	hub, _, server := setupRealtimeTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/realtime/sse?topic=notifications/7", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Test-User", "7")
+	req.Header.Set("X-Test-Role", "chef")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() realtime.Event {
+		var event realtime.Event
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if strings.HasPrefix(line, "data: ") {
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+				return event
+			}
+		}
+	}
+
+	assert.Equal(t, realtime.EventSnapshot, readEvent().Type)
+
+	_, err = hub.Push(ctx, "notifications/8", map[string]interface{}{"title": "bukan untuk user 7"})
+	require.NoError(t, err)
+	key, err := hub.Push(ctx, "notifications/7", map[string]interface{}{"title": "Stok menipis"})
+	require.NoError(t, err)
+
+	event := readEvent()
+	assert.Equal(t, realtime.EventSet, event.Type)
+	assert.Equal(t, "notifications/7/"+key, event.Path)
+	assert.JSONEq(t, `{"title":"Stok menipis"}`, string(event.Data))
+}
+
+func TestRealtimeHandler_WebSocketSubscriptions(t *testing.T) {
+	hub, _, server := setupRealtimeTestServer(t)
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/realtime/ws?topic=kds/cooking/2026-03-02"
+
+	header := http.Header{}
+	header.Set("X-Test-User", "7")
+	header.Set("X-Test-Role", "chef")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	var event realtime.Event
+	require.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, realtime.EventSnapshot, event.Type)
+	assert.Equal(t, "kds/cooking/2026-03-02", event.Topic)
+
+	require.NoError(t, hub.Set(context.Background(), "kds/cooking/2026-03-02/4", map[string]interface{}{"status": "cooking"}))
+	require.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, realtime.EventSet, event.Type)
+	assert.Equal(t, "kds/cooking/2026-03-02/4", event.Path)
+
+	// A chef may not follow someone else's notifications
+	require.NoError(t, conn.WriteJSON(realtimeClientMessage{Action: "subscribe", Topic: "notifications/8"}))
+	require.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, "error", event.Type)
+	assert.Equal(t, "notifications/8", event.Topic)
+
+	require.NoError(t, conn.WriteJSON(realtimeClientMessage{Action: "subscribe", Topic: "notifications/7"}))
+	require.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, realtime.EventSnapshot, event.Type)
+	assert.Equal(t, "notifications/7", event.Topic)
+
+	// Nothing more is delivered for an unsubscribed topic
+	require.NoError(t, conn.WriteJSON(realtimeClientMessage{Action: "unsubscribe", Topic: "kds/cooking/2026-03-02"}))
+	require.Eventually(t, func() bool { return hub.SubscriberCount() == 1 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, hub.Set(context.Background(), "kds/cooking/2026-03-02/4", map[string]interface{}{"status": "ready"}))
+	require.NoError(t, hub.Set(context.Background(), "notifications/7/a", map[string]interface{}{"title": "Baru"}))
+	require.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, "notifications/7/a", event.Path)
+}
+
+func TestRealtimeHandler_WebSocketRejectsUnknownOrigin(t *testing.T) {
+	_, _, server := setupRealtimeTestServer(t)
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/realtime/ws"
+
+	header := http.Header{}
+	header.Set("X-Test-User", "7")
+	header.Set("X-Test-Role", "chef")
+	header.Set("Origin", "http://evil.example")
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, header)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
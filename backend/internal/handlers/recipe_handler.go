@@ -1,18 +1,14 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/erp-sppg/backend/internal/models"
 	"github.com/erp-sppg/backend/internal/services"
+	"github.com/erp-sppg/backend/internal/storage"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -20,13 +16,15 @@ import (
 type RecipeHandler struct {
 	recipeService    *services.RecipeService
 	inventoryService *services.InventoryService
+	uploader         *storage.Uploader
 }
 
 // NewRecipeHandler creates a new recipe handler
-func NewRecipeHandler(db *gorm.DB) *RecipeHandler {
+func NewRecipeHandler(db *gorm.DB, uploader *storage.Uploader) *RecipeHandler {
 	return &RecipeHandler{
 		recipeService:    services.NewRecipeService(db),
 		inventoryService: services.NewInventoryService(db),
+		uploader:         uploader,
 	}
 }
 
@@ -497,63 +495,17 @@ func (h *RecipeHandler) UploadRecipePhoto(c *gin.Context) {
 		return
 	}
 
-	// Validate file size (max 2MB)
-	if file.Size > 2*1024*1024 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success":    false,
-			"error_code": "FILE_TOO_LARGE",
-			"message":    "Ukuran file maksimal 2MB",
-		})
-		return
-	}
-
-	// Validate file type
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	allowedExts := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-	}
-	if !allowedExts[ext] {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success":    false,
-			"error_code": "INVALID_FILE_TYPE",
-			"message":    "Format file harus JPG, JPEG, atau PNG",
-		})
-		return
-	}
-
-	// Create uploads directory if not exists
-	uploadDir := "./uploads/recipes"
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error_code": "INTERNAL_ERROR",
-			"message":    "Gagal membuat direktori upload",
-		})
-		return
-	}
-
-	// Generate unique filename
-	filename := fmt.Sprintf("%s_%d%s", uuid.New().String(), time.Now().Unix(), ext)
-	filepath := filepath.Join(uploadDir, filename)
-
-	// Save file
-	if err := c.SaveUploadedFile(file, filepath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error_code": "INTERNAL_ERROR",
-			"message":    "Gagal menyimpan file",
-		})
+	stored, err := h.uploader.SaveMultipart(c.Request.Context(), storage.CategoryRecipePhoto, "recipe", file)
+	if err != nil {
+		respondUploadError(c, storage.CategoryRecipePhoto, err)
 		return
 	}
 
-	// Return URL
-	photoURL := fmt.Sprintf("/uploads/recipes/%s", filename)
 	c.JSON(http.StatusOK, gin.H{
-		"success":   true,
-		"message":   "File berhasil diupload",
-		"photo_url": photoURL,
+		"success":       true,
+		"message":       "File berhasil diupload",
+		"photo_url":     stored.Ref,
+		"thumbnail_url": stored.ThumbnailRef,
 	})
 }
 
@@ -569,27 +521,16 @@ func (h *RecipeHandler) DeleteRecipePhoto(c *gin.Context) {
 		return
 	}
 
-	// Extract filename from URL
-	parts := strings.Split(photoURL, "/")
-	if len(parts) < 3 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success":    false,
-			"error_code": "INVALID_URL",
-			"message":    "URL foto tidak valid",
-		})
-		return
-	}
-
-	filename := parts[len(parts)-1]
-	filepath := filepath.Join("./uploads/recipes", filename)
-
-	// Delete file
-	if err := os.Remove(filepath); err != nil {
-		// File might not exist, but that's okay
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"message": "File berhasil dihapus atau tidak ditemukan",
-		})
+	if err := h.uploader.Delete(c.Request.Context(), storage.CategoryRecipePhoto, photoURL); err != nil {
+		if errors.Is(err, storage.ErrInvalidKey) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "INVALID_URL",
+				"message":    "URL foto tidak valid",
+			})
+			return
+		}
+		respondFileError(c, err)
 		return
 	}
 
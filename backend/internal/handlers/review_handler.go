@@ -4,14 +4,13 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/erp-sppg/backend/internal/models"
 	"github.com/erp-sppg/backend/internal/services"
+	"github.com/erp-sppg/backend/internal/storage"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -19,12 +18,14 @@ import (
 // ReviewHandler handles delivery review endpoints
 type ReviewHandler struct {
 	reviewService *services.ReviewService
+	uploader      *storage.Uploader
 }
 
 // NewReviewHandler creates a new review handler
-func NewReviewHandler(db *gorm.DB) *ReviewHandler {
+func NewReviewHandler(db *gorm.DB, uploader *storage.Uploader) *ReviewHandler {
 	return &ReviewHandler{
 		reviewService: services.NewReviewService(db),
+		uploader:      uploader,
 	}
 }
 
@@ -66,35 +67,30 @@ func (h *ReviewHandler) CreateReview(c *gin.Context) {
 		return
 	}
 
-	// Handle base64 photo - save to file
+	// Handle base64 photo - save to the blob store
 	photoURL := ""
 	if req.PhotoURL != "" && strings.HasPrefix(req.PhotoURL, "data:image") {
-		// Extract base64 data
-		parts := strings.Split(req.PhotoURL, ",")
+		parts := strings.SplitN(req.PhotoURL, ",", 2)
+		var imageData []byte
+		var err error
 		if len(parts) == 2 {
-			// Decode base64
-			imageData, err := base64.StdEncoding.DecodeString(parts[1])
-			if err == nil {
-				// Determine file extension
-				ext := ".png"
-				if strings.Contains(parts[0], "jpeg") || strings.Contains(parts[0], "jpg") {
-					ext = ".jpg"
-				}
-
-				// Create uploads directory
-				uploadDir := "uploads/reviews"
-				if err := os.MkdirAll(uploadDir, 0755); err == nil {
-					// Generate filename
-					filename := fmt.Sprintf("review_%d_%d%s", req.DeliveryRecordID, time.Now().Unix(), ext)
-					filePath := filepath.Join(uploadDir, filename)
-
-					// Save file
-					if err := os.WriteFile(filePath, imageData, 0644); err == nil {
-						photoURL = "/" + filePath
-					}
-				}
-			}
+			imageData, err = base64.StdEncoding.DecodeString(parts[1])
 		}
+		if len(parts) != 2 || err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "VALIDATION_ERROR",
+				"message":    "Foto ulasan tidak valid",
+			})
+			return
+		}
+
+		stored, err := h.uploader.Save(c.Request.Context(), storage.CategoryReviewPhoto, fmt.Sprintf("review-%d", req.DeliveryRecordID), "", imageData)
+		if err != nil {
+			respondUploadError(c, storage.CategoryReviewPhoto, err)
+			return
+		}
+		photoURL = stored.Ref
 	}
 
 	review := &models.DeliveryReview{
@@ -117,6 +113,7 @@ func (h *ReviewHandler) CreateReview(c *gin.Context) {
 	}
 
 	if err := h.reviewService.CreateReview(review); err != nil {
+		h.uploader.Delete(c.Request.Context(), storage.CategoryReviewPhoto, photoURL)
 		if err == services.ErrReviewAlreadyExists {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"success":    false,
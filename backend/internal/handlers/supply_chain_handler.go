@@ -3,20 +3,16 @@ package handlers
 import (
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"time"
 
 	"github.com/erp-sppg/backend/internal/models"
 	"github.com/erp-sppg/backend/internal/services"
+	"github.com/erp-sppg/backend/internal/storage"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-// uploadBaseDir is the base directory for file uploads
-var uploadBaseDir = filepath.Join("uploads")
-
 // SupplyChainHandler handles supply chain endpoints
 type SupplyChainHandler struct {
 	db                    *gorm.DB
@@ -24,12 +20,14 @@ type SupplyChainHandler struct {
 	purchaseOrderService  *services.PurchaseOrderService
 	goodsReceiptService   *services.GoodsReceiptService
 	inventoryService      *services.InventoryService
+	uploader              *storage.Uploader
 }
 
 // NewSupplyChainHandler creates a new supply chain handler
-func NewSupplyChainHandler(db *gorm.DB) *SupplyChainHandler {
+func NewSupplyChainHandler(db *gorm.DB, budgetService *services.BudgetService, uploader *storage.Uploader) *SupplyChainHandler {
 	inventoryService := services.NewInventoryService(db)
 	cashFlowService := services.NewCashFlowService(db)
+	cashFlowService.SetBudgetService(budgetService)
 	
 	return &SupplyChainHandler{
 		db:                   db,
@@ -37,6 +35,7 @@ func NewSupplyChainHandler(db *gorm.DB) *SupplyChainHandler {
 		purchaseOrderService: services.NewPurchaseOrderService(db),
 		goodsReceiptService:  services.NewGoodsReceiptService(db, inventoryService, cashFlowService),
 		inventoryService:     inventoryService,
+		uploader:             uploader,
 	}
 }
 
@@ -50,6 +49,7 @@ type CreateSupplierRequest struct {
 	Email           string  `json:"email" binding:"omitempty,email"`
 	Address         string  `json:"address"`
 	ProductCategory string  `json:"product_category"`
+	PaymentTermDays *int    `json:"payment_term_days" binding:"omitempty,gte=0,lte=365"`
 }
 
 // CreateSupplier creates a new supplier
@@ -73,6 +73,9 @@ func (h *SupplyChainHandler) CreateSupplier(c *gin.Context) {
 		Address:         req.Address,
 		ProductCategory: req.ProductCategory,
 	}
+	if req.PaymentTermDays != nil {
+		supplier.PaymentTermDays = *req.PaymentTermDays
+	}
 
 	if err := h.supplierService.CreateSupplier(supplier); err != nil {
 		if err == services.ErrDuplicateSupplier {
@@ -197,6 +200,9 @@ func (h *SupplyChainHandler) UpdateSupplier(c *gin.Context) {
 		Address:         req.Address,
 		ProductCategory: req.ProductCategory,
 	}
+	if req.PaymentTermDays != nil {
+		supplier.PaymentTermDays = *req.PaymentTermDays
+	}
 
 	if err := h.supplierService.UpdateSupplier(uint(id), supplier); err != nil {
 		if err == services.ErrSupplierNotFound {
@@ -303,9 +309,9 @@ type CreatePurchaseOrderRequest struct {
 
 // PurchaseOrderItemRequest represents PO item request
 type PurchaseOrderItemRequest struct {
-	IngredientID uint    `json:"ingredient_id" binding:"required"`
-	Quantity     float64 `json:"quantity" binding:"required,gt=0"`
-	UnitPrice    float64 `json:"unit_price" binding:"required,gte=0"`
+	IngredientID uint         `json:"ingredient_id" binding:"required"`
+	Quantity     float64      `json:"quantity" binding:"required,gt=0"`
+	UnitPrice    models.Money `json:"unit_price" binding:"required,gte=0"`
 }
 
 // CreatePurchaseOrder creates a new purchase order
@@ -748,34 +754,15 @@ func (h *SupplyChainHandler) UploadInvoicePhoto(c *gin.Context) {
 		return
 	}
 
-	// Save file
-	filename := fmt.Sprintf("invoice_%d_%d%s", id, time.Now().Unix(), filepath.Ext(file.Filename))
-	invoiceDir := filepath.Join(uploadBaseDir, "invoices")
-	savePath := filepath.Join(invoiceDir, filename)
-	
-	// Create directory if not exists
-	if err := os.MkdirAll(invoiceDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error_code": "UPLOAD_ERROR",
-			"message":    "Gagal membuat direktori upload",
-		})
-		return
-	}
-
-	if err := c.SaveUploadedFile(file, savePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":    false,
-			"error_code": "UPLOAD_ERROR",
-			"message":    "Gagal menyimpan file",
-		})
+	stored, err := h.uploader.SaveMultipart(c.Request.Context(), storage.CategoryInvoice, fmt.Sprintf("invoice-%d", id), file)
+	if err != nil {
+		respondUploadError(c, storage.CategoryInvoice, err)
 		return
 	}
-
-	// Generate URL
-	photoURL := fmt.Sprintf("/uploads/invoices/%s", filename)
+	photoURL := stored.Ref
 
 	if err := h.goodsReceiptService.UpdateInvoicePhoto(uint(id), photoURL); err != nil {
+		h.uploader.Delete(c.Request.Context(), storage.CategoryInvoice, photoURL)
 		if err == services.ErrGRNNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"success":    false,
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// SyncHandler receives the operations the PWA queues while offline
+type SyncHandler struct {
+	syncService *services.OfflineSyncService
+}
+
+// NewSyncHandler creates a new sync handler
+func NewSyncHandler(syncService *services.OfflineSyncService) *SyncHandler {
+	return &SyncHandler{syncService: syncService}
+}
+
+// SyncOperationRequest is one queued operation. The idempotency key is
+// generated by the client when the action is queued and the client timestamp
+// is the time the driver performed it.
+type SyncOperationRequest struct {
+	IdempotencyKey  string          `json:"idempotency_key" binding:"required,max=100"`
+	Type            string          `json:"type" binding:"required,oneof=delivery_status pickup_stage epod"`
+	ClientTimestamp time.Time       `json:"client_timestamp" binding:"required"`
+	Payload         json.RawMessage `json:"payload" binding:"required"`
+}
+
+// SyncBatchRequest represents the offline queue sent by a client, oldest first
+type SyncBatchRequest struct {
+	Operations []SyncOperationRequest `json:"operations" binding:"required,min=1,max=100,dive"`
+}
+
+// SyncBatch applies the queued operations in order and reports the result of
+// each one. Applied, conflict and rejected operations can be removed from the
+// queue; failed and skipped operations should be sent again later.
+// POST /api/v1/sync/batch
+func (h *SyncHandler) SyncBatch(c *gin.Context) {
+	var req SyncBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	ops := make([]services.SyncOperationInput, len(req.Operations))
+	for i, op := range req.Operations {
+		ops[i] = services.SyncOperationInput{
+			IdempotencyKey:  op.IdempotencyKey,
+			Type:            op.Type,
+			ClientTimestamp: op.ClientTimestamp,
+			Payload:         op.Payload,
+		}
+	}
+
+	userID, _ := c.Get("user_id")
+	userRole, _ := c.Get("user_role")
+	results := h.syncService.ApplyBatch(userID.(uint), userRole.(string), ops)
+
+	summary := map[string]int{}
+	for _, result := range results {
+		summary[result.Status]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Sinkronisasi selesai",
+		"data": gin.H{
+			"results": results,
+			"summary": summary,
+		},
+	})
+}
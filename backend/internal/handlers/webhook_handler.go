@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/erp-sppg/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WebhookHandler lets administrators manage the webhooks of partner systems
+// and inspect and redeliver their deliveries
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	auditService   *services.AuditTrailService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(db *gorm.DB) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: services.NewWebhookService(db),
+		auditService:   services.NewAuditTrailService(db),
+	}
+}
+
+// GetWebhookDeliveriesRequest represents the query parameters for the delivery log
+type GetWebhookDeliveriesRequest struct {
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	PageSize int    `form:"page_size,default=20" binding:"min=1,max=100"`
+	Status   string `form:"status" binding:"omitempty,oneof=pending retrying delivered failed"`
+}
+
+// GetWebhooks lists the webhook subscriptions and the event types they can receive
+// GET /api/v1/system/webhooks
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	subscriptions, err := h.webhookService.ListSubscriptions()
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"data":        subscriptions,
+		"event_types": services.WebhookEventTypes,
+	})
+}
+
+// CreateWebhook registers a webhook. The signing secret is only returned here.
+// POST /api/v1/system/webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var input services.WebhookSubscriptionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data webhook tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	subscription, secret, err := h.webhookService.CreateSubscription(input, userID.(uint))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	h.auditService.RecordAction(userID.(uint), "create", "webhook_subscription", fmt.Sprint(subscription.ID), nil, subscription, c.ClientIP())
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Webhook berhasil dibuat. Simpan secret ini, secret tidak ditampilkan lagi",
+		"data":    subscription,
+		"secret":  secret,
+	})
+}
+
+// UpdateWebhook changes a webhook; a non-empty secret replaces the current one
+// PUT /api/v1/system/webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var input services.WebhookSubscriptionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Data webhook tidak valid",
+			"details":    err.Error(),
+		})
+		return
+	}
+
+	subscription, err := h.webhookService.UpdateSubscription(id, input)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	h.auditService.RecordAction(userID.(uint), "update", "webhook_subscription", fmt.Sprint(id), nil, subscription, c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook berhasil diperbarui",
+		"data":    subscription,
+	})
+}
+
+// DeleteWebhook removes a webhook with its delivery log
+// DELETE /api/v1/system/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(id); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	h.auditService.RecordAction(userID.(uint), "delete", "webhook_subscription", fmt.Sprint(id), nil, nil, c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook berhasil dihapus",
+	})
+}
+
+// GetDeliveries returns the delivery log of a webhook, newest first
+// GET /api/v1/system/webhooks/:id/deliveries?status=failed
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	var req GetWebhookDeliveriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    "Parameter tidak valid",
+		})
+		return
+	}
+
+	deliveries, total, err := h.webhookService.ListDeliveries(id, req.Status, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"data":        deliveries,
+		"total":       total,
+		"page":        req.Page,
+		"page_size":   req.PageSize,
+		"total_pages": (total + int64(req.PageSize) - 1) / int64(req.PageSize),
+	})
+}
+
+// Redeliver sends a delivered or failed delivery again
+// POST /api/v1/system/webhooks/deliveries/:id/redeliver
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, ok := parseLedgerID(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(id)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	h.auditService.RecordAction(userID.(uint), "redeliver", "webhook_delivery", fmt.Sprint(id), nil, nil, c.ClientIP())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook dijadwalkan untuk dikirim ulang",
+		"data":    delivery,
+	})
+}
+
+func respondWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound), errors.Is(err, services.ErrWebhookDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success":    false,
+			"error_code": "NOT_FOUND",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrWebhookInvalidURL), errors.Is(err, services.ErrWebhookInvalidEventType):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":    false,
+			"error_code": "VALIDATION_ERROR",
+			"message":    err.Error(),
+		})
+	case errors.Is(err, services.ErrWebhookDeliveryInProgress):
+		c.JSON(http.StatusConflict, gin.H{
+			"success":    false,
+			"error_code": "INVALID_STATE",
+			"message":    err.Error(),
+		})
+	default:
+		log.Printf("[WEBHOOK] error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":    false,
+			"error_code": "INTERNAL_ERROR",
+			"message":    "Terjadi kesalahan pada server",
+		})
+	}
+}
@@ -53,39 +53,52 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 		// Set user context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_role", claims.Role)
+		c.Set("session_id", claims.SessionID)
+		c.Set("must_change_password", claims.MustChangePassword)
+		c.Set("two_factor_setup", claims.TwoFactorSetup)
 
 		c.Next()
 	}
 }
 
-// RequireRole middleware checks if user has required role
-func RequireRole(allowedRoles ...string) gin.HandlerFunc {
+// TokenFromQuery lets clients that cannot set headers, such as browser
+// WebSocket and EventSource connections, pass the access token as the
+// access_token query parameter. It must run before JWTAuth.
+func TokenFromQuery() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userRole, exists := c.Get("user_role")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success":    false,
-				"error_code": "UNAUTHORIZED",
-				"message":    "Autentikasi diperlukan",
-			})
-			c.Abort()
-			return
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
 		}
+		c.Next()
+	}
+}
 
-		role := userRole.(string)
-		allowed := false
-		for _, allowedRole := range allowedRoles {
-			if role == allowedRole {
-				allowed = true
-				break
+// passwordChangeAllowedPaths lists the routes still reachable while a password change is pending
+var passwordChangeAllowedPaths = []string{
+	"/api/v1/auth/change-password",
+	"/api/v1/auth/me",
+	"/api/v1/auth/logout",
+}
+
+// PasswordChangeGuard blocks every route except the password change endpoints
+// for users whose token says the password must be changed first
+func PasswordChangeGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("must_change_password") {
+			path := c.FullPath()
+			for _, allowed := range passwordChangeAllowedPaths {
+				if path == allowed {
+					c.Next()
+					return
+				}
 			}
-		}
 
-		if !allowed {
 			c.JSON(http.StatusForbidden, gin.H{
 				"success":    false,
-				"error_code": "FORBIDDEN",
-				"message":    "Anda tidak memiliki izin untuk mengakses resource ini",
+				"error_code": "PASSWORD_CHANGE_REQUIRED",
+				"message":    "Anda harus mengganti password sebelum melanjutkan",
 			})
 			c.Abort()
 			return
@@ -95,58 +108,43 @@ func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	}
 }
 
-// PermissionChecker defines permission checking logic
-type PermissionChecker struct {
-	permissions map[string][]string // feature -> allowed roles
+// twoFactorSetupAllowedPaths lists the routes still reachable while 2FA enrollment is pending
+var twoFactorSetupAllowedPaths = []string{
+	"/api/v1/auth/2fa",
+	"/api/v1/auth/2fa/setup",
+	"/api/v1/auth/2fa/confirm",
+	"/api/v1/auth/me",
+	"/api/v1/auth/logout",
 }
 
-// NewPermissionChecker creates a new permission checker with default permissions
-func NewPermissionChecker() *PermissionChecker {
-	pc := &PermissionChecker{
-		permissions: make(map[string][]string),
-	}
-
-	// Define permissions based on requirements
-	pc.permissions["dashboard_executive"] = []string{"kepala_sppg", "kepala_yayasan"}
-	pc.permissions["financial_reports"] = []string{"kepala_sppg", "kepala_yayasan", "akuntan"}
-	pc.permissions["menu_planning"] = []string{"kepala_sppg", "ahli_gizi"}
-	pc.permissions["recipe_management"] = []string{"kepala_sppg", "ahli_gizi"}
-	pc.permissions["kitchen_display"] = []string{"kepala_sppg", "ahli_gizi", "chef", "packing"}
-	pc.permissions["procurement"] = []string{"kepala_sppg", "pengadaan"}
-	pc.permissions["inventory"] = []string{"kepala_sppg", "akuntan", "pengadaan"}
-	pc.permissions["delivery_tasks"] = []string{"kepala_sppg", "driver", "asisten_lapangan"}
-	pc.permissions["attendance"] = []string{"kepala_sppg", "akuntan", "ahli_gizi", "pengadaan", "chef", "packing", "driver", "asisten_lapangan"}
-	pc.permissions["hrm_management"] = []string{"kepala_sppg", "akuntan"}
-	
-	// Logistics monitoring permissions - all roles except kebersihan
-	pc.permissions["monitoring"] = []string{"kepala_sppg", "kepala_yayasan", "akuntan", "ahli_gizi", "pengadaan", "chef", "packing", "driver", "asisten_lapangan"}
-	
-	// Cleaning module permissions - kebersihan role only (plus admin override)
-	pc.permissions["cleaning"] = []string{"kebersihan", "kepala_sppg", "kepala_yayasan"}
-
-	return pc
-}
-
-// CheckPermission checks if a role has permission for a feature
-func (pc *PermissionChecker) CheckPermission(role, feature string) bool {
-	allowedRoles, exists := pc.permissions[feature]
-	if !exists {
-		return false
-	}
+// TwoFactorSetupGuard blocks every route except the 2FA enrollment endpoints
+// for users whose role requires 2FA but who have not enrolled yet
+func TwoFactorSetupGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("two_factor_setup") {
+			path := c.FullPath()
+			for _, allowed := range twoFactorSetupAllowedPaths {
+				if path == allowed {
+					c.Next()
+					return
+				}
+			}
 
-	for _, allowedRole := range allowedRoles {
-		if role == allowedRole {
-			return true
+			c.JSON(http.StatusForbidden, gin.H{
+				"success":    false,
+				"error_code": "TWO_FACTOR_SETUP_REQUIRED",
+				"message":    "Anda harus mengaktifkan verifikasi dua langkah sebelum melanjutkan",
+			})
+			c.Abort()
+			return
 		}
-	}
 
-	return false
+		c.Next()
+	}
 }
 
-// RequirePermission middleware checks if user has permission for a feature
-func RequirePermission(feature string) gin.HandlerFunc {
-	pc := NewPermissionChecker()
-
+// RequireRole middleware checks if user has required role
+func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
 		if !exists {
@@ -160,11 +158,19 @@ func RequirePermission(feature string) gin.HandlerFunc {
 		}
 
 		role := userRole.(string)
-		if !pc.CheckPermission(role, feature) {
+		allowed := false
+		for _, allowedRole := range allowedRoles {
+			if role == allowedRole {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
 			c.JSON(http.StatusForbidden, gin.H{
 				"success":    false,
 				"error_code": "FORBIDDEN",
-				"message":    "Anda tidak memiliki izin untuk mengakses fitur ini",
+				"message":    "Anda tidak memiliki izin untuk mengakses resource ini",
 			})
 			c.Abort()
 			return
@@ -174,66 +180,93 @@ func RequirePermission(feature string) gin.HandlerFunc {
 	}
 }
 
-// StatusCategoryAuthorizer defines authorization logic for status updates
-type StatusCategoryAuthorizer struct {
-	statusRoleMap map[string][]string // status -> allowed roles
+// PermissionChecker enforces the database-backed role permissions and user overrides
+type PermissionChecker struct {
+	service *services.PermissionService
 }
 
-// NewStatusCategoryAuthorizer creates a new status category authorizer
-func NewStatusCategoryAuthorizer() *StatusCategoryAuthorizer {
-	sca := &StatusCategoryAuthorizer{
-		statusRoleMap: make(map[string][]string),
-	}
-
-	// Cooking statuses - chef role
-	sca.statusRoleMap["sedang_dimasak"] = []string{"chef", "kepala_sppg", "kepala_yayasan"}
-	sca.statusRoleMap["selesai_dimasak"] = []string{"chef", "kepala_sppg", "kepala_yayasan"}
-
-	// Packing statuses - packing role
-	sca.statusRoleMap["siap_dipacking"] = []string{"packing", "kepala_sppg", "kepala_yayasan"}
-	sca.statusRoleMap["selesai_dipacking"] = []string{"packing", "kepala_sppg", "kepala_yayasan"}
+// NewPermissionChecker creates a permission checker backed by the permission service
+func NewPermissionChecker(service *services.PermissionService) *PermissionChecker {
+	return &PermissionChecker{service: service}
+}
 
-	// Delivery statuses - driver role
-	sca.statusRoleMap["siap_dikirim"] = []string{"driver", "kepala_sppg", "kepala_yayasan"}
-	sca.statusRoleMap["diperjalanan"] = []string{"driver", "kepala_sppg", "kepala_yayasan"}
-	sca.statusRoleMap["sudah_sampai_sekolah"] = []string{"driver", "kepala_sppg", "kepala_yayasan"}
-	sca.statusRoleMap["sudah_diterima_pihak_sekolah"] = []string{"driver", "kepala_sppg", "kepala_yayasan"}
+// CheckPermission checks if a user has permission for a feature
+func (pc *PermissionChecker) CheckPermission(userID uint, role, feature string) bool {
+	return pc.service.HasPermission(userID, role, feature)
+}
 
-	// Collection statuses - driver role
-	sca.statusRoleMap["driver_ditugaskan_mengambil_ompreng"] = []string{"driver", "kepala_sppg", "kepala_yayasan"}
-	sca.statusRoleMap["driver_menuju_sekolah"] = []string{"driver", "kepala_sppg", "kepala_yayasan"}
-	sca.statusRoleMap["driver_sampai_di_sekolah"] = []string{"driver", "kepala_sppg", "kepala_yayasan"}
-	sca.statusRoleMap["ompreng_telah_diambil"] = []string{"driver", "kepala_sppg", "kepala_yayasan"}
-	sca.statusRoleMap["ompreng_sampai_di_sppg"] = []string{"driver", "kepala_sppg", "kepala_yayasan"}
+// RequirePermission middleware checks if user has permission for a feature
+func (pc *PermissionChecker) RequirePermission(feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pc.enforce(c, feature)
+	}
+}
 
-	// Cleaning statuses - kebersihan role
-	sca.statusRoleMap["ompreng_proses_pencucian"] = []string{"kebersihan", "kepala_sppg", "kepala_yayasan"}
-	sca.statusRoleMap["ompreng_selesai_dicuci"] = []string{"kebersihan", "kepala_sppg", "kepala_yayasan"}
+// RequireReadWrite middleware checks the read permission for GET and HEAD
+// requests and the write permission for every other method
+func (pc *PermissionChecker) RequireReadWrite(readFeature, writeFeature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		feature := writeFeature
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			feature = readFeature
+		}
+		pc.enforce(c, feature)
+	}
+}
 
-	return sca
+// RequirePermissionForQuery middleware checks the feature only when the request
+// carries the given query parameter, e.g. when it asks for another user's data
+func (pc *PermissionChecker) RequirePermissionForQuery(param, feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Query(param) == "" {
+			c.Next()
+			return
+		}
+		pc.enforce(c, feature)
+	}
 }
 
-// CheckStatusUpdatePermission checks if a role has permission to update to a specific status
-func (sca *StatusCategoryAuthorizer) CheckStatusUpdatePermission(role, status string) bool {
-	allowedRoles, exists := sca.statusRoleMap[status]
+// enforce aborts the request unless the current user has the feature
+func (pc *PermissionChecker) enforce(c *gin.Context, feature string) {
+	userRole, exists := c.Get("user_role")
 	if !exists {
-		// If status not in map, deny by default
-		return false
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"error_code": "UNAUTHORIZED",
+			"message":    "Autentikasi diperlukan",
+		})
+		c.Abort()
+		return
 	}
 
-	for _, allowedRole := range allowedRoles {
-		if role == allowedRole {
-			return true
-		}
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(uint)
+	if !pc.CheckPermission(id, userRole.(string), feature) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":    false,
+			"error_code": "FORBIDDEN",
+			"message":    "Anda tidak memiliki izin untuk mengakses fitur ini",
+		})
+		c.Abort()
+		return
 	}
 
-	return false
+	c.Next()
+}
+
+// StatusCategoryAuthorizer decides which users may set a delivery status.
+// Each status maps to a "status.<status>" permission.
+type StatusCategoryAuthorizer struct {
+	service *services.PermissionService
+}
+
+// NewStatusCategoryAuthorizer creates a status authorizer backed by the permission service
+func NewStatusCategoryAuthorizer(service *services.PermissionService) *StatusCategoryAuthorizer {
+	return &StatusCategoryAuthorizer{service: service}
 }
 
-// ValidateStatusUpdatePermission checks if a user role has permission to update to a specific status
-// This is a helper function to be called from handlers
-// Returns true if the user has permission, false otherwise
-func ValidateStatusUpdatePermission(role, status string) bool {
-	sca := NewStatusCategoryAuthorizer()
-	return sca.CheckStatusUpdatePermission(role, status)
+// CheckStatusUpdatePermission checks if a user has permission to update to a specific status.
+// Unknown statuses have no permission and are denied.
+func (sca *StatusCategoryAuthorizer) CheckStatusUpdatePermission(userID uint, role, status string) bool {
+	return sca.service.CanUpdateStatus(userID, role, status)
 }
@@ -4,15 +4,16 @@ import (
 	"crypto/md5"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/erp-sppg/backend/internal/cache"
 	"github.com/gin-gonic/gin"
 )
 
-// CacheMiddleware provides HTTP response caching
-func CacheMiddleware(cacheService *cache.CacheService, duration time.Duration) gin.HandlerFunc {
+// CacheMiddleware provides HTTP response caching. Cached responses are
+// invalidated together with the cache tags of the data they show, e.g.
+// cache.InventoryTag when stock changes.
+func CacheMiddleware(cacheService *cache.CacheService, duration time.Duration, tags ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip caching for non-GET requests
 		if c.Request.Method != "GET" {
@@ -73,88 +74,11 @@ func CacheMiddleware(cacheService *cache.CacheService, duration time.Duration) g
 			}
 
 			// Cache the response
-			setCachedResponse(cacheService, cacheKey, cachedResponse, duration)
+			setCachedResponse(cacheService, cacheKey, cachedResponse, tags, duration)
 		}
 	}
 }
 
-// DashboardCacheMiddleware provides specialized caching for dashboard endpoints
-func DashboardCacheMiddleware(cacheService *cache.CacheService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Skip caching for non-GET requests
-		if c.Request.Method != "GET" {
-			c.Next()
-			return
-		}
-
-		// Get user role from context
-		userRole, exists := c.Get("user_role")
-		if !exists {
-			c.Next()
-			return
-		}
-
-		// Generate cache key based on role and date
-		today := time.Now().Format("2006-01-02")
-
-		// Try to get cached dashboard data
-		data, err := cacheService.GetDashboardData(userRole.(string), today)
-		if err == nil {
-			// Cache hit
-			c.Header("X-Cache", "HIT")
-			c.JSON(http.StatusOK, gin.H{
-				"success": true,
-				"data":    data,
-			})
-			c.Abort()
-			return
-		}
-
-		// Cache miss - continue with request processing
-		c.Header("X-Cache", "MISS")
-		c.Next()
-	}
-}
-
-// InventoryCacheMiddleware provides specialized caching for inventory endpoints
-func InventoryCacheMiddleware(cacheService *cache.CacheService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if c.Request.Method != "GET" {
-			c.Next()
-			return
-		}
-
-		// Check for low stock alerts endpoint
-		if strings.Contains(c.Request.URL.Path, "alerts") {
-			items, err := cacheService.GetLowStockItems()
-			if err == nil {
-				c.Header("X-Cache", "HIT")
-				c.JSON(http.StatusOK, gin.H{
-					"success": true,
-					"data":    items,
-				})
-				c.Abort()
-				return
-			}
-		} else {
-			// Regular inventory items
-			items, err := cacheService.GetInventoryItems()
-			if err == nil {
-				c.Header("X-Cache", "HIT")
-				c.JSON(http.StatusOK, gin.H{
-					"success": true,
-					"data":    items,
-				})
-				c.Abort()
-				return
-			}
-		}
-
-		c.Header("X-Cache", "MISS")
-		c.Next()
-	}
-}
-
 // CachedResponse represents a cached HTTP response
 type CachedResponse struct {
 	StatusCode  int               `json:"status_code"`
@@ -200,56 +124,15 @@ func generateCacheKey(c *gin.Context) string {
 	return fmt.Sprintf("http:%x", hash)
 }
 
-// CacheInvalidationMiddleware invalidates cache on data modifications
-func CacheInvalidationMiddleware(cacheService *cache.CacheService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Store original path for later use
-		path := c.Request.URL.Path
-		method := c.Request.Method
-
-		c.Next()
-
-		// Only invalidate cache for successful modifications
-		if c.Writer.Status() >= 200 && c.Writer.Status() < 300 {
-			// Determine what cache to invalidate based on the endpoint
-			switch {
-			case strings.Contains(path, "/inventory"):
-				if method != "GET" {
-					cacheService.InvalidateOnInventoryChange()
-				}
-			case strings.Contains(path, "/menu") || strings.Contains(path, "/recipe"):
-				if method != "GET" {
-					cacheService.InvalidateOnMenuChange()
-				}
-			case strings.Contains(path, "/cash-flow") || strings.Contains(path, "/financial"):
-				if method != "GET" {
-					cacheService.InvalidateOnFinancialChange()
-				}
-			case strings.Contains(path, "/supplier"):
-				if method != "GET" {
-					cacheService.InvalidateOnSupplierChange()
-				}
-			case strings.Contains(path, "/notification"):
-				if method != "GET" {
-					// Invalidate notifications for the specific user
-					if userID, exists := c.Get("user_id"); exists {
-						cacheService.InvalidateUserNotifications(userID.(uint))
-					}
-				}
-			}
-		}
-	}
-}
-
 // ConditionalCacheMiddleware applies caching based on conditions
-func ConditionalCacheMiddleware(cacheService *cache.CacheService, condition func(*gin.Context) bool, duration time.Duration) gin.HandlerFunc {
+func ConditionalCacheMiddleware(cacheService *cache.CacheService, condition func(*gin.Context) bool, duration time.Duration, tags ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if !condition(c) {
 			c.Next()
 			return
 		}
 
-		CacheMiddleware(cacheService, duration)(c)
+		CacheMiddleware(cacheService, duration, tags...)(c)
 	}
 }
 
@@ -283,8 +166,8 @@ func CacheForReadOnlyOperations(c *gin.Context) bool {
 }
 
 // Helper function to set cached response
-func setCachedResponse(cacheService *cache.CacheService, key string, response CachedResponse, duration time.Duration) error {
-	return cacheService.SetCachedResponse(key, response, duration)
+func setCachedResponse(cacheService *cache.CacheService, key string, response CachedResponse, tags []string, duration time.Duration) error {
+	return cacheService.SetCachedResponseWithTags(key, response, tags, duration)
 }
 
 // Helper function to get cached response
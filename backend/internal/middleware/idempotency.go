@@ -0,0 +1,308 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/cache"
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyKeyHeader is the request header clients set on retried requests
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const (
+	// DefaultIdempotencyTTL is how long the first response to a key is kept
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// idempotencyLockTimeout is how long an unfinished request blocks a
+	// repeat; older claims were left behind by a crashed request
+	idempotencyLockTimeout = 5 * time.Minute
+
+	// maxIdempotencyKeyLength is the longest key a client may send
+	maxIdempotencyKeyLength = 255
+
+	// maxStoredResponseSize is the largest response body that is stored;
+	// larger responses are not replayed and a repeat runs the handler again
+	maxStoredResponseSize = 1 << 20
+)
+
+// IdempotentResponse is the state of an idempotency key: claimed by a request
+// that is still running, or completed with the response to replay
+type IdempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// IdempotencyStore keeps the responses of requests sent with an idempotency key
+type IdempotencyStore interface {
+	// Claim reserves the key for a new request. It returns true when the key
+	// was free, or false with the state stored by an earlier request.
+	Claim(userID uint, key, fingerprint string) (bool, *IdempotentResponse, error)
+	// Complete stores the response of the request that claimed the key
+	Complete(userID uint, key string, response *IdempotentResponse, ttl time.Duration) error
+	// Release frees the key so the request can be tried again
+	Release(userID uint, key string) error
+}
+
+// RedisIdempotencyStore keeps idempotency keys in Redis
+type RedisIdempotencyStore struct {
+	redis *cache.RedisCache
+}
+
+// NewRedisIdempotencyStore creates an idempotency store backed by Redis
+func NewRedisIdempotencyStore(redis *cache.RedisCache) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{redis: redis}
+}
+
+func idempotencyRedisKey(userID uint, key string) string {
+	return fmt.Sprintf("idempotency:%d:%s", userID, key)
+}
+
+// Claim implements IdempotencyStore
+func (s *RedisIdempotencyStore) Claim(userID uint, key, fingerprint string) (bool, *IdempotentResponse, error) {
+	redisKey := idempotencyRedisKey(userID, key)
+	claimed, err := s.redis.SetNX(redisKey, IdempotentResponse{Fingerprint: fingerprint}, idempotencyLockTimeout)
+	if err != nil || claimed {
+		return claimed, nil, err
+	}
+
+	var existing IdempotentResponse
+	if err := s.redis.Get(redisKey, &existing); err != nil {
+		if errors.Is(err, cache.ErrCacheMiss) {
+			// Expired between the two calls; try once more
+			claimed, err := s.redis.SetNX(redisKey, IdempotentResponse{Fingerprint: fingerprint}, idempotencyLockTimeout)
+			if err != nil || claimed {
+				return claimed, nil, err
+			}
+		}
+		return false, nil, err
+	}
+	return false, &existing, nil
+}
+
+// Complete implements IdempotencyStore
+func (s *RedisIdempotencyStore) Complete(userID uint, key string, response *IdempotentResponse, ttl time.Duration) error {
+	return s.redis.Set(idempotencyRedisKey(userID, key), response, ttl)
+}
+
+// Release implements IdempotencyStore
+func (s *RedisIdempotencyStore) Release(userID uint, key string) error {
+	return s.redis.Delete(idempotencyRedisKey(userID, key))
+}
+
+// DBIdempotencyStore keeps idempotency keys in the idempotency_keys table.
+// Expired keys are purged periodically.
+type DBIdempotencyStore struct {
+	db *gorm.DB
+}
+
+// NewDBIdempotencyStore creates an idempotency store backed by the database
+func NewDBIdempotencyStore(db *gorm.DB) *DBIdempotencyStore {
+	s := &DBIdempotencyStore{db: db}
+
+	// Start cleanup goroutine to remove expired keys
+	go s.cleanup()
+
+	return s
+}
+
+// cleanup removes expired keys from the database
+func (s *DBIdempotencyStore) cleanup() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.PurgeExpired(); err != nil {
+			log.Printf("[IDEMPOTENCY] failed to purge expired keys: %v", err)
+		}
+	}
+}
+
+// PurgeExpired deletes the keys whose response is no longer replayed
+func (s *DBIdempotencyStore) PurgeExpired() error {
+	return s.db.Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyKey{}).Error
+}
+
+// Claim implements IdempotencyStore
+func (s *DBIdempotencyStore) Claim(userID uint, key, fingerprint string) (bool, *IdempotentResponse, error) {
+	now := time.Now()
+
+	// An expired key is free again
+	if err := s.db.Where("user_id = ? AND key = ? AND expires_at < ?", userID, key, now).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return false, nil, err
+	}
+
+	record := models.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(idempotencyLockTimeout),
+	}
+	res := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if res.Error != nil {
+		return false, nil, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil, nil
+	}
+
+	var existing models.IdempotencyKey
+	if err := s.db.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error; err != nil {
+		return false, nil, err
+	}
+	return false, &IdempotentResponse{
+		Fingerprint: existing.Fingerprint,
+		Completed:   existing.Completed,
+		StatusCode:  existing.StatusCode,
+		ContentType: existing.ContentType,
+		Body:        existing.Body,
+	}, nil
+}
+
+// Complete implements IdempotencyStore
+func (s *DBIdempotencyStore) Complete(userID uint, key string, response *IdempotentResponse, ttl time.Duration) error {
+	return s.db.Model(&models.IdempotencyKey{}).
+		Where("user_id = ? AND key = ?", userID, key).
+		Updates(map[string]interface{}{
+			"completed":    true,
+			"status_code":  response.StatusCode,
+			"content_type": response.ContentType,
+			"body":         response.Body,
+			"expires_at":   time.Now().Add(ttl),
+		}).Error
+}
+
+// Release implements IdempotencyStore
+func (s *DBIdempotencyStore) Release(userID uint, key string) error {
+	return s.db.Where("user_id = ? AND key = ?", userID, key).Delete(&models.IdempotencyKey{}).Error
+}
+
+// Idempotency makes mutating requests sent with an Idempotency-Key header
+// safe to retry. The first response per user and key is stored for ttl and
+// replayed for repeats; a repeat with a different method, path or body is
+// rejected. Server errors are not stored, so a failed request can be retried.
+// It must run after JWTAuth so that user_id is set.
+func Idempotency(store IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method != "POST" && method != "PUT" && method != "PATCH" && method != "DELETE" {
+			c.Next()
+			return
+		}
+
+		key := c.GetHeader(IdempotencyKeyHeader)
+		userIDInterface, exists := c.Get("user_id")
+		if key == "" || !exists {
+			c.Next()
+			return
+		}
+		userID := userIDInterface.(uint)
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":    false,
+				"error_code": "INVALID_IDEMPOTENCY_KEY",
+				"message":    fmt.Sprintf("Idempotency-Key maksimal %d karakter", maxIdempotencyKeyLength),
+			})
+			c.Abort()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"success":    false,
+					"error_code": "INVALID_REQUEST",
+					"message":    "Gagal membaca request",
+				})
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+		fingerprint := requestFingerprint(method, c.Request.URL.RequestURI(), body)
+
+		claimed, existing, err := store.Claim(userID, key, fingerprint)
+		if err != nil {
+			// Without the store the request is processed as if it had no key
+			log.Printf("[IDEMPOTENCY] failed to claim key %q of user %d: %v", key, userID, err)
+			c.Next()
+			return
+		}
+
+		if !claimed {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"success":    false,
+					"error_code": "IDEMPOTENCY_KEY_REUSED",
+					"message":    "Idempotency-Key sudah dipakai untuk request yang berbeda",
+				})
+			case !existing.Completed:
+				c.JSON(http.StatusConflict, gin.H{
+					"success":    false,
+					"error_code": "IDEMPOTENCY_KEY_IN_PROGRESS",
+					"message":    "Request dengan Idempotency-Key ini masih diproses",
+				})
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(existing.StatusCode, existing.ContentType, existing.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		writer := &responseWriter{
+			ResponseWriter: c.Writer,
+			body:           make([]byte, 0),
+			statusCode:     http.StatusOK,
+		}
+		c.Writer = writer
+
+		c.Next()
+
+		if writer.statusCode >= http.StatusInternalServerError || len(writer.body) > maxStoredResponseSize {
+			if err := store.Release(userID, key); err != nil {
+				log.Printf("[IDEMPOTENCY] failed to release key %q of user %d: %v", key, userID, err)
+			}
+			return
+		}
+
+		response := &IdempotentResponse{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			StatusCode:  writer.statusCode,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body,
+		}
+		if err := store.Complete(userID, key, response, ttl); err != nil {
+			log.Printf("[IDEMPOTENCY] failed to store response for key %q of user %d: %v", key, userID, err)
+		}
+	}
+}
+
+// requestFingerprint identifies the request a key was first used for
+func requestFingerprint(method, uri string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + uri + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
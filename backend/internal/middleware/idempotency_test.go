@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupIdempotencyTest(t *testing.T) (*gin.Engine, *gorm.DB, *int) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.IdempotencyKey{}))
+
+	calls := 0
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", uint(7))
+		c.Next()
+	})
+	r.Use(Idempotency(&DBIdempotencyStore{db: db}, time.Hour))
+	r.POST("/receipts", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"success": true, "id": calls})
+	})
+	r.POST("/fail", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+	})
+	return r, db, &calls
+}
+
+func sendIdempotent(r *gin.Engine, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	r, _, calls := setupIdempotencyTest(t)
+
+	first := sendIdempotent(r, "/receipts", "key-1", `{"qty":5}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	repeat := sendIdempotent(r, "/receipts", "key-1", `{"qty":5}`)
+	assert.Equal(t, http.StatusCreated, repeat.Code)
+	assert.Equal(t, first.Body.String(), repeat.Body.String())
+	assert.Equal(t, "true", repeat.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, *calls, "the handler runs once per key")
+
+	// Requests without a key are not deduplicated
+	sendIdempotent(r, "/receipts", "", `{"qty":5}`)
+	sendIdempotent(r, "/receipts", "", `{"qty":5}`)
+	assert.Equal(t, 3, *calls)
+}
+
+func TestIdempotency_RejectsDifferentBody(t *testing.T) {
+	r, _, calls := setupIdempotencyTest(t)
+
+	sendIdempotent(r, "/receipts", "key-1", `{"qty":5}`)
+	w := sendIdempotent(r, "/receipts", "key-1", `{"qty":6}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "IDEMPOTENCY_KEY_REUSED")
+	assert.Equal(t, 1, *calls)
+}
+
+func TestIdempotency_InProgressAndExpiredKeys(t *testing.T) {
+	r, db, calls := setupIdempotencyTest(t)
+
+	store := &DBIdempotencyStore{db: db}
+	claimed, _, err := store.Claim(7, "running", requestFingerprint(http.MethodPost, "/receipts", []byte(`{}`)))
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	w := sendIdempotent(r, "/receipts", "running", `{}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 0, *calls)
+
+	// A claim left behind by a crashed request expires
+	require.NoError(t, db.Model(&models.IdempotencyKey{}).Where("key = ?", "running").
+		Update("expires_at", time.Now().Add(-time.Second)).Error)
+	w = sendIdempotent(r, "/receipts", "running", `{}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	r, db, calls := setupIdempotencyTest(t)
+
+	assert.Equal(t, http.StatusInternalServerError, sendIdempotent(r, "/fail", "key-1", `{}`).Code)
+	assert.Equal(t, http.StatusInternalServerError, sendIdempotent(r, "/fail", "key-1", `{}`).Code)
+	assert.Equal(t, 2, *calls, "a failed request can be retried with the same key")
+
+	var count int64
+	db.Model(&models.IdempotencyKey{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
@@ -6,19 +6,72 @@ import (
 
 // KitchenAsset represents kitchen equipment and assets
 type KitchenAsset struct {
-	ID                 uint                `gorm:"primaryKey" json:"id"`
-	AssetCode          string              `gorm:"uniqueIndex;size:50;not null" json:"asset_code" validate:"required"`
-	Name               string              `gorm:"size:200;not null;index" json:"name" validate:"required"`
-	Category           string              `gorm:"size:50;index" json:"category"`
-	PurchaseDate       time.Time           `gorm:"index;not null" json:"purchase_date"`
-	PurchasePrice      float64             `gorm:"not null" json:"purchase_price" validate:"required,gte=0"`
-	CurrentValue       float64             `gorm:"not null" json:"current_value"`
-	DepreciationRate   float64             `gorm:"not null" json:"depreciation_rate" validate:"gte=0,lte=100"` // annual percentage
-	Condition          string              `gorm:"size:50;index" json:"condition" validate:"oneof=good fair poor"`
-	Location           string              `gorm:"size:100" json:"location"`
-	CreatedAt          time.Time           `json:"created_at"`
-	UpdatedAt          time.Time           `json:"updated_at"`
-	MaintenanceRecords []AssetMaintenance  `gorm:"foreignKey:AssetID" json:"maintenance_records,omitempty"`
+	ID                      uint               `gorm:"primaryKey" json:"id"`
+	AssetCode               string             `gorm:"uniqueIndex;size:50;not null" json:"asset_code" validate:"required"`
+	Name                    string             `gorm:"size:200;not null;index" json:"name" validate:"required"`
+	Category                string             `gorm:"size:50;index" json:"category"`
+	PurchaseDate            time.Time          `gorm:"index;not null" json:"purchase_date"`
+	PurchasePrice           Money              `gorm:"not null" json:"purchase_price" validate:"required,gte=0"`
+	CurrentValue            Money              `gorm:"not null" json:"current_value"` // book value after the posted depreciation
+	DepreciationMethod      string             `gorm:"size:30;not null;default:'straight_line'" json:"depreciation_method" validate:"oneof=straight_line declining_balance units_of_production"`
+	DepreciationRate        float64            `gorm:"not null" json:"depreciation_rate" validate:"gte=0,lte=100"` // annual percentage; derives the useful life when none is set and the declining-balance rate
+	UsefulLifeMonths        int                `gorm:"default:0" json:"useful_life_months" validate:"gte=0"`
+	SalvageValue            Money              `gorm:"default:0" json:"salvage_value" validate:"gte=0"`
+	TotalUnits              float64            `gorm:"default:0" json:"total_units" validate:"gte=0"` // expected lifetime output for units of production
+	AccumulatedDepreciation Money              `gorm:"default:0" json:"accumulated_depreciation"`
+	Condition               string             `gorm:"size:50;index" json:"condition" validate:"oneof=good fair poor"`
+	Location                string             `gorm:"size:100" json:"location"`
+	Status                  string             `gorm:"size:20;not null;default:'active';index" json:"status"` // active, disposed, sold
+	DisposalDate            *time.Time         `json:"disposal_date"`
+	DisposalProceeds        Money              `gorm:"default:0" json:"disposal_proceeds"`
+	DisposalGainLoss        Money              `gorm:"default:0" json:"disposal_gain_loss"` // positive for a gain, negative for a loss
+	DisposalNotes           string             `gorm:"type:text" json:"disposal_notes"`
+	CreatedAt               time.Time          `json:"created_at"`
+	UpdatedAt               time.Time          `json:"updated_at"`
+	MaintenanceRecords      []AssetMaintenance `gorm:"foreignKey:AssetID" json:"maintenance_records,omitempty"`
+}
+
+// AssetUsageLog records the output of an asset in a period, used by the
+// units-of-production depreciation method
+type AssetUsageLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	AssetID   uint      `gorm:"index;not null" json:"asset_id"`
+	UsageDate time.Time `gorm:"index;not null" json:"usage_date"`
+	Units     float64   `gorm:"not null" json:"units" validate:"required,gt=0"`
+	Notes     string    `gorm:"type:text" json:"notes"`
+	CreatedBy uint      `gorm:"not null;index" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DepreciationRun represents a monthly depreciation posting. A run can be
+// reversed as long as it is the latest posted run.
+type DepreciationRun struct {
+	ID          uint                  `gorm:"primaryKey" json:"id"`
+	Year        int                   `gorm:"index:idx_depreciation_run_period;not null" json:"year"`
+	Month       int                   `gorm:"index:idx_depreciation_run_period;not null" json:"month"`
+	Status      string                `gorm:"size:20;not null;index" json:"status"` // posted, reversed
+	AssetCount  int                   `gorm:"not null" json:"asset_count"`
+	TotalAmount Money                 `gorm:"not null" json:"total_amount"`
+	PostedBy    uint                  `gorm:"index" json:"posted_by"` // 0 for the automatic run
+	ReversedBy  *uint                 `json:"reversed_by"`
+	ReversedAt  *time.Time            `json:"reversed_at"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+	Lines       []DepreciationRunLine `gorm:"foreignKey:RunID" json:"lines,omitempty"`
+}
+
+// DepreciationRunLine represents the depreciation of one asset in a run
+type DepreciationRunLine struct {
+	ID              uint         `gorm:"primaryKey" json:"id"`
+	RunID           uint         `gorm:"index;not null" json:"run_id"`
+	AssetID         uint         `gorm:"index;not null" json:"asset_id"`
+	Method          string       `gorm:"size:30;not null" json:"method"`
+	Amount          Money        `gorm:"not null" json:"amount"`
+	UnitsUsed       float64      `gorm:"default:0" json:"units_used"`
+	BookValueBefore Money        `gorm:"not null" json:"book_value_before"`
+	BookValueAfter  Money        `gorm:"not null" json:"book_value_after"`
+	JournalEntryID  uint         `gorm:"index" json:"journal_entry_id"`
+	Asset           KitchenAsset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
 }
 
 // AssetMaintenance represents maintenance activities for assets
@@ -27,34 +80,365 @@ type AssetMaintenance struct {
 	AssetID         uint         `gorm:"index;not null" json:"asset_id"`
 	MaintenanceDate time.Time    `gorm:"index;not null" json:"maintenance_date"`
 	Description     string       `gorm:"type:text" json:"description"`
-	Cost            float64      `gorm:"not null" json:"cost" validate:"gte=0"`
+	Cost            Money        `gorm:"not null" json:"cost" validate:"gte=0"`
 	PerformedBy     string       `gorm:"size:100" json:"performed_by"`
+	PlanID          *uint        `gorm:"index" json:"plan_id"` // preventive maintenance plan the record completes
+	DueDate         *time.Time   `json:"due_date"`             // date the planned maintenance was due
 	CreatedAt       time.Time    `json:"created_at"`
 	Asset           KitchenAsset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
 }
 
+// MaintenancePlan represents a preventive maintenance schedule for an asset,
+// due every IntervalDays days or every IntervalUnits units of recorded usage
+type MaintenancePlan struct {
+	ID                 uint         `gorm:"primaryKey" json:"id"`
+	AssetID            uint         `gorm:"index;not null" json:"asset_id" validate:"required"`
+	Name               string       `gorm:"size:200;not null" json:"name" validate:"required"`
+	Description        string       `gorm:"type:text" json:"description"`
+	IntervalType       string       `gorm:"size:20;not null" json:"interval_type" validate:"required,oneof=days usage"`
+	IntervalDays       int          `gorm:"default:0" json:"interval_days" validate:"gte=0"`
+	IntervalUnits      float64      `gorm:"default:0" json:"interval_units" validate:"gte=0"`
+	IsSafetyCritical   bool         `gorm:"default:false;index" json:"is_safety_critical"`
+	EstimatedCost      Money        `gorm:"default:0" json:"estimated_cost" validate:"gte=0"`
+	ResponsibleUserID  uint         `gorm:"index;not null" json:"responsible_user_id" validate:"required"`
+	StartDate          time.Time    `gorm:"not null" json:"start_date"` // the first interval is counted from here
+	LastCompletedAt    *time.Time   `json:"last_completed_at"`
+	LastCompletedUnits float64      `gorm:"default:0" json:"last_completed_units"` // total recorded usage of the asset at the last completion
+	NextDueDate        *time.Time   `gorm:"index" json:"next_due_date"`            // day-based plans only; usage plans are projected from the recorded usage
+	LastRemindedAt     *time.Time   `json:"last_reminded_at"`
+	IsActive           bool         `gorm:"default:true;index" json:"is_active"`
+	CreatedBy          uint         `gorm:"not null;index" json:"created_by"`
+	CreatedAt          time.Time    `json:"created_at"`
+	UpdatedAt          time.Time    `json:"updated_at"`
+	Asset              KitchenAsset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+	ResponsibleUser    User         `gorm:"foreignKey:ResponsibleUserID" json:"responsible_user,omitempty"`
+}
+
+// AssetAudit represents a physical audit of the kitchen assets. Staff scan
+// the asset labels and confirm where each asset is and its condition; once
+// approved, the findings are applied to the assets.
+type AssetAudit struct {
+	ID              uint             `gorm:"primaryKey" json:"id"`
+	AuditNumber     string           `gorm:"uniqueIndex;size:50;not null" json:"audit_number"`
+	Location        string           `gorm:"size:100" json:"location"`             // audited location, empty for all assets
+	Status          string           `gorm:"size:20;not null;index" json:"status"` // in_progress, submitted, approved, rejected
+	Notes           string           `gorm:"type:text" json:"notes"`
+	CreatedBy       uint             `gorm:"not null;index" json:"created_by"`
+	SubmittedAt     *time.Time       `json:"submitted_at"`
+	ApprovedBy      *uint            `gorm:"index" json:"approved_by"`
+	ApprovedAt      *time.Time       `json:"approved_at"`
+	RejectionReason string           `gorm:"type:text" json:"rejection_reason"`
+	CreatedAt       time.Time        `gorm:"index" json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	Creator         User             `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+	Approver        *User            `gorm:"foreignKey:ApprovedBy" json:"approver,omitempty"`
+	Items           []AssetAuditItem `gorm:"foreignKey:AuditID" json:"items,omitempty"`
+}
+
+// AssetAuditItem represents the finding for one asset in an audit
+type AssetAuditItem struct {
+	ID                uint         `gorm:"primaryKey" json:"id"`
+	AuditID           uint         `gorm:"index;not null" json:"audit_id"`
+	AssetID           uint         `gorm:"index;not null" json:"asset_id"`
+	ExpectedLocation  string       `gorm:"size:100" json:"expected_location"`
+	ExpectedCondition string       `gorm:"size:50" json:"expected_condition"`
+	FoundLocation     string       `gorm:"size:100" json:"found_location"`
+	FoundCondition    string       `gorm:"size:50" json:"found_condition"`
+	Result            string       `gorm:"size:20;not null;index" json:"result"` // pending, found, moved, missing
+	ScannedBy         *uint        `json:"scanned_by"`
+	ScannedAt         *time.Time   `json:"scanned_at"`
+	Notes             string       `gorm:"type:text" json:"notes"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+	Asset             KitchenAsset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+}
+
 // CashFlowEntry represents a financial transaction
 type CashFlowEntry struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	TransactionID    string    `gorm:"uniqueIndex;size:50;not null" json:"transaction_id" validate:"required"`
+	Date             time.Time `gorm:"index;not null" json:"date"`
+	Category         string    `gorm:"size:50;not null;index" json:"category" validate:"required,oneof=bahan_baku gaji utilitas operasional lainnya"` // bahan_baku, gaji, utilitas, operasional, lainnya
+	Type             string    `gorm:"size:20;not null;index" json:"type" validate:"required,oneof=income expense"`                                   // income, expense
+	Amount           Money     `gorm:"not null" json:"amount" validate:"required,gt=0"`
+	Description      string    `gorm:"type:text" json:"description"`
+	Reference        string    `gorm:"size:100;index" json:"reference"` // GRN number, employee ID, etc.
+	IsPayable        bool      `gorm:"default:false" json:"is_payable"` // expense owed to a supplier, credited to accounts payable instead of cash
+	FundingTrancheID *uint     `gorm:"index" json:"funding_tranche_id"` // tranche the expense is accounted to; unassigned expenses fall to the tranche covering their date
+	CreatedBy        uint      `gorm:"not null;index" json:"created_by"`
+	CreatedAt        time.Time `json:"created_at"`
+	Creator          User      `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+}
+
+// BudgetTarget represents budget targets and actuals for a cash flow category.
+// Month 0 is the yearly budget, months 1-12 are monthly budgets. Actual is
+// filled from cash flow expenses by BudgetService whenever a target is read;
+// the stored column is not kept in sync.
+type BudgetTarget struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Year      int       `gorm:"uniqueIndex:idx_budget_period;index;not null" json:"year" validate:"required,gte=2000"`
+	Month     int       `gorm:"uniqueIndex:idx_budget_period;index;not null" json:"month" validate:"gte=0,lte=12"`
+	Category  string    `gorm:"uniqueIndex:idx_budget_period;size:50;not null;index" json:"category" validate:"required"`
+	Target    Money     `gorm:"not null" json:"target" validate:"gte=0"`
+	Actual    Money     `gorm:"default:0" json:"actual"`
+	Notes     string    `gorm:"type:text" json:"notes"`
+	CreatedBy uint      `gorm:"index" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BudgetAlert records that a budget threshold notification was sent so each
+// threshold is only announced once per budget target
+type BudgetAlert struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	BudgetTargetID uint      `gorm:"uniqueIndex:idx_budget_alert;not null" json:"budget_target_id"`
+	Threshold      int       `gorm:"uniqueIndex:idx_budget_alert;not null" json:"threshold"` // percent of target
+	Actual         Money     `json:"actual"`
+	Target         Money     `json:"target"`
+	AbsorptionRate float64   `json:"absorption_rate"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Account represents an account in the chart of accounts
+type Account struct {
 	ID            uint      `gorm:"primaryKey" json:"id"`
-	TransactionID string    `gorm:"uniqueIndex;size:50;not null" json:"transaction_id" validate:"required"`
-	Date          time.Time `gorm:"index;not null" json:"date"`
-	Category      string    `gorm:"size:50;not null;index" json:"category" validate:"required,oneof=bahan_baku gaji utilitas operasional lainnya"` // bahan_baku, gaji, utilitas, operasional, lainnya
-	Type          string    `gorm:"size:20;not null;index" json:"type" validate:"required,oneof=income expense"`                                  // income, expense
-	Amount        float64   `gorm:"not null" json:"amount" validate:"required,gt=0"`
+	Code          string    `gorm:"uniqueIndex;size:20;not null" json:"code" validate:"required"`
+	Name          string    `gorm:"size:150;not null" json:"name" validate:"required"`
+	Type          string    `gorm:"size:20;not null;index" json:"type" validate:"required,oneof=asset liability equity revenue expense"`
+	NormalBalance string    `gorm:"size:10;not null" json:"normal_balance" validate:"required,oneof=debit credit"` // credit for contra assets such as accumulated depreciation
+	Category      string    `gorm:"size:50;index" json:"category"`                                                 // reporting group, matches the cash flow category for expense accounts
 	Description   string    `gorm:"type:text" json:"description"`
-	Reference     string    `gorm:"size:100;index" json:"reference"` // GRN number, employee ID, etc.
+	IsSystem      bool      `gorm:"default:false" json:"is_system"` // used by automatic postings
+	IsActive      bool      `gorm:"default:true;index" json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// JournalEntry represents a balanced double-entry journal. Automatic entries
+// are identified by SourceType and SourceRef (e.g. cash_flow + transaction ID).
+type JournalEntry struct {
+	ID           uint          `gorm:"primaryKey" json:"id"`
+	EntryNumber  string        `gorm:"uniqueIndex;size:50;not null" json:"entry_number"`
+	Date         time.Time     `gorm:"index;not null" json:"date"`
+	Description  string        `gorm:"type:text" json:"description"`
+	SourceType   string        `gorm:"size:30;not null;index:idx_journal_source" json:"source_type"` // manual, cash_flow, asset_purchase, asset_maintenance, depreciation, supplier_payment, reversal
+	SourceRef    string        `gorm:"size:100;index:idx_journal_source" json:"source_ref"`
+	Reference    string        `gorm:"size:100;index" json:"reference"`      // GRN number, asset code, etc.
+	Status       string        `gorm:"size:20;not null;index" json:"status"` // posted, reversed
+	ReversalOfID *uint         `gorm:"index" json:"reversal_of_id,omitempty"`
+	TotalAmount  Money         `gorm:"not null" json:"total_amount"`
+	CreatedBy    uint          `gorm:"index" json:"created_by"`
+	CreatedAt    time.Time     `json:"created_at"`
+	Lines        []JournalLine `gorm:"foreignKey:JournalEntryID" json:"lines,omitempty"`
+}
+
+// JournalLine represents a debit or credit line of a journal entry
+type JournalLine struct {
+	ID             uint    `gorm:"primaryKey" json:"id"`
+	JournalEntryID uint    `gorm:"index;not null" json:"journal_entry_id"`
+	AccountID      uint    `gorm:"index;not null" json:"account_id"`
+	Debit          Money   `gorm:"not null;default:0" json:"debit" validate:"gte=0"`
+	Credit         Money   `gorm:"not null;default:0" json:"credit" validate:"gte=0"`
+	Description    string  `gorm:"size:255" json:"description"`
+	Account        Account `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+}
+
+// SupplierInvoice represents an invoice received from a supplier for a goods
+// receipt. It is matched three ways against the purchase order and the goods
+// receipt before it can be paid.
+type SupplierInvoice struct {
+	ID             uint                  `gorm:"primaryKey" json:"id"`
+	InvoiceNumber  string                `gorm:"size:100;not null;index:idx_supplier_invoice_number" json:"invoice_number" validate:"required"` // supplier's own invoice number
+	SupplierID     uint                  `gorm:"not null;index:idx_supplier_invoice_number" json:"supplier_id"`
+	POID           uint                  `gorm:"not null;index" json:"po_id"`
+	GRNID          uint                  `gorm:"not null;index" json:"grn_id"`
+	InvoiceDate    time.Time             `gorm:"index;not null" json:"invoice_date"`
+	DueDate        time.Time             `gorm:"index;not null" json:"due_date"`
+	Subtotal       Money                 `gorm:"not null" json:"subtotal"`
+	TaxAmount      Money                 `gorm:"default:0" json:"tax_amount"`
+	TotalAmount    Money                 `gorm:"not null" json:"total_amount"`
+	ReceivedAmount Money                 `gorm:"default:0" json:"received_amount"` // value of the goods receipt at purchase order prices
+	PaidAmount     Money                 `gorm:"default:0" json:"paid_amount"`
+	Status         string                `gorm:"size:20;not null;index" json:"status"`       // on_hold, open, partially_paid, paid, cancelled
+	MatchStatus    string                `gorm:"size:20;not null;index" json:"match_status"` // matched, mismatch, overridden
+	MatchNotes     string                `gorm:"type:text" json:"match_notes"`
+	InvoicePhoto   string                `gorm:"size:500" json:"invoice_photo"`
+	Notes          string                `gorm:"type:text" json:"notes"`
+	ApprovedBy     *uint                 `gorm:"index" json:"approved_by"`
+	ApprovedAt     *time.Time            `json:"approved_at"`
+	CreatedBy      uint                  `gorm:"not null;index" json:"created_by"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	Supplier       Supplier              `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	PurchaseOrder  PurchaseOrder         `gorm:"foreignKey:POID" json:"purchase_order,omitempty"`
+	GoodsReceipt   GoodsReceipt          `gorm:"foreignKey:GRNID" json:"goods_receipt,omitempty"`
+	Items          []SupplierInvoiceItem `gorm:"foreignKey:InvoiceID" json:"items,omitempty"`
+	Payments       []SupplierPayment     `gorm:"foreignKey:InvoiceID" json:"payments,omitempty"`
+}
+
+// SupplierInvoiceItem represents an invoice line with the result of its
+// three-way match against the ordered and received quantities
+type SupplierInvoiceItem struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	InvoiceID        uint       `gorm:"index;not null" json:"invoice_id"`
+	IngredientID     uint       `gorm:"index;not null" json:"ingredient_id"`
+	Quantity         float64    `gorm:"not null" json:"quantity" validate:"required,gt=0"`
+	UnitPrice        Money      `gorm:"not null" json:"unit_price" validate:"gte=0"`
+	Subtotal         Money      `gorm:"not null" json:"subtotal"`
+	OrderedQuantity  float64    `gorm:"default:0" json:"ordered_quantity"`
+	ReceivedQuantity float64    `gorm:"default:0" json:"received_quantity"`
+	POUnitPrice      Money      `gorm:"default:0" json:"po_unit_price"`
+	QuantityVariance float64    `gorm:"default:0" json:"quantity_variance"` // percent of received quantity
+	PriceVariance    float64    `gorm:"default:0" json:"price_variance"`    // percent of purchase order price
+	MatchStatus      string     `gorm:"size:20" json:"match_status"`        // matched, mismatch
+	MatchNotes       string     `gorm:"type:text" json:"match_notes"`
+	Ingredient       Ingredient `gorm:"foreignKey:IngredientID" json:"ingredient,omitempty"`
+}
+
+// SupplierPayment represents a full or partial payment of a supplier invoice
+type SupplierPayment struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	PaymentNumber string    `gorm:"uniqueIndex;size:50;not null" json:"payment_number"`
+	InvoiceID     uint      `gorm:"index;not null" json:"invoice_id"`
+	SupplierID    uint      `gorm:"index;not null" json:"supplier_id"`
+	PaymentDate   time.Time `gorm:"index;not null" json:"payment_date"`
+	Amount        Money     `gorm:"not null" json:"amount" validate:"required,gt=0"`
+	Method        string    `gorm:"size:20;not null" json:"method" validate:"required,oneof=transfer cash"` // transfer, cash
+	BankReference string    `gorm:"size:100;index" json:"bank_reference"`                                   // bank transfer reference number
+	BankAccount   string    `gorm:"size:100" json:"bank_account"`                                           // destination account of the supplier
+	Notes         string    `gorm:"type:text" json:"notes"`
 	CreatedBy     uint      `gorm:"not null;index" json:"created_by"`
 	CreatedAt     time.Time `json:"created_at"`
-	Creator       User      `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+	Supplier      Supplier  `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
 }
 
-// BudgetTarget represents budget targets and actuals
-type BudgetTarget struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Year      int       `gorm:"index;not null" json:"year" validate:"required,gte=2000"`
-	Month     int       `gorm:"index;not null" json:"month" validate:"required,gte=1,lte=12"`
-	Category  string    `gorm:"size:50;not null;index" json:"category" validate:"required"`
-	Target    float64   `gorm:"not null" json:"target" validate:"gte=0"`
-	Actual    float64   `gorm:"default:0" json:"actual"`
-	UpdatedAt time.Time `json:"updated_at"`
+// FundingTranche represents a government program disbursement received by the
+// SPPG, the period it covers and the portions it is meant to fund. Receiving a
+// tranche records a cash flow income entry.
+type FundingTranche struct {
+	ID              uint                   `gorm:"primaryKey" json:"id"`
+	TrancheNumber   string                 `gorm:"uniqueIndex;size:50;not null" json:"tranche_number"`
+	Source          string                 `gorm:"size:150;not null" json:"source" validate:"required"` // e.g. Badan Gizi Nasional
+	ReferenceNumber string                 `gorm:"size:100;index" json:"reference_number"`              // SP2D or bank transfer reference
+	ReceivedDate    time.Time              `gorm:"index;not null" json:"received_date"`
+	Amount          Money                  `gorm:"not null" json:"amount" validate:"required,gt=0"`
+	PeriodStart     time.Time              `gorm:"index;not null" json:"period_start"`
+	PeriodEnd       time.Time              `gorm:"index;not null" json:"period_end"`
+	TargetPortions  int                    `gorm:"default:0" json:"target_portions" validate:"gte=0"`
+	Description     string                 `gorm:"type:text" json:"description"`
+	CashFlowEntryID *uint                  `gorm:"index" json:"cash_flow_entry_id"`
+	CreatedBy       uint                   `gorm:"not null;index" json:"created_by"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+	Schools         []FundingTrancheSchool `gorm:"foreignKey:TrancheID" json:"schools,omitempty"`
+}
+
+// FundingTrancheSchool is a school a tranche is meant for, with its target portions
+type FundingTrancheSchool struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	TrancheID      uint   `gorm:"uniqueIndex:idx_tranche_school;not null" json:"tranche_id"`
+	SchoolID       uint   `gorm:"uniqueIndex:idx_tranche_school;not null" json:"school_id"`
+	TargetPortions int    `gorm:"default:0" json:"target_portions" validate:"gte=0"`
+	School         School `gorm:"foreignKey:SchoolID" json:"school,omitempty"`
+}
+
+// BankStatement represents an imported bank account statement (mutasi rekening)
+type BankStatement struct {
+	ID             uint                `gorm:"primaryKey" json:"id"`
+	BankName       string              `gorm:"size:50" json:"bank_name"`
+	AccountNumber  string              `gorm:"size:50;index" json:"account_number"`
+	Format         string              `gorm:"size:20;not null" json:"format"` // csv, mt940
+	FileName       string              `gorm:"size:255" json:"file_name"`
+	PeriodStart    time.Time           `gorm:"index" json:"period_start"`
+	PeriodEnd      time.Time           `gorm:"index" json:"period_end"`
+	OpeningBalance Money               `gorm:"default:0" json:"opening_balance"`
+	ClosingBalance Money               `gorm:"default:0" json:"closing_balance"`
+	TotalCredit    Money               `gorm:"default:0" json:"total_credit"`
+	TotalDebit     Money               `gorm:"default:0" json:"total_debit"`
+	LineCount      int                 `gorm:"default:0" json:"line_count"`
+	ImportedBy     uint                `gorm:"not null;index" json:"imported_by"`
+	CreatedAt      time.Time           `json:"created_at"`
+	Lines          []BankStatementLine `gorm:"foreignKey:StatementID" json:"lines,omitempty"`
+}
+
+// BankStatementLine represents a single bank mutation and the book item it is
+// reconciled with, either a cash flow entry or a supplier transfer payment
+type BankStatementLine struct {
+	ID                uint             `gorm:"primaryKey" json:"id"`
+	StatementID       uint             `gorm:"index;not null" json:"statement_id"`
+	AccountNumber     string           `gorm:"size:50;index" json:"account_number"`
+	LineNumber        int              `gorm:"not null" json:"line_number"`
+	TransactionDate   time.Time        `gorm:"index;not null" json:"transaction_date"`
+	Description       string           `gorm:"type:text" json:"description"`
+	Reference         string           `gorm:"size:100;index" json:"reference"`
+	Direction         string           `gorm:"size:10;not null" json:"direction"` // credit (money in), debit (money out)
+	Amount            Money            `gorm:"not null" json:"amount"`
+	Balance           *Money           `json:"balance"`                                               // running balance when the bank provides it
+	DedupKey          string           `gorm:"uniqueIndex;size:64;not null" json:"-"`                 // prevents importing overlapping statements twice
+	MatchStatus       string           `gorm:"size:20;index;default:'unmatched'" json:"match_status"` // unmatched, matched
+	MatchType         string           `gorm:"size:20" json:"match_type"`                             // auto, manual
+	CashFlowEntryID   *uint            `gorm:"uniqueIndex" json:"cash_flow_entry_id"`
+	SupplierPaymentID *uint            `gorm:"uniqueIndex" json:"supplier_payment_id"`
+	MatchedBy         *uint            `gorm:"index" json:"matched_by"`
+	MatchedAt         *time.Time       `json:"matched_at"`
+	CreatedAt         time.Time        `json:"created_at"`
+	CashFlowEntry     *CashFlowEntry   `gorm:"foreignKey:CashFlowEntryID" json:"cash_flow_entry,omitempty"`
+	SupplierPayment   *SupplierPayment `gorm:"foreignKey:SupplierPaymentID" json:"supplier_payment,omitempty"`
+}
+
+// PettyCashFund represents a petty cash (kas kecil) float held by a custodian
+// for small daily purchases. Balance is the cash on hand; the float is
+// restored by approved replenishments.
+type PettyCashFund struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"size:100;not null" json:"name" validate:"required"`
+	CustodianID uint      `gorm:"index;not null" json:"custodian_id" validate:"required"`
+	FloatAmount Money     `gorm:"not null" json:"float_amount" validate:"required,gt=0"`
+	Balance     Money     `gorm:"not null" json:"balance"`
+	IsActive    bool      `gorm:"default:true;index" json:"is_active"`
+	CreatedBy   uint      `gorm:"not null;index" json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Custodian   User      `gorm:"foreignKey:CustodianID" json:"custodian,omitempty"`
+}
+
+// PettyCashExpense represents a purchase paid from a petty cash fund. Every
+// expense is booked as a cash flow expense; food purchases tagged with an
+// ingredient also add stock through an inventory movement.
+type PettyCashExpense struct {
+	ID                  uint        `gorm:"primaryKey" json:"id"`
+	ExpenseNumber       string      `gorm:"uniqueIndex;size:50;not null" json:"expense_number"`
+	FundID              uint        `gorm:"index;not null" json:"fund_id"`
+	ExpenseDate         time.Time   `gorm:"index;not null" json:"expense_date"`
+	Category            string      `gorm:"size:50;not null;index" json:"category" validate:"required,oneof=bahan_baku gaji utilitas operasional lainnya"`
+	Description         string      `gorm:"type:text;not null" json:"description" validate:"required"`
+	Amount              Money       `gorm:"not null" json:"amount" validate:"required,gt=0"`
+	ReceiptPhoto        string      `gorm:"size:500;not null" json:"receipt_photo" validate:"required"`
+	IngredientID        *uint       `gorm:"index" json:"ingredient_id"`
+	Quantity            float64     `gorm:"default:0" json:"quantity"` // in the ingredient unit
+	CashFlowEntryID     *uint       `gorm:"index" json:"cash_flow_entry_id"`
+	InventoryMovementID *uint       `gorm:"index" json:"inventory_movement_id"`
+	ReplenishmentID     *uint       `gorm:"index" json:"replenishment_id"` // set once included in a replenishment request
+	CreatedBy           uint        `gorm:"not null;index" json:"created_by"`
+	CreatedAt           time.Time   `json:"created_at"`
+	Ingredient          *Ingredient `gorm:"foreignKey:IngredientID" json:"ingredient,omitempty"`
+}
+
+// PettyCashReplenishment represents a request to top a petty cash fund back up
+// to its float for the expenses it covers, approved by the Kepala SPPG
+type PettyCashReplenishment struct {
+	ID                  uint               `gorm:"primaryKey" json:"id"`
+	ReplenishmentNumber string             `gorm:"uniqueIndex;size:50;not null" json:"replenishment_number"`
+	FundID              uint               `gorm:"index;not null" json:"fund_id"`
+	Amount              Money              `gorm:"not null" json:"amount"`
+	Status              string             `gorm:"size:20;not null;index" json:"status"` // pending, approved, rejected
+	Notes               string             `gorm:"type:text" json:"notes"`
+	RequestedBy         uint               `gorm:"not null;index" json:"requested_by"`
+	ApprovedBy          *uint              `gorm:"index" json:"approved_by"`
+	ApprovedAt          *time.Time         `json:"approved_at"`
+	RejectionReason     string             `gorm:"type:text" json:"rejection_reason"`
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+	Fund                PettyCashFund      `gorm:"foreignKey:FundID" json:"fund,omitempty"`
+	Expenses            []PettyCashExpense `gorm:"foreignKey:ReplenishmentID" json:"expenses,omitempty"`
 }
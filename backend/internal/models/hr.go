@@ -36,15 +36,15 @@ type Attendance struct {
 
 // WiFiConfig represents authorized Wi-Fi networks for attendance
 type WiFiConfig struct {
-	ID         uint      `gorm:"primaryKey" json:"id"`
-	SSID       string    `gorm:"column:ss_id;size:100;not null;index" json:"ssid" validate:"required"`
-	BSSID      string    `gorm:"column:bss_id;size:100;not null;index" json:"bssid" validate:"required"`
-	Location   string    `gorm:"size:200" json:"location"`
-	IPRange    string    `gorm:"size:100" json:"ip_range"`           // e.g., "192.168.1.0/24"
-	AllowedIPs []string  `gorm:"type:text[]" json:"allowed_ips"`     // Specific IPs allowed
-	IsActive   bool      `gorm:"default:true;index" json:"is_active"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	SSID       string     `gorm:"column:ss_id;size:100;not null;index" json:"ssid" validate:"required"`
+	BSSID      string     `gorm:"column:bss_id;size:100;not null;index" json:"bssid" validate:"required"`
+	Location   string     `gorm:"size:200" json:"location"`
+	IPRange    string     `gorm:"size:100" json:"ip_range"` // e.g., "192.168.1.0/24"
+	AllowedIPs StringList `json:"allowed_ips"`              // Specific IPs allowed
+	IsActive   bool       `gorm:"default:true;index" json:"is_active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
 }
 
 // TableName specifies the table name for WiFiConfig
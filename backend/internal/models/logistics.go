@@ -131,6 +131,7 @@ type StatusTransition struct {
 	Notes            string         `gorm:"type:text" json:"notes"`
 	MediaURL         string         `gorm:"size:500" json:"media_url"`  // Photo/video URL
 	MediaType        string         `gorm:"size:20" json:"media_type"`  // "photo" or "video"
+	ThumbnailURL     string         `gorm:"size:500" json:"thumbnail_url"` // Preview of photos, empty for videos
 	DeliveryRecord   DeliveryRecord `gorm:"foreignKey:DeliveryRecordID" json:"delivery_record,omitempty"`
 	User             User           `gorm:"foreignKey:TransitionedBy" json:"user,omitempty"`
 }
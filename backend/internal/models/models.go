@@ -6,6 +6,14 @@ func AllModels() []interface{} {
 		// User & Authentication
 		&User{},
 		&AuditTrail{},
+		&PasswordHistory{},
+		&UserSession{},
+		&RefreshToken{},
+		&TwoFactorRecoveryCode{},
+		&Role{},
+		&Permission{},
+		&RolePermission{},
+		&UserPermissionOverride{},
 		
 		// Recipe & Menu Planning - Ingredients & Semi-Finished Goods
 		&Ingredient{},
@@ -55,11 +63,36 @@ func AllModels() []interface{} {
 		// Financial & Asset Management
 		&KitchenAsset{},
 		&AssetMaintenance{},
+		&MaintenancePlan{},
+		&AssetAudit{},
+		&AssetAuditItem{},
+		&AssetUsageLog{},
+		&DepreciationRun{},
+		&DepreciationRunLine{},
 		&CashFlowEntry{},
 		&BudgetTarget{},
+		&BudgetAlert{},
+		&Account{},
+		&JournalEntry{},
+		&JournalLine{},
+		&SupplierInvoice{},
+		&SupplierInvoiceItem{},
+		&SupplierPayment{},
+		&FundingTranche{},
+		&FundingTrancheSchool{},
+		&BankStatement{},
+		&BankStatementLine{},
+		&PettyCashFund{},
+		&PettyCashExpense{},
+		&PettyCashReplenishment{},
 		
 		// System Configuration
 		&SystemConfig{},
 		&Notification{},
+		&OutboxEvent{},
+		&SyncOperation{},
+		&IdempotencyKey{},
+		&WebhookSubscription{},
+		&WebhookDelivery{},
 	}
 }
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MoneyScale is the number of minor units (sen) in one rupiah
+const MoneyScale = 100
+
+// Money is an amount of rupiah stored as an integer number of sen, so sums,
+// comparisons and report totals are exact. It is stored as a bigint column and
+// serialized to JSON as a decimal number of rupiah (e.g. 1500000.5).
+type Money int64
+
+// Rupiah returns the Money for a whole number of rupiah
+func Rupiah(rupiah int64) Money {
+	return Money(rupiah * MoneyScale)
+}
+
+// NewMoney converts a float amount of rupiah to Money, rounding half away from
+// zero to the nearest sen
+func NewMoney(rupiah float64) Money {
+	return Money(math.Round(rupiah * MoneyScale))
+}
+
+// ParseMoney parses a decimal amount of rupiah such as "1500000", "-12.5" or
+// "1500000.50" without going through a float. Digits beyond the sen are
+// rounded half away from zero.
+func ParseMoney(value string) (Money, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return 0, fmt.Errorf("jumlah uang kosong")
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac := s, ""
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		whole, frac = s[:dot], s[dot+1:]
+	}
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("jumlah uang tidak valid: %q", value)
+	}
+	for _, part := range []string{whole, frac} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return 0, fmt.Errorf("jumlah uang tidak valid: %q", value)
+			}
+		}
+	}
+
+	var rupiah int64
+	if whole != "" {
+		var err error
+		if rupiah, err = strconv.ParseInt(whole, 10, 64); err != nil || rupiah > math.MaxInt64/MoneyScale-1 {
+			return 0, fmt.Errorf("jumlah uang tidak valid: %q", value)
+		}
+	}
+
+	sen := int64(0)
+	for i := 0; i < 2; i++ {
+		sen *= 10
+		if i < len(frac) {
+			sen += int64(frac[i] - '0')
+		}
+	}
+	if len(frac) > 2 && frac[2] >= '5' {
+		sen++
+	}
+
+	amount := rupiah*MoneyScale + sen
+	if negative {
+		amount = -amount
+	}
+	return Money(amount), nil
+}
+
+// Float64 returns the amount in rupiah as a float, for display and charts only
+func (m Money) Float64() float64 {
+	return float64(m) / MoneyScale
+}
+
+// String formats the amount in rupiah with two decimals, e.g. "1500000.50"
+func (m Money) String() string {
+	sign := ""
+	value := int64(m)
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/MoneyScale, value%MoneyScale)
+}
+
+// Mul multiplies the amount by a quantity or rate, rounding to the nearest sen
+func (m Money) Mul(factor float64) Money {
+	return Money(math.Round(float64(m) * factor))
+}
+
+// Div divides the amount into n parts, rounding to the nearest sen
+func (m Money) Div(n int64) Money {
+	if n == 0 {
+		return 0
+	}
+	value, divisor := int64(m), n
+	if divisor < 0 {
+		value, divisor = -value, -divisor
+	}
+	if value < 0 {
+		return Money(-((-value + divisor/2) / divisor))
+	}
+	return Money((value + divisor/2) / divisor)
+}
+
+// Ratio returns m divided by other as a float, e.g. for percentages
+func (m Money) Ratio(other Money) float64 {
+	if other == 0 {
+		return 0
+	}
+	return float64(m) / float64(other)
+}
+
+// Abs returns the absolute amount
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// MarshalJSON writes the amount as a decimal number of rupiah
+func (m Money) MarshalJSON() ([]byte, error) {
+	s := m.String()
+	s = strings.TrimSuffix(s, ".00")
+	if strings.Contains(s, ".") {
+		s = strings.TrimSuffix(s, "0")
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalJSON reads a decimal number of rupiah, given as a number or string
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	if strings.ContainsAny(s, "eE") {
+		// Exponent notation from some JSON encoders
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("jumlah uang tidak valid: %s", string(data))
+		}
+		*m = NewMoney(f)
+		return nil
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount as an integer number of sen
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// Scan reads an integer number of sen
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Money(v)
+	case float64:
+		*m = Money(math.Round(v))
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("tipe jumlah uang tidak didukung: %T", src)
+	}
+	return nil
+}
+
+func (m *Money) scanString(s string) error {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		*m = Money(n)
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("jumlah uang tidak valid: %q", s)
+	}
+	*m = Money(math.Round(f))
+	return nil
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Money
+	}{
+		{"1500000", Rupiah(1500000)},
+		{"1500000.5", 150000050},
+		{"1500000.50", 150000050},
+		{"0.1", 10},
+		{".75", 75},
+		{"-12.34", -1234},
+		{"+8", 800},
+		{"10.005", 1001},
+		{"10.004", 1000},
+		{"-10.005", -1001},
+	}
+	for _, tt := range tests {
+		money, err := ParseMoney(tt.input)
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.expected, money, tt.input)
+	}
+
+	for _, invalid := range []string{"", "-", "1.2.3", "1,5", "abc", "1e5"} {
+		_, err := ParseMoney(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	var payload struct {
+		Amount  Money  `json:"amount"`
+		Balance *Money `json:"balance"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": 1234567.89, "balance": "-0.5"}`), &payload))
+	assert.Equal(t, Money(123456789), payload.Amount)
+	require.NotNil(t, payload.Balance)
+	assert.Equal(t, Money(-50), *payload.Balance)
+
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 1234567.89, "balance": -0.5}`, string(data))
+
+	data, err = json.Marshal(Rupiah(150000))
+	require.NoError(t, err)
+	assert.Equal(t, "150000", string(data))
+
+	// Floats that cannot hold the amount exactly still arrive to the sen
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": 0.30000000000000004}`), &payload))
+	assert.Equal(t, Money(30), payload.Amount)
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": "sepuluh"}`), &payload))
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	price := NewMoney(12500.5)
+	assert.Equal(t, Money(1250050), price)
+	assert.Equal(t, Money(3750150), price.Mul(3))
+	assert.Equal(t, Money(416683), price.Div(3))
+	assert.Equal(t, Money(-416683), (-price).Div(3))
+	assert.Equal(t, Money(0), price.Div(0))
+	assert.Equal(t, 0.5, Rupiah(50).Ratio(Rupiah(100)))
+	assert.Equal(t, "12500.50", price.String())
+	assert.Equal(t, "-0.05", Money(-5).String())
+	assert.Equal(t, 12500.5, price.Float64())
+
+	// Summing sen is exact where summing float rupiah drifts
+	var total Money
+	var floatTotal float64
+	for i := 0; i < 10; i++ {
+		total += NewMoney(0.1)
+		floatTotal += 0.1
+	}
+	assert.Equal(t, Rupiah(1), total)
+	assert.NotEqual(t, 1.0, floatTotal)
+}
+
+func TestMoney_Scan(t *testing.T) {
+	var m Money
+	require.NoError(t, m.Scan(int64(150050)))
+	assert.Equal(t, Money(150050), m)
+	require.NoError(t, m.Scan(float64(150050)))
+	assert.Equal(t, Money(150050), m)
+	require.NoError(t, m.Scan([]byte("42")))
+	assert.Equal(t, Money(42), m)
+	require.NoError(t, m.Scan(nil))
+	assert.Equal(t, Money(0), m)
+	assert.Error(t, m.Scan(true))
+
+	value, err := Money(150050).Value()
+	require.NoError(t, err)
+	assert.Equal(t, int64(150050), value)
+}
@@ -82,7 +82,7 @@ type MenuItem struct {
 	Portions          int                         `gorm:"not null" json:"portions" validate:"required,gt=0"`
 	MenuPlan          MenuPlan                    `gorm:"foreignKey:MenuPlanID" json:"menu_plan,omitempty"`
 	Recipe            Recipe                      `gorm:"foreignKey:RecipeID" json:"recipe,omitempty"`
-	SchoolAllocations []MenuItemSchoolAllocation  `gorm:"foreignKey:MenuItemID" json:"school_allocations,omitempty"`
+	SchoolAllocations []MenuItemSchoolAllocation  `gorm:"foreignKey:MenuItemID;constraint:OnDelete:CASCADE" json:"school_allocations,omitempty"`
 }
 
 // MenuItemSchoolAllocation represents portions of a menu item allocated to a specific school
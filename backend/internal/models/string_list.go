@@ -0,0 +1,51 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// StringList is a list of strings stored in a single text column as a JSON
+// array, so it works the same on PostgreSQL and SQLite
+type StringList []string
+
+// GormDataType stores the list in a text column
+func (StringList) GormDataType() string {
+	return "text"
+}
+
+// Value stores the list as a JSON array; a nil list is stored as NULL
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads a JSON array of strings
+func (l *StringList) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("tipe daftar teks tidak didukung: %T", src)
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("daftar teks tidak valid: %w", err)
+	}
+	*l = list
+	return nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringList_ValueScan(t *testing.T) {
+	value, err := StringList{"192.168.1.10", "192.168.1.11"}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `["192.168.1.10","192.168.1.11"]`, value)
+
+	var list StringList
+	require.NoError(t, list.Scan([]byte(`["192.168.1.10","192.168.1.11"]`)))
+	assert.Equal(t, StringList{"192.168.1.10", "192.168.1.11"}, list)
+	require.NoError(t, list.Scan(`[]`))
+	assert.Empty(t, list)
+	require.NoError(t, list.Scan(nil))
+	assert.Nil(t, list)
+	assert.Error(t, list.Scan(`{192.168.1.10}`))
+	assert.Error(t, list.Scan(42))
+
+	value, err = StringList(nil).Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
@@ -16,6 +16,7 @@ type Supplier struct {
 	IsActive        bool      `gorm:"default:true;index" json:"is_active"`
 	OnTimeDelivery  float64   `gorm:"default:0" json:"on_time_delivery"` // percentage
 	QualityRating   float64   `gorm:"default:0" json:"quality_rating"`   // 1-5 scale
+	PaymentTermDays int       `gorm:"default:30" json:"payment_term_days"` // invoice due date = invoice date + term
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 }
@@ -28,7 +29,7 @@ type PurchaseOrder struct {
 	OrderDate        time.Time           `gorm:"index;not null" json:"order_date"`
 	ExpectedDelivery time.Time           `gorm:"index" json:"expected_delivery"`
 	Status           string              `gorm:"size:20;not null;index" json:"status" validate:"required,oneof=pending approved received cancelled"` // pending, approved, received, cancelled
-	TotalAmount      float64             `gorm:"not null" json:"total_amount"`
+	TotalAmount      Money               `gorm:"not null" json:"total_amount"`
 	ApprovedBy       *uint               `gorm:"index" json:"approved_by"`
 	ApprovedAt       *time.Time          `json:"approved_at"`
 	CreatedBy        uint                `gorm:"not null;index" json:"created_by"`
@@ -46,8 +47,8 @@ type PurchaseOrderItem struct {
 	POID         uint       `gorm:"index;not null" json:"po_id"`
 	IngredientID uint       `gorm:"index;not null" json:"ingredient_id"`
 	Quantity     float64    `gorm:"not null" json:"quantity" validate:"required,gt=0"`
-	UnitPrice    float64    `gorm:"not null" json:"unit_price" validate:"required,gte=0"`
-	Subtotal     float64    `gorm:"not null" json:"subtotal"`
+	UnitPrice    Money      `gorm:"not null" json:"unit_price" validate:"required,gte=0"`
+	Subtotal     Money      `gorm:"not null" json:"subtotal"`
 	PO           PurchaseOrder `gorm:"foreignKey:POID" json:"po,omitempty"`
 	Ingredient   Ingredient `gorm:"foreignKey:IngredientID" json:"ingredient,omitempty"`
 }
@@ -119,7 +120,7 @@ type StokOpnameForm struct {
 	UpdatedAt       time.Time         `json:"updated_at"`
 	Creator         User              `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
 	Approver        *User             `gorm:"foreignKey:ApprovedBy" json:"approver,omitempty"`
-	Items           []StokOpnameItem  `gorm:"foreignKey:FormID" json:"items,omitempty"`
+	Items           []StokOpnameItem  `gorm:"foreignKey:FormID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
 }
 
 // StokOpnameItem represents a line item in a stok opname form
@@ -28,3 +28,116 @@ type Notification struct {
 	CreatedAt time.Time `gorm:"index" json:"created_at"`
 	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
+
+// Outbox event statuses
+const (
+	OutboxStatusPending    = "pending"
+	OutboxStatusProcessing = "processing"
+	OutboxStatusDelivered  = "delivered"
+	OutboxStatusDead       = "dead"
+)
+
+// OutboxEvent is a side effect recorded in the same transaction as the change
+// that caused it, such as a realtime sync of a delivery record. The outbox
+// dispatcher delivers it, retrying with backoff until it succeeds or is moved
+// to the dead letter state.
+type OutboxEvent struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	EventType     string     `gorm:"size:100;not null;index" json:"event_type"`
+	AggregateType string     `gorm:"size:50;index:idx_outbox_events_aggregate,priority:1" json:"aggregate_type"`
+	AggregateID   uint       `gorm:"index:idx_outbox_events_aggregate,priority:2" json:"aggregate_id"`
+	Payload       string     `gorm:"type:text" json:"payload"`
+	Status        string     `gorm:"size:20;not null;default:'pending';index:idx_outbox_events_due,priority:1" json:"status"` // pending, processing, delivered, dead
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts   int        `gorm:"not null;default:8" json:"max_attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_events_due,priority:2" json:"next_attempt_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Offline sync operation statuses
+const (
+	SyncStatusPending  = "pending"
+	SyncStatusApplied  = "applied"
+	SyncStatusConflict = "conflict"
+	SyncStatusRejected = "rejected"
+)
+
+// SyncOperation records an operation queued by an offline client and sent
+// through the batch sync, keyed by the idempotency key the client generated.
+// A resent operation returns the stored result instead of being applied again.
+type SyncOperation struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	UserID          uint      `gorm:"not null;uniqueIndex:idx_sync_operations_user_key,priority:1" json:"user_id"`
+	IdempotencyKey  string    `gorm:"size:100;not null;uniqueIndex:idx_sync_operations_user_key,priority:2" json:"idempotency_key"`
+	Type            string    `gorm:"size:50;not null;index" json:"type"`
+	Status          string    `gorm:"size:20;not null;default:'pending';index" json:"status"` // pending, applied, conflict, rejected
+	ClientTimestamp time.Time `gorm:"not null" json:"client_timestamp"`
+	Result          string    `gorm:"type:text" json:"result"`
+	CreatedAt       time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	User            User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// IdempotencyKey stores the first response to a mutating request sent with an
+// Idempotency-Key header, so a retried request gets the same response instead
+// of being applied again. It is the fallback store when Redis is not available.
+type IdempotencyKey struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_idempotency_keys_user_key,priority:1" json:"user_id"`
+	Key         string    `gorm:"size:255;not null;uniqueIndex:idx_idempotency_keys_user_key,priority:2" json:"key"`
+	Fingerprint string    `gorm:"size:64;not null" json:"fingerprint"` // SHA-256 of method, path and body
+	Completed   bool      `gorm:"not null;default:false" json:"completed"`
+	StatusCode  int       `json:"status_code"`
+	ContentType string    `gorm:"size:100" json:"content_type"`
+	Body        []byte    `json:"-"`
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookSubscription sends domain events to a partner system, such as the
+// foundation or the regional program office. Each request is signed with an
+// HMAC-SHA256 of the body using the subscription secret.
+type WebhookSubscription struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Name       string    `gorm:"size:100;not null" json:"name" validate:"required"`
+	TargetURL  string    `gorm:"size:500;not null" json:"target_url" validate:"required,url"`
+	EventTypes string    `gorm:"type:text;not null" json:"event_types"` // comma-separated event names, e.g. "delivery.completed,purchase_order.approved"
+	Secret     string    `gorm:"size:128;not null" json:"-"`
+	IsActive   bool      `gorm:"not null;default:true;index" json:"is_active"`
+	CreatedBy  uint      `gorm:"not null" json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryRetrying  = "retrying"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event sent to one subscription. It is the delivery
+// log: the last response or error is kept with the number of attempts. The
+// HTTP requests are made by the outbox dispatcher, which retries with backoff.
+type WebhookDelivery struct {
+	ID             uint                `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint                `gorm:"not null;index:idx_webhook_deliveries_subscription,priority:1" json:"subscription_id"`
+	EventType      string              `gorm:"size:100;not null;index" json:"event_type"`
+	Payload        string              `gorm:"type:text;not null" json:"payload"`
+	Status         string              `gorm:"size:20;not null;default:'pending';index" json:"status"` // pending, delivered, retrying, failed
+	Attempts       int                 `gorm:"not null;default:0" json:"attempts"`
+	ResponseStatus int                 `json:"response_status,omitempty"`
+	ResponseBody   string              `gorm:"type:text" json:"response_body,omitempty"`
+	LastError      string              `gorm:"type:text" json:"last_error,omitempty"`
+	LastAttemptAt  *time.Time          `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time          `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time           `gorm:"index:idx_webhook_deliveries_subscription,priority:2" json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+	Subscription   WebhookSubscription `gorm:"foreignKey:SubscriptionID" json:"-"`
+}
@@ -12,10 +12,87 @@ type User struct {
 	PasswordHash string    `gorm:"size:255;not null" json:"-"`
 	FullName     string    `gorm:"size:100;not null" json:"full_name" validate:"required"`
 	PhoneNumber  string    `gorm:"size:20" json:"phone_number"`
-	Role         string    `gorm:"size:50;not null;index" json:"role" validate:"required,oneof=kepala_sppg kepala_yayasan akuntan ahli_gizi pengadaan chef packing driver asisten_lapangan kebersihan"`
+	Role         string    `gorm:"size:50;not null;index" json:"role" validate:"required"` // name of a Role
 	IsActive     bool      `gorm:"default:true;index" json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	// Password policy and lockout state
+	MustChangePassword  bool       `gorm:"default:false" json:"must_change_password"`
+	PasswordChangedAt   *time.Time `json:"password_changed_at"`
+	FailedLoginAttempts int        `gorm:"default:0" json:"-"`
+	LockedUntil         *time.Time `json:"locked_until"`
+	// TOTP two-factor authentication; the secret is set during enrollment and
+	// only used for login once TwoFactorEnabled is true
+	TwoFactorEnabled    bool       `gorm:"default:false" json:"two_factor_enabled"`
+	TwoFactorSecret     string     `gorm:"size:64" json:"-"`
+	TwoFactorLastStep   int64      `gorm:"default:0" json:"-"`
+	TwoFactorEnabledAt  *time.Time `json:"two_factor_enabled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// IsLocked reports whether the account is locked at the given time
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// PasswordHistory keeps previous password hashes to prevent reuse
+type PasswordHistory struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"index;not null" json:"user_id"`
+	PasswordHash string    `gorm:"size:255;not null" json:"-"`
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+}
+
+// TwoFactorRecoveryCode is a single-use code that replaces a TOTP code when
+// the authenticator device is lost. Only the SHA-256 hash is stored.
+type TwoFactorRecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null;index" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// UserRoles lists the built-in system roles that are seeded into the roles table
+var UserRoles = []string{
+	"kepala_sppg",
+	"kepala_yayasan",
+	"akuntan",
+	"ahli_gizi",
+	"pengadaan",
+	"chef",
+	"packing",
+	"driver",
+	"asisten_lapangan",
+	"kebersihan",
+}
+
+// UserSession is a server-side login session bound to one device.
+// Access tokens carry the SessionID so revoking the session invalidates them.
+type UserSession struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	SessionID      string     `gorm:"uniqueIndex;size:64;not null" json:"session_id"`
+	UserID         uint       `gorm:"index;not null" json:"user_id"`
+	UserAgent      string     `gorm:"size:255" json:"user_agent"`
+	DeviceName     string     `gorm:"size:100" json:"device_name"`
+	IPAddress      string     `gorm:"size:45" json:"ip_address"`
+	LastActivityAt time.Time  `gorm:"not null" json:"last_activity_at"`
+	ExpiresAt      time.Time  `gorm:"index;not null" json:"expires_at"`
+	RevokedAt      *time.Time `gorm:"index" json:"revoked_at"`
+	RevokedReason  string     `gorm:"size:50" json:"revoked_reason,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	User           User       `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// RefreshToken is an opaque, single-use refresh token belonging to a session.
+// Only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID            uint        `gorm:"primaryKey" json:"id"`
+	UserSessionID uint        `gorm:"index;not null" json:"user_session_id"`
+	TokenHash     string      `gorm:"uniqueIndex;size:64;not null" json:"-"`
+	ExpiresAt     time.Time   `gorm:"not null" json:"expires_at"`
+	UsedAt        *time.Time  `json:"used_at"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UserSession   UserSession `gorm:"foreignKey:UserSessionID" json:"-"`
 }
 
 // AuditTrail records all user actions for accountability
@@ -31,3 +108,50 @@ type AuditTrail struct {
 	IPAddress string    `gorm:"size:45" json:"ip_address"`
 	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
+
+// Role is an assignable user role. System roles are seeded from UserRoles and
+// cannot be deleted; additional roles can be created by administrators.
+type Role struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"uniqueIndex;size:50;not null" json:"name" validate:"required"`
+	DisplayName string    `gorm:"size:100;not null" json:"display_name" validate:"required"`
+	Description string    `gorm:"type:text" json:"description"`
+	IsSystem    bool      `gorm:"default:false" json:"is_system"`
+	IsActive    bool      `gorm:"default:true;index" json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Permission is a named capability checked by the API, either a feature
+// (route group or action) or a delivery status a user may set
+type Permission struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Code        string    `gorm:"uniqueIndex;size:100;not null" json:"code"`
+	Category    string    `gorm:"size:20;not null;index" json:"category"` // feature, status
+	Description string    `gorm:"size:255" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RolePermission grants a permission to every user with the role
+type RolePermission struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	RoleID       uint       `gorm:"uniqueIndex:idx_role_permission;not null" json:"role_id"`
+	PermissionID uint       `gorm:"uniqueIndex:idx_role_permission;not null" json:"permission_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	Role         Role       `gorm:"foreignKey:RoleID" json:"-"`
+	Permission   Permission `gorm:"foreignKey:PermissionID" json:"permission,omitempty"`
+}
+
+// UserPermissionOverride grants (Granted=true) or revokes (Granted=false) a
+// single permission for one user regardless of the role
+type UserPermissionOverride struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"uniqueIndex:idx_user_permission_override;not null" json:"user_id"`
+	PermissionID uint       `gorm:"uniqueIndex:idx_user_permission_override;not null" json:"permission_id"`
+	Granted      bool       `gorm:"not null" json:"granted"`
+	Reason       string     `gorm:"size:255" json:"reason"`
+	CreatedBy    uint       `gorm:"not null" json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Permission   Permission `gorm:"foreignKey:PermissionID" json:"permission,omitempty"`
+}
@@ -0,0 +1,64 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/db"
+)
+
+// FirebasePublisher publishes to the Firebase Realtime Database
+type FirebasePublisher struct {
+	client *db.Client
+}
+
+// NewFirebasePublisher creates a publisher on the database of a Firebase app
+func NewFirebasePublisher(ctx context.Context, app *firebase.App) (*FirebasePublisher, error) {
+	client, err := app.Database(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menginisialisasi Firebase Database client: %w", err)
+	}
+	return &FirebasePublisher{client: client}, nil
+}
+
+// Set replaces the value at path
+func (p *FirebasePublisher) Set(ctx context.Context, path string, value interface{}) error {
+	if err := p.client.NewRef(path).Set(ctx, value); err != nil {
+		return fmt.Errorf("gagal mengirim data ke Firebase path %s: %w", path, err)
+	}
+	return nil
+}
+
+// Update merges the given children into the value at path
+func (p *FirebasePublisher) Update(ctx context.Context, path string, values map[string]interface{}) error {
+	if err := p.client.NewRef(path).Update(ctx, values); err != nil {
+		return fmt.Errorf("gagal mengupdate Firebase path %s: %w", path, err)
+	}
+	return nil
+}
+
+// Push adds value under a new child key generated by Firebase
+func (p *FirebasePublisher) Push(ctx context.Context, path string, value interface{}) (string, error) {
+	ref, err := p.client.NewRef(path).Push(ctx, value)
+	if err != nil {
+		return "", fmt.Errorf("gagal menambahkan data ke Firebase path %s: %w", path, err)
+	}
+	return ref.Key, nil
+}
+
+// Delete removes the value at path
+func (p *FirebasePublisher) Delete(ctx context.Context, path string) error {
+	if err := p.client.NewRef(path).Delete(ctx); err != nil {
+		return fmt.Errorf("gagal menghapus Firebase path %s: %w", path, err)
+	}
+	return nil
+}
+
+// Get reads the value at path into dest
+func (p *FirebasePublisher) Get(ctx context.Context, path string, dest interface{}) error {
+	if err := p.client.NewRef(path).Get(ctx, dest); err != nil {
+		return fmt.Errorf("gagal mengambil data dari Firebase path %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types sent to subscribers
+const (
+	EventSnapshot = "snapshot"
+	EventSet      = "set"
+	EventUpdate   = "update"
+	EventDelete   = "delete"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before the
+// hub drops it; clients reconnect and start again from a fresh snapshot
+const subscriberBuffer = 64
+
+// Event is a change delivered to the subscribers of a topic. Path is the node
+// that changed; a snapshot carries the whole value of the topic.
+type Event struct {
+	Type      string          `json:"type"`
+	Topic     string          `json:"topic"`
+	Path      string          `json:"path"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// Subscription receives the events of one topic until it is closed
+type Subscription struct {
+	Topic  string
+	Events <-chan Event
+
+	hub    *Hub
+	id     uint64
+	events chan Event
+}
+
+// Close stops the subscription and releases its channel
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s.id)
+}
+
+// Hub is a self-hosted RealtimePublisher. It keeps the published tree in
+// memory and fans changes out to subscribers by topic, a path prefix such as
+// kds/cooking/2026-03-02 or notifications/7. Clients connect through the
+// WebSocket or SSE endpoints of the realtime handler.
+type Hub struct {
+	mu          sync.Mutex
+	root        map[string]interface{}
+	subscribers map[uint64]*Subscription
+	nextID      uint64
+	pushSeq     uint32
+}
+
+// NewHub creates an empty hub
+func NewHub() *Hub {
+	return &Hub{
+		root:        make(map[string]interface{}),
+		subscribers: make(map[uint64]*Subscription),
+	}
+}
+
+// Subscribe starts a subscription on a topic. The first event is a snapshot
+// of the current value of the topic.
+func (h *Hub) Subscribe(topic string) *Subscription {
+	topic = NormalizePath(topic)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	events := make(chan Event, subscriberBuffer)
+	sub := &Subscription{Topic: topic, Events: events, hub: h, id: h.nextID, events: events}
+	h.subscribers[sub.id] = sub
+	sub.events <- h.snapshotLocked(topic)
+	return sub
+}
+
+// SubscriberCount returns the number of open subscriptions
+func (h *Hub) SubscriberCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+func (h *Hub) unsubscribe(id uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if sub, ok := h.subscribers[id]; ok {
+		delete(h.subscribers, id)
+		close(sub.events)
+	}
+}
+
+// Set replaces the value at path
+func (h *Hub) Set(ctx context.Context, path string, value interface{}) error {
+	normalized, err := normalizeValue(value)
+	if err != nil {
+		return fmt.Errorf("gagal mengkonversi data untuk path %s: %w", path, err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	segments := splitPath(path)
+	if normalized == nil {
+		h.deleteLocked(segments)
+		h.publishLocked(EventDelete, segments, nil)
+		return nil
+	}
+	if err := h.setLocked(segments, normalized); err != nil {
+		return err
+	}
+	h.publishLocked(EventSet, segments, normalized)
+	return nil
+}
+
+// Update merges the given children into the value at path
+func (h *Hub) Update(ctx context.Context, path string, values map[string]interface{}) error {
+	normalized, err := normalizeValue(values)
+	if err != nil {
+		return fmt.Errorf("gagal mengkonversi data untuk path %s: %w", path, err)
+	}
+	children, _ := normalized.(map[string]interface{})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	segments := splitPath(path)
+	for key, child := range children {
+		childSegments := append(append([]string{}, segments...), splitPath(key)...)
+		if child == nil {
+			h.deleteLocked(childSegments)
+		} else if err := h.setLocked(childSegments, child); err != nil {
+			return err
+		}
+	}
+	h.publishLocked(EventUpdate, segments, children)
+	return nil
+}
+
+// Push adds value under a new time ordered child key of path
+func (h *Hub) Push(ctx context.Context, path string, value interface{}) (string, error) {
+	h.mu.Lock()
+	h.pushSeq++
+	key := fmt.Sprintf("%016x%04x", time.Now().UnixNano(), h.pushSeq&0xffff)
+	h.mu.Unlock()
+
+	if err := h.Set(ctx, NormalizePath(path)+"/"+key, value); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Delete removes the value at path
+func (h *Hub) Delete(ctx context.Context, path string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	segments := splitPath(path)
+	h.deleteLocked(segments)
+	h.publishLocked(EventDelete, segments, nil)
+	return nil
+}
+
+// Get reads the current value at path into dest
+func (h *Hub) Get(ctx context.Context, path string, dest interface{}) error {
+	h.mu.Lock()
+	value := h.getLocked(splitPath(path))
+	if value == nil {
+		h.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(value)
+	h.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("gagal membaca data path %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("gagal membaca data path %s: %w", path, err)
+	}
+	return nil
+}
+
+func (h *Hub) getLocked(segments []string) interface{} {
+	var node interface{} = h.root
+	for _, segment := range segments {
+		children, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = children[segment]
+	}
+	return node
+}
+
+func (h *Hub) setLocked(segments []string, value interface{}) error {
+	if len(segments) == 0 {
+		children, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("data root harus berupa objek")
+		}
+		h.root = children
+		return nil
+	}
+
+	node := h.root
+	for _, segment := range segments[:len(segments)-1] {
+		child, ok := node[segment].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			node[segment] = child
+		}
+		node = child
+	}
+	node[segments[len(segments)-1]] = value
+	return nil
+}
+
+// deleteLocked removes a node and prunes parents that became empty, like
+// Firebase does
+func (h *Hub) deleteLocked(segments []string) {
+	if len(segments) == 0 {
+		h.root = make(map[string]interface{})
+		return
+	}
+
+	parents := []map[string]interface{}{h.root}
+	node := h.root
+	for _, segment := range segments[:len(segments)-1] {
+		child, ok := node[segment].(map[string]interface{})
+		if !ok {
+			return
+		}
+		parents = append(parents, child)
+		node = child
+	}
+	delete(node, segments[len(segments)-1])
+
+	for i := len(parents) - 1; i > 0; i-- {
+		if len(parents[i]) > 0 {
+			break
+		}
+		delete(parents[i-1], segments[i-1])
+	}
+}
+
+// publishLocked notifies the subscribers a change at segments concerns.
+// Subscribers below the changed node get a fresh snapshot of their topic,
+// subscribers above it get the change itself.
+func (h *Hub) publishLocked(eventType string, segments []string, data interface{}) {
+	path := strings.Join(segments, "/")
+	var payload json.RawMessage
+	if data != nil {
+		payload, _ = json.Marshal(data)
+	}
+	now := time.Now().Unix()
+
+	for id, sub := range h.subscribers {
+		var event Event
+		switch {
+		case IsWithin(path, sub.Topic):
+			event = Event{Type: eventType, Topic: sub.Topic, Path: path, Data: payload, Timestamp: now}
+		case IsWithin(sub.Topic, path):
+			event = h.snapshotLocked(sub.Topic)
+		default:
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			// Too slow to keep up; the client resubscribes from a snapshot
+			delete(h.subscribers, id)
+			close(sub.events)
+		}
+	}
+}
+
+func (h *Hub) snapshotLocked(topic string) Event {
+	event := Event{Type: EventSnapshot, Topic: topic, Path: topic, Timestamp: time.Now().Unix()}
+	if value := h.getLocked(splitPath(topic)); value != nil {
+		event.Data, _ = json.Marshal(value)
+	}
+	return event
+}
+
+// normalizeValue converts a value to its JSON form (maps, slices, numbers,
+// strings and booleans) so the stored tree never aliases caller data
+func normalizeValue(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var normalized interface{}
+	if err := decoder.Decode(&normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
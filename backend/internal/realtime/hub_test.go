@@ -0,0 +1,211 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nextEvent(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event, ok := <-sub.Events:
+		require.True(t, ok, "subscription closed")
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func assertNoEvent(t *testing.T, sub *Subscription) {
+	t.Helper()
+	select {
+	case event := <-sub.Events:
+		t.Fatalf("unexpected event %+v", event)
+	default:
+	}
+}
+
+func TestHub_SetGetUpdateDelete(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub()
+
+	require.NoError(t, hub.Set(ctx, "/kds/cooking/2026-03-02/12", map[string]interface{}{
+		"recipe_id": 12,
+		"status":    "pending",
+	}))
+
+	var item struct {
+		RecipeID int    `json:"recipe_id"`
+		Status   string `json:"status"`
+	}
+	require.NoError(t, hub.Get(ctx, "kds/cooking/2026-03-02/12", &item))
+	assert.Equal(t, 12, item.RecipeID)
+	assert.Equal(t, "pending", item.Status)
+
+	require.NoError(t, hub.Update(ctx, "kds/cooking/2026-03-02/12", map[string]interface{}{"status": "cooking"}))
+	require.NoError(t, hub.Get(ctx, "kds/cooking/2026-03-02/12", &item))
+	assert.Equal(t, 12, item.RecipeID, "update keeps the other children")
+	assert.Equal(t, "cooking", item.Status)
+
+	// Deleting the only item prunes the empty parents
+	require.NoError(t, hub.Delete(ctx, "kds/cooking/2026-03-02/12"))
+	var kds map[string]interface{}
+	require.NoError(t, hub.Get(ctx, "kds", &kds))
+	assert.Nil(t, kds)
+
+	// Get leaves dest untouched when nothing is stored
+	missing := map[string]interface{}{"keep": true}
+	require.NoError(t, hub.Get(ctx, "does/not/exist", &missing))
+	assert.Equal(t, true, missing["keep"])
+}
+
+func TestHub_PushGeneratesOrderedKeys(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub()
+
+	first, err := hub.Push(ctx, "notifications/7", map[string]interface{}{"title": "a"})
+	require.NoError(t, err)
+	second, err := hub.Push(ctx, "notifications/7", map[string]interface{}{"title": "b"})
+	require.NoError(t, err)
+	assert.Less(t, first, second)
+
+	var notifications map[string]map[string]string
+	require.NoError(t, hub.Get(ctx, "notifications/7", &notifications))
+	assert.Len(t, notifications, 2)
+	assert.Equal(t, "a", notifications[first]["title"])
+	assert.Equal(t, "b", notifications[second]["title"])
+}
+
+func TestHub_SubscribeReceivesSnapshotThenChangesOfTopic(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub()
+	require.NoError(t, hub.Set(ctx, "kds/cooking/2026-03-02/1", map[string]interface{}{"status": "pending"}))
+
+	sub := hub.Subscribe("kds/cooking/2026-03-02")
+	defer sub.Close()
+
+	snapshot := nextEvent(t, sub)
+	assert.Equal(t, EventSnapshot, snapshot.Type)
+	assert.JSONEq(t, `{"1":{"status":"pending"}}`, string(snapshot.Data))
+
+	require.NoError(t, hub.Set(ctx, "kds/cooking/2026-03-02/2", map[string]interface{}{"status": "pending"}))
+	event := nextEvent(t, sub)
+	assert.Equal(t, EventSet, event.Type)
+	assert.Equal(t, "kds/cooking/2026-03-02", event.Topic)
+	assert.Equal(t, "kds/cooking/2026-03-02/2", event.Path)
+	assert.JSONEq(t, `{"status":"pending"}`, string(event.Data))
+
+	// Other days and other users' topics are not delivered
+	require.NoError(t, hub.Set(ctx, "kds/cooking/2026-03-03/1", map[string]interface{}{"status": "pending"}))
+	require.NoError(t, hub.Set(ctx, "notifications/7/x", map[string]interface{}{"title": "a"}))
+	assertNoEvent(t, sub)
+
+	require.NoError(t, hub.Delete(ctx, "kds/cooking/2026-03-02/1"))
+	event = nextEvent(t, sub)
+	assert.Equal(t, EventDelete, event.Type)
+	assert.Equal(t, "kds/cooking/2026-03-02/1", event.Path)
+}
+
+func TestHub_ChangeAboveTopicSendsSnapshot(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub()
+
+	sub := hub.Subscribe("kds/cooking/2026-03-02")
+	defer sub.Close()
+	nextEvent(t, sub)
+
+	// Replacing the whole cooking tree, as the daily sync does
+	require.NoError(t, hub.Set(ctx, "kds/cooking", map[string]interface{}{
+		"2026-03-02": map[string]interface{}{"5": map[string]interface{}{"status": "ready"}},
+	}))
+	event := nextEvent(t, sub)
+	assert.Equal(t, EventSnapshot, event.Type)
+	assert.JSONEq(t, `{"5":{"status":"ready"}}`, string(event.Data))
+
+	require.NoError(t, hub.Delete(ctx, "kds"))
+	event = nextEvent(t, sub)
+	assert.Equal(t, EventSnapshot, event.Type)
+	assert.Empty(t, event.Data)
+}
+
+func TestHub_StoredValueDoesNotAliasCallerData(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub()
+
+	data := map[string]interface{}{"status": "pending"}
+	require.NoError(t, hub.Set(ctx, "cleaning/pending/1", data))
+	data["status"] = "changed"
+
+	var stored map[string]string
+	require.NoError(t, hub.Get(ctx, "cleaning/pending/1", &stored))
+	assert.Equal(t, "pending", stored["status"])
+}
+
+func TestHub_SlowSubscriberIsDropped(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub()
+
+	slow := hub.Subscribe("dashboard")
+	fast := hub.Subscribe("dashboard")
+	assert.Equal(t, 2, hub.SubscriberCount())
+
+	received := 0
+	for i := 0; i < subscriberBuffer+1; i++ {
+		require.NoError(t, hub.Set(ctx, "dashboard/counter", i))
+		// The fast subscriber keeps up, the slow one never reads
+		for {
+			event := nextEvent(t, fast)
+			received++
+			if event.Type == EventSet {
+				break
+			}
+		}
+	}
+	assert.Equal(t, subscriberBuffer+2, received, "snapshot plus every change")
+	assert.Equal(t, 1, hub.SubscriberCount())
+
+	// The slow subscriber's channel is drained and then closed
+	count := 0
+	for range slow.Events {
+		count++
+	}
+	assert.Equal(t, subscriberBuffer, count)
+
+	// Closing a dropped subscription is harmless
+	slow.Close()
+	fast.Close()
+	assert.Equal(t, 0, hub.SubscriberCount())
+}
+
+func TestHub_EventJSON(t *testing.T) {
+	hub := NewHub()
+	require.NoError(t, hub.Set(context.Background(), "monitoring/deliveries/2026-03-02/3", map[string]interface{}{"portions": 150}))
+
+	sub := hub.Subscribe(MonitoringTopic("2026-03-02"))
+	defer sub.Close()
+
+	data, err := json.Marshal(nextEvent(t, sub))
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "snapshot", decoded["type"])
+	assert.Equal(t, "monitoring/deliveries/2026-03-02", decoded["topic"])
+	assert.Equal(t, map[string]interface{}{"3": map[string]interface{}{"portions": float64(150)}}, decoded["data"])
+}
+
+func TestIsWithin(t *testing.T) {
+	assert.True(t, IsWithin("kds/cooking/2026-03-02/1", "kds/cooking"))
+	assert.True(t, IsWithin("/kds/cooking/", "kds/cooking"))
+	assert.True(t, IsWithin("anything", ""))
+	assert.False(t, IsWithin("kds/cookingx", "kds/cooking"))
+	assert.False(t, IsWithin("kds", "kds/cooking"))
+	assert.Equal(t, "notifications/7", NotificationsTopic(7))
+	assert.Equal(t, "kds/cooking/2026-03-02", NormalizePath("//kds//cooking/2026-03-02/"))
+}
@@ -0,0 +1,79 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RealtimePublisher pushes live data to connected clients. Data is organised
+// in a tree of slash separated paths (e.g. /kds/cooking/2026-03-02/12), the
+// same layout the Firebase Realtime Database uses, so every backend can serve
+// the existing paths unchanged.
+type RealtimePublisher interface {
+	// Set replaces the value at path
+	Set(ctx context.Context, path string, value interface{}) error
+	// Update merges the given children into the value at path
+	Update(ctx context.Context, path string, values map[string]interface{}) error
+	// Push adds value under a new generated child key of path and returns the key
+	Push(ctx context.Context, path string, value interface{}) (string, error)
+	// Delete removes the value at path and everything below it
+	Delete(ctx context.Context, path string) error
+	// Get reads the current value at path into dest; dest is left untouched
+	// when nothing is stored at path
+	Get(ctx context.Context, path string, dest interface{}) error
+}
+
+// Backend names accepted by REALTIME_BACKEND
+const (
+	BackendFirebase = "firebase"
+	BackendHub      = "hub"
+)
+
+// Topics clients commonly subscribe to
+const (
+	TopicKDSCooking    = "kds/cooking"
+	TopicKDSPacking    = "kds/packing"
+	TopicMonitoring    = "monitoring/deliveries"
+	TopicCleaning      = "cleaning/pending"
+	TopicDashboard     = "dashboard"
+	TopicNotifications = "notifications"
+)
+
+// CookingTopic returns the topic of the cooking board of a day (YYYY-MM-DD)
+func CookingTopic(date string) string {
+	return TopicKDSCooking + "/" + date
+}
+
+// MonitoringTopic returns the topic of the delivery monitoring of a day (YYYY-MM-DD)
+func MonitoringTopic(date string) string {
+	return TopicMonitoring + "/" + date
+}
+
+// NotificationsTopic returns the personal notification topic of a user
+func NotificationsTopic(userID uint) string {
+	return fmt.Sprintf("%s/%d", TopicNotifications, userID)
+}
+
+// NormalizePath trims surrounding slashes and collapses empty segments, so
+// "/kds//cooking/" and "kds/cooking" name the same node
+func NormalizePath(path string) string {
+	return strings.Join(splitPath(path), "/")
+}
+
+func splitPath(path string) []string {
+	parts := strings.Split(path, "/")
+	segments := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			segments = append(segments, part)
+		}
+	}
+	return segments
+}
+
+// IsWithin reports whether path equals ancestor or lies below it
+func IsWithin(path, ancestor string) bool {
+	path, ancestor = NormalizePath(path), NormalizePath(ancestor)
+	return ancestor == "" || path == ancestor || strings.HasPrefix(path, ancestor+"/")
+}
@@ -2,18 +2,20 @@ package router
 
 import (
 	"log"
+	"time"
 
-	firebase "firebase.google.com/go/v4"
 	"github.com/erp-sppg/backend/internal/cache"
 	"github.com/erp-sppg/backend/internal/config"
 	"github.com/erp-sppg/backend/internal/handlers"
 	"github.com/erp-sppg/backend/internal/middleware"
+	"github.com/erp-sppg/backend/internal/realtime"
 	"github.com/erp-sppg/backend/internal/services"
+	"github.com/erp-sppg/backend/internal/storage"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheService *cache.CacheService) *gin.Engine {
+func Setup(db *gorm.DB, publisher realtime.RealtimePublisher, cfg *config.Config, cacheService *cache.CacheService, uploader *storage.Uploader) *gin.Engine {
 	r := gin.Default()
 
 	// Security middleware (applied to all routes)
@@ -33,9 +35,6 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 	// CORS middleware
 	r.Use(middleware.CORS(cfg.AllowedOrigins))
 
-	// Static file serving for uploads
-	r.Static("/uploads", "./uploads")
-
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -53,39 +52,141 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 		}
 
 		// Auth routes (public, with stricter rate limiting)
-		authHandler := handlers.NewAuthHandler(db, cfg.JWTSecret)
+		authService := services.NewAuthService(db, cfg.JWTSecret).ConfigureSessions(
+			time.Duration(cfg.AccessTokenTTLMinutes)*time.Minute,
+			time.Duration(cfg.RefreshTokenTTLDays)*24*time.Hour,
+			time.Duration(cfg.SessionTimeoutMinutes)*time.Minute,
+		)
+		authHandler := handlers.NewAuthHandler(db, authService)
 		auth := v1.Group("/auth")
 		if cfg.EnableRateLimit {
 			auth.Use(middleware.AuthRateLimitMiddleware())
 		}
 		{
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/login/2fa", authHandler.VerifyTwoFactorLogin)
 			auth.POST("/refresh", authHandler.RefreshToken)
 		}
 
+		// Roles and permissions are stored in the database; seed the built-in
+		// defaults so a fresh database behaves like the previous hardcoded rules
+		permissionService := services.NewPermissionService(db)
+		if err := permissionService.SeedDefaults(); err != nil {
+			log.Printf("Warning: Failed to seed default roles and permissions: %v", err)
+		}
+		// The ledger needs its system accounts; records created before the
+		// ledger existed are posted once so the books start complete
+		ledgerService := services.NewLedgerService(db)
+		if err := ledgerService.SeedDefaultAccounts(); err != nil {
+			log.Printf("Warning: Failed to seed chart of accounts: %v", err)
+		} else if posted, err := ledgerService.SyncExistingRecords(); err != nil {
+			log.Printf("Warning: Failed to post existing records to the ledger: %v", err)
+		} else if posted > 0 {
+			log.Printf("Posted %d existing records to the ledger", posted)
+		}
+
+		perm := middleware.NewPermissionChecker(permissionService)
+		statusAuthorizer := middleware.NewStatusCategoryAuthorizer(permissionService)
+
+		// Uploaded files are only served through short-lived signed URLs.
+		// /storage/open redirects to such a URL, so it accepts the access
+		// token as a query parameter for use in img src and links.
+		fileHandler := handlers.NewFileHandler(uploader)
+		v1.GET("/files/*key", fileHandler.ServeFile)
+		fileRoutes := v1.Group("/storage")
+		fileRoutes.Use(middleware.TokenFromQuery())
+		fileRoutes.Use(middleware.JWTAuth(cfg.JWTSecret))
+		fileRoutes.Use(middleware.PasswordChangeGuard())
+		fileRoutes.Use(middleware.TwoFactorSetupGuard())
+		fileRoutes.Use(middleware.SessionValidation(authService.Sessions()))
+		{
+			fileRoutes.GET("/sign", fileHandler.SignFile)
+			fileRoutes.GET("/open", fileHandler.OpenFile)
+		}
+
+		// Realtime streams of the built-in hub (only when Firebase is not used).
+		// Browsers cannot set headers on WebSocket/EventSource connections, so
+		// the access token may also come from the access_token query parameter.
+		if hub, ok := publisher.(*realtime.Hub); ok {
+			realtimeHandler := handlers.NewRealtimeHandler(hub, permissionService, cfg.AllowedOrigins)
+			realtimeRoutes := v1.Group("/realtime")
+			realtimeRoutes.Use(middleware.TokenFromQuery())
+			realtimeRoutes.Use(middleware.JWTAuth(cfg.JWTSecret))
+			realtimeRoutes.Use(middleware.PasswordChangeGuard())
+			realtimeRoutes.Use(middleware.TwoFactorSetupGuard())
+			realtimeRoutes.Use(middleware.SessionValidation(authService.Sessions()))
+			{
+				realtimeRoutes.GET("/ws", realtimeHandler.ServeWebSocket)
+				realtimeRoutes.GET("/sse", realtimeHandler.StreamEvents)
+			}
+		}
+
 		// Protected routes (require JWT authentication)
 		protected := v1.Group("")
 		protected.Use(middleware.JWTAuth(cfg.JWTSecret))
-		protected.Use(middleware.SessionTimeoutMiddleware(cfg.SessionTimeoutMinutes))
+		protected.Use(middleware.PasswordChangeGuard())
+		protected.Use(middleware.TwoFactorSetupGuard())
+		protected.Use(middleware.SessionValidation(authService.Sessions()))
 		protected.Use(middleware.AuditTrail(db))
 		if cfg.EnableCSRFProtection {
 			protected.Use(middleware.CSRFMiddleware())
 		}
-		// Apply cache invalidation middleware for data modifications
-		if cacheService != nil {
-			protected.Use(middleware.CacheInvalidationMiddleware(cacheService))
+		// Retried mutations with the same Idempotency-Key replay the first
+		// response; keys live in Redis when available, else in the database
+		var idempotencyStore middleware.IdempotencyStore
+		if cacheService != nil && cacheService.Redis() != nil {
+			idempotencyStore = middleware.NewRedisIdempotencyStore(cacheService.Redis())
+		} else {
+			idempotencyStore = middleware.NewDBIdempotencyStore(db)
+		}
+		idempotencyTTL := time.Duration(cfg.IdempotencyTTLHours) * time.Hour
+		if idempotencyTTL <= 0 {
+			idempotencyTTL = middleware.DefaultIdempotencyTTL
 		}
+		protected.Use(middleware.Idempotency(idempotencyStore, idempotencyTTL))
 		{
 			// Auth protected routes
 			protected.POST("/auth/logout", authHandler.Logout)
 			protected.GET("/auth/me", authHandler.GetMe)
+			protected.POST("/auth/change-password", authHandler.ChangePassword)
+			protected.GET("/auth/sessions", authHandler.GetSessions)
+			protected.DELETE("/auth/sessions/:id", authHandler.RevokeSession)
+			protected.GET("/auth/2fa", authHandler.GetTwoFactorStatus)
+			protected.POST("/auth/2fa/setup", authHandler.SetupTwoFactor)
+			protected.POST("/auth/2fa/confirm", authHandler.ConfirmTwoFactor)
+			protected.POST("/auth/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+			protected.POST("/auth/2fa/disable", authHandler.DisableTwoFactor)
+			// Effective permissions of the current user
+			permissionHandler := handlers.NewPermissionHandler(permissionService)
+			protected.GET("/auth/permissions", permissionHandler.GetMyPermissions)
+
+			// Role and permission management routes
+			roles := protected.Group("/roles")
+			roles.Use(perm.RequirePermission("role_management"))
+			{
+				roles.GET("", permissionHandler.GetRoles)
+				roles.POST("", permissionHandler.CreateRole)
+				roles.GET("/:id", permissionHandler.GetRole)
+				roles.PUT("/:id", permissionHandler.UpdateRole)
+				roles.DELETE("/:id", permissionHandler.DeleteRole)
+				roles.PUT("/:id/permissions", permissionHandler.SetRolePermissions)
+			}
+			permissions := protected.Group("/permissions")
+			permissions.Use(perm.RequirePermission("role_management"))
+			{
+				permissions.GET("", permissionHandler.GetPermissions)
+				permissions.GET("/users/:user_id/overrides", permissionHandler.GetUserOverrides)
+				permissions.PUT("/users/:user_id/overrides", permissionHandler.SetUserOverride)
+				permissions.DELETE("/users/:user_id/overrides/:code", permissionHandler.RemoveUserOverride)
+			}
 
 			// Recipe routes
-			recipeHandler := handlers.NewRecipeHandler(db)
+			recipeHandler := handlers.NewRecipeHandler(db, uploader)
 			recipes := protected.Group("/recipes")
+			recipes.Use(perm.RequireReadWrite("recipe_view", "recipe_management"))
 			// Apply caching for recipe GET requests
 			if cacheService != nil {
-				recipes.Use(middleware.ConditionalCacheMiddleware(cacheService, middleware.CacheForReadOnlyOperations, cache.LongCacheDuration))
+				recipes.Use(middleware.ConditionalCacheMiddleware(cacheService, middleware.CacheForReadOnlyOperations, cache.LongCacheDuration, cache.MenuTag))
 			}
 			{
 				recipes.GET("", recipeHandler.GetAllRecipes)
@@ -101,8 +202,9 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 
 			// Ingredient routes
 			ingredients := protected.Group("/ingredients")
+			ingredients.Use(perm.RequireReadWrite("recipe_view", "recipe_management"))
 			if cacheService != nil {
-				ingredients.Use(middleware.ConditionalCacheMiddleware(cacheService, middleware.CacheForReadOnlyOperations, cache.LongCacheDuration))
+				ingredients.Use(middleware.ConditionalCacheMiddleware(cacheService, middleware.CacheForReadOnlyOperations, cache.LongCacheDuration, cache.MenuTag))
 			}
 			{
 				ingredients.GET("", recipeHandler.GetAllIngredients)
@@ -113,6 +215,7 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 			// Semi-Finished Goods routes
 			semiFinishedHandler := handlers.NewSemiFinishedHandler(db)
 			semiFinished := protected.Group("/semi-finished")
+			semiFinished.Use(perm.RequireReadWrite("recipe_view", "semi_finished_production"))
 			{
 				semiFinished.GET("", semiFinishedHandler.GetAllSemiFinishedGoods)
 				semiFinished.POST("", semiFinishedHandler.CreateSemiFinishedGoods)
@@ -126,41 +229,43 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 			// Menu Planning routes
 			menuPlanningHandler := handlers.NewMenuPlanningHandler(db)
 			menuPlans := protected.Group("/menu-plans")
+			menuPlans.Use(perm.RequirePermission("menu_planning_view"))
 			{
 				menuPlans.GET("", menuPlanningHandler.GetAllMenuPlans)
-				menuPlans.POST("", menuPlanningHandler.CreateMenuPlan)
+				menuPlans.POST("", perm.RequirePermission("menu_planning"), menuPlanningHandler.CreateMenuPlan)
 				menuPlans.GET("/current-week", menuPlanningHandler.GetCurrentWeekMenuPlan)
 				menuPlans.GET("/:id", menuPlanningHandler.GetMenuPlan)
-				menuPlans.PUT("/:id", menuPlanningHandler.UpdateMenuPlan)
-				menuPlans.POST("/:id/approve", menuPlanningHandler.ApproveMenuPlan)
-				menuPlans.POST("/:id/duplicate", menuPlanningHandler.DuplicateMenuPlan)
+				menuPlans.PUT("/:id", perm.RequirePermission("menu_planning"), menuPlanningHandler.UpdateMenuPlan)
+				menuPlans.POST("/:id/approve", perm.RequirePermission("menu_planning_approve"), menuPlanningHandler.ApproveMenuPlan)
+				menuPlans.POST("/:id/duplicate", perm.RequirePermission("menu_planning"), menuPlanningHandler.DuplicateMenuPlan)
 				menuPlans.GET("/:id/daily-nutrition", menuPlanningHandler.GetDailyNutrition)
 				menuPlans.GET("/:id/ingredient-requirements", menuPlanningHandler.GetIngredientRequirements)
-				menuPlans.POST("/:id/items", menuPlanningHandler.CreateMenuItem)
+				menuPlans.POST("/:id/items", perm.RequirePermission("menu_planning"), menuPlanningHandler.CreateMenuItem)
 				menuPlans.GET("/:id/items/:item_id", menuPlanningHandler.GetMenuItem)
-				menuPlans.PUT("/:id/items/:item_id", menuPlanningHandler.UpdateMenuItem)
-				menuPlans.DELETE("/:id/items/:item_id", menuPlanningHandler.DeleteMenuItem)
-				menuPlans.POST("/generate-delivery-records", menuPlanningHandler.GenerateDeliveryRecords)
+				menuPlans.PUT("/:id/items/:item_id", perm.RequirePermission("menu_planning"), menuPlanningHandler.UpdateMenuItem)
+				menuPlans.DELETE("/:id/items/:item_id", perm.RequirePermission("menu_planning"), menuPlanningHandler.DeleteMenuItem)
+				menuPlans.POST("/generate-delivery-records", perm.RequirePermission("menu_planning"), menuPlanningHandler.GenerateDeliveryRecords)
 			}
 
 			// Monitoring routes (logistics monitoring process)
 			// Requirements: 1.1, 8.2, 8.3
-			monitoringService, err := services.NewMonitoringService(db, firebaseApp)
+			monitoringService, err := services.NewMonitoringService(db, publisher)
 			if err != nil {
 				panic("Failed to initialize Monitoring service: " + err.Error())
 			}
 
 			// KDS routes
-			kdsService, err := services.NewKDSService(db, firebaseApp, monitoringService)
+			kdsService, err := services.NewKDSService(db, publisher)
 			if err != nil {
 				panic("Failed to initialize KDS service: " + err.Error())
 			}
-			packingAllocationService, err := services.NewPackingAllocationService(db, firebaseApp, monitoringService)
+			packingAllocationService, err := services.NewPackingAllocationService(db, publisher)
 			if err != nil {
 				panic("Failed to initialize Packing Allocation service: " + err.Error())
 			}
 			kdsHandler := handlers.NewKDSHandler(kdsService, packingAllocationService)
 			kds := protected.Group("/kds")
+			kds.Use(perm.RequirePermission("kitchen_display"))
 			{
 				// Cooking routes
 				kds.GET("/cooking/today", kdsHandler.GetCookingToday)
@@ -173,14 +278,21 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 				kds.POST("/packing/sync", kdsHandler.SyncPackingToFirebase)
 			}
 
+			notificationService, err := services.NewNotificationService(db, publisher)
+			if err != nil {
+				panic("Failed to initialize Notification service: " + err.Error())
+			}
+			budgetService := services.NewBudgetService(db, notificationService)
+
 			// Supply Chain routes
-			supplyChainHandler := handlers.NewSupplyChainHandler(db)
+			supplyChainHandler := handlers.NewSupplyChainHandler(db, budgetService, uploader)
 			
 			// Supplier routes
 			suppliers := protected.Group("/suppliers")
+			suppliers.Use(perm.RequireReadWrite("procurement_view", "procurement"))
 			// Apply caching for supplier GET requests
 			if cacheService != nil {
-				suppliers.Use(middleware.ConditionalCacheMiddleware(cacheService, middleware.CacheForReadOnlyOperations, cache.LongCacheDuration))
+				suppliers.Use(middleware.ConditionalCacheMiddleware(cacheService, middleware.CacheForReadOnlyOperations, cache.LongCacheDuration, cache.SupplierTag))
 			}
 			{
 				suppliers.GET("", supplyChainHandler.GetAllSuppliers)
@@ -193,16 +305,18 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 
 			// Purchase Order routes
 			purchaseOrders := protected.Group("/purchase-orders")
+			purchaseOrders.Use(perm.RequirePermission("procurement_view"))
 			{
 				purchaseOrders.GET("", supplyChainHandler.GetAllPurchaseOrders)
-				purchaseOrders.POST("", supplyChainHandler.CreatePurchaseOrder)
+				purchaseOrders.POST("", perm.RequirePermission("procurement"), supplyChainHandler.CreatePurchaseOrder)
 				purchaseOrders.GET("/:id", supplyChainHandler.GetPurchaseOrder)
-				purchaseOrders.PUT("/:id", supplyChainHandler.UpdatePurchaseOrder)
-				purchaseOrders.POST("/:id/approve", supplyChainHandler.ApprovePurchaseOrder)
+				purchaseOrders.PUT("/:id", perm.RequirePermission("procurement"), supplyChainHandler.UpdatePurchaseOrder)
+				purchaseOrders.POST("/:id/approve", perm.RequirePermission("procurement_approve"), supplyChainHandler.ApprovePurchaseOrder)
 			}
 
 			// Goods Receipt routes
 			goodsReceipts := protected.Group("/goods-receipts")
+			goodsReceipts.Use(perm.RequireReadWrite("procurement_view", "procurement"))
 			{
 				goodsReceipts.GET("", supplyChainHandler.GetAllGoodsReceipts)
 				goodsReceipts.POST("", supplyChainHandler.CreateGoodsReceipt)
@@ -212,9 +326,10 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 
 			// Inventory routes
 			inventory := protected.Group("/inventory")
-			// Apply inventory caching middleware
+			inventory.Use(perm.RequireReadWrite("inventory_view", "inventory"))
+			// Apply caching for inventory GET requests; stock changes invalidate it
 			if cacheService != nil {
-				inventory.Use(middleware.InventoryCacheMiddleware(cacheService))
+				inventory.Use(middleware.CacheMiddleware(cacheService, cache.ShortCacheDuration, cache.InventoryTag))
 			}
 			{
 				inventory.GET("", supplyChainHandler.GetInventory)
@@ -227,13 +342,10 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 
 			// Stok Opname routes
 			// Requirements: 2.1, 6.1
-			notificationService, err := services.NewNotificationService(db, firebaseApp)
-			if err != nil {
-				panic("Failed to initialize Notification service: " + err.Error())
-			}
 			inventoryService := services.NewInventoryService(db)
 			stokOpnameHandler := handlers.NewStokOpnameHandler(db, inventoryService, notificationService)
 			stokOpname := protected.Group("/stok-opname")
+			stokOpname.Use(perm.RequirePermission("inventory"))
 			{
 				// Form management endpoints
 				stokOpname.POST("/forms", stokOpnameHandler.CreateForm)
@@ -250,19 +362,20 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 				// Workflow endpoints
 				stokOpname.POST("/forms/:id/submit", stokOpnameHandler.SubmitForApproval)
 				
-				// Approval/rejection endpoints (Kepala_SPPG only)
-				stokOpname.POST("/forms/:id/approve", middleware.RequireRole("kepala_sppg"), stokOpnameHandler.ApproveForm)
-				stokOpname.POST("/forms/:id/reject", middleware.RequireRole("kepala_sppg"), stokOpnameHandler.RejectForm)
+				// Approval/rejection endpoints (Kepala_SPPG by default)
+				stokOpname.POST("/forms/:id/approve", perm.RequirePermission("stok_opname_approve"), stokOpnameHandler.ApproveForm)
+				stokOpname.POST("/forms/:id/reject", perm.RequirePermission("stok_opname_approve"), stokOpnameHandler.RejectForm)
 
 				// Export endpoint
 				stokOpname.GET("/forms/:id/export", stokOpnameHandler.ExportForm)
 			}
 
 			// Logistics routes
-			logisticsHandler := handlers.NewLogisticsHandler(db)
+			logisticsHandler := handlers.NewLogisticsHandler(db, uploader)
 			
 			// School routes
 			schools := protected.Group("/schools")
+			schools.Use(perm.RequireReadWrite("school_view", "school_management"))
 			{
 				schools.GET("", logisticsHandler.GetAllSchools)
 				schools.POST("", logisticsHandler.CreateSchool)
@@ -275,16 +388,17 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 
 			// Delivery Task routes
 			deliveryTasks := protected.Group("/delivery-tasks")
+			deliveryTasks.Use(perm.RequirePermission("delivery_tasks"))
 			{
 				deliveryTasks.GET("", logisticsHandler.GetAllDeliveryTasks)
-				deliveryTasks.POST("", logisticsHandler.CreateDeliveryTask)
+				deliveryTasks.POST("", perm.RequirePermission("delivery_task_management"), logisticsHandler.CreateDeliveryTask)
 				deliveryTasks.GET("/ready-orders", logisticsHandler.GetReadyOrders)
 				deliveryTasks.GET("/available-drivers", logisticsHandler.GetAvailableDrivers)
 				deliveryTasks.GET("/driver/:driver_id/today", logisticsHandler.GetDriverTasksToday)
 				deliveryTasks.GET("/:id", logisticsHandler.GetDeliveryTask)
-				deliveryTasks.PUT("/:id", logisticsHandler.UpdateDeliveryTask)
+				deliveryTasks.PUT("/:id", perm.RequirePermission("delivery_task_management"), logisticsHandler.UpdateDeliveryTask)
 				deliveryTasks.PUT("/:id/status", logisticsHandler.UpdateDeliveryTaskStatus)
-				deliveryTasks.DELETE("/:id", logisticsHandler.DeleteDeliveryTask)
+				deliveryTasks.DELETE("/:id", perm.RequirePermission("delivery_task_management"), logisticsHandler.DeleteDeliveryTask)
 			}
 
 			// Activity Tracker Service (shared by pickup tasks and activity tracker routes)
@@ -294,7 +408,7 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 			pickupTaskService := services.NewPickupTaskService(db, activityTrackerService)
 			pickupTaskHandler := handlers.NewPickupTaskHandler(pickupTaskService)
 			pickupTasks := protected.Group("/pickup-tasks")
-			pickupTasks.Use(middleware.RequireRole("kepala_sppg", "kepala_yayasan", "asisten_lapangan", "driver"))
+			pickupTasks.Use(perm.RequirePermission("pickup_tasks"))
 			{
 				pickupTasks.GET("/eligible-orders", pickupTaskHandler.GetEligibleOrders)
 				pickupTasks.GET("/available-drivers", pickupTaskHandler.GetAvailableDrivers)
@@ -306,8 +420,16 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 				pickupTasks.DELETE("/:id", pickupTaskHandler.CancelPickupTask)
 			}
 
+			// Offline sync: the PWA sends its queued delivery status, pickup
+			// stage and ePOD operations in one ordered batch. Each operation
+			// is checked against the permission its live endpoint requires.
+			syncService := services.NewOfflineSyncService(db, monitoringService, pickupTaskService, permissionService)
+			syncHandler := handlers.NewSyncHandler(syncService)
+			protected.POST("/sync/batch", syncHandler.SyncBatch)
+
 			// e-POD routes
 			epod := protected.Group("/epod")
+			epod.Use(perm.RequireReadWrite("monitoring", "delivery_tasks"))
 			{
 				epod.GET("", logisticsHandler.GetEPODByDeliveryTask)
 				epod.POST("", logisticsHandler.CreateEPOD)
@@ -316,8 +438,9 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 			}
 
 			// Delivery Review routes
-			reviewHandler := handlers.NewReviewHandler(db)
+			reviewHandler := handlers.NewReviewHandler(db, uploader)
 			reviews := protected.Group("/reviews")
+			reviews.Use(perm.RequirePermission("reviews"))
 			{
 				reviews.GET("", reviewHandler.GetAllReviews)
 				reviews.POST("", reviewHandler.CreateReview)
@@ -329,6 +452,7 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 
 			// Ompreng Tracking routes
 			ompreng := protected.Group("/ompreng")
+			ompreng.Use(perm.RequirePermission("delivery_tasks"))
 			{
 				ompreng.GET("/tracking", logisticsHandler.GetOmprengTracking)
 				ompreng.POST("/drop-off", logisticsHandler.RecordOmprengDropOff)
@@ -337,36 +461,43 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 			}
 
 			// HRM routes
-			authService := services.NewAuthService(db, cfg.JWTSecret)
 			hrmHandler := handlers.NewHRMHandler(db, authService)
 			
 			// Employee routes
 			employees := protected.Group("/employees")
+			employees.Use(perm.RequireReadWrite("employee_view", "hrm_management"))
 			{
 				employees.GET("", hrmHandler.GetEmployees)
 				employees.POST("", hrmHandler.CreateEmployee)
 				employees.GET("/stats", hrmHandler.GetEmployeeStats)
+				employees.GET("/import/template", hrmHandler.DownloadImportTemplate)
+				employees.POST("/import", hrmHandler.ImportEmployees)
 				employees.GET("/:id", hrmHandler.GetEmployeeByID)
 				employees.PUT("/:id", hrmHandler.UpdateEmployee)
 				employees.POST("/:id/deactivate", hrmHandler.DeactivateEmployee)
+				employees.POST("/:id/reset-password", hrmHandler.ResetPassword)
+				employees.POST("/:id/unlock", hrmHandler.UnlockAccount)
+				employees.POST("/:id/reset-2fa", hrmHandler.ResetTwoFactor)
 			}
 
 			// Attendance routes
 			attendance := protected.Group("/attendance")
+			attendance.Use(perm.RequirePermission("attendance"))
 			{
 				attendance.POST("/check-in", hrmHandler.CheckIn)
 				attendance.POST("/check-out", hrmHandler.CheckOut)
 				attendance.POST("/validate-wifi", hrmHandler.ValidateWiFi)
 				attendance.GET("/today", hrmHandler.GetTodayAttendance)
-				attendance.GET("/report", hrmHandler.GetAttendanceReport)
-				attendance.GET("/by-date-range", hrmHandler.GetAttendanceByDateRange)
-				attendance.GET("/export/excel", hrmHandler.ExportAttendanceReport)
-				attendance.GET("/export/pdf", hrmHandler.ExportAttendanceReport)
-				attendance.GET("/stats", hrmHandler.GetAttendanceStats)
+				attendance.GET("/report", perm.RequirePermission("attendance_report"), hrmHandler.GetAttendanceReport)
+				attendance.GET("/by-date-range", perm.RequirePermissionForQuery("employee_id", "attendance_report"), hrmHandler.GetAttendanceByDateRange)
+				attendance.GET("/export/excel", perm.RequirePermission("attendance_report"), hrmHandler.ExportAttendanceReport)
+				attendance.GET("/export/pdf", perm.RequirePermission("attendance_report"), hrmHandler.ExportAttendanceReport)
+				attendance.GET("/stats", perm.RequirePermission("attendance_report"), hrmHandler.GetAttendanceStats)
 			}
 
 			// Wi-Fi Configuration routes
 			wifiConfig := protected.Group("/wifi-config")
+			wifiConfig.Use(perm.RequireReadWrite("attendance", "hrm_management"))
 			{
 				wifiConfig.GET("", hrmHandler.GetWiFiConfigs)
 				wifiConfig.POST("", hrmHandler.CreateWiFiConfig)
@@ -376,6 +507,7 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 
 			// GPS Configuration routes
 			gpsConfig := protected.Group("/gps-config")
+			gpsConfig.Use(perm.RequireReadWrite("attendance", "hrm_management"))
 			{
 				gpsConfig.GET("", hrmHandler.GetGPSConfigs)
 				gpsConfig.POST("", hrmHandler.CreateGPSConfig)
@@ -386,6 +518,7 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 			// System Configuration routes (admin only with IP whitelist)
 			systemConfigHandler := handlers.NewSystemConfigHandler(db)
 			systemConfig := protected.Group("/system-config")
+			systemConfig.Use(perm.RequirePermission("system_config"))
 			if len(cfg.AdminWhitelistIPs) > 0 {
 				systemConfig.Use(middleware.IPWhitelist(cfg.AdminWhitelistIPs))
 			}
@@ -399,61 +532,247 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 				systemConfig.DELETE("/:key", systemConfigHandler.DeleteConfig)
 			}
 
+			// Outbox routes: pending and dead-lettered events with replay
+			outboxHandler := handlers.NewOutboxHandler(db)
+			outbox := protected.Group("/system/outbox")
+			outbox.Use(perm.RequirePermission("system_config"))
+			if len(cfg.AdminWhitelistIPs) > 0 {
+				outbox.Use(middleware.IPWhitelist(cfg.AdminWhitelistIPs))
+			}
+			{
+				outbox.GET("", outboxHandler.GetEvents)
+				outbox.GET("/stats", outboxHandler.GetStats)
+				outbox.POST("/replay-dead", outboxHandler.ReplayDeadEvents)
+				outbox.POST("/:id/replay", outboxHandler.ReplayEvent)
+			}
+
+			// Webhook routes: partner system subscriptions and their delivery log
+			webhookHandler := handlers.NewWebhookHandler(db)
+			webhooks := protected.Group("/system/webhooks")
+			webhooks.Use(perm.RequirePermission("system_config"))
+			if len(cfg.AdminWhitelistIPs) > 0 {
+				webhooks.Use(middleware.IPWhitelist(cfg.AdminWhitelistIPs))
+			}
+			{
+				webhooks.GET("", webhookHandler.GetWebhooks)
+				webhooks.POST("", webhookHandler.CreateWebhook)
+				webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+				webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+				webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+				webhooks.POST("/deliveries/:id/redeliver", webhookHandler.Redeliver)
+			}
+
+			// Cache hit/miss metrics
+			cacheHandler := handlers.NewCacheHandler(cacheService)
+			cacheRoutes := protected.Group("/system/cache")
+			cacheRoutes.Use(perm.RequirePermission("system_config"))
+			if len(cfg.AdminWhitelistIPs) > 0 {
+				cacheRoutes.Use(middleware.IPWhitelist(cfg.AdminWhitelistIPs))
+			}
+			{
+				cacheRoutes.GET("/stats", cacheHandler.GetStats)
+			}
+
 			// Financial routes
-			financialHandler := handlers.NewFinancialHandler(db)
+			financialHandler := handlers.NewFinancialHandler(db, budgetService)
 			
 			// Asset routes
+			assetAuditHandler := handlers.NewAssetAuditHandler(services.NewAssetAuditService(db, notificationService))
 			assets := protected.Group("/assets")
+			assets.Use(perm.RequireReadWrite("financial_reports", "finance_management"))
 			{
 				assets.GET("", financialHandler.GetAllAssets)
 				assets.POST("", financialHandler.CreateAsset)
 				assets.GET("/report", financialHandler.GetAssetReport)
+				assets.GET("/labels", assetAuditHandler.GetAssetLabels)
 				assets.GET("/:id", financialHandler.GetAsset)
 				assets.PUT("/:id", financialHandler.UpdateAsset)
 				assets.DELETE("/:id", financialHandler.DeleteAsset)
 				assets.POST("/:id/maintenance", financialHandler.AddMaintenance)
 				assets.GET("/:id/depreciation-schedule", financialHandler.GetDepreciationSchedule)
+				assets.POST("/:id/dispose", financialHandler.DisposeAsset)
+				assets.GET("/:id/usage", financialHandler.GetAssetUsage)
+				assets.POST("/:id/usage", financialHandler.RecordAssetUsage)
+				assets.GET("/depreciation-runs", financialHandler.GetDepreciationRuns)
+				assets.POST("/depreciation-runs", financialHandler.RunDepreciation)
+				assets.GET("/depreciation-runs/:id", financialHandler.GetDepreciationRun)
+				assets.POST("/depreciation-runs/:id/reverse", financialHandler.ReverseDepreciationRun)
+			}
+
+			// Preventive maintenance plan, calendar and completion routes
+			maintenancePlanHandler := handlers.NewMaintenancePlanHandler(services.NewMaintenancePlanService(db, notificationService))
+			maintenance := protected.Group("/maintenance")
+			maintenance.Use(perm.RequirePermission("asset_maintenance"))
+			{
+				maintenance.GET("/plans", maintenancePlanHandler.GetPlans)
+				maintenance.POST("/plans", perm.RequirePermission("finance_management"), maintenancePlanHandler.CreatePlan)
+				maintenance.GET("/plans/:id", maintenancePlanHandler.GetPlan)
+				maintenance.PUT("/plans/:id", perm.RequirePermission("finance_management"), maintenancePlanHandler.UpdatePlan)
+				maintenance.GET("/plans/:id/history", maintenancePlanHandler.GetPlanHistory)
+				maintenance.POST("/plans/:id/complete", maintenancePlanHandler.CompletePlanTask)
+				maintenance.GET("/calendar", maintenancePlanHandler.GetCalendar)
+				maintenance.GET("/alerts", maintenancePlanHandler.GetAssetAlerts)
+			}
+
+			// Physical asset audit routes (scan labels, submit, approve)
+			assetAudits := protected.Group("/asset-audits")
+			assetAudits.Use(perm.RequirePermission("asset_audit"))
+			{
+				assetAudits.GET("", assetAuditHandler.GetAudits)
+				assetAudits.POST("", assetAuditHandler.StartAudit)
+				assetAudits.GET("/:id", assetAuditHandler.GetAudit)
+				assetAudits.GET("/:id/report", assetAuditHandler.GetAuditReport)
+				assetAudits.POST("/:id/scan", assetAuditHandler.ScanAsset)
+				assetAudits.POST("/:id/submit", assetAuditHandler.SubmitAudit)
+				assetAudits.POST("/:id/approve", perm.RequirePermission("asset_audit_approve"), assetAuditHandler.ApproveAudit)
+				assetAudits.POST("/:id/reject", perm.RequirePermission("asset_audit_approve"), assetAuditHandler.RejectAudit)
 			}
 
 			// Cash Flow routes
 			cashFlow := protected.Group("/cash-flow")
+			cashFlow.Use(perm.RequireReadWrite("financial_reports", "finance_management"))
 			{
 				cashFlow.GET("", financialHandler.GetAllCashFlow)
 				cashFlow.POST("", financialHandler.CreateCashFlow)
 				cashFlow.GET("/summary", financialHandler.GetCashFlowSummary)
 			}
 
+			// Budget target routes
+			budgetHandler := handlers.NewBudgetHandler(budgetService)
+			budgetTargets := protected.Group("/budget-targets")
+			budgetTargets.Use(perm.RequireReadWrite("financial_reports", "finance_management"))
+			{
+				budgetTargets.GET("", budgetHandler.GetBudgetTargets)
+				budgetTargets.POST("", budgetHandler.CreateBudgetTarget)
+				budgetTargets.GET("/summary", budgetHandler.GetBudgetSummary)
+				budgetTargets.POST("/import", budgetHandler.ImportBudgetTargets)
+				budgetTargets.GET("/:id", budgetHandler.GetBudgetTarget)
+				budgetTargets.PUT("/:id", budgetHandler.UpdateBudgetTarget)
+				budgetTargets.DELETE("/:id", budgetHandler.DeleteBudgetTarget)
+			}
+
+			// General ledger routes
+			ledgerHandler := handlers.NewLedgerHandler(ledgerService)
+			ledger := protected.Group("/ledger")
+			ledger.Use(perm.RequireReadWrite("financial_reports", "finance_management"))
+			{
+				ledger.GET("/accounts", ledgerHandler.GetAccounts)
+				ledger.POST("/accounts", ledgerHandler.CreateAccount)
+				ledger.GET("/accounts/:id", ledgerHandler.GetAccount)
+				ledger.PUT("/accounts/:id", ledgerHandler.UpdateAccount)
+				ledger.GET("/accounts/:id/ledger", ledgerHandler.GetGeneralLedger)
+				ledger.GET("/journals", ledgerHandler.GetJournalEntries)
+				ledger.POST("/journals", ledgerHandler.CreateJournalEntry)
+				ledger.GET("/journals/:id", ledgerHandler.GetJournalEntry)
+				ledger.POST("/journals/:id/reverse", ledgerHandler.ReverseJournalEntry)
+				ledger.GET("/trial-balance", ledgerHandler.GetTrialBalance)
+				ledger.GET("/balance-sheet", ledgerHandler.GetBalanceSheet)
+				ledger.GET("/income-statement", ledgerHandler.GetIncomeStatement)
+				ledger.POST("/depreciation", ledgerHandler.PostDepreciation)
+			}
+
+			// Accounts payable routes (supplier invoices, payments, aging)
+			apCashFlowService := services.NewCashFlowService(db)
+			apCashFlowService.SetBudgetService(budgetService)
+			apHandler := handlers.NewAccountsPayableHandler(services.NewAccountsPayableService(db, apCashFlowService))
+			accountsPayable := protected.Group("/accounts-payable")
+			accountsPayable.Use(perm.RequireReadWrite("financial_reports", "finance_management"))
+			{
+				accountsPayable.GET("/invoices", apHandler.GetInvoices)
+				accountsPayable.POST("/invoices", apHandler.CreateInvoice)
+				accountsPayable.GET("/invoices/:id", apHandler.GetInvoice)
+				accountsPayable.POST("/invoices/:id/approve", apHandler.ApproveInvoice)
+				accountsPayable.POST("/invoices/:id/cancel", apHandler.CancelInvoice)
+				accountsPayable.POST("/invoices/:id/payments", apHandler.RecordPayment)
+				accountsPayable.GET("/payments", apHandler.GetPayments)
+				accountsPayable.GET("/aging", apHandler.GetAgingReport)
+			}
+
+			// Government funding tranche and LPJ routes
+			fundingHandler := handlers.NewFundingHandler(services.NewFundingService(db, apCashFlowService).WithBlobStore(uploader.Store()))
+			fundingTranches := protected.Group("/funding-tranches")
+			fundingTranches.Use(perm.RequireReadWrite("financial_reports", "finance_management"))
+			{
+				fundingTranches.GET("", fundingHandler.GetTranches)
+				fundingTranches.POST("", fundingHandler.CreateTranche)
+				fundingTranches.GET("/:id", fundingHandler.GetTranche)
+				fundingTranches.PUT("/:id", fundingHandler.UpdateTranche)
+				fundingTranches.DELETE("/:id", fundingHandler.DeleteTranche)
+				fundingTranches.POST("/:id/expenses", fundingHandler.AssignExpenses)
+				fundingTranches.DELETE("/:id/expenses/:entry_id", fundingHandler.UnassignExpense)
+				fundingTranches.GET("/:id/lpj", fundingHandler.GetLPJ)
+				fundingTranches.GET("/:id/lpj/export", fundingHandler.ExportLPJ)
+			}
+
+			// Bank statement import and reconciliation routes
+			bankReconciliationHandler := handlers.NewBankReconciliationHandler(services.NewBankReconciliationService(db))
+			bankReconciliation := protected.Group("/bank-reconciliation")
+			bankReconciliation.Use(perm.RequireReadWrite("financial_reports", "finance_management"))
+			{
+				bankReconciliation.GET("/statements", bankReconciliationHandler.GetStatements)
+				bankReconciliation.POST("/statements/import", bankReconciliationHandler.ImportStatement)
+				bankReconciliation.GET("/statements/:id", bankReconciliationHandler.GetStatement)
+				bankReconciliation.DELETE("/statements/:id", bankReconciliationHandler.DeleteStatement)
+				bankReconciliation.POST("/statements/:id/auto-match", bankReconciliationHandler.AutoMatchStatement)
+				bankReconciliation.GET("/lines", bankReconciliationHandler.GetLines)
+				bankReconciliation.GET("/lines/:id/candidates", bankReconciliationHandler.GetMatchCandidates)
+				bankReconciliation.POST("/lines/:id/match", bankReconciliationHandler.MatchLine)
+				bankReconciliation.POST("/lines/:id/unmatch", bankReconciliationHandler.UnmatchLine)
+				bankReconciliation.GET("/report", bankReconciliationHandler.GetReconciliationReport)
+			}
+
+			// Petty cash fund, expense and replenishment routes
+			pettyCashHandler := handlers.NewPettyCashHandler(services.NewPettyCashService(db, apCashFlowService, notificationService), uploader)
+			pettyCash := protected.Group("/petty-cash")
+			pettyCash.Use(perm.RequirePermission("petty_cash"))
+			{
+				pettyCash.GET("/funds", pettyCashHandler.GetFunds)
+				pettyCash.POST("/funds", perm.RequirePermission("finance_management"), pettyCashHandler.CreateFund)
+				pettyCash.GET("/funds/:id", pettyCashHandler.GetFund)
+				pettyCash.PUT("/funds/:id", perm.RequirePermission("finance_management"), pettyCashHandler.UpdateFund)
+				pettyCash.GET("/funds/:id/expenses", pettyCashHandler.GetExpenses)
+				pettyCash.POST("/funds/:id/expenses", pettyCashHandler.CreateExpense)
+				pettyCash.POST("/funds/:id/replenishments", pettyCashHandler.RequestReplenishment)
+				pettyCash.GET("/replenishments", pettyCashHandler.GetReplenishments)
+				pettyCash.GET("/replenishments/:id", pettyCashHandler.GetReplenishment)
+				pettyCash.POST("/replenishments/:id/approve", perm.RequirePermission("petty_cash_approve"), pettyCashHandler.ApproveReplenishment)
+				pettyCash.POST("/replenishments/:id/reject", perm.RequirePermission("petty_cash_approve"), pettyCashHandler.RejectReplenishment)
+			}
+
 			// Financial Report routes
 			financialReports := protected.Group("/financial-reports")
+			financialReports.Use(perm.RequirePermission("financial_reports"))
 			{
 				financialReports.GET("", financialHandler.GetFinancialReport)
 				financialReports.POST("/export", financialHandler.ExportFinancialReport)
 			}
 
 			// Dashboard routes (works with or without Firebase)
-			dashboardHandler, err := handlers.NewDashboardHandler(db, firebaseApp)
+			dashboardHandler, err := handlers.NewDashboardHandler(db, publisher)
 			if err != nil {
 				log.Printf("Warning: Dashboard handler initialization failed: %v. Using dummy data mode.", err)
 			}
 			dashboard := protected.Group("/dashboard")
+			dashboard.Use(perm.RequirePermission("dashboard"))
 			// Apply dashboard caching middleware
 			if cacheService != nil {
-				dashboard.Use(middleware.DashboardCacheMiddleware(cacheService))
+				dashboard.Use(middleware.CacheMiddleware(cacheService, cache.ShortCacheDuration, cache.DashboardTag))
 			}
 			{
-				dashboard.GET("/kepala-sppg", dashboardHandler.GetKepalaSSPGDashboard)
-				dashboard.GET("/kepala-yayasan", dashboardHandler.GetKepalaYayasanDashboard)
+				dashboard.GET("/kepala-sppg", perm.RequirePermission("dashboard_executive"), dashboardHandler.GetKepalaSSPGDashboard)
+				dashboard.GET("/kepala-yayasan", perm.RequirePermission("dashboard_executive"), dashboardHandler.GetKepalaYayasanDashboard)
 				dashboard.GET("/kpi", dashboardHandler.GetKPIs)
-				dashboard.POST("/sync", dashboardHandler.SyncDashboardToFirebase)
-				dashboard.POST("/export", dashboardHandler.ExportDashboard)
-				dashboard.POST("/clear-firebase", dashboardHandler.ClearFirebaseKDSData)
+				dashboard.POST("/sync", perm.RequirePermission("dashboard_executive"), dashboardHandler.SyncDashboardToFirebase)
+				dashboard.POST("/export", perm.RequirePermission("dashboard_executive"), dashboardHandler.ExportDashboard)
+				dashboard.POST("/clear-firebase", perm.RequirePermission("dashboard_executive"), dashboardHandler.ClearFirebaseKDSData)
 			}
 
 			// Notification routes
-			notificationHandler, err := handlers.NewNotificationHandler(db, firebaseApp)
+			notificationHandler, err := handlers.NewNotificationHandler(db, publisher)
 			if err != nil {
 				panic("Failed to initialize Notification handler: " + err.Error())
 			}
+			// Notifications are personal, so authentication is enough
 			notifications := protected.Group("/notifications")
 			{
 				notifications.GET("", notificationHandler.GetNotifications)
@@ -466,15 +785,16 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 			// Audit Trail routes
 			auditHandler := handlers.NewAuditHandler(db)
 			auditTrail := protected.Group("/audit-trail")
+			auditTrail.Use(perm.RequirePermission("audit_trail"))
 			{
 				auditTrail.GET("", auditHandler.GetAuditTrail)
 				auditTrail.GET("/stats", auditHandler.GetAuditStats)
 			}
 
-			monitoringHandler := handlers.NewMonitoringHandler(monitoringService)
+			monitoringHandler := handlers.NewMonitoringHandler(monitoringService, statusAuthorizer)
 			monitoring := protected.Group("/monitoring")
-			// Exclude kebersihan role from monitoring routes
-			monitoring.Use(middleware.RequireRole("kepala_sppg", "kepala_yayasan", "akuntan", "ahli_gizi", "pengadaan", "chef", "packing", "driver", "asisten_lapangan"))
+			// Excludes the kebersihan role by default
+			monitoring.Use(perm.RequirePermission("monitoring"))
 			{
 				monitoring.GET("/deliveries", monitoringHandler.GetDeliveryRecords)
 				monitoring.GET("/deliveries/:id", monitoringHandler.GetDeliveryDetail)
@@ -485,14 +805,14 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 
 			// Cleaning routes (KDS Cleaning module)
 			// Requirements: 7.1, 7.2, 7.3, 8.2
-			cleaningService, err := services.NewCleaningService(db, firebaseApp)
+			cleaningService, err := services.NewCleaningService(db, publisher)
 			if err != nil {
 				panic("Failed to initialize Cleaning service: " + err.Error())
 			}
 			cleaningHandler := handlers.NewCleaningHandler(cleaningService)
 			cleaning := protected.Group("/cleaning")
-			// Allow kebersihan role and admin override (kepala_sppg, kepala_yayasan)
-			cleaning.Use(middleware.RequireRole("kebersihan", "kepala_sppg", "kepala_yayasan"))
+			// Allow kebersihan role and admin override (kepala_sppg, kepala_yayasan) by default
+			cleaning.Use(perm.RequirePermission("cleaning"))
 			{
 				cleaning.GET("/pending", cleaningHandler.GetPendingOmpreng)
 				cleaning.POST("/:id/start", cleaningHandler.StartCleaning)
@@ -500,10 +820,10 @@ func Setup(db *gorm.DB, firebaseApp *firebase.App, cfg *config.Config, cacheServ
 			}
 
 			// Activity Tracker routes (standalone module)
-			activityTrackerHandler := handlers.NewActivityTrackerHandler(activityTrackerService)
+			activityTrackerHandler := handlers.NewActivityTrackerHandler(activityTrackerService, uploader)
 			activityTracker := protected.Group("/activity-tracker")
-			// Allow kepala_sppg and management roles
-			activityTracker.Use(middleware.RequireRole("kepala_sppg", "kepala_yayasan", "akuntan"))
+			// Allow kepala_sppg and management roles by default
+			activityTracker.Use(perm.RequirePermission("activity_tracker"))
 			{
 				activityTracker.GET("/orders", activityTrackerHandler.GetOrdersByDate)
 				activityTracker.GET("/orders/:id", activityTrackerHandler.GetOrderDetails)
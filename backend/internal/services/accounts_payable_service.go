@@ -0,0 +1,651 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSupplierInvoiceNotFound  = errors.New("faktur supplier tidak ditemukan")
+	ErrDuplicateSupplierInvoice = errors.New("nomor faktur sudah tercatat untuk supplier ini")
+	ErrGRNAlreadyInvoiced       = errors.New("goods receipt sudah memiliki faktur aktif")
+	ErrInvoiceWithoutItems      = errors.New("faktur harus memiliki minimal 1 item")
+	ErrInvalidInvoiceAmount     = errors.New("jumlah, harga dan pajak faktur tidak valid")
+	ErrInvalidInvoiceDueDate    = errors.New("tanggal jatuh tempo tidak boleh sebelum tanggal faktur")
+	ErrInvoiceNotOnHold         = errors.New("faktur tidak sedang ditahan")
+	ErrInvoiceNotPayable        = errors.New("faktur belum dapat dibayar atau sudah lunas")
+	ErrInvoiceHasPayments       = errors.New("faktur yang sudah dibayar tidak dapat dibatalkan")
+	ErrInvoiceAlreadyCancelled  = errors.New("faktur sudah dibatalkan")
+	ErrApprovalReasonRequired   = errors.New("alasan persetujuan wajib diisi")
+	ErrInvalidPaymentAmount     = errors.New("jumlah pembayaran harus lebih dari nol dan tidak melebihi sisa tagihan")
+	ErrInvalidPaymentMethod     = errors.New("metode pembayaran tidak valid")
+	ErrBankReferenceRequired    = errors.New("nomor referensi transfer bank wajib diisi")
+)
+
+// Supplier invoice statuses
+const (
+	InvoiceStatusOnHold        = "on_hold"
+	InvoiceStatusOpen          = "open"
+	InvoiceStatusPartiallyPaid = "partially_paid"
+	InvoiceStatusPaid          = "paid"
+	InvoiceStatusCancelled     = "cancelled"
+)
+
+// Three-way match results
+const (
+	MatchStatusMatched    = "matched"
+	MatchStatusMismatch   = "mismatch"
+	MatchStatusOverridden = "overridden"
+)
+
+const (
+	// defaultAPQuantityTolerance is the allowed difference between invoiced and received quantity, in percent
+	defaultAPQuantityTolerance = 2.0
+	// defaultAPPriceTolerance is the allowed increase of the invoiced price over the purchase order price, in percent
+	defaultAPPriceTolerance = 2.0
+	// defaultAPAmountTolerance is the allowed difference between the invoice subtotal and the received value, in percent
+	defaultAPAmountTolerance = 2.0
+)
+
+// APAgingBuckets holds outstanding amounts grouped by days past due
+type APAgingBuckets struct {
+	Current    models.Money `json:"current"`
+	Days1To30  models.Money `json:"days_1_30"`
+	Days31To60 models.Money `json:"days_31_60"`
+	Days61To90 models.Money `json:"days_61_90"`
+	Over90     models.Money `json:"over_90"`
+	Total      models.Money `json:"total"`
+}
+
+// APAgingRow is the outstanding balance of a supplier
+type APAgingRow struct {
+	SupplierID   uint   `json:"supplier_id"`
+	SupplierName string `json:"supplier_name"`
+	InvoiceCount int    `json:"invoice_count"`
+	APAgingBuckets
+}
+
+// APAgingReport is the accounts payable aging per supplier at a date
+type APAgingReport struct {
+	AsOf      time.Time      `json:"as_of"`
+	Suppliers []APAgingRow   `json:"suppliers"`
+	Totals    APAgingBuckets `json:"totals"`
+}
+
+// AccountsPayableService handles supplier invoices, three-way matching and supplier payments
+type AccountsPayableService struct {
+	db              *gorm.DB
+	cashFlowService *CashFlowService
+	ledgerService   *LedgerService
+}
+
+// NewAccountsPayableService creates a new accounts payable service
+func NewAccountsPayableService(db *gorm.DB, cashFlowService *CashFlowService) *AccountsPayableService {
+	return &AccountsPayableService{
+		db:              db,
+		cashFlowService: cashFlowService,
+		ledgerService:   NewLedgerService(db),
+	}
+}
+
+// CreateInvoice records a supplier invoice for a goods receipt and matches it
+// against the purchase order and the goods receipt. Matched invoices are open
+// for payment and replace the received value with the invoiced amount as the
+// purchase expense; invoices outside the tolerances are put on hold.
+func (s *AccountsPayableService) CreateInvoice(invoice *models.SupplierInvoice, userID uint) error {
+	if len(invoice.Items) == 0 {
+		return ErrInvoiceWithoutItems
+	}
+	if invoice.TaxAmount < 0 {
+		return ErrInvalidInvoiceAmount
+	}
+
+	var grn models.GoodsReceipt
+	if err := s.db.Preload("GRNItems").
+		Preload("PurchaseOrder.Supplier").
+		Preload("PurchaseOrder.POItems").
+		First(&grn, invoice.GRNID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrGRNNotFound
+		}
+		return err
+	}
+	po := grn.PurchaseOrder
+
+	var count int64
+	if err := s.db.Model(&models.SupplierInvoice{}).
+		Where("grn_id = ? AND status <> ?", grn.ID, InvoiceStatusCancelled).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrGRNAlreadyInvoiced
+	}
+
+	invoice.InvoiceNumber = strings.TrimSpace(invoice.InvoiceNumber)
+	if err := s.db.Model(&models.SupplierInvoice{}).
+		Where("supplier_id = ? AND invoice_number = ? AND status <> ?", po.SupplierID, invoice.InvoiceNumber, InvoiceStatusCancelled).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateSupplierInvoice
+	}
+
+	if invoice.InvoiceDate.IsZero() {
+		invoice.InvoiceDate = time.Now()
+	}
+	if invoice.DueDate.IsZero() {
+		invoice.DueDate = invoice.InvoiceDate.AddDate(0, 0, po.Supplier.PaymentTermDays)
+	}
+	if invoice.DueDate.Before(startOfDay(invoice.InvoiceDate)) {
+		return ErrInvalidInvoiceDueDate
+	}
+
+	var subtotal models.Money
+	for i := range invoice.Items {
+		item := &invoice.Items[i]
+		if item.Quantity <= 0 || item.UnitPrice < 0 {
+			return ErrInvalidInvoiceAmount
+		}
+		item.ID = 0
+		item.Subtotal = item.UnitPrice.Mul(item.Quantity)
+		subtotal += item.Subtotal
+	}
+
+	invoice.ID = 0
+	invoice.SupplierID = po.SupplierID
+	invoice.POID = po.ID
+	invoice.Subtotal = subtotal
+	invoice.TotalAmount = subtotal + invoice.TaxAmount
+	if invoice.TotalAmount <= 0 {
+		return ErrInvalidInvoiceAmount
+	}
+	invoice.ReceivedAmount = ReceivedValue(&po, grn.GRNItems)
+	invoice.PaidAmount = 0
+	invoice.ApprovedBy = nil
+	invoice.ApprovedAt = nil
+	invoice.CreatedBy = userID
+
+	s.matchInvoice(invoice, &grn)
+	invoice.Status = InvoiceStatusOnHold
+	if invoice.MatchStatus == MatchStatusMatched {
+		invoice.Status = InvoiceStatusOpen
+	}
+
+	var expense *models.CashFlowEntry
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(invoice).Error; err != nil {
+			return err
+		}
+		if invoice.Status != InvoiceStatusOpen {
+			return nil
+		}
+
+		var err error
+		expense, err = s.recognizeExpenseWithTx(tx, invoice, &grn, invoice.TotalAmount, userID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if expense != nil {
+		s.cashFlowService.CheckBudgetAlerts(expense)
+	}
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "supplier_invoice", strconv.FormatUint(uint64(invoice.ID), 10), nil, invoice, "")
+	return nil
+}
+
+// ApproveInvoice releases an invoice held by the three-way match for payment.
+// The reason is kept in the match notes.
+func (s *AccountsPayableService) ApproveInvoice(id uint, reason string, userID uint) (*models.SupplierInvoice, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrApprovalReasonRequired
+	}
+
+	var expense *models.CashFlowEntry
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		invoice, grn, err := s.loadInvoiceWithTx(tx, id)
+		if err != nil {
+			return err
+		}
+		if invoice.Status != InvoiceStatusOnHold {
+			return ErrInvoiceNotOnHold
+		}
+
+		now := time.Now()
+		matchNotes := strings.TrimSpace(invoice.MatchNotes + "\nDisetujui: " + reason)
+		if err := tx.Model(&models.SupplierInvoice{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":       InvoiceStatusOpen,
+			"match_status": MatchStatusOverridden,
+			"match_notes":  matchNotes,
+			"approved_by":  userID,
+			"approved_at":  now,
+			"updated_at":   now,
+		}).Error; err != nil {
+			return err
+		}
+
+		expense, err = s.recognizeExpenseWithTx(tx, invoice, grn, invoice.TotalAmount, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if expense != nil {
+		s.cashFlowService.CheckBudgetAlerts(expense)
+	}
+	NewAuditTrailService(s.db).RecordAction(userID, "approve", "supplier_invoice", strconv.FormatUint(uint64(id), 10), nil, map[string]interface{}{"reason": reason}, "")
+	return s.GetInvoice(id)
+}
+
+// CancelInvoice cancels an unpaid invoice. When the invoice already replaced
+// the purchase expense, the expense goes back to the received value.
+func (s *AccountsPayableService) CancelInvoice(id uint, reason string, userID uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		invoice, grn, err := s.loadInvoiceWithTx(tx, id)
+		if err != nil {
+			return err
+		}
+		if invoice.Status == InvoiceStatusCancelled {
+			return ErrInvoiceAlreadyCancelled
+		}
+		if invoice.PaidAmount > 0 {
+			return ErrInvoiceHasPayments
+		}
+
+		notes := invoice.Notes
+		if reason = strings.TrimSpace(reason); reason != "" {
+			notes = strings.TrimSpace(notes + "\nDibatalkan: " + reason)
+		}
+		if err := tx.Model(&models.SupplierInvoice{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":     InvoiceStatusCancelled,
+			"notes":      notes,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		if invoice.Status == InvoiceStatusOnHold {
+			return nil
+		}
+		_, err = s.recognizeExpenseWithTx(tx, invoice, grn, invoice.ReceivedAmount, userID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "cancel", "supplier_invoice", strconv.FormatUint(uint64(id), 10), nil, map[string]interface{}{"reason": reason}, "")
+	return nil
+}
+
+// GetInvoice retrieves a supplier invoice with its match result and payments
+func (s *AccountsPayableService) GetInvoice(id uint) (*models.SupplierInvoice, error) {
+	var invoice models.SupplierInvoice
+	err := s.db.Preload("Supplier").
+		Preload("PurchaseOrder").
+		Preload("GoodsReceipt").
+		Preload("Items.Ingredient").
+		Preload("Payments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("payment_date ASC")
+		}).
+		First(&invoice, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSupplierInvoiceNotFound
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// GetInvoices retrieves supplier invoices by supplier and status, earliest due date first
+func (s *AccountsPayableService) GetInvoices(supplierID uint, status string) ([]models.SupplierInvoice, error) {
+	query := s.db.Preload("Supplier")
+	if supplierID > 0 {
+		query = query.Where("supplier_id = ?", supplierID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var invoices []models.SupplierInvoice
+	err := query.Order("due_date ASC, id ASC").Find(&invoices).Error
+	return invoices, err
+}
+
+// RecordPayment records a full or partial payment of an open invoice and posts
+// it against accounts payable. Bank transfers require the transfer reference.
+func (s *AccountsPayableService) RecordPayment(invoiceID uint, payment *models.SupplierPayment, userID uint) error {
+	payment.BankReference = strings.TrimSpace(payment.BankReference)
+	if payment.Method != "transfer" && payment.Method != "cash" {
+		return ErrInvalidPaymentMethod
+	}
+	if payment.Method == "transfer" && payment.BankReference == "" {
+		return ErrBankReferenceRequired
+	}
+	if payment.Amount <= 0 {
+		return ErrInvalidPaymentAmount
+	}
+	if payment.PaymentDate.IsZero() {
+		payment.PaymentDate = time.Now()
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var invoice models.SupplierInvoice
+		if err := tx.First(&invoice, invoiceID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSupplierInvoiceNotFound
+			}
+			return err
+		}
+		if invoice.Status != InvoiceStatusOpen && invoice.Status != InvoiceStatusPartiallyPaid {
+			return ErrInvoiceNotPayable
+		}
+
+		outstanding := invoice.TotalAmount - invoice.PaidAmount
+		if payment.Amount > outstanding {
+			return ErrInvalidPaymentAmount
+		}
+
+		paymentNumber, err := s.generatePaymentNumber(tx, payment.PaymentDate)
+		if err != nil {
+			return err
+		}
+		payment.ID = 0
+		payment.PaymentNumber = paymentNumber
+		payment.InvoiceID = invoice.ID
+		payment.SupplierID = invoice.SupplierID
+		payment.CreatedBy = userID
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		if err := s.ledgerService.PostSupplierPaymentWithTx(tx, payment, invoice.InvoiceNumber); err != nil {
+			return err
+		}
+
+		paidAmount := invoice.PaidAmount + payment.Amount
+		status := InvoiceStatusPartiallyPaid
+		if paidAmount >= invoice.TotalAmount {
+			status = InvoiceStatusPaid
+		}
+		return tx.Model(&models.SupplierInvoice{}).Where("id = ?", invoice.ID).Updates(map[string]interface{}{
+			"paid_amount": paidAmount,
+			"status":      status,
+			"updated_at":  time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "supplier_payment", payment.PaymentNumber, nil, payment, "")
+	return nil
+}
+
+// GetPayments retrieves supplier payments with optional supplier and date filters
+func (s *AccountsPayableService) GetPayments(supplierID uint, startDate, endDate *time.Time) ([]models.SupplierPayment, error) {
+	query := s.db.Preload("Supplier")
+	if supplierID > 0 {
+		query = query.Where("supplier_id = ?", supplierID)
+	}
+	if startDate != nil {
+		query = query.Where("payment_date >= ?", startOfDay(*startDate))
+	}
+	if endDate != nil {
+		query = query.Where("payment_date < ?", nextDay(*endDate))
+	}
+
+	var payments []models.SupplierPayment
+	err := query.Order("payment_date DESC, id DESC").Find(&payments).Error
+	return payments, err
+}
+
+// GetAgingReport returns the outstanding invoices of every supplier at asOf,
+// grouped by days past due. Payments after asOf are not deducted.
+func (s *AccountsPayableService) GetAgingReport(asOf time.Time) (*APAgingReport, error) {
+	end := nextDay(asOf)
+
+	var invoices []models.SupplierInvoice
+	if err := s.db.Preload("Supplier").
+		Where("status <> ? AND invoice_date < ?", InvoiceStatusCancelled, end).
+		Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+
+	var paidRows []struct {
+		InvoiceID uint
+		Paid      models.Money
+	}
+	if err := s.db.Model(&models.SupplierPayment{}).
+		Select("invoice_id, SUM(amount) AS paid").
+		Where("payment_date < ?", end).
+		Group("invoice_id").
+		Scan(&paidRows).Error; err != nil {
+		return nil, err
+	}
+	paid := make(map[uint]models.Money, len(paidRows))
+	for _, row := range paidRows {
+		paid[row.InvoiceID] = row.Paid
+	}
+
+	report := &APAgingReport{AsOf: asOf, Suppliers: []APAgingRow{}}
+	rows := make(map[uint]*APAgingRow)
+	today := startOfDay(asOf)
+	for _, invoice := range invoices {
+		outstanding := invoice.TotalAmount - paid[invoice.ID]
+		if outstanding <= 0 {
+			continue
+		}
+
+		row, ok := rows[invoice.SupplierID]
+		if !ok {
+			row = &APAgingRow{SupplierID: invoice.SupplierID, SupplierName: invoice.Supplier.Name}
+			rows[invoice.SupplierID] = row
+		}
+		row.InvoiceCount++
+
+		daysPastDue := int(math.Round(today.Sub(startOfDay(invoice.DueDate)).Hours() / 24))
+		row.APAgingBuckets.add(daysPastDue, outstanding)
+		report.Totals.add(daysPastDue, outstanding)
+	}
+
+	for _, row := range rows {
+		report.Suppliers = append(report.Suppliers, *row)
+	}
+	sort.SliceStable(report.Suppliers, func(i, j int) bool {
+		if report.Suppliers[i].Total != report.Suppliers[j].Total {
+			return report.Suppliers[i].Total > report.Suppliers[j].Total
+		}
+		return report.Suppliers[i].SupplierName < report.Suppliers[j].SupplierName
+	})
+	return report, nil
+}
+
+// add adds an outstanding amount to the bucket of its days past due
+func (b *APAgingBuckets) add(daysPastDue int, amount models.Money) {
+	switch {
+	case daysPastDue <= 0:
+		b.Current = b.Current + amount
+	case daysPastDue <= 30:
+		b.Days1To30 = b.Days1To30 + amount
+	case daysPastDue <= 60:
+		b.Days31To60 = b.Days31To60 + amount
+	case daysPastDue <= 90:
+		b.Days61To90 = b.Days61To90 + amount
+	default:
+		b.Over90 = b.Over90 + amount
+	}
+	b.Total = b.Total + amount
+}
+
+// matchInvoice matches every invoice line against the purchase order price and
+// the received quantity, and the invoice subtotal against the received value,
+// using the tolerances from the system configuration
+func (s *AccountsPayableService) matchInvoice(invoice *models.SupplierInvoice, grn *models.GoodsReceipt) {
+	configService := NewSystemConfigService(s.db)
+	quantityTolerance := configService.GetConfigFloat("ap_match_quantity_tolerance_percent", defaultAPQuantityTolerance)
+	priceTolerance := configService.GetConfigFloat("ap_match_price_tolerance_percent", defaultAPPriceTolerance)
+	amountTolerance := configService.GetConfigFloat("ap_match_amount_tolerance_percent", defaultAPAmountTolerance)
+
+	poItems := make(map[uint]models.PurchaseOrderItem, len(grn.PurchaseOrder.POItems))
+	for _, poItem := range grn.PurchaseOrder.POItems {
+		poItems[poItem.IngredientID] = poItem
+	}
+	received := make(map[uint]float64, len(grn.GRNItems))
+	for _, grnItem := range grn.GRNItems {
+		received[grnItem.IngredientID] += grnItem.ReceivedQuantity
+	}
+
+	var notes []string
+	for i := range invoice.Items {
+		item := &invoice.Items[i]
+		var itemNotes []string
+
+		poItem, ok := poItems[item.IngredientID]
+		if !ok {
+			itemNotes = append(itemNotes, "bahan baku tidak ada dalam purchase order")
+		} else {
+			item.OrderedQuantity = poItem.Quantity
+			item.ReceivedQuantity = received[item.IngredientID]
+			item.POUnitPrice = poItem.UnitPrice
+			item.QuantityVariance = variancePercent(item.Quantity, item.ReceivedQuantity)
+			item.PriceVariance = variancePercent(item.UnitPrice.Float64(), poItem.UnitPrice.Float64())
+
+			if math.Abs(item.QuantityVariance) > quantityTolerance {
+				itemNotes = append(itemNotes, fmt.Sprintf("jumlah faktur %.2f berbeda %.2f%% dari jumlah diterima %.2f",
+					item.Quantity, item.QuantityVariance, item.ReceivedQuantity))
+			}
+			if item.PriceVariance > priceTolerance {
+				itemNotes = append(itemNotes, fmt.Sprintf("harga faktur %s lebih tinggi %.2f%% dari harga PO %s",
+					item.UnitPrice, item.PriceVariance, item.POUnitPrice))
+			}
+		}
+
+		item.MatchStatus = MatchStatusMatched
+		item.MatchNotes = ""
+		if len(itemNotes) > 0 {
+			item.MatchStatus = MatchStatusMismatch
+			item.MatchNotes = strings.Join(itemNotes, "; ")
+			notes = append(notes, fmt.Sprintf("Bahan baku ID %d: %s", item.IngredientID, item.MatchNotes))
+		}
+	}
+
+	if amountVariance := variancePercent(invoice.Subtotal.Float64(), invoice.ReceivedAmount.Float64()); math.Abs(amountVariance) > amountTolerance {
+		notes = append(notes, fmt.Sprintf("Subtotal faktur %s berbeda %.2f%% dari nilai barang diterima %s",
+			invoice.Subtotal, amountVariance, invoice.ReceivedAmount))
+	}
+
+	invoice.MatchStatus = MatchStatusMatched
+	invoice.MatchNotes = ""
+	if len(notes) > 0 {
+		invoice.MatchStatus = MatchStatusMismatch
+		invoice.MatchNotes = strings.Join(notes, "\n")
+	}
+}
+
+// recognizeExpenseWithTx sets the purchase expense of a goods receipt to the
+// given amount and reposts its journal. It returns the expense when it changed.
+func (s *AccountsPayableService) recognizeExpenseWithTx(tx *gorm.DB, invoice *models.SupplierInvoice, grn *models.GoodsReceipt, amount models.Money, userID uint) (*models.CashFlowEntry, error) {
+
+	var entry models.CashFlowEntry
+	err := tx.Where("reference = ? AND category = ? AND type = ?", grn.GRNNumber, "bahan_baku", "expense").
+		Order("id ASC").First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if amount <= 0 {
+			return nil, nil
+		}
+		entry = models.CashFlowEntry{
+			Date:        grn.ReceiptDate,
+			Category:    "bahan_baku",
+			Type:        "expense",
+			Amount:      amount,
+			Description: fmt.Sprintf("Pembelian bahan baku dari %s (faktur: %s)", grn.PurchaseOrder.Supplier.Name, invoice.InvoiceNumber),
+			Reference:   grn.GRNNumber,
+			IsPayable:   true,
+			CreatedBy:   userID,
+		}
+		if err := s.cashFlowService.CreateCashFlowEntryWithTx(tx, &entry); err != nil {
+			return nil, err
+		}
+		return &entry, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if amount <= 0 {
+		if err := s.ledgerService.ReverseSourceWithTx(tx, JournalSourceCashFlow, entry.TransactionID,
+			"Pembatalan faktur "+invoice.InvoiceNumber, userID); err != nil {
+			return nil, err
+		}
+		return nil, tx.Delete(&entry).Error
+	}
+	if entry.Amount == amount && entry.IsPayable {
+		return nil, nil
+	}
+
+	if err := tx.Model(&models.CashFlowEntry{}).Where("id = ?", entry.ID).Updates(map[string]interface{}{
+		"amount":     amount,
+		"is_payable": true,
+	}).Error; err != nil {
+		return nil, err
+	}
+	entry.Amount = amount
+	entry.IsPayable = true
+	if err := s.ledgerService.RepostCashFlowEntryWithTx(tx, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// loadInvoiceWithTx loads an invoice together with its goods receipt
+func (s *AccountsPayableService) loadInvoiceWithTx(tx *gorm.DB, id uint) (*models.SupplierInvoice, *models.GoodsReceipt, error) {
+	var invoice models.SupplierInvoice
+	if err := tx.First(&invoice, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrSupplierInvoiceNotFound
+		}
+		return nil, nil, err
+	}
+
+	var grn models.GoodsReceipt
+	if err := tx.Preload("PurchaseOrder.Supplier").First(&grn, invoice.GRNID).Error; err != nil {
+		return nil, nil, err
+	}
+	return &invoice, &grn, nil
+}
+
+// generatePaymentNumber generates a payment number in the format PAY-YYYYMMDD-XXXX
+func (s *AccountsPayableService) generatePaymentNumber(tx *gorm.DB, date time.Time) (string, error) {
+	prefix := fmt.Sprintf("PAY-%s-", date.Format("20060102"))
+
+	var count int64
+	if err := tx.Model(&models.SupplierPayment{}).Where("payment_number LIKE ?", prefix+"%").Count(&count).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%04d", prefix, count+1), nil
+}
+
+// variancePercent returns the difference of actual from expected in percent of expected
+func variancePercent(actual, expected float64) float64 {
+	if expected == 0 {
+		if actual == 0 {
+			return 0
+		}
+		return 100
+	}
+	return roundPercent((actual - expected) / expected * 100)
+}
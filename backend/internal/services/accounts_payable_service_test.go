@@ -0,0 +1,258 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupAPTestDB uses a file database because goods receipts generate their
+// numbers outside the receipt transaction
+func setupAPTestDB(t *testing.T) *gorm.DB {
+	db, err := openTestDB(t, filepath.Join(t.TempDir(), "ap.db"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Supplier{}, &models.Ingredient{}, &models.PurchaseOrder{},
+		&models.PurchaseOrderItem{}, &models.GoodsReceipt{}, &models.GoodsReceiptItem{}, &models.InventoryItem{},
+		&models.InventoryMovement{}, &models.CashFlowEntry{}, &models.Account{}, &models.JournalEntry{},
+		&models.JournalLine{}, &models.AuditTrail{}, &models.SystemConfig{}, &models.SupplierInvoice{},
+		&models.SupplierInvoiceItem{}, &models.SupplierPayment{})
+	require.NoError(t, err)
+
+	require.NoError(t, NewLedgerService(db).SeedDefaultAccounts())
+	return db
+}
+
+// createAPReceipt creates an approved purchase order of 100 kg beras at 10.000
+// and 50 kg ayam at 20.000, and receives it with the given quantities
+func createAPReceipt(t *testing.T, db *gorm.DB, beras, ayam float64) (*models.GoodsReceipt, []models.Ingredient) {
+	supplier := models.Supplier{Name: "CV Sumber Pangan", IsActive: true, PaymentTermDays: 30}
+	require.NoError(t, db.Create(&supplier).Error)
+	ingredients := []models.Ingredient{{Name: "Beras", Unit: "kg"}, {Name: "Ayam", Unit: "kg"}}
+	require.NoError(t, db.Create(&ingredients).Error)
+
+	po := models.PurchaseOrder{
+		PONumber:    "PO-TEST-0001",
+		SupplierID:  supplier.ID,
+		OrderDate:   time.Now(),
+		Status:      "approved",
+		TotalAmount: models.Rupiah(2000000),
+		CreatedBy:   1,
+		POItems: []models.PurchaseOrderItem{
+			{IngredientID: ingredients[0].ID, Quantity: 100, UnitPrice: models.Rupiah(10000), Subtotal: models.Rupiah(1000000)},
+			{IngredientID: ingredients[1].ID, Quantity: 50, UnitPrice: models.Rupiah(20000), Subtotal: models.Rupiah(1000000)},
+		},
+	}
+	require.NoError(t, db.Create(&po).Error)
+
+	grnService := NewGoodsReceiptService(db, NewInventoryService(db), NewCashFlowService(db))
+	grn := &models.GoodsReceipt{POID: po.ID}
+	items := []models.GoodsReceiptItem{
+		{IngredientID: ingredients[0].ID, ReceivedQuantity: beras},
+		{IngredientID: ingredients[1].ID, ReceivedQuantity: ayam},
+	}
+	require.NoError(t, grnService.CreateGoodsReceipt(grn, items, 1))
+	return grn, ingredients
+}
+
+func grnExpense(t *testing.T, db *gorm.DB, grn *models.GoodsReceipt) models.CashFlowEntry {
+	var entry models.CashFlowEntry
+	require.NoError(t, db.Where("reference = ? AND category = ?", grn.GRNNumber, "bahan_baku").First(&entry).Error)
+	return entry
+}
+
+func TestAccountsPayableService_ShortReceiptMatchedInvoiceAndPartialPayments(t *testing.T) {
+	db := setupAPTestDB(t)
+	ledger := NewLedgerService(db)
+	service := NewAccountsPayableService(db, NewCashFlowService(db))
+
+	// Only 80 of 100 kg beras arrived: the expense is the received value, owed to the supplier
+	grn, ingredients := createAPReceipt(t, db, 80, 50)
+	entry := grnExpense(t, db, grn)
+	assert.Equal(t, models.Rupiah(1800000), entry.Amount)
+	assert.True(t, entry.IsPayable)
+	assert.Equal(t, models.Rupiah(-1800000), ledgerBalance(t, ledger, AccountCodeAccountsPayable))
+	assert.Equal(t, models.Rupiah(0), ledgerBalance(t, ledger, AccountCodeCash))
+
+	invoiceDate := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	invoice := &models.SupplierInvoice{
+		GRNID:         grn.ID,
+		InvoiceNumber: "INV/SP/0301",
+		InvoiceDate:   invoiceDate,
+		Items: []models.SupplierInvoiceItem{
+			{IngredientID: ingredients[0].ID, Quantity: 80, UnitPrice: models.Rupiah(10100)}, // 1% above PO price, within tolerance
+			{IngredientID: ingredients[1].ID, Quantity: 50, UnitPrice: models.Rupiah(20000)},
+		},
+	}
+	require.NoError(t, service.CreateInvoice(invoice, 1))
+	assert.Equal(t, InvoiceStatusOpen, invoice.Status)
+	assert.Equal(t, MatchStatusMatched, invoice.MatchStatus)
+	assert.Equal(t, models.Rupiah(1808000), invoice.TotalAmount)
+	assert.Equal(t, models.Rupiah(1800000), invoice.ReceivedAmount)
+	assert.Equal(t, invoiceDate.AddDate(0, 0, 30), invoice.DueDate)
+	assert.Equal(t, 80.0, invoice.Items[0].ReceivedQuantity)
+	assert.Equal(t, 1.0, invoice.Items[0].PriceVariance)
+
+	// The purchase expense now follows the invoice
+	assert.Equal(t, models.Rupiah(1808000), grnExpense(t, db, grn).Amount)
+	assert.Equal(t, models.Rupiah(-1808000), ledgerBalance(t, ledger, AccountCodeAccountsPayable))
+	assert.Equal(t, models.Rupiah(1808000), ledgerBalance(t, ledger, AccountCodeRawMaterialExpense))
+
+	duplicate := &models.SupplierInvoice{GRNID: grn.ID, InvoiceNumber: "INV/SP/0302", Items: invoice.Items}
+	assert.ErrorIs(t, service.CreateInvoice(duplicate, 1), ErrGRNAlreadyInvoiced)
+
+	paymentDate := time.Date(2026, 3, 20, 0, 0, 0, 0, time.Local)
+	assert.ErrorIs(t, service.RecordPayment(invoice.ID, &models.SupplierPayment{Amount: models.Rupiah(1000000), Method: "transfer", PaymentDate: paymentDate}, 1), ErrBankReferenceRequired)
+
+	first := &models.SupplierPayment{Amount: models.Rupiah(1000000), Method: "transfer", BankReference: "TRF-20260320-01", PaymentDate: paymentDate}
+	require.NoError(t, service.RecordPayment(invoice.ID, first, 1))
+	assert.Equal(t, "PAY-20260320-0001", first.PaymentNumber)
+
+	stored, err := service.GetInvoice(invoice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, InvoiceStatusPartiallyPaid, stored.Status)
+	assert.Equal(t, models.Rupiah(1000000), stored.PaidAmount)
+
+	over := &models.SupplierPayment{Amount: models.Rupiah(900000), Method: "cash", PaymentDate: paymentDate}
+	assert.ErrorIs(t, service.RecordPayment(invoice.ID, over, 1), ErrInvalidPaymentAmount)
+
+	second := &models.SupplierPayment{Amount: models.Rupiah(808000), Method: "cash", PaymentDate: paymentDate.AddDate(0, 0, 5)}
+	require.NoError(t, service.RecordPayment(invoice.ID, second, 1))
+
+	stored, err = service.GetInvoice(invoice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, InvoiceStatusPaid, stored.Status)
+	assert.Len(t, stored.Payments, 2)
+	assert.Equal(t, "TRF-20260320-01", stored.Payments[0].BankReference)
+
+	assert.Equal(t, models.Rupiah(0), ledgerBalance(t, ledger, AccountCodeAccountsPayable))
+	assert.Equal(t, models.Rupiah(-1000000), ledgerBalance(t, ledger, AccountCodeBank))
+	assert.Equal(t, models.Rupiah(-808000), ledgerBalance(t, ledger, AccountCodeCash))
+
+	third := &models.SupplierPayment{Amount: models.Rupiah(1), Method: "cash"}
+	assert.ErrorIs(t, service.RecordPayment(invoice.ID, third, 1), ErrInvoiceNotPayable)
+}
+
+func TestAccountsPayableService_MismatchHoldApproveAndCancel(t *testing.T) {
+	db := setupAPTestDB(t)
+	ledger := NewLedgerService(db)
+	service := NewAccountsPayableService(db, NewCashFlowService(db))
+
+	grn, ingredients := createAPReceipt(t, db, 100, 50)
+	assert.Equal(t, models.Rupiah(2000000), grnExpense(t, db, grn).Amount)
+
+	invoice := &models.SupplierInvoice{
+		GRNID:         grn.ID,
+		InvoiceNumber: "INV-778",
+		InvoiceDate:   time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local),
+		DueDate:       time.Date(2026, 3, 16, 0, 0, 0, 0, time.Local),
+		Items: []models.SupplierInvoiceItem{
+			{IngredientID: ingredients[0].ID, Quantity: 100, UnitPrice: models.Rupiah(11000)}, // 10% above PO price
+			{IngredientID: ingredients[1].ID, Quantity: 55, UnitPrice: models.Rupiah(20000)},  // more than received
+		},
+	}
+	require.NoError(t, service.CreateInvoice(invoice, 1))
+	assert.Equal(t, InvoiceStatusOnHold, invoice.Status)
+	assert.Equal(t, MatchStatusMismatch, invoice.MatchStatus)
+	assert.Equal(t, MatchStatusMismatch, invoice.Items[0].MatchStatus)
+	assert.Contains(t, invoice.Items[0].MatchNotes, "harga faktur")
+	assert.Contains(t, invoice.Items[1].MatchNotes, "jumlah faktur")
+	assert.Equal(t, time.Date(2026, 3, 16, 0, 0, 0, 0, time.Local), invoice.DueDate)
+
+	// Held invoices cannot be paid and do not change the expense
+	payment := &models.SupplierPayment{Amount: models.Rupiah(100000), Method: "cash"}
+	assert.ErrorIs(t, service.RecordPayment(invoice.ID, payment, 1), ErrInvoiceNotPayable)
+	assert.Equal(t, models.Rupiah(2000000), grnExpense(t, db, grn).Amount)
+
+	_, err := service.ApproveInvoice(invoice.ID, " ", 1)
+	assert.ErrorIs(t, err, ErrApprovalReasonRequired)
+
+	approved, err := service.ApproveInvoice(invoice.ID, "Harga pasar naik, disetujui kepala SPPG", 2)
+	require.NoError(t, err)
+	assert.Equal(t, InvoiceStatusOpen, approved.Status)
+	assert.Equal(t, MatchStatusOverridden, approved.MatchStatus)
+	require.NotNil(t, approved.ApprovedBy)
+	assert.Equal(t, uint(2), *approved.ApprovedBy)
+	assert.Equal(t, models.Rupiah(2200000), grnExpense(t, db, grn).Amount)
+	assert.Equal(t, models.Rupiah(-2200000), ledgerBalance(t, ledger, AccountCodeAccountsPayable))
+
+	_, err = service.ApproveInvoice(invoice.ID, "lagi", 2)
+	assert.ErrorIs(t, err, ErrInvoiceNotOnHold)
+
+	// Cancelling restores the received value and frees the goods receipt and invoice number
+	require.NoError(t, service.CancelInvoice(invoice.ID, "Faktur salah harga", 1))
+	assert.Equal(t, models.Rupiah(2000000), grnExpense(t, db, grn).Amount)
+	assert.Equal(t, models.Rupiah(-2000000), ledgerBalance(t, ledger, AccountCodeAccountsPayable))
+	assert.ErrorIs(t, service.CancelInvoice(invoice.ID, "", 1), ErrInvoiceAlreadyCancelled)
+
+	corrected := &models.SupplierInvoice{
+		GRNID:         grn.ID,
+		InvoiceNumber: "INV-778",
+		InvoiceDate:   time.Date(2026, 3, 3, 0, 0, 0, 0, time.Local),
+		Items: []models.SupplierInvoiceItem{
+			{IngredientID: ingredients[0].ID, Quantity: 100, UnitPrice: models.Rupiah(10000)},
+			{IngredientID: ingredients[1].ID, Quantity: 50, UnitPrice: models.Rupiah(20000)},
+		},
+	}
+	require.NoError(t, service.CreateInvoice(corrected, 1))
+	assert.Equal(t, InvoiceStatusOpen, corrected.Status)
+
+	invoices, err := service.GetInvoices(0, InvoiceStatusCancelled)
+	require.NoError(t, err)
+	require.Len(t, invoices, 1)
+	assert.Equal(t, invoice.ID, invoices[0].ID)
+}
+
+func TestAccountsPayableService_AgingReport(t *testing.T) {
+	db := setupAPTestDB(t)
+	service := NewAccountsPayableService(db, NewCashFlowService(db))
+
+	suppliers := []models.Supplier{{Name: "UD Tani Makmur", IsActive: true}, {Name: "CV Segar Jaya", IsActive: true}}
+	require.NoError(t, db.Create(&suppliers).Error)
+
+	date := func(month, day int) time.Time { return time.Date(2026, time.Month(month), day, 0, 0, 0, 0, time.Local) }
+	invoices := []models.SupplierInvoice{
+		{InvoiceNumber: "A-1", SupplierID: suppliers[0].ID, InvoiceDate: date(6, 10), DueDate: date(7, 10), TotalAmount: models.Rupiah(1000000), Status: InvoiceStatusOpen},
+		{InvoiceNumber: "A-2", SupplierID: suppliers[0].ID, InvoiceDate: date(5, 16), DueDate: date(6, 15), TotalAmount: models.Rupiah(500000), Status: InvoiceStatusPartiallyPaid},
+		{InvoiceNumber: "B-1", SupplierID: suppliers[1].ID, InvoiceDate: date(3, 2), DueDate: date(4, 1), TotalAmount: models.Rupiah(750000), Status: InvoiceStatusOnHold},
+		{InvoiceNumber: "B-2", SupplierID: suppliers[1].ID, InvoiceDate: date(1, 2), DueDate: date(2, 1), TotalAmount: models.Rupiah(250000), Status: InvoiceStatusOpen},
+		{InvoiceNumber: "B-3", SupplierID: suppliers[1].ID, InvoiceDate: date(1, 2), DueDate: date(2, 1), TotalAmount: models.Rupiah(900000), Status: InvoiceStatusCancelled},
+		{InvoiceNumber: "B-4", SupplierID: suppliers[1].ID, InvoiceDate: date(7, 2), DueDate: date(8, 1), TotalAmount: models.Rupiah(400000), Status: InvoiceStatusOpen},
+	}
+	for i := range invoices {
+		invoices[i].MatchStatus = MatchStatusMatched
+		invoices[i].CreatedBy = 1
+	}
+	require.NoError(t, db.Create(&invoices).Error)
+
+	payments := []models.SupplierPayment{
+		{PaymentNumber: "PAY-1", InvoiceID: invoices[1].ID, SupplierID: suppliers[0].ID, PaymentDate: date(6, 20), Amount: models.Rupiah(200000), Method: "cash", CreatedBy: 1},
+		{PaymentNumber: "PAY-2", InvoiceID: invoices[0].ID, SupplierID: suppliers[0].ID, PaymentDate: date(7, 5), Amount: models.Rupiah(100000), Method: "cash", CreatedBy: 1},
+	}
+	require.NoError(t, db.Create(&payments).Error)
+
+	report, err := service.GetAgingReport(date(6, 30))
+	require.NoError(t, err)
+	require.Len(t, report.Suppliers, 2)
+
+	tani := report.Suppliers[0]
+	assert.Equal(t, "UD Tani Makmur", tani.SupplierName)
+	assert.Equal(t, 2, tani.InvoiceCount)
+	assert.Equal(t, models.Rupiah(1000000), tani.Current)
+	assert.Equal(t, models.Rupiah(300000), tani.Days1To30)
+	assert.Equal(t, models.Rupiah(1300000), tani.Total)
+
+	segar := report.Suppliers[1]
+	assert.Equal(t, 2, segar.InvoiceCount)
+	assert.Equal(t, models.Rupiah(750000), segar.Days61To90)
+	assert.Equal(t, models.Rupiah(250000), segar.Over90)
+	assert.Equal(t, models.Rupiah(1000000), segar.Total)
+
+	assert.Equal(t, models.Rupiah(2300000), report.Totals.Total)
+	assert.Equal(t, models.Rupiah(0), report.Totals.Days31To60)
+}
@@ -101,7 +101,7 @@ func (s *ActivityTrackerService) GetOrdersByDate(ctx context.Context, date time.
 		query = query.Joins("JOIN schools ON schools.id = delivery_records.school_id").
 			Joins("JOIN menu_items ON menu_items.id = delivery_records.menu_item_id").
 			Joins("JOIN recipes ON recipes.id = menu_items.recipe_id").
-			Where("schools.name ILIKE ? OR recipes.name ILIKE ?", searchPattern, searchPattern)
+			Where("LOWER(schools.name) LIKE LOWER(?) OR LOWER(recipes.name) LIKE LOWER(?)", searchPattern, searchPattern)
 	}
 	
 	// Execute query
@@ -348,7 +348,7 @@ func (s *ActivityTrackerService) buildTimeline(currentStage int, transitions []m
 				stage.Media = &MediaInfo{
 					Type:         transition.MediaType,
 					URL:          transition.MediaURL,
-					ThumbnailURL: transition.MediaURL, // TODO: Generate actual thumbnail
+					ThumbnailURL: transition.ThumbnailURL,
 				}
 			}
 		}
@@ -469,7 +469,7 @@ func (s *ActivityTrackerService) GetActivityLog(ctx context.Context, orderID uin
 }
 
 // AttachStageMedia attaches photo or video URL to a specific stage transition
-func (s *ActivityTrackerService) AttachStageMedia(ctx context.Context, orderID uint, stage int, mediaURL string, mediaType string) error {
+func (s *ActivityTrackerService) AttachStageMedia(ctx context.Context, orderID uint, stage int, mediaURL string, thumbnailURL string, mediaType string) error {
 	// Find the status transition for this stage
 	var transition models.StatusTransition
 	if err := s.db.WithContext(ctx).
@@ -483,8 +483,9 @@ func (s *ActivityTrackerService) AttachStageMedia(ctx context.Context, orderID u
 	
 	// Update media fields
 	if err := s.db.WithContext(ctx).Model(&transition).Updates(map[string]interface{}{
-		"media_url":  mediaURL,
-		"media_type": mediaType,
+		"media_url":     mediaURL,
+		"thumbnail_url": thumbnailURL,
+		"media_type":    mediaType,
 	}).Error; err != nil {
 		log.Printf("Error attaching media to stage %d: %v", stage, err)
 		return fmt.Errorf("failed to attach media: %w", err)
@@ -539,6 +540,7 @@ func (s *ActivityTrackerService) SyncOrderToFirebase(ctx context.Context, orderI
 			
 			if stage.Media != nil {
 				transition["media_url"] = stage.Media.URL
+				transition["thumbnail_url"] = stage.Media.ThumbnailURL
 				transition["media_type"] = stage.Media.Type
 			}
 			
@@ -613,29 +615,6 @@ func (s *ActivityTrackerService) validatePickupStageTransition(currentStage, new
 	return nil
 }
 
-// HandleKDSStatusUpdate processes status updates from KDS modules
-func (s *ActivityTrackerService) HandleKDSStatusUpdate(ctx context.Context, orderID uint, kdsStatus string, userID uint) error {
-	// Map KDS status to Activity Tracker status and stage
-	statusMap := map[string]struct {
-		status string
-		stage  int
-	}{
-		"cooking":           {"order_dimasak", 2},
-		"ready":             {"order_dikemas", 3},
-		"packing_completed": {"order_siap_diambil", 4},
-	}
-	
-	mapping, exists := statusMap[kdsStatus]
-	if !exists {
-		log.Printf("Warning: Unknown KDS status: %s", kdsStatus)
-		return fmt.Errorf("unknown KDS status: %s", kdsStatus)
-	}
-	
-	// Update order status
-	notes := fmt.Sprintf("Status updated from KDS module: %s", kdsStatus)
-	return s.UpdateOrderStatus(ctx, orderID, mapping.status, mapping.stage, userID, notes)
-}
-
 // HandleLogisticsStatusUpdate processes status updates from Logistics/Delivery module
 func (s *ActivityTrackerService) HandleLogisticsStatusUpdate(ctx context.Context, orderID uint, logisticsStatus string, userID uint) error {
 	// Map Logistics status to Activity Tracker status and stage
@@ -8,13 +8,12 @@ import (
 	"github.com/erp-sppg/backend/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 // setupTestDB creates an in-memory SQLite database for testing
 func setupActivityTrackerTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
 	require.NoError(t, err)
 
 	// Auto-migrate the schema
@@ -0,0 +1,646 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/jung-kurt/gofpdf"
+	qrcode "github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAssetAuditNotFound      = errors.New("audit aset tidak ditemukan")
+	ErrAssetAuditNotInProgress = errors.New("audit aset sudah diajukan dan tidak dapat diubah")
+	ErrAssetAuditNotSubmitted  = errors.New("audit aset belum diajukan atau sudah diproses")
+	ErrAssetAuditInProgress    = errors.New("masih ada audit aset yang berjalan untuk lokasi ini")
+	ErrNoAssetsToAudit         = errors.New("tidak ada aset aktif untuk diaudit")
+	ErrNoAssetsScanned         = errors.New("belum ada aset yang dipindai")
+	ErrInvalidAssetCondition   = errors.New("kondisi aset tidak valid (good, fair atau poor)")
+	ErrAuditLocationRequired   = errors.New("lokasi aset wajib diisi")
+	ErrNoAssetLabels           = errors.New("tidak ada aset untuk dicetak labelnya")
+)
+
+// Asset audit statuses
+const (
+	AssetAuditStatusInProgress = "in_progress"
+	AssetAuditStatusSubmitted  = "submitted"
+	AssetAuditStatusApproved   = "approved"
+	AssetAuditStatusRejected   = "rejected"
+)
+
+// Asset audit item results
+const (
+	AssetAuditResultPending = "pending"
+	AssetAuditResultFound   = "found"
+	AssetAuditResultMoved   = "moved"
+	AssetAuditResultMissing = "missing"
+)
+
+// Asset label sheet layout: 3 x 8 labels on A4 portrait
+const (
+	assetLabelColumns = 3
+	assetLabelRows    = 8
+	assetLabelWidth   = 70.0
+	assetLabelHeight  = 37.125
+	assetLabelQRSize  = 30.0
+)
+
+// AssetAuditService handles asset labels and physical asset audits. An audit
+// lists the active assets expected at a location; scanning a label records
+// where the asset was found and its condition, unscanned assets are reported
+// missing on submission, and approval by the Kepala SPPG applies the found
+// locations and conditions to the assets.
+type AssetAuditService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+// NewAssetAuditService creates a new asset audit service. notificationService
+// may be nil, in which case notifications are stored without a realtime push.
+func NewAssetAuditService(db *gorm.DB, notificationService *NotificationService) *AssetAuditService {
+	return &AssetAuditService{
+		db:                  db,
+		notificationService: notificationService,
+	}
+}
+
+// AssetScan represents a scanned asset label with what was found
+type AssetScan struct {
+	AssetCode string `json:"asset_code"`
+	Location  string `json:"location"`  // defaults to the audited location
+	Condition string `json:"condition"` // defaults to the recorded condition
+	Notes     string `json:"notes"`
+}
+
+// AssetLabelFilter selects the assets to print labels for
+type AssetLabelFilter struct {
+	AssetIDs []uint
+	Category string
+	Location string
+}
+
+// AssetAuditReport summarizes the findings of an audit
+type AssetAuditReport struct {
+	Audit            *models.AssetAudit      `json:"audit"`
+	TotalAssets      int                     `json:"total_assets"`
+	Found            int                     `json:"found"`
+	Moved            int                     `json:"moved"`
+	Missing          int                     `json:"missing"`
+	Pending          int                     `json:"pending"`
+	ConditionChanges int                     `json:"condition_changes"`
+	MovedItems       []models.AssetAuditItem `json:"moved_items"`
+	MissingItems     []models.AssetAuditItem `json:"missing_items"`
+	ConditionItems   []models.AssetAuditItem `json:"condition_items"`
+}
+
+// StartAudit starts an audit of the active assets at a location, or of all
+// active assets when no location is given
+func (s *AssetAuditService) StartAudit(audit *models.AssetAudit, userID uint) error {
+	audit.Location = strings.TrimSpace(audit.Location)
+
+	var running int64
+	query := s.db.Model(&models.AssetAudit{}).Where("status = ?", AssetAuditStatusInProgress)
+	if audit.Location != "" {
+		query = query.Where("location = ? OR location = ''", audit.Location)
+	}
+	if err := query.Count(&running).Error; err != nil {
+		return err
+	}
+	if running > 0 {
+		return ErrAssetAuditInProgress
+	}
+
+	assetQuery := s.db.Where("status = ?", AssetStatusActive)
+	if audit.Location != "" {
+		assetQuery = assetQuery.Where("location = ?", audit.Location)
+	}
+	var assets []models.KitchenAsset
+	if err := assetQuery.Order("asset_code ASC").Find(&assets).Error; err != nil {
+		return err
+	}
+	if len(assets) == 0 {
+		return ErrNoAssetsToAudit
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		number, err := s.generateAuditNumber(tx, time.Now())
+		if err != nil {
+			return err
+		}
+
+		audit.ID = 0
+		audit.AuditNumber = number
+		audit.Status = AssetAuditStatusInProgress
+		audit.CreatedBy = userID
+		audit.SubmittedAt = nil
+		audit.ApprovedBy = nil
+		audit.ApprovedAt = nil
+		audit.Items = nil
+		if err := tx.Create(audit).Error; err != nil {
+			return err
+		}
+
+		items := make([]models.AssetAuditItem, len(assets))
+		for i, asset := range assets {
+			items[i] = models.AssetAuditItem{
+				AuditID:           audit.ID,
+				AssetID:           asset.ID,
+				ExpectedLocation:  asset.Location,
+				ExpectedCondition: asset.Condition,
+				Result:            AssetAuditResultPending,
+			}
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return err
+		}
+		audit.Items = items
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "asset_audit", audit.AuditNumber, nil, audit, "")
+	return nil
+}
+
+// ScanAsset records a scanned asset in an audit. An active asset that was not
+// expected in the audit, for example one found at the audited location but
+// registered elsewhere, is added to it. Scanning an asset again replaces the
+// earlier finding.
+func (s *AssetAuditService) ScanAsset(auditID uint, scan AssetScan, userID uint) (*models.AssetAuditItem, error) {
+	audit, err := s.findAudit(s.db, auditID)
+	if err != nil {
+		return nil, err
+	}
+	if audit.Status != AssetAuditStatusInProgress {
+		return nil, ErrAssetAuditNotInProgress
+	}
+
+	location := strings.TrimSpace(scan.Location)
+	if location == "" {
+		location = audit.Location
+	}
+	if location == "" {
+		return nil, ErrAuditLocationRequired
+	}
+	condition := strings.TrimSpace(scan.Condition)
+	if condition != "" && !validAssetCondition(condition) {
+		return nil, ErrInvalidAssetCondition
+	}
+
+	var asset models.KitchenAsset
+	if err := s.db.Where("asset_code = ?", strings.TrimSpace(scan.AssetCode)).First(&asset).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssetNotFound
+		}
+		return nil, err
+	}
+	if asset.Status != AssetStatusActive {
+		return nil, ErrAssetNotActive
+	}
+
+	var item models.AssetAuditItem
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("audit_id = ? AND asset_id = ?", auditID, asset.ID).First(&item).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			item = models.AssetAuditItem{
+				AuditID:           auditID,
+				AssetID:           asset.ID,
+				ExpectedLocation:  asset.Location,
+				ExpectedCondition: asset.Condition,
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if condition == "" {
+			condition = item.ExpectedCondition
+		}
+		now := time.Now()
+		item.FoundLocation = location
+		item.FoundCondition = condition
+		item.Result = AssetAuditResultFound
+		if !strings.EqualFold(strings.TrimSpace(item.ExpectedLocation), location) {
+			item.Result = AssetAuditResultMoved
+		}
+		item.ScannedBy = &userID
+		item.ScannedAt = &now
+		item.Notes = strings.TrimSpace(scan.Notes)
+		return tx.Save(&item).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	item.Asset = asset
+	return &item, nil
+}
+
+// SubmitAudit closes the scanning of an audit, reports the assets not scanned
+// as missing and asks the Kepala SPPG for approval
+func (s *AssetAuditService) SubmitAudit(id uint, userID uint) (*models.AssetAudit, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		audit, err := s.findAudit(tx, id)
+		if err != nil {
+			return err
+		}
+		if audit.Status != AssetAuditStatusInProgress {
+			return ErrAssetAuditNotInProgress
+		}
+
+		var scanned int64
+		if err := tx.Model(&models.AssetAuditItem{}).
+			Where("audit_id = ? AND result <> ?", id, AssetAuditResultPending).
+			Count(&scanned).Error; err != nil {
+			return err
+		}
+		if scanned == 0 {
+			return ErrNoAssetsScanned
+		}
+
+		if err := tx.Model(&models.AssetAuditItem{}).
+			Where("audit_id = ? AND result = ?", id, AssetAuditResultPending).
+			Updates(map[string]interface{}{"result": AssetAuditResultMissing, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		return tx.Model(&models.AssetAudit{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":       AssetAuditStatusSubmitted,
+			"submitted_at": now,
+			"updated_at":   now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.GetAuditReport(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var approvers []models.User
+	if err := s.db.Where("role = ? AND is_active = ? AND id <> ?", "kepala_sppg", true, userID).Find(&approvers).Error; err != nil {
+		return nil, err
+	}
+	for _, approver := range approvers {
+		s.notify(approver.ID, "Audit Aset Menunggu Persetujuan",
+			fmt.Sprintf("Audit aset %s: %d ditemukan, %d berpindah, %d hilang", report.Audit.AuditNumber,
+				report.Found, report.Moved, report.Missing), report.Audit.ID)
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "submit", "asset_audit", report.Audit.AuditNumber, nil, report.Audit, "")
+	return report.Audit, nil
+}
+
+// ApproveAudit approves a submitted audit and applies the found locations and
+// conditions to the assets. Missing assets are left unchanged and stay on the
+// audit report for follow-up.
+func (s *AssetAuditService) ApproveAudit(id uint, approverID uint) (*models.AssetAudit, error) {
+	audit, err := s.GetAudit(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkApprover(audit, approverID); err != nil {
+		return nil, err
+	}
+
+	type adjustment struct {
+		old models.KitchenAsset
+		new models.KitchenAsset
+	}
+	var adjustments []adjustment
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range audit.Items {
+			if item.Result != AssetAuditResultFound && item.Result != AssetAuditResultMoved {
+				continue
+			}
+
+			var asset models.KitchenAsset
+			if err := tx.First(&asset, item.AssetID).Error; err != nil {
+				return err
+			}
+			if asset.Status != AssetStatusActive {
+				continue
+			}
+			adjusted := asset
+			if item.Result == AssetAuditResultMoved {
+				adjusted.Location = item.FoundLocation
+			}
+			if item.FoundCondition != "" {
+				adjusted.Condition = item.FoundCondition
+			}
+			if adjusted.Location == asset.Location && adjusted.Condition == asset.Condition {
+				continue
+			}
+
+			if err := tx.Model(&models.KitchenAsset{}).Where("id = ?", asset.ID).Updates(map[string]interface{}{
+				"location":   adjusted.Location,
+				"condition":  adjusted.Condition,
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+			adjustments = append(adjustments, adjustment{old: asset, new: adjusted})
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.AssetAudit{}).
+			Where("id = ? AND status = ?", id, AssetAuditStatusSubmitted).
+			Updates(map[string]interface{}{
+				"status":      AssetAuditStatusApproved,
+				"approved_by": approverID,
+				"approved_at": now,
+				"updated_at":  now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAssetAuditNotSubmitted
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	auditTrail := NewAuditTrailService(s.db)
+	for _, adj := range adjustments {
+		auditTrail.RecordAction(approverID, "audit_adjust", "kitchen_asset", adj.old.AssetCode, adj.old, adj.new, "")
+	}
+	approved, err := s.GetAudit(id)
+	if err != nil {
+		return nil, err
+	}
+	auditTrail.RecordAction(approverID, "approve", "asset_audit", approved.AuditNumber, audit, approved, "")
+
+	s.notify(approved.CreatedBy, "Audit Aset Disetujui",
+		fmt.Sprintf("Audit aset %s disetujui, %d aset diperbarui", approved.AuditNumber, len(adjustments)), approved.ID)
+	return approved, nil
+}
+
+// RejectAudit rejects a submitted audit; the assets are left unchanged
+func (s *AssetAuditService) RejectAudit(id uint, approverID uint, reason string) (*models.AssetAudit, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrRejectionReasonRequired
+	}
+
+	audit, err := s.GetAudit(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkApprover(audit, approverID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := s.db.Model(&models.AssetAudit{}).
+		Where("id = ? AND status = ?", id, AssetAuditStatusSubmitted).
+		Updates(map[string]interface{}{
+			"status":           AssetAuditStatusRejected,
+			"approved_by":      approverID,
+			"approved_at":      now,
+			"rejection_reason": reason,
+			"updated_at":       now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrAssetAuditNotSubmitted
+	}
+
+	rejected, err := s.GetAudit(id)
+	if err != nil {
+		return nil, err
+	}
+	NewAuditTrailService(s.db).RecordAction(approverID, "reject", "asset_audit", rejected.AuditNumber, audit, rejected, "")
+	s.notify(rejected.CreatedBy, "Audit Aset Ditolak",
+		fmt.Sprintf("Audit aset %s ditolak: %s", rejected.AuditNumber, reason), rejected.ID)
+	return rejected, nil
+}
+
+// GetAudits retrieves asset audits, optionally with a given status
+func (s *AssetAuditService) GetAudits(status string) ([]models.AssetAudit, error) {
+	query := s.db.Preload("Creator").Preload("Approver")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var audits []models.AssetAudit
+	err := query.Order("created_at DESC, id DESC").Find(&audits).Error
+	return audits, err
+}
+
+// GetAudit retrieves an asset audit with its items
+func (s *AssetAuditService) GetAudit(id uint) (*models.AssetAudit, error) {
+	var audit models.AssetAudit
+	err := s.db.Preload("Creator").Preload("Approver").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Items.Asset").
+		First(&audit, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssetAuditNotFound
+		}
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// GetAuditReport summarizes the found, moved and missing assets and the
+// condition changes of an audit
+func (s *AssetAuditService) GetAuditReport(id uint) (*AssetAuditReport, error) {
+	audit, err := s.GetAudit(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AssetAuditReport{
+		Audit:          audit,
+		TotalAssets:    len(audit.Items),
+		MovedItems:     []models.AssetAuditItem{},
+		MissingItems:   []models.AssetAuditItem{},
+		ConditionItems: []models.AssetAuditItem{},
+	}
+	for _, item := range audit.Items {
+		switch item.Result {
+		case AssetAuditResultFound:
+			report.Found++
+		case AssetAuditResultMoved:
+			report.Moved++
+			report.MovedItems = append(report.MovedItems, item)
+		case AssetAuditResultMissing:
+			report.Missing++
+			report.MissingItems = append(report.MissingItems, item)
+		default:
+			report.Pending++
+		}
+		if item.FoundCondition != "" && item.FoundCondition != item.ExpectedCondition {
+			report.ConditionChanges++
+			report.ConditionItems = append(report.ConditionItems, item)
+		}
+	}
+	return report, nil
+}
+
+// GenerateAssetLabels renders a printable A4 sheet of QR code labels for the
+// selected active assets. Each QR code holds the asset code scanned during
+// an audit.
+func (s *AssetAuditService) GenerateAssetLabels(filter AssetLabelFilter) ([]byte, error) {
+	query := s.db.Where("status = ?", AssetStatusActive)
+	if len(filter.AssetIDs) > 0 {
+		query = query.Where("id IN ?", filter.AssetIDs)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Location != "" {
+		query = query.Where("location = ?", filter.Location)
+	}
+	var assets []models.KitchenAsset
+	if err := query.Order("asset_code ASC").Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	if len(assets) == 0 {
+		return nil, ErrNoAssetLabels
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	perPage := assetLabelColumns * assetLabelRows
+
+	for i, asset := range assets {
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+		position := i % perPage
+		x := float64(position%assetLabelColumns) * assetLabelWidth
+		y := float64(position/assetLabelColumns) * assetLabelHeight
+
+		png, err := qrcode.Encode(asset.AssetCode, qrcode.Medium, 256)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membuat QR code aset %s: %w", asset.AssetCode, err)
+		}
+		imageName := fmt.Sprintf("qr-%d", asset.ID)
+		pdf.RegisterImageOptionsReader(imageName, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+
+		// Light cutting guide around the label
+		pdf.SetDrawColor(200, 200, 200)
+		pdf.Rect(x, y, assetLabelWidth, assetLabelHeight, "D")
+
+		qrMargin := (assetLabelHeight - assetLabelQRSize) / 2
+		pdf.ImageOptions(imageName, x+qrMargin, y+qrMargin, assetLabelQRSize, assetLabelQRSize, false,
+			gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+		textX := x + assetLabelQRSize + 2*qrMargin
+		textWidth := assetLabelWidth - (textX - x) - qrMargin
+		pdf.SetXY(textX, y+6)
+		pdf.SetFont("Arial", "B", 10)
+		pdf.CellFormat(textWidth, 5, truncateToWidth(pdf, asset.AssetCode, textWidth), "", 2, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 8)
+		pdf.CellFormat(textWidth, 4, truncateToWidth(pdf, asset.Name, textWidth), "", 2, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 7)
+		if asset.Category != "" {
+			pdf.CellFormat(textWidth, 4, truncateToWidth(pdf, asset.Category, textWidth), "", 2, "L", false, 0, "")
+		}
+		if asset.Location != "" {
+			pdf.CellFormat(textWidth, 4, truncateToWidth(pdf, asset.Location, textWidth), "", 2, "L", false, 0, "")
+		}
+		pdf.SetFont("Arial", "I", 6)
+		pdf.CellFormat(textWidth, 4, "Aset SPPG - jangan dilepas", "", 2, "L", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("gagal membuat PDF label aset: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// checkApprover checks that an audit is submitted and that the approver is a
+// Kepala SPPG other than the auditor
+func (s *AssetAuditService) checkApprover(audit *models.AssetAudit, approverID uint) error {
+	if audit.Status != AssetAuditStatusSubmitted {
+		return ErrAssetAuditNotSubmitted
+	}
+
+	var approver models.User
+	if err := s.db.First(&approver, approverID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUnauthorized
+		}
+		return err
+	}
+	if approver.Role != "kepala_sppg" {
+		return ErrUnauthorized
+	}
+	if approverID == audit.CreatedBy {
+		return ErrSelfApproval
+	}
+	return nil
+}
+
+// findAudit retrieves an audit without its items
+func (s *AssetAuditService) findAudit(tx *gorm.DB, id uint) (*models.AssetAudit, error) {
+	var audit models.AssetAudit
+	if err := tx.First(&audit, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssetAuditNotFound
+		}
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// generateAuditNumber generates an audit number in the format AA-YYYYMMDD-XXXX
+func (s *AssetAuditService) generateAuditNumber(tx *gorm.DB, date time.Time) (string, error) {
+	prefix := fmt.Sprintf("AA-%s-", date.Format("20060102"))
+
+	var count int64
+	if err := tx.Model(&models.AssetAudit{}).Where("audit_number LIKE ?", prefix+"%").Count(&count).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%04d", prefix, count+1), nil
+}
+
+// notify sends an asset audit notification; failures are logged and ignored
+func (s *AssetAuditService) notify(userID uint, title, message string, auditID uint) {
+	notification := &models.Notification{
+		UserID:  userID,
+		Type:    NotificationTypeAssetAudit,
+		Title:   title,
+		Message: message,
+		Link:    fmt.Sprintf("/assets/audits/%d", auditID),
+	}
+
+	var err error
+	if s.notificationService != nil {
+		err = s.notificationService.CreateNotification(context.Background(), notification)
+	} else {
+		err = s.db.Create(notification).Error
+	}
+	if err != nil {
+		log.Printf("[ASSET AUDIT] gagal mengirim notifikasi audit aset: %v", err)
+	}
+}
+
+// validAssetCondition reports whether a condition is one of the asset conditions
+func validAssetCondition(condition string) bool {
+	switch condition {
+	case "good", "fair", "poor":
+		return true
+	}
+	return false
+}
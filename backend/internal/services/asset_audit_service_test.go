@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type assetAuditFixture struct {
+	db      *gorm.DB
+	service *AssetAuditService
+	kepala  models.User
+	auditor models.User
+	assets  map[string]*models.KitchenAsset
+}
+
+func setupAssetAuditTest(t *testing.T) assetAuditFixture {
+	db, err := openTestDB(t, filepath.Join(t.TempDir(), "asset_audit.db"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.KitchenAsset{}, &models.AssetMaintenance{},
+		&models.AssetAudit{}, &models.AssetAuditItem{}, &models.Account{}, &models.JournalEntry{}, &models.JournalLine{},
+		&models.AuditTrail{}, &models.Notification{})
+	require.NoError(t, err)
+	require.NoError(t, NewLedgerService(db).SeedDefaultAccounts())
+
+	kepala := models.User{NIK: "AA001", Email: "kepala@sppg.id", PasswordHash: "x", FullName: "Kepala SPPG", Role: "kepala_sppg", IsActive: true}
+	auditor := models.User{NIK: "AA002", Email: "akuntan@sppg.id", PasswordHash: "x", FullName: "Akuntan", Role: "akuntan", IsActive: true}
+	require.NoError(t, db.Create(&kepala).Error)
+	require.NoError(t, db.Create(&auditor).Error)
+
+	assetService := NewAssetService(db)
+	assets := map[string]*models.KitchenAsset{}
+	for _, a := range []struct{ code, name, location string }{
+		{"AST-001", "Kompor Gas 2 Tungku", "Dapur Utama"},
+		{"AST-002", "Rice Cooker 10L", "Dapur Utama"},
+		{"AST-003", "Freezer 300L", "Dapur Utama"},
+		{"AST-004", "Timbangan Digital", "Gudang"},
+	} {
+		asset := &models.KitchenAsset{AssetCode: a.code, Name: a.name, Category: "peralatan", Location: a.location,
+			PurchaseDate: time.Now().AddDate(0, -6, 0), PurchasePrice: models.Rupiah(2500000), UsefulLifeMonths: 48, Condition: "good"}
+		require.NoError(t, assetService.CreateAsset(asset))
+		assets[a.code] = asset
+	}
+
+	return assetAuditFixture{
+		db:      db,
+		service: NewAssetAuditService(db, nil),
+		kepala:  kepala,
+		auditor: auditor,
+		assets:  assets,
+	}
+}
+
+func TestAssetAuditService_AuditWorkflow(t *testing.T) {
+	f := setupAssetAuditTest(t)
+
+	audit := &models.AssetAudit{Location: "Dapur Utama", Notes: "Audit semester"}
+	require.NoError(t, f.service.StartAudit(audit, f.auditor.ID))
+	assert.Regexp(t, `^AA-\d{8}-0001$`, audit.AuditNumber)
+	assert.Equal(t, AssetAuditStatusInProgress, audit.Status)
+	assert.Len(t, audit.Items, 3)
+	assert.ErrorIs(t, f.service.StartAudit(&models.AssetAudit{Location: "Dapur Utama"}, f.auditor.ID), ErrAssetAuditInProgress)
+
+	_, err := f.service.SubmitAudit(audit.ID, f.auditor.ID)
+	assert.ErrorIs(t, err, ErrNoAssetsScanned)
+
+	// Found in place, found in poor condition, and one from the warehouse
+	item, err := f.service.ScanAsset(audit.ID, AssetScan{AssetCode: "AST-001"}, f.auditor.ID)
+	require.NoError(t, err)
+	assert.Equal(t, AssetAuditResultFound, item.Result)
+	assert.Equal(t, "good", item.FoundCondition)
+
+	item, err = f.service.ScanAsset(audit.ID, AssetScan{AssetCode: "AST-002", Condition: "poor", Notes: "Tutup retak"}, f.auditor.ID)
+	require.NoError(t, err)
+	assert.Equal(t, AssetAuditResultFound, item.Result)
+
+	item, err = f.service.ScanAsset(audit.ID, AssetScan{AssetCode: "AST-004"}, f.auditor.ID)
+	require.NoError(t, err)
+	assert.Equal(t, AssetAuditResultMoved, item.Result)
+	assert.Equal(t, "Gudang", item.ExpectedLocation)
+	assert.Equal(t, "Dapur Utama", item.FoundLocation)
+
+	_, err = f.service.ScanAsset(audit.ID, AssetScan{AssetCode: "AST-001", Condition: "rusak"}, f.auditor.ID)
+	assert.ErrorIs(t, err, ErrInvalidAssetCondition)
+	_, err = f.service.ScanAsset(audit.ID, AssetScan{AssetCode: "AST-999"}, f.auditor.ID)
+	assert.ErrorIs(t, err, ErrAssetNotFound)
+
+	// Unscanned assets are reported missing and the Kepala SPPG is notified
+	submitted, err := f.service.SubmitAudit(audit.ID, f.auditor.ID)
+	require.NoError(t, err)
+	assert.Equal(t, AssetAuditStatusSubmitted, submitted.Status)
+	_, err = f.service.ScanAsset(audit.ID, AssetScan{AssetCode: "AST-003"}, f.auditor.ID)
+	assert.ErrorIs(t, err, ErrAssetAuditNotInProgress)
+
+	report, err := f.service.GetAuditReport(audit.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.TotalAssets)
+	assert.Equal(t, 2, report.Found)
+	assert.Equal(t, 1, report.Moved)
+	assert.Equal(t, 1, report.Missing)
+	assert.Equal(t, 1, report.ConditionChanges)
+	require.Len(t, report.MissingItems, 1)
+	assert.Equal(t, "AST-003", report.MissingItems[0].Asset.AssetCode)
+
+	var notifications []models.Notification
+	require.NoError(t, f.db.Where("user_id = ? AND type = ?", f.kepala.ID, NotificationTypeAssetAudit).Find(&notifications).Error)
+	assert.Len(t, notifications, 1)
+
+	// Only the Kepala SPPG approves, and approval adjusts the assets
+	_, err = f.service.ApproveAudit(audit.ID, f.auditor.ID)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	approved, err := f.service.ApproveAudit(audit.ID, f.kepala.ID)
+	require.NoError(t, err)
+	assert.Equal(t, AssetAuditStatusApproved, approved.Status)
+
+	var cooker, scale, freezer models.KitchenAsset
+	require.NoError(t, f.db.First(&cooker, f.assets["AST-002"].ID).Error)
+	require.NoError(t, f.db.First(&scale, f.assets["AST-004"].ID).Error)
+	require.NoError(t, f.db.First(&freezer, f.assets["AST-003"].ID).Error)
+	assert.Equal(t, "poor", cooker.Condition)
+	assert.Equal(t, "Dapur Utama", scale.Location)
+	assert.Equal(t, "Dapur Utama", freezer.Location)
+	assert.Equal(t, AssetStatusActive, freezer.Status)
+
+	_, err = f.service.ApproveAudit(audit.ID, f.kepala.ID)
+	assert.ErrorIs(t, err, ErrAssetAuditNotSubmitted)
+}
+
+func TestAssetAuditService_RejectLeavesAssetsUnchanged(t *testing.T) {
+	f := setupAssetAuditTest(t)
+
+	audit := &models.AssetAudit{}
+	require.NoError(t, f.service.StartAudit(audit, f.auditor.ID))
+	assert.Len(t, audit.Items, 4)
+
+	// Auditing every asset needs the location of each scan
+	_, err := f.service.ScanAsset(audit.ID, AssetScan{AssetCode: "AST-001"}, f.auditor.ID)
+	assert.ErrorIs(t, err, ErrAuditLocationRequired)
+	_, err = f.service.ScanAsset(audit.ID, AssetScan{AssetCode: "AST-001", Location: "Gudang", Condition: "fair"}, f.auditor.ID)
+	require.NoError(t, err)
+	_, err = f.service.SubmitAudit(audit.ID, f.auditor.ID)
+	require.NoError(t, err)
+
+	_, err = f.service.RejectAudit(audit.ID, f.kepala.ID, " ")
+	assert.ErrorIs(t, err, ErrRejectionReasonRequired)
+	rejected, err := f.service.RejectAudit(audit.ID, f.kepala.ID, "Pemindaian belum lengkap")
+	require.NoError(t, err)
+	assert.Equal(t, AssetAuditStatusRejected, rejected.Status)
+
+	var stove models.KitchenAsset
+	require.NoError(t, f.db.First(&stove, f.assets["AST-001"].ID).Error)
+	assert.Equal(t, "Dapur Utama", stove.Location)
+	assert.Equal(t, "good", stove.Condition)
+}
+
+func TestAssetAuditService_GenerateAssetLabels(t *testing.T) {
+	f := setupAssetAuditTest(t)
+
+	data, err := f.service.GenerateAssetLabels(AssetLabelFilter{Location: "Dapur Utama"})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF")))
+
+	data, err = f.service.GenerateAssetLabels(AssetLabelFilter{AssetIDs: []uint{f.assets["AST-004"].ID}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, data)
+
+	_, err = f.service.GenerateAssetLabels(AssetLabelFilter{Category: "kendaraan"})
+	assert.ErrorIs(t, err, ErrNoAssetLabels)
+}
@@ -1,7 +1,12 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/erp-sppg/backend/internal/models"
@@ -9,24 +14,66 @@ import (
 )
 
 var (
-	ErrAssetNotFound      = errors.New("aset tidak ditemukan")
-	ErrAssetValidation    = errors.New("validasi aset gagal")
-	ErrDuplicateAssetCode = errors.New("kode aset sudah digunakan")
+	ErrAssetNotFound              = errors.New("aset tidak ditemukan")
+	ErrAssetValidation            = errors.New("validasi aset gagal")
+	ErrDuplicateAssetCode         = errors.New("kode aset sudah digunakan")
+	ErrInvalidDepreciationMethod  = errors.New("metode penyusutan tidak valid")
+	ErrUsefulLifeRequired         = errors.New("umur manfaat atau tarif penyusutan wajib diisi")
+	ErrTotalUnitsRequired         = errors.New("total kapasitas produksi wajib diisi untuk metode unit produksi")
+	ErrInvalidSalvageValue        = errors.New("nilai sisa tidak boleh melebihi harga perolehan")
+	ErrAssetNotActive             = errors.New("aset sudah dilepas atau dijual")
+	ErrInvalidDisposal            = errors.New("tanggal atau nilai pelepasan aset tidak valid")
+	ErrInvalidUsageUnits          = errors.New("jumlah pemakaian harus lebih dari nol")
+	ErrDepreciationRunNotFound    = errors.New("penyusutan periode tidak ditemukan")
+	ErrDepreciationRunReversed    = errors.New("penyusutan periode sudah dibatalkan")
+	ErrDepreciationRunNotLatest   = errors.New("hanya penyusutan periode terakhir yang dapat dibatalkan")
+	ErrDepreciationRunAssetClosed = errors.New("penyusutan tidak dapat dibatalkan karena asetnya sudah dilepas atau dijual")
+)
+
+// Depreciation methods
+const (
+	DepreciationMethodStraightLine      = "straight_line"
+	DepreciationMethodDecliningBalance  = "declining_balance"
+	DepreciationMethodUnitsOfProduction = "units_of_production"
+)
+
+// Asset statuses
+const (
+	AssetStatusActive   = "active"
+	AssetStatusDisposed = "disposed"
+	AssetStatusSold     = "sold"
+)
+
+// Depreciation run statuses
+const (
+	DepreciationRunStatusPosted   = "posted"
+	DepreciationRunStatusReversed = "reversed"
 )
 
 // AssetService handles kitchen asset business logic
 type AssetService struct {
-	db *gorm.DB
+	db            *gorm.DB
+	ledgerService *LedgerService
 }
 
 // NewAssetService creates a new asset service
 func NewAssetService(db *gorm.DB) *AssetService {
 	return &AssetService{
-		db: db,
+		db:            db,
+		ledgerService: NewLedgerService(db),
 	}
 }
 
-// CreateAsset creates a new kitchen asset
+// AssetDisposal represents the disposal or sale of an asset. Assets disposed
+// with proceeds are recorded as sold.
+type AssetDisposal struct {
+	Date     time.Time    `json:"date"`
+	Proceeds models.Money `json:"proceeds"`
+	Notes    string       `json:"notes"`
+}
+
+// CreateAsset creates a new kitchen asset. Its book value starts at the
+// purchase price and is reduced by the monthly depreciation runs.
 func (s *AssetService) CreateAsset(asset *models.KitchenAsset) error {
 	// Check for duplicate asset code
 	var existing models.KitchenAsset
@@ -38,22 +85,26 @@ func (s *AssetService) CreateAsset(asset *models.KitchenAsset) error {
 		return err
 	}
 
-	// Set initial current value to purchase price
-	asset.CurrentValue = asset.PurchasePrice
-
-	// Calculate initial depreciation if purchase date is in the past
-	if asset.PurchaseDate.Before(time.Now()) {
-		asset.CurrentValue = s.CalculateBookValue(asset.PurchasePrice, asset.PurchaseDate, asset.DepreciationRate)
+	if err := validateDepreciationSettings(asset); err != nil {
+		return err
 	}
 
-	return s.db.Create(asset).Error
+	asset.CurrentValue = asset.PurchasePrice
+	asset.AccumulatedDepreciation = 0
+	asset.Status = AssetStatusActive
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(asset).Error; err != nil {
+			return err
+		}
+		return s.ledgerService.PostAssetPurchaseWithTx(tx, asset, AccountCodeCash, 0)
+	})
 }
 
 // GetAssetByID retrieves an asset by ID with maintenance records
 func (s *AssetService) GetAssetByID(id uint) (*models.KitchenAsset, error) {
 	var asset models.KitchenAsset
 	err := s.db.Preload("MaintenanceRecords").First(&asset, id).Error
-	
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrAssetNotFound
@@ -61,9 +112,6 @@ func (s *AssetService) GetAssetByID(id uint) (*models.KitchenAsset, error) {
 		return nil, err
 	}
 
-	// Recalculate current value based on depreciation
-	asset.CurrentValue = s.CalculateBookValue(asset.PurchasePrice, asset.PurchaseDate, asset.DepreciationRate)
-
 	return &asset, nil
 }
 
@@ -71,32 +119,26 @@ func (s *AssetService) GetAssetByID(id uint) (*models.KitchenAsset, error) {
 func (s *AssetService) GetAllAssets(category string) ([]models.KitchenAsset, error) {
 	var assets []models.KitchenAsset
 	query := s.db.Model(&models.KitchenAsset{})
-	
+
 	if category != "" {
 		query = query.Where("category = ?", category)
 	}
-	
+
 	err := query.Order("name ASC").Find(&assets).Error
-	
-	// Update current values for all assets
-	for i := range assets {
-		assets[i].CurrentValue = s.CalculateBookValue(
-			assets[i].PurchasePrice,
-			assets[i].PurchaseDate,
-			assets[i].DepreciationRate,
-		)
-	}
-	
 	return assets, err
 }
 
-// UpdateAsset updates an existing asset
+// UpdateAsset updates an existing active asset. The book value follows the
+// purchase price less the depreciation posted so far.
 func (s *AssetService) UpdateAsset(id uint, updates *models.KitchenAsset) error {
 	// Check if asset exists
-	_, err := s.GetAssetByID(id)
+	current, err := s.GetAssetByID(id)
 	if err != nil {
 		return err
 	}
+	if current.Status != AssetStatusActive {
+		return ErrAssetNotActive
+	}
 
 	// Check for duplicate asset code (excluding current asset)
 	var existing models.KitchenAsset
@@ -108,81 +150,439 @@ func (s *AssetService) UpdateAsset(id uint, updates *models.KitchenAsset) error
 		return err
 	}
 
-	// Recalculate current value
-	updates.CurrentValue = s.CalculateBookValue(updates.PurchasePrice, updates.PurchaseDate, updates.DepreciationRate)
+	if err := validateDepreciationSettings(updates); err != nil {
+		return err
+	}
+	updates.CurrentValue = updates.PurchasePrice - current.AccumulatedDepreciation
+	if updates.CurrentValue < 0 {
+		updates.CurrentValue = 0
+	}
 
 	// Update asset
-	return s.db.Model(&models.KitchenAsset{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"asset_code":        updates.AssetCode,
-		"name":              updates.Name,
-		"category":          updates.Category,
-		"purchase_date":     updates.PurchaseDate,
-		"purchase_price":    updates.PurchasePrice,
-		"current_value":     updates.CurrentValue,
-		"depreciation_rate": updates.DepreciationRate,
-		"condition":         updates.Condition,
-		"location":          updates.Location,
-		"updated_at":        time.Now(),
-	}).Error
-}
-
-// DeleteAsset deletes an asset
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.KitchenAsset{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"asset_code":          updates.AssetCode,
+			"name":                updates.Name,
+			"category":            updates.Category,
+			"purchase_date":       updates.PurchaseDate,
+			"purchase_price":      updates.PurchasePrice,
+			"current_value":       updates.CurrentValue,
+			"depreciation_method": updates.DepreciationMethod,
+			"depreciation_rate":   updates.DepreciationRate,
+			"useful_life_months":  updates.UsefulLifeMonths,
+			"salvage_value":       updates.SalvageValue,
+			"total_units":         updates.TotalUnits,
+			"condition":           updates.Condition,
+			"location":            updates.Location,
+			"updated_at":          time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		// Correct the acquisition journal when the purchase changed
+		if current.PurchasePrice == updates.PurchasePrice && current.PurchaseDate.Equal(updates.PurchaseDate) {
+			return nil
+		}
+		var asset models.KitchenAsset
+		if err := tx.First(&asset, id).Error; err != nil {
+			return err
+		}
+		return s.ledgerService.RepostAssetPurchaseWithTx(tx, &asset, 0)
+	})
+}
+
+// DeleteAsset deletes an asset with its maintenance plans and cancels its
+// acquisition and disposal journals
 func (s *AssetService) DeleteAsset(id uint) error {
-	result := s.db.Delete(&models.KitchenAsset{}, id)
-	if result.Error != nil {
-		return result.Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var asset models.KitchenAsset
+		if err := tx.First(&asset, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAssetNotFound
+			}
+			return err
+		}
+
+		sourceRef := strconv.FormatUint(uint64(id), 10)
+		if err := s.ledgerService.ReverseSourceWithTx(tx, JournalSourceAssetDisposal, sourceRef,
+			"Penghapusan aset "+asset.AssetCode, 0); err != nil {
+			return err
+		}
+		if err := s.ledgerService.ReverseSourceWithTx(tx, JournalSourceAssetPurchase, sourceRef,
+			"Penghapusan aset "+asset.AssetCode, 0); err != nil {
+			return err
+		}
+		if err := tx.Where("asset_id = ?", id).Delete(&models.MaintenancePlan{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&asset).Error
+	})
+}
+
+// DisposeAsset records the disposal or sale of an active asset. The cost and
+// accumulated depreciation leave the books and the difference between the
+// proceeds and the book value is posted as a gain or loss.
+func (s *AssetService) DisposeAsset(id uint, disposal AssetDisposal, userID uint) (*models.KitchenAsset, error) {
+	if disposal.Proceeds < 0 {
+		return nil, ErrInvalidDisposal
 	}
-	if result.RowsAffected == 0 {
-		return ErrAssetNotFound
+	if disposal.Date.IsZero() {
+		disposal.Date = time.Now()
+	}
+
+	var oldAsset models.KitchenAsset
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var asset models.KitchenAsset
+		if err := tx.First(&asset, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAssetNotFound
+			}
+			return err
+		}
+		oldAsset = asset
+		if asset.Status != AssetStatusActive {
+			return ErrAssetNotActive
+		}
+		if disposal.Date.Before(startOfDay(asset.PurchaseDate)) {
+			return ErrInvalidDisposal
+		}
+
+		accumulated, err := s.postedDepreciation(tx, asset.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.ledgerService.PostAssetDisposalWithTx(tx, &asset, disposal.Date, accumulated, disposal.Proceeds, userID); err != nil {
+			return err
+		}
+
+		status := AssetStatusDisposed
+		if disposal.Proceeds > 0 {
+			status = AssetStatusSold
+		}
+		bookValue := asset.PurchasePrice - accumulated
+		return tx.Model(&models.KitchenAsset{}).Where("id = ?", asset.ID).Updates(map[string]interface{}{
+			"status":                   status,
+			"disposal_date":            disposal.Date,
+			"disposal_proceeds":        disposal.Proceeds,
+			"disposal_gain_loss":       disposal.Proceeds - bookValue,
+			"disposal_notes":           disposal.Notes,
+			"accumulated_depreciation": accumulated,
+			"current_value":            0,
+			"updated_at":               time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	asset, err := s.GetAssetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	NewAuditTrailService(s.db).RecordAction(userID, "dispose", "kitchen_asset", asset.AssetCode, oldAsset, asset, "")
+	return asset, nil
+}
+
+// RecordUsage records the output of an asset for the units-of-production method
+func (s *AssetService) RecordUsage(assetID uint, usage *models.AssetUsageLog, userID uint) error {
+	if usage.Units <= 0 {
+		return ErrInvalidUsageUnits
+	}
+	asset, err := s.GetAssetByID(assetID)
+	if err != nil {
+		return err
+	}
+	if asset.Status != AssetStatusActive {
+		return ErrAssetNotActive
+	}
+	if usage.UsageDate.IsZero() {
+		usage.UsageDate = time.Now()
+	}
+
+	usage.ID = 0
+	usage.AssetID = assetID
+	usage.CreatedBy = userID
+	return s.db.Create(usage).Error
+}
+
+// GetUsageLogs retrieves the usage records of an asset
+func (s *AssetService) GetUsageLogs(assetID uint) ([]models.AssetUsageLog, error) {
+	var logs []models.AssetUsageLog
+	err := s.db.Where("asset_id = ?", assetID).Order("usage_date DESC, id DESC").Find(&logs).Error
+	return logs, err
+}
+
+// RunDepreciation posts the depreciation of every active asset for a month
+// according to its depreciation method, updates the book values and records
+// the run so that it can be reversed. Assets already depreciated for the
+// month or fully depreciated are skipped.
+func (s *AssetService) RunDepreciation(year, month int, userID uint) (*DepreciationRunResult, error) {
+	if year < 2000 || month < 1 || month > 12 {
+		return nil, ErrInvalidLedgerPeriod
+	}
+	periodStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	periodEnd := periodStart.AddDate(0, 1, 0)
+	postingDate := periodEnd.AddDate(0, 0, -1)
+
+	result := &DepreciationRunResult{Year: year, Month: month, Entries: []models.JournalEntry{}}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var assets []models.KitchenAsset
+		if err := tx.Where("status = ? AND purchase_date < ? AND purchase_price > 0", AssetStatusActive, periodEnd).
+			Order("asset_code ASC").Find(&assets).Error; err != nil {
+			return err
+		}
+
+		var lines []models.DepreciationRunLine
+		for i := range assets {
+			asset := &assets[i]
+			sourceRef := depreciationSourceRef(asset.ID, year, month)
+			posted, err := s.ledgerService.hasPostedSource(tx, JournalSourceDepreciation, sourceRef)
+			if err != nil {
+				return err
+			}
+			if posted {
+				result.Skipped++
+				continue
+			}
+
+			accumulated, err := s.postedDepreciation(tx, asset.ID)
+			if err != nil {
+				return err
+			}
+			var units float64
+			if asset.DepreciationMethod == DepreciationMethodUnitsOfProduction {
+				if err := tx.Model(&models.AssetUsageLog{}).
+					Where("asset_id = ? AND usage_date >= ? AND usage_date < ?", asset.ID, periodStart, periodEnd).
+					Select("COALESCE(SUM(units), 0)").Scan(&units).Error; err != nil {
+					return err
+				}
+			}
+
+			bookValue := asset.PurchasePrice - accumulated
+			amount := monthlyDepreciation(asset, bookValue, monthsSincePurchase(asset.PurchaseDate, year, month), units)
+			if amount <= 0 {
+				result.Skipped++
+				continue
+			}
+
+			description := fmt.Sprintf("Penyusutan %s (%s) periode %02d/%d", asset.Name, asset.AssetCode, month, year)
+			if err := s.ledgerService.postAutomatic(tx, JournalSourceDepreciation, sourceRef, asset.AssetCode, postingDate, description,
+				AccountCodeDepreciationExpense, AccountCodeAccumulatedDepreciation, amount, userID); err != nil {
+				return err
+			}
+
+			var entry models.JournalEntry
+			if err := tx.Where("source_type = ? AND source_ref = ? AND status = ?", JournalSourceDepreciation, sourceRef, JournalStatusPosted).
+				First(&entry).Error; err != nil {
+				return err
+			}
+
+			newAccumulated := accumulated + amount
+			if err := tx.Model(&models.KitchenAsset{}).Where("id = ?", asset.ID).Updates(map[string]interface{}{
+				"accumulated_depreciation": newAccumulated,
+				"current_value":            asset.PurchasePrice - newAccumulated,
+				"updated_at":               time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+
+			lines = append(lines, models.DepreciationRunLine{
+				AssetID:         asset.ID,
+				Method:          depreciationMethod(asset),
+				Amount:          amount,
+				UnitsUsed:       units,
+				BookValueBefore: bookValue,
+				BookValueAfter:  bookValue - amount,
+				JournalEntryID:  entry.ID,
+			})
+			result.Entries = append(result.Entries, entry)
+			result.Posted++
+			result.TotalAmount += amount
+		}
+
+		if len(lines) == 0 {
+			return nil
+		}
+		run := models.DepreciationRun{
+			Year:        year,
+			Month:       month,
+			Status:      DepreciationRunStatusPosted,
+			AssetCount:  len(lines),
+			TotalAmount: result.TotalAmount,
+			PostedBy:    userID,
+			Lines:       lines,
+		}
+		if err := tx.Create(&run).Error; err != nil {
+			return err
+		}
+		result.RunID = run.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "depreciation_run", fmt.Sprintf("%04d-%02d", year, month), nil, result, "")
+	return result, nil
+}
+
+// ReverseDepreciationRun reverses the depreciation journals of the latest
+// posted run and restores the book values of its assets. The month can then
+// be run again.
+func (s *AssetService) ReverseDepreciationRun(id uint, reason string, userID uint) (*models.DepreciationRun, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var run models.DepreciationRun
+		if err := tx.Preload("Lines").First(&run, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDepreciationRunNotFound
+			}
+			return err
+		}
+		if run.Status != DepreciationRunStatusPosted {
+			return ErrDepreciationRunReversed
+		}
+
+		var later int64
+		if err := tx.Model(&models.DepreciationRun{}).
+			Where("status = ? AND id <> ?", DepreciationRunStatusPosted, run.ID).
+			Where("year > ? OR (year = ? AND month > ?) OR (year = ? AND month = ? AND id > ?)",
+				run.Year, run.Year, run.Month, run.Year, run.Month, run.ID).
+			Count(&later).Error; err != nil {
+			return err
+		}
+		if later > 0 {
+			return ErrDepreciationRunNotLatest
+		}
+
+		description := fmt.Sprintf("Pembatalan penyusutan periode %02d/%d", run.Month, run.Year)
+		if reason != "" {
+			description += ": " + reason
+		}
+		for _, line := range run.Lines {
+			var asset models.KitchenAsset
+			if err := tx.First(&asset, line.AssetID).Error; err != nil {
+				return err
+			}
+			if asset.Status != AssetStatusActive {
+				return ErrDepreciationRunAssetClosed
+			}
+
+			if err := s.ledgerService.ReverseSourceWithTx(tx, JournalSourceDepreciation,
+				depreciationSourceRef(asset.ID, run.Year, run.Month), description, userID); err != nil {
+				return err
+			}
+			accumulated, err := s.postedDepreciation(tx, asset.ID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&models.KitchenAsset{}).Where("id = ?", asset.ID).Updates(map[string]interface{}{
+				"accumulated_depreciation": accumulated,
+				"current_value":            asset.PurchasePrice - accumulated,
+				"updated_at":               time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		return tx.Model(&models.DepreciationRun{}).Where("id = ?", run.ID).Updates(map[string]interface{}{
+			"status":      DepreciationRunStatusReversed,
+			"reversed_by": userID,
+			"reversed_at": now,
+			"updated_at":  now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "reverse", "depreciation_run", strconv.FormatUint(uint64(id), 10), nil, nil, "")
+	return s.GetDepreciationRun(id)
+}
+
+// GetDepreciationRuns lists depreciation runs, optionally for a single year
+func (s *AssetService) GetDepreciationRuns(year int) ([]models.DepreciationRun, error) {
+	query := s.db.Model(&models.DepreciationRun{})
+	if year > 0 {
+		query = query.Where("year = ?", year)
+	}
+
+	var runs []models.DepreciationRun
+	err := query.Order("year DESC, month DESC, id DESC").Find(&runs).Error
+	return runs, err
+}
+
+// GetDepreciationRun retrieves a depreciation run with its asset lines
+func (s *AssetService) GetDepreciationRun(id uint) (*models.DepreciationRun, error) {
+	var run models.DepreciationRun
+	if err := s.db.Preload("Lines.Asset").First(&run, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDepreciationRunNotFound
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// StartDepreciationScheduler posts the depreciation of the previous month
+// once it has ended, unless a run for that month already exists (a reversed
+// run is left for the finance team to post again). The automatic run can be
+// switched off with the depreciation_auto_post system config.
+func (s *AssetService) StartDepreciationScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.runScheduledDepreciation(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
-	return nil
 }
 
-// CalculateBookValue calculates the current book value of an asset
-// Formula: Book Value = Purchase Price - (Purchase Price * Depreciation Rate * Years Elapsed)
-func (s *AssetService) CalculateBookValue(purchasePrice float64, purchaseDate time.Time, depreciationRate float64) float64 {
-	now := time.Now()
-	
-	// Calculate years elapsed (including fractional years)
-	yearsElapsed := now.Sub(purchaseDate).Hours() / (24 * 365.25)
-	
-	// Calculate accumulated depreciation
-	accumulatedDepreciation := purchasePrice * (depreciationRate / 100) * yearsElapsed
-	
-	// Book value cannot be negative
-	bookValue := purchasePrice - accumulatedDepreciation
-	if bookValue < 0 {
-		bookValue = 0
-	}
-	
-	return bookValue
-}
-
-// CalculateAccumulatedDepreciation calculates total depreciation for an asset
-func (s *AssetService) CalculateAccumulatedDepreciation(purchasePrice float64, purchaseDate time.Time, depreciationRate float64) float64 {
-	now := time.Now()
-	yearsElapsed := now.Sub(purchaseDate).Hours() / (24 * 365.25)
-	
-	accumulatedDepreciation := purchasePrice * (depreciationRate / 100) * yearsElapsed
-	
-	// Cannot exceed purchase price
-	if accumulatedDepreciation > purchasePrice {
-		accumulatedDepreciation = purchasePrice
-	}
-	
-	return accumulatedDepreciation
+// runScheduledDepreciation posts the month before now when it has no run yet
+func (s *AssetService) runScheduledDepreciation(now time.Time) {
+	if !NewSystemConfigService(s.db).GetConfigBool("depreciation_auto_post", true) {
+		return
+	}
+
+	previous := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -1, 0)
+	var count int64
+	if err := s.db.Model(&models.DepreciationRun{}).
+		Where("year = ? AND month = ?", previous.Year(), int(previous.Month())).
+		Count(&count).Error; err != nil || count > 0 {
+		return
+	}
+
+	result, err := s.RunDepreciation(previous.Year(), int(previous.Month()), 0)
+	if err != nil {
+		log.Printf("[DEPRECIATION] gagal memposting penyusutan %02d/%d: %v", previous.Month(), previous.Year(), err)
+		return
+	}
+	if result.Posted > 0 {
+		log.Printf("[DEPRECIATION] penyusutan %02d/%d diposting untuk %d aset, total Rp %s",
+			previous.Month(), previous.Year(), result.Posted, result.TotalAmount)
+	}
 }
 
 // AddMaintenanceRecord adds a maintenance record for an asset
 func (s *AssetService) AddMaintenanceRecord(assetID uint, maintenance *models.AssetMaintenance) error {
 	// Check if asset exists
-	_, err := s.GetAssetByID(assetID)
+	asset, err := s.GetAssetByID(assetID)
 	if err != nil {
 		return err
 	}
 
 	maintenance.AssetID = assetID
-	return s.db.Create(maintenance).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(maintenance).Error; err != nil {
+			return err
+		}
+		return s.ledgerService.PostAssetMaintenanceWithTx(tx, maintenance, asset, 0)
+	})
 }
 
 // GetMaintenanceRecords retrieves all maintenance records for an asset
@@ -194,24 +594,28 @@ func (s *AssetService) GetMaintenanceRecords(assetID uint) ([]models.AssetMainte
 
 // AssetReport represents asset summary report
 type AssetReport struct {
-	TotalAssets            int                      `json:"total_assets"`
-	TotalPurchaseValue     float64                  `json:"total_purchase_value"`
-	TotalCurrentValue      float64                  `json:"total_current_value"`
-	TotalDepreciation      float64                  `json:"total_depreciation"`
-	AssetsByCategory       map[string]CategoryStats `json:"assets_by_category"`
-	AssetsByCondition      map[string]int           `json:"assets_by_condition"`
-	TotalMaintenanceCost   float64                  `json:"total_maintenance_cost"`
+	TotalAssets          int                      `json:"total_assets"`
+	TotalPurchaseValue   models.Money             `json:"total_purchase_value"`
+	TotalCurrentValue    models.Money             `json:"total_current_value"`
+	TotalDepreciation    models.Money             `json:"total_depreciation"`
+	AssetsByCategory     map[string]CategoryStats `json:"assets_by_category"`
+	AssetsByCondition    map[string]int           `json:"assets_by_condition"`
+	AssetsByStatus       map[string]int           `json:"assets_by_status"`
+	TotalDisposalGain    models.Money             `json:"total_disposal_gain_loss"`
+	TotalMaintenanceCost models.Money             `json:"total_maintenance_cost"`
 }
 
 // CategoryStats represents statistics for an asset category
 type CategoryStats struct {
-	Count         int     `json:"count"`
-	PurchaseValue float64 `json:"purchase_value"`
-	CurrentValue  float64 `json:"current_value"`
-	Depreciation  float64 `json:"depreciation"`
+	Count         int          `json:"count"`
+	PurchaseValue models.Money `json:"purchase_value"`
+	CurrentValue  models.Money `json:"current_value"`
+	Depreciation  models.Money `json:"depreciation"`
 }
 
-// GenerateAssetReport generates a comprehensive asset report
+// GenerateAssetReport generates a comprehensive asset report. Values and
+// categories cover the active assets; disposed and sold assets are only
+// counted by status and in the disposal result.
 func (s *AssetService) GenerateAssetReport() (*AssetReport, error) {
 	var assets []models.KitchenAsset
 	err := s.db.Find(&assets).Error
@@ -222,24 +626,33 @@ func (s *AssetService) GenerateAssetReport() (*AssetReport, error) {
 	report := &AssetReport{
 		AssetsByCategory:  make(map[string]CategoryStats),
 		AssetsByCondition: make(map[string]int),
+		AssetsByStatus:    make(map[string]int),
 	}
 
 	for _, asset := range assets {
-		// Update current value
-		currentValue := s.CalculateBookValue(asset.PurchasePrice, asset.PurchaseDate, asset.DepreciationRate)
-		depreciation := asset.PurchasePrice - currentValue
+		status := asset.Status
+		if status == "" {
+			status = AssetStatusActive
+		}
+		report.AssetsByStatus[status]++
+		if status != AssetStatusActive {
+			report.TotalDisposalGain += asset.DisposalGainLoss
+			continue
+		}
+
+		depreciation := asset.PurchasePrice - asset.CurrentValue
 
 		// Update totals
 		report.TotalAssets++
 		report.TotalPurchaseValue += asset.PurchasePrice
-		report.TotalCurrentValue += currentValue
+		report.TotalCurrentValue += asset.CurrentValue
 		report.TotalDepreciation += depreciation
 
 		// Update category stats
 		categoryStats := report.AssetsByCategory[asset.Category]
 		categoryStats.Count++
 		categoryStats.PurchaseValue += asset.PurchasePrice
-		categoryStats.CurrentValue += currentValue
+		categoryStats.CurrentValue += asset.CurrentValue
 		categoryStats.Depreciation += depreciation
 		report.AssetsByCategory[asset.Category] = categoryStats
 
@@ -248,7 +661,7 @@ func (s *AssetService) GenerateAssetReport() (*AssetReport, error) {
 	}
 
 	// Calculate total maintenance cost
-	var totalMaintenanceCost float64
+	var totalMaintenanceCost models.Money
 	s.db.Model(&models.AssetMaintenance{}).Select("COALESCE(SUM(cost), 0)").Scan(&totalMaintenanceCost)
 	report.TotalMaintenanceCost = totalMaintenanceCost
 
@@ -273,20 +686,13 @@ func (s *AssetService) SearchAssets(query string, category string, condition str
 	}
 
 	err := db.Order("name ASC").Find(&assets).Error
-	
-	// Update current values
-	for i := range assets {
-		assets[i].CurrentValue = s.CalculateBookValue(
-			assets[i].PurchasePrice,
-			assets[i].PurchaseDate,
-			assets[i].DepreciationRate,
-		)
-	}
-	
 	return assets, err
 }
 
-// GetDepreciationSchedule generates a depreciation schedule for an asset
+// GetDepreciationSchedule generates a yearly depreciation schedule for an
+// asset following its depreciation method. For units of production the
+// recorded usage is used for past months and the average monthly usage is
+// projected for the future.
 func (s *AssetService) GetDepreciationSchedule(assetID uint, years int) ([]DepreciationEntry, error) {
 	asset, err := s.GetAssetByID(assetID)
 	if err != nil {
@@ -297,22 +703,59 @@ func (s *AssetService) GetDepreciationSchedule(assetID uint, years int) ([]Depre
 		years = 5 // Default to 5 years
 	}
 
-	schedule := make([]DepreciationEntry, 0, years)
-	
-	for year := 0; year <= years; year++ {
-		futureDate := asset.PurchaseDate.AddDate(year, 0, 0)
-		bookValue := s.CalculateBookValue(asset.PurchasePrice, futureDate, asset.DepreciationRate)
-		depreciation := asset.PurchasePrice - bookValue
-		
-		entry := DepreciationEntry{
+	start := time.Date(asset.PurchaseDate.Year(), asset.PurchaseDate.Month(), 1, 0, 0, 0, 0, time.Local)
+	usage := make(map[string]float64)
+	var projectedUnits float64
+	if asset.DepreciationMethod == DepreciationMethodUnitsOfProduction {
+		var logs []models.AssetUsageLog
+		if err := s.db.Where("asset_id = ?", asset.ID).Find(&logs).Error; err != nil {
+			return nil, err
+		}
+		var totalUnits float64
+		for _, usageLog := range logs {
+			usage[usageLog.UsageDate.Format("2006-01")] += usageLog.Units
+			totalUnits += usageLog.Units
+		}
+
+		now := time.Now()
+		elapsed := monthsSincePurchase(asset.PurchaseDate, now.Year(), int(now.Month())) + 1
+		switch {
+		case totalUnits > 0 && elapsed > 0:
+			projectedUnits = totalUnits / float64(elapsed)
+		case asset.UsefulLifeMonths > 0:
+			projectedUnits = asset.TotalUnits / float64(asset.UsefulLifeMonths)
+		}
+	}
+	currentMonth := time.Now().Format("2006-01")
+
+	schedule := make([]DepreciationEntry, 0, years+1)
+	schedule = append(schedule, DepreciationEntry{Year: 0, Date: asset.PurchaseDate, BookValue: asset.PurchasePrice})
+
+	bookValue := asset.PurchasePrice
+	for year := 1; year <= years; year++ {
+		var annual models.Money
+		for m := (year - 1) * 12; m < year*12; m++ {
+			var units float64
+			if asset.DepreciationMethod == DepreciationMethodUnitsOfProduction {
+				period := start.AddDate(0, m, 0).Format("2006-01")
+				if period <= currentMonth {
+					units = usage[period]
+				} else {
+					units = projectedUnits
+				}
+			}
+			amount := monthlyDepreciation(asset, bookValue, m, units)
+			bookValue = bookValue - amount
+			annual += amount
+		}
+
+		schedule = append(schedule, DepreciationEntry{
 			Year:                    year,
-			Date:                    futureDate,
+			Date:                    asset.PurchaseDate.AddDate(year, 0, 0),
 			BookValue:               bookValue,
-			AccumulatedDepreciation: depreciation,
-			AnnualDepreciation:      asset.PurchasePrice * (asset.DepreciationRate / 100),
-		}
-		
-		schedule = append(schedule, entry)
+			AccumulatedDepreciation: asset.PurchasePrice - bookValue,
+			AnnualDepreciation:      annual,
+		})
 	}
 
 	return schedule, nil
@@ -320,9 +763,121 @@ func (s *AssetService) GetDepreciationSchedule(assetID uint, years int) ([]Depre
 
 // DepreciationEntry represents a single entry in depreciation schedule
 type DepreciationEntry struct {
-	Year                    int       `json:"year"`
-	Date                    time.Time `json:"date"`
-	BookValue               float64   `json:"book_value"`
-	AccumulatedDepreciation float64   `json:"accumulated_depreciation"`
-	AnnualDepreciation      float64   `json:"annual_depreciation"`
+	Year                    int          `json:"year"`
+	Date                    time.Time    `json:"date"`
+	BookValue               models.Money `json:"book_value"`
+	AccumulatedDepreciation models.Money `json:"accumulated_depreciation"`
+	AnnualDepreciation      models.Money `json:"annual_depreciation"`
+}
+
+// postedDepreciation sums the posted depreciation journals of an asset
+func (s *AssetService) postedDepreciation(tx *gorm.DB, assetID uint) (models.Money, error) {
+	var accumulated models.Money
+	err := tx.Model(&models.JournalEntry{}).
+		Where("source_type = ? AND status = ? AND source_ref LIKE ?", JournalSourceDepreciation, JournalStatusPosted,
+			strconv.FormatUint(uint64(assetID), 10)+"/%").
+		Select("COALESCE(SUM(total_amount), 0)").Scan(&accumulated).Error
+	return accumulated, err
+}
+
+// depreciationSourceRef is the journal source reference of an asset's monthly depreciation
+func depreciationSourceRef(assetID uint, year, month int) string {
+	return fmt.Sprintf("%d/%04d-%02d", assetID, year, month)
+}
+
+// validateDepreciationSettings defaults the method and checks that it has the
+// parameters it needs
+func validateDepreciationSettings(asset *models.KitchenAsset) error {
+	if asset.DepreciationMethod == "" {
+		asset.DepreciationMethod = DepreciationMethodStraightLine
+	}
+	if asset.SalvageValue < 0 || asset.SalvageValue > asset.PurchasePrice {
+		return ErrInvalidSalvageValue
+	}
+
+	switch asset.DepreciationMethod {
+	case DepreciationMethodStraightLine, DepreciationMethodDecliningBalance:
+		if asset.UsefulLifeMonths <= 0 && asset.DepreciationRate <= 0 && asset.PurchasePrice > asset.SalvageValue {
+			return ErrUsefulLifeRequired
+		}
+	case DepreciationMethodUnitsOfProduction:
+		if asset.TotalUnits <= 0 {
+			return ErrTotalUnitsRequired
+		}
+	default:
+		return ErrInvalidDepreciationMethod
+	}
+	return nil
+}
+
+// depreciationMethod returns the method of an asset, straight line for assets
+// created before methods existed
+func depreciationMethod(asset *models.KitchenAsset) string {
+	if asset.DepreciationMethod == "" {
+		return DepreciationMethodStraightLine
+	}
+	return asset.DepreciationMethod
+}
+
+// usefulLifeMonths returns the useful life of an asset, derived from the
+// annual depreciation rate when no useful life is set
+func usefulLifeMonths(asset *models.KitchenAsset) int {
+	if asset.UsefulLifeMonths > 0 {
+		return asset.UsefulLifeMonths
+	}
+	if asset.DepreciationRate > 0 {
+		return int(math.Round(1200 / asset.DepreciationRate))
+	}
+	return 0
+}
+
+// monthsSincePurchase returns the number of whole months between the purchase
+// month and the given period; the purchase month itself is month 0
+func monthsSincePurchase(purchaseDate time.Time, year, month int) int {
+	return (year-purchaseDate.Year())*12 + month - int(purchaseDate.Month())
+}
+
+// monthlyDepreciation calculates the depreciation of an asset for one month,
+// given its book value at the start of the month, the month index since
+// purchase and the units produced in the month. The book value never goes
+// below the salvage value.
+func monthlyDepreciation(asset *models.KitchenAsset, bookValue models.Money, monthIndex int, units float64) models.Money {
+	depreciable := bookValue - asset.SalvageValue
+	if depreciable <= 0 || monthIndex < 0 {
+		return 0
+	}
+
+	var amount models.Money
+	switch depreciationMethod(asset) {
+	case DepreciationMethodStraightLine:
+		life := usefulLifeMonths(asset)
+		if life <= 0 || monthIndex >= life {
+			return 0
+		}
+		amount = (asset.PurchasePrice - asset.SalvageValue).Div(int64(life))
+	case DepreciationMethodDecliningBalance:
+		life := usefulLifeMonths(asset)
+		if life <= 0 || monthIndex >= life {
+			return 0
+		}
+		if monthIndex == life-1 {
+			// The last month of the useful life brings the book value to the salvage value
+			return depreciable
+		}
+		rate := asset.DepreciationRate / 100
+		if rate <= 0 {
+			rate = 2 * 12 / float64(life) // double declining balance
+		}
+		amount = bookValue.Mul(rate / 12)
+	case DepreciationMethodUnitsOfProduction:
+		if asset.TotalUnits <= 0 {
+			return 0
+		}
+		amount = (asset.PurchasePrice - asset.SalvageValue).Mul(units / asset.TotalUnits)
+	}
+
+	if amount > depreciable {
+		return depreciable
+	}
+	return amount
 }
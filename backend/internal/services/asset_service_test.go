@@ -0,0 +1,247 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupAssetTestDB(t *testing.T) *gorm.DB {
+	db, err := openTestDB(t, filepath.Join(t.TempDir(), "asset.db"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.KitchenAsset{}, &models.AssetMaintenance{}, &models.AssetUsageLog{},
+		&models.DepreciationRun{}, &models.DepreciationRunLine{},
+		&models.Account{}, &models.JournalEntry{}, &models.JournalLine{}, &models.AuditTrail{}, &models.SystemConfig{})
+	require.NoError(t, err)
+
+	require.NoError(t, NewLedgerService(db).SeedDefaultAccounts())
+	return db
+}
+
+func TestMonthlyDepreciation(t *testing.T) {
+	straightLine := &models.KitchenAsset{DepreciationMethod: DepreciationMethodStraightLine, PurchasePrice: models.Rupiah(13000000), SalvageValue: models.Rupiah(1000000), UsefulLifeMonths: 60}
+	assert.Equal(t, models.Rupiah(200000), monthlyDepreciation(straightLine, models.Rupiah(13000000), 0, 0))
+	// Never below the salvage value and nothing after the useful life
+	assert.Equal(t, models.Rupiah(50000), monthlyDepreciation(straightLine, models.Rupiah(1050000), 59, 0))
+	assert.Equal(t, models.Rupiah(0), monthlyDepreciation(straightLine, models.Rupiah(1000000), 60, 0))
+
+	// Legacy assets derive the useful life from the annual rate
+	legacy := &models.KitchenAsset{PurchasePrice: models.Rupiah(12000000), DepreciationRate: 10}
+	assert.Equal(t, models.Rupiah(100000), monthlyDepreciation(legacy, models.Rupiah(12000000), 3, 0))
+
+	// Double declining balance on the book value, salvage reached in the last month
+	declining := &models.KitchenAsset{DepreciationMethod: DepreciationMethodDecliningBalance, PurchasePrice: models.Rupiah(12000000), UsefulLifeMonths: 48}
+	assert.Equal(t, models.Rupiah(500000), monthlyDepreciation(declining, models.Rupiah(12000000), 0, 0))
+	assert.Equal(t, models.Rupiah(250000), monthlyDepreciation(declining, models.Rupiah(6000000), 20, 0))
+	assert.Equal(t, models.Rupiah(1200000), monthlyDepreciation(declining, models.Rupiah(1200000), 47, 0))
+
+	// A fixed annual rate overrides the double declining rate
+	declining.DepreciationRate = 30
+	assert.Equal(t, models.Rupiah(300000), monthlyDepreciation(declining, models.Rupiah(12000000), 0, 0))
+
+	units := &models.KitchenAsset{DepreciationMethod: DepreciationMethodUnitsOfProduction, PurchasePrice: models.Rupiah(20000000), SalvageValue: models.Rupiah(2000000), TotalUnits: 900000}
+	assert.Equal(t, models.Rupiah(300000), monthlyDepreciation(units, models.Rupiah(20000000), 0, 15000))
+	assert.Equal(t, models.Rupiah(0), monthlyDepreciation(units, models.Rupiah(20000000), 1, 0))
+}
+
+func TestAssetService_ValidatesDepreciationSettings(t *testing.T) {
+	db := setupAssetTestDB(t)
+	service := NewAssetService(db)
+	date := time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local)
+
+	asset := &models.KitchenAsset{AssetCode: "AST-V1", Name: "Oven", PurchaseDate: date, PurchasePrice: models.Rupiah(5000000), Condition: "good"}
+	assert.ErrorIs(t, service.CreateAsset(asset), ErrUsefulLifeRequired)
+
+	asset.UsefulLifeMonths = 36
+	asset.SalvageValue = models.Rupiah(6000000)
+	assert.ErrorIs(t, service.CreateAsset(asset), ErrInvalidSalvageValue)
+
+	asset.SalvageValue = models.Rupiah(0)
+	asset.DepreciationMethod = DepreciationMethodUnitsOfProduction
+	assert.ErrorIs(t, service.CreateAsset(asset), ErrTotalUnitsRequired)
+
+	asset.DepreciationMethod = "sum_of_years"
+	assert.ErrorIs(t, service.CreateAsset(asset), ErrInvalidDepreciationMethod)
+
+	asset.DepreciationMethod = ""
+	require.NoError(t, service.CreateAsset(asset))
+	assert.Equal(t, DepreciationMethodStraightLine, asset.DepreciationMethod)
+	assert.Equal(t, AssetStatusActive, asset.Status)
+	assert.Equal(t, models.Rupiah(5000000), asset.CurrentValue)
+}
+
+func TestAssetService_DepreciationRunAndReverse(t *testing.T) {
+	db := setupAssetTestDB(t)
+	service := NewAssetService(db)
+	ledger := NewLedgerService(db)
+	purchased := time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local)
+
+	stove := &models.KitchenAsset{AssetCode: "AST-SL", Name: "Kompor", PurchaseDate: purchased, PurchasePrice: models.Rupiah(13000000),
+		SalvageValue: models.Rupiah(1000000), UsefulLifeMonths: 60, Condition: "good"}
+	freezer := &models.KitchenAsset{AssetCode: "AST-DB", Name: "Freezer", PurchaseDate: purchased, PurchasePrice: models.Rupiah(12000000),
+		DepreciationMethod: DepreciationMethodDecliningBalance, UsefulLifeMonths: 48, Condition: "good"}
+	mixer := &models.KitchenAsset{AssetCode: "AST-UP", Name: "Mixer", PurchaseDate: purchased, PurchasePrice: models.Rupiah(20000000),
+		SalvageValue: models.Rupiah(2000000), DepreciationMethod: DepreciationMethodUnitsOfProduction, TotalUnits: 900000, Condition: "good"}
+	for _, asset := range []*models.KitchenAsset{stove, freezer, mixer} {
+		require.NoError(t, service.CreateAsset(asset))
+	}
+	require.NoError(t, service.RecordUsage(mixer.ID, &models.AssetUsageLog{UsageDate: time.Date(2026, 1, 20, 0, 0, 0, 0, time.Local), Units: 15000}, 1))
+	assert.ErrorIs(t, service.RecordUsage(mixer.ID, &models.AssetUsageLog{Units: 0}, 1), ErrInvalidUsageUnits)
+
+	january, err := service.RunDepreciation(2026, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, january.Posted)
+	assert.Equal(t, models.Rupiah(1000000), january.TotalAmount)
+	require.NotZero(t, january.RunID)
+
+	// The mixer was not used in February
+	february, err := service.RunDepreciation(2026, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, february.Posted)
+	assert.Equal(t, 1, february.Skipped)
+	assert.Equal(t, models.NewMoney(679166.67), february.TotalAmount) // 200.000 + 50%/12 of 11.500.000
+
+	again, err := service.RunDepreciation(2026, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, again.Posted)
+	assert.Zero(t, again.RunID)
+
+	asset, err := service.GetAssetByID(freezer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.NewMoney(979166.67), asset.AccumulatedDepreciation)
+	assert.Equal(t, models.NewMoney(11020833.33), asset.CurrentValue)
+	assert.Equal(t, models.NewMoney(-1679166.67), ledgerBalance(t, ledger, AccountCodeAccumulatedDepreciation))
+
+	// Only the latest run can be reversed
+	_, err = service.ReverseDepreciationRun(january.RunID, "", 1)
+	assert.ErrorIs(t, err, ErrDepreciationRunNotLatest)
+
+	run, err := service.ReverseDepreciationRun(february.RunID, "salah periode", 1)
+	require.NoError(t, err)
+	assert.Equal(t, DepreciationRunStatusReversed, run.Status)
+	require.Len(t, run.Lines, 2)
+	_, err = service.ReverseDepreciationRun(february.RunID, "", 1)
+	assert.ErrorIs(t, err, ErrDepreciationRunReversed)
+
+	asset, err = service.GetAssetByID(freezer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(11500000), asset.CurrentValue)
+	assert.Equal(t, models.Rupiah(-1000000), ledgerBalance(t, ledger, AccountCodeAccumulatedDepreciation))
+
+	// The reversed month can be posted again
+	february, err = service.RunDepreciation(2026, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, february.Posted)
+
+	runs, err := service.GetDepreciationRuns(2026)
+	require.NoError(t, err)
+	assert.Len(t, runs, 3)
+}
+
+func TestAssetService_DisposeAssetRecordsGainOrLoss(t *testing.T) {
+	db := setupAssetTestDB(t)
+	service := NewAssetService(db)
+	ledger := NewLedgerService(db)
+	purchased := time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local)
+
+	van := &models.KitchenAsset{AssetCode: "AST-VAN", Name: "Mobil Box", PurchaseDate: purchased, PurchasePrice: models.Rupiah(12000000), DepreciationRate: 10, Condition: "good"}
+	blender := &models.KitchenAsset{AssetCode: "AST-BLD", Name: "Blender", PurchaseDate: purchased, PurchasePrice: models.Rupiah(2400000), UsefulLifeMonths: 24, Condition: "fair"}
+	require.NoError(t, service.CreateAsset(van))
+	require.NoError(t, service.CreateAsset(blender))
+
+	_, err := service.RunDepreciation(2026, 1, 1)
+	require.NoError(t, err)
+	february, err := service.RunDepreciation(2026, 2, 1)
+	require.NoError(t, err)
+
+	_, err = service.DisposeAsset(van.ID, AssetDisposal{Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.Local), Proceeds: models.Rupiah(1)}, 1)
+	assert.ErrorIs(t, err, ErrInvalidDisposal)
+
+	// Sold above its book value of 11.800.000
+	sold, err := service.DisposeAsset(van.ID, AssetDisposal{Date: time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local), Proceeds: models.Rupiah(12500000)}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, AssetStatusSold, sold.Status)
+	assert.Equal(t, models.Rupiah(700000), sold.DisposalGainLoss)
+	assert.Equal(t, models.Rupiah(0), sold.CurrentValue)
+
+	// Written off at its book value of 2.200.000
+	scrapped, err := service.DisposeAsset(blender.ID, AssetDisposal{Date: time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local), Notes: "Motor terbakar"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, AssetStatusDisposed, scrapped.Status)
+	assert.Equal(t, models.Rupiah(-2200000), scrapped.DisposalGainLoss)
+
+	_, err = service.DisposeAsset(blender.ID, AssetDisposal{}, 1)
+	assert.ErrorIs(t, err, ErrAssetNotActive)
+
+	// Cost and accumulated depreciation leave the books
+	assert.Equal(t, models.Rupiah(0), ledgerBalance(t, ledger, AccountCodeFixedAssets))
+	assert.Equal(t, models.Rupiah(0), ledgerBalance(t, ledger, AccountCodeAccumulatedDepreciation))
+	assert.Equal(t, models.Rupiah(-700000), ledgerBalance(t, ledger, AccountCodeAssetDisposalGain))
+	assert.Equal(t, models.Rupiah(2200000), ledgerBalance(t, ledger, AccountCodeAssetDisposalLoss))
+	assert.Equal(t, models.Rupiah(12500000-14400000), ledgerBalance(t, ledger, AccountCodeCash))
+
+	// Disposed assets are no longer depreciated and lock the earlier runs
+	march, err := service.RunDepreciation(2026, 3, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, march.Posted+march.Skipped)
+	_, err = service.ReverseDepreciationRun(february.RunID, "", 1)
+	assert.ErrorIs(t, err, ErrDepreciationRunAssetClosed)
+
+	report, err := service.GenerateAssetReport()
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.TotalAssets)
+	assert.Equal(t, 1, report.AssetsByStatus[AssetStatusSold])
+	assert.Equal(t, models.Rupiah(-1500000), report.TotalDisposalGain)
+}
+
+func TestAssetService_DepreciationScheduleFollowsMethod(t *testing.T) {
+	db := setupAssetTestDB(t)
+	service := NewAssetService(db)
+
+	freezer := &models.KitchenAsset{AssetCode: "AST-SCH", Name: "Freezer", PurchaseDate: time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local),
+		PurchasePrice: models.Rupiah(12000000), SalvageValue: models.Rupiah(500000), DepreciationMethod: DepreciationMethodDecliningBalance, UsefulLifeMonths: 48, Condition: "good"}
+	require.NoError(t, service.CreateAsset(freezer))
+
+	schedule, err := service.GetDepreciationSchedule(freezer.ID, 5)
+	require.NoError(t, err)
+	require.Len(t, schedule, 6)
+	assert.Equal(t, models.Rupiah(12000000), schedule[0].BookValue)
+
+	// Declining balance depreciates most in the first year
+	assert.Greater(t, schedule[1].AnnualDepreciation, schedule[2].AnnualDepreciation)
+	assert.Greater(t, schedule[2].AnnualDepreciation, schedule[3].AnnualDepreciation)
+	// and ends at the salvage value at the end of the useful life
+	assert.Equal(t, models.Rupiah(500000), schedule[4].BookValue)
+	assert.Equal(t, models.Rupiah(11500000), schedule[4].AccumulatedDepreciation)
+	assert.Equal(t, models.Rupiah(0), schedule[5].AnnualDepreciation)
+}
+
+func TestAssetService_ScheduledDepreciationRunsOncePerMonth(t *testing.T) {
+	db := setupAssetTestDB(t)
+	service := NewAssetService(db)
+
+	asset := &models.KitchenAsset{AssetCode: "AST-AUTO", Name: "Rice Cooker", PurchaseDate: time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local),
+		PurchasePrice: models.Rupiah(2400000), UsefulLifeMonths: 24, Condition: "good"}
+	require.NoError(t, service.CreateAsset(asset))
+
+	now := time.Date(2026, 3, 1, 1, 0, 0, 0, time.Local)
+	service.runScheduledDepreciation(now)
+	runs, err := service.GetDepreciationRuns(2026)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, 2, runs[0].Month)
+
+	// A reversed run is not posted again automatically
+	_, err = service.ReverseDepreciationRun(runs[0].ID, "", 1)
+	require.NoError(t, err)
+	service.runScheduledDepreciation(now)
+	runs, err = service.GetDepreciationRuns(2026)
+	require.NoError(t, err)
+	assert.Len(t, runs, 1)
+}
@@ -313,7 +313,9 @@ func (s *AttendanceService) GetAttendanceReport(startDate, endDate time.Time) ([
 		AverageHours float64
 	}
 
-	// Use DATE() function to compare only the date part, ignoring time and timezone
+	// Whole local days from startDate through endDate
+	rangeStart, _ := localDayRange(startDate)
+	_, rangeEnd := localDayRange(endDate)
 	err := s.db.Model(&models.Attendance{}).
 		Select(`
 			attendances.employee_id,
@@ -324,9 +326,7 @@ func (s *AttendanceService) GetAttendanceReport(startDate, endDate time.Time) ([
 			AVG(attendances.work_hours) as average_hours
 		`).
 		Joins("JOIN employees ON employees.id = attendances.employee_id").
-		Where("DATE(attendances.date) >= ? AND DATE(attendances.date) <= ?", 
-			startDate.Format("2006-01-02"), 
-			endDate.Format("2006-01-02")).
+		Where("attendances.date >= ? AND attendances.date < ?", rangeStart, rangeEnd).
 		Group("attendances.employee_id, employees.full_name, employees.position").
 		Scan(&results).Error
 
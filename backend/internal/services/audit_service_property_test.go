@@ -10,14 +10,13 @@ import (
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 // setupAuditTestDB creates an in-memory SQLite database for audit testing
 func setupAuditTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+	db, err := openTestDB(t, ":memory:", &gorm.Config{
 		Logger: logger.Discard,
 	})
 	if err != nil {
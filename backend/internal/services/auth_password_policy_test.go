@@ -0,0 +1,211 @@
+package services
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupPasswordPolicyTestDB(t *testing.T) *gorm.DB {
+	db, err := openTestDB(t, ":memory:", &gorm.Config{
+		Logger: logger.Discard,
+	})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Employee{}, &models.PasswordHistory{}, &models.UserSession{}, &models.RefreshToken{}, &models.Role{}, &models.TwoFactorRecoveryCode{}, &models.AuditTrail{}, &models.SystemConfig{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func createPolicyTestUser(t *testing.T, db *gorm.DB, authService *AuthService, password string) *models.User {
+	hash, err := authService.HashPassword(password)
+	require.NoError(t, err)
+
+	user := &models.User{
+		NIK:          "3201010101010001",
+		Email:        "policy@sppg.test",
+		PasswordHash: hash,
+		FullName:     "Policy Test",
+		Role:         "akuntan",
+		IsActive:     true,
+	}
+	require.NoError(t, db.Create(user).Error)
+	return user
+}
+
+func TestAuthService_LockoutAfterMaxFailedAttempts(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	configService := NewSystemConfigService(db)
+	require.NoError(t, configService.SetConfig("security_max_login_attempts", "3", "int", "security", 1))
+	require.NoError(t, configService.SetConfig("security_lockout_duration", "15", "int", "security", 1))
+
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+
+	_, _, err := authService.Login(user.NIK, "salah")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, _, err = authService.Login(user.NIK, "salah")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, _, err = authService.Login(user.NIK, "salah")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+
+	// Even the correct password is rejected while locked
+	_, _, err = authService.Login(user.NIK, "Rahasia123")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+
+	var audit models.AuditTrail
+	require.NoError(t, db.Where("action = ? AND user_id = ?", "lockout", user.ID).First(&audit).Error)
+
+	require.NoError(t, authService.UnlockAccount(user.ID))
+	_, token, err := authService.Login(user.NIK, "Rahasia123")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	var reloaded models.User
+	require.NoError(t, db.First(&reloaded, user.ID).Error)
+	assert.Equal(t, 0, reloaded.FailedLoginAttempts)
+	assert.Nil(t, reloaded.LockedUntil)
+}
+
+func TestAuthService_ParallelFailedLoginsAllCount(t *testing.T) {
+	// A file database, so the logins really run in parallel
+	db, err := openTestDB(t, filepath.Join(t.TempDir(), "auth.db")+"?_busy_timeout=5000&_txlock=immediate", &gorm.Config{
+		Logger: logger.Discard,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.PasswordHistory{}, &models.UserSession{}, &models.RefreshToken{}, &models.Role{}, &models.TwoFactorRecoveryCode{}, &models.AuditTrail{}, &models.SystemConfig{}))
+	authService := NewAuthService(db, "test-secret")
+	require.NoError(t, NewSystemConfigService(db).SetConfig("security_max_login_attempts", "5", "int", "security", 1))
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			authService.Login(user.NIK, "salah")
+		}()
+	}
+	wg.Wait()
+
+	var reloaded models.User
+	require.NoError(t, db.First(&reloaded, user.ID).Error)
+	assert.Equal(t, 8, reloaded.FailedLoginAttempts, "every failed login counts")
+	assert.True(t, reloaded.IsLocked(time.Now()))
+}
+
+func TestAuthService_ExpiredLockIsLifted(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+
+	past := time.Now().Add(-time.Minute)
+	require.NoError(t, db.Model(user).Updates(map[string]interface{}{"locked_until": past, "failed_login_attempts": 5}).Error)
+
+	_, _, err := authService.Login(user.NIK, "Rahasia123")
+	assert.NoError(t, err)
+}
+
+func TestAuthService_ExpiredLockRestartsFailedCount(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+
+	past := time.Now().Add(-time.Minute)
+	require.NoError(t, db.Model(user).Updates(map[string]interface{}{"locked_until": past, "failed_login_attempts": 5}).Error)
+
+	_, _, err := authService.Login(user.NIK, "salah")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	var reloaded models.User
+	require.NoError(t, db.First(&reloaded, user.ID).Error)
+	assert.Equal(t, 1, reloaded.FailedLoginAttempts)
+	assert.False(t, reloaded.IsLocked(time.Now()))
+}
+
+func TestAuthService_MustChangePasswordFlagInToken(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	employeeService := NewEmployeeService(db, authService)
+
+	employee := &models.Employee{
+		NIK:      "3201010101010002",
+		FullName: "Karyawan Baru",
+		Email:    "baru@sppg.test",
+		Position: "Staff",
+		JoinDate: time.Now(),
+		IsActive: true,
+	}
+	_, password, err := employeeService.CreateEmployee(employee, "chef")
+	require.NoError(t, err)
+
+	user, token, err := authService.Login(employee.NIK, password)
+	require.NoError(t, err)
+	assert.True(t, user.MustChangePassword)
+
+	claims, err := authService.ValidateToken(token)
+	require.NoError(t, err)
+	assert.True(t, claims.MustChangePassword)
+
+	newToken, err := authService.ChangePassword(user.ID, "", password, "PasswordBaru1")
+	require.NoError(t, err)
+	claims, err = authService.ValidateToken(newToken)
+	require.NoError(t, err)
+	assert.False(t, claims.MustChangePassword)
+
+	// A reset forces the change again
+	_, err = employeeService.ResetPassword(employee.ID)
+	require.NoError(t, err)
+	var reloaded models.User
+	require.NoError(t, db.First(&reloaded, user.ID).Error)
+	assert.True(t, reloaded.MustChangePassword)
+}
+
+func TestAuthService_ChangePasswordPolicy(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+
+	_, err := authService.ChangePassword(user.ID, "", "bukan-ini", "PasswordBaru1")
+	assert.ErrorIs(t, err, ErrCurrentPassword)
+
+	_, err = authService.ChangePassword(user.ID, "", "Rahasia123", "lemah")
+	assert.ErrorIs(t, err, utils.ErrPasswordTooShort)
+
+	_, err = authService.ChangePassword(user.ID, "", "Rahasia123", "Rahasia123")
+	assert.ErrorIs(t, err, ErrPasswordReused)
+
+	_, err = authService.ChangePassword(user.ID, "", "Rahasia123", "PasswordBaru1")
+	require.NoError(t, err)
+
+	// The previous password is remembered in the history
+	_, err = authService.ChangePassword(user.ID, "", "PasswordBaru1", "Rahasia123")
+	assert.ErrorIs(t, err, ErrPasswordReused)
+
+	var historyCount int64
+	db.Model(&models.PasswordHistory{}).Where("user_id = ?", user.ID).Count(&historyCount)
+	assert.Equal(t, int64(1), historyCount)
+}
+
+func TestAuthService_ExpiredPasswordRequiresChange(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	require.NoError(t, NewSystemConfigService(db).SetConfig("security_password_expiry_days", "30", "int", "security", 1))
+
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+	changedAt := time.Now().AddDate(0, 0, -31)
+	require.NoError(t, db.Model(user).Update("password_changed_at", changedAt).Error)
+
+	loggedIn, _, err := authService.Login(user.NIK, "Rahasia123")
+	require.NoError(t, err)
+	assert.True(t, loggedIn.MustChangePassword)
+}
@@ -2,9 +2,11 @@ package services
 
 import (
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/utils"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -15,59 +17,515 @@ var (
 	ErrUserNotFound       = errors.New("pengguna tidak ditemukan")
 	ErrUserInactive       = errors.New("akun tidak aktif")
 	ErrInvalidToken       = errors.New("token tidak valid")
+	ErrAccountLocked      = errors.New("akun terkunci karena terlalu banyak percobaan login gagal")
+	ErrCurrentPassword    = errors.New("password saat ini salah")
+	ErrPasswordReused     = errors.New("password baru tidak boleh sama dengan password sebelumnya")
 )
 
+// lockedIndefinitely is used as LockedUntil when lockout lasts until an admin unlocks the account
+var lockedIndefinitely = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
 // JWTClaims represents the claims in the JWT token
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
+	UserID             uint   `json:"user_id"`
+	Role               string `json:"role"`
+	SessionID          string `json:"sid,omitempty"`
+	MustChangePassword bool   `json:"must_change_password,omitempty"`
+	TwoFactorSetup     bool   `json:"two_factor_setup,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// TwoFactorChallengeClaims identify a user that passed the password step and
+// still has to enter a TOTP or recovery code. They are signed with a separate
+// key so a challenge token can never be used as an access token.
+type TwoFactorChallengeClaims struct {
+	UserID     uint   `json:"user_id"`
+	DeviceName string `json:"device_name,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TwoFactorChallengeTTL is how long the user has to enter the second factor
+const TwoFactorChallengeTTL = 5 * time.Minute
+
+// TokenPair is returned on login and refresh
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // access token lifetime in seconds
+	SessionID    string `json:"session_id"`
+}
+
+// Default token lifetimes, overridable with ConfigureSessions
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// PasswordPolicy holds the configurable password and lockout rules.
+// Values are read from SystemConfig (category "security").
+type PasswordPolicy struct {
+	MaxLoginAttempts int  // failed attempts before the account is locked, 0 disables lockout
+	LockoutMinutes   int  // lockout duration, 0 keeps the account locked until an admin unlocks it
+	HistoryCount     int  // number of previous passwords that cannot be reused
+	ExpiryDays       int  // days before a password must be changed, 0 disables expiry
+	RequireStrong    bool // enforce utils.ValidatePasswordStrength
+}
+
 // AuthService handles authentication operations
 type AuthService struct {
-	db        *gorm.DB
-	jwtSecret []byte
+	db             *gorm.DB
+	jwtSecret      []byte
+	accessTokenTTL time.Duration
+	sessions       *SessionService
+	twoFactor      *TwoFactorService
 }
 
 // NewAuthService creates a new authentication service
 func NewAuthService(db *gorm.DB, jwtSecret string) *AuthService {
 	return &AuthService{
-		db:        db,
-		jwtSecret: []byte(jwtSecret),
+		db:             db,
+		jwtSecret:      []byte(jwtSecret),
+		accessTokenTTL: DefaultAccessTokenTTL,
+		sessions:       NewSessionService(db, DefaultRefreshTokenTTL, 0),
+		twoFactor:      NewTwoFactorService(db),
 	}
 }
 
-// Login authenticates a user with NIK/Email and password
+// ConfigureSessions sets the token lifetimes and the session inactivity timeout
+func (s *AuthService) ConfigureSessions(accessTokenTTL, refreshTokenTTL, idleTimeout time.Duration) *AuthService {
+	s.accessTokenTTL = accessTokenTTL
+	s.sessions = NewSessionService(s.db, refreshTokenTTL, idleTimeout)
+	return s
+}
+
+// Sessions returns the session service used for server-side session tracking
+func (s *AuthService) Sessions() *SessionService {
+	return s.sessions
+}
+
+// TwoFactor returns the TOTP two-factor authentication service
+func (s *AuthService) TwoFactor() *TwoFactorService {
+	return s.twoFactor
+}
+
+// GetPasswordPolicy loads the current password policy from system configuration
+func (s *AuthService) GetPasswordPolicy() PasswordPolicy {
+	configService := NewSystemConfigService(s.db)
+	return PasswordPolicy{
+		MaxLoginAttempts: configService.GetConfigInt("security_max_login_attempts", 5),
+		LockoutMinutes:   configService.GetConfigInt("security_lockout_duration", 15),
+		HistoryCount:     configService.GetConfigInt("security_password_history", 5),
+		ExpiryDays:       configService.GetConfigInt("security_password_expiry_days", 90),
+		RequireStrong:    configService.GetConfigBool("security_strong_password", true),
+	}
+}
+
+// Login authenticates a user with NIK/Email and password and opens a session
+// without device information
 func (s *AuthService) Login(identifier, password string) (*models.User, string, error) {
+	user, tokens, err := s.LoginWithDevice(identifier, password, DeviceInfo{})
+	if err != nil {
+		return nil, "", err
+	}
+	return user, tokens.AccessToken, nil
+}
+
+// LoginWithDevice authenticates a user and opens a server-side session for the device.
+// Users with 2FA enabled get ErrTwoFactorRequired together with the user and
+// must finish the login with CompleteTwoFactorLogin.
+func (s *AuthService) LoginWithDevice(identifier, password string, device DeviceInfo) (*models.User, *TokenPair, error) {
+	user, err := s.Authenticate(identifier, password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return user, nil, ErrTwoFactorRequired
+	}
+
+	tokens, err := s.StartSession(user, device)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, tokens, nil
+}
+
+// Authenticate verifies the credentials and applies lockout and expiry rules
+// without opening a session
+func (s *AuthService) Authenticate(identifier, password string) (*models.User, error) {
 	var user models.User
 
 	// Find user by NIK or Email
 	result := s.db.Where("nik = ? OR email = ?", identifier, identifier).First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, "", ErrInvalidCredentials
+			return nil, ErrInvalidCredentials
 		}
-		return nil, "", result.Error
+		return nil, result.Error
 	}
 
 	// Check if user is active
 	if !user.IsActive {
-		return nil, "", ErrUserInactive
+		return nil, ErrUserInactive
 	}
 
+	now := time.Now()
+	if user.IsLocked(now) {
+		return nil, ErrAccountLocked
+	}
+
+	policy := s.GetPasswordPolicy()
+
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, "", ErrInvalidCredentials
+		if locked := s.recordFailedLogin(&user, policy, now); locked {
+			return nil, ErrAccountLocked
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	// Successful login clears the failure counter and any expired lock.
+	// With 2FA the counter is only cleared once the second factor is verified,
+	// otherwise a known password would allow unlimited code guesses.
+	updates := map[string]interface{}{}
+	if (user.FailedLoginAttempts > 0 && !user.TwoFactorEnabled) || user.LockedUntil != nil {
+		updates["failed_login_attempts"] = 0
+		updates["locked_until"] = nil
+		user.FailedLoginAttempts = 0
+		user.LockedUntil = nil
+	}
+
+	// An expired password must be changed before anything else
+	if !user.MustChangePassword && isPasswordExpired(&user, policy, now) {
+		updates["must_change_password"] = true
+		user.MustChangePassword = true
 	}
 
-	// Generate JWT token
-	token, err := s.GenerateToken(user.ID, user.Role)
+	if len(updates) > 0 {
+		if err := s.db.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+// StartSession opens a new server-side session and issues its token pair
+func (s *AuthService) StartSession(user *models.User, device DeviceInfo) (*TokenPair, error) {
+	session, refreshToken, err := s.sessions.CreateSession(user.ID, device)
 	if err != nil {
-		return nil, "", err
+		return nil, err
+	}
+
+	accessToken, err := s.generateUserToken(user, session.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
+		SessionID:    session.SessionID,
+	}, nil
+}
+
+// IssueTwoFactorChallenge creates the short-lived token that links the
+// password step to the second factor step of the login
+func (s *AuthService) IssueTwoFactorChallenge(user *models.User, deviceName string) (string, error) {
+	claims := TwoFactorChallengeClaims{
+		UserID:     user.ID,
+		DeviceName: deviceName,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TwoFactorChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.challengeKey())
+}
+
+// CompleteTwoFactorLogin verifies the second factor for a challenge and opens
+// the session. Wrong codes count as failed login attempts.
+func (s *AuthService) CompleteTwoFactorLogin(challengeToken, code string, device DeviceInfo) (*models.User, *TokenPair, error) {
+	token, err := jwt.ParseWithClaims(challengeToken, &TwoFactorChallengeClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidTwoFactorChallenge
+		}
+		return s.challengeKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, nil, ErrInvalidTwoFactorChallenge
+	}
+	claims, ok := token.Claims.(*TwoFactorChallengeClaims)
+	if !ok {
+		return nil, nil, ErrInvalidTwoFactorChallenge
+	}
+
+	user, err := s.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil, nil, ErrInvalidTwoFactorChallenge
+	}
+	if !user.IsActive {
+		return nil, nil, ErrUserInactive
+	}
+
+	now := time.Now()
+	if user.IsLocked(now) {
+		return nil, nil, ErrAccountLocked
 	}
 
-	return &user, token, nil
+	if err := s.twoFactor.VerifyCode(user, code); err != nil {
+		if err == ErrInvalidTwoFactorCode {
+			if locked := s.recordFailedLogin(user, s.GetPasswordPolicy(), now); locked {
+				return nil, nil, ErrAccountLocked
+			}
+		}
+		return nil, nil, err
+	}
+
+	if user.FailedLoginAttempts > 0 {
+		if err := s.db.Model(&models.User{}).Where("id = ?", user.ID).Update("failed_login_attempts", 0).Error; err != nil {
+			return nil, nil, err
+		}
+		user.FailedLoginAttempts = 0
+	}
+
+	if device.DeviceName == "" {
+		device.DeviceName = claims.DeviceName
+	}
+
+	tokens, err := s.StartSession(user, device)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokens, nil
+}
+
+// challengeKey derives the signing key for 2FA challenge tokens
+func (s *AuthService) challengeKey() []byte {
+	return append(append([]byte{}, s.jwtSecret...), []byte(":2fa-challenge")...)
+}
+
+// ReissueAccessToken returns a fresh access token for an existing session,
+// e.g. after the claims of the user changed
+func (s *AuthService) ReissueAccessToken(userID uint, sessionID string) (string, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+	return s.generateUserToken(user, sessionID)
+}
+
+// Logout revokes the session the access token belongs to
+func (s *AuthService) Logout(sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	err := s.sessions.RevokeSession(sessionID, SessionRevokedLogout)
+	if err == ErrSessionRevoked || err == ErrSessionNotFound {
+		return nil
+	}
+	return err
+}
+
+// recordFailedLogin increments the failure counter and locks the account
+// once the policy limit is reached. It returns true when the account was locked.
+// The counter is incremented in the database and read back in the same
+// transaction, so parallel failed logins each count and cannot skip the lockout.
+func (s *AuthService) recordFailedLogin(user *models.User, policy PasswordPolicy, now time.Time) bool {
+	var (
+		attempts    int
+		locked      bool
+		lockedUntil time.Time
+	)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// An expired lock starts a fresh count, otherwise the first wrong
+		// password after the lock ends would lock the account again
+		if err := tx.Model(&models.User{}).
+			Where("id = ? AND locked_until IS NOT NULL AND locked_until <= ?", user.ID, now).
+			Updates(map[string]interface{}{"failed_login_attempts": 0, "locked_until": nil}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Update("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Pluck("failed_login_attempts", &attempts).Error; err != nil {
+			return err
+		}
+
+		locked = policy.MaxLoginAttempts > 0 && attempts >= policy.MaxLoginAttempts
+		if !locked {
+			return nil
+		}
+		lockedUntil = lockedIndefinitely
+		if policy.LockoutMinutes > 0 {
+			lockedUntil = now.Add(time.Duration(policy.LockoutMinutes) * time.Minute)
+		}
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Update("locked_until", lockedUntil).Error
+	})
+	if err != nil {
+		return false
+	}
+	user.FailedLoginAttempts = attempts
+	if locked {
+		user.LockedUntil = &lockedUntil
+	} else {
+		user.LockedUntil = nil
+	}
+
+	if locked {
+		NewAuditTrailService(s.db).RecordAction(user.ID, "lockout", "user", strconv.FormatUint(uint64(user.ID), 10), nil, map[string]interface{}{
+			"failed_attempts": attempts,
+			"locked_until":    lockedUntil,
+		}, "")
+	}
+
+	return locked
+}
+
+// isPasswordExpired reports whether the user's password is older than the policy allows.
+// Accounts that never recorded a password change are not considered expired.
+func isPasswordExpired(user *models.User, policy PasswordPolicy, now time.Time) bool {
+	if policy.ExpiryDays <= 0 || user.PasswordChangedAt == nil {
+		return false
+	}
+	return now.After(user.PasswordChangedAt.AddDate(0, 0, policy.ExpiryDays))
+}
+
+// UnlockAccount clears the lockout state of a user account
+func (s *AuthService) UnlockAccount(userID uint) error {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+	}).Error
+}
+
+// ValidateNewPassword checks a candidate password against the policy and
+// the user's current and previous passwords
+func (s *AuthService) ValidateNewPassword(user *models.User, newPassword string) error {
+	policy := s.GetPasswordPolicy()
+	if policy.RequireStrong {
+		if err := utils.ValidatePasswordStrength(newPassword); err != nil {
+			return err
+		}
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(newPassword)) == nil {
+		return ErrPasswordReused
+	}
+
+	if policy.HistoryCount > 1 {
+		var history []models.PasswordHistory
+		// The current password counts as one of the remembered passwords
+		if err := s.db.Where("user_id = ?", user.ID).
+			Order("created_at DESC").
+			Limit(policy.HistoryCount - 1).
+			Find(&history).Error; err != nil {
+			return err
+		}
+		for _, h := range history {
+			if bcrypt.CompareHashAndPassword([]byte(h.PasswordHash), []byte(newPassword)) == nil {
+				return ErrPasswordReused
+			}
+		}
+	}
+
+	return nil
+}
+
+// ChangePassword verifies the current password, applies the policy and stores
+// the new password. All other sessions of the user are revoked and a fresh
+// access token without the password change flag is returned for sessionID.
+func (s *AuthService) ChangePassword(userID uint, sessionID, currentPassword, newPassword string) (string, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return "", ErrCurrentPassword
+	}
+
+	if err := s.ValidateNewPassword(user, newPassword); err != nil {
+		return "", err
+	}
+
+	if err := s.SetPassword(s.db, user, newPassword, false); err != nil {
+		return "", err
+	}
+
+	if _, err := s.sessions.RevokeAllUserSessions(user.ID, SessionRevokedPasswordChange, sessionID); err != nil {
+		return "", err
+	}
+
+	return s.generateUserToken(user, sessionID)
+}
+
+// SetPassword stores a new password for the user, keeps the previous hash in
+// the password history and sets whether a change is required on next login
+func (s *AuthService) SetPassword(tx *gorm.DB, user *models.User, newPassword string, mustChange bool) error {
+	hashedPassword, err := s.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return tx.Transaction(func(tx *gorm.DB) error {
+		if user.PasswordHash != "" {
+			if err := tx.Create(&models.PasswordHistory{
+				UserID:       user.ID,
+				PasswordHash: user.PasswordHash,
+				CreatedAt:    now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"password_hash":         hashedPassword,
+			"password_changed_at":   now,
+			"must_change_password":  mustChange,
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		user.PasswordHash = hashedPassword
+		user.PasswordChangedAt = &now
+		user.MustChangePassword = mustChange
+		user.FailedLoginAttempts = 0
+		user.LockedUntil = nil
+		return nil
+	})
+}
+
+// generateUserToken creates a short-lived access token bound to a session
+func (s *AuthService) generateUserToken(user *models.User, sessionID string) (string, error) {
+	claims := JWTClaims{
+		UserID:             user.ID,
+		Role:               user.Role,
+		SessionID:          sessionID,
+		MustChangePassword: user.MustChangePassword,
+		TwoFactorSetup:     !user.TwoFactorEnabled && s.twoFactor.IsRequiredForRole(user.Role),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.accessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.jwtSecret)
 }
 
 // GenerateToken creates a new JWT token for a user
@@ -106,29 +564,40 @@ func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
 	return nil, ErrInvalidToken
 }
 
-// RefreshToken generates a new token for an existing valid token
-func (s *AuthService) RefreshToken(tokenString string) (string, error) {
-	claims, err := s.ValidateToken(tokenString)
+// RefreshToken rotates an opaque refresh token and issues a new token pair
+// for the same session
+func (s *AuthService) RefreshToken(refreshToken string) (*TokenPair, error) {
+	session, newRefreshToken, err := s.sessions.RotateRefreshToken(refreshToken)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Verify user still exists and is active
 	var user models.User
-	result := s.db.First(&user, claims.UserID)
+	result := s.db.First(&user, session.UserID)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return "", ErrUserNotFound
+			return nil, ErrUserNotFound
 		}
-		return "", result.Error
+		return nil, result.Error
 	}
 
 	if !user.IsActive {
-		return "", ErrUserInactive
+		s.sessions.RevokeSession(session.SessionID, SessionRevokedDeactivated)
+		return nil, ErrUserInactive
+	}
+
+	accessToken, err := s.generateUserToken(&user, session.SessionID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate new token
-	return s.GenerateToken(user.ID, user.Role)
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
+		SessionID:    session.SessionID,
+	}, nil
 }
 
 // HashPassword hashes a password using bcrypt
@@ -8,7 +8,6 @@ import (
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -16,7 +15,7 @@ import (
 // setupTestDB creates an in-memory SQLite database for testing
 func setupTestDB(t *testing.T) *gorm.DB {
 	// Use SQLite in-memory database for property tests
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+	db, err := openTestDB(t, ":memory:", &gorm.Config{
 		Logger: logger.Discard,
 	})
 	if err != nil {
@@ -24,7 +23,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Auto-migrate the schema
-	err = db.AutoMigrate(&models.User{})
+	err = db.AutoMigrate(&models.User{}, &models.UserSession{}, &models.RefreshToken{})
 	if err != nil {
 		t.Fatalf("Failed to migrate schema: %v", err)
 	}
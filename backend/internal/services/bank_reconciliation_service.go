@@ -0,0 +1,810 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBankStatementNotFound      = errors.New("mutasi rekening tidak ditemukan")
+	ErrBankStatementLineNotFound  = errors.New("baris mutasi rekening tidak ditemukan")
+	ErrBankStatementAlreadyLoaded = errors.New("seluruh transaksi pada mutasi rekening sudah pernah diimpor")
+	ErrBankLineAlreadyMatched     = errors.New("baris mutasi rekening sudah dicocokkan")
+	ErrBankLineNotMatched         = errors.New("baris mutasi rekening belum dicocokkan")
+	ErrBookItemNotFound           = errors.New("transaksi buku tidak ditemukan")
+	ErrBookItemAlreadyMatched     = errors.New("transaksi buku sudah dicocokkan dengan baris mutasi lain")
+	ErrInvalidBookItemType        = errors.New("jenis transaksi buku tidak valid (gunakan cash_flow atau supplier_payment)")
+	ErrPayableEntryNotMatchable   = errors.New("pengeluaran utang usaha tidak dapat dicocokkan, cocokkan dengan pembayaran supplier")
+	ErrBankMatchDirectionMismatch = errors.New("arah transaksi bank dan buku tidak sesuai")
+	ErrBankMatchAmountMismatch    = errors.New("nominal transaksi bank dan buku tidak sama")
+)
+
+// Book item types a bank line can be matched with
+const (
+	BookItemCashFlow        = "cash_flow"
+	BookItemSupplierPayment = "supplier_payment"
+)
+
+// Bank line match statuses and types
+const (
+	BankLineUnmatched = "unmatched"
+	BankLineMatched   = "matched"
+	BankMatchAuto     = "auto"
+	BankMatchManual   = "manual"
+)
+
+const (
+	// defaultBankMatchDateTolerance is the number of days a bank line may be
+	// booked before or after its book item and still be matched automatically
+	defaultBankMatchDateTolerance = 3
+	// bankCandidateWindowDays limits the manual match candidates around a bank line
+	bankCandidateWindowDays = 31
+)
+
+// BankBookItem is a book transaction that moves money through the bank:
+// a cash flow entry that is not a payable, or a supplier transfer payment
+type BankBookItem struct {
+	Type        string       `json:"type"` // cash_flow, supplier_payment
+	ID          uint         `json:"id"`
+	Date        time.Time    `json:"date"`
+	Direction   string       `json:"direction"` // credit (money in), debit (money out)
+	Amount      models.Money `json:"amount"`
+	Number      string       `json:"number"` // transaction ID or payment number
+	Reference   string       `json:"reference"`
+	Description string       `json:"description"`
+}
+
+// BankMatchCandidate is a book item proposed for a bank line
+type BankMatchCandidate struct {
+	BankBookItem
+	DaysApart      int  `json:"days_apart"`
+	AmountMatches  bool `json:"amount_matches"`
+	ReferenceMatch bool `json:"reference_match"`
+}
+
+// BankImportResult summarises a bank statement import
+type BankImportResult struct {
+	Statement   *models.BankStatement `json:"statement"`
+	Imported    int                   `json:"imported"`
+	Skipped     int                   `json:"skipped"` // lines already imported from an overlapping statement
+	AutoMatched int                   `json:"auto_matched"`
+}
+
+// BankLineFilter filters bank statement lines
+type BankLineFilter struct {
+	StatementID   uint
+	AccountNumber string
+	MatchStatus   string
+	StartDate     *time.Time
+	EndDate       *time.Time
+}
+
+// BankReconciliationPeriod compares the book and bank balance at the end of a month
+type BankReconciliationPeriod struct {
+	PeriodStart           time.Time    `json:"period_start"`
+	PeriodEnd             time.Time    `json:"period_end"`
+	BankBalance           models.Money `json:"bank_balance"`
+	BookBalance           models.Money `json:"book_balance"`
+	Difference            models.Money `json:"difference"` // bank - book
+	UnmatchedBankLines    int          `json:"unmatched_bank_lines"`
+	UnmatchedBankNet      models.Money `json:"unmatched_bank_net"` // of the period
+	UnmatchedBookItems    int          `json:"unmatched_book_items"`
+	UnmatchedBookNet      models.Money `json:"unmatched_book_net"`     // of the period
+	UnexplainedDifference models.Money `json:"unexplained_difference"` // difference not explained by unmatched items up to the period end
+	Reconciled            bool         `json:"reconciled"`
+}
+
+// BankReconciliationReport lists the reconciliation per month and the unmatched items
+type BankReconciliationReport struct {
+	AccountNumber      string                     `json:"account_number"`
+	StartDate          time.Time                  `json:"start_date"`
+	EndDate            time.Time                  `json:"end_date"`
+	Periods            []BankReconciliationPeriod `json:"periods"`
+	UnmatchedBankLines []models.BankStatementLine `json:"unmatched_bank_lines"`
+	UnmatchedBookItems []BankBookItem             `json:"unmatched_book_items"`
+	GeneratedAt        time.Time                  `json:"generated_at"`
+}
+
+// BankReconciliationService imports bank statements and reconciles them with
+// cash flow entries and supplier payments
+type BankReconciliationService struct {
+	db *gorm.DB
+}
+
+// NewBankReconciliationService creates a new bank reconciliation service
+func NewBankReconciliationService(db *gorm.DB) *BankReconciliationService {
+	return &BankReconciliationService{
+		db: db,
+	}
+}
+
+// ImportStatement parses a bank statement file, stores the lines that were not
+// imported before and auto-matches them. BankName and accountNumber override
+// what the file provides.
+func (s *BankReconciliationService) ImportStatement(filename string, content []byte, format, bankName, accountNumber string, userID uint) (*BankImportResult, error) {
+	parsed, err := ParseBankStatement(filename, content, format)
+	if err != nil {
+		return nil, err
+	}
+	if accountNumber = normalizeAccountNumber(accountNumber); accountNumber == "" {
+		accountNumber = parsed.AccountNumber
+	}
+
+	statement := &models.BankStatement{
+		BankName:       strings.TrimSpace(bankName),
+		AccountNumber:  accountNumber,
+		Format:         parsed.Format,
+		FileName:       filename,
+		PeriodStart:    parsed.PeriodStart,
+		PeriodEnd:      parsed.PeriodEnd,
+		OpeningBalance: *parsed.OpeningBalance,
+		ClosingBalance: *parsed.ClosingBalance,
+		ImportedBy:     userID,
+	}
+
+	// Identical mutations on the same day are told apart by their occurrence
+	occurrences := make(map[string]int)
+	lines := make([]models.BankStatementLine, 0, len(parsed.Lines))
+	keys := make([]string, 0, len(parsed.Lines))
+	for _, line := range parsed.Lines {
+		base := bankLineKey(accountNumber, line)
+		occurrences[base]++
+		key := dedupKey(base, occurrences[base])
+
+		lines = append(lines, models.BankStatementLine{
+			AccountNumber:   accountNumber,
+			LineNumber:      line.LineNumber,
+			TransactionDate: line.Date,
+			Description:     line.Description,
+			Reference:       line.Reference,
+			Direction:       line.Direction,
+			Amount:          line.Amount,
+			Balance:         line.Balance,
+			DedupKey:        key,
+			MatchStatus:     BankLineUnmatched,
+		})
+		keys = append(keys, key)
+	}
+
+	var existing []string
+	if err := s.db.Model(&models.BankStatementLine{}).Where("dedup_key IN ?", keys).Pluck("dedup_key", &existing).Error; err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, key := range existing {
+		known[key] = true
+	}
+
+	result := &BankImportResult{Statement: statement}
+	newLines := make([]models.BankStatementLine, 0, len(lines))
+	for _, line := range lines {
+		if known[line.DedupKey] {
+			result.Skipped++
+			continue
+		}
+		if line.Direction == BankDirectionDebit {
+			statement.TotalDebit += line.Amount
+		} else {
+			statement.TotalCredit += line.Amount
+		}
+		newLines = append(newLines, line)
+	}
+	if len(newLines) == 0 {
+		return result, ErrBankStatementAlreadyLoaded
+	}
+	statement.LineCount = len(newLines)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(statement).Error; err != nil {
+			return err
+		}
+		for i := range newLines {
+			newLines[i].StatementID = statement.ID
+		}
+		return tx.CreateInBatches(newLines, 100).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(newLines)
+
+	if result.AutoMatched, err = s.AutoMatch(statement.ID); err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "import", "bank_statement", strconv.FormatUint(uint64(statement.ID), 10), nil, map[string]interface{}{
+		"file_name": filename, "account_number": accountNumber, "imported": result.Imported, "skipped": result.Skipped, "auto_matched": result.AutoMatched,
+	}, "")
+	return result, nil
+}
+
+// GetStatements lists imported bank statements, newest period first
+func (s *BankReconciliationService) GetStatements(accountNumber string) ([]models.BankStatement, error) {
+	query := s.db.Model(&models.BankStatement{})
+	if accountNumber = normalizeAccountNumber(accountNumber); accountNumber != "" {
+		query = query.Where("account_number = ?", accountNumber)
+	}
+
+	var statements []models.BankStatement
+	err := query.Order("period_end DESC, id DESC").Find(&statements).Error
+	return statements, err
+}
+
+// GetStatement returns a bank statement with its lines and matched book items
+func (s *BankReconciliationService) GetStatement(id uint) (*models.BankStatement, error) {
+	var statement models.BankStatement
+	err := s.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("transaction_date ASC, line_number ASC")
+	}).Preload("Lines.CashFlowEntry").Preload("Lines.SupplierPayment").First(&statement, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBankStatementNotFound
+		}
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// DeleteStatement deletes an imported statement, releasing its matched book items
+func (s *BankReconciliationService) DeleteStatement(id uint, userID uint) error {
+	var statement models.BankStatement
+	if err := s.db.First(&statement, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBankStatementNotFound
+		}
+		return err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("statement_id = ?", id).Delete(&models.BankStatementLine{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&statement).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "delete", "bank_statement", strconv.FormatUint(uint64(id), 10), statement, nil, "")
+	return nil
+}
+
+// GetLines lists bank statement lines with their matched book items
+func (s *BankReconciliationService) GetLines(filter BankLineFilter) ([]models.BankStatementLine, error) {
+	query := s.db.Preload("CashFlowEntry").Preload("SupplierPayment")
+	if filter.StatementID > 0 {
+		query = query.Where("statement_id = ?", filter.StatementID)
+	}
+	if account := normalizeAccountNumber(filter.AccountNumber); account != "" {
+		query = query.Where("account_number = ?", account)
+	}
+	if filter.MatchStatus != "" {
+		query = query.Where("match_status = ?", filter.MatchStatus)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("transaction_date >= ?", startOfDay(*filter.StartDate))
+	}
+	if filter.EndDate != nil {
+		query = query.Where("transaction_date < ?", nextDay(*filter.EndDate))
+	}
+
+	var lines []models.BankStatementLine
+	err := query.Order("transaction_date ASC, id ASC").Find(&lines).Error
+	return lines, err
+}
+
+// GetMatchCandidates proposes unmatched book items in the same direction within
+// a month of the bank line, best candidates first
+func (s *BankReconciliationService) GetMatchCandidates(lineID uint) ([]BankMatchCandidate, error) {
+	line, err := s.getLine(s.db, lineID)
+	if err != nil {
+		return nil, err
+	}
+
+	window := time.Duration(bankCandidateWindowDays) * 24 * time.Hour
+	items, err := s.loadBookItems(s.db, line.TransactionDate.Add(-window), line.TransactionDate.Add(window))
+	if err != nil {
+		return nil, err
+	}
+	matched, err := s.matchedBookItems(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]BankMatchCandidate, 0)
+	for _, item := range items {
+		if item.Direction != line.Direction || matched[bookItemKey(item.Type, item.ID)] {
+			continue
+		}
+		candidates = append(candidates, BankMatchCandidate{
+			BankBookItem:   item,
+			DaysApart:      daysApart(line.TransactionDate, item.Date),
+			AmountMatches:  line.Amount == item.Amount,
+			ReferenceMatch: referenceMatches(*line, item),
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.AmountMatches != b.AmountMatches {
+			return a.AmountMatches
+		}
+		if a.ReferenceMatch != b.ReferenceMatch {
+			return a.ReferenceMatch
+		}
+		return a.DaysApart < b.DaysApart
+	})
+	return candidates, nil
+}
+
+// AutoMatch matches the unmatched lines of a statement, or of all statements
+// when statementID is 0, with book items of the same direction and amount
+// booked within the date tolerance. A matching reference wins over a closer
+// date; lines with several equally good candidates are left for manual matching.
+func (s *BankReconciliationService) AutoMatch(statementID uint) (int, error) {
+	query := s.db.Where("match_status = ?", BankLineUnmatched)
+	if statementID > 0 {
+		query = query.Where("statement_id = ?", statementID)
+	}
+	var lines []models.BankStatementLine
+	if err := query.Order("transaction_date ASC, id ASC").Find(&lines).Error; err != nil {
+		return 0, err
+	}
+	if len(lines) == 0 {
+		return 0, nil
+	}
+
+	tolerance := NewSystemConfigService(s.db).GetConfigInt("bank_match_date_tolerance_days", defaultBankMatchDateTolerance)
+	margin := time.Duration(tolerance) * 24 * time.Hour
+	items, err := s.loadBookItems(s.db, lines[0].TransactionDate.Add(-margin), lines[len(lines)-1].TransactionDate.Add(margin))
+	if err != nil {
+		return 0, err
+	}
+	used, err := s.matchedBookItems(s.db)
+	if err != nil {
+		return 0, err
+	}
+
+	matchedCount := 0
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range lines {
+			line := &lines[i]
+
+			var best *BankBookItem
+			bestScore, ties := math.MinInt, 0
+			for j := range items {
+				item := &items[j]
+				if used[bookItemKey(item.Type, item.ID)] || item.Direction != line.Direction || item.Amount != line.Amount {
+					continue
+				}
+				days := daysApart(line.TransactionDate, item.Date)
+				if days > tolerance {
+					continue
+				}
+
+				score := -days
+				if referenceMatches(*line, *item) {
+					score += 1000
+				}
+				switch {
+				case score > bestScore:
+					best, bestScore, ties = item, score, 1
+				case score == bestScore:
+					ties++
+				}
+			}
+			if best == nil || ties > 1 {
+				continue
+			}
+
+			if err := s.applyMatch(tx, line, best.Type, best.ID, BankMatchAuto, nil); err != nil {
+				return err
+			}
+			used[bookItemKey(best.Type, best.ID)] = true
+			matchedCount++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return matchedCount, nil
+}
+
+// MatchLine manually matches a bank line with a book item of the same direction and amount
+func (s *BankReconciliationService) MatchLine(lineID uint, itemType string, itemID uint, userID uint) (*models.BankStatementLine, error) {
+	var line *models.BankStatementLine
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if line, err = s.getLine(tx, lineID); err != nil {
+			return err
+		}
+		if line.MatchStatus == BankLineMatched {
+			return ErrBankLineAlreadyMatched
+		}
+
+		item, err := s.getBookItem(tx, itemType, itemID)
+		if err != nil {
+			return err
+		}
+		if item.Direction != line.Direction {
+			return ErrBankMatchDirectionMismatch
+		}
+		if item.Amount != line.Amount {
+			return ErrBankMatchAmountMismatch
+		}
+
+		column := "cash_flow_entry_id"
+		if itemType == BookItemSupplierPayment {
+			column = "supplier_payment_id"
+		}
+		var count int64
+		if err := tx.Model(&models.BankStatementLine{}).Where(column+" = ?", itemID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrBookItemAlreadyMatched
+		}
+
+		return s.applyMatch(tx, line, itemType, itemID, BankMatchManual, &userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "match", "bank_statement_line", strconv.FormatUint(uint64(lineID), 10), nil, map[string]interface{}{
+		"book_type": itemType, "book_id": itemID,
+	}, "")
+	return s.getLine(s.db, lineID)
+}
+
+// UnmatchLine releases the book item matched with a bank line
+func (s *BankReconciliationService) UnmatchLine(lineID uint, userID uint) error {
+	line, err := s.getLine(s.db, lineID)
+	if err != nil {
+		return err
+	}
+	if line.MatchStatus != BankLineMatched {
+		return ErrBankLineNotMatched
+	}
+
+	err = s.db.Model(&models.BankStatementLine{}).Where("id = ?", lineID).Updates(map[string]interface{}{
+		"match_status":        BankLineUnmatched,
+		"match_type":          "",
+		"cash_flow_entry_id":  nil,
+		"supplier_payment_id": nil,
+		"matched_by":          nil,
+		"matched_at":          nil,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "unmatch", "bank_statement_line", strconv.FormatUint(uint64(lineID), 10), map[string]interface{}{
+		"match_type": line.MatchType, "cash_flow_entry_id": line.CashFlowEntryID, "supplier_payment_id": line.SupplierPaymentID,
+	}, nil, "")
+	return nil
+}
+
+// GetReconciliationReport compares the bank and book balance at the end of
+// every month between start and end. The bank balance starts from the opening
+// balance of the first imported statement of each account; the book balance
+// is the net of all bank book items. Book items dated before the first
+// statement are covered by its opening balance and never reported as
+// unmatched. The difference not explained by unmatched items on either side
+// should be zero once the account reconciles.
+func (s *BankReconciliationService) GetReconciliationReport(accountNumber string, start, end time.Time) (*BankReconciliationReport, error) {
+	if end.Before(start) {
+		return nil, ErrInvalidDateRange
+	}
+	accountNumber = normalizeAccountNumber(accountNumber)
+
+	statementQuery := s.db.Model(&models.BankStatement{})
+	lineQuery := s.db.Where("transaction_date < ?", nextDay(end))
+	if accountNumber != "" {
+		statementQuery = statementQuery.Where("account_number = ?", accountNumber)
+		lineQuery = lineQuery.Where("account_number = ?", accountNumber)
+	}
+
+	var statements []models.BankStatement
+	if err := statementQuery.Order("period_start ASC, id ASC").Find(&statements).Error; err != nil {
+		return nil, err
+	}
+	// Book items before the first statement are covered by its opening balance
+	var historyStart time.Time
+	if len(statements) > 0 {
+		historyStart = startOfDay(statements[0].PeriodStart)
+	}
+	var bankOpening models.Money
+	seenAccounts := make(map[string]bool)
+	for _, statement := range statements {
+		if !seenAccounts[statement.AccountNumber] {
+			seenAccounts[statement.AccountNumber] = true
+			bankOpening += statement.OpeningBalance
+		}
+	}
+
+	var lines []models.BankStatementLine
+	if err := lineQuery.Order("transaction_date ASC, id ASC").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	items, err := s.loadBookItems(s.db, time.Time{}, end)
+	if err != nil {
+		return nil, err
+	}
+	matched, err := s.matchedBookItems(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BankReconciliationReport{
+		AccountNumber:      accountNumber,
+		StartDate:          startOfDay(start),
+		EndDate:            startOfDay(end),
+		UnmatchedBankLines: make([]models.BankStatementLine, 0),
+		UnmatchedBookItems: make([]BankBookItem, 0),
+		GeneratedAt:        time.Now(),
+	}
+	for _, line := range lines {
+		if line.MatchStatus != BankLineMatched && !line.TransactionDate.Before(report.StartDate) {
+			report.UnmatchedBankLines = append(report.UnmatchedBankLines, line)
+		}
+	}
+	for _, item := range items {
+		if !matched[bookItemKey(item.Type, item.ID)] && !item.Date.Before(report.StartDate) && !item.Date.Before(historyStart) {
+			report.UnmatchedBookItems = append(report.UnmatchedBookItems, item)
+		}
+	}
+
+	for periodStart := report.StartDate; !periodStart.After(report.EndDate); {
+		monthEnd := time.Date(periodStart.Year(), periodStart.Month()+1, 0, 0, 0, 0, 0, periodStart.Location())
+		if monthEnd.After(report.EndDate) {
+			monthEnd = report.EndDate
+		}
+		periodEnd := nextDay(monthEnd)
+
+		period := BankReconciliationPeriod{PeriodStart: periodStart, PeriodEnd: monthEnd, BankBalance: bankOpening}
+		var unmatchedBankToDate, unmatchedBookToDate models.Money
+		for _, line := range lines {
+			if !line.TransactionDate.Before(periodEnd) {
+				continue
+			}
+			amount := signedBankAmount(line.Direction, line.Amount)
+			period.BankBalance += amount
+			if line.MatchStatus != BankLineMatched {
+				unmatchedBankToDate += amount
+				if !line.TransactionDate.Before(periodStart) {
+					period.UnmatchedBankLines++
+					period.UnmatchedBankNet += amount
+				}
+			}
+		}
+		for _, item := range items {
+			if !item.Date.Before(periodEnd) {
+				continue
+			}
+			amount := signedBankAmount(item.Direction, item.Amount)
+			period.BookBalance += amount
+			if !matched[bookItemKey(item.Type, item.ID)] && !item.Date.Before(historyStart) {
+				unmatchedBookToDate += amount
+				if !item.Date.Before(periodStart) {
+					period.UnmatchedBookItems++
+					period.UnmatchedBookNet += amount
+				}
+			}
+		}
+
+		period.Difference = period.BankBalance - period.BookBalance
+		period.UnexplainedDifference = (period.BankBalance - unmatchedBankToDate) - (period.BookBalance - unmatchedBookToDate)
+		period.Reconciled = period.UnexplainedDifference == 0
+		report.Periods = append(report.Periods, period)
+
+		periodStart = periodEnd
+	}
+
+	return report, nil
+}
+
+// applyMatch links a bank line with a book item
+func (s *BankReconciliationService) applyMatch(tx *gorm.DB, line *models.BankStatementLine, itemType string, itemID uint, matchType string, userID *uint) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"match_status": BankLineMatched,
+		"match_type":   matchType,
+		"matched_by":   userID,
+		"matched_at":   now,
+	}
+	if itemType == BookItemSupplierPayment {
+		updates["supplier_payment_id"] = itemID
+	} else {
+		updates["cash_flow_entry_id"] = itemID
+	}
+	if err := tx.Model(&models.BankStatementLine{}).Where("id = ?", line.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	line.MatchStatus = BankLineMatched
+	line.MatchType = matchType
+	line.MatchedBy = userID
+	line.MatchedAt = &now
+	return nil
+}
+
+// getLine loads a bank line with its matched book item
+func (s *BankReconciliationService) getLine(db *gorm.DB, id uint) (*models.BankStatementLine, error) {
+	var line models.BankStatementLine
+	if err := db.Preload("CashFlowEntry").Preload("SupplierPayment").First(&line, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBankStatementLineNotFound
+		}
+		return nil, err
+	}
+	return &line, nil
+}
+
+// getBookItem loads a single book item for manual matching
+func (s *BankReconciliationService) getBookItem(db *gorm.DB, itemType string, id uint) (*BankBookItem, error) {
+	switch itemType {
+	case BookItemCashFlow:
+		var entry models.CashFlowEntry
+		if err := db.First(&entry, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrBookItemNotFound
+			}
+			return nil, err
+		}
+		if entry.IsPayable {
+			return nil, ErrPayableEntryNotMatchable
+		}
+		item := cashFlowBookItem(entry)
+		return &item, nil
+	case BookItemSupplierPayment:
+		var payment models.SupplierPayment
+		if err := db.First(&payment, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrBookItemNotFound
+			}
+			return nil, err
+		}
+		item := supplierPaymentBookItem(payment)
+		return &item, nil
+	}
+	return nil, ErrInvalidBookItemType
+}
+
+// loadBookItems returns the book items dated within the inclusive range.
+// Payable expenses are left out: the money leaves the bank with the supplier
+// payment, not with the goods receipt.
+func (s *BankReconciliationService) loadBookItems(db *gorm.DB, start, end time.Time) ([]BankBookItem, error) {
+	var entries []models.CashFlowEntry
+	err := db.Where("is_payable = ? AND date >= ? AND date < ?", false, startOfDay(start), nextDay(end)).
+		Order("date ASC, id ASC").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var payments []models.SupplierPayment
+	err = db.Where("method = ? AND payment_date >= ? AND payment_date < ?", "transfer", startOfDay(start), nextDay(end)).
+		Order("payment_date ASC, id ASC").Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]BankBookItem, 0, len(entries)+len(payments))
+	for _, entry := range entries {
+		items = append(items, cashFlowBookItem(entry))
+	}
+	for _, payment := range payments {
+		items = append(items, supplierPaymentBookItem(payment))
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Date.Before(items[j].Date) })
+	return items, nil
+}
+
+// matchedBookItems returns the keys of all book items matched with a bank line
+func (s *BankReconciliationService) matchedBookItems(db *gorm.DB) (map[string]bool, error) {
+	var lines []models.BankStatementLine
+	err := db.Select("cash_flow_entry_id", "supplier_payment_id").
+		Where("cash_flow_entry_id IS NOT NULL OR supplier_payment_id IS NOT NULL").Find(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		if line.CashFlowEntryID != nil {
+			matched[bookItemKey(BookItemCashFlow, *line.CashFlowEntryID)] = true
+		}
+		if line.SupplierPaymentID != nil {
+			matched[bookItemKey(BookItemSupplierPayment, *line.SupplierPaymentID)] = true
+		}
+	}
+	return matched, nil
+}
+
+// cashFlowBookItem converts a cash flow entry to a book item
+func cashFlowBookItem(entry models.CashFlowEntry) BankBookItem {
+	direction := BankDirectionDebit
+	if entry.Type == "income" {
+		direction = BankDirectionCredit
+	}
+	return BankBookItem{
+		Type:        BookItemCashFlow,
+		ID:          entry.ID,
+		Date:        entry.Date,
+		Direction:   direction,
+		Amount:      entry.Amount,
+		Number:      entry.TransactionID,
+		Reference:   entry.Reference,
+		Description: entry.Description,
+	}
+}
+
+// supplierPaymentBookItem converts a supplier transfer payment to a book item
+func supplierPaymentBookItem(payment models.SupplierPayment) BankBookItem {
+	return BankBookItem{
+		Type:        BookItemSupplierPayment,
+		ID:          payment.ID,
+		Date:        payment.PaymentDate,
+		Direction:   BankDirectionDebit,
+		Amount:      payment.Amount,
+		Number:      payment.PaymentNumber,
+		Reference:   payment.BankReference,
+		Description: payment.Notes,
+	}
+}
+
+// referenceMatches reports whether the bank line mentions the number or
+// reference of the book item
+func referenceMatches(line models.BankStatementLine, item BankBookItem) bool {
+	text := strings.ToUpper(line.Reference + " " + line.Description)
+	for _, needle := range []string{item.Number, item.Reference} {
+		needle = strings.ToUpper(strings.TrimSpace(needle))
+		if len(needle) >= 4 && strings.Contains(text, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// bookItemKey identifies a book item across types
+func bookItemKey(itemType string, id uint) string {
+	return itemType + ":" + strconv.FormatUint(uint64(id), 10)
+}
+
+// bankLineKey describes a mutation independent of the file it was read from
+func bankLineKey(accountNumber string, line ParsedBankLine) string {
+	return strings.Join([]string{
+		accountNumber,
+		line.Date.Format("2006-01-02"),
+		line.Direction,
+		strconv.FormatInt(int64(line.Amount), 10),
+		strings.ToUpper(line.Reference),
+		strings.ToUpper(strings.Join(strings.Fields(line.Description), " ")),
+	}, "|")
+}
+
+// dedupKey hashes a mutation and its occurrence within the statement
+func dedupKey(base string, occurrence int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", base, occurrence)))
+	return hex.EncodeToString(sum[:])
+}
+
+// daysApart returns the number of calendar days between two dates
+func daysApart(a, b time.Time) int {
+	days := int(math.Round(startOfDay(a).Sub(startOfDay(b)).Hours() / 24))
+	if days < 0 {
+		return -days
+	}
+	return days
+}
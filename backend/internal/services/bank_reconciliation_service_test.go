@@ -0,0 +1,227 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupBankReconciliationTestDB(t *testing.T) *gorm.DB {
+	db, err := openTestDB(t, filepath.Join(t.TempDir(), "bank.db"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Supplier{}, &models.CashFlowEntry{}, &models.SupplierPayment{},
+		&models.BankStatement{}, &models.BankStatementLine{}, &models.AuditTrail{}, &models.SystemConfig{})
+	require.NoError(t, err)
+	return db
+}
+
+// createBankBookItems books March 2026 transactions: the tranche income, a
+// salary run, a supplier transfer, a payable purchase and a cash expense
+func createBankBookItems(t *testing.T, db *gorm.DB) map[string]uint {
+	date := func(day int) time.Time { return time.Date(2026, 3, day, 10, 0, 0, 0, time.Local) }
+	entries := []models.CashFlowEntry{
+		{TransactionID: "TXN-BANK-0001", Date: date(1), Category: "operasional", Type: "income", Amount: models.Rupiah(50000000), Reference: "DANA-202603-0001", CreatedBy: 1},
+		{TransactionID: "TXN-BANK-0002", Date: date(24), Category: "gaji", Type: "expense", Amount: models.Rupiah(12000000), CreatedBy: 1},
+		{TransactionID: "TXN-BANK-0003", Date: date(3), Category: "bahan_baku", Type: "expense", Amount: models.Rupiah(7500000), Reference: "GRN-20260303-0001", IsPayable: true, CreatedBy: 1},
+		{TransactionID: "TXN-BANK-0004", Date: date(10), Category: "utilitas", Type: "expense", Amount: models.Rupiah(850000), CreatedBy: 1},
+		{TransactionID: "TXN-BANK-0005", Date: date(12), Category: "utilitas", Type: "expense", Amount: models.Rupiah(850000), CreatedBy: 1},
+	}
+	require.NoError(t, db.Create(&entries).Error)
+
+	supplier := models.Supplier{Name: "CV Sumber Pangan", IsActive: true}
+	require.NoError(t, db.Create(&supplier).Error)
+	payment := models.SupplierPayment{PaymentNumber: "PAY-20260304-0001", InvoiceID: 1, SupplierID: supplier.ID, PaymentDate: date(4),
+		Amount: models.Rupiah(7500000), Method: "transfer", BankReference: "FT26063KLM", CreatedBy: 1}
+	require.NoError(t, db.Create(&payment).Error)
+
+	ids := map[string]uint{"payment": payment.ID}
+	for _, entry := range entries {
+		ids[entry.TransactionID] = entry.ID
+	}
+	return ids
+}
+
+const reconciliationStatement = "Tanggal;Keterangan;No. Referensi;Debet;Kredit;Saldo\n" +
+	"02/03/2026;TRANSFER MASUK BGN DANA-202603-0001;SP2D-0012;0;50.000.000,00;60.000.000,00\n" +
+	"05/03/2026;TRANSFER KELUAR CV SUMBER PANGAN;FT26063KLM;7.500.000,00;0;52.500.000,00\n" +
+	"11/03/2026;PEMBAYARAN LISTRIK;PLN01;850.000,00;0;51.650.000,00\n" +
+	"25/03/2026;PAYROLL MARET;PR0325;12.000.000,00;0;39.650.000,00\n" +
+	"31/03/2026;BIAYA ADMINISTRASI;;15.000,00;0;39.635.000,00\n"
+
+func TestBankReconciliationService_ImportAndAutoMatch(t *testing.T) {
+	db := setupBankReconciliationTestDB(t)
+	ids := createBankBookItems(t, db)
+	service := NewBankReconciliationService(db)
+
+	result, err := service.ImportStatement("mutasi_maret.csv", []byte(reconciliationStatement), BankStatementFormatAuto, "BRI", "0123-4567-89", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Imported)
+	assert.Zero(t, result.Skipped)
+	assert.Equal(t, "0123456789", result.Statement.AccountNumber)
+	assert.Equal(t, models.Rupiah(10000000), result.Statement.OpeningBalance)
+	assert.Equal(t, models.Rupiah(39635000), result.Statement.ClosingBalance)
+	assert.Equal(t, models.Rupiah(50000000), result.Statement.TotalCredit)
+	assert.Equal(t, models.Rupiah(20365000), result.Statement.TotalDebit)
+
+	// The electricity payment has two equal candidates and the bank fee has none
+	assert.Equal(t, 3, result.AutoMatched)
+
+	statement, err := service.GetStatement(result.Statement.ID)
+	require.NoError(t, err)
+	require.Len(t, statement.Lines, 5)
+	lines := statement.Lines
+
+	require.NotNil(t, lines[0].CashFlowEntryID)
+	assert.Equal(t, ids["TXN-BANK-0001"], *lines[0].CashFlowEntryID)
+	assert.Equal(t, BankMatchAuto, lines[0].MatchType)
+	require.NotNil(t, lines[1].SupplierPaymentID)
+	assert.Equal(t, ids["payment"], *lines[1].SupplierPaymentID)
+	assert.Equal(t, BankLineUnmatched, lines[2].MatchStatus)
+	require.NotNil(t, lines[3].CashFlowEntryID)
+	assert.Equal(t, ids["TXN-BANK-0002"], *lines[3].CashFlowEntryID)
+	assert.Equal(t, BankLineUnmatched, lines[4].MatchStatus)
+
+	// Importing an overlapping statement only adds the new mutation
+	overlapping := reconciliationStatement + "01/04/2026;SETORAN;SET01;0;1.000.000,00;40.635.000,00\n"
+	result, err = service.ImportStatement("mutasi_maret_april.csv", []byte(overlapping), BankStatementFormatAuto, "BRI", "0123456789", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Imported)
+	assert.Equal(t, 5, result.Skipped)
+
+	_, err = service.ImportStatement("mutasi_maret.csv", []byte(reconciliationStatement), BankStatementFormatAuto, "BRI", "0123456789", 1)
+	assert.ErrorIs(t, err, ErrBankStatementAlreadyLoaded)
+}
+
+func TestBankReconciliationService_ManualMatchAndUnmatch(t *testing.T) {
+	db := setupBankReconciliationTestDB(t)
+	ids := createBankBookItems(t, db)
+	service := NewBankReconciliationService(db)
+
+	result, err := service.ImportStatement("mutasi_maret.csv", []byte(reconciliationStatement), BankStatementFormatAuto, "BRI", "", 1)
+	require.NoError(t, err)
+
+	unmatched, err := service.GetLines(BankLineFilter{StatementID: result.Statement.ID, MatchStatus: BankLineUnmatched})
+	require.NoError(t, err)
+	require.Len(t, unmatched, 2)
+	electricity := unmatched[0]
+	assert.Equal(t, models.Rupiah(850000), electricity.Amount)
+
+	candidates, err := service.GetMatchCandidates(electricity.ID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(candidates), 2)
+	assert.True(t, candidates[0].AmountMatches)
+	assert.True(t, candidates[1].AmountMatches)
+	for _, candidate := range candidates {
+		assert.Equal(t, BankDirectionDebit, candidate.Direction)
+		assert.NotEqual(t, ids["TXN-BANK-0003"], candidate.ID, "payable expenses are not bank items")
+	}
+
+	_, err = service.MatchLine(electricity.ID, BookItemCashFlow, ids["TXN-BANK-0001"], 1)
+	assert.ErrorIs(t, err, ErrBankMatchDirectionMismatch)
+	_, err = service.MatchLine(electricity.ID, BookItemCashFlow, ids["TXN-BANK-0002"], 1)
+	assert.ErrorIs(t, err, ErrBankMatchAmountMismatch)
+	_, err = service.MatchLine(electricity.ID, BookItemCashFlow, ids["TXN-BANK-0003"], 1)
+	assert.ErrorIs(t, err, ErrPayableEntryNotMatchable)
+	_, err = service.MatchLine(electricity.ID, "invoice", 1, 1)
+	assert.ErrorIs(t, err, ErrInvalidBookItemType)
+
+	line, err := service.MatchLine(electricity.ID, BookItemCashFlow, ids["TXN-BANK-0004"], 7)
+	require.NoError(t, err)
+	assert.Equal(t, BankLineMatched, line.MatchStatus)
+	assert.Equal(t, BankMatchManual, line.MatchType)
+	require.NotNil(t, line.MatchedBy)
+	assert.Equal(t, uint(7), *line.MatchedBy)
+	require.NotNil(t, line.CashFlowEntry)
+	assert.Equal(t, "TXN-BANK-0004", line.CashFlowEntry.TransactionID)
+
+	_, err = service.MatchLine(electricity.ID, BookItemCashFlow, ids["TXN-BANK-0005"], 1)
+	assert.ErrorIs(t, err, ErrBankLineAlreadyMatched)
+
+	// A book item can only be matched once
+	payroll, err := service.GetLines(BankLineFilter{MatchStatus: BankLineMatched})
+	require.NoError(t, err)
+	var payrollLine models.BankStatementLine
+	for _, l := range payroll {
+		if l.Reference == "PR0325" {
+			payrollLine = l
+		}
+	}
+	require.NoError(t, service.UnmatchLine(payrollLine.ID, 1))
+	assert.ErrorIs(t, service.UnmatchLine(payrollLine.ID, 1), ErrBankLineNotMatched)
+	_, err = service.MatchLine(payrollLine.ID, BookItemCashFlow, ids["TXN-BANK-0002"], 1)
+	require.NoError(t, err)
+
+	require.NoError(t, service.UnmatchLine(electricity.ID, 1))
+	_, err = service.MatchLine(unmatched[1].ID, BookItemCashFlow, ids["TXN-BANK-0004"], 1)
+	assert.ErrorIs(t, err, ErrBankMatchAmountMismatch)
+
+	// Deleting the statement releases its book items
+	require.NoError(t, service.DeleteStatement(result.Statement.ID, 1))
+	_, err = service.GetStatement(result.Statement.ID)
+	assert.ErrorIs(t, err, ErrBankStatementNotFound)
+	var remaining int64
+	require.NoError(t, db.Model(&models.BankStatementLine{}).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+}
+
+func TestBankReconciliationService_ReconciliationReport(t *testing.T) {
+	db := setupBankReconciliationTestDB(t)
+	ids := createBankBookItems(t, db)
+	service := NewBankReconciliationService(db)
+
+	// Money that was in the bank before the statements is booked as earlier income
+	opening := models.CashFlowEntry{TransactionID: "TXN-BANK-0000", Date: time.Date(2026, 2, 20, 0, 0, 0, 0, time.Local),
+		Category: "lainnya", Type: "income", Amount: models.Rupiah(10000000), CreatedBy: 1}
+	require.NoError(t, db.Create(&opening).Error)
+
+	result, err := service.ImportStatement("mutasi_maret.csv", []byte(reconciliationStatement), BankStatementFormatAuto, "BRI", "0123456789", 1)
+	require.NoError(t, err)
+
+	unmatched, err := service.GetLines(BankLineFilter{StatementID: result.Statement.ID, MatchStatus: BankLineUnmatched})
+	require.NoError(t, err)
+	_, err = service.MatchLine(unmatched[0].ID, BookItemCashFlow, ids["TXN-BANK-0004"], 1)
+	require.NoError(t, err)
+
+	report, err := service.GetReconciliationReport("0123456789", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 4, 30, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	require.Len(t, report.Periods, 2)
+
+	march := report.Periods[0]
+	assert.Equal(t, models.Rupiah(39635000), march.BankBalance)
+	// 10.000.000 + 50.000.000 - 12.000.000 - 850.000 - 850.000 - 7.500.000 (supplier transfer)
+	assert.Equal(t, models.Rupiah(38800000), march.BookBalance)
+	assert.Equal(t, models.Rupiah(835000), march.Difference)
+	// Explained by the unbooked bank fee and the second electricity entry not yet seen by the bank
+	assert.Equal(t, 1, march.UnmatchedBankLines)
+	assert.Equal(t, models.Rupiah(-15000), march.UnmatchedBankNet)
+	assert.Equal(t, 1, march.UnmatchedBookItems)
+	assert.Equal(t, models.Rupiah(-850000), march.UnmatchedBookNet)
+	assert.Equal(t, models.Rupiah(0), march.UnexplainedDifference)
+	assert.True(t, march.Reconciled)
+
+	april := report.Periods[1]
+	assert.Equal(t, time.Date(2026, 4, 30, 0, 0, 0, 0, time.Local), april.PeriodEnd)
+	assert.Equal(t, march.BankBalance, april.BankBalance)
+	assert.Zero(t, april.UnmatchedBankLines)
+
+	require.Len(t, report.UnmatchedBankLines, 1)
+	assert.Equal(t, "BIAYA ADMINISTRASI", report.UnmatchedBankLines[0].Description)
+	require.Len(t, report.UnmatchedBookItems, 1)
+	assert.Equal(t, "TXN-BANK-0005", report.UnmatchedBookItems[0].Number)
+
+	// Without the opening income the books miss the money already in the bank
+	require.NoError(t, db.Delete(&opening).Error)
+	report, err = service.GetReconciliationReport("", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 3, 31, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(10000000), report.Periods[0].UnexplainedDifference)
+	assert.False(t, report.Periods[0].Reconciled)
+
+	_, err = service.GetReconciliationReport("", time.Date(2026, 3, 31, 0, 0, 0, 0, time.Local), time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local))
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+}
@@ -0,0 +1,658 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+)
+
+var (
+	ErrUnsupportedBankStatementFormat = errors.New("format mutasi rekening tidak didukung (gunakan csv, xlsx atau mt940)")
+	ErrUnreadableBankStatement        = errors.New("file mutasi rekening tidak dapat dibaca")
+	ErrEmptyBankStatement             = errors.New("mutasi rekening tidak berisi transaksi")
+	ErrBankStatementHeaderNotFound    = errors.New("kolom tanggal dan nominal tidak ditemukan pada mutasi rekening")
+	ErrInvalidBankStatementRow        = errors.New("baris mutasi rekening tidak valid")
+)
+
+// Bank statement formats
+const (
+	BankStatementFormatAuto  = "auto"
+	BankStatementFormatCSV   = "csv"
+	BankStatementFormatMT940 = "mt940"
+)
+
+// Bank mutation directions, seen from the SPPG account
+const (
+	BankDirectionCredit = "credit" // money in
+	BankDirectionDebit  = "debit"  // money out
+)
+
+// ParsedBankLine is a single mutation read from a bank statement file
+type ParsedBankLine struct {
+	LineNumber  int           `json:"line_number"` // row number in the file, or sequence for MT940
+	Date        time.Time     `json:"date"`
+	Description string        `json:"description"`
+	Reference   string        `json:"reference"`
+	Direction   string        `json:"direction"`
+	Amount      models.Money  `json:"amount"`
+	Balance     *models.Money `json:"balance"`
+}
+
+// ParsedBankStatement is a bank statement file normalized across bank formats
+type ParsedBankStatement struct {
+	Format         string           `json:"format"`
+	AccountNumber  string           `json:"account_number"`
+	PeriodStart    time.Time        `json:"period_start"`
+	PeriodEnd      time.Time        `json:"period_end"`
+	OpeningBalance *models.Money    `json:"opening_balance"`
+	ClosingBalance *models.Money    `json:"closing_balance"`
+	Lines          []ParsedBankLine `json:"lines"`
+}
+
+// bankStatementColumns maps normalized header names of Indonesian bank exports
+// (KlikBCA, Mandiri MCM/Livin, BNI Direct, BRI CMS) to their field key
+var bankStatementColumns = map[string]string{
+	"tanggal":               "date",
+	"tanggal transaksi":     "date",
+	"tgl":                   "date",
+	"tgl transaksi":         "date",
+	"tgl. transaksi":        "date",
+	"date":                  "date",
+	"transaction date":      "date",
+	"post date":             "date",
+	"posting date":          "date",
+	"tanggal posting":       "date",
+	"keterangan":            "description",
+	"keterangan transaksi":  "description",
+	"uraian":                "description",
+	"uraian transaksi":      "description",
+	"deskripsi":             "description",
+	"description":           "description",
+	"transaction remarks":   "description",
+	"remark":                "description",
+	"remarks":               "description",
+	"referensi":             "reference",
+	"no. referensi":         "reference",
+	"no referensi":          "reference",
+	"reference":             "reference",
+	"reference no":          "reference",
+	"reference no.":         "reference",
+	"ref no":                "reference",
+	"ref no.":               "reference",
+	"no. ref":               "reference",
+	"journal no":            "reference",
+	"journal no.":           "reference",
+	"no. jurnal":            "reference",
+	"debet":                 "debit",
+	"debit":                 "debit",
+	"mutasi debet":          "debit",
+	"mutasi debit":          "debit",
+	"debit amount":          "debit",
+	"jumlah debet":          "debit",
+	"kredit":                "credit",
+	"credit":                "credit",
+	"mutasi kredit":         "credit",
+	"credit amount":         "credit",
+	"jumlah kredit":         "credit",
+	"jumlah":                "amount",
+	"nominal":               "amount",
+	"amount":                "amount",
+	"mutasi":                "amount",
+	"nilai":                 "amount",
+	"db/cr":                 "direction",
+	"cr/db":                 "direction",
+	"d/k":                   "direction",
+	"d/c":                   "direction",
+	"dk":                    "direction",
+	"tipe":                  "direction",
+	"type":                  "direction",
+	"jenis":                 "direction",
+	"saldo":                 "balance",
+	"saldo akhir":           "balance",
+	"balance":               "balance",
+	"running balance":       "balance",
+	"ledger balance":        "balance",
+	"saldo (rp)":            "balance",
+	"jumlah (rp)":           "amount",
+	"mutasi (rp)":           "amount",
+	"keterangan tambahan":   "description",
+	"berita":                "description",
+	"transaction reference": "reference",
+}
+
+// bankStatementDateLayouts lists accepted transaction date formats
+var bankStatementDateLayouts = []string{
+	"02/01/2006", "02/01/06", "2006-01-02", "02-01-2006", "02-01-06", "2006/01/02", "02.01.2006",
+	"02 Jan 2006", "02-Jan-2006", "02-Jan-06", "02 Jan 06", "2 Jan 2006",
+	"02/01/2006 15:04:05", "02/01/2006 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05",
+}
+
+// indonesianMonthAbbreviations translates month names that differ from English
+var indonesianMonthAbbreviations = strings.NewReplacer(
+	"Mei", "May", "MEI", "MAY", "Agu", "Aug", "AGU", "AUG", "Agt", "Aug", "AGT", "AUG",
+	"Okt", "Oct", "OKT", "OCT", "Des", "Dec", "DES", "DEC", "Nop", "Nov", "NOP", "NOV",
+)
+
+var (
+	bankAccountNumberPattern = regexp.MustCompile(`(?i)(?:no\.?\s*rek(?:ening)?|nomor\s+rekening|account\s+no\.?|account\s+number)\s*[:=]?\s*'?([0-9][0-9\-. ]{4,}[0-9])`)
+	bankPeriodPattern        = regexp.MustCompile(`(?i)period[e]?\s*[:=]?\s*([0-9]{1,4}[/\-.][0-9a-z]{1,3}[/\-.][0-9]{2,4})\s*(?:-|s/d|sd|sampai|to)\s*([0-9]{1,4}[/\-.][0-9a-z]{1,3}[/\-.][0-9]{2,4})`)
+	mt940TagPattern          = regexp.MustCompile(`^:([0-9]{2}[A-Z]?):`)
+	mt940LinePattern         = regexp.MustCompile(`(?s)^([0-9]{6})([0-9]{4})?(RC|RD|C|D)([A-Z])?([0-9]+(?:,[0-9]*)?)(?:[NF][A-Z0-9]{3})?([^\n/]*)(?://([^\n]*))?(?:\n(.*))?$`)
+	mt940BalancePattern      = regexp.MustCompile(`^([CD])([0-9]{6})([A-Z]{3})([0-9]+(?:,[0-9]*)?)`)
+)
+
+// ParseBankStatement reads a bank statement file. Format is csv, mt940 or
+// auto, which detects MT940 content and otherwise reads the file as a CSV or
+// XLSX export depending on its extension.
+func ParseBankStatement(filename string, content []byte, format string) (*ParsedBankStatement, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if format == "" || format == BankStatementFormatAuto {
+		format = BankStatementFormatCSV
+		if ext == ".sta" || ext == ".940" || ext == ".mt940" || isMT940Content(content) {
+			format = BankStatementFormatMT940
+		}
+	}
+
+	var statement *ParsedBankStatement
+	var err error
+	switch format {
+	case BankStatementFormatMT940:
+		statement, err = parseMT940Statement(content)
+	case BankStatementFormatCSV:
+		var records [][]string
+		if ext == ".xlsx" {
+			records, err = readXLSXRecords(bytes.NewReader(content))
+		} else {
+			records, err = readCSVRecords(bytes.NewReader(content))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnreadableBankStatement, err)
+		}
+		statement, err = parseBankStatementRecords(records)
+	default:
+		return nil, ErrUnsupportedBankStatementFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(statement.Lines) == 0 {
+		return nil, ErrEmptyBankStatement
+	}
+
+	statement.Format = format
+	completeBankStatement(statement)
+	return statement, nil
+}
+
+// isMT940Content reports whether the content looks like a SWIFT MT940 statement
+func isMT940Content(content []byte) bool {
+	return bytes.Contains(content, []byte(":61:")) &&
+		(bytes.Contains(content, []byte(":25:")) || bytes.Contains(content, []byte(":20:")))
+}
+
+// parseBankStatementRecords reads a tabular bank export. Rows above the header
+// hold the account number and period, rows below the transactions may hold the
+// opening and closing balance (Saldo Awal / Saldo Akhir).
+func parseBankStatementRecords(records [][]string) (*ParsedBankStatement, error) {
+	statement := &ParsedBankStatement{}
+
+	headerRow := -1
+	var columns map[string][]int
+	var periodStart, periodEnd string
+	for i, record := range records {
+		if cols := mapBankStatementColumns(record); cols != nil {
+			headerRow, columns = i, cols
+			break
+		}
+		text := strings.Join(record, " ")
+		if match := bankAccountNumberPattern.FindStringSubmatch(text); match != nil && statement.AccountNumber == "" {
+			statement.AccountNumber = normalizeAccountNumber(match[1])
+		}
+		if match := bankPeriodPattern.FindStringSubmatch(text); match != nil {
+			periodStart, periodEnd = match[1], match[2]
+		}
+	}
+	if headerRow < 0 {
+		return nil, ErrBankStatementHeaderNotFound
+	}
+
+	if start, err := parseBankDate(periodStart, 0); err == nil {
+		statement.PeriodStart = start
+	}
+	if end, err := parseBankDate(periodEnd, 0); err == nil {
+		statement.PeriodEnd = end
+	}
+	// Short dates (dd/mm) take the year of the statement period
+	year := statement.PeriodEnd.Year()
+	if statement.PeriodEnd.IsZero() {
+		year = time.Now().Year()
+	}
+
+	cell := func(record []string, field string) string {
+		var values []string
+		for _, idx := range columns[field] {
+			if idx < len(record) {
+				if value := strings.TrimSpace(record[idx]); value != "" {
+					values = append(values, value)
+				}
+			}
+		}
+		return strings.Join(values, " ")
+	}
+
+	for i, record := range records[headerRow+1:] {
+		rowNumber := headerRow + i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+
+		label := strings.ToLower(strings.TrimSpace(record[0]))
+		switch {
+		case strings.HasPrefix(label, "saldo awal") || strings.HasPrefix(label, "opening balance"):
+			statement.OpeningBalance = lastBankAmount(record[1:])
+			continue
+		case strings.HasPrefix(label, "saldo akhir") || strings.HasPrefix(label, "closing balance"):
+			statement.ClosingBalance = lastBankAmount(record[1:])
+			continue
+		case strings.HasPrefix(label, "mutasi") || strings.HasPrefix(label, "total"):
+			continue
+		}
+
+		dateValue := strings.TrimPrefix(cell(record, "date"), "'")
+		if strings.EqualFold(dateValue, "PEND") {
+			continue // pending transactions are not booked by the bank yet
+		}
+
+		line := ParsedBankLine{
+			LineNumber:  rowNumber,
+			Description: strings.Join(strings.Fields(cell(record, "description")), " "),
+			Reference:   strings.TrimPrefix(cell(record, "reference"), "'"),
+		}
+		if err := fillBankLineAmount(&line, record, columns, cell); err != nil {
+			return nil, fmt.Errorf("%w: baris %d (%v)", ErrInvalidBankStatementRow, rowNumber, err)
+		}
+		if line.Amount == 0 {
+			// Wrapped description rows carry no date and no amount
+			if dateValue == "" && line.Description != "" && len(statement.Lines) > 0 {
+				last := &statement.Lines[len(statement.Lines)-1]
+				last.Description = strings.TrimSpace(last.Description + " " + line.Description)
+			}
+			continue
+		}
+
+		date, err := parseBankDate(dateValue, year)
+		if err != nil {
+			return nil, fmt.Errorf("%w: baris %d (%v)", ErrInvalidBankStatementRow, rowNumber, err)
+		}
+		line.Date = date
+
+		if value := cell(record, "balance"); value != "" {
+			if balance, _, err := parseBankAmount(value); err == nil {
+				line.Balance = &balance
+			}
+		}
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	return statement, nil
+}
+
+// mapBankStatementColumns returns the field columns when the record is a
+// transaction header, that is it names a date and an amount column
+func mapBankStatementColumns(record []string) map[string][]int {
+	columns := make(map[string][]int)
+	for i, header := range record {
+		key := strings.ToLower(strings.Join(strings.Fields(strings.TrimPrefix(header, "\ufeff")), " "))
+		field, ok := bankStatementColumns[key]
+		if !ok {
+			field, ok = bankStatementColumns[strings.TrimSuffix(key, ".")]
+		}
+		if ok {
+			columns[field] = append(columns[field], i)
+		}
+	}
+
+	_, hasDate := columns["date"]
+	_, hasAmount := columns["amount"]
+	_, hasDebit := columns["debit"]
+	_, hasCredit := columns["credit"]
+	if !hasDate || !(hasAmount || (hasDebit && hasCredit)) {
+		return nil
+	}
+
+	// KlikBCA leaves the header of the DB/CR column next to Jumlah empty
+	if _, ok := columns["direction"]; !ok && hasAmount {
+		next := columns["amount"][0] + 1
+		if next < len(record) && strings.TrimSpace(record[next]) == "" {
+			columns["direction"] = []int{next}
+		}
+	}
+	return columns
+}
+
+// fillBankLineAmount sets the amount and direction of a row from either
+// separate debit/credit columns or an amount with a direction marker
+func fillBankLineAmount(line *ParsedBankLine, record []string, columns map[string][]int, cell func([]string, string) string) error {
+	if _, ok := columns["amount"]; !ok {
+		debit, _, err := parseOptionalBankAmount(cell(record, "debit"))
+		if err != nil {
+			return err
+		}
+		credit, _, err := parseOptionalBankAmount(cell(record, "credit"))
+		if err != nil {
+			return err
+		}
+		switch {
+		case credit > 0:
+			line.Direction, line.Amount = BankDirectionCredit, credit
+		case debit > 0:
+			line.Direction, line.Amount = BankDirectionDebit, debit
+		}
+		return nil
+	}
+
+	amount, direction, err := parseOptionalBankAmount(cell(record, "amount"))
+	if err != nil {
+		return err
+	}
+	if marker := bankDirectionMarker(cell(record, "direction")); marker != "" {
+		direction = marker
+	}
+	if direction == "" {
+		direction = BankDirectionCredit
+		if amount < 0 {
+			direction = BankDirectionDebit
+		}
+	}
+	line.Direction, line.Amount = direction, amount.Abs()
+	return nil
+}
+
+// bankDirectionMarker maps DB/CR, D/K and similar markers to a direction
+func bankDirectionMarker(value string) string {
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "CR", "C", "K", "KR", "KREDIT", "CREDIT":
+		return BankDirectionCredit
+	case "DB", "D", "DR", "DEBET", "DEBIT":
+		return BankDirectionDebit
+	}
+	return ""
+}
+
+// parseOptionalBankAmount parses an amount cell, treating blanks and dashes as zero
+func parseOptionalBankAmount(value string) (models.Money, string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "-" {
+		return 0, "", nil
+	}
+	return parseBankAmount(value)
+}
+
+// parseBankAmount parses Indonesian (1.500.000,00) and English (1,500,000.00)
+// formatted amounts. A trailing CR/DB marker is returned as direction and a
+// leading minus or parentheses make the amount negative.
+func parseBankAmount(value string) (models.Money, string, error) {
+	original := value
+	value = strings.ToUpper(strings.TrimSpace(strings.Trim(value, "'\"")))
+	value = strings.NewReplacer("RP", "", "IDR", "", " ", "", "\u00a0", "").Replace(value)
+
+	direction := ""
+	for suffix, marker := range map[string]string{"CR": BankDirectionCredit, "DB": BankDirectionDebit, "DR": BankDirectionDebit} {
+		if strings.HasSuffix(value, suffix) {
+			direction = marker
+			value = strings.TrimSuffix(value, suffix)
+			break
+		}
+	}
+
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative, value = true, value[1:len(value)-1]
+	}
+	if strings.HasPrefix(value, "-") {
+		negative, value = true, value[1:]
+	}
+	value = strings.TrimPrefix(value, "+")
+
+	lastDot, lastComma := strings.LastIndex(value, "."), strings.LastIndex(value, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			value = strings.ReplaceAll(value, ".", "")
+			value = strings.Replace(value, ",", ".", 1)
+		} else {
+			value = strings.ReplaceAll(value, ",", "")
+		}
+	case lastComma >= 0:
+		// A single comma followed by one or two digits is a decimal separator
+		if strings.Count(value, ",") == 1 && len(value)-lastComma-1 <= 2 {
+			value = strings.Replace(value, ",", ".", 1)
+		} else {
+			value = strings.ReplaceAll(value, ",", "")
+		}
+	case lastDot >= 0:
+		// Dots grouping thousands are always followed by three digits
+		if strings.Count(value, ".") > 1 || len(value)-lastDot-1 == 3 {
+			value = strings.ReplaceAll(value, ".", "")
+		}
+	}
+
+	amount, err := models.ParseMoney(value)
+	if err != nil {
+		return 0, "", fmt.Errorf("nominal tidak valid: %s", original)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, direction, nil
+}
+
+// lastBankAmount returns the last parsable amount in a footer row
+func lastBankAmount(cells []string) *models.Money {
+	for i := len(cells) - 1; i >= 0; i-- {
+		value := strings.TrimSpace(cells[i])
+		if value == "" || value == "=" || value == ":" {
+			continue
+		}
+		if amount, _, err := parseBankAmount(value); err == nil {
+			return &amount
+		}
+	}
+	return nil
+}
+
+// parseBankDate parses a transaction date. Dates without a year (dd/mm) use
+// the given year.
+func parseBankDate(value string, year int) (time.Time, error) {
+	value = indonesianMonthAbbreviations.Replace(strings.TrimSpace(value))
+	for _, layout := range bankStatementDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if year > 0 {
+		if t, err := time.ParseInLocation("02/01/2006", value+"/"+strconv.Itoa(year), time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("tanggal tidak valid: %s", value)
+}
+
+// normalizeAccountNumber keeps only the digits of an account number
+func normalizeAccountNumber(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// parseMT940Statement reads a SWIFT MT940 customer statement. Only the tags
+// needed for reconciliation are used: :25: account, :60F:/:60M: opening
+// balance, :61: statement line, :86: line information and :62F:/:62M: closing
+// balance.
+func parseMT940Statement(content []byte) (*ParsedBankStatement, error) {
+	type mt940Field struct {
+		tag   string
+		value string
+	}
+
+	var fields []mt940Field
+	for _, raw := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+		raw = strings.TrimRight(raw, "\r ")
+		if match := mt940TagPattern.FindStringSubmatch(raw); match != nil {
+			fields = append(fields, mt940Field{tag: match[1], value: raw[len(match[0]):]})
+			continue
+		}
+		if raw == "" || raw == "-" || strings.HasPrefix(raw, "{") || strings.HasPrefix(raw, "-}") || len(fields) == 0 {
+			continue
+		}
+		fields[len(fields)-1].value += "\n" + raw
+	}
+
+	statement := &ParsedBankStatement{}
+	for _, field := range fields {
+		switch field.tag {
+		case "25":
+			if statement.AccountNumber == "" {
+				account := field.value
+				if idx := strings.LastIndex(account, "/"); idx >= 0 {
+					account = account[idx+1:]
+				}
+				statement.AccountNumber = normalizeAccountNumber(account)
+			}
+		case "60F", "60M":
+			if statement.OpeningBalance == nil {
+				if balance, date, ok := parseMT940Balance(field.value); ok {
+					statement.OpeningBalance = &balance
+					statement.PeriodStart = date
+				}
+			}
+		case "62F", "62M":
+			if balance, date, ok := parseMT940Balance(field.value); ok {
+				statement.ClosingBalance = &balance
+				statement.PeriodEnd = date
+			}
+		case "61":
+			line, err := parseMT940Line(field.value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: transaksi %d (%v)", ErrInvalidBankStatementRow, len(statement.Lines)+1, err)
+			}
+			line.LineNumber = len(statement.Lines) + 1
+			statement.Lines = append(statement.Lines, line)
+		case "86":
+			if len(statement.Lines) > 0 {
+				last := &statement.Lines[len(statement.Lines)-1]
+				last.Description = strings.Join(strings.Fields(field.value), " ")
+			}
+		}
+	}
+
+	return statement, nil
+}
+
+// parseMT940Line parses the value of a :61: statement line tag
+func parseMT940Line(value string) (ParsedBankLine, error) {
+	match := mt940LinePattern.FindStringSubmatch(value)
+	if match == nil {
+		return ParsedBankLine{}, fmt.Errorf("format :61: tidak dikenali: %s", value)
+	}
+
+	date, err := time.ParseInLocation("060102", match[1], time.Local)
+	if err != nil {
+		return ParsedBankLine{}, err
+	}
+	amount, err := models.ParseMoney(strings.Replace(match[5], ",", ".", 1))
+	if err != nil {
+		return ParsedBankLine{}, err
+	}
+
+	// A reversal of a credit takes money out and vice versa
+	direction := BankDirectionCredit
+	if match[3] == "D" || match[3] == "RC" {
+		direction = BankDirectionDebit
+	}
+
+	reference := strings.TrimSpace(match[6])
+	if strings.EqualFold(reference, "NONREF") {
+		reference = ""
+	}
+	if reference == "" {
+		reference = strings.TrimSpace(match[7])
+	}
+
+	return ParsedBankLine{
+		Date:        date,
+		Direction:   direction,
+		Amount:      amount,
+		Reference:   reference,
+		Description: strings.TrimSpace(match[8]),
+	}, nil
+}
+
+// parseMT940Balance parses an opening or closing balance tag value
+func parseMT940Balance(value string) (models.Money, time.Time, bool) {
+	match := mt940BalancePattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, time.Time{}, false
+	}
+	date, err := time.ParseInLocation("060102", match[2], time.Local)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	amount, err := models.ParseMoney(strings.Replace(match[4], ",", ".", 1))
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	if match[1] == "D" {
+		amount = -amount
+	}
+	return amount, date, true
+}
+
+// completeBankStatement fills the period and balances a format did not provide
+func completeBankStatement(statement *ParsedBankStatement) {
+	var net models.Money
+	for _, line := range statement.Lines {
+		if statement.PeriodStart.IsZero() || line.Date.Before(statement.PeriodStart) {
+			statement.PeriodStart = line.Date
+		}
+		if line.Date.After(statement.PeriodEnd) {
+			statement.PeriodEnd = line.Date
+		}
+		net += signedBankAmount(line.Direction, line.Amount)
+	}
+
+	if statement.OpeningBalance == nil {
+		var opening models.Money
+		switch {
+		case statement.Lines[0].Balance != nil:
+			first := statement.Lines[0]
+			opening = *first.Balance - signedBankAmount(first.Direction, first.Amount)
+		case statement.ClosingBalance != nil:
+			opening = *statement.ClosingBalance - net
+		}
+		statement.OpeningBalance = &opening
+	}
+	if statement.ClosingBalance == nil {
+		closing := *statement.OpeningBalance + net
+		statement.ClosingBalance = &closing
+	}
+}
+
+// signedBankAmount returns the amount as a change of the account balance
+func signedBankAmount(direction string, amount models.Money) models.Money {
+	if direction == BankDirectionDebit {
+		return -amount
+	}
+	return amount
+}
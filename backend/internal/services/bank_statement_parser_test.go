@@ -0,0 +1,150 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const klikBCAStatement = `No. rekening : 0123456789
+Nama : SPPG SUKAMAJU
+Periode : 01/03/2026 - 31/03/2026
+Kode Mata Uang : Rp
+
+Tanggal Transaksi,Keterangan,Cabang,Jumlah,,Saldo
+'01/03,TRSF E-BANKING CR 0103/FTSCY/WS95031 50000000.00 BADAN GIZI NASIONAL,0000,"50,000,000.00",CR,"60,000,000.00"
+'03/03,TRSF E-BANKING DB 0303/FTFVA/WS95031 PAY-20260303-0001 CV SUMBER PANGAN,0000,"7,500,000.00",DB,"52,500,000.00"
+'31/03,BIAYA ADM,0000,"15,000.00",DB,"52,485,000.00"
+PEND,TRSF E-BANKING DB 3103 GAJI,0000,"12,000,000.00",DB,
+
+Saldo Awal,=,"10,000,000.00"
+Mutasi Kredit,=,"50,000,000.00",1
+Mutasi Debet,=,"7,515,000.00",2
+Saldo Akhir,=,"52,485,000.00"
+`
+
+func TestParseBankStatement_KlikBCA(t *testing.T) {
+	statement, err := ParseBankStatement("mutasi.csv", []byte(klikBCAStatement), BankStatementFormatAuto)
+	require.NoError(t, err)
+
+	assert.Equal(t, BankStatementFormatCSV, statement.Format)
+	assert.Equal(t, "0123456789", statement.AccountNumber)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), statement.PeriodStart)
+	assert.Equal(t, time.Date(2026, 3, 31, 0, 0, 0, 0, time.Local), statement.PeriodEnd)
+	require.NotNil(t, statement.OpeningBalance)
+	assert.Equal(t, models.Rupiah(10000000), *statement.OpeningBalance)
+	assert.Equal(t, models.Rupiah(52485000), *statement.ClosingBalance)
+
+	// The pending transfer is not booked by the bank yet
+	require.Len(t, statement.Lines, 3)
+	assert.Equal(t, BankDirectionCredit, statement.Lines[0].Direction)
+	assert.Equal(t, models.Rupiah(50000000), statement.Lines[0].Amount)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), statement.Lines[0].Date)
+	require.NotNil(t, statement.Lines[0].Balance)
+	assert.Equal(t, models.Rupiah(60000000), *statement.Lines[0].Balance)
+	assert.Equal(t, BankDirectionDebit, statement.Lines[1].Direction)
+	assert.Contains(t, statement.Lines[1].Description, "PAY-20260303-0001")
+	assert.Equal(t, models.Rupiah(15000), statement.Lines[2].Amount)
+}
+
+func TestParseBankStatement_DebitCreditColumnsWithIndonesianNumbers(t *testing.T) {
+	content := "Tanggal;Uraian Transaksi;No. Referensi;Debet;Kredit;Saldo\n" +
+		"02/03/2026;SETORAN TUNAI;REF001;0,00;1.250.000,50;11.250.000,50\n" +
+		"05/03/2026;PEMBAYARAN LISTRIK;REF002;850.000,00;0,00;10.400.000,50\n" +
+		";PLN ID 5123;;;;\n"
+
+	statement, err := ParseBankStatement("mutasi_bri.csv", []byte(content), BankStatementFormatCSV)
+	require.NoError(t, err)
+
+	require.Len(t, statement.Lines, 2)
+	assert.Equal(t, BankDirectionCredit, statement.Lines[0].Direction)
+	assert.Equal(t, models.NewMoney(1250000.5), statement.Lines[0].Amount)
+	assert.Equal(t, "REF001", statement.Lines[0].Reference)
+	assert.Equal(t, BankDirectionDebit, statement.Lines[1].Direction)
+	assert.Equal(t, models.Rupiah(850000), statement.Lines[1].Amount)
+	assert.Equal(t, "PEMBAYARAN LISTRIK PLN ID 5123", statement.Lines[1].Description)
+
+	// Balances are derived from the running balance when there is no footer
+	assert.Equal(t, models.Rupiah(10000000), *statement.OpeningBalance)
+	assert.Equal(t, models.NewMoney(10400000.5), *statement.ClosingBalance)
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local), statement.PeriodStart)
+}
+
+func TestParseBankStatement_MT940(t *testing.T) {
+	content := "{1:F01BMRIIDJAXXXX0000000000}{2:O940}{4:\n" +
+		":20:STMT260331\n" +
+		":25:BMRIIDJA/1230009876543\n" +
+		":28C:00031/001\n" +
+		":60F:C260301IDR10000000,00\n" +
+		":61:2603010301C50000000,00NTRFSP2D-0012//FT26060ABC\n" +
+		":86:TRANSFER DANA BGN\n" +
+		"TAHAP 1 MARET\n" +
+		":61:2603050305D850000,NMSCNONREF//FT26064XYZ\n" +
+		":86:PEMBAYARAN LISTRIK\n" +
+		":61:2603060306RC100000,00NTRFNONREF\n" +
+		":62F:C260331IDR59050000,00\n" +
+		"-}"
+
+	statement, err := ParseBankStatement("statement.txt", []byte(content), BankStatementFormatAuto)
+	require.NoError(t, err)
+
+	assert.Equal(t, BankStatementFormatMT940, statement.Format)
+	assert.Equal(t, "1230009876543", statement.AccountNumber)
+	assert.Equal(t, models.Rupiah(10000000), *statement.OpeningBalance)
+	assert.Equal(t, models.Rupiah(59050000), *statement.ClosingBalance)
+	assert.Equal(t, time.Date(2026, 3, 31, 0, 0, 0, 0, time.Local), statement.PeriodEnd)
+
+	require.Len(t, statement.Lines, 3)
+	assert.Equal(t, "SP2D-0012", statement.Lines[0].Reference)
+	assert.Equal(t, "TRANSFER DANA BGN TAHAP 1 MARET", statement.Lines[0].Description)
+	assert.Equal(t, BankDirectionCredit, statement.Lines[0].Direction)
+	assert.Equal(t, "FT26064XYZ", statement.Lines[1].Reference)
+	assert.Equal(t, models.Rupiah(850000), statement.Lines[1].Amount)
+	assert.Equal(t, BankDirectionDebit, statement.Lines[1].Direction)
+	// A reversed credit takes money out of the account
+	assert.Equal(t, BankDirectionDebit, statement.Lines[2].Direction)
+}
+
+func TestParseBankAmount(t *testing.T) {
+	tests := []struct {
+		value     string
+		amount    models.Money
+		direction string
+	}{
+		{"1,500,000.00", models.Rupiah(1500000), ""},
+		{"1.500.000,00", models.Rupiah(1500000), ""},
+		{"Rp 1.500", models.Rupiah(1500), ""},
+		{"1500,5", models.NewMoney(1500.5), ""},
+		{"250.75", models.NewMoney(250.75), ""},
+		{"-12.000", models.Rupiah(-12000), ""},
+		{"(3,000.00)", models.Rupiah(-3000), ""},
+		{"1,500,000.00 CR", models.Rupiah(1500000), BankDirectionCredit},
+		{"75.000 DB", models.Rupiah(75000), BankDirectionDebit},
+	}
+	for _, tt := range tests {
+		amount, direction, err := parseBankAmount(tt.value)
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.amount, amount, tt.value)
+		assert.Equal(t, tt.direction, direction, tt.value)
+	}
+
+	_, _, err := parseBankAmount("satu juta")
+	assert.Error(t, err)
+}
+
+func TestParseBankStatement_Errors(t *testing.T) {
+	_, err := ParseBankStatement("mutasi.csv", []byte("Nama,Alamat\nBudi,Jakarta\n"), BankStatementFormatAuto)
+	assert.ErrorIs(t, err, ErrBankStatementHeaderNotFound)
+
+	_, err = ParseBankStatement("mutasi.csv", []byte("Tanggal,Keterangan,Jumlah\n"), BankStatementFormatAuto)
+	assert.ErrorIs(t, err, ErrEmptyBankStatement)
+
+	_, err = ParseBankStatement("mutasi.csv", []byte("Tanggal,Keterangan,Jumlah\nbesok,TRANSFER,1000\n"), BankStatementFormatAuto)
+	assert.ErrorIs(t, err, ErrInvalidBankStatementRow)
+
+	_, err = ParseBankStatement("mutasi.pdf", []byte("%PDF"), "pdf")
+	assert.ErrorIs(t, err, ErrUnsupportedBankStatementFormat)
+}
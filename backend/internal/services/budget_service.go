@@ -0,0 +1,658 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBudgetTargetNotFound  = errors.New("target anggaran tidak ditemukan")
+	ErrDuplicateBudgetTarget = errors.New("target anggaran untuk kategori dan periode tersebut sudah ada")
+	ErrInvalidBudgetPeriod   = errors.New("periode anggaran tidak valid")
+	ErrInvalidBudgetAmount   = errors.New("target anggaran tidak boleh negatif")
+)
+
+const (
+	// defaultBudgetAlertThresholds are the absorption percentages that trigger a notification
+	defaultBudgetAlertThresholds = "80,100"
+	// defaultBudgetAlertRoles are the roles notified when a threshold is crossed
+	defaultBudgetAlertRoles = "kepala_sppg,kepala_yayasan,akuntan"
+	// MaxBudgetImportRows limits the size of a single budget upload
+	MaxBudgetImportRows = 1000
+)
+
+// Budget status values
+const (
+	BudgetStatusOnTrack    = "on_track"
+	BudgetStatusWarning    = "warning"
+	BudgetStatusOverBudget = "over_budget"
+)
+
+// budgetImportColumns maps accepted header names to field keys
+var budgetImportColumns = map[string]string{
+	"tahun":    "year",
+	"year":     "year",
+	"bulan":    "month",
+	"month":    "month",
+	"kategori": "category",
+	"category": "category",
+	"target":   "target",
+	"catatan":  "notes",
+	"notes":    "notes",
+}
+
+var requiredBudgetImportFields = []string{"year", "month", "category", "target"}
+
+// BudgetStatus is a budget target with live realisation and forecast
+type BudgetStatus struct {
+	models.BudgetTarget
+	PeriodStart            time.Time    `json:"period_start"`
+	PeriodEnd              time.Time    `json:"period_end"`
+	Remaining              models.Money `json:"remaining"`
+	AbsorptionRate         float64      `json:"absorption_rate"`
+	Forecast               models.Money `json:"forecast"` // projected realisation at the end of the period
+	ForecastAbsorptionRate float64      `json:"forecast_absorption_rate"`
+	Status                 string       `json:"status"`
+}
+
+// BudgetSummary totals the budget statuses of a period
+type BudgetSummary struct {
+	Year                   int            `json:"year"`
+	Month                  int            `json:"month"`
+	TotalTarget            models.Money   `json:"total_target"`
+	TotalActual            models.Money   `json:"total_actual"`
+	TotalForecast          models.Money   `json:"total_forecast"`
+	AbsorptionRate         float64        `json:"absorption_rate"`
+	ForecastAbsorptionRate float64        `json:"forecast_absorption_rate"`
+	Categories             []BudgetStatus `json:"categories"`
+}
+
+// BudgetImportRow represents a single parsed row of a budget upload
+type BudgetImportRow struct {
+	RowNumber int          `json:"row_number"`
+	Year      int          `json:"year"`
+	Month     int          `json:"month"`
+	Category  string       `json:"category"`
+	Target    models.Money `json:"target"`
+	Notes     string       `json:"notes"`
+}
+
+// BudgetImportResult summarises a budget upload
+type BudgetImportResult struct {
+	TotalRows int              `json:"total_rows"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	DryRun    bool             `json:"dry_run"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// BudgetService manages budget targets, their realisation and threshold alerts
+type BudgetService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+	now                 func() time.Time
+}
+
+// NewBudgetService creates a new budget service. notificationService may be nil,
+// in which case alerts are stored without a realtime push.
+func NewBudgetService(db *gorm.DB, notificationService *NotificationService) *BudgetService {
+	return &BudgetService{
+		db:                  db,
+		notificationService: notificationService,
+		now:                 time.Now,
+	}
+}
+
+// CreateBudgetTarget creates a yearly (month 0) or monthly budget target
+func (s *BudgetService) CreateBudgetTarget(target *models.BudgetTarget, userID uint) error {
+	if err := validateBudgetTarget(target.Year, target.Month, target.Category, target.Target); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.BudgetTarget{}).
+		Where("year = ? AND month = ? AND category = ?", target.Year, target.Month, target.Category).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateBudgetTarget
+	}
+
+	target.ID = 0
+	target.Actual = 0
+	target.CreatedBy = userID
+	if err := s.db.Create(target).Error; err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "budget_target", strconv.FormatUint(uint64(target.ID), 10), nil, target, "")
+	return nil
+}
+
+// UpdateBudgetTarget changes the target amount and notes. Alerts for
+// thresholds that are no longer exceeded are cleared so they can fire again.
+func (s *BudgetService) UpdateBudgetTarget(id uint, amount models.Money, notes string, userID uint) (*BudgetStatus, error) {
+	if amount < 0 {
+		return nil, ErrInvalidBudgetAmount
+	}
+
+	var target models.BudgetTarget
+	if err := s.db.First(&target, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBudgetTargetNotFound
+		}
+		return nil, err
+	}
+
+	old := map[string]interface{}{"target": target.Target, "notes": target.Notes}
+	if err := s.db.Model(&target).Updates(map[string]interface{}{
+		"target": amount,
+		"notes":  notes,
+	}).Error; err != nil {
+		return nil, err
+	}
+	target.Target = amount
+	target.Notes = notes
+
+	status, err := s.buildStatus(target)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("budget_target_id = ? AND threshold > ?", target.ID, status.AbsorptionRate).
+		Delete(&models.BudgetAlert{}).Error; err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "update", "budget_target", strconv.FormatUint(uint64(id), 10), old,
+		map[string]interface{}{"target": amount, "notes": notes}, "")
+	return status, nil
+}
+
+// DeleteBudgetTarget deletes a budget target and its alert history
+func (s *BudgetService) DeleteBudgetTarget(id uint, userID uint) error {
+	var target models.BudgetTarget
+	if err := s.db.First(&target, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBudgetTargetNotFound
+		}
+		return err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("budget_target_id = ?", id).Delete(&models.BudgetAlert{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&target).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "delete", "budget_target", strconv.FormatUint(uint64(id), 10), target, nil, "")
+	return nil
+}
+
+// GetBudgetTarget returns a budget target with live realisation
+func (s *BudgetService) GetBudgetTarget(id uint) (*BudgetStatus, error) {
+	var target models.BudgetTarget
+	if err := s.db.First(&target, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBudgetTargetNotFound
+		}
+		return nil, err
+	}
+	return s.buildStatus(target)
+}
+
+// GetBudgetTargets lists budget targets of a year, optionally filtered by month and category
+func (s *BudgetService) GetBudgetTargets(year int, month *int, category string) ([]BudgetStatus, error) {
+	query := s.db.Where("year = ?", year)
+	if month != nil {
+		query = query.Where("month = ?", *month)
+	}
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var targets []models.BudgetTarget
+	if err := query.Order("month ASC, category ASC").Find(&targets).Error; err != nil {
+		return nil, err
+	}
+
+	statuses := make([]BudgetStatus, 0, len(targets))
+	for _, target := range targets {
+		status, err := s.buildStatus(target)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+// GetBudgetSummary totals the targets of one period (month 0 for the yearly budget)
+func (s *BudgetService) GetBudgetSummary(year, month int) (*BudgetSummary, error) {
+	if _, _, err := budgetPeriod(year, month); err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.GetBudgetTargets(year, &month, "")
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &BudgetSummary{Year: year, Month: month, Categories: statuses}
+	for _, status := range statuses {
+		summary.TotalTarget += status.Target
+		summary.TotalActual += status.Actual
+		summary.TotalForecast += status.Forecast
+	}
+	summary.AbsorptionRate = percentOf(summary.TotalActual, summary.TotalTarget)
+	summary.ForecastAbsorptionRate = percentOf(summary.TotalForecast, summary.TotalTarget)
+	return summary, nil
+}
+
+// CheckThresholds recomputes the monthly and yearly budgets of a category for
+// the given date and notifies once per threshold that has been crossed
+func (s *BudgetService) CheckThresholds(ctx context.Context, category string, date time.Time) error {
+	date = date.In(time.Local)
+	var targets []models.BudgetTarget
+	if err := s.db.Where("year = ? AND month IN ? AND category = ?", date.Year(), []int{0, int(date.Month())}, category).
+		Find(&targets).Error; err != nil {
+		return err
+	}
+
+	thresholds := s.alertThresholds()
+	for _, target := range targets {
+		status, err := s.buildStatus(target)
+		if err != nil {
+			return err
+		}
+		if status.Target <= 0 {
+			continue
+		}
+
+		for _, threshold := range thresholds {
+			if status.AbsorptionRate < float64(threshold) {
+				continue
+			}
+
+			alert := models.BudgetAlert{
+				BudgetTargetID: target.ID,
+				Threshold:      threshold,
+				Actual:         status.Actual,
+				Target:         status.Target,
+				AbsorptionRate: status.AbsorptionRate,
+			}
+			result := s.db.Where(models.BudgetAlert{BudgetTargetID: target.ID, Threshold: threshold}).FirstOrCreate(&alert)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				// Already announced
+				continue
+			}
+
+			if err := s.notifyThreshold(ctx, status, threshold); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ParseImportFile reads an XLSX or CSV budget upload with the columns
+// Tahun, Bulan, Kategori, Target and optionally Catatan
+func (s *BudgetService) ParseImportFile(filename string, r io.Reader) ([]BudgetImportRow, []ImportRowError, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx":
+		records, err = readXLSXRecords(r)
+	case ".csv":
+		records, err = readCSVRecords(r)
+	default:
+		return nil, nil, ErrUnsupportedImportFormat
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) < 2 {
+		return nil, nil, ErrEmptyImportFile
+	}
+
+	columnIndex := make(map[string]int)
+	for i, header := range records[0] {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		if field, ok := budgetImportColumns[key]; ok {
+			columnIndex[field] = i
+		}
+	}
+	for _, field := range requiredBudgetImportFields {
+		if _, ok := columnIndex[field]; !ok {
+			return nil, nil, fmt.Errorf("%w: %s", ErrMissingImportColumn, field)
+		}
+	}
+
+	cell := func(record []string, field string) string {
+		idx, ok := columnIndex[field]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	var rowErrors []ImportRowError
+	rows := make([]BudgetImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		row := BudgetImportRow{
+			RowNumber: i + 2, // header is row 1
+			Category:  strings.ToLower(cell(record, "category")),
+			Notes:     cell(record, "notes"),
+		}
+
+		if row.Year, err = strconv.Atoi(cell(record, "year")); err != nil {
+			rowErrors = append(rowErrors, ImportRowError{RowNumber: row.RowNumber, Field: "year", Message: "tahun harus berupa angka"})
+		}
+		if row.Month, err = strconv.Atoi(cell(record, "month")); err != nil {
+			rowErrors = append(rowErrors, ImportRowError{RowNumber: row.RowNumber, Field: "month", Message: "bulan harus berupa angka (0 untuk anggaran tahunan)"})
+		}
+		amount := strings.ReplaceAll(cell(record, "target"), ",", "")
+		if row.Target, err = models.ParseMoney(amount); err != nil {
+			rowErrors = append(rowErrors, ImportRowError{RowNumber: row.RowNumber, Field: "target", Message: "target harus berupa angka"})
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, nil, ErrEmptyImportFile
+	}
+	if len(rows) > MaxBudgetImportRows {
+		return nil, nil, fmt.Errorf("jumlah baris melebihi batas maksimum %d", MaxBudgetImportRows)
+	}
+	return rows, rowErrors, nil
+}
+
+// ImportBudgetTargets creates or updates all rows in one transaction.
+// Nothing is written when any row is invalid or when dryRun is true.
+func (s *BudgetService) ImportBudgetTargets(rows []BudgetImportRow, parseErrors []ImportRowError, dryRun bool, userID uint) (*BudgetImportResult, error) {
+	result := &BudgetImportResult{
+		TotalRows: len(rows),
+		DryRun:    dryRun,
+		Errors:    parseErrors,
+	}
+
+	invalid := make(map[int]bool, len(parseErrors))
+	for _, e := range parseErrors {
+		invalid[e.RowNumber] = true
+	}
+
+	seen := make(map[string]int)
+	for _, row := range rows {
+		if invalid[row.RowNumber] {
+			continue
+		}
+		if err := validateBudgetTarget(row.Year, row.Month, row.Category, row.Target); err != nil {
+			result.Errors = append(result.Errors, ImportRowError{RowNumber: row.RowNumber, Field: budgetErrorField(err), Message: err.Error()})
+			continue
+		}
+		key := fmt.Sprintf("%d-%d-%s", row.Year, row.Month, row.Category)
+		if first, ok := seen[key]; ok {
+			result.Errors = append(result.Errors, ImportRowError{RowNumber: row.RowNumber, Field: "category", Message: fmt.Sprintf("duplikat dengan baris %d", first)})
+			continue
+		}
+		seen[key] = row.RowNumber
+	}
+	if len(result.Errors) > 0 {
+		return result, ErrImportValidationFailed
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			var target models.BudgetTarget
+			err := tx.Where("year = ? AND month = ? AND category = ?", row.Year, row.Month, row.Category).First(&target).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				result.Created++
+				if dryRun {
+					continue
+				}
+				target = models.BudgetTarget{
+					Year:      row.Year,
+					Month:     row.Month,
+					Category:  row.Category,
+					Target:    row.Target,
+					Notes:     row.Notes,
+					CreatedBy: userID,
+				}
+				if err := tx.Create(&target).Error; err != nil {
+					return fmt.Errorf("baris %d: %w", row.RowNumber, err)
+				}
+			case err != nil:
+				return err
+			default:
+				result.Updated++
+				if dryRun {
+					continue
+				}
+				if err := tx.Model(&target).Updates(map[string]interface{}{
+					"target": row.Target,
+					"notes":  row.Notes,
+				}).Error; err != nil {
+					return fmt.Errorf("baris %d: %w", row.RowNumber, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		NewAuditTrailService(s.db).RecordAction(userID, "import", "budget_target", "", nil, map[string]interface{}{
+			"created": result.Created,
+			"updated": result.Updated,
+		}, "")
+	}
+	return result, nil
+}
+
+// buildStatus computes the live realisation and forecast of a target without
+// writing to the database
+func (s *BudgetService) buildStatus(target models.BudgetTarget) (*BudgetStatus, error) {
+	start, end, err := budgetPeriod(target.Year, target.Month)
+	if err != nil {
+		return nil, err
+	}
+
+	var actual models.Money
+	if err := s.db.Model(&models.CashFlowEntry{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("type = ? AND category = ? AND date >= ? AND date < ?", "expense", target.Category, start, end).
+		Scan(&actual).Error; err != nil {
+		return nil, err
+	}
+
+	target.Actual = actual
+
+	forecast := forecastRealisation(actual, start, end, s.now())
+	status := &BudgetStatus{
+		BudgetTarget:           target,
+		PeriodStart:            start,
+		PeriodEnd:              end.Add(-time.Nanosecond),
+		Remaining:              target.Target - actual,
+		AbsorptionRate:         percentOf(actual, target.Target),
+		Forecast:               forecast,
+		ForecastAbsorptionRate: percentOf(forecast, target.Target),
+	}
+
+	warningAt := 100.0
+	if thresholds := s.alertThresholds(); len(thresholds) > 0 {
+		warningAt = float64(thresholds[0])
+	}
+	switch {
+	case status.AbsorptionRate >= 100 || (target.Target == 0 && actual > 0):
+		status.Status = BudgetStatusOverBudget
+	case status.AbsorptionRate >= warningAt || status.ForecastAbsorptionRate >= 100:
+		status.Status = BudgetStatusWarning
+	default:
+		status.Status = BudgetStatusOnTrack
+	}
+	return status, nil
+}
+
+// notifyThreshold notifies the configured roles that a threshold was crossed
+func (s *BudgetService) notifyThreshold(ctx context.Context, status *BudgetStatus, threshold int) error {
+	roles := splitConfigList(NewSystemConfigService(s.db).GetConfigString("budget_alert_roles", defaultBudgetAlertRoles))
+	if len(roles) == 0 {
+		return nil
+	}
+
+	var userIDs []uint
+	if err := s.db.Model(&models.User{}).Where("role IN ? AND is_active = ?", roles, true).Pluck("id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	period := strconv.Itoa(status.Year)
+	if status.Month > 0 {
+		period = fmt.Sprintf("%02d/%d", status.Month, status.Year)
+	}
+
+	for _, userID := range userIDs {
+		notification := &models.Notification{
+			UserID:  userID,
+			Type:    NotificationTypeBudgetAlert,
+			Title:   fmt.Sprintf("Anggaran %s Mencapai %d%%", status.Category, threshold),
+			Message: fmt.Sprintf("Realisasi anggaran %s periode %s telah melewati %d%% dari target: Rp %s dari Rp %s", status.Category, period, threshold, status.Actual, status.Target),
+			Link:    "/budget-targets",
+		}
+
+		if s.notificationService != nil {
+			if err := s.notificationService.CreateNotification(ctx, notification); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.db.Create(notification).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// alertThresholds returns the configured thresholds in ascending order
+func (s *BudgetService) alertThresholds() []int {
+	value := NewSystemConfigService(s.db).GetConfigString("budget_alert_thresholds", defaultBudgetAlertThresholds)
+
+	var thresholds []int
+	for _, item := range splitConfigList(value) {
+		if threshold, err := strconv.Atoi(item); err == nil && threshold > 0 {
+			thresholds = append(thresholds, threshold)
+		}
+	}
+	sort.Ints(thresholds)
+	return thresholds
+}
+
+// validateBudgetTarget checks the period, category and amount of a target
+func validateBudgetTarget(year, month int, category string, amount models.Money) error {
+	if _, _, err := budgetPeriod(year, month); err != nil {
+		return err
+	}
+
+	isValidCategory := false
+	for _, cat := range CashFlowCategories {
+		if category == cat {
+			isValidCategory = true
+			break
+		}
+	}
+	if !isValidCategory {
+		return ErrInvalidCategory
+	}
+
+	if amount < 0 {
+		return ErrInvalidBudgetAmount
+	}
+	return nil
+}
+
+// budgetErrorField names the import column a validation error belongs to
+func budgetErrorField(err error) string {
+	switch err {
+	case ErrInvalidBudgetPeriod:
+		return "month"
+	case ErrInvalidCategory:
+		return "category"
+	default:
+		return "target"
+	}
+}
+
+// budgetPeriod returns the half-open date range covered by a target
+func budgetPeriod(year, month int) (time.Time, time.Time, error) {
+	if year < 2000 || month < 0 || month > 12 {
+		return time.Time{}, time.Time{}, ErrInvalidBudgetPeriod
+	}
+	if month == 0 {
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+		return start, start.AddDate(1, 0, 0), nil
+	}
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// forecastRealisation projects the realisation at the end of the period by
+// extrapolating the average daily spending so far
+func forecastRealisation(actual models.Money, start, end, now time.Time) models.Money {
+	if !now.After(start) {
+		return actual
+	}
+	if !now.Before(end) {
+		return actual
+	}
+
+	// Count the current day as elapsed so early projections stay reasonable
+	elapsedDays := math.Ceil(now.Sub(start).Hours() / 24)
+	if elapsedDays < 1 {
+		elapsedDays = 1
+	}
+	totalDays := math.Round(end.Sub(start).Hours() / 24)
+	return actual.Mul(totalDays / elapsedDays)
+}
+
+// percentOf returns value as a percentage of total rounded to two decimals
+func percentOf(value, total models.Money) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(value.Ratio(total)*10000) / 100
+}
+
+// splitConfigList splits a comma separated configuration value
+func splitConfigList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
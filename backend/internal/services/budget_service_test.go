@@ -0,0 +1,209 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupBudgetTestDB(t *testing.T) *gorm.DB {
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.CashFlowEntry{}, &models.BudgetTarget{}, &models.BudgetAlert{},
+		&models.Notification{}, &models.SystemConfig{}, &models.AuditTrail{}, &models.Account{}, &models.JournalEntry{},
+		&models.JournalLine{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func addBudgetExpense(t *testing.T, service *CashFlowService, category string, date time.Time, amount models.Money) {
+	var count int64
+	service.db.Model(&models.CashFlowEntry{}).Count(&count)
+
+	err := service.CreateCashFlowEntry(&models.CashFlowEntry{
+		TransactionID: fmt.Sprintf("TXN-TEST-%04d", count+1),
+		Date:          date,
+		Category:      category,
+		Type:          "expense",
+		Amount:        amount,
+		CreatedBy:     1,
+	})
+	require.NoError(t, err)
+}
+
+func TestBudgetService_LiveActualAndForecast(t *testing.T) {
+	db := setupBudgetTestDB(t)
+	service := NewBudgetService(db, nil)
+	service.now = func() time.Time { return time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local) }
+	cashFlowService := NewCashFlowService(db)
+
+	monthly := &models.BudgetTarget{Year: 2026, Month: 3, Category: "bahan_baku", Target: models.Rupiah(3100000)}
+	require.NoError(t, service.CreateBudgetTarget(monthly, 1))
+	yearly := &models.BudgetTarget{Year: 2026, Month: 0, Category: "bahan_baku", Target: models.Rupiah(40000000)}
+	require.NoError(t, service.CreateBudgetTarget(yearly, 1))
+
+	assert.ErrorIs(t, service.CreateBudgetTarget(&models.BudgetTarget{Year: 2026, Month: 3, Category: "bahan_baku", Target: models.Rupiah(1)}, 1), ErrDuplicateBudgetTarget)
+	assert.ErrorIs(t, service.CreateBudgetTarget(&models.BudgetTarget{Year: 2026, Month: 13, Category: "gaji", Target: models.Rupiah(1)}, 1), ErrInvalidBudgetPeriod)
+	assert.ErrorIs(t, service.CreateBudgetTarget(&models.BudgetTarget{Year: 2026, Month: 3, Category: "lainnya", Target: models.Rupiah(1)}, 1), ErrInvalidCategory)
+
+	addBudgetExpense(t, cashFlowService, "bahan_baku", time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local), models.Rupiah(600000))
+	addBudgetExpense(t, cashFlowService, "bahan_baku", time.Date(2026, 3, 9, 9, 0, 0, 0, time.Local), models.Rupiah(400000))
+	// Other months and categories are not counted in the March budget
+	addBudgetExpense(t, cashFlowService, "bahan_baku", time.Date(2026, 4, 1, 0, 0, 0, 0, time.Local), models.Rupiah(500000))
+	addBudgetExpense(t, cashFlowService, "gaji", time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local), models.Rupiah(900000))
+
+	status, err := service.GetBudgetTarget(monthly.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(1000000), status.Actual)
+	assert.Equal(t, models.Rupiah(2100000), status.Remaining)
+	assert.InDelta(t, 32.26, status.AbsorptionRate, 0.01)
+	// 1.000.000 over 10 days projected to 31 days
+	assert.Equal(t, models.Rupiah(3100000), status.Forecast)
+	assert.Equal(t, BudgetStatusWarning, status.Status)
+
+	// Reading a target must not write the computed realisation back
+	var stored models.BudgetTarget
+	require.NoError(t, db.First(&stored, monthly.ID).Error)
+	assert.Equal(t, models.Money(0), stored.Actual)
+
+	yearlyStatus, err := service.GetBudgetTarget(yearly.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(1500000), yearlyStatus.Actual)
+	assert.Equal(t, BudgetStatusOnTrack, yearlyStatus.Status)
+
+	summary, err := service.GetBudgetSummary(2026, 3)
+	require.NoError(t, err)
+	assert.Len(t, summary.Categories, 1)
+	assert.Equal(t, models.Rupiah(3100000), summary.TotalTarget)
+	assert.Equal(t, models.Rupiah(1000000), summary.TotalActual)
+}
+
+func TestBudgetService_ThresholdAlertsFireOnce(t *testing.T) {
+	db := setupBudgetTestDB(t)
+	service := NewBudgetService(db, nil)
+	cashFlowService := NewCashFlowService(db)
+	cashFlowService.SetBudgetService(service)
+
+	akuntan := models.User{NIK: "3201010101010010", Email: "akuntan@example.com", PasswordHash: "x", FullName: "Akuntan", Role: "akuntan", IsActive: true}
+	chef := models.User{NIK: "3201010101010011", Email: "chef@example.com", PasswordHash: "x", FullName: "Chef", Role: "chef", IsActive: true}
+	require.NoError(t, db.Create(&akuntan).Error)
+	require.NoError(t, db.Create(&chef).Error)
+
+	target := &models.BudgetTarget{Year: 2026, Month: 5, Category: "utilitas", Target: models.Rupiah(1000000)}
+	require.NoError(t, service.CreateBudgetTarget(target, 1))
+
+	countAlerts := func() (int64, int64) {
+		var alerts, notifications int64
+		db.Model(&models.BudgetAlert{}).Count(&alerts)
+		db.Model(&models.Notification{}).Where("type = ?", NotificationTypeBudgetAlert).Count(&notifications)
+		return alerts, notifications
+	}
+
+	may := time.Date(2026, 5, 10, 0, 0, 0, 0, time.Local)
+	addBudgetExpense(t, cashFlowService, "utilitas", may, models.Rupiah(700000))
+	alerts, notifications := countAlerts()
+	assert.Equal(t, int64(0), alerts)
+	assert.Equal(t, int64(0), notifications)
+
+	addBudgetExpense(t, cashFlowService, "utilitas", may, models.Rupiah(150000))
+	alerts, notifications = countAlerts()
+	assert.Equal(t, int64(1), alerts)
+	assert.Equal(t, int64(1), notifications)
+
+	// Staying above 80% must not notify again
+	addBudgetExpense(t, cashFlowService, "utilitas", may, models.Rupiah(10000))
+	alerts, notifications = countAlerts()
+	assert.Equal(t, int64(1), alerts)
+	assert.Equal(t, int64(1), notifications)
+
+	addBudgetExpense(t, cashFlowService, "utilitas", may, models.Rupiah(200000))
+	alerts, notifications = countAlerts()
+	assert.Equal(t, int64(2), alerts)
+	assert.Equal(t, int64(2), notifications)
+
+	var notification models.Notification
+	require.NoError(t, db.Where("type = ?", NotificationTypeBudgetAlert).Last(&notification).Error)
+	assert.Equal(t, akuntan.ID, notification.UserID)
+	assert.Contains(t, notification.Title, "100%")
+
+	// Raising the target below the 100% line re-arms that threshold only
+	status, err := service.UpdateBudgetTarget(target.ID, models.Rupiah(1200000), "revisi", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 88.33, status.AbsorptionRate)
+	alerts, _ = countAlerts()
+	assert.Equal(t, int64(1), alerts)
+
+	addBudgetExpense(t, cashFlowService, "utilitas", may, models.Rupiah(200000))
+	alerts, notifications = countAlerts()
+	assert.Equal(t, int64(2), alerts)
+	assert.Equal(t, int64(3), notifications)
+
+	require.NoError(t, service.DeleteBudgetTarget(target.ID, 1))
+	alerts, _ = countAlerts()
+	assert.Equal(t, int64(0), alerts)
+	_, err = service.GetBudgetTarget(target.ID)
+	assert.ErrorIs(t, err, ErrBudgetTargetNotFound)
+}
+
+func TestBudgetService_ImportTargets(t *testing.T) {
+	db := setupBudgetTestDB(t)
+	service := NewBudgetService(db, nil)
+
+	existing := &models.BudgetTarget{Year: 2026, Month: 1, Category: "gaji", Target: models.Rupiah(1000)}
+	require.NoError(t, service.CreateBudgetTarget(existing, 1))
+
+	csv := "Tahun,Bulan,Kategori,Target,Catatan\n" +
+		"2026,1,gaji,25000000,naik\n" +
+		"2026,0,bahan_baku,120000000,\n" +
+		"2026,2,utilitas,3000000,\n"
+	rows, parseErrors, err := service.ParseImportFile("anggaran.csv", strings.NewReader(csv))
+	require.NoError(t, err)
+	require.Empty(t, parseErrors)
+	require.Len(t, rows, 3)
+
+	result, err := service.ImportBudgetTargets(rows, parseErrors, true, 1)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	var count int64
+	db.Model(&models.BudgetTarget{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	result, err = service.ImportBudgetTargets(rows, parseErrors, false, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	db.Model(&models.BudgetTarget{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+
+	var updated models.BudgetTarget
+	require.NoError(t, db.First(&updated, existing.ID).Error)
+	assert.Equal(t, models.Rupiah(25000000), updated.Target)
+	assert.Equal(t, "naik", updated.Notes)
+
+	// A single invalid row rejects the whole file
+	invalid := "Tahun,Bulan,Kategori,Target\n" +
+		"2026,3,gaji,1000\n" +
+		"2026,14,gaji,1000\n" +
+		"2026,3,tidak_ada,abc\n"
+	rows, parseErrors, err = service.ParseImportFile("anggaran.csv", strings.NewReader(invalid))
+	require.NoError(t, err)
+	result, err = service.ImportBudgetTargets(rows, parseErrors, false, 1)
+	assert.ErrorIs(t, err, ErrImportValidationFailed)
+	require.NotNil(t, result)
+	assert.NotEmpty(t, result.Errors)
+	db.Model(&models.BudgetTarget{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+
+	_, _, err = service.ParseImportFile("anggaran.csv", strings.NewReader("Tahun,Bulan,Target\n2026,1,100\n"))
+	assert.ErrorIs(t, err, ErrMissingImportColumn)
+}
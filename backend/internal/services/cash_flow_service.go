@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -16,23 +17,51 @@ var (
 	ErrInvalidType        = errors.New("tipe transaksi tidak valid")
 )
 
+// CashFlowCategories lists the categories accepted for cash flow entries and budget targets
+var CashFlowCategories = []string{"bahan_baku", "gaji", "utilitas", "operasional"}
+
 // CashFlowService handles cash flow business logic
 type CashFlowService struct {
-	db *gorm.DB
+	db            *gorm.DB
+	ledgerService *LedgerService
+	budgetService *BudgetService
 }
 
 // NewCashFlowService creates a new cash flow service
 func NewCashFlowService(db *gorm.DB) *CashFlowService {
 	return &CashFlowService{
-		db: db,
+		db:            db,
+		ledgerService: NewLedgerService(db),
 	}
 }
 
+// SetBudgetService enables budget threshold alerts for new expenses
+func (s *CashFlowService) SetBudgetService(budgetService *BudgetService) {
+	s.budgetService = budgetService
+}
+
 // CreateCashFlowEntry creates a new cash flow entry
 func (s *CashFlowService) CreateCashFlowEntry(entry *models.CashFlowEntry) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		return s.CreateCashFlowEntryWithTx(tx, entry)
 	})
+	if err != nil {
+		return err
+	}
+
+	s.CheckBudgetAlerts(entry)
+	return nil
+}
+
+// CheckBudgetAlerts checks the budget thresholds of the entry's category.
+// Callers using CreateCashFlowEntryWithTx call it after their transaction commits.
+func (s *CashFlowService) CheckBudgetAlerts(entry *models.CashFlowEntry) {
+	if s.budgetService == nil || entry.Type != "expense" {
+		return
+	}
+	if err := s.budgetService.CheckThresholds(context.Background(), entry.Category, entry.Date); err != nil {
+		fmt.Printf("Peringatan: gagal memeriksa ambang anggaran: %v\n", err)
+	}
 }
 
 // CreateCashFlowEntryWithTx creates a new cash flow entry within a transaction
@@ -43,9 +72,8 @@ func (s *CashFlowService) CreateCashFlowEntryWithTx(tx *gorm.DB, entry *models.C
 	}
 
 	// Validate category
-	validCategories := []string{"bahan_baku", "gaji", "utilitas", "operasional"}
 	isValidCategory := false
-	for _, cat := range validCategories {
+	for _, cat := range CashFlowCategories {
 		if entry.Category == cat {
 			isValidCategory = true
 			break
@@ -69,7 +97,12 @@ func (s *CashFlowService) CreateCashFlowEntryWithTx(tx *gorm.DB, entry *models.C
 		entry.Date = time.Now()
 	}
 
-	return tx.Create(entry).Error
+	if err := tx.Create(entry).Error; err != nil {
+		return err
+	}
+
+	// Every cash flow entry is mirrored by a journal entry in the ledger
+	return s.ledgerService.PostCashFlowEntryWithTx(tx, entry)
 }
 
 // GetCashFlowEntryByID retrieves a cash flow entry by ID
@@ -110,12 +143,12 @@ func (s *CashFlowService) GetAllCashFlowEntries(category, entryType string, star
 
 // CashFlowSummary represents a summary of cash flow
 type CashFlowSummary struct {
-	TotalIncome      float64            `json:"total_income"`
-	TotalExpense     float64            `json:"total_expense"`
-	NetCashFlow      float64            `json:"net_cash_flow"`
-	ByCategory       map[string]float64 `json:"by_category"`
-	StartDate        time.Time          `json:"start_date"`
-	EndDate          time.Time          `json:"end_date"`
+	TotalIncome      models.Money            `json:"total_income"`
+	TotalExpense     models.Money            `json:"total_expense"`
+	NetCashFlow      models.Money            `json:"net_cash_flow"`
+	ByCategory       map[string]models.Money `json:"by_category"`
+	StartDate        time.Time               `json:"start_date"`
+	EndDate          time.Time               `json:"end_date"`
 }
 
 // GetCashFlowSummary generates a cash flow summary for a date range
@@ -123,7 +156,7 @@ func (s *CashFlowService) GetCashFlowSummary(startDate, endDate time.Time) (*Cas
 	summary := &CashFlowSummary{
 		StartDate:  startDate,
 		EndDate:    endDate,
-		ByCategory: make(map[string]float64),
+		ByCategory: make(map[string]models.Money),
 	}
 
 	// Get all entries in date range
@@ -152,8 +185,8 @@ func (s *CashFlowService) GetCashFlowSummary(startDate, endDate time.Time) (*Cas
 }
 
 // GetRunningBalance calculates running balance for a category
-func (s *CashFlowService) GetRunningBalance(category string, upToDate time.Time) (float64, error) {
-	var totalIncome, totalExpense float64
+func (s *CashFlowService) GetRunningBalance(category string, upToDate time.Time) (models.Money, error) {
+	var totalIncome, totalExpense models.Money
 
 	// Get total income
 	s.db.Model(&models.CashFlowEntry{}).
@@ -205,9 +238,8 @@ func (s *CashFlowService) UpdateCashFlowEntry(id uint, updates *models.CashFlowE
 
 	// Validate category if provided
 	if updates.Category != "" {
-		validCategories := []string{"bahan_baku", "gaji", "utilitas", "operasional"}
 		isValidCategory := false
-		for _, cat := range validCategories {
+		for _, cat := range CashFlowCategories {
 			if updates.Category == cat {
 				isValidCategory = true
 				break
@@ -218,27 +250,51 @@ func (s *CashFlowService) UpdateCashFlowEntry(id uint, updates *models.CashFlowE
 		}
 	}
 
-	// Update entry
-	return s.db.Model(&models.CashFlowEntry{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"date":        updates.Date,
-		"category":    updates.Category,
-		"type":        updates.Type,
-		"amount":      updates.Amount,
-		"description": updates.Description,
-		"reference":   updates.Reference,
-	}).Error
+	// Update entry and repost its journal
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.CashFlowEntry{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"date":        updates.Date,
+			"category":    updates.Category,
+			"type":        updates.Type,
+			"amount":      updates.Amount,
+			"description": updates.Description,
+			"reference":   updates.Reference,
+		}).Error; err != nil {
+			return err
+		}
+
+		var entry models.CashFlowEntry
+		if err := tx.First(&entry, id).Error; err != nil {
+			return err
+		}
+		return s.ledgerService.RepostCashFlowEntryWithTx(tx, &entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.CheckBudgetAlerts(updates)
+	return nil
 }
 
 // DeleteCashFlowEntry deletes a cash flow entry
 func (s *CashFlowService) DeleteCashFlowEntry(id uint) error {
-	result := s.db.Delete(&models.CashFlowEntry{}, id)
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return ErrCashFlowNotFound
-	}
-	return nil
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var entry models.CashFlowEntry
+		if err := tx.First(&entry, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCashFlowNotFound
+			}
+			return err
+		}
+
+		// Keep the ledger history and cancel the journal instead of deleting it
+		if err := s.ledgerService.ReverseSourceWithTx(tx, JournalSourceCashFlow, entry.TransactionID,
+			"Penghapusan arus kas "+entry.TransactionID, entry.CreatedBy); err != nil {
+			return err
+		}
+		return tx.Delete(&entry).Error
+	})
 }
 
 // generateTransactionID generates a unique transaction ID
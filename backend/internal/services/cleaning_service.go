@@ -6,47 +6,42 @@ import (
 	"log"
 	"time"
 
-	firebase "firebase.google.com/go/v4"
-	"firebase.google.com/go/v4/db"
 	"gorm.io/gorm"
 
 	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/realtime"
 )
 
-// cleaningSyncRetryItem represents an item in the cleaning retry queue
-type cleaningSyncRetryItem struct {
-	cleaningID  uint
-	attempt     int
-	nextRetryAt time.Time
-}
-
 // CleaningService handles ompreng cleaning operations
 type CleaningService struct {
-	db          *gorm.DB
-	firebaseApp *firebase.App
-	dbClient    *db.Client
-	retryQueue  chan cleaningSyncRetryItem
+	db        *gorm.DB
+	publisher realtime.RealtimePublisher
 }
 
 // NewCleaningService creates a new cleaning service
-func NewCleaningService(database *gorm.DB, firebaseApp *firebase.App) (*CleaningService, error) {
-	ctx := context.Background()
-	dbClient, err := firebaseApp.Database(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get Firebase database client: %w", err)
-	}
-
-	service := &CleaningService{
-		db:          database,
-		firebaseApp: firebaseApp,
-		dbClient:    dbClient,
-		retryQueue:  make(chan cleaningSyncRetryItem, 100), // Buffer for 100 retry items
-	}
+func NewCleaningService(database *gorm.DB, publisher realtime.RealtimePublisher) (*CleaningService, error) {
+	return &CleaningService{
+		db:        database,
+		publisher: publisher,
+	}, nil
+}
 
-	// Start retry worker goroutine
-	go service.retryWorker()
+// RegisterOutboxHandlers lets the outbox dispatcher deliver the realtime
+// syncs of cleaning records
+func (s *CleaningService) RegisterOutboxHandlers(outbox *OutboxService) {
+	outbox.RegisterHandler(OutboxEventCleaningSync, func(ctx context.Context, event *models.OutboxEvent) error {
+		return s.SyncToFirebase(&models.OmprengCleaning{ID: event.AggregateID})
+	})
+}
 
-	return service, nil
+// enqueueCleaningSync records the realtime sync of a cleaning record in tx
+func (s *CleaningService) enqueueCleaningSync(tx *gorm.DB, cleaningID uint, status string) error {
+	if s.publisher == nil {
+		return nil
+	}
+	return EnqueueOutboxEvent(tx, OutboxEventCleaningSync, "ompreng_cleaning", cleaningID, map[string]interface{}{
+		"status": status,
+	})
 }
 
 // GetPendingOmpreng retrieves ompreng cleaning records that are pending cleaning.
@@ -75,8 +70,9 @@ func (s *CleaningService) GetPendingOmpreng(dateStr string) ([]models.OmprengCle
 		date, err := time.Parse("2006-01-02", dateStr)
 		if err == nil {
 			// Filter by delivery_date in delivery_records
+			dayStart, dayEnd := localDayRange(date)
 			query = query.Joins("JOIN delivery_records ON delivery_records.id = ompreng_cleanings.delivery_record_id").
-				Where("DATE(delivery_records.delivery_date) = DATE(?)", date)
+				Where("delivery_records.delivery_date >= ? AND delivery_records.delivery_date < ?", dayStart, dayEnd)
 		}
 	}
 
@@ -94,7 +90,8 @@ func (s *CleaningService) GetPendingOmpreng(dateStr string) ([]models.OmprengCle
 	if dateStr != "" {
 		date, err := time.Parse("2006-01-02", dateStr)
 		if err == nil {
-			deliveryQuery = deliveryQuery.Where("DATE(delivery_date) = DATE(?)", date)
+			dayStart, dayEnd := localDayRange(date)
+			deliveryQuery = deliveryQuery.Where("delivery_date >= ? AND delivery_date < ?", dayStart, dayEnd)
 		}
 	}
 
@@ -140,7 +137,7 @@ func (s *CleaningService) GetPendingOmpreng(dateStr string) ([]models.OmprengCle
 // 2. Sets started_at timestamp to current time
 // 3. Sets cleaned_by to current user ID
 // 4. Updates corresponding delivery record status to "ompreng_proses_pencucian"
-// 5. Syncs to Firebase asynchronously
+// 5. Records the realtime sync in the outbox within the same transaction
 //
 // Parameters:
 //   - cleaningID: The ID of the ompreng cleaning record
@@ -197,29 +194,13 @@ func (s *CleaningService) StartCleaning(cleaningID uint, userID uint) error {
 			return err
 		}
 
-		return nil
+		return s.enqueueCleaningSync(tx, cleaning.ID, "in_progress")
 	})
 
 	if err != nil {
 		return err
 	}
 
-	// Update the cleaning record with new values for Firebase sync
-	cleaning.CleaningStatus = "in_progress"
-	now := getJakartaTime()
-	cleaning.StartedAt = &now
-	cleaning.CleanedBy = &userID
-
-	// Sync to Firebase asynchronously with retry mechanism
-	// Only spawn goroutine if Firebase is configured
-	if s.dbClient != nil {
-		go func() {
-			if syncErr := s.syncToFirebaseWithRetry(&cleaning, 0); syncErr != nil {
-				log.Printf("Firebase sync failed for cleaning record %d after all retries: %v", cleaning.ID, syncErr)
-			}
-		}()
-	}
-
 	return nil
 }
 
@@ -228,7 +209,7 @@ func (s *CleaningService) StartCleaning(cleaningID uint, userID uint) error {
 // 1. Updates ompreng_cleaning status to "completed"
 // 2. Sets completed_at timestamp to current time
 // 3. Updates corresponding delivery record status to "ompreng_selesai_dicuci"
-// 4. Syncs to Firebase asynchronously
+// 4. Records the realtime sync in the outbox within the same transaction
 //
 // Parameters:
 //   - cleaningID: The ID of the ompreng cleaning record
@@ -284,28 +265,13 @@ func (s *CleaningService) CompleteCleaning(cleaningID uint, userID uint) error {
 			return err
 		}
 
-		return nil
+		return s.enqueueCleaningSync(tx, cleaning.ID, "completed")
 	})
 
 	if err != nil {
 		return err
 	}
 
-	// Update the cleaning record with new values for Firebase sync
-	cleaning.CleaningStatus = "completed"
-	now := getJakartaTime()
-	cleaning.CompletedAt = &now
-
-	// Sync to Firebase asynchronously with retry mechanism
-	// Only spawn goroutine if Firebase is configured
-	if s.dbClient != nil {
-		go func() {
-			if syncErr := s.syncToFirebaseWithRetry(&cleaning, 0); syncErr != nil {
-				log.Printf("Firebase sync failed for cleaning record %d after all retries: %v", cleaning.ID, syncErr)
-			}
-		}()
-	}
-
 	return nil
 }
 
@@ -314,8 +280,7 @@ func (s *CleaningService) CompleteCleaning(cleaningID uint, userID uint) error {
 // /cleaning/pending/{cleaning_id} path.
 //
 // This method formats the cleaning record data and writes it to Firebase.
-// Errors are returned but should be handled gracefully by the caller,
-// typically with retry logic.
+// Errors are returned to the outbox dispatcher, which retries the sync.
 //
 // Parameters:
 //   - cleaning: The ompreng cleaning record to sync
@@ -364,120 +329,12 @@ func (s *CleaningService) SyncToFirebase(cleaning *models.OmprengCleaning) error
 	// Add last updated timestamp
 	data["last_updated"] = time.Now().Unix()
 
-	// Write to Firebase
-	if s.dbClient != nil {
-		ref := s.dbClient.NewRef(firebasePath)
-		ctx := context.Background()
-		if err := ref.Set(ctx, data); err != nil {
+	// Publish to connected clients
+	if s.publisher != nil {
+		if err := s.publisher.Set(context.Background(), firebasePath, data); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
-
-// syncToFirebaseWithRetry attempts to sync to Firebase with exponential backoff retry logic.
-// It implements the following retry strategy:
-// - Attempt 1: Immediate
-// - Attempt 2: 1 second delay
-// - Attempt 3: 2 seconds delay
-// - Attempt 4: 4 seconds delay
-// - Attempt 5: 8 seconds delay
-// - Attempt 6: 16 seconds delay
-// - Maximum retry attempts: 5 (total 6 attempts including initial)
-//
-// After max retries, the error is logged for admin alerting.
-//
-// Parameters:
-//   - cleaning: The ompreng cleaning record to sync
-//   - attempt: Current attempt number (0-based)
-//
-// Returns:
-//   - error: Error if all retry attempts fail
-//
-// Requirements: 1.1
-func (s *CleaningService) syncToFirebaseWithRetry(cleaning *models.OmprengCleaning, attempt int) error {
-	const maxRetries = 5
-
-	// Attempt to sync to Firebase
-	err := s.SyncToFirebase(cleaning)
-
-	if err == nil {
-		// Success
-		return nil
-	}
-
-	// Check if we've exceeded max retries
-	if attempt >= maxRetries {
-		// Log error for admin alerting
-		log.Printf("ERROR: Firebase sync failed for cleaning record %d after %d attempts: %v",
-			cleaning.ID, attempt+1, err)
-		return fmt.Errorf("firebase sync failed after %d attempts: %w", attempt+1, err)
-	}
-
-	// Calculate exponential backoff delay: 1s, 2s, 4s, 8s, 16s
-	backoffSeconds := 1 << attempt // 2^attempt
-	delay := time.Duration(backoffSeconds) * time.Second
-
-	log.Printf("Firebase sync failed for cleaning record %d (attempt %d/%d), retrying in %v: %v",
-		cleaning.ID, attempt+1, maxRetries+1, delay, err)
-
-	// Queue for retry with delay
-	go func() {
-		time.Sleep(delay)
-		if retryErr := s.syncToFirebaseWithRetry(cleaning, attempt+1); retryErr != nil {
-			// Final error already logged in the recursive call
-			_ = retryErr
-		}
-	}()
-
-	return nil
-}
-
-// retryWorker processes items from the retry queue
-// This is a background worker that handles delayed retries
-func (s *CleaningService) retryWorker() {
-	for item := range s.retryQueue {
-		// Wait until it's time to retry
-		now := time.Now()
-		if item.nextRetryAt.After(now) {
-			time.Sleep(item.nextRetryAt.Sub(now))
-		}
-
-		// Retrieve the cleaning record
-		var cleaning models.OmprengCleaning
-		if err := s.db.First(&cleaning, item.cleaningID).Error; err != nil {
-			log.Printf("Failed to retrieve cleaning record %d for retry: %v", item.cleaningID, err)
-			continue
-		}
-
-		// Attempt sync with retry
-		go func() {
-			if err := s.syncToFirebaseWithRetry(&cleaning, item.attempt); err != nil {
-				log.Printf("Retry failed for cleaning record %d: %v", cleaning.ID, err)
-			}
-		}()
-	}
-}
-
-// queueForRetry adds a cleaning record to the retry queue
-func (s *CleaningService) queueForRetry(cleaningID uint, attempt int) {
-	// Calculate next retry time with exponential backoff
-	backoffSeconds := 1 << attempt // 2^attempt
-	nextRetryAt := time.Now().Add(time.Duration(backoffSeconds) * time.Second)
-
-	item := cleaningSyncRetryItem{
-		cleaningID:  cleaningID,
-		attempt:     attempt,
-		nextRetryAt: nextRetryAt,
-	}
-
-	// Non-blocking send to queue
-	select {
-	case s.retryQueue <- item:
-		log.Printf("Queued cleaning record %d for retry (attempt %d) at %v",
-			cleaningID, attempt+1, nextRetryAt)
-	default:
-		log.Printf("WARNING: Retry queue full, dropping retry for cleaning record %d", cleaningID)
-	}
-}
@@ -7,51 +7,41 @@ import (
 	"math"
 	"time"
 
-	firebase "firebase.google.com/go/v4"
-	"firebase.google.com/go/v4/db"
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/realtime"
 	"gorm.io/gorm"
 )
 
 // DashboardService handles executive dashboard operations
 type DashboardService struct {
 	db                     *gorm.DB
-	firebaseApp            *firebase.App
-	dbClient               *db.Client
+	publisher              realtime.RealtimePublisher
 	kdsService             *KDSService
 	deliveryTaskService    *DeliveryTaskService
 	inventoryService       *InventoryService
 	cashFlowService        *CashFlowService
 	financialReportService *FinancialReportService
 	supplierService        *SupplierService
+	maintenancePlanService *MaintenancePlanService
 }
 
 // NewDashboardService creates a new dashboard service instance
-func NewDashboardService(database *gorm.DB, firebaseApp *firebase.App) (*DashboardService, error) {
-	var dbClient *db.Client
-	
-	// Try to get Firebase database client, but don't fail if Firebase is not available
-	if firebaseApp != nil {
-		ctx := context.Background()
-		var err error
-		dbClient, err = firebaseApp.Database(ctx)
-		if err != nil {
-			log.Printf("Warning: Failed to get Firebase database client: %v. Using dummy data mode.", err)
-			dbClient = nil
-		}
-	} else {
-		log.Println("Warning: Firebase app is nil. Dashboard will use dummy data.")
+func NewDashboardService(database *gorm.DB, publisher realtime.RealtimePublisher) (*DashboardService, error) {
+	// The dashboard works without a realtime backend, it just cannot push updates
+	if publisher == nil {
+		log.Println("Warning: No realtime backend configured. Dashboard updates will not be pushed.")
 	}
 
 	return &DashboardService{
 		db:                     database,
-		firebaseApp:            firebaseApp,
-		dbClient:               dbClient,
+		publisher:              publisher,
 		kdsService:             nil, // Will be initialized when needed
 		deliveryTaskService:    NewDeliveryTaskService(database),
 		inventoryService:       NewInventoryService(database),
 		cashFlowService:        NewCashFlowService(database),
 		financialReportService: NewFinancialReportService(database),
 		supplierService:        NewSupplierService(database),
+		maintenancePlanService: NewMaintenancePlanService(database, nil),
 	}, nil
 }
 
@@ -72,6 +62,7 @@ type KepalaSSPGDashboard struct {
 	PickupDetails     []SchoolDetail      `json:"pickup_details"`
 	CleaningDetails   []SchoolDetail      `json:"cleaning_details"`
 	CriticalStock     []CriticalStockItem `json:"critical_stock"`
+	AssetAlerts       []AssetAlert        `json:"asset_alerts"` // assets in poor condition or overdue for safety-critical maintenance
 	TodayKPIs         *TodayKPIs          `json:"today_kpis"`
 	UpdatedAt         time.Time           `json:"updated_at"`
 }
@@ -155,18 +146,18 @@ type KepalaYayasanDashboard struct {
 
 // BudgetAbsorption represents budget usage
 type BudgetAbsorption struct {
-	TotalBudget       float64                    `json:"total_budget"`
-	TotalSpent        float64                    `json:"total_spent"`
+	TotalBudget       models.Money               `json:"total_budget"`
+	TotalSpent        models.Money               `json:"total_spent"`
 	AbsorptionRate    float64                    `json:"absorption_rate"`
 	CategoryBreakdown []BudgetCategoryBreakdown  `json:"category_breakdown"`
 }
 
 // BudgetCategoryBreakdown represents budget by category
 type BudgetCategoryBreakdown struct {
-	Category       string  `json:"category"`
-	Budget         float64 `json:"budget"`
-	Spent          float64 `json:"spent"`
-	AbsorptionRate float64 `json:"absorption_rate"`
+	Category       string       `json:"category"`
+	Budget         models.Money `json:"budget"`
+	Spent          models.Money `json:"spent"`
+	AbsorptionRate float64      `json:"absorption_rate"`
 }
 
 // NutritionDistribution represents distribution metrics
@@ -187,11 +178,11 @@ type SupplierMetrics struct {
 
 // MonthlyMetrics represents monthly trend data
 type MonthlyMetrics struct {
-	Month              string  `json:"month"`
-	Year               int     `json:"year"`
-	PortionsDistributed int    `json:"portions_distributed"`
-	BudgetSpent        float64 `json:"budget_spent"`
-	SchoolsServed      int     `json:"schools_served"`
+	Month              string       `json:"month"`
+	Year               int          `json:"year"`
+	PortionsDistributed int         `json:"portions_distributed"`
+	BudgetSpent        models.Money `json:"budget_spent"`
+	SchoolsServed      int          `json:"schools_served"`
 }
 
 // GetKepalaSSPGDashboard retrieves operational dashboard data
@@ -240,6 +231,14 @@ func (s *DashboardService) GetKepalaSSPGDashboard(ctx context.Context) (*KepalaS
 	}
 	dashboard.CriticalStock = criticalStock
 
+	// Get asset alerts
+	assetAlerts, err := s.maintenancePlanService.GetAssetAlerts()
+	if err != nil {
+		log.Printf("Warning: Failed to get asset alerts: %v. Using empty list.", err)
+		assetAlerts = []AssetAlert{}
+	}
+	dashboard.AssetAlerts = assetAlerts
+
 	// Get production details (school-level)
 	productionDetails, err := s.getProductionDetails(ctx)
 	if err != nil {
@@ -340,6 +339,7 @@ func (s *DashboardService) getDummyKepalaSSPGDashboard() *KepalaSSPGDashboard {
 				DaysRemaining:  3.2,
 			},
 		},
+		AssetAlerts: []AssetAlert{},
 		TodayKPIs: &TodayKPIs{
 			PortionsPrepared:   3250,
 			DeliveryRate:       78.5,
@@ -600,12 +600,12 @@ func (s *DashboardService) getCleaningStatus(ctx context.Context) (*CleaningStat
 		}
 	}
 	
-	// Also check Firebase for any additional data (in case database is not synced)
-	if s.dbClient != nil {
-		log.Printf("Dashboard: Checking Firebase for cleaning data...")
+	// Also check the realtime backend for any additional data (in case database is not synced)
+	if s.publisher != nil {
+		log.Printf("Dashboard: Checking realtime backend for cleaning data...")
 		cleaningPath := "/cleaning/pending"
 		var cleaningRecords map[string]interface{}
-		err := s.dbClient.NewRef(cleaningPath).Get(ctx, &cleaningRecords)
+		err := s.publisher.Get(ctx, cleaningPath, &cleaningRecords)
 		
 		if err != nil {
 			if err.Error() != "client: no data at ref" {
@@ -1088,14 +1088,14 @@ func (s *DashboardService) getDummyKepalaYayasanDashboard() *KepalaYayasanDashbo
 	return &KepalaYayasanDashboard{
 		UpdatedAt: time.Now(),
 		BudgetAbsorption: &BudgetAbsorption{
-			TotalBudget:    5000000000,
-			TotalSpent:     3750000000,
+			TotalBudget:    models.Rupiah(5000000000),
+			TotalSpent:     models.Rupiah(3750000000),
 			AbsorptionRate: 75.0,
 			CategoryBreakdown: []BudgetCategoryBreakdown{
-				{Category: "bahan_baku", Budget: 3000000000, Spent: 2400000000, AbsorptionRate: 80.0},
-				{Category: "gaji", Budget: 1200000000, Spent: 900000000, AbsorptionRate: 75.0},
-				{Category: "operasional", Budget: 500000000, Spent: 300000000, AbsorptionRate: 60.0},
-				{Category: "utilitas", Budget: 300000000, Spent: 150000000, AbsorptionRate: 50.0},
+				{Category: "bahan_baku", Budget: models.Rupiah(3000000000), Spent: models.Rupiah(2400000000), AbsorptionRate: 80.0},
+				{Category: "gaji", Budget: models.Rupiah(1200000000), Spent: models.Rupiah(900000000), AbsorptionRate: 75.0},
+				{Category: "operasional", Budget: models.Rupiah(500000000), Spent: models.Rupiah(300000000), AbsorptionRate: 60.0},
+				{Category: "utilitas", Budget: models.Rupiah(300000000), Spent: models.Rupiah(150000000), AbsorptionRate: 50.0},
 			},
 		},
 		NutritionDistribution: &NutritionDistribution{
@@ -1111,12 +1111,12 @@ func (s *DashboardService) getDummyKepalaYayasanDashboard() *KepalaYayasanDashbo
 			AvgQualityRating:  4.2,
 		},
 		MonthlyTrend: []MonthlyMetrics{
-			{Month: "Januari", Year: 2026, PortionsDistributed: 42000, BudgetSpent: 350000000, SchoolsServed: 14},
-			{Month: "Februari", Year: 2026, PortionsDistributed: 45000, BudgetSpent: 375000000, SchoolsServed: 15},
-			{Month: "Maret", Year: 2026, PortionsDistributed: 43000, BudgetSpent: 360000000, SchoolsServed: 15},
-			{Month: "April", Year: 2026, PortionsDistributed: 46000, BudgetSpent: 380000000, SchoolsServed: 16},
-			{Month: "Mei", Year: 2026, PortionsDistributed: 48000, BudgetSpent: 400000000, SchoolsServed: 16},
-			{Month: "Juni", Year: 2026, PortionsDistributed: 47000, BudgetSpent: 390000000, SchoolsServed: 16},
+			{Month: "Januari", Year: 2026, PortionsDistributed: 42000, BudgetSpent: models.Rupiah(350000000), SchoolsServed: 14},
+			{Month: "Februari", Year: 2026, PortionsDistributed: 45000, BudgetSpent: models.Rupiah(375000000), SchoolsServed: 15},
+			{Month: "Maret", Year: 2026, PortionsDistributed: 43000, BudgetSpent: models.Rupiah(360000000), SchoolsServed: 15},
+			{Month: "April", Year: 2026, PortionsDistributed: 46000, BudgetSpent: models.Rupiah(380000000), SchoolsServed: 16},
+			{Month: "Mei", Year: 2026, PortionsDistributed: 48000, BudgetSpent: models.Rupiah(400000000), SchoolsServed: 16},
+			{Month: "Juni", Year: 2026, PortionsDistributed: 47000, BudgetSpent: models.Rupiah(390000000), SchoolsServed: 16},
 		},
 	}
 }
@@ -1126,7 +1126,7 @@ func (s *DashboardService) getBudgetAbsorption(ctx context.Context, startDate, e
 	// Get budget targets for the period
 	var budgetTargets []struct {
 		Category string
-		Target   float64
+		Target   models.Money
 	}
 	err := s.db.WithContext(ctx).
 		Table("budget_targets").
@@ -1144,7 +1144,7 @@ func (s *DashboardService) getBudgetAbsorption(ctx context.Context, startDate, e
 	// Get actual spending by category
 	var actualSpending []struct {
 		Category string
-		Amount   float64
+		Amount   models.Money
 	}
 	err = s.db.WithContext(ctx).
 		Table("cash_flow_entries").
@@ -1157,18 +1157,18 @@ func (s *DashboardService) getBudgetAbsorption(ctx context.Context, startDate, e
 	}
 
 	// Build category breakdown
-	budgetMap := make(map[string]float64)
+	budgetMap := make(map[string]models.Money)
 	for _, bt := range budgetTargets {
 		budgetMap[bt.Category] = bt.Target
 	}
 
-	actualMap := make(map[string]float64)
+	actualMap := make(map[string]models.Money)
 	for _, as := range actualSpending {
 		actualMap[as.Category] = as.Amount
 	}
 
 	var categoryBreakdown []BudgetCategoryBreakdown
-	var totalBudget, totalSpent float64
+	var totalBudget, totalSpent models.Money
 
 	// Combine all categories
 	allCategories := make(map[string]bool)
@@ -1184,7 +1184,7 @@ func (s *DashboardService) getBudgetAbsorption(ctx context.Context, startDate, e
 		spent := actualMap[category]
 		absorptionRate := 0.0
 		if budget > 0 {
-			absorptionRate = roundToDecimal((spent/budget)*100, 2)
+			absorptionRate = roundToDecimal(spent.Ratio(budget)*100, 2)
 		}
 
 		categoryBreakdown = append(categoryBreakdown, BudgetCategoryBreakdown{
@@ -1200,7 +1200,7 @@ func (s *DashboardService) getBudgetAbsorption(ctx context.Context, startDate, e
 
 	overallAbsorptionRate := 0.0
 	if totalBudget > 0 {
-		overallAbsorptionRate = roundToDecimal((totalSpent/totalBudget)*100, 2)
+		overallAbsorptionRate = roundToDecimal(totalSpent.Ratio(totalBudget)*100, 2)
 	}
 
 	return &BudgetAbsorption{
@@ -1320,7 +1320,7 @@ func (s *DashboardService) getMonthlyTrend(ctx context.Context, startDate, endDa
 			Scan(&portionsDistributed)
 
 		// Get budget spent
-		var budgetSpent float64
+		var budgetSpent models.Money
 		s.db.WithContext(ctx).
 			Table("cash_flow_entries").
 			Where("type = ? AND date BETWEEN ? AND ?", "expense", monthStart, monthEnd).
@@ -1350,10 +1350,10 @@ func (s *DashboardService) getMonthlyTrend(ctx context.Context, startDate, endDa
 	return trend, nil
 }
 
-// SyncKepalaSSPGDashboardToFirebase syncs Kepala SPPG dashboard to Firebase
+// SyncKepalaSSPGDashboardToFirebase pushes the Kepala SPPG dashboard to connected clients
 func (s *DashboardService) SyncKepalaSSPGDashboardToFirebase(ctx context.Context) error {
-	if s.dbClient == nil {
-		return fmt.Errorf("Firebase client tidak tersedia")
+	if s.publisher == nil {
+		return fmt.Errorf("backend realtime tidak tersedia")
 	}
 
 	dashboard, err := s.GetKepalaSSPGDashboard(ctx)
@@ -1362,18 +1362,18 @@ func (s *DashboardService) SyncKepalaSSPGDashboardToFirebase(ctx context.Context
 	}
 
 	firebasePath := "/dashboard/kepala_sppg"
-	err = s.dbClient.NewRef(firebasePath).Set(ctx, dashboard)
+	err = s.publisher.Set(ctx, firebasePath, dashboard)
 	if err != nil {
-		return fmt.Errorf("gagal sync dashboard ke Firebase: %w", err)
+		return fmt.Errorf("gagal sync dashboard realtime: %w", err)
 	}
 
 	return nil
 }
 
-// SyncKepalaYayasanDashboardToFirebase syncs Kepala Yayasan dashboard to Firebase
+// SyncKepalaYayasanDashboardToFirebase pushes the Kepala Yayasan dashboard to connected clients
 func (s *DashboardService) SyncKepalaYayasanDashboardToFirebase(ctx context.Context, startDate, endDate time.Time) error {
-	if s.dbClient == nil {
-		return fmt.Errorf("Firebase client tidak tersedia")
+	if s.publisher == nil {
+		return fmt.Errorf("backend realtime tidak tersedia")
 	}
 
 	dashboard, err := s.GetKepalaYayasanDashboard(ctx, startDate, endDate)
@@ -1382,9 +1382,9 @@ func (s *DashboardService) SyncKepalaYayasanDashboardToFirebase(ctx context.Cont
 	}
 
 	firebasePath := "/dashboard/kepala_yayasan"
-	err = s.dbClient.NewRef(firebasePath).Set(ctx, dashboard)
+	err = s.publisher.Set(ctx, firebasePath, dashboard)
 	if err != nil {
-		return fmt.Errorf("gagal sync dashboard ke Firebase: %w", err)
+		return fmt.Errorf("gagal sync dashboard realtime: %w", err)
 	}
 
 	return nil
@@ -1424,21 +1424,15 @@ func (s *DashboardService) ExportDashboardData(ctx context.Context, dashboardTyp
 	return data, nil
 }
 
-// ClearFirebaseKDSData clears all KDS-related data from Firebase
+// ClearFirebaseKDSData clears all KDS-related data from the realtime backend
 func (s *DashboardService) ClearFirebaseKDSData(ctx context.Context) error {
-	if s.firebaseApp == nil {
-		return fmt.Errorf("Firebase app tidak tersedia")
-	}
-
-	// Create Firebase sync service
-	firebaseSync, err := NewFirebaseSyncService(s.firebaseApp)
-	if err != nil {
-		return fmt.Errorf("gagal membuat Firebase sync service: %w", err)
+	if s.publisher == nil {
+		return fmt.Errorf("backend realtime tidak tersedia")
 	}
 
 	// Clear KDS data
-	if err := firebaseSync.ClearKDSData(ctx); err != nil {
-		return fmt.Errorf("gagal menghapus data KDS dari Firebase: %w", err)
+	if err := NewRealtimeSyncService(s.publisher).ClearKDSData(ctx); err != nil {
+		return fmt.Errorf("gagal menghapus data KDS realtime: %w", err)
 	}
 
 	return nil
@@ -9,7 +9,6 @@ import (
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -17,7 +16,7 @@ import (
 // setupDeliveryTestDB creates an in-memory SQLite database for delivery task testing
 func setupDeliveryTestDB(t *testing.T) *gorm.DB {
 	// Use SQLite in-memory database for property tests
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+	db, err := openTestDB(t, ":memory:", &gorm.Config{
 		Logger: logger.Discard,
 	})
 	if err != nil {
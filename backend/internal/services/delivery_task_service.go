@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -260,7 +261,10 @@ func (s *DeliveryTaskService) UpdateDeliveryTaskStatus(id uint, status string) e
 	}
 
 	// Update in transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var deliveryRecords []models.DeliveryRecord
+	var published []events.Event
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Update delivery task status and current_stage
 		if err := tx.Model(&models.DeliveryTask{}).
 			Where("id = ?", id).
@@ -278,9 +282,9 @@ func (s *DeliveryTaskService) UpdateDeliveryTaskStatus(id uint, status string) e
 		}
 
 		// Find all delivery records for this task (by school_id, driver_id, and date)
-		var deliveryRecords []models.DeliveryRecord
-		if err := tx.Where("school_id = ? AND driver_id = ? AND DATE(delivery_date) = DATE(?)",
-			task.SchoolID, task.DriverID, task.TaskDate).
+		dayStart, dayEnd := localDayRange(task.TaskDate)
+		if err := tx.Where("school_id = ? AND driver_id = ? AND delivery_date >= ? AND delivery_date < ?",
+			task.SchoolID, task.DriverID, dayStart, dayEnd).
 			Find(&deliveryRecords).Error; err != nil {
 			return err
 		}
@@ -313,10 +317,18 @@ func (s *DeliveryTaskService) UpdateDeliveryTaskStatus(id uint, status string) e
 			if err := tx.Create(&transition).Error; err != nil {
 				return err
 			}
+
+			published = append(published, deliveryStatusEvents(&record, mapping.Status, now)...)
 		}
 
-		return nil
+		return events.PublishTx(tx, published...)
 	})
+	if err != nil {
+		return err
+	}
+
+	events.Notify(published...)
+	return nil
 }
 
 // AssignDriverToTask assigns a driver to a delivery task
@@ -418,10 +430,11 @@ type ReadyOrderResponse struct {
 // GetReadyOrders retrieves delivery records that are ready for delivery (status = selesai_dipacking)
 func (s *DeliveryTaskService) GetReadyOrders(date time.Time) ([]ReadyOrderResponse, error) {
 	var orders []ReadyOrderResponse
+	dayStart, dayEnd := localDayRange(date)
 	
 	// Query delivery records with status "selesai_dipacking" (packing completed, ready for delivery)
 	// This status is set when packing is completed in KDS Packing
-	// Only show orders that don't have a driver assigned yet (driver_id IS NULL)
+	// A driver set during an earlier step does not hide the order, the task assigns or reassigns it
 	err := s.db.Table("delivery_records").
 		Select(`
 			delivery_records.id,
@@ -438,9 +451,9 @@ func (s *DeliveryTaskService) GetReadyOrders(date time.Time) ([]ReadyOrderRespon
 		Joins("JOIN schools ON delivery_records.school_id = schools.id").
 		Joins("JOIN menu_items ON delivery_records.menu_item_id = menu_items.id").
 		Joins("JOIN recipes ON menu_items.recipe_id = recipes.id").
-		Where("DATE(delivery_records.delivery_date) = DATE(?)", date).
+		Where("delivery_records.delivery_date >= ? AND delivery_records.delivery_date < ?", dayStart, dayEnd).
 		Where("delivery_records.current_status = ?", "selesai_dipacking").
-		Where("delivery_records.driver_id IS NULL").
+		Order("schools.name, delivery_records.id").
 		Scan(&orders).Error
 	
 	if err != nil {
@@ -472,13 +485,14 @@ func (s *DeliveryTaskService) GetAvailableDrivers(date time.Time) ([]AvailableDr
 		AND u.id NOT IN (
 			SELECT DISTINCT driver_id 
 			FROM delivery_tasks 
-			WHERE DATE(task_date) = DATE(?)
+			WHERE task_date >= ? AND task_date < ?
 			AND driver_id IS NOT NULL
 		)
 		ORDER BY u.full_name
 	`
 	
-	err := s.db.Raw(query, "driver", true, date).Scan(&drivers).Error
+	dayStart, dayEnd := localDayRange(date)
+	err := s.db.Raw(query, "driver", true, dayStart, dayEnd).Scan(&drivers).Error
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,478 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/utils"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnsupportedImportFormat = errors.New("format file tidak didukung (gunakan .xlsx atau .csv)")
+	ErrEmptyImportFile         = errors.New("file impor tidak berisi data karyawan")
+	ErrMissingImportColumn     = errors.New("kolom wajib tidak ditemukan pada file impor")
+	ErrImportValidationFailed  = errors.New("terdapat baris yang tidak valid pada file impor")
+)
+
+// MaxEmployeeImportRows limits the number of rows accepted in a single import
+const MaxEmployeeImportRows = 500
+
+// employeeImportColumns maps accepted header names (lowercase) to their field key
+var employeeImportColumns = map[string]string{
+	"nik":               "nik",
+	"nama":              "full_name",
+	"nama lengkap":      "full_name",
+	"full_name":         "full_name",
+	"email":             "email",
+	"no. telepon":       "phone_number",
+	"no telepon":        "phone_number",
+	"telepon":           "phone_number",
+	"phone_number":      "phone_number",
+	"posisi":            "position",
+	"jabatan":           "position",
+	"position":          "position",
+	"role":              "role",
+	"peran":             "role",
+	"tanggal bergabung": "join_date",
+	"tanggal masuk":     "join_date",
+	"join_date":         "join_date",
+}
+
+// requiredImportFields lists the field keys that must be present as columns
+var requiredImportFields = []string{"nik", "full_name", "email", "position", "role", "join_date"}
+
+// employeeImportDateLayouts lists accepted date formats for the join date column
+var employeeImportDateLayouts = []string{"2006-01-02", "02/01/2006", "02-01-2006", "1/2/2006"}
+
+// EmployeeImportRow represents a single parsed row from an import file
+type EmployeeImportRow struct {
+	RowNumber   int    `json:"row_number"` // 1-based row number in the source file
+	NIK         string `json:"nik"`
+	FullName    string `json:"full_name"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+	Position    string `json:"position"`
+	Role        string `json:"role"`
+	JoinDate    string `json:"join_date"`
+}
+
+// ImportRowError describes a validation problem on one row of an uploaded
+// file, shared by the employee and budget imports
+type ImportRowError struct {
+	RowNumber int    `json:"row_number"`
+	Field     string `json:"field"`
+	Message   string `json:"message"`
+}
+
+// EmployeeImportCredential is one line of the printable credential sheet
+type EmployeeImportCredential struct {
+	RowNumber  int    `json:"row_number"`
+	EmployeeID uint   `json:"employee_id"`
+	UserID     uint   `json:"user_id"`
+	NIK        string `json:"nik"`
+	FullName   string `json:"full_name"`
+	Email      string `json:"email"`
+	Role       string `json:"role"`
+	Password   string `json:"password"`
+}
+
+// EmployeeImportResult summarises an import run
+type EmployeeImportResult struct {
+	TotalRows   int                        `json:"total_rows"`
+	Imported    int                        `json:"imported"`
+	DryRun      bool                       `json:"dry_run"`
+	Errors      []ImportRowError           `json:"errors"`
+	Credentials []EmployeeImportCredential `json:"credentials,omitempty"`
+}
+
+// EmployeeImportService handles bulk onboarding of employees from spreadsheets
+type EmployeeImportService struct {
+	db              *gorm.DB
+	employeeService *EmployeeService
+	exportService   *ExportService
+}
+
+// NewEmployeeImportService creates a new employee import service
+func NewEmployeeImportService(db *gorm.DB, employeeService *EmployeeService) *EmployeeImportService {
+	return &EmployeeImportService{
+		db:              db,
+		employeeService: employeeService,
+		exportService:   NewExportService("SPPG - Satuan Pelayanan Pemenuhan Gizi"),
+	}
+}
+
+// ParseImportFile reads an XLSX or CSV file into import rows.
+// The file format is determined by the filename extension.
+func (s *EmployeeImportService) ParseImportFile(filename string, r io.Reader) ([]EmployeeImportRow, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx":
+		records, err = readXLSXRecords(r)
+	case ".csv":
+		records, err = readCSVRecords(r)
+	default:
+		return nil, ErrUnsupportedImportFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) < 2 {
+		return nil, ErrEmptyImportFile
+	}
+
+	// Map header columns to field keys
+	columnIndex := make(map[string]int)
+	for i, header := range records[0] {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		if field, ok := employeeImportColumns[key]; ok {
+			columnIndex[field] = i
+		}
+	}
+	for _, field := range requiredImportFields {
+		if _, ok := columnIndex[field]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingImportColumn, field)
+		}
+	}
+
+	cell := func(record []string, field string) string {
+		idx, ok := columnIndex[field]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	rows := make([]EmployeeImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		rows = append(rows, EmployeeImportRow{
+			RowNumber:   i + 2, // header is row 1
+			NIK:         cell(record, "nik"),
+			FullName:    cell(record, "full_name"),
+			Email:       strings.ToLower(cell(record, "email")),
+			PhoneNumber: cell(record, "phone_number"),
+			Position:    cell(record, "position"),
+			Role:        strings.ToLower(cell(record, "role")),
+			JoinDate:    cell(record, "join_date"),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrEmptyImportFile
+	}
+	if len(rows) > MaxEmployeeImportRows {
+		return nil, fmt.Errorf("jumlah baris melebihi batas maksimum %d", MaxEmployeeImportRows)
+	}
+
+	return rows, nil
+}
+
+// ValidateRows checks each row and returns all problems found, including
+// duplicates within the file and conflicts with existing employees or users
+func (s *EmployeeImportService) ValidateRows(rows []EmployeeImportRow) ([]ImportRowError, error) {
+	var rowErrors []ImportRowError
+	addError := func(row int, field, message string) {
+		rowErrors = append(rowErrors, ImportRowError{RowNumber: row, Field: field, Message: message})
+	}
+
+	validRoles, err := activeRoleNames(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	seenNIK := make(map[string]int)
+	seenEmail := make(map[string]int)
+	niks := make([]string, 0, len(rows))
+	emails := make([]string, 0, len(rows))
+
+	for _, row := range rows {
+		if err := utils.ValidateNIK(row.NIK); err != nil {
+			addError(row.RowNumber, "nik", "NIK harus 16 digit angka")
+		} else if first, ok := seenNIK[row.NIK]; ok {
+			addError(row.RowNumber, "nik", fmt.Sprintf("NIK duplikat dengan baris %d", first))
+		} else {
+			seenNIK[row.NIK] = row.RowNumber
+			niks = append(niks, row.NIK)
+		}
+
+		if row.FullName == "" {
+			addError(row.RowNumber, "full_name", "nama lengkap tidak boleh kosong")
+		}
+
+		if err := utils.ValidateEmail(row.Email); err != nil {
+			addError(row.RowNumber, "email", "format email tidak valid")
+		} else if first, ok := seenEmail[row.Email]; ok {
+			addError(row.RowNumber, "email", fmt.Sprintf("email duplikat dengan baris %d", first))
+		} else {
+			seenEmail[row.Email] = row.RowNumber
+			emails = append(emails, row.Email)
+		}
+
+		if row.PhoneNumber != "" {
+			if err := utils.ValidatePhone(row.PhoneNumber); err != nil {
+				addError(row.RowNumber, "phone_number", "format nomor telepon tidak valid")
+			}
+		}
+
+		if row.Position == "" {
+			addError(row.RowNumber, "position", "posisi tidak boleh kosong")
+		}
+
+		if !validRoles[row.Role] {
+			addError(row.RowNumber, "role", fmt.Sprintf("role '%s' tidak dikenal", row.Role))
+		}
+
+		if _, err := parseImportDate(row.JoinDate); err != nil {
+			addError(row.RowNumber, "join_date", "format tanggal bergabung tidak valid (gunakan YYYY-MM-DD)")
+		}
+	}
+
+	// Check conflicts with existing records in a single query per column
+	if len(niks) > 0 {
+		var existing []string
+		if err := s.db.Model(&models.User{}).Where("nik IN ?", niks).Pluck("nik", &existing).Error; err != nil {
+			return nil, err
+		}
+		for _, nik := range existing {
+			addError(seenNIK[nik], "nik", "NIK sudah terdaftar")
+		}
+	}
+	if len(emails) > 0 {
+		var existing []string
+		if err := s.db.Model(&models.User{}).Where("email IN ?", emails).Pluck("email", &existing).Error; err != nil {
+			return nil, err
+		}
+		for _, email := range existing {
+			addError(seenEmail[email], "email", "email sudah terdaftar")
+		}
+	}
+
+	return rowErrors, nil
+}
+
+// ImportEmployees validates and creates all rows in one transaction.
+// Nothing is written when any row is invalid or when dryRun is true.
+func (s *EmployeeImportService) ImportEmployees(rows []EmployeeImportRow, dryRun bool) (*EmployeeImportResult, error) {
+	result := &EmployeeImportResult{
+		TotalRows: len(rows),
+		DryRun:    dryRun,
+	}
+
+	rowErrors, err := s.ValidateRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	result.Errors = rowErrors
+	if len(rowErrors) > 0 {
+		return result, ErrImportValidationFailed
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	credentials := make([]EmployeeImportCredential, 0, len(rows))
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			joinDate, _ := parseImportDate(row.JoinDate)
+			password := s.employeeService.generatePassword()
+			hashedPassword, err := s.employeeService.authService.HashPassword(password)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			user := &models.User{
+				NIK:                row.NIK,
+				Email:              row.Email,
+				PasswordHash:       hashedPassword,
+				FullName:           row.FullName,
+				PhoneNumber:        row.PhoneNumber,
+				Role:               row.Role,
+				IsActive:           true,
+				MustChangePassword: true,
+				PasswordChangedAt:  &now,
+			}
+			if err := tx.Create(user).Error; err != nil {
+				return fmt.Errorf("baris %d: %w", row.RowNumber, err)
+			}
+
+			employee := &models.Employee{
+				UserID:      user.ID,
+				NIK:         row.NIK,
+				FullName:    row.FullName,
+				Email:       row.Email,
+				PhoneNumber: row.PhoneNumber,
+				Position:    row.Position,
+				JoinDate:    joinDate,
+				IsActive:    true,
+			}
+			if err := tx.Create(employee).Error; err != nil {
+				return fmt.Errorf("baris %d: %w", row.RowNumber, err)
+			}
+
+			credentials = append(credentials, EmployeeImportCredential{
+				RowNumber:  row.RowNumber,
+				EmployeeID: employee.ID,
+				UserID:     user.ID,
+				NIK:        row.NIK,
+				FullName:   row.FullName,
+				Email:      row.Email,
+				Role:       row.Role,
+				Password:   password,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Imported = len(credentials)
+	result.Credentials = credentials
+	return result, nil
+}
+
+// GenerateCredentialSheet renders the credential sheet as PDF or Excel
+func (s *EmployeeImportService) GenerateCredentialSheet(credentials []EmployeeImportCredential, format, generatedBy string) (*bytes.Buffer, error) {
+	data := &ExportData{
+		Title:       "Daftar Akun Karyawan Baru",
+		Headers:     []string{"No", "NIK", "Nama Lengkap", "Email", "Role", "Password Awal"},
+		Rows:        make([][]string, 0, len(credentials)),
+		GeneratedBy: generatedBy,
+	}
+	for i, cred := range credentials {
+		data.Rows = append(data.Rows, []string{
+			fmt.Sprintf("%d", i+1),
+			cred.NIK,
+			cred.FullName,
+			cred.Email,
+			cred.Role,
+			cred.Password,
+		})
+	}
+
+	if format == "excel" {
+		return s.exportService.ExportToExcel(data)
+	}
+	return s.exportService.ExportToPDF(data)
+}
+
+// GenerateImportTemplate returns an empty XLSX template with the expected headers
+func (s *EmployeeImportService) GenerateImportTemplate() (*bytes.Buffer, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheetName := "Karyawan"
+	index, err := f.NewSheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	f.SetActiveSheet(index)
+	f.DeleteSheet("Sheet1")
+
+	headers := []string{"NIK", "Nama Lengkap", "Email", "No. Telepon", "Posisi", "Role", "Tanggal Bergabung"}
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#D3D3D3"}, Pattern: 1},
+	})
+	for i, header := range headers {
+		col, _ := excelize.ColumnNumberToName(i + 1)
+		f.SetCellValue(sheetName, fmt.Sprintf("%s1", col), header)
+		f.SetCellStyle(sheetName, fmt.Sprintf("%s1", col), fmt.Sprintf("%s1", col), headerStyle)
+		f.SetColWidth(sheetName, col, col, 20)
+	}
+
+	// Keep NIK and phone columns as text so leading zeros survive
+	textStyle, _ := f.NewStyle(&excelize.Style{NumFmt: 49})
+	f.SetColStyle(sheetName, "A", textStyle)
+	f.SetColStyle(sheetName, "D", textStyle)
+
+	// Example row
+	example := []string{"3201010101010001", "Budi Santoso", "budi@example.com", "081234567890", "Juru Masak", "chef", time.Now().Format("2006-01-02")}
+	for i, value := range example {
+		col, _ := excelize.ColumnNumberToName(i + 1)
+		f.SetCellValue(sheetName, fmt.Sprintf("%s2", col), value)
+	}
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// readXLSXRecords reads all rows from the first sheet of an XLSX file
+func readXLSXRecords(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file xlsx: %w", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, ErrEmptyImportFile
+	}
+	return f.GetRows(sheets[0])
+}
+
+// readCSVRecords reads a CSV file, accepting comma or semicolon separators
+func readCSVRecords(r io.Reader) ([][]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// Spreadsheets saved with an Indonesian locale use semicolons
+	firstLine := content
+	if idx := bytes.IndexByte(content, '\n'); idx >= 0 {
+		firstLine = content[:idx]
+	}
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file csv: %w", err)
+	}
+	return records, nil
+}
+
+// isBlankRecord reports whether every cell in the record is empty
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseImportDate parses a join date using the accepted layouts
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range employeeImportDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("tanggal tidak valid: %s", value)
+}
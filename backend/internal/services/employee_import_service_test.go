@@ -0,0 +1,145 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupEmployeeImportTestDB(t *testing.T) *gorm.DB {
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Employee{}, &models.UserSession{}, &models.RefreshToken{}, &models.Role{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func newTestEmployeeImportService(db *gorm.DB) *EmployeeImportService {
+	authService := NewAuthService(db, "test-secret")
+	return NewEmployeeImportService(db, NewEmployeeService(db, authService))
+}
+
+const validEmployeeCSV = "NIK;Nama Lengkap;Email;No. Telepon;Posisi;Role;Tanggal Bergabung\n" +
+	"3201010101010001;Budi Santoso;budi@example.com;081234567890;Juru Masak;chef;2026-01-05\n" +
+	"3201010101010002;Siti Aminah;SITI@example.com;;Ahli Gizi;ahli_gizi;05/01/2026\n"
+
+func TestEmployeeImportService_ParseCSV(t *testing.T) {
+	service := newTestEmployeeImportService(setupEmployeeImportTestDB(t))
+
+	rows, err := service.ParseImportFile("karyawan.csv", strings.NewReader(validEmployeeCSV))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, 2, rows[0].RowNumber)
+	assert.Equal(t, "3201010101010001", rows[0].NIK)
+	assert.Equal(t, "chef", rows[0].Role)
+	assert.Equal(t, "siti@example.com", rows[1].Email, "email should be lowercased")
+	assert.Equal(t, 3, rows[1].RowNumber)
+}
+
+func TestEmployeeImportService_ParseRejectsMissingColumnsAndFormat(t *testing.T) {
+	service := newTestEmployeeImportService(setupEmployeeImportTestDB(t))
+
+	_, err := service.ParseImportFile("karyawan.csv", strings.NewReader("NIK,Email\n3201010101010001,a@b.com\n"))
+	assert.ErrorIs(t, err, ErrMissingImportColumn)
+
+	_, err = service.ParseImportFile("karyawan.txt", strings.NewReader(validEmployeeCSV))
+	assert.ErrorIs(t, err, ErrUnsupportedImportFormat)
+}
+
+func TestEmployeeImportService_ImportCreatesUsersInOneTransaction(t *testing.T) {
+	db := setupEmployeeImportTestDB(t)
+	service := newTestEmployeeImportService(db)
+
+	rows, err := service.ParseImportFile("karyawan.csv", strings.NewReader(validEmployeeCSV))
+	require.NoError(t, err)
+
+	result, err := service.ImportEmployees(rows, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	require.Len(t, result.Credentials, 2)
+	assert.NotEmpty(t, result.Credentials[0].Password)
+
+	var user models.User
+	require.NoError(t, db.Where("nik = ?", "3201010101010002").First(&user).Error)
+	assert.Equal(t, "ahli_gizi", user.Role)
+
+	var employeeCount int64
+	db.Model(&models.Employee{}).Count(&employeeCount)
+	assert.Equal(t, int64(2), employeeCount)
+}
+
+func TestEmployeeImportService_ReportsErrorsPerRowAndWritesNothing(t *testing.T) {
+	db := setupEmployeeImportTestDB(t)
+	service := newTestEmployeeImportService(db)
+
+	// Existing user conflicts with row 3
+	require.NoError(t, db.Create(&models.User{
+		NIK: "3201010101010009", Email: "lama@example.com", PasswordHash: "x", FullName: "Lama", Role: "chef", IsActive: true,
+	}).Error)
+
+	rows := []EmployeeImportRow{
+		{RowNumber: 2, NIK: "123", FullName: "Salah NIK", Email: "a@example.com", Position: "Staff", Role: "chef", JoinDate: "2026-01-01"},
+		{RowNumber: 3, NIK: "3201010101010009", FullName: "Duplikat DB", Email: "b@example.com", Position: "Staff", Role: "chef", JoinDate: "2026-01-01"},
+		{RowNumber: 4, NIK: "3201010101010010", FullName: "Role Salah", Email: "a@example.com", Position: "Staff", Role: "admin", JoinDate: "2026-01-01"},
+		{RowNumber: 5, NIK: "3201010101010011", FullName: "Telepon Salah", Email: "c@example.com", PhoneNumber: "12ab", Position: "Staff", Role: "driver", JoinDate: "kemarin"},
+	}
+
+	result, err := service.ImportEmployees(rows, false)
+	assert.ErrorIs(t, err, ErrImportValidationFailed)
+	require.NotNil(t, result)
+
+	fieldsByRow := make(map[int][]string)
+	for _, e := range result.Errors {
+		fieldsByRow[e.RowNumber] = append(fieldsByRow[e.RowNumber], e.Field)
+	}
+	assert.Contains(t, fieldsByRow[2], "nik")
+	assert.Contains(t, fieldsByRow[3], "nik")
+	assert.Contains(t, fieldsByRow[4], "role")
+	assert.Contains(t, fieldsByRow[4], "email", "duplicate email within the file")
+	assert.Contains(t, fieldsByRow[5], "phone_number")
+	assert.Contains(t, fieldsByRow[5], "join_date")
+
+	var userCount int64
+	db.Model(&models.User{}).Count(&userCount)
+	assert.Equal(t, int64(1), userCount, "no users should be created when any row is invalid")
+}
+
+func TestEmployeeImportService_DryRunDoesNotWrite(t *testing.T) {
+	db := setupEmployeeImportTestDB(t)
+	service := newTestEmployeeImportService(db)
+
+	rows, err := service.ParseImportFile("karyawan.csv", strings.NewReader(validEmployeeCSV))
+	require.NoError(t, err)
+
+	result, err := service.ImportEmployees(rows, true)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Empty(t, result.Credentials)
+
+	var userCount int64
+	db.Model(&models.User{}).Count(&userCount)
+	assert.Equal(t, int64(0), userCount)
+}
+
+func TestEmployeeImportService_TemplateRoundTrip(t *testing.T) {
+	service := newTestEmployeeImportService(setupEmployeeImportTestDB(t))
+
+	buf, err := service.GenerateImportTemplate()
+	require.NoError(t, err)
+
+	rows, err := service.ParseImportFile("template.xlsx", buf)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "3201010101010001", rows[0].NIK)
+
+	sheet, err := service.GenerateCredentialSheet([]EmployeeImportCredential{{NIK: rows[0].NIK, FullName: rows[0].FullName, Password: "rahasia"}}, "pdf", "tester")
+	require.NoError(t, err)
+	assert.True(t, sheet.Len() > 0)
+}
@@ -33,6 +33,10 @@ func NewEmployeeService(db *gorm.DB, authService *AuthService) *EmployeeService
 
 // CreateEmployee creates a new employee and auto-generates login credentials
 func (s *EmployeeService) CreateEmployee(employee *models.Employee, role string) (*models.User, string, error) {
+	if err := validateRole(s.db, role); err != nil {
+		return nil, "", err
+	}
+
 	// Validate unique NIK
 	var existingEmployee models.Employee
 	if err := s.db.Where("nik = ?", employee.NIK).First(&existingEmployee).Error; err == nil {
@@ -53,15 +57,18 @@ func (s *EmployeeService) CreateEmployee(employee *models.Employee, role string)
 		return nil, "", err
 	}
 
-	// Create user account
+	// Create user account; the initial password must be changed on first login
+	now := time.Now()
 	user := &models.User{
-		NIK:          employee.NIK,
-		Email:        employee.Email,
-		PasswordHash: hashedPassword,
-		FullName:     employee.FullName,
-		PhoneNumber:  employee.PhoneNumber,
-		Role:         role,
-		IsActive:     true,
+		NIK:                employee.NIK,
+		Email:              employee.Email,
+		PasswordHash:       hashedPassword,
+		FullName:           employee.FullName,
+		PhoneNumber:        employee.PhoneNumber,
+		Role:               role,
+		IsActive:           true,
+		MustChangePassword: true,
+		PasswordChangedAt:  &now,
 	}
 
 	// Start transaction
@@ -97,6 +104,10 @@ func (s *EmployeeService) CreateEmployee(employee *models.Employee, role string)
 
 // CreateEmployeeWithPassword creates a new employee with a custom password
 func (s *EmployeeService) CreateEmployeeWithPassword(employee *models.Employee, role string, password string) (*models.User, error) {
+	if err := validateRole(s.db, role); err != nil {
+		return nil, err
+	}
+
 	// Validate unique NIK
 	var existingEmployee models.Employee
 	if err := s.db.Where("nik = ?", employee.NIK).First(&existingEmployee).Error; err == nil {
@@ -114,15 +125,18 @@ func (s *EmployeeService) CreateEmployeeWithPassword(employee *models.Employee,
 		return nil, err
 	}
 
-	// Create user account
+	// Create user account; the initial password must be changed on first login
+	now := time.Now()
 	user := &models.User{
-		NIK:          employee.NIK,
-		Email:        employee.Email,
-		PasswordHash: hashedPassword,
-		FullName:     employee.FullName,
-		PhoneNumber:  employee.PhoneNumber,
-		Role:         role,
-		IsActive:     true,
+		NIK:                employee.NIK,
+		Email:              employee.Email,
+		PasswordHash:       hashedPassword,
+		FullName:           employee.FullName,
+		PhoneNumber:        employee.PhoneNumber,
+		Role:               role,
+		IsActive:           true,
+		MustChangePassword: true,
+		PasswordChangedAt:  &now,
 	}
 
 	// Start transaction
@@ -310,7 +324,13 @@ func (s *EmployeeService) DeactivateEmployee(id uint) error {
 	}
 
 	// Commit transaction
-	return tx.Commit().Error
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	// Existing tokens must stop working immediately
+	_, err = s.authService.Sessions().RevokeAllUserSessions(employee.UserID, SessionRevokedDeactivated, "")
+	return err
 }
 
 // ActivateEmployee activates an employee account
@@ -358,7 +378,8 @@ func (s *EmployeeService) generatePassword() string {
 	return string(password)
 }
 
-// ResetPassword resets an employee's password
+// ResetPassword resets an employee's password to a random one that must be
+// changed on next login
 func (s *EmployeeService) ResetPassword(id uint) (string, error) {
 	employee, err := s.GetEmployeeByID(id)
 	if err != nil {
@@ -368,14 +389,12 @@ func (s *EmployeeService) ResetPassword(id uint) (string, error) {
 	// Generate new password
 	newPassword := s.generatePassword()
 
-	// Hash password
-	hashedPassword, err := s.authService.HashPassword(newPassword)
-	if err != nil {
+	// Store the password and force a change on next login
+	if err := s.authService.SetPassword(s.db, &employee.User, newPassword, true); err != nil {
 		return "", err
 	}
 
-	// Update user password
-	if err := s.db.Model(&models.User{}).Where("id = ?", employee.UserID).Update("password_hash", hashedPassword).Error; err != nil {
+	if _, err := s.authService.Sessions().RevokeAllUserSessions(employee.UserID, SessionRevokedPasswordReset, ""); err != nil {
 		return "", err
 	}
 
@@ -70,8 +70,11 @@ func (s *EPODService) CreateEPOD(epod *models.ElectronicPOD) error {
 		return err
 	}
 
-	// Set completion timestamp
-	epod.CompletedAt = time.Now()
+	// Set completion timestamp; ePODs synced from offline clients keep the
+	// time they were captured, as long as it is not in the future
+	if now := time.Now(); epod.CompletedAt.IsZero() || epod.CompletedAt.After(now) {
+		epod.CompletedAt = now
+	}
 
 	// Create e-POD and update delivery task status in a transaction
 	return s.db.Transaction(func(tx *gorm.DB) error {
@@ -158,10 +161,12 @@ func (s *EPODService) GetEPODByDeliveryRecordID(deliveryRecordID uint) (*models.
 
 	// Find matching delivery task by school_id, driver_id, and date
 	var deliveryTask models.DeliveryTask
-	err := s.db.Where("school_id = ? AND driver_id = ? AND DATE(task_date) = DATE(?)",
+	dayStart, dayEnd := localDayRange(deliveryRecord.DeliveryDate)
+	err := s.db.Where("school_id = ? AND driver_id = ? AND task_date >= ? AND task_date < ?",
 		deliveryRecord.SchoolID,
 		deliveryRecord.DriverID,
-		deliveryRecord.DeliveryDate,
+		dayStart,
+		dayEnd,
 	).First(&deliveryTask).Error
 
 	if err != nil {
@@ -4,6 +4,11 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
 	"time"
 
 	"github.com/jung-kurt/gofpdf"
@@ -37,6 +42,21 @@ type ExportData struct {
 	Metadata    map[string]string   // Additional metadata
 }
 
+// ExportImage is an image attached to a report, e.g. a scanned invoice
+type ExportImage struct {
+	Caption string // Shown above the image
+	Data    []byte // JPG, PNG or GIF content, empty when the file is not available
+}
+
+// ExportReport is a report made of several tables followed by attached images
+type ExportReport struct {
+	Title       string        // Report title
+	DateRange   string        // Date range for the report
+	GeneratedBy string        // User who generated the report
+	Sections    []*ExportData // Tables, each section title is taken from ExportData.Title
+	Images      []ExportImage // Attachments, unreadable files are listed without image
+}
+
 // ExportToPDF exports data to PDF format
 func (s *ExportService) ExportToPDF(data *ExportData) (*bytes.Buffer, error) {
 	if data == nil || len(data.Headers) == 0 {
@@ -329,3 +349,284 @@ func (s *ExportService) ExportToExcelMultiSheet(datasets map[string]*ExportData)
 
 	return &buf, nil
 }
+
+// ExportReportToPDF exports a multi-section report with attached images to PDF
+func (s *ExportService) ExportReportToPDF(report *ExportReport) (*bytes.Buffer, error) {
+	if report == nil || len(report.Sections) == 0 {
+		return nil, ErrInvalidData
+	}
+
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Arial", "I", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Halaman %d dari {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 16)
+	pdf.Cell(0, 10, s.organizationName)
+	pdf.Ln(8)
+	pdf.SetFont("Arial", "B", 14)
+	pdf.Cell(0, 10, report.Title)
+	pdf.Ln(8)
+	if report.DateRange != "" {
+		pdf.SetFont("Arial", "", 10)
+		pdf.Cell(0, 6, fmt.Sprintf("Periode: %s", report.DateRange))
+		pdf.Ln(6)
+	}
+	pdf.SetFont("Arial", "I", 9)
+	pdf.Cell(0, 5, fmt.Sprintf("Dibuat oleh: %s | Tanggal: %s", report.GeneratedBy, time.Now().Format("02/01/2006 15:04")))
+	pdf.Ln(8)
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	leftMargin, _, rightMargin, _ := pdf.GetMargins()
+	availableWidth := pageWidth - leftMargin - rightMargin
+
+	for _, section := range report.Sections {
+		if section == nil || len(section.Headers) == 0 {
+			continue
+		}
+		pdf.Ln(4)
+		pdf.SetFont("Arial", "B", 12)
+		pdf.Cell(0, 8, section.Title)
+		pdf.Ln(8)
+
+		colWidth := availableWidth / float64(len(section.Headers))
+		pdf.SetFont("Arial", "B", 9)
+		pdf.SetFillColor(200, 200, 200)
+		for _, header := range section.Headers {
+			pdf.CellFormat(colWidth, 7, header, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+
+		pdf.SetFont("Arial", "", 8)
+		for i, row := range section.Rows {
+			if i%2 == 0 {
+				pdf.SetFillColor(245, 245, 245)
+			} else {
+				pdf.SetFillColor(255, 255, 255)
+			}
+			for j, cell := range row {
+				if j >= len(section.Headers) {
+					break
+				}
+				pdf.CellFormat(colWidth, 6, truncateToWidth(pdf, cell, colWidth-2), "1", 0, "L", true, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+	}
+
+	if len(report.Images) > 0 {
+		pdf.AddPage()
+		pdf.SetFont("Arial", "B", 12)
+		pdf.Cell(0, 8, "Lampiran Bukti")
+		pdf.Ln(10)
+
+		for i, attachment := range report.Images {
+			imageType, ok := exportImageType(attachment.Data)
+			if !ok {
+				pdf.SetFont("Arial", "", 9)
+				pdf.Cell(0, 6, fmt.Sprintf("%s (berkas bukti tidak tersedia)", attachment.Caption))
+				pdf.Ln(7)
+				continue
+			}
+
+			name := fmt.Sprintf("lampiran-%d", i)
+			info := pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(attachment.Data))
+			if info == nil || pdf.Err() {
+				return nil, fmt.Errorf("%w: %v", ErrExportFailed, pdf.Error())
+			}
+
+			// Fit the image within 120 mm height and the page width
+			height := 120.0
+			width := info.Width() * height / info.Height()
+			if width > availableWidth {
+				width = availableWidth
+				height = info.Height() * width / info.Width()
+			}
+			if pdf.GetY()+height+10 > pageHeight-15 {
+				pdf.AddPage()
+			}
+
+			pdf.SetFont("Arial", "B", 9)
+			pdf.Cell(0, 6, attachment.Caption)
+			pdf.Ln(7)
+			pdf.ImageOptions(name, leftMargin, pdf.GetY(), width, height, false, gofpdf.ImageOptions{ImageType: imageType}, 0, "")
+			pdf.SetY(pdf.GetY() + height + 5)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExportFailed, err)
+	}
+	return &buf, nil
+}
+
+// ExportReportToExcel exports a multi-section report to Excel, one sheet per
+// section and the attached images on a "Lampiran" sheet
+func (s *ExportService) ExportReportToExcel(report *ExportReport) (*bytes.Buffer, error) {
+	if report == nil || len(report.Sections) == 0 {
+		return nil, ErrInvalidData
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	titleStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 12}})
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#D3D3D3"}, Pattern: 1},
+		Border: []excelize.Border{
+			{Type: "left", Color: "000000", Style: 1},
+			{Type: "top", Color: "000000", Style: 1},
+			{Type: "bottom", Color: "000000", Style: 1},
+			{Type: "right", Color: "000000", Style: 1},
+		},
+	})
+
+	sheetNames := make(map[string]bool)
+	firstSheet := true
+	for _, section := range report.Sections {
+		if section == nil || len(section.Headers) == 0 {
+			continue
+		}
+		sheetName := uniqueSheetName(section.Title, sheetNames)
+		if firstSheet {
+			f.SetSheetName("Sheet1", sheetName)
+			firstSheet = false
+		} else if _, err := f.NewSheet(sheetName); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrExportFailed, err)
+		}
+
+		f.SetCellValue(sheetName, "A1", s.organizationName)
+		f.SetCellValue(sheetName, "A2", report.Title+" - "+section.Title)
+		f.SetCellStyle(sheetName, "A1", "A2", titleStyle)
+		if report.DateRange != "" {
+			f.SetCellValue(sheetName, "A3", fmt.Sprintf("Periode: %s", report.DateRange))
+		}
+
+		currentRow := 5
+		for i, header := range section.Headers {
+			col, _ := excelize.ColumnNumberToName(i + 1)
+			cell := fmt.Sprintf("%s%d", col, currentRow)
+			f.SetColWidth(sheetName, col, col, 20)
+			f.SetCellValue(sheetName, cell, header)
+			f.SetCellStyle(sheetName, cell, cell, headerStyle)
+		}
+		for _, row := range section.Rows {
+			currentRow++
+			for colIdx, value := range row {
+				if colIdx >= len(section.Headers) {
+					break
+				}
+				col, _ := excelize.ColumnNumberToName(colIdx + 1)
+				f.SetCellValue(sheetName, fmt.Sprintf("%s%d", col, currentRow), value)
+			}
+		}
+	}
+	if firstSheet {
+		return nil, ErrInvalidData
+	}
+
+	if len(report.Images) > 0 {
+		sheetName := uniqueSheetName("Lampiran", sheetNames)
+		if _, err := f.NewSheet(sheetName); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrExportFailed, err)
+		}
+		f.SetColWidth(sheetName, "A", "A", 40)
+		f.SetColWidth(sheetName, "B", "B", 60)
+		f.SetCellValue(sheetName, "A1", "Keterangan")
+		f.SetCellValue(sheetName, "B1", "Bukti")
+		f.SetCellStyle(sheetName, "A1", "B1", headerStyle)
+
+		for i, attachment := range report.Images {
+			row := i + 2
+			f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), attachment.Caption)
+			imageType, ok := exportImageType(attachment.Data)
+			if !ok {
+				f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), "Berkas bukti tidak tersedia")
+				continue
+			}
+			f.SetRowHeight(sheetName, row, 240)
+			if err := f.AddPictureFromBytes(sheetName, fmt.Sprintf("B%d", row), &excelize.Picture{
+				Extension: "." + strings.ToLower(imageType),
+				File:      attachment.Data,
+				Format: &excelize.GraphicOptions{
+					AltText:         attachment.Caption,
+					AutoFit:         true,
+					LockAspectRatio: true,
+				},
+			}); err != nil {
+				f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), "Berkas bukti tidak dapat dilampirkan")
+			}
+		}
+	}
+	f.SetActiveSheet(0)
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExportFailed, err)
+	}
+	return &buf, nil
+}
+
+// exportImageType returns the gofpdf image type of an attachment. Missing
+// files and data that is not a decodable image are rejected.
+func exportImageType(data []byte) (string, bool) {
+	if len(data) == 0 {
+		return "", false
+	}
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", false
+	}
+	switch format {
+	case "jpeg":
+		return "JPG", true
+	case "png":
+		return "PNG", true
+	case "gif":
+		return "GIF", true
+	}
+	return "", false
+}
+
+// truncateToWidth shortens a cell text so it fits the column width
+func truncateToWidth(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// uniqueSheetName returns a valid, unused Excel sheet name (max 31 characters)
+func uniqueSheetName(title string, used map[string]bool) string {
+	name := strings.NewReplacer(":", " ", "\\", " ", "/", " ", "?", " ", "*", " ", "[", " ", "]", " ").Replace(title)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Data"
+	}
+	if len([]rune(name)) > 31 {
+		name = string([]rune(name)[:31])
+	}
+
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		base := []rune(name)
+		if len(base)+len(suffix) > 31 {
+			base = base[:31-len(suffix)]
+		}
+		candidate = string(base) + suffix
+	}
+	used[candidate] = true
+	return candidate
+}
@@ -14,19 +14,20 @@ var (
 	ErrReportGeneration = errors.New("gagal membuat laporan")
 )
 
-// FinancialReportService handles financial reporting business logic
+// FinancialReportService handles financial reporting business logic.
+// Reports are built on the general ledger rather than raw cash flow rows.
 type FinancialReportService struct {
-	db              *gorm.DB
-	cashFlowService *CashFlowService
-	assetService    *AssetService
+	db            *gorm.DB
+	ledgerService *LedgerService
+	assetService  *AssetService
 }
 
 // NewFinancialReportService creates a new financial report service
 func NewFinancialReportService(db *gorm.DB) *FinancialReportService {
 	return &FinancialReportService{
-		db:              db,
-		cashFlowService: NewCashFlowService(db),
-		assetService:    NewAssetService(db),
+		db:            db,
+		ledgerService: NewLedgerService(db),
+		assetService:  NewAssetService(db),
 	}
 }
 
@@ -37,6 +38,8 @@ type FinancialReport struct {
 	EndDate           time.Time                 `json:"end_date"`
 	GeneratedAt       time.Time                 `json:"generated_at"`
 	CashFlowSummary   *CashFlowSummary          `json:"cash_flow_summary"`
+	IncomeStatement   *IncomeStatement          `json:"income_statement"`
+	BalanceSheet      *BalanceSheet             `json:"balance_sheet"`
 	BudgetComparison  *BudgetComparison         `json:"budget_comparison,omitempty"`
 	AssetSummary      *AssetSummary             `json:"asset_summary,omitempty"`
 	CategoryBreakdown []CategoryBreakdown       `json:"category_breakdown"`
@@ -46,44 +49,44 @@ type FinancialReport struct {
 // BudgetComparison represents budget vs actual comparison
 type BudgetComparison struct {
 	Categories []BudgetCategoryComparison `json:"categories"`
-	TotalBudget float64                   `json:"total_budget"`
-	TotalActual float64                   `json:"total_actual"`
-	Variance    float64                   `json:"variance"`
+	TotalBudget models.Money              `json:"total_budget"`
+	TotalActual models.Money              `json:"total_actual"`
+	Variance    models.Money              `json:"variance"`
 	VariancePercent float64               `json:"variance_percent"`
 }
 
 // BudgetCategoryComparison represents budget comparison for a category
 type BudgetCategoryComparison struct {
-	Category        string  `json:"category"`
-	Budget          float64 `json:"budget"`
-	Actual          float64 `json:"actual"`
-	Variance        float64 `json:"variance"`
-	VariancePercent float64 `json:"variance_percent"`
+	Category        string       `json:"category"`
+	Budget          models.Money `json:"budget"`
+	Actual          models.Money `json:"actual"`
+	Variance        models.Money `json:"variance"`
+	VariancePercent float64      `json:"variance_percent"`
 }
 
 // AssetSummary represents asset summary in financial report
 type AssetSummary struct {
-	TotalAssets       int     `json:"total_assets"`
-	TotalPurchaseValue float64 `json:"total_purchase_value"`
-	TotalCurrentValue  float64 `json:"total_current_value"`
-	TotalDepreciation  float64 `json:"total_depreciation"`
+	TotalAssets       int          `json:"total_assets"`
+	TotalPurchaseValue models.Money `json:"total_purchase_value"`
+	TotalCurrentValue  models.Money `json:"total_current_value"`
+	TotalDepreciation  models.Money `json:"total_depreciation"`
 }
 
 // CategoryBreakdown represents expense breakdown by category
 type CategoryBreakdown struct {
-	Category    string  `json:"category"`
-	Amount      float64 `json:"amount"`
-	Percentage  float64 `json:"percentage"`
-	Count       int     `json:"count"`
+	Category    string       `json:"category"`
+	Amount      models.Money `json:"amount"`
+	Percentage  float64      `json:"percentage"`
+	Count       int          `json:"count"`
 }
 
 // MonthlyData represents monthly financial data
 type MonthlyData struct {
-	Month       string  `json:"month"`
-	Year        int     `json:"year"`
-	Income      float64 `json:"income"`
-	Expense     float64 `json:"expense"`
-	NetCashFlow float64 `json:"net_cash_flow"`
+	Month       string       `json:"month"`
+	Year        int          `json:"year"`
+	Income      models.Money `json:"income"`
+	Expense     models.Money `json:"expense"`
+	NetCashFlow models.Money `json:"net_cash_flow"`
 }
 
 // GenerateFinancialReport generates a comprehensive financial report
@@ -102,13 +105,25 @@ func (s *FinancialReportService) GenerateFinancialReport(startDate, endDate time
 	// Set report period description
 	report.ReportPeriod = s.formatReportPeriod(startDate, endDate)
 
-	// Get cash flow summary
-	cashFlowSummary, err := s.cashFlowService.GetCashFlowSummary(startDate, endDate)
+	// Get income statement and summary from the ledger
+	incomeStatement, err := s.ledgerService.GetIncomeStatement(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	report.IncomeStatement = incomeStatement
+
+	cashFlowSummary, err := s.ledgerSummary(startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 	report.CashFlowSummary = cashFlowSummary
 
+	balanceSheet, err := s.ledgerService.GetBalanceSheet(endDate)
+	if err != nil {
+		return nil, err
+	}
+	report.BalanceSheet = balanceSheet
+
 	// Generate category breakdown
 	categoryBreakdown, err := s.generateCategoryBreakdown(startDate, endDate)
 	if err != nil {
@@ -145,25 +160,13 @@ func (s *FinancialReportService) GenerateFinancialReport(startDate, endDate time
 
 // generateCategoryBreakdown generates expense breakdown by category
 func (s *FinancialReportService) generateCategoryBreakdown(startDate, endDate time.Time) ([]CategoryBreakdown, error) {
-	var results []struct {
-		Category string
-		Amount   float64
-		Count    int64
-	}
-
-	err := s.db.Model(&models.CashFlowEntry{}).
-		Select("category, SUM(amount) as amount, COUNT(*) as count").
-		Where("type = ? AND date BETWEEN ? AND ?", "expense", startDate, endDate).
-		Group("category").
-		Order("amount DESC").
-		Scan(&results).Error
-
+	results, err := s.ledgerService.GetExpensesByCategory(startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
 	// Calculate total for percentage
-	var total float64
+	var total models.Money
 	for _, r := range results {
 		total += r.Amount
 	}
@@ -172,14 +175,14 @@ func (s *FinancialReportService) generateCategoryBreakdown(startDate, endDate ti
 	for i, r := range results {
 		percentage := 0.0
 		if total > 0 {
-			percentage = (r.Amount / total) * 100
+			percentage = r.Amount.Ratio(total) * 100
 		}
 
 		breakdown[i] = CategoryBreakdown{
 			Category:   r.Category,
 			Amount:     r.Amount,
 			Percentage: percentage,
-			Count:      int(r.Count),
+			Count:      r.Count,
 		}
 	}
 
@@ -192,9 +195,9 @@ func (s *FinancialReportService) generateBudgetComparison(startDate, endDate tim
 		Categories: make([]BudgetCategoryComparison, 0),
 	}
 
-	// Get budget targets for the period
+	// Get monthly budget targets for the period
 	var budgetTargets []models.BudgetTarget
-	err := s.db.Where("year = ? AND month >= ? AND month <= ?",
+	err := s.db.Where("year = ? AND month >= ? AND month <= ? AND month > 0",
 		startDate.Year(),
 		int(startDate.Month()),
 		int(endDate.Month())).
@@ -205,22 +208,20 @@ func (s *FinancialReportService) generateBudgetComparison(startDate, endDate tim
 	}
 
 	// Aggregate budget by category
-	budgetByCategory := make(map[string]float64)
+	budgetByCategory := make(map[string]models.Money)
 	for _, target := range budgetTargets {
 		budgetByCategory[target.Category] += target.Target
 	}
 
-	// Get actual expenses by category
-	actualByCategory := make(map[string]float64)
-	var cashFlowEntries []models.CashFlowEntry
-	err = s.db.Where("type = ? AND date BETWEEN ? AND ?", "expense", startDate, endDate).
-		Find(&cashFlowEntries).Error
+	// Get actual expenses by category from the ledger
+	actualByCategory := make(map[string]models.Money)
+	expenses, err := s.ledgerService.GetExpensesByCategory(startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, entry := range cashFlowEntries {
-		actualByCategory[entry.Category] += entry.Amount
+	for _, expense := range expenses {
+		actualByCategory[expense.Category] += expense.Amount
 	}
 
 	// Build comparison for each category
@@ -229,7 +230,7 @@ func (s *FinancialReportService) generateBudgetComparison(startDate, endDate tim
 		variance := budget - actual
 		variancePercent := 0.0
 		if budget > 0 {
-			variancePercent = (variance / budget) * 100
+			variancePercent = variance.Ratio(budget) * 100
 		}
 
 		comparison.Categories = append(comparison.Categories, BudgetCategoryComparison{
@@ -246,7 +247,7 @@ func (s *FinancialReportService) generateBudgetComparison(startDate, endDate tim
 
 	comparison.Variance = comparison.TotalBudget - comparison.TotalActual
 	if comparison.TotalBudget > 0 {
-		comparison.VariancePercent = (comparison.Variance / comparison.TotalBudget) * 100
+		comparison.VariancePercent = comparison.Variance.Ratio(comparison.TotalBudget) * 100
 	}
 
 	return comparison, nil
@@ -279,8 +280,8 @@ func (s *FinancialReportService) generateMonthlyTrend(startDate, endDate time.Ti
 		monthStart := currentDate
 		monthEnd := monthStart.AddDate(0, 1, -1).Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 
-		// Get cash flow summary for the month
-		summary, err := s.cashFlowService.GetCashFlowSummary(monthStart, monthEnd)
+		// Get ledger summary for the month
+		summary, err := s.ledgerSummary(monthStart, monthEnd)
 		if err != nil {
 			return nil, err
 		}
@@ -302,6 +303,32 @@ func (s *FinancialReportService) generateMonthlyTrend(startDate, endDate time.Ti
 	return trend, nil
 }
 
+// ledgerSummary summarises revenue and expense of the ledger in the cash flow
+// summary shape used by the existing reports
+func (s *FinancialReportService) ledgerSummary(startDate, endDate time.Time) (*CashFlowSummary, error) {
+	statement, err := s.ledgerService.GetIncomeStatement(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.ledgerService.GetExpensesByCategory(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &CashFlowSummary{
+		TotalIncome:  statement.TotalRevenue,
+		TotalExpense: statement.TotalExpense,
+		NetCashFlow:  statement.Surplus,
+		ByCategory:   make(map[string]models.Money),
+		StartDate:    startDate,
+		EndDate:      endDate,
+	}
+	for _, expense := range expenses {
+		summary.ByCategory[expense.Category] = expense.Amount
+	}
+	return summary, nil
+}
+
 // formatReportPeriod formats the report period description
 func (s *FinancialReportService) formatReportPeriod(startDate, endDate time.Time) string {
 	if startDate.Year() == endDate.Year() && startDate.Month() == endDate.Month() {
@@ -393,7 +420,7 @@ func (s *FinancialReportService) GetDailyCashFlow(date time.Time) (*CashFlowSumm
 	startDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 	endDate := startDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 	
-	return s.cashFlowService.GetCashFlowSummary(startDate, endDate)
+	return s.ledgerSummary(startDate, endDate)
 }
 
 // GetWeeklyCashFlow retrieves weekly cash flow summary
@@ -410,7 +437,7 @@ func (s *FinancialReportService) GetWeeklyCashFlow(year int, week int) (*CashFlo
 	startDate = startDate.AddDate(0, 0, (week-1)*7)
 	endDate := startDate.AddDate(0, 0, 6).Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 	
-	return s.cashFlowService.GetCashFlowSummary(startDate, endDate)
+	return s.ledgerSummary(startDate, endDate)
 }
 
 // GetQuarterlyCashFlow retrieves quarterly cash flow summary
@@ -423,7 +450,7 @@ func (s *FinancialReportService) GetQuarterlyCashFlow(year int, quarter int) (*C
 	startDate := time.Date(year, time.Month(startMonth), 1, 0, 0, 0, 0, time.UTC)
 	endDate := startDate.AddDate(0, 3, -1).Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 	
-	return s.cashFlowService.GetCashFlowSummary(startDate, endDate)
+	return s.ledgerSummary(startDate, endDate)
 }
 
 // GetCustomPeriodReport generates a report for a custom date range
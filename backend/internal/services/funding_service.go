@@ -0,0 +1,785 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/storage"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFundingTrancheNotFound  = errors.New("tranche dana tidak ditemukan")
+	ErrInvalidFundingPeriod    = errors.New("periode tranche dana tidak valid")
+	ErrInvalidFundingAmount    = errors.New("jumlah dana harus lebih dari nol")
+	ErrInvalidTargetPortions   = errors.New("target porsi tidak boleh negatif")
+	ErrDuplicateTrancheSchool  = errors.New("sekolah tercantum lebih dari sekali dalam tranche")
+	ErrExpenseNotAssignable    = errors.New("hanya pengeluaran yang dapat dialokasikan ke tranche dana")
+	ErrExpenseNotInTranche     = errors.New("pengeluaran tidak dialokasikan ke tranche ini")
+	ErrUnsupportedExportFormat = errors.New("format export tidak didukung")
+)
+
+// servedDeliveryStage is the first delivery stage at which portions count as
+// served (sudah_diterima_pihak_sekolah)
+const servedDeliveryStage = 9
+
+// LPJCategoryLine is the spending of a cash flow category in an LPJ
+type LPJCategoryLine struct {
+	Category     string       `json:"category"`
+	Transactions int          `json:"transactions"`
+	Amount       models.Money `json:"amount"`
+	Percentage   float64      `json:"percentage"` // of total spending
+}
+
+// LPJSpendingLine is a single expense accounted to a tranche
+type LPJSpendingLine struct {
+	EntryID       uint         `json:"entry_id"`
+	Date          time.Time    `json:"date"`
+	TransactionID string       `json:"transaction_id"`
+	JournalNumber string       `json:"journal_number"`
+	Category      string       `json:"category"`
+	Description   string       `json:"description"`
+	Reference     string       `json:"reference"`
+	Amount        models.Money `json:"amount"`
+	Assigned      bool         `json:"assigned"` // explicitly assigned, otherwise included by period
+}
+
+// LPJSchoolLine compares the target and served portions of a school
+type LPJSchoolLine struct {
+	SchoolID       uint    `json:"school_id"`
+	SchoolName     string  `json:"school_name"`
+	TargetPortions int     `json:"target_portions"`
+	ServedPortions int     `json:"served_portions"`
+	Deliveries     int     `json:"deliveries"`
+	Achievement    float64 `json:"achievement"` // served / target in percent
+}
+
+// LPJEvidence is a goods receipt supporting raw material spending
+type LPJEvidence struct {
+	GRNID         uint         `json:"grn_id"`
+	GRNNumber     string       `json:"grn_number"`
+	ReceiptDate   time.Time    `json:"receipt_date"`
+	SupplierName  string       `json:"supplier_name"`
+	InvoiceNumber string       `json:"invoice_number"`
+	Amount        models.Money `json:"amount"`
+	InvoicePhoto  string       `json:"invoice_photo"`
+}
+
+// LPJReport is the accountability report (Laporan Pertanggungjawaban) of a funding tranche
+type LPJReport struct {
+	Tranche          models.FundingTranche `json:"tranche"`
+	FundsReceived    models.Money          `json:"funds_received"`
+	TotalSpending    models.Money          `json:"total_spending"`
+	RemainingBalance models.Money          `json:"remaining_balance"`
+	AbsorptionRate   float64               `json:"absorption_rate"`
+	TargetPortions   int                   `json:"target_portions"`
+	ServedPortions   int                   `json:"served_portions"`
+	CostPerPortion   models.Money          `json:"cost_per_portion"`
+	ByCategory       []LPJCategoryLine     `json:"by_category"`
+	Spending         []LPJSpendingLine     `json:"spending"`
+	Schools          []LPJSchoolLine       `json:"schools"`
+	Evidence         []LPJEvidence         `json:"evidence"`
+	GeneratedAt      time.Time             `json:"generated_at"`
+}
+
+// FundingService manages government funding tranches and their LPJ
+type FundingService struct {
+	db              *gorm.DB
+	cashFlowService *CashFlowService
+	ledgerService   *LedgerService
+	exportService   *ExportService
+	files           storage.BlobStore
+}
+
+// NewFundingService creates a new funding service
+func NewFundingService(db *gorm.DB, cashFlowService *CashFlowService) *FundingService {
+	return &FundingService{
+		db:              db,
+		cashFlowService: cashFlowService,
+		ledgerService:   NewLedgerService(db),
+		exportService:   NewExportService("SPPG - Satuan Pelayanan Pemenuhan Gizi"),
+		files:           storage.NewLocalStore("uploads", nil),
+	}
+}
+
+// WithBlobStore sets the store the invoice photos of the LPJ are read from
+func (s *FundingService) WithBlobStore(files storage.BlobStore) *FundingService {
+	s.files = files
+	return s
+}
+
+// CreateTranche records a received tranche together with its income entry
+func (s *FundingService) CreateTranche(tranche *models.FundingTranche, userID uint) error {
+	if err := s.validateTranche(tranche); err != nil {
+		return err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		trancheNumber, err := s.generateTrancheNumber(tx, tranche.ReceivedDate)
+		if err != nil {
+			return err
+		}
+
+		income := &models.CashFlowEntry{
+			Date:        tranche.ReceivedDate,
+			Category:    "operasional",
+			Type:        "income",
+			Amount:      tranche.Amount,
+			Description: trancheIncomeDescription(tranche),
+			Reference:   trancheNumber,
+			CreatedBy:   userID,
+		}
+		if err := s.cashFlowService.CreateCashFlowEntryWithTx(tx, income); err != nil {
+			return err
+		}
+
+		tranche.ID = 0
+		tranche.TrancheNumber = trancheNumber
+		tranche.CashFlowEntryID = &income.ID
+		tranche.CreatedBy = userID
+		return tx.Create(tranche).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "funding_tranche", tranche.TrancheNumber, nil, tranche, "")
+	return nil
+}
+
+// UpdateTranche updates a tranche, replaces its schools and corrects its income entry
+func (s *FundingService) UpdateTranche(id uint, updates *models.FundingTranche, userID uint) (*models.FundingTranche, error) {
+	if err := s.validateTranche(updates); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.GetTranche(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.FundingTranche{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"source":           updates.Source,
+			"reference_number": updates.ReferenceNumber,
+			"received_date":    updates.ReceivedDate,
+			"amount":           updates.Amount,
+			"period_start":     updates.PeriodStart,
+			"period_end":       updates.PeriodEnd,
+			"target_portions":  updates.TargetPortions,
+			"description":      updates.Description,
+			"updated_at":       time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("tranche_id = ?", id).Delete(&models.FundingTrancheSchool{}).Error; err != nil {
+			return err
+		}
+		for _, school := range updates.Schools {
+			school.ID = 0
+			school.TrancheID = id
+			if err := tx.Create(&school).Error; err != nil {
+				return err
+			}
+		}
+
+		if existing.CashFlowEntryID == nil {
+			return nil
+		}
+		var income models.CashFlowEntry
+		if err := tx.First(&income, *existing.CashFlowEntryID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		updates.TrancheNumber = existing.TrancheNumber
+		income.Date = updates.ReceivedDate
+		income.Amount = updates.Amount
+		income.Description = trancheIncomeDescription(updates)
+		if err := tx.Model(&models.CashFlowEntry{}).Where("id = ?", income.ID).Updates(map[string]interface{}{
+			"date":        income.Date,
+			"amount":      income.Amount,
+			"description": income.Description,
+		}).Error; err != nil {
+			return err
+		}
+		return s.ledgerService.RepostCashFlowEntryWithTx(tx, &income)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.GetTranche(id)
+	if err != nil {
+		return nil, err
+	}
+	NewAuditTrailService(s.db).RecordAction(userID, "update", "funding_tranche", existing.TrancheNumber, existing, updated, "")
+	return updated, nil
+}
+
+// DeleteTranche deletes a tranche, releases its assigned expenses and cancels its income entry
+func (s *FundingService) DeleteTranche(id uint, userID uint) error {
+	existing, err := s.GetTranche(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.CashFlowEntry{}).Where("funding_tranche_id = ?", id).
+			Update("funding_tranche_id", nil).Error; err != nil {
+			return err
+		}
+
+		if existing.CashFlowEntryID != nil {
+			var income models.CashFlowEntry
+			err := tx.First(&income, *existing.CashFlowEntryID).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err == nil {
+				if err := s.ledgerService.ReverseSourceWithTx(tx, JournalSourceCashFlow, income.TransactionID,
+					"Penghapusan tranche dana "+existing.TrancheNumber, userID); err != nil {
+					return err
+				}
+				if err := tx.Delete(&income).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := tx.Where("tranche_id = ?", id).Delete(&models.FundingTrancheSchool{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.FundingTranche{}, id).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "delete", "funding_tranche", existing.TrancheNumber, existing, nil, "")
+	return nil
+}
+
+// GetTranche retrieves a tranche with its schools
+func (s *FundingService) GetTranche(id uint) (*models.FundingTranche, error) {
+	var tranche models.FundingTranche
+	if err := s.db.Preload("Schools.School").First(&tranche, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFundingTrancheNotFound
+		}
+		return nil, err
+	}
+	return &tranche, nil
+}
+
+// GetTranches retrieves tranches, optionally only those whose period overlaps a year
+func (s *FundingService) GetTranches(year int) ([]models.FundingTranche, error) {
+	query := s.db.Preload("Schools.School")
+	if year > 0 {
+		yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.Local)
+		query = query.Where("period_start < ? AND period_end >= ?", yearStart.AddDate(1, 0, 0), yearStart)
+	}
+
+	var tranches []models.FundingTranche
+	err := query.Order("period_start DESC, id DESC").Find(&tranches).Error
+	return tranches, err
+}
+
+// AssignExpenses accounts expenses to a tranche regardless of their date
+func (s *FundingService) AssignExpenses(id uint, entryIDs []uint, userID uint) (int, error) {
+	tranche, err := s.GetTranche(id)
+	if err != nil {
+		return 0, err
+	}
+
+	var entries []models.CashFlowEntry
+	if err := s.db.Where("id IN ?", entryIDs).Find(&entries).Error; err != nil {
+		return 0, err
+	}
+	if len(entries) != len(uniqueIDs(entryIDs)) {
+		return 0, ErrCashFlowNotFound
+	}
+	for _, entry := range entries {
+		if entry.Type != "expense" {
+			return 0, ErrExpenseNotAssignable
+		}
+	}
+
+	result := s.db.Model(&models.CashFlowEntry{}).Where("id IN ?", entryIDs).Update("funding_tranche_id", id)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "assign", "funding_tranche", tranche.TrancheNumber, nil, map[string]interface{}{"entry_ids": entryIDs}, "")
+	return int(result.RowsAffected), nil
+}
+
+// UnassignExpense removes the explicit tranche of an expense
+func (s *FundingService) UnassignExpense(id, entryID uint, userID uint) error {
+	tranche, err := s.GetTranche(id)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.CashFlowEntry{}).
+		Where("id = ? AND funding_tranche_id = ?", entryID, id).
+		Update("funding_tranche_id", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrExpenseNotInTranche
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "unassign", "funding_tranche", tranche.TrancheNumber, map[string]interface{}{"entry_id": entryID}, nil, "")
+	return nil
+}
+
+// GetLPJ builds the accountability report of a tranche: spending accounted to
+// the tranche by category with journal references, served portions per school
+// from delivery records, and goods receipts as evidence
+func (s *FundingService) GetLPJ(id uint) (*LPJReport, error) {
+	tranche, err := s.GetTranche(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LPJReport{
+		Tranche:        *tranche,
+		FundsReceived:  tranche.Amount,
+		TargetPortions: tranche.TargetPortions,
+		ByCategory:     []LPJCategoryLine{},
+		Spending:       []LPJSpendingLine{},
+		Schools:        []LPJSchoolLine{},
+		Evidence:       []LPJEvidence{},
+		GeneratedAt:    time.Now(),
+	}
+
+	// Spending: explicitly assigned expenses and unassigned expenses in the period
+	periodEnd := nextDay(tranche.PeriodEnd)
+	var entries []models.CashFlowEntry
+	if err := s.db.Where("type = ?", "expense").
+		Where("funding_tranche_id = ? OR (funding_tranche_id IS NULL AND date >= ? AND date < ?)",
+			tranche.ID, startOfDay(tranche.PeriodStart), periodEnd).
+		Order("date ASC, id ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	transactionIDs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		transactionIDs = append(transactionIDs, entry.TransactionID)
+	}
+	journalNumbers := make(map[string]string)
+	if len(transactionIDs) > 0 {
+		var journals []models.JournalEntry
+		if err := s.db.Where("source_type = ? AND status = ? AND source_ref IN ?", JournalSourceCashFlow, JournalStatusPosted, transactionIDs).
+			Find(&journals).Error; err != nil {
+			return nil, err
+		}
+		for _, journal := range journals {
+			journalNumbers[journal.SourceRef] = journal.EntryNumber
+		}
+	}
+
+	categories := make(map[string]*LPJCategoryLine)
+	grnAmounts := make(map[string]models.Money)
+	for _, entry := range entries {
+		report.Spending = append(report.Spending, LPJSpendingLine{
+			EntryID:       entry.ID,
+			Date:          entry.Date,
+			TransactionID: entry.TransactionID,
+			JournalNumber: journalNumbers[entry.TransactionID],
+			Category:      entry.Category,
+			Description:   entry.Description,
+			Reference:     entry.Reference,
+			Amount:        entry.Amount,
+			Assigned:      entry.FundingTrancheID != nil,
+		})
+		report.TotalSpending += entry.Amount
+
+		line, ok := categories[entry.Category]
+		if !ok {
+			line = &LPJCategoryLine{Category: entry.Category}
+			categories[entry.Category] = line
+		}
+		line.Transactions++
+		line.Amount += entry.Amount
+
+		if entry.Category == "bahan_baku" && entry.Reference != "" {
+			grnAmounts[entry.Reference] += entry.Amount
+		}
+	}
+
+	report.RemainingBalance = report.FundsReceived - report.TotalSpending
+	if report.FundsReceived > 0 {
+		report.AbsorptionRate = roundPercent(report.TotalSpending.Ratio(report.FundsReceived) * 100)
+	}
+	for _, line := range categories {
+		if report.TotalSpending > 0 {
+			line.Percentage = roundPercent(line.Amount.Ratio(report.TotalSpending) * 100)
+		}
+		report.ByCategory = append(report.ByCategory, *line)
+	}
+	sort.SliceStable(report.ByCategory, func(i, j int) bool {
+		return report.ByCategory[i].Amount > report.ByCategory[j].Amount
+	})
+
+	if err := s.fillServedPortions(report, tranche, periodEnd); err != nil {
+		return nil, err
+	}
+	if err := s.fillEvidence(report, grnAmounts); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ExportLPJ exports the LPJ of a tranche to PDF or Excel through the export service
+func (s *FundingService) ExportLPJ(id uint, format ExportFormat, userID uint) ([]byte, string, error) {
+	if format != ExportFormatPDF && format != ExportFormatExcel {
+		return nil, "", ErrUnsupportedExportFormat
+	}
+
+	report, err := s.GetLPJ(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	generatedBy := "System"
+	var user models.User
+	if err := s.db.Select("full_name").First(&user, userID).Error; err == nil && user.FullName != "" {
+		generatedBy = user.FullName
+	}
+
+	exportReport := s.buildLPJExport(report, generatedBy)
+	filename := fmt.Sprintf("lpj_%s_%s", strings.ToLower(report.Tranche.TrancheNumber), time.Now().Format("20060102_150405"))
+
+	var data []byte
+	if format == ExportFormatExcel {
+		buf, err := s.exportService.ExportReportToExcel(exportReport)
+		if err != nil {
+			return nil, "", err
+		}
+		data, filename = buf.Bytes(), filename+".xlsx"
+	} else {
+		buf, err := s.exportService.ExportReportToPDF(exportReport)
+		if err != nil {
+			return nil, "", err
+		}
+		data, filename = buf.Bytes(), filename+".pdf"
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "export", "funding_lpj", report.Tranche.TrancheNumber, nil, string(format), "")
+	return data, filename, nil
+}
+
+// buildLPJExport lays out the LPJ as export sections with the invoice photos attached
+func (s *FundingService) buildLPJExport(report *LPJReport, generatedBy string) *ExportReport {
+	tranche := report.Tranche
+	summary := &ExportData{
+		Title:   "Ringkasan",
+		Headers: []string{"Uraian", "Nilai"},
+		Rows: [][]string{
+			{"Nomor Tranche", tranche.TrancheNumber},
+			{"Sumber Dana", tranche.Source},
+			{"Nomor Referensi", tranche.ReferenceNumber},
+			{"Tanggal Diterima", tranche.ReceivedDate.Format("02/01/2006")},
+			{"Dana Diterima", formatRupiah(report.FundsReceived)},
+			{"Total Belanja", formatRupiah(report.TotalSpending)},
+			{"Sisa Dana", formatRupiah(report.RemainingBalance)},
+			{"Penyerapan", fmt.Sprintf("%.2f%%", report.AbsorptionRate)},
+			{"Target Porsi", strconv.Itoa(report.TargetPortions)},
+			{"Porsi Tersalurkan", strconv.Itoa(report.ServedPortions)},
+			{"Biaya per Porsi", formatRupiah(report.CostPerPortion)},
+		},
+	}
+
+	byCategory := &ExportData{Title: "Belanja per Kategori", Headers: []string{"Kategori", "Jumlah Transaksi", "Total", "Persentase"}}
+	for _, line := range report.ByCategory {
+		byCategory.Rows = append(byCategory.Rows, []string{
+			line.Category, strconv.Itoa(line.Transactions), formatRupiah(line.Amount), fmt.Sprintf("%.2f%%", line.Percentage),
+		})
+	}
+
+	spending := &ExportData{Title: "Rincian Belanja", Headers: []string{"Tanggal", "No. Transaksi", "No. Jurnal", "Kategori", "Uraian", "Referensi", "Jumlah"}}
+	for _, line := range report.Spending {
+		spending.Rows = append(spending.Rows, []string{
+			line.Date.Format("02/01/2006"), line.TransactionID, line.JournalNumber, line.Category, line.Description, line.Reference, formatRupiah(line.Amount),
+		})
+	}
+
+	schools := &ExportData{Title: "Porsi per Sekolah", Headers: []string{"Sekolah", "Target Porsi", "Porsi Tersalurkan", "Jumlah Pengiriman", "Capaian"}}
+	for _, line := range report.Schools {
+		schools.Rows = append(schools.Rows, []string{
+			line.SchoolName, strconv.Itoa(line.TargetPortions), strconv.Itoa(line.ServedPortions), strconv.Itoa(line.Deliveries), fmt.Sprintf("%.2f%%", line.Achievement),
+		})
+	}
+
+	evidence := &ExportData{Title: "Bukti Penerimaan Barang", Headers: []string{"No. GRN", "Tanggal", "Supplier", "No. Faktur", "Nilai", "Foto Faktur"}}
+	var images []ExportImage
+	for _, item := range report.Evidence {
+		photo := "-"
+		if item.InvoicePhoto != "" {
+			photo = item.InvoicePhoto
+			images = append(images, ExportImage{
+				Caption: fmt.Sprintf("%s - %s (%s)", item.GRNNumber, item.SupplierName, item.ReceiptDate.Format("02/01/2006")),
+				Data:    s.readAttachment(item.InvoicePhoto),
+			})
+		}
+		evidence.Rows = append(evidence.Rows, []string{
+			item.GRNNumber, item.ReceiptDate.Format("02/01/2006"), item.SupplierName, item.InvoiceNumber, formatRupiah(item.Amount), photo,
+		})
+	}
+
+	return &ExportReport{
+		Title:       "Laporan Pertanggungjawaban (LPJ) Dana " + tranche.TrancheNumber,
+		DateRange:   tranche.PeriodStart.Format("02/01/2006") + " - " + tranche.PeriodEnd.Format("02/01/2006"),
+		GeneratedBy: generatedBy,
+		Sections:    []*ExportData{summary, byCategory, spending, schools, evidence},
+		Images:      images,
+	}
+}
+
+// readAttachment loads an uploaded file for the export. Files that cannot be
+// read are left empty and listed as unavailable.
+func (s *FundingService) readAttachment(ref string) []byte {
+	key, ok := storage.KeyFromRef(ref)
+	if !ok {
+		return nil
+	}
+	r, err := s.files.Open(context.Background(), key)
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// fillServedPortions counts portions received by schools in the tranche period
+func (s *FundingService) fillServedPortions(report *LPJReport, tranche *models.FundingTranche, periodEnd time.Time) error {
+	var rows []struct {
+		SchoolID   uint
+		Portions   int
+		Deliveries int
+	}
+	if err := s.db.Model(&models.DeliveryRecord{}).
+		Select("school_id, SUM(portions) AS portions, COUNT(*) AS deliveries").
+		Where("delivery_date >= ? AND delivery_date < ? AND current_stage >= ?", startOfDay(tranche.PeriodStart), periodEnd, servedDeliveryStage).
+		Group("school_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	lines := make(map[uint]*LPJSchoolLine)
+	var order []uint
+	for _, school := range tranche.Schools {
+		lines[school.SchoolID] = &LPJSchoolLine{SchoolID: school.SchoolID, SchoolName: school.School.Name, TargetPortions: school.TargetPortions}
+		order = append(order, school.SchoolID)
+	}
+
+	var otherSchoolIDs []uint
+	for _, row := range rows {
+		line, ok := lines[row.SchoolID]
+		if !ok {
+			line = &LPJSchoolLine{SchoolID: row.SchoolID}
+			lines[row.SchoolID] = line
+			order = append(order, row.SchoolID)
+			otherSchoolIDs = append(otherSchoolIDs, row.SchoolID)
+		}
+		line.ServedPortions = row.Portions
+		line.Deliveries = row.Deliveries
+		report.ServedPortions += row.Portions
+	}
+
+	if len(otherSchoolIDs) > 0 {
+		var schools []models.School
+		if err := s.db.Select("id, name").Where("id IN ?", otherSchoolIDs).Find(&schools).Error; err != nil {
+			return err
+		}
+		for _, school := range schools {
+			lines[school.ID].SchoolName = school.Name
+		}
+	}
+
+	for _, schoolID := range order {
+		line := lines[schoolID]
+		if line.TargetPortions > 0 {
+			line.Achievement = roundPercent(float64(line.ServedPortions) / float64(line.TargetPortions) * 100)
+		}
+		report.Schools = append(report.Schools, *line)
+	}
+
+	if report.ServedPortions > 0 {
+		report.CostPerPortion = report.TotalSpending.Div(int64(report.ServedPortions))
+	}
+	return nil
+}
+
+// fillEvidence lists the goods receipts behind raw material spending with their invoice photos
+func (s *FundingService) fillEvidence(report *LPJReport, grnAmounts map[string]models.Money) error {
+	if len(grnAmounts) == 0 {
+		return nil
+	}
+	grnNumbers := make([]string, 0, len(grnAmounts))
+	for number := range grnAmounts {
+		grnNumbers = append(grnNumbers, number)
+	}
+
+	var grns []models.GoodsReceipt
+	if err := s.db.Preload("PurchaseOrder.Supplier").
+		Where("grn_number IN ?", grnNumbers).
+		Order("receipt_date ASC").
+		Find(&grns).Error; err != nil {
+		return err
+	}
+
+	grnIDs := make([]uint, 0, len(grns))
+	for _, grn := range grns {
+		grnIDs = append(grnIDs, grn.ID)
+	}
+	invoices := make(map[uint]models.SupplierInvoice)
+	if len(grnIDs) > 0 {
+		var rows []models.SupplierInvoice
+		if err := s.db.Where("grn_id IN ? AND status <> ?", grnIDs, InvoiceStatusCancelled).Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, invoice := range rows {
+			invoices[invoice.GRNID] = invoice
+		}
+	}
+
+	for _, grn := range grns {
+		item := LPJEvidence{
+			GRNID:        grn.ID,
+			GRNNumber:    grn.GRNNumber,
+			ReceiptDate:  grn.ReceiptDate,
+			SupplierName: grn.PurchaseOrder.Supplier.Name,
+			Amount:       grnAmounts[grn.GRNNumber],
+			InvoicePhoto: grn.InvoicePhoto,
+		}
+		if invoice, ok := invoices[grn.ID]; ok {
+			item.InvoiceNumber = invoice.InvoiceNumber
+			if item.InvoicePhoto == "" {
+				item.InvoicePhoto = invoice.InvoicePhoto
+			}
+		}
+		report.Evidence = append(report.Evidence, item)
+	}
+	return nil
+}
+
+// validateTranche checks amounts, period and target portions and derives the
+// target portions from the schools when not given
+func (s *FundingService) validateTranche(tranche *models.FundingTranche) error {
+	tranche.Source = strings.TrimSpace(tranche.Source)
+	if tranche.Amount <= 0 {
+		return ErrInvalidFundingAmount
+	}
+	if tranche.PeriodStart.IsZero() || tranche.PeriodEnd.IsZero() || tranche.PeriodEnd.Before(tranche.PeriodStart) {
+		return ErrInvalidFundingPeriod
+	}
+	if tranche.ReceivedDate.IsZero() {
+		tranche.ReceivedDate = time.Now()
+	}
+	if tranche.TargetPortions < 0 {
+		return ErrInvalidTargetPortions
+	}
+
+	seen := make(map[uint]bool, len(tranche.Schools))
+	schoolTargets := 0
+	for _, school := range tranche.Schools {
+		if school.TargetPortions < 0 {
+			return ErrInvalidTargetPortions
+		}
+		if seen[school.SchoolID] {
+			return ErrDuplicateTrancheSchool
+		}
+		seen[school.SchoolID] = true
+		schoolTargets += school.TargetPortions
+	}
+	if tranche.TargetPortions == 0 {
+		tranche.TargetPortions = schoolTargets
+	}
+
+	if len(seen) > 0 {
+		ids := make([]uint, 0, len(seen))
+		for id := range seen {
+			ids = append(ids, id)
+		}
+		var count int64
+		if err := s.db.Model(&models.School{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(ids) {
+			return ErrSchoolNotFound
+		}
+	}
+	return nil
+}
+
+// generateTrancheNumber generates a tranche number in the format DANA-YYYYMM-XXXX
+func (s *FundingService) generateTrancheNumber(tx *gorm.DB, receivedDate time.Time) (string, error) {
+	prefix := fmt.Sprintf("DANA-%s-", receivedDate.Format("200601"))
+
+	var count int64
+	if err := tx.Model(&models.FundingTranche{}).Where("tranche_number LIKE ?", prefix+"%").Count(&count).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%04d", prefix, count+1), nil
+}
+
+// trancheIncomeDescription describes the income entry of a tranche
+func trancheIncomeDescription(tranche *models.FundingTranche) string {
+	description := fmt.Sprintf("Penerimaan dana %s periode %s - %s", tranche.Source,
+		tranche.PeriodStart.Format("02/01/2006"), tranche.PeriodEnd.Format("02/01/2006"))
+	if tranche.ReferenceNumber != "" {
+		description += " (ref. " + tranche.ReferenceNumber + ")"
+	}
+	return description
+}
+
+// uniqueIDs removes duplicate IDs
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// formatRupiah formats an amount as Rupiah with Indonesian separators, e.g. Rp 1.250.000,50
+func formatRupiah(amount models.Money) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	cents := int64(amount)
+	whole := strconv.FormatInt(cents/100, 10)
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%sRp %s,%02d", sign, grouped.String(), cents%100)
+}
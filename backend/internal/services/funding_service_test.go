@@ -0,0 +1,249 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+func setupFundingTestDB(t *testing.T) *gorm.DB {
+	db, err := openTestDB(t, filepath.Join(t.TempDir(), "funding.db"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.School{}, &models.DeliveryRecord{}, &models.Supplier{},
+		&models.PurchaseOrder{}, &models.GoodsReceipt{}, &models.SupplierInvoice{}, &models.CashFlowEntry{},
+		&models.Account{}, &models.JournalEntry{}, &models.JournalLine{}, &models.AuditTrail{},
+		&models.FundingTranche{}, &models.FundingTrancheSchool{})
+	require.NoError(t, err)
+
+	require.NoError(t, NewLedgerService(db).SeedDefaultAccounts())
+	return db
+}
+
+type fundingFixture struct {
+	tranche *models.FundingTranche
+	schools []models.School
+	grn     models.GoodsReceipt
+}
+
+// createFundingFixture records a March 2026 tranche for two schools, spending
+// in and around the period and deliveries in different stages
+func createFundingFixture(t *testing.T, db *gorm.DB, service *FundingService) fundingFixture {
+	schools := []models.School{
+		{Name: "SDN 1 Sukamaju", Category: "SD", StudentCount: 300},
+		{Name: "SMPN 2 Sukamaju", Category: "SMP", StudentCount: 250},
+		{Name: "SMAN 1 Sukamaju", Category: "SMA", StudentCount: 200},
+	}
+	require.NoError(t, db.Create(&schools).Error)
+
+	date := func(day int) time.Time { return time.Date(2026, 3, day, 9, 0, 0, 0, time.Local) }
+	tranche := &models.FundingTranche{
+		Source:          "Badan Gizi Nasional",
+		ReferenceNumber: "SP2D-0012/2026",
+		ReceivedDate:    date(1),
+		Amount:          models.Rupiah(50000000),
+		PeriodStart:     date(1),
+		PeriodEnd:       time.Date(2026, 3, 31, 0, 0, 0, 0, time.Local),
+		Schools: []models.FundingTrancheSchool{
+			{SchoolID: schools[0].ID, TargetPortions: 6000},
+			{SchoolID: schools[1].ID, TargetPortions: 5000},
+		},
+	}
+	require.NoError(t, service.CreateTranche(tranche, 1))
+
+	supplier := models.Supplier{Name: "CV Sumber Pangan", IsActive: true}
+	require.NoError(t, db.Create(&supplier).Error)
+	po := models.PurchaseOrder{PONumber: "PO-LPJ-1", SupplierID: supplier.ID, OrderDate: date(2), Status: "received", TotalAmount: models.Rupiah(7500000), CreatedBy: 1}
+	require.NoError(t, db.Create(&po).Error)
+	grn := models.GoodsReceipt{GRNNumber: "GRN-20260303-0001", POID: po.ID, ReceiptDate: date(3), ReceivedBy: 1, InvoicePhoto: "/uploads/invoices/invoice_1.png"}
+	require.NoError(t, db.Create(&grn).Error)
+
+	cashFlowService := NewCashFlowService(db)
+	expenses := []models.CashFlowEntry{
+		{TransactionID: "TXN-LPJ-0001", Date: date(3), Category: "bahan_baku", Type: "expense", Amount: models.Rupiah(7500000), Reference: grn.GRNNumber, CreatedBy: 1},
+		{TransactionID: "TXN-LPJ-0002", Date: date(25), Category: "gaji", Type: "expense", Amount: models.Rupiah(12000000), CreatedBy: 1},
+		// Paid in February but accounted to this tranche
+		{TransactionID: "TXN-LPJ-0003", Date: time.Date(2026, 2, 27, 0, 0, 0, 0, time.Local), Category: "utilitas", Type: "expense", Amount: models.Rupiah(500000), CreatedBy: 1, FundingTrancheID: &tranche.ID},
+		// Outside the period and not assigned
+		{TransactionID: "TXN-LPJ-0004", Date: time.Date(2026, 4, 1, 0, 0, 0, 0, time.Local), Category: "operasional", Type: "expense", Amount: models.Rupiah(900000), CreatedBy: 1},
+	}
+	for i := range expenses {
+		require.NoError(t, cashFlowService.CreateCashFlowEntry(&expenses[i]))
+	}
+
+	deliveries := []models.DeliveryRecord{
+		{DeliveryDate: date(10), SchoolID: schools[0].ID, MenuItemID: 1, Portions: 300, CurrentStatus: "sudah_diterima_pihak_sekolah", CurrentStage: 9, OmprengCount: 30},
+		{DeliveryDate: date(11), SchoolID: schools[0].ID, MenuItemID: 1, Portions: 300, CurrentStatus: "ompreng_selesai_dicuci", CurrentStage: 16, OmprengCount: 30},
+		{DeliveryDate: date(11), SchoolID: schools[1].ID, MenuItemID: 1, Portions: 250, CurrentStatus: "driver_tiba_di_sppg", CurrentStage: 13, OmprengCount: 25},
+		{DeliveryDate: date(12), SchoolID: schools[2].ID, MenuItemID: 1, Portions: 150, CurrentStatus: "sudah_diterima_pihak_sekolah", CurrentStage: 9, OmprengCount: 15},
+		// Not yet received by the school
+		{DeliveryDate: date(31), SchoolID: schools[1].ID, MenuItemID: 1, Portions: 250, CurrentStatus: "diperjalanan", CurrentStage: 7, OmprengCount: 25},
+		// Outside the period
+		{DeliveryDate: time.Date(2026, 4, 1, 8, 0, 0, 0, time.Local), SchoolID: schools[0].ID, MenuItemID: 1, Portions: 300, CurrentStatus: "sudah_diterima_pihak_sekolah", CurrentStage: 9, OmprengCount: 30},
+	}
+	require.NoError(t, db.Create(&deliveries).Error)
+
+	return fundingFixture{tranche: tranche, schools: schools, grn: grn}
+}
+
+func TestFundingService_TrancheIncomeAndLPJ(t *testing.T) {
+	db := setupFundingTestDB(t)
+	service := NewFundingService(db, NewCashFlowService(db))
+	fixture := createFundingFixture(t, db, service)
+
+	assert.Equal(t, "DANA-202603-0001", fixture.tranche.TrancheNumber)
+	assert.Equal(t, 11000, fixture.tranche.TargetPortions)
+	require.NotNil(t, fixture.tranche.CashFlowEntryID)
+
+	var income models.CashFlowEntry
+	require.NoError(t, db.First(&income, *fixture.tranche.CashFlowEntryID).Error)
+	assert.Equal(t, "income", income.Type)
+	assert.Equal(t, models.Rupiah(50000000), income.Amount)
+	assert.Equal(t, fixture.tranche.TrancheNumber, income.Reference)
+
+	report, err := service.GetLPJ(fixture.tranche.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.Rupiah(50000000), report.FundsReceived)
+	assert.Equal(t, models.Rupiah(20000000), report.TotalSpending)
+	assert.Equal(t, models.Rupiah(30000000), report.RemainingBalance)
+	assert.Equal(t, 40.0, report.AbsorptionRate)
+
+	require.Len(t, report.Spending, 3)
+	assert.Equal(t, "TXN-LPJ-0003", report.Spending[0].TransactionID)
+	assert.True(t, report.Spending[0].Assigned)
+	for _, line := range report.Spending {
+		assert.NotEmpty(t, line.JournalNumber, line.TransactionID)
+	}
+
+	require.Len(t, report.ByCategory, 3)
+	assert.Equal(t, "gaji", report.ByCategory[0].Category)
+	assert.Equal(t, 60.0, report.ByCategory[0].Percentage)
+
+	// Only deliveries received by the school within the period count
+	assert.Equal(t, 1000, report.ServedPortions)
+	assert.Equal(t, models.Rupiah(20000), report.CostPerPortion)
+	require.Len(t, report.Schools, 3)
+	assert.Equal(t, "SDN 1 Sukamaju", report.Schools[0].SchoolName)
+	assert.Equal(t, 600, report.Schools[0].ServedPortions)
+	assert.Equal(t, 2, report.Schools[0].Deliveries)
+	assert.Equal(t, 10.0, report.Schools[0].Achievement)
+	assert.Equal(t, 250, report.Schools[1].ServedPortions)
+	assert.Equal(t, "SMAN 1 Sukamaju", report.Schools[2].SchoolName)
+	assert.Equal(t, 0, report.Schools[2].TargetPortions)
+
+	require.Len(t, report.Evidence, 1)
+	assert.Equal(t, fixture.grn.GRNNumber, report.Evidence[0].GRNNumber)
+	assert.Equal(t, "CV Sumber Pangan", report.Evidence[0].SupplierName)
+	assert.Equal(t, "/uploads/invoices/invoice_1.png", report.Evidence[0].InvoicePhoto)
+
+	// Assigning an out-of-period expense adds it to the report
+	var april models.CashFlowEntry
+	require.NoError(t, db.Where("transaction_id = ?", "TXN-LPJ-0004").First(&april).Error)
+	assigned, err := service.AssignExpenses(fixture.tranche.ID, []uint{april.ID}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, assigned)
+	_, err = service.AssignExpenses(fixture.tranche.ID, []uint{income.ID}, 1)
+	assert.ErrorIs(t, err, ErrExpenseNotAssignable)
+
+	report, err = service.GetLPJ(fixture.tranche.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(20900000), report.TotalSpending)
+
+	require.NoError(t, service.UnassignExpense(fixture.tranche.ID, april.ID, 1))
+	assert.ErrorIs(t, service.UnassignExpense(fixture.tranche.ID, april.ID, 1), ErrExpenseNotInTranche)
+}
+
+func TestFundingService_ExportLPJWithInvoicePhotos(t *testing.T) {
+	db := setupFundingTestDB(t)
+	service := NewFundingService(db, NewCashFlowService(db))
+	fixture := createFundingFixture(t, db, service)
+
+	// Invoice photos are stored under ./uploads relative to the working directory
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(filepath.Join("uploads", "invoices"), 0755))
+	img := image.NewRGBA(image.Rect(0, 0, 40, 60))
+	img.Set(10, 10, color.RGBA{R: 200, A: 255})
+	var photo bytes.Buffer
+	require.NoError(t, png.Encode(&photo, img))
+	require.NoError(t, os.WriteFile(filepath.Join("uploads", "invoices", "invoice_1.png"), photo.Bytes(), 0644))
+
+	data, filename, err := service.ExportLPJ(fixture.tranche.ID, ExportFormatPDF, 1)
+	require.NoError(t, err)
+	assert.Contains(t, filename, "lpj_dana-202603-0001_")
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF")))
+
+	data, filename, err = service.ExportLPJ(fixture.tranche.ID, ExportFormatExcel, 1)
+	require.NoError(t, err)
+	assert.Contains(t, filename, ".xlsx")
+
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{"Ringkasan", "Belanja per Kategori", "Rincian Belanja", "Porsi per Sekolah", "Bukti Penerimaan Barang", "Lampiran"}, f.GetSheetList())
+	pictures, err := f.GetPictures("Lampiran", "B2")
+	require.NoError(t, err)
+	assert.Len(t, pictures, 1)
+	rows, err := f.GetRows("Ringkasan")
+	require.NoError(t, err)
+	var remaining string
+	for _, row := range rows {
+		if len(row) > 1 && row[0] == "Sisa Dana" {
+			remaining = row[1]
+		}
+	}
+	assert.Equal(t, "Rp 30.000.000,00", remaining)
+
+	// A missing photo is listed without failing the export
+	require.NoError(t, os.Remove(filepath.Join("uploads", "invoices", "invoice_1.png")))
+	_, _, err = service.ExportLPJ(fixture.tranche.ID, ExportFormatPDF, 1)
+	require.NoError(t, err)
+
+	_, _, err = service.ExportLPJ(fixture.tranche.ID, ExportFormat("csv"), 1)
+	assert.ErrorIs(t, err, ErrUnsupportedExportFormat)
+}
+
+func TestFundingService_UpdateAndDeleteTranche(t *testing.T) {
+	db := setupFundingTestDB(t)
+	ledger := NewLedgerService(db)
+	service := NewFundingService(db, NewCashFlowService(db))
+	fixture := createFundingFixture(t, db, service)
+
+	assert.Equal(t, models.Rupiah(-50000000), ledgerBalance(t, ledger, AccountCodeGovernmentFunding))
+
+	updates := *fixture.tranche
+	updates.Amount = models.Rupiah(45000000)
+	updates.TargetPortions = 0
+	updates.Schools = []models.FundingTrancheSchool{{SchoolID: fixture.schools[0].ID, TargetPortions: 9000}}
+	updated, err := service.UpdateTranche(fixture.tranche.ID, &updates, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(45000000), updated.Amount)
+	assert.Equal(t, 9000, updated.TargetPortions)
+	require.Len(t, updated.Schools, 1)
+	assert.Equal(t, models.Rupiah(-45000000), ledgerBalance(t, ledger, AccountCodeGovernmentFunding))
+
+	invalid := updates
+	invalid.PeriodEnd = invalid.PeriodStart.AddDate(0, 0, -1)
+	_, err = service.UpdateTranche(fixture.tranche.ID, &invalid, 1)
+	assert.ErrorIs(t, err, ErrInvalidFundingPeriod)
+
+	require.NoError(t, service.DeleteTranche(fixture.tranche.ID, 1))
+	_, err = service.GetTranche(fixture.tranche.ID)
+	assert.ErrorIs(t, err, ErrFundingTrancheNotFound)
+	assert.Equal(t, models.Rupiah(0), ledgerBalance(t, ledger, AccountCodeGovernmentFunding))
+
+	var assigned int64
+	require.NoError(t, db.Model(&models.CashFlowEntry{}).Where("funding_tranche_id IS NOT NULL").Count(&assigned).Error)
+	assert.Zero(t, assigned)
+}
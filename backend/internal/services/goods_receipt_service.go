@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -46,7 +47,7 @@ type QuantityDiscrepancy struct {
 func (s *GoodsReceiptService) CreateGoodsReceipt(grn *models.GoodsReceipt, items []models.GoodsReceiptItem, userID uint) error {
 	// Validate PO exists and is approved
 	var po models.PurchaseOrder
-	if err := s.db.Preload("POItems.Ingredient").First(&po, grn.POID).Error; err != nil {
+	if err := s.db.Preload("Supplier").Preload("POItems.Ingredient").First(&po, grn.POID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errors.New("purchase order tidak ditemukan")
 		}
@@ -92,7 +93,9 @@ func (s *GoodsReceiptService) CreateGoodsReceipt(grn *models.GoodsReceipt, items
 	grn.ReceiptDate = time.Now()
 
 	// Create GRN in transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var cashFlowEntry *models.CashFlowEntry
+	var published []events.Event
+	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// Create GRN
 		if err := tx.Create(grn).Error; err != nil {
 			return err
@@ -121,15 +124,18 @@ func (s *GoodsReceiptService) CreateGoodsReceipt(grn *models.GoodsReceipt, items
 			}
 		}
 
-		// Create cash flow entry
-		if s.cashFlowService != nil {
-			cashFlowEntry := &models.CashFlowEntry{
+		// Recognise the value actually received as an expense owed to the
+		// supplier; the supplier invoice later corrects it to the invoiced amount
+		receivedAmount := ReceivedValue(&po, items)
+		if s.cashFlowService != nil && receivedAmount > 0 {
+			cashFlowEntry = &models.CashFlowEntry{
 				Date:        grn.ReceiptDate,
 				Category:    "bahan_baku",
 				Type:        "expense",
-				Amount:      po.TotalAmount,
+				Amount:      receivedAmount,
 				Description: fmt.Sprintf("Pembelian bahan baku dari %s (PO: %s)", po.Supplier.Name, po.PONumber),
 				Reference:   grn.GRNNumber,
+				IsPayable:   true,
 				CreatedBy:   userID,
 			}
 			if err := s.cashFlowService.CreateCashFlowEntryWithTx(tx, cashFlowEntry); err != nil {
@@ -137,8 +143,46 @@ func (s *GoodsReceiptService) CreateGoodsReceipt(grn *models.GoodsReceipt, items
 			}
 		}
 
-		return nil
+		ingredientIDs := make([]uint, len(items))
+		for i, item := range items {
+			ingredientIDs[i] = item.IngredientID
+		}
+		published = []events.Event{
+			events.StockChanged{IngredientIDs: ingredientIDs, Reason: "in", Reference: grn.GRNNumber},
+			events.GoodsReceived{
+				GoodsReceiptID:  grn.ID,
+				PurchaseOrderID: po.ID,
+				SupplierID:      po.SupplierID,
+				IngredientIDs:   ingredientIDs,
+			},
+		}
+		return events.PublishTx(tx, published...)
 	})
+	if err != nil {
+		return err
+	}
+
+	// Budget alerts are only sent once the expense is committed
+	if cashFlowEntry != nil {
+		s.cashFlowService.CheckBudgetAlerts(cashFlowEntry)
+	}
+
+	events.Notify(published...)
+	return nil
+}
+
+// ReceivedValue returns the value of received goods at purchase order prices
+func ReceivedValue(po *models.PurchaseOrder, items []models.GoodsReceiptItem) models.Money {
+	unitPrices := make(map[uint]models.Money, len(po.POItems))
+	for _, poItem := range po.POItems {
+		unitPrices[poItem.IngredientID] = poItem.UnitPrice
+	}
+
+	var total models.Money
+	for _, item := range items {
+		total += unitPrices[item.IngredientID].Mul(item.ReceivedQuantity)
+	}
+	return total
 }
 
 // GetGoodsReceiptByID retrieves a goods receipt by ID with related data
@@ -3,8 +3,10 @@ package services
 import (
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -29,12 +31,23 @@ func NewInventoryService(db *gorm.DB) *InventoryService {
 
 // UpdateStock updates inventory stock levels and creates movement record
 func (s *InventoryService) UpdateStock(ingredientID uint, quantity float64, movementType string, reference string, userID uint, notes string) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		return s.UpdateStockWithTx(tx, ingredientID, quantity, movementType, reference, userID, notes)
+	event := events.StockChanged{IngredientIDs: []uint{ingredientID}, Reason: movementType, Reference: reference}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.UpdateStockWithTx(tx, ingredientID, quantity, movementType, reference, userID, notes); err != nil {
+			return err
+		}
+		return events.PublishTx(tx, event)
 	})
+	if err != nil {
+		return err
+	}
+
+	events.Notify(event)
+	return nil
 }
 
-// UpdateStockWithTx updates inventory stock levels within a transaction
+// UpdateStockWithTx updates inventory stock levels within a transaction.
+// The caller publishes events.StockChanged with the transaction.
 func (s *InventoryService) UpdateStockWithTx(tx *gorm.DB, ingredientID uint, quantity float64, movementType string, reference string, userID uint, notes string) error {
 	// Validate movement type
 	if movementType != "in" && movementType != "out" && movementType != "adjustment" {
@@ -150,6 +163,14 @@ func (s *InventoryService) InitializeInventoryForAllIngredients() error {
 		}
 	}
 
+	if len(ingredientsWithoutInventory) > 0 {
+		ingredientIDs := make([]uint, len(ingredientsWithoutInventory))
+		for i, ingredient := range ingredientsWithoutInventory {
+			ingredientIDs[i] = ingredient.ID
+		}
+		events.Publish(events.StockChanged{IngredientIDs: ingredientIDs, Reason: "initialize"})
+	}
+
 	return nil
 }
 
@@ -167,7 +188,11 @@ func (s *InventoryService) InitializeInventoryForIngredient(ingredientID uint) e
 				MinThreshold: 10, // default threshold
 				LastUpdated:  time.Now(),
 			}
-			return s.db.Create(&inventoryItem).Error
+			if err := s.db.Create(&inventoryItem).Error; err != nil {
+				return err
+			}
+			events.Publish(events.StockChanged{IngredientIDs: []uint{ingredientID}, Reason: "initialize"})
+			return nil
 		}
 		return err
 	}
@@ -254,6 +279,36 @@ func (s *InventoryService) calculateDaysRemaining(ingredientID uint, currentStoc
 	return currentStock / avgDailyConsumption
 }
 
+// SubscribeEvents publishes events.StockBelowThreshold when stock is taken
+// out or adjusted and an ingredient ends up below its minimum threshold.
+// The check runs asynchronously so it does not slow down stock changes.
+func (s *InventoryService) SubscribeEvents(bus *events.Bus) {
+	bus.SubscribeAsync(events.StockChangedEvent, func(e events.Event) {
+		event := e.(events.StockChanged)
+		// Incoming stock and new empty inventory records do not raise alerts
+		if event.Reason == "in" || event.Reason == "initialize" || len(event.IngredientIDs) == 0 {
+			return
+		}
+
+		var items []models.InventoryItem
+		if err := s.db.Preload("Ingredient").
+			Where("ingredient_id IN ? AND quantity < min_threshold", event.IngredientIDs).
+			Find(&items).Error; err != nil {
+			log.Printf("Warning: failed to check stock thresholds: %v", err)
+			return
+		}
+
+		for _, item := range items {
+			bus.Publish(events.StockBelowThreshold{
+				IngredientID:   item.IngredientID,
+				IngredientName: item.Ingredient.Name,
+				Quantity:       item.Quantity,
+				MinThreshold:   item.MinThreshold,
+			})
+		}
+	})
+}
+
 // GetMovements retrieves inventory movements with optional filters
 func (s *InventoryService) GetMovements(ingredientID *uint, movementType string, startDate, endDate *time.Time) ([]models.InventoryMovement, error) {
 	query := s.db.Preload("Ingredient").Preload("Creator")
@@ -287,6 +342,8 @@ func (s *InventoryService) UpdateMinThreshold(ingredientID uint, threshold float
 	if result.RowsAffected == 0 {
 		return ErrInventoryNotFound
 	}
+
+	events.Publish(events.StockChanged{IngredientIDs: []uint{ingredientID}, Reason: "threshold"})
 	return nil
 }
 
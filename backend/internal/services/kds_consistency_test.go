@@ -8,14 +8,13 @@ import (
 	"github.com/erp-sppg/backend/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 // TestKDSDataConsistency verifies that portion size data is consistent across KDS Cooking View and KDS Packing View
 func TestKDSDataConsistency(t *testing.T) {
 	// Setup test database
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
 	require.NoError(t, err)
 
 	// Auto migrate all models
@@ -233,7 +232,7 @@ func TestKDSDataConsistency(t *testing.T) {
 // TestKDSDataConsistencyMultipleRecipes verifies consistency with multiple recipes
 func TestKDSDataConsistencyMultipleRecipes(t *testing.T) {
 	// Setup test database
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
 	require.NoError(t, err)
 
 	// Auto migrate all models
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/erp-sppg/backend/internal/models"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -15,7 +14,7 @@ import (
 // TestPreValidationCheck tests that the pre-validation check collects all insufficient items
 func TestPreValidationCheck(t *testing.T) {
 	// Setup test database
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+	db, err := openTestDB(t, ":memory:", &gorm.Config{
 		Logger: logger.Discard,
 	})
 	if err != nil {
@@ -39,10 +38,8 @@ func TestPreValidationCheck(t *testing.T) {
 
 	// Create KDS service
 	kdsService := &KDSService{
-		db:                db,
-		firebaseApp:       nil,
-		dbClient:          nil,
-		monitoringService: nil,
+		db:        db,
+		publisher: nil,
 	}
 
 	// Create test user
@@ -8,9 +8,9 @@ import (
 	"strings"
 	"time"
 
-	firebase "firebase.google.com/go/v4"
-	"firebase.google.com/go/v4/db"
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/realtime"
 	"gorm.io/gorm"
 )
 
@@ -47,25 +47,15 @@ func NewKDSError(code, message, details string) *KDSError {
 
 // KDSService handles Kitchen Display System operations
 type KDSService struct {
-	db                *gorm.DB
-	firebaseApp       *firebase.App
-	dbClient          *db.Client
-	monitoringService *MonitoringService
+	db        *gorm.DB
+	publisher realtime.RealtimePublisher
 }
 
 // NewKDSService creates a new KDS service instance
-func NewKDSService(database *gorm.DB, firebaseApp *firebase.App, monitoringService *MonitoringService) (*KDSService, error) {
-	ctx := context.Background()
-	dbClient, err := firebaseApp.Database(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get Firebase database client: %w", err)
-	}
-
+func NewKDSService(database *gorm.DB, publisher realtime.RealtimePublisher) (*KDSService, error) {
 	return &KDSService{
-		db:                database,
-		firebaseApp:       firebaseApp,
-		dbClient:          dbClient,
-		monitoringService: monitoringService,
+		db:        database,
+		publisher: publisher,
 	}, nil
 }
 
@@ -142,6 +132,7 @@ type PackingAllocation struct {
 // GetTodayMenu retrieves the menu for the specified date from approved weekly plan
 func (s *KDSService) GetTodayMenu(ctx context.Context, date time.Time) ([]RecipeStatus, error) {
 	normalizedDate := normalizeDate(date)
+	dayStart, dayEnd := localDayRange(normalizedDate)
 
 	var menuItems []models.MenuItem
 	err := s.db.WithContext(ctx).
@@ -156,21 +147,23 @@ func (s *KDSService) GetTodayMenu(ctx context.Context, date time.Time) ([]Recipe
 		Preload("MenuPlan").
 		Joins("JOIN menu_plans ON menu_items.menu_plan_id = menu_plans.id").
 		Where("menu_plans.status = ?", "approved").
-		Where("DATE(menu_items.date) = DATE(?)", normalizedDate).
+		Where("menu_items.date >= ? AND menu_items.date < ?", dayStart, dayEnd).
+		Order("menu_items.id").
 		Find(&menuItems).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get menu for date: %w", err)
 	}
 
-	// Get current statuses from Firebase
+	// Get current statuses from the realtime backend
 	dateStr := normalizedDate.Format("2006-01-02")
 	firebasePath := fmt.Sprintf("/kds/cooking/%s", dateStr)
 	var firebaseData map[string]interface{}
-	err = s.dbClient.NewRef(firebasePath).Get(ctx, &firebaseData)
-	if err != nil {
-		// If Firebase read fails, just log and continue with default status
-		fmt.Printf("Warning: failed to read from Firebase: %v\n", err)
+	if s.publisher != nil {
+		if err := s.publisher.Get(ctx, firebasePath, &firebaseData); err != nil {
+			// If the read fails, just log and continue with default status
+			fmt.Printf("Warning: failed to read realtime data: %v\n", err)
+		}
 	}
 
 	// Convert to RecipeStatus format
@@ -350,6 +343,7 @@ func (s *KDSService) UpdateRecipeStatus(ctx context.Context, recipeID uint, stat
 	}
 
 	// Get recipe details and menu item for today
+	todayStart, todayEnd := localDayRange(time.Now())
 	var menuItem models.MenuItem
 	err := s.db.WithContext(ctx).
 		Preload("Recipe").
@@ -360,7 +354,7 @@ func (s *KDSService) UpdateRecipeStatus(ctx context.Context, recipeID uint, stat
 		Joins("JOIN menu_plans ON menu_items.menu_plan_id = menu_plans.id").
 		Where("menu_plans.status = ?", "approved").
 		Where("menu_items.recipe_id = ?", recipeID).
-		Where("DATE(menu_items.date) = DATE(?)", time.Now()).
+		Where("menu_items.date >= ? AND menu_items.date < ?", todayStart, todayEnd).
 		First(&menuItem).Error
 	if err != nil {
 		return fmt.Errorf("failed to get menu item: %w", err)
@@ -380,125 +374,12 @@ func (s *KDSService) UpdateRecipeStatus(ctx context.Context, recipeID uint, stat
 		log.Printf("INFO: Stock validation and deduction completed successfully for recipe_id=%d", recipeID)
 	}
 
-	// Trigger monitoring system updates for each school allocation
-	if s.monitoringService != nil {
-		fmt.Printf("DEBUG: monitoringService is available, status=%s, allocations=%d\n", status, len(menuItem.SchoolAllocations))
-		if status == "cooking" {
-			fmt.Printf("DEBUG: Creating delivery records for cooking status\n")
-			// Create delivery records and update status to "order_dimasak" (stage 2)
-			// Group allocations by school to calculate portions_small and portions_large
-			schoolPortions := make(map[uint]struct {
-				small int
-				large int
-				total int
-			})
-			
-			for _, alloc := range menuItem.SchoolAllocations {
-				portions := schoolPortions[alloc.SchoolID]
-				if alloc.PortionSize == "small" {
-					portions.small += alloc.Portions
-				} else if alloc.PortionSize == "large" {
-					portions.large += alloc.Portions
-				}
-				portions.total += alloc.Portions
-				schoolPortions[alloc.SchoolID] = portions
-			}
-			
-			// Create delivery records for each school
-			for schoolID, portions := range schoolPortions {
-				fmt.Printf("DEBUG: Processing school_id=%d, small=%d, large=%d, total=%d\n", 
-					schoolID, portions.small, portions.large, portions.total)
-				
-				// Check if delivery record already exists for this school
-				var existingRecord models.DeliveryRecord
-				err := s.db.WithContext(ctx).
-					Where("menu_item_id = ? AND school_id = ? AND DATE(delivery_date) = DATE(?)", 
-						menuItem.ID, schoolID, menuItem.Date).
-					First(&existingRecord).Error
-				
-				if err == gorm.ErrRecordNotFound {
-					// Create new delivery record with stage 2 (sedang_dimasak)
-					deliveryRecord := models.DeliveryRecord{
-						DeliveryDate:  menuItem.Date,
-						SchoolID:      schoolID,
-						DriverID:      nil, // Driver assigned later at stage 4
-						MenuItemID:    menuItem.ID,
-						Portions:      portions.total,
-						PortionsSmall: portions.small,
-						PortionsLarge: portions.large,
-						CurrentStatus: "sedang_dimasak",
-						CurrentStage:  2,
-						OmprengCount:  portions.total, // Assume 1 ompreng per portion
-						CreatedAt:     time.Now(),
-						UpdatedAt:     time.Now(),
-					}
-					
-					if err := s.db.WithContext(ctx).Create(&deliveryRecord).Error; err != nil {
-						// Log error but don't block cooking workflow
-						fmt.Printf("Warning: failed to create delivery record for school %d: %v\n", schoolID, err)
-						continue
-					}
-					
-					createdRecord := deliveryRecord
-					
-					// Create initial status transition for stage 2
-					transition := models.StatusTransition{
-						DeliveryRecordID: createdRecord.ID,
-						FromStatus:       "",
-						ToStatus:         "sedang_dimasak",
-						Stage:            2,
-						TransitionedAt:   time.Now(),
-						TransitionedBy:   userID,
-						Notes:            "Cooking started from KDS",
-					}
-					
-					if err := s.db.WithContext(ctx).Create(&transition).Error; err != nil {
-						// Log error but don't block cooking workflow
-						fmt.Printf("Warning: failed to create status transition for delivery record %d: %v\n", createdRecord.ID, err)
-					}
-				} else if err == nil {
-					// Update existing delivery record status to stage 2
-					if err := s.monitoringService.UpdateDeliveryStatus(existingRecord.ID, "sedang_dimasak", userID, "Cooking started from KDS"); err != nil {
-						// Log error but don't block cooking workflow
-						fmt.Printf("Warning: failed to update delivery status for record %d: %v\n", existingRecord.ID, err)
-					}
-				}
-			}
-		} else if status == "ready" {
-			fmt.Printf("DEBUG: Updating delivery records to ready status, allocations=%d\n", len(menuItem.SchoolAllocations))
-			// Update delivery records to "selesai_dimasak" (stage 3)
-			// Use a map to track which schools we've already updated (to avoid duplicate updates for SD schools with multiple allocations)
-			updatedSchools := make(map[uint]bool)
-			
-			for _, alloc := range menuItem.SchoolAllocations {
-				// Skip if we've already updated this school
-				if updatedSchools[alloc.SchoolID] {
-					fmt.Printf("DEBUG: Skipping duplicate update for school_id=%d\n", alloc.SchoolID)
-					continue
-				}
-				
-				fmt.Printf("DEBUG: Looking for delivery record for school_id=%d, menu_item_id=%d\n", alloc.SchoolID, menuItem.ID)
-				var deliveryRecord models.DeliveryRecord
-				err := s.db.WithContext(ctx).
-					Where("menu_item_id = ? AND school_id = ? AND DATE(delivery_date) = DATE(?)", 
-						menuItem.ID, alloc.SchoolID, menuItem.Date).
-					First(&deliveryRecord).Error
-				
-				if err == nil {
-					fmt.Printf("DEBUG: Found delivery record id=%d, updating to selesai_dimasak\n", deliveryRecord.ID)
-					if err := s.monitoringService.UpdateDeliveryStatus(deliveryRecord.ID, "selesai_dimasak", userID, "Cooking completed, ready for packing"); err != nil {
-						// Log error but don't block cooking workflow
-						fmt.Printf("Warning: failed to update delivery status for record %d: %v\n", deliveryRecord.ID, err)
-					} else {
-						fmt.Printf("DEBUG: Successfully updated delivery record id=%d to selesai_dimasak\n", deliveryRecord.ID)
-					}
-					updatedSchools[alloc.SchoolID] = true
-				} else {
-					// Log error but don't block cooking workflow
-					fmt.Printf("Warning: delivery record not found for school %d: %v\n", alloc.SchoolID, err)
-				}
-			}
-		}
+	// Monitoring moves the delivery records of the allocated schools along
+	switch status {
+	case "cooking":
+		events.Publish(events.CookingStarted{MenuItemID: menuItem.ID, RecipeID: recipeID, Date: menuItem.Date, UserID: userID})
+	case "ready":
+		events.Publish(events.CookingCompleted{MenuItemID: menuItem.ID, RecipeID: recipeID, Date: menuItem.Date, UserID: userID})
 	}
 
 	// Update Firebase with new status
@@ -566,9 +447,9 @@ func (s *KDSService) UpdateRecipeStatus(ctx context.Context, recipeID uint, stat
 		updateData["end_time"] = endTime
 		
 		// Calculate duration if start_time exists
-		if s.dbClient != nil {
+		if s.publisher != nil {
 			var existingData map[string]interface{}
-			err := s.dbClient.NewRef(firebasePath).Get(ctx, &existingData)
+			err := s.publisher.Get(ctx, firebasePath, &existingData)
 			if err == nil && existingData != nil {
 				if startTimeFloat, ok := existingData["start_time"].(float64); ok {
 					startTime := int64(startTimeFloat)
@@ -580,11 +461,11 @@ func (s *KDSService) UpdateRecipeStatus(ctx context.Context, recipeID uint, stat
 		}
 	}
 
-	// Skip Firebase update if dbClient is nil (for testing)
-	if s.dbClient != nil {
-		err = s.dbClient.NewRef(firebasePath).Set(ctx, updateData)
+	// Skip the realtime update if no publisher is configured (for testing)
+	if s.publisher != nil {
+		err = s.publisher.Set(ctx, firebasePath, updateData)
 		if err != nil {
-			return fmt.Errorf("failed to update Firebase: %w", err)
+			return fmt.Errorf("failed to publish cooking status: %w", err)
 		}
 	}
 
@@ -856,9 +737,12 @@ func (s *KDSService) SyncTodayMenuToFirebase(ctx context.Context, date time.Time
 		}
 	}
 
-	err = s.dbClient.NewRef(firebasePath).Set(ctx, firebaseData)
+	if s.publisher == nil {
+		return nil
+	}
+	err = s.publisher.Set(ctx, firebasePath, firebaseData)
 	if err != nil {
-		return fmt.Errorf("failed to sync to Firebase: %w", err)
+		return fmt.Errorf("failed to sync to realtime backend: %w", err)
 	}
 
 	return nil
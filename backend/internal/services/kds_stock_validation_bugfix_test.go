@@ -9,14 +9,13 @@ import (
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 // setupKDSBugfixTestDB creates an in-memory SQLite database for KDS bugfix testing
 func setupKDSBugfixTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+	db, err := openTestDB(t, ":memory:", &gorm.Config{
 		Logger: logger.Discard,
 	})
 	if err != nil {
@@ -31,6 +30,7 @@ func setupKDSBugfixTestDB(t *testing.T) *gorm.DB {
 		&models.RecipeItem{},
 		&models.SemiFinishedGoods{},
 		&models.SemiFinishedInventory{},
+		&models.SemiFinishedMovement{},
 		&models.InventoryMovement{},
 		&models.MenuPlan{},
 		&models.MenuItem{},
@@ -46,6 +46,7 @@ func setupKDSBugfixTestDB(t *testing.T) *gorm.DB {
 // cleanupKDSBugfixTestDB cleans up the test database
 func cleanupKDSBugfixTestDB(db *gorm.DB) {
 	db.Exec("DELETE FROM inventory_movements")
+	db.Exec("DELETE FROM semi_finished_movements")
 	db.Exec("DELETE FROM semi_finished_inventories")
 	db.Exec("DELETE FROM menu_item_school_allocations")
 	db.Exec("DELETE FROM menu_items")
@@ -78,10 +79,8 @@ func TestProperty1_BugCondition_StockValidationBeforeCooking(t *testing.T) {
 
 	// Create KDS service directly without Firebase (for testing)
 	kdsService := &KDSService{
-		db:                db,
-		firebaseApp:       nil,
-		dbClient:          nil,
-		monitoringService: nil,
+		db:        db,
+		publisher: nil,
 	}
 
 	properties := gopter.NewProperties(nil)
@@ -344,10 +343,8 @@ func TestProperty2_Preservation_NonCookingStatusUpdates(t *testing.T) {
 
 	// Create KDS service directly without Firebase (for testing)
 	kdsService := &KDSService{
-		db:                db,
-		firebaseApp:       nil,
-		dbClient:          nil,
-		monitoringService: nil,
+		db:        db,
+		publisher: nil,
 	}
 
 	properties := gopter.NewProperties(nil)
@@ -571,10 +568,8 @@ func TestProperty3_Preservation_MixedPortionSizes(t *testing.T) {
 
 	// Create KDS service directly without Firebase (for testing)
 	kdsService := &KDSService{
-		db:                db,
-		firebaseApp:       nil,
-		dbClient:          nil,
-		monitoringService: nil,
+		db:        db,
+		publisher: nil,
 	}
 
 	properties := gopter.NewProperties(nil)
@@ -762,10 +757,8 @@ func TestProperty4_Preservation_StatusSequence(t *testing.T) {
 
 	// Create KDS service directly without Firebase (for testing)
 	kdsService := &KDSService{
-		db:                db,
-		firebaseApp:       nil,
-		dbClient:          nil,
-		monitoringService: nil,
+		db:        db,
+		publisher: nil,
 	}
 
 	properties := gopter.NewProperties(nil)
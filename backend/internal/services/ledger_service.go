@@ -0,0 +1,1155 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAccountNotFound        = errors.New("akun tidak ditemukan")
+	ErrDuplicateAccountCode   = errors.New("kode akun sudah digunakan")
+	ErrInvalidAccountType     = errors.New("tipe akun tidak valid")
+	ErrSystemAccount          = errors.New("akun sistem tidak dapat dinonaktifkan")
+	ErrInactiveAccount        = errors.New("akun tidak aktif")
+	ErrJournalNotFound        = errors.New("jurnal tidak ditemukan")
+	ErrJournalTooFewLines     = errors.New("jurnal minimal memiliki dua baris")
+	ErrInvalidJournalLine     = errors.New("setiap baris jurnal harus berisi debit atau kredit lebih dari nol")
+	ErrUnbalancedJournal      = errors.New("total debit dan kredit jurnal tidak seimbang")
+	ErrJournalAlreadyReversed = errors.New("jurnal sudah dibatalkan")
+	ErrJournalNotReversible   = errors.New("jurnal pembalik tidak dapat dibatalkan")
+	ErrInvalidLedgerPeriod    = errors.New("periode tidak valid")
+)
+
+// Account types
+const (
+	AccountTypeAsset     = "asset"
+	AccountTypeLiability = "liability"
+	AccountTypeEquity    = "equity"
+	AccountTypeRevenue   = "revenue"
+	AccountTypeExpense   = "expense"
+)
+
+// Normal balance sides
+const (
+	NormalBalanceDebit  = "debit"
+	NormalBalanceCredit = "credit"
+)
+
+// Journal sources
+const (
+	JournalSourceManual           = "manual"
+	JournalSourceCashFlow         = "cash_flow"
+	JournalSourceAssetPurchase    = "asset_purchase"
+	JournalSourceAssetMaintenance = "asset_maintenance"
+	JournalSourceDepreciation     = "depreciation"
+	JournalSourceSupplierPayment  = "supplier_payment"
+	JournalSourceAssetDisposal    = "asset_disposal"
+	JournalSourceReversal         = "reversal"
+)
+
+// Journal statuses
+const (
+	JournalStatusPosted   = "posted"
+	JournalStatusReversed = "reversed"
+)
+
+// System account codes used by automatic postings
+const (
+	AccountCodeCash                    = "1-1100"
+	AccountCodeBank                    = "1-1200"
+	AccountCodeInventory               = "1-1300"
+	AccountCodeFixedAssets             = "1-2100"
+	AccountCodeAccumulatedDepreciation = "1-2900"
+	AccountCodeAccountsPayable         = "2-1100"
+	AccountCodeSalariesPayable         = "2-1200"
+	AccountCodeNetAssets               = "3-1000"
+	AccountCodeGovernmentFunding       = "4-1000"
+	AccountCodeOtherIncome             = "4-9000"
+	AccountCodeAssetDisposalGain       = "4-9100"
+	AccountCodeRawMaterialExpense      = "5-1000"
+	AccountCodeSalaryExpense           = "5-2000"
+	AccountCodeUtilityExpense          = "5-3000"
+	AccountCodeOperationalExpense      = "5-4000"
+	AccountCodeDepreciationExpense     = "5-5000"
+	AccountCodeMaintenanceExpense      = "5-6000"
+	AccountCodeAssetDisposalLoss       = "5-7000"
+)
+
+// DefaultAccounts is the chart of accounts created for a new installation
+var DefaultAccounts = []models.Account{
+	{Code: AccountCodeCash, Name: "Kas", Type: AccountTypeAsset, NormalBalance: NormalBalanceDebit, Category: "kas"},
+	{Code: AccountCodeBank, Name: "Bank", Type: AccountTypeAsset, NormalBalance: NormalBalanceDebit, Category: "kas"},
+	{Code: AccountCodeInventory, Name: "Persediaan Bahan Baku", Type: AccountTypeAsset, NormalBalance: NormalBalanceDebit, Category: "persediaan"},
+	{Code: AccountCodeFixedAssets, Name: "Aset Tetap - Peralatan Dapur", Type: AccountTypeAsset, NormalBalance: NormalBalanceDebit, Category: "aset_tetap"},
+	{Code: AccountCodeAccumulatedDepreciation, Name: "Akumulasi Penyusutan Peralatan Dapur", Type: AccountTypeAsset, NormalBalance: NormalBalanceCredit, Category: "aset_tetap"},
+	{Code: AccountCodeAccountsPayable, Name: "Utang Usaha", Type: AccountTypeLiability, NormalBalance: NormalBalanceCredit, Category: "utang"},
+	{Code: AccountCodeSalariesPayable, Name: "Utang Gaji", Type: AccountTypeLiability, NormalBalance: NormalBalanceCredit, Category: "utang"},
+	{Code: AccountCodeNetAssets, Name: "Aset Neto", Type: AccountTypeEquity, NormalBalance: NormalBalanceCredit, Category: "aset_neto"},
+	{Code: AccountCodeGovernmentFunding, Name: "Pendapatan Dana Pemerintah", Type: AccountTypeRevenue, NormalBalance: NormalBalanceCredit, Category: "dana_pemerintah"},
+	{Code: AccountCodeOtherIncome, Name: "Pendapatan Lain-lain", Type: AccountTypeRevenue, NormalBalance: NormalBalanceCredit, Category: "lainnya"},
+	{Code: AccountCodeAssetDisposalGain, Name: "Keuntungan Pelepasan Aset", Type: AccountTypeRevenue, NormalBalance: NormalBalanceCredit, Category: "pelepasan_aset"},
+	{Code: AccountCodeRawMaterialExpense, Name: "Beban Bahan Baku", Type: AccountTypeExpense, NormalBalance: NormalBalanceDebit, Category: "bahan_baku"},
+	{Code: AccountCodeSalaryExpense, Name: "Beban Gaji", Type: AccountTypeExpense, NormalBalance: NormalBalanceDebit, Category: "gaji"},
+	{Code: AccountCodeUtilityExpense, Name: "Beban Utilitas", Type: AccountTypeExpense, NormalBalance: NormalBalanceDebit, Category: "utilitas"},
+	{Code: AccountCodeOperationalExpense, Name: "Beban Operasional", Type: AccountTypeExpense, NormalBalance: NormalBalanceDebit, Category: "operasional"},
+	{Code: AccountCodeDepreciationExpense, Name: "Beban Penyusutan", Type: AccountTypeExpense, NormalBalance: NormalBalanceDebit, Category: "penyusutan"},
+	{Code: AccountCodeMaintenanceExpense, Name: "Beban Pemeliharaan Aset", Type: AccountTypeExpense, NormalBalance: NormalBalanceDebit, Category: "pemeliharaan"},
+	{Code: AccountCodeAssetDisposalLoss, Name: "Kerugian Pelepasan Aset", Type: AccountTypeExpense, NormalBalance: NormalBalanceDebit, Category: "pelepasan_aset"},
+}
+
+// cashFlowExpenseAccounts maps cash flow categories to their expense account
+var cashFlowExpenseAccounts = map[string]string{
+	"bahan_baku":  AccountCodeRawMaterialExpense,
+	"gaji":        AccountCodeSalaryExpense,
+	"utilitas":    AccountCodeUtilityExpense,
+	"operasional": AccountCodeOperationalExpense,
+}
+
+// LedgerService manages the chart of accounts, journal entries and ledger reports
+type LedgerService struct {
+	db *gorm.DB
+}
+
+// NewLedgerService creates a new ledger service
+func NewLedgerService(db *gorm.DB) *LedgerService {
+	return &LedgerService{
+		db: db,
+	}
+}
+
+// TrialBalanceRow represents one account of the trial balance
+type TrialBalanceRow struct {
+	AccountID     uint         `json:"account_id"`
+	Code          string       `json:"code"`
+	Name          string       `json:"name"`
+	Type          string       `json:"type"`
+	TotalDebit    models.Money `json:"total_debit"`
+	TotalCredit   models.Money `json:"total_credit"`
+	DebitBalance  models.Money `json:"debit_balance"`
+	CreditBalance models.Money `json:"credit_balance"`
+}
+
+// TrialBalance represents the trial balance at a date
+type TrialBalance struct {
+	AsOf        time.Time         `json:"as_of"`
+	Accounts    []TrialBalanceRow `json:"accounts"`
+	TotalDebit  models.Money      `json:"total_debit"`
+	TotalCredit models.Money      `json:"total_credit"`
+	Balanced    bool              `json:"balanced"`
+}
+
+// LedgerLine represents a posting in the general ledger of an account
+type LedgerLine struct {
+	JournalEntryID uint         `json:"journal_entry_id"`
+	EntryNumber    string       `json:"entry_number"`
+	Date           time.Time    `json:"date"`
+	Description    string       `json:"description"`
+	Reference      string       `json:"reference"`
+	SourceType     string       `json:"source_type"`
+	Debit          models.Money `json:"debit"`
+	Credit         models.Money `json:"credit"`
+	Balance        models.Money `json:"balance"`
+}
+
+// GeneralLedger represents the postings of one account in a period
+type GeneralLedger struct {
+	Account        models.Account `json:"account"`
+	StartDate      time.Time      `json:"start_date"`
+	EndDate        time.Time      `json:"end_date"`
+	OpeningBalance models.Money   `json:"opening_balance"`
+	Lines          []LedgerLine   `json:"lines"`
+	TotalDebit     models.Money   `json:"total_debit"`
+	TotalCredit    models.Money   `json:"total_credit"`
+	ClosingBalance models.Money   `json:"closing_balance"`
+}
+
+// StatementLine represents an account amount in the balance sheet or income statement
+type StatementLine struct {
+	AccountID uint         `json:"account_id"`
+	Code      string       `json:"code"`
+	Name      string       `json:"name"`
+	Category  string       `json:"category"`
+	Amount    models.Money `json:"amount"`
+}
+
+// BalanceSheet represents the financial position at a date. The surplus of
+// revenue over expense that has not been closed is shown as part of equity.
+type BalanceSheet struct {
+	AsOf                      time.Time       `json:"as_of"`
+	Assets                    []StatementLine `json:"assets"`
+	Liabilities               []StatementLine `json:"liabilities"`
+	Equity                    []StatementLine `json:"equity"`
+	CurrentSurplus            models.Money    `json:"current_surplus"`
+	TotalAssets               models.Money    `json:"total_assets"`
+	TotalLiabilities          models.Money    `json:"total_liabilities"`
+	TotalEquity               models.Money    `json:"total_equity"`
+	TotalLiabilitiesAndEquity models.Money    `json:"total_liabilities_and_equity"`
+	Balanced                  bool            `json:"balanced"`
+}
+
+// IncomeStatement represents revenue and expense of a period
+type IncomeStatement struct {
+	StartDate    time.Time       `json:"start_date"`
+	EndDate      time.Time       `json:"end_date"`
+	Revenues     []StatementLine `json:"revenues"`
+	Expenses     []StatementLine `json:"expenses"`
+	TotalRevenue models.Money    `json:"total_revenue"`
+	TotalExpense models.Money    `json:"total_expense"`
+	Surplus      models.Money    `json:"surplus"`
+}
+
+// LedgerCategoryTotal represents the expense of a reporting category
+type LedgerCategoryTotal struct {
+	Category string       `json:"category"`
+	Amount   models.Money `json:"amount"`
+	Count    int          `json:"count"`
+}
+
+// DepreciationRunResult represents the outcome of a monthly depreciation posting
+type DepreciationRunResult struct {
+	RunID       uint                  `json:"run_id,omitempty"` // zero when nothing was posted
+	Year        int                   `json:"year"`
+	Month       int                   `json:"month"`
+	Posted      int                   `json:"posted"`
+	Skipped     int                   `json:"skipped"`
+	TotalAmount models.Money          `json:"total_amount"`
+	Entries     []models.JournalEntry `json:"entries"`
+}
+
+// accountTotal holds the summed postings of an account
+type accountTotal struct {
+	AccountID uint
+	Debit     models.Money
+	Credit    models.Money
+}
+
+// SeedDefaultAccounts creates the default chart of accounts. Existing accounts
+// are left untouched so administrator changes survive a restart.
+func (s *LedgerService) SeedDefaultAccounts() error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, account := range DefaultAccounts {
+			if _, err := s.accountByCode(tx, account.Code); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SyncExistingRecords posts journals for cash flow entries and assets that were
+// recorded before the ledger existed. Assets are posted against net assets as
+// opening balances. Returns the number of journals created.
+func (s *LedgerService) SyncExistingRecords() (int, error) {
+	posted := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		cashFlowRefs, err := s.sourceRefs(tx, JournalSourceCashFlow)
+		if err != nil {
+			return err
+		}
+		var entries []models.CashFlowEntry
+		if err := tx.Order("date ASC, id ASC").Find(&entries).Error; err != nil {
+			return err
+		}
+		for i := range entries {
+			if cashFlowRefs[entries[i].TransactionID] {
+				continue
+			}
+			if err := s.PostCashFlowEntryWithTx(tx, &entries[i]); err != nil {
+				return err
+			}
+			posted++
+		}
+
+		assetRefs, err := s.sourceRefs(tx, JournalSourceAssetPurchase)
+		if err != nil {
+			return err
+		}
+		var assets []models.KitchenAsset
+		if err := tx.Order("purchase_date ASC, id ASC").Find(&assets).Error; err != nil {
+			return err
+		}
+		for i := range assets {
+			if assetRefs[strconv.FormatUint(uint64(assets[i].ID), 10)] || assets[i].PurchasePrice <= 0 {
+				continue
+			}
+			if err := s.PostAssetPurchaseWithTx(tx, &assets[i], AccountCodeNetAssets, 0); err != nil {
+				return err
+			}
+			posted++
+		}
+		return nil
+	})
+	return posted, err
+}
+
+// GetAccounts lists accounts ordered by code
+func (s *LedgerService) GetAccounts(accountType string, includeInactive bool) ([]models.Account, error) {
+	query := s.db.Model(&models.Account{})
+	if accountType != "" {
+		query = query.Where("type = ?", accountType)
+	}
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var accounts []models.Account
+	err := query.Order("code ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// GetAccount retrieves an account by ID
+func (s *LedgerService) GetAccount(id uint) (*models.Account, error) {
+	var account models.Account
+	if err := s.db.First(&account, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CreateAccount adds an account to the chart of accounts
+func (s *LedgerService) CreateAccount(account *models.Account, userID uint) error {
+	if !isValidAccountType(account.Type) {
+		return ErrInvalidAccountType
+	}
+	if account.NormalBalance == "" {
+		account.NormalBalance = defaultNormalBalance(account.Type)
+	}
+	if account.NormalBalance != NormalBalanceDebit && account.NormalBalance != NormalBalanceCredit {
+		return ErrInvalidAccountType
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Account{}).Where("code = ?", account.Code).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateAccountCode
+	}
+
+	account.ID = 0
+	account.IsSystem = false
+	account.IsActive = true
+	if err := s.db.Create(account).Error; err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "account", strconv.FormatUint(uint64(account.ID), 10), nil, account, "")
+	return nil
+}
+
+// UpdateAccount updates the name, description, category and active flag of an
+// account. Code, type and normal balance are fixed once journals may exist.
+func (s *LedgerService) UpdateAccount(id uint, name, description, category string, isActive bool, userID uint) (*models.Account, error) {
+	account, err := s.GetAccount(id)
+	if err != nil {
+		return nil, err
+	}
+	if account.IsSystem && !isActive {
+		return nil, ErrSystemAccount
+	}
+
+	old := *account
+	updates := map[string]interface{}{
+		"description": description,
+		"category":    category,
+		"is_active":   isActive,
+		"updated_at":  time.Now(),
+	}
+	if name != "" {
+		updates["name"] = name
+	}
+	if err := s.db.Model(account).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	updated, err := s.GetAccount(id)
+	if err != nil {
+		return nil, err
+	}
+	NewAuditTrailService(s.db).RecordAction(userID, "update", "account", strconv.FormatUint(uint64(id), 10), old, updated, "")
+	return updated, nil
+}
+
+// CreateJournalEntry posts a manual journal entry
+func (s *LedgerService) CreateJournalEntry(entry *models.JournalEntry, userID uint) error {
+	entry.SourceType = JournalSourceManual
+	entry.SourceRef = ""
+	entry.CreatedBy = userID
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.PostJournalWithTx(tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "journal_entry", strconv.FormatUint(uint64(entry.ID), 10), nil, entry, "")
+	return nil
+}
+
+// GetJournalEntry retrieves a journal entry with its lines
+func (s *LedgerService) GetJournalEntry(id uint) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	err := s.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Lines.Account").First(&entry, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJournalNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetJournalEntries lists journal entries with optional filters
+func (s *LedgerService) GetJournalEntries(sourceType string, startDate, endDate *time.Time, limit int) ([]models.JournalEntry, error) {
+	query := s.db.Preload("Lines").Preload("Lines.Account")
+	if sourceType != "" {
+		query = query.Where("source_type = ?", sourceType)
+	}
+	if startDate != nil {
+		query = query.Where("date >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("date < ?", nextDay(*endDate))
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var entries []models.JournalEntry
+	err := query.Order("date DESC, id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// ReverseJournalEntry cancels a journal entry by posting an entry with the
+// debit and credit lines swapped
+func (s *LedgerService) ReverseJournalEntry(id uint, reason string, userID uint) (*models.JournalEntry, error) {
+	var reversal *models.JournalEntry
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var original models.JournalEntry
+		if err := tx.Preload("Lines").First(&original, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrJournalNotFound
+			}
+			return err
+		}
+
+		var err error
+		reversal, err = s.reverseWithTx(tx, &original, time.Now(), reason, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "reverse", "journal_entry", strconv.FormatUint(uint64(id), 10), nil, reversal, "")
+	return reversal, nil
+}
+
+// PostJournalWithTx validates and posts a journal entry within a transaction.
+// Lines must reference active accounts and total debit must equal total credit.
+func (s *LedgerService) PostJournalWithTx(tx *gorm.DB, entry *models.JournalEntry) error {
+	if len(entry.Lines) < 2 {
+		return ErrJournalTooFewLines
+	}
+
+	var totalDebit, totalCredit models.Money
+	accountIDs := make([]uint, 0, len(entry.Lines))
+	for i := range entry.Lines {
+		line := &entry.Lines[i]
+		line.ID = 0
+		if line.Debit < 0 || line.Credit < 0 || (line.Debit > 0) == (line.Credit > 0) {
+			return ErrInvalidJournalLine
+		}
+		totalDebit += line.Debit
+		totalCredit += line.Credit
+		accountIDs = append(accountIDs, line.AccountID)
+	}
+	if totalDebit != totalCredit {
+		return ErrUnbalancedJournal
+	}
+
+	var accounts []models.Account
+	if err := tx.Where("id IN ?", accountIDs).Find(&accounts).Error; err != nil {
+		return err
+	}
+	active := make(map[uint]bool, len(accounts))
+	for _, account := range accounts {
+		active[account.ID] = account.IsActive
+	}
+	for _, id := range accountIDs {
+		isActive, ok := active[id]
+		if !ok {
+			return ErrAccountNotFound
+		}
+		if !isActive {
+			return ErrInactiveAccount
+		}
+	}
+
+	if entry.Date.IsZero() {
+		entry.Date = time.Now()
+	}
+	entryNumber, err := s.generateEntryNumber(tx, entry.Date)
+	if err != nil {
+		return err
+	}
+
+	entry.ID = 0
+	entry.EntryNumber = entryNumber
+	entry.Status = JournalStatusPosted
+	entry.TotalAmount = totalDebit
+	return tx.Create(entry).Error
+}
+
+// PostCashFlowEntryWithTx posts the journal of a cash flow entry. Expenses are
+// debited to the expense account of their category, income is recorded as
+// government funding, both against cash. Payable expenses are credited to
+// accounts payable instead. Entries already posted are skipped.
+func (s *LedgerService) PostCashFlowEntryWithTx(tx *gorm.DB, entry *models.CashFlowEntry) error {
+	posted, err := s.hasPostedSource(tx, JournalSourceCashFlow, entry.TransactionID)
+	if err != nil || posted {
+		return err
+	}
+
+	debitCode, creditCode := AccountCodeCash, AccountCodeGovernmentFunding
+	if entry.Type == "expense" {
+		debitCode, creditCode = expenseAccountCode(entry.Category), AccountCodeCash
+		if entry.IsPayable {
+			creditCode = AccountCodeAccountsPayable
+		}
+	}
+
+	description := entry.Description
+	if description == "" {
+		description = fmt.Sprintf("Arus kas %s %s", entry.Type, entry.Category)
+	}
+	return s.postAutomatic(tx, JournalSourceCashFlow, entry.TransactionID, entry.Reference, entry.Date, description,
+		debitCode, creditCode, entry.Amount, entry.CreatedBy)
+}
+
+// RepostCashFlowEntryWithTx reverses the current journal of a cash flow entry
+// and posts it again with the updated values
+func (s *LedgerService) RepostCashFlowEntryWithTx(tx *gorm.DB, entry *models.CashFlowEntry) error {
+	if err := s.ReverseSourceWithTx(tx, JournalSourceCashFlow, entry.TransactionID, "Koreksi arus kas "+entry.TransactionID, entry.CreatedBy); err != nil {
+		return err
+	}
+	return s.PostCashFlowEntryWithTx(tx, entry)
+}
+
+// PostAssetPurchaseWithTx posts the acquisition of an asset against the given
+// credit account (cash for purchases, net assets for opening balances)
+func (s *LedgerService) PostAssetPurchaseWithTx(tx *gorm.DB, asset *models.KitchenAsset, creditCode string, userID uint) error {
+	sourceRef := strconv.FormatUint(uint64(asset.ID), 10)
+	posted, err := s.hasPostedSource(tx, JournalSourceAssetPurchase, sourceRef)
+	if err != nil || posted || asset.PurchasePrice <= 0 {
+		return err
+	}
+
+	description := fmt.Sprintf("Pembelian aset %s (%s)", asset.Name, asset.AssetCode)
+	if creditCode == AccountCodeNetAssets {
+		description = fmt.Sprintf("Saldo awal aset %s (%s)", asset.Name, asset.AssetCode)
+	}
+	return s.postAutomatic(tx, JournalSourceAssetPurchase, sourceRef, asset.AssetCode, asset.PurchaseDate, description,
+		AccountCodeFixedAssets, creditCode, asset.PurchasePrice, userID)
+}
+
+// RepostAssetPurchaseWithTx reverses the acquisition journal of an asset and
+// posts it again, keeping the original credit account
+func (s *LedgerService) RepostAssetPurchaseWithTx(tx *gorm.DB, asset *models.KitchenAsset, userID uint) error {
+	sourceRef := strconv.FormatUint(uint64(asset.ID), 10)
+	creditCode := AccountCodeCash
+
+	var entry models.JournalEntry
+	err := tx.Preload("Lines.Account").
+		Where("source_type = ? AND source_ref = ? AND status = ?", JournalSourceAssetPurchase, sourceRef, JournalStatusPosted).
+		First(&entry).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	for _, line := range entry.Lines {
+		if line.Credit > 0 {
+			creditCode = line.Account.Code
+		}
+	}
+
+	if err := s.ReverseSourceWithTx(tx, JournalSourceAssetPurchase, sourceRef, "Koreksi aset "+asset.AssetCode, userID); err != nil {
+		return err
+	}
+	return s.PostAssetPurchaseWithTx(tx, asset, creditCode, userID)
+}
+
+// PostAssetMaintenanceWithTx posts a paid maintenance activity as an expense
+func (s *LedgerService) PostAssetMaintenanceWithTx(tx *gorm.DB, maintenance *models.AssetMaintenance, asset *models.KitchenAsset, userID uint) error {
+	if maintenance.Cost <= 0 {
+		return nil
+	}
+	description := fmt.Sprintf("Pemeliharaan aset %s (%s)", asset.Name, asset.AssetCode)
+	if maintenance.Description != "" {
+		description += ": " + maintenance.Description
+	}
+	return s.postAutomatic(tx, JournalSourceAssetMaintenance, strconv.FormatUint(uint64(maintenance.ID), 10), asset.AssetCode,
+		maintenance.MaintenanceDate, description, AccountCodeMaintenanceExpense, AccountCodeCash, maintenance.Cost, userID)
+}
+
+// PostSupplierPaymentWithTx posts a supplier payment against accounts payable,
+// paid from the bank for transfers and from cash otherwise
+func (s *LedgerService) PostSupplierPaymentWithTx(tx *gorm.DB, payment *models.SupplierPayment, invoiceNumber string) error {
+	creditCode := AccountCodeCash
+	if payment.Method == "transfer" {
+		creditCode = AccountCodeBank
+	}
+	description := fmt.Sprintf("Pembayaran faktur supplier %s", invoiceNumber)
+	if payment.BankReference != "" {
+		description += " (ref. " + payment.BankReference + ")"
+	}
+	return s.postAutomatic(tx, JournalSourceSupplierPayment, payment.PaymentNumber, invoiceNumber, payment.PaymentDate, description,
+		AccountCodeAccountsPayable, creditCode, payment.Amount, payment.CreatedBy)
+}
+
+// PostAssetDisposalWithTx posts the disposal or sale of an asset: the cost and
+// accumulated depreciation are removed, the proceeds received in cash and the
+// difference with the book value booked as a gain or loss
+func (s *LedgerService) PostAssetDisposalWithTx(tx *gorm.DB, asset *models.KitchenAsset, date time.Time, accumulated, proceeds models.Money, userID uint) error {
+	if asset.PurchasePrice <= 0 {
+		return nil
+	}
+
+	accountIDs := make(map[string]uint)
+	for _, code := range []string{AccountCodeCash, AccountCodeAccumulatedDepreciation, AccountCodeFixedAssets,
+		AccountCodeAssetDisposalGain, AccountCodeAssetDisposalLoss} {
+		account, err := s.accountByCode(tx, code)
+		if err != nil {
+			return err
+		}
+		accountIDs[code] = account.ID
+	}
+
+	description := fmt.Sprintf("Pelepasan aset %s (%s)", asset.Name, asset.AssetCode)
+	if proceeds > 0 {
+		description = fmt.Sprintf("Penjualan aset %s (%s)", asset.Name, asset.AssetCode)
+	}
+	entry := &models.JournalEntry{
+		Date:        date,
+		Description: description,
+		SourceType:  JournalSourceAssetDisposal,
+		SourceRef:   strconv.FormatUint(uint64(asset.ID), 10),
+		Reference:   asset.AssetCode,
+		CreatedBy:   userID,
+	}
+	if proceeds > 0 {
+		entry.Lines = append(entry.Lines, models.JournalLine{AccountID: accountIDs[AccountCodeCash], Debit: proceeds})
+	}
+	if accumulated > 0 {
+		entry.Lines = append(entry.Lines, models.JournalLine{AccountID: accountIDs[AccountCodeAccumulatedDepreciation], Debit: accumulated})
+	}
+	switch gainLoss := proceeds + accumulated - asset.PurchasePrice; {
+	case gainLoss > 0:
+		entry.Lines = append(entry.Lines, models.JournalLine{AccountID: accountIDs[AccountCodeAssetDisposalGain], Credit: gainLoss})
+	case gainLoss < 0:
+		entry.Lines = append(entry.Lines, models.JournalLine{AccountID: accountIDs[AccountCodeAssetDisposalLoss], Debit: -gainLoss})
+	}
+	entry.Lines = append(entry.Lines, models.JournalLine{AccountID: accountIDs[AccountCodeFixedAssets], Credit: asset.PurchasePrice})
+	return s.PostJournalWithTx(tx, entry)
+}
+
+// PostDepreciation posts the depreciation of every active asset for a month
+// using the asset's depreciation method. See AssetService.RunDepreciation.
+func (s *LedgerService) PostDepreciation(year, month int, userID uint) (*DepreciationRunResult, error) {
+	return NewAssetService(s.db).RunDepreciation(year, month, userID)
+}
+
+// ReverseSourceWithTx reverses every posted journal of an automatic source
+func (s *LedgerService) ReverseSourceWithTx(tx *gorm.DB, sourceType, sourceRef, description string, userID uint) error {
+	var entries []models.JournalEntry
+	if err := tx.Preload("Lines").
+		Where("source_type = ? AND source_ref = ? AND status = ?", sourceType, sourceRef, JournalStatusPosted).
+		Find(&entries).Error; err != nil {
+		return err
+	}
+	for i := range entries {
+		if _, err := s.reverseWithTx(tx, &entries[i], time.Now(), description, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTrialBalance returns the balance of every account up to and including asOf
+func (s *LedgerService) GetTrialBalance(asOf time.Time) (*TrialBalance, error) {
+	accounts, totals, err := s.accountTotals(nil, nextDay(asOf))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TrialBalance{AsOf: asOf, Accounts: []TrialBalanceRow{}}
+	for _, account := range accounts {
+		total, ok := totals[account.ID]
+		if !ok {
+			continue
+		}
+		row := TrialBalanceRow{
+			AccountID:   account.ID,
+			Code:        account.Code,
+			Name:        account.Name,
+			Type:        account.Type,
+			TotalDebit:  total.Debit,
+			TotalCredit: total.Credit,
+		}
+		if net := total.Debit - total.Credit; net >= 0 {
+			row.DebitBalance = net
+		} else {
+			row.CreditBalance = -net
+		}
+		report.Accounts = append(report.Accounts, row)
+		report.TotalDebit += row.DebitBalance
+		report.TotalCredit += row.CreditBalance
+	}
+	report.Balanced = report.TotalDebit == report.TotalCredit
+	return report, nil
+}
+
+// GetGeneralLedger returns the postings of an account between two dates
+// (inclusive) with the running balance on the account's normal side
+func (s *LedgerService) GetGeneralLedger(accountID uint, startDate, endDate time.Time) (*GeneralLedger, error) {
+	if endDate.Before(startDate) {
+		return nil, ErrInvalidDateRange
+	}
+	account, err := s.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var opening accountTotal
+	if err := s.db.Table("journal_lines").
+		Select("COALESCE(SUM(journal_lines.debit), 0) AS debit, COALESCE(SUM(journal_lines.credit), 0) AS credit").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Where("journal_lines.account_id = ? AND journal_entries.date < ?", accountID, startOfDay(startDate)).
+		Scan(&opening).Error; err != nil {
+		return nil, err
+	}
+
+	var lines []LedgerLine
+	if err := s.db.Table("journal_lines").
+		Select("journal_entries.id AS journal_entry_id, journal_entries.entry_number, journal_entries.date, "+
+			"COALESCE(NULLIF(journal_lines.description, ''), journal_entries.description) AS description, "+
+			"journal_entries.reference, journal_entries.source_type, journal_lines.debit, journal_lines.credit").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Where("journal_lines.account_id = ? AND journal_entries.date >= ? AND journal_entries.date < ?",
+			accountID, startOfDay(startDate), nextDay(endDate)).
+		Order("journal_entries.date ASC, journal_entries.id ASC, journal_lines.id ASC").
+		Scan(&lines).Error; err != nil {
+		return nil, err
+	}
+
+	ledger := &GeneralLedger{
+		Account:        *account,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		OpeningBalance: signedBalance(account.NormalBalance, opening.Debit, opening.Credit),
+		Lines:          []LedgerLine{},
+	}
+	balance := ledger.OpeningBalance
+	for _, line := range lines {
+		balance = balance + signedBalance(account.NormalBalance, line.Debit, line.Credit)
+		line.Balance = balance
+		ledger.TotalDebit += line.Debit
+		ledger.TotalCredit += line.Credit
+		ledger.Lines = append(ledger.Lines, line)
+	}
+	ledger.ClosingBalance = balance
+	return ledger, nil
+}
+
+// GetBalanceSheet returns assets, liabilities and equity up to and including asOf
+func (s *LedgerService) GetBalanceSheet(asOf time.Time) (*BalanceSheet, error) {
+	accounts, totals, err := s.accountTotals(nil, nextDay(asOf))
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &BalanceSheet{AsOf: asOf, Assets: []StatementLine{}, Liabilities: []StatementLine{}, Equity: []StatementLine{}}
+	for _, account := range accounts {
+		total, ok := totals[account.ID]
+		if !ok {
+			continue
+		}
+		line := StatementLine{AccountID: account.ID, Code: account.Code, Name: account.Name, Category: account.Category}
+
+		switch account.Type {
+		case AccountTypeAsset:
+			// Contra assets such as accumulated depreciation show as negative amounts
+			line.Amount = total.Debit - total.Credit
+			sheet.Assets = appendNonZero(sheet.Assets, line)
+			sheet.TotalAssets += line.Amount
+		case AccountTypeLiability:
+			line.Amount = total.Credit - total.Debit
+			sheet.Liabilities = appendNonZero(sheet.Liabilities, line)
+			sheet.TotalLiabilities += line.Amount
+		case AccountTypeEquity:
+			line.Amount = total.Credit - total.Debit
+			sheet.Equity = appendNonZero(sheet.Equity, line)
+			sheet.TotalEquity += line.Amount
+		case AccountTypeRevenue:
+			sheet.CurrentSurplus += total.Credit - total.Debit
+		case AccountTypeExpense:
+			sheet.CurrentSurplus -= total.Debit - total.Credit
+		}
+	}
+
+	sheet.TotalEquity = sheet.TotalEquity + sheet.CurrentSurplus
+	sheet.TotalLiabilitiesAndEquity = sheet.TotalLiabilities + sheet.TotalEquity
+	sheet.Balanced = sheet.TotalAssets == sheet.TotalLiabilitiesAndEquity
+	return sheet, nil
+}
+
+// GetIncomeStatement returns revenue and expense between two dates (inclusive)
+func (s *LedgerService) GetIncomeStatement(startDate, endDate time.Time) (*IncomeStatement, error) {
+	if endDate.Before(startDate) {
+		return nil, ErrInvalidDateRange
+	}
+	start := startOfDay(startDate)
+	accounts, totals, err := s.accountTotals(&start, nextDay(endDate))
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &IncomeStatement{StartDate: startDate, EndDate: endDate, Revenues: []StatementLine{}, Expenses: []StatementLine{}}
+	for _, account := range accounts {
+		total, ok := totals[account.ID]
+		if !ok {
+			continue
+		}
+		line := StatementLine{AccountID: account.ID, Code: account.Code, Name: account.Name, Category: account.Category}
+
+		switch account.Type {
+		case AccountTypeRevenue:
+			line.Amount = total.Credit - total.Debit
+			statement.Revenues = appendNonZero(statement.Revenues, line)
+			statement.TotalRevenue += line.Amount
+		case AccountTypeExpense:
+			line.Amount = total.Debit - total.Credit
+			statement.Expenses = appendNonZero(statement.Expenses, line)
+			statement.TotalExpense += line.Amount
+		}
+	}
+
+	statement.Surplus = statement.TotalRevenue - statement.TotalExpense
+	return statement, nil
+}
+
+// GetExpensesByCategory totals the expense accounts per reporting category
+// between two dates (inclusive), largest first
+func (s *LedgerService) GetExpensesByCategory(startDate, endDate time.Time) ([]LedgerCategoryTotal, error) {
+	var rows []struct {
+		Category string
+		Code     string
+		Amount   models.Money
+		Count    int64
+	}
+	err := s.db.Table("journal_lines").
+		Select("accounts.category, accounts.code, SUM(journal_lines.debit - journal_lines.credit) AS amount, COUNT(*) AS count").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Joins("JOIN accounts ON accounts.id = journal_lines.account_id").
+		Where("accounts.type = ? AND journal_entries.date >= ? AND journal_entries.date < ?",
+			AccountTypeExpense, startOfDay(startDate), nextDay(endDate)).
+		Group("accounts.category, accounts.code").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[string]*LedgerCategoryTotal)
+	var order []string
+	for _, row := range rows {
+		category := row.Category
+		if category == "" {
+			category = row.Code
+		}
+		total, ok := byCategory[category]
+		if !ok {
+			total = &LedgerCategoryTotal{Category: category}
+			byCategory[category] = total
+			order = append(order, category)
+		}
+		total.Amount += row.Amount
+		total.Count += int(row.Count)
+	}
+
+	result := make([]LedgerCategoryTotal, 0, len(order))
+	for _, category := range order {
+		total := byCategory[category]
+		if total.Amount == 0 {
+			continue
+		}
+		result = append(result, *total)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Amount > result[j].Amount
+	})
+	return result, nil
+}
+
+// postAutomatic posts a two-line journal for an automatic source
+func (s *LedgerService) postAutomatic(tx *gorm.DB, sourceType, sourceRef, reference string, date time.Time, description, debitCode, creditCode string, amount models.Money, userID uint) error {
+	debitAccount, err := s.accountByCode(tx, debitCode)
+	if err != nil {
+		return err
+	}
+	creditAccount, err := s.accountByCode(tx, creditCode)
+	if err != nil {
+		return err
+	}
+
+	entry := &models.JournalEntry{
+		Date:        date,
+		Description: description,
+		SourceType:  sourceType,
+		SourceRef:   sourceRef,
+		Reference:   reference,
+		CreatedBy:   userID,
+		Lines: []models.JournalLine{
+			{AccountID: debitAccount.ID, Debit: amount},
+			{AccountID: creditAccount.ID, Credit: amount},
+		},
+	}
+	return s.PostJournalWithTx(tx, entry)
+}
+
+// reverseWithTx posts the reversal of a journal entry and marks it reversed
+func (s *LedgerService) reverseWithTx(tx *gorm.DB, original *models.JournalEntry, date time.Time, reason string, userID uint) (*models.JournalEntry, error) {
+	if original.Status == JournalStatusReversed {
+		return nil, ErrJournalAlreadyReversed
+	}
+	if original.SourceType == JournalSourceReversal {
+		return nil, ErrJournalNotReversible
+	}
+	if len(original.Lines) == 0 {
+		if err := tx.Where("journal_entry_id = ?", original.ID).Find(&original.Lines).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	description := "Pembatalan " + original.EntryNumber
+	if reason != "" {
+		description += ": " + reason
+	}
+	originalID := original.ID
+	reversal := &models.JournalEntry{
+		Date:         date,
+		Description:  description,
+		SourceType:   JournalSourceReversal,
+		SourceRef:    original.EntryNumber,
+		Reference:    original.Reference,
+		ReversalOfID: &originalID,
+		CreatedBy:    userID,
+	}
+	for _, line := range original.Lines {
+		reversal.Lines = append(reversal.Lines, models.JournalLine{
+			AccountID:   line.AccountID,
+			Debit:       line.Credit,
+			Credit:      line.Debit,
+			Description: line.Description,
+		})
+	}
+
+	if err := s.postReversalWithTx(tx, reversal); err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.JournalEntry{}).Where("id = ?", original.ID).
+		Update("status", JournalStatusReversed).Error; err != nil {
+		return nil, err
+	}
+	original.Status = JournalStatusReversed
+	return reversal, nil
+}
+
+// postReversalWithTx posts a reversal without the active account check so
+// journals on accounts deactivated later can still be cancelled
+func (s *LedgerService) postReversalWithTx(tx *gorm.DB, entry *models.JournalEntry) error {
+	var total models.Money
+	for _, line := range entry.Lines {
+		total += line.Debit
+	}
+	entryNumber, err := s.generateEntryNumber(tx, entry.Date)
+	if err != nil {
+		return err
+	}
+	entry.EntryNumber = entryNumber
+	entry.Status = JournalStatusPosted
+	entry.TotalAmount = total
+	return tx.Create(entry).Error
+}
+
+// accountByCode returns an account by code, creating it when it is one of the
+// default accounts that has not been seeded yet
+func (s *LedgerService) accountByCode(tx *gorm.DB, code string) (*models.Account, error) {
+	var account models.Account
+	err := tx.Where("code = ?", code).First(&account).Error
+	if err == nil {
+		return &account, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	for _, defaultAccount := range DefaultAccounts {
+		if defaultAccount.Code != code {
+			continue
+		}
+		account = defaultAccount
+		account.IsSystem = true
+		account.IsActive = true
+		if err := tx.Create(&account).Error; err != nil {
+			return nil, err
+		}
+		return &account, nil
+	}
+	return nil, ErrAccountNotFound
+}
+
+// accountTotals sums the postings of every account in [start, end)
+func (s *LedgerService) accountTotals(start *time.Time, end time.Time) ([]models.Account, map[uint]accountTotal, error) {
+	var accounts []models.Account
+	if err := s.db.Order("code ASC").Find(&accounts).Error; err != nil {
+		return nil, nil, err
+	}
+
+	query := s.db.Table("journal_lines").
+		Select("journal_lines.account_id, SUM(journal_lines.debit) AS debit, SUM(journal_lines.credit) AS credit").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Where("journal_entries.date < ?", end)
+	if start != nil {
+		query = query.Where("journal_entries.date >= ?", *start)
+	}
+
+	var rows []accountTotal
+	if err := query.Group("journal_lines.account_id").Scan(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	totals := make(map[uint]accountTotal, len(rows))
+	for _, row := range rows {
+		totals[row.AccountID] = row
+	}
+	return accounts, totals, nil
+}
+
+// hasPostedSource checks whether an automatic source already has a posted journal
+func (s *LedgerService) hasPostedSource(tx *gorm.DB, sourceType, sourceRef string) (bool, error) {
+	var count int64
+	err := tx.Model(&models.JournalEntry{}).
+		Where("source_type = ? AND source_ref = ? AND status = ?", sourceType, sourceRef, JournalStatusPosted).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// sourceRefs returns the references of every journal of a source type
+func (s *LedgerService) sourceRefs(tx *gorm.DB, sourceType string) (map[string]bool, error) {
+	var refs []string
+	if err := tx.Model(&models.JournalEntry{}).Where("source_type = ?", sourceType).Pluck("source_ref", &refs).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		result[ref] = true
+	}
+	return result, nil
+}
+
+// generateEntryNumber generates a journal number in the format JU-YYYYMMDD-XXXX
+func (s *LedgerService) generateEntryNumber(tx *gorm.DB, date time.Time) (string, error) {
+	prefix := fmt.Sprintf("JU-%s-", date.Format("20060102"))
+
+	var count int64
+	if err := tx.Model(&models.JournalEntry{}).Where("entry_number LIKE ?", prefix+"%").Count(&count).Error; err != nil {
+		return "", err
+	}
+	for {
+		count++
+		entryNumber := fmt.Sprintf("%s%04d", prefix, count)
+		var existing int64
+		if err := tx.Model(&models.JournalEntry{}).Where("entry_number = ?", entryNumber).Count(&existing).Error; err != nil {
+			return "", err
+		}
+		if existing == 0 {
+			return entryNumber, nil
+		}
+	}
+}
+
+// expenseAccountCode returns the expense account of a cash flow category
+func expenseAccountCode(category string) string {
+	if code, ok := cashFlowExpenseAccounts[category]; ok {
+		return code
+	}
+	return AccountCodeOperationalExpense
+}
+
+// isValidAccountType checks an account type
+func isValidAccountType(accountType string) bool {
+	switch accountType {
+	case AccountTypeAsset, AccountTypeLiability, AccountTypeEquity, AccountTypeRevenue, AccountTypeExpense:
+		return true
+	}
+	return false
+}
+
+// defaultNormalBalance returns the usual balance side of an account type
+func defaultNormalBalance(accountType string) string {
+	if accountType == AccountTypeAsset || accountType == AccountTypeExpense {
+		return NormalBalanceDebit
+	}
+	return NormalBalanceCredit
+}
+
+// signedBalance returns debit minus credit, negated for credit-normal accounts
+func signedBalance(normalBalance string, debit, credit models.Money) models.Money {
+	if normalBalance == NormalBalanceCredit {
+		return credit - debit
+	}
+	return debit - credit
+}
+
+// appendNonZero appends a statement line unless its amount is zero
+func appendNonZero(lines []StatementLine, line StatementLine) []StatementLine {
+	if line.Amount == 0 {
+		return lines
+	}
+	return append(lines, line)
+}
+
+// roundPercent rounds a percentage or ratio to two decimals
+func roundPercent(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// startOfDay returns midnight of the given day
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextDay returns midnight of the day after t, the exclusive end of an inclusive date range
+func nextDay(t time.Time) time.Time {
+	return startOfDay(t).AddDate(0, 0, 1)
+}
+
+// localDayRange returns the local midnights that start the calendar day of t
+// and the day after it, for filters like "col >= ? AND col < ?". Date-only
+// values parsed as UTC keep their calendar day.
+func localDayRange(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	return start, start.AddDate(0, 0, 1)
+}
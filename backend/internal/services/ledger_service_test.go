@@ -0,0 +1,308 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupLedgerTestDB(t *testing.T) *gorm.DB {
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.CashFlowEntry{}, &models.KitchenAsset{}, &models.AssetMaintenance{},
+		&models.AssetUsageLog{}, &models.MaintenancePlan{}, &models.DepreciationRun{}, &models.DepreciationRunLine{}, &models.BudgetTarget{},
+		&models.Account{}, &models.JournalEntry{}, &models.JournalLine{}, &models.AuditTrail{})
+	require.NoError(t, err)
+
+	require.NoError(t, NewLedgerService(db).SeedDefaultAccounts())
+	return db
+}
+
+func ledgerAccountID(t *testing.T, db *gorm.DB, code string) uint {
+	var account models.Account
+	require.NoError(t, db.Where("code = ?", code).First(&account).Error)
+	return account.ID
+}
+
+func ledgerBalance(t *testing.T, service *LedgerService, code string) models.Money {
+	sheet, err := service.GetTrialBalance(time.Date(2100, 1, 1, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	for _, row := range sheet.Accounts {
+		if row.Code == code {
+			return row.DebitBalance - row.CreditBalance
+		}
+	}
+	return 0
+}
+
+func TestLedgerService_CashFlowEntriesPostJournals(t *testing.T) {
+	db := setupLedgerTestDB(t)
+	ledger := NewLedgerService(db)
+	cashFlowService := NewCashFlowService(db)
+
+	date := time.Date(2026, 2, 3, 10, 0, 0, 0, time.Local)
+	income := &models.CashFlowEntry{TransactionID: "TXN-TEST-0001", Date: date, Category: "operasional", Type: "income", Amount: models.Rupiah(50000000), Description: "Pencairan dana tahap 1", CreatedBy: 1}
+	require.NoError(t, cashFlowService.CreateCashFlowEntry(income))
+	expense := &models.CashFlowEntry{TransactionID: "TXN-TEST-0002", Date: date, Category: "bahan_baku", Type: "expense", Amount: models.Rupiah(7500000), Reference: "GRN-001", CreatedBy: 1}
+	require.NoError(t, cashFlowService.CreateCashFlowEntry(expense))
+	payroll := &models.CashFlowEntry{TransactionID: "TXN-TEST-0003", Date: date, Category: "gaji", Type: "expense", Amount: models.Rupiah(12000000), CreatedBy: 1}
+	require.NoError(t, cashFlowService.CreateCashFlowEntry(payroll))
+
+	entries, err := ledger.GetJournalEntries(JournalSourceCashFlow, nil, nil, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	for _, entry := range entries {
+		require.Len(t, entry.Lines, 2)
+		assert.Equal(t, entry.Lines[0].Debit+entry.Lines[1].Debit, entry.Lines[0].Credit+entry.Lines[1].Credit)
+	}
+
+	assert.Equal(t, models.Rupiah(30500000), ledgerBalance(t, ledger, AccountCodeCash))
+	assert.Equal(t, models.Rupiah(-50000000), ledgerBalance(t, ledger, AccountCodeGovernmentFunding))
+	assert.Equal(t, models.Rupiah(7500000), ledgerBalance(t, ledger, AccountCodeRawMaterialExpense))
+	assert.Equal(t, models.Rupiah(12000000), ledgerBalance(t, ledger, AccountCodeSalaryExpense))
+
+	trial, err := ledger.GetTrialBalance(date)
+	require.NoError(t, err)
+	assert.True(t, trial.Balanced)
+	assert.Equal(t, models.Rupiah(50000000), trial.TotalDebit)
+
+	// Updating an entry reverses the old journal and posts a corrected one
+	expense.Amount = models.Rupiah(8000000)
+	require.NoError(t, cashFlowService.UpdateCashFlowEntry(expense.ID, expense))
+	assert.Equal(t, models.Rupiah(8000000), ledgerBalance(t, ledger, AccountCodeRawMaterialExpense))
+	var reversals int64
+	db.Model(&models.JournalEntry{}).Where("source_type = ?", JournalSourceReversal).Count(&reversals)
+	assert.Equal(t, int64(1), reversals)
+
+	// Deleting an entry cancels its journal
+	require.NoError(t, cashFlowService.DeleteCashFlowEntry(payroll.ID))
+	assert.Equal(t, models.Rupiah(0), ledgerBalance(t, ledger, AccountCodeSalaryExpense))
+	assert.Equal(t, models.Rupiah(42000000), ledgerBalance(t, ledger, AccountCodeCash))
+
+	glStart := time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local)
+	gl, err := ledger.GetGeneralLedger(ledgerAccountID(t, db, AccountCodeRawMaterialExpense), glStart, time.Date(2100, 1, 1, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Len(t, gl.Lines, 3)
+	assert.Equal(t, models.Rupiah(0), gl.OpeningBalance)
+	assert.Equal(t, models.Rupiah(8000000), gl.ClosingBalance)
+	assert.Equal(t, "GRN-001", gl.Lines[0].Reference)
+}
+
+func TestLedgerService_ReportTotalsEqualSumOfLines(t *testing.T) {
+	db := setupLedgerTestDB(t)
+	ledger := NewLedgerService(db)
+	cashFlowService := NewCashFlowService(db)
+
+	// Amounts with sen that do not add up exactly as float rupiah
+	date := time.Date(2026, 3, 4, 9, 0, 0, 0, time.Local)
+	lines := []struct {
+		category  string
+		entryType string
+		amount    string
+	}{
+		{"operasional", "income", "10000000.10"},
+		{"operasional", "income", "0.20"},
+		{"bahan_baku", "expense", "1234567.89"},
+		{"bahan_baku", "expense", "0.10"},
+		{"bahan_baku", "expense", "0.20"},
+		{"gaji", "expense", "2999999.99"},
+		{"utilitas", "expense", "333333.33"},
+		{"utilitas", "expense", "333333.33"},
+		{"utilitas", "expense", "333333.34"},
+	}
+	var income, expense models.Money
+	byCategory := map[string]models.Money{}
+	for i, line := range lines {
+		amount, err := models.ParseMoney(line.amount)
+		require.NoError(t, err)
+		require.NoError(t, cashFlowService.CreateCashFlowEntry(&models.CashFlowEntry{
+			TransactionID: fmt.Sprintf("TXN-SUM-%04d", i+1), Date: date, Category: line.category, Type: line.entryType, Amount: amount, CreatedBy: 1,
+		}))
+		if line.entryType == "income" {
+			income += amount
+		} else {
+			expense += amount
+			byCategory[line.category] += amount
+		}
+	}
+	assert.Equal(t, models.NewMoney(10000000.3), income)
+	assert.Equal(t, models.NewMoney(5234568.18), expense)
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(2026, 3, 31, 23, 59, 59, 0, time.Local)
+	summary, err := cashFlowService.GetCashFlowSummary(start, end)
+	require.NoError(t, err)
+	assert.Equal(t, income, summary.TotalIncome)
+	assert.Equal(t, expense, summary.TotalExpense)
+	assert.Equal(t, income-expense, summary.NetCashFlow)
+
+	trial, err := ledger.GetTrialBalance(end)
+	require.NoError(t, err)
+	var trialDebit, trialCredit models.Money
+	for _, row := range trial.Accounts {
+		trialDebit += row.DebitBalance
+		trialCredit += row.CreditBalance
+	}
+	assert.True(t, trial.Balanced)
+	assert.Equal(t, trialDebit, trial.TotalDebit)
+	assert.Equal(t, trialCredit, trial.TotalCredit)
+	assert.Equal(t, income, trial.TotalDebit)
+
+	statement, err := ledger.GetIncomeStatement(start, end)
+	require.NoError(t, err)
+	var revenue, expenses models.Money
+	for _, line := range statement.Revenues {
+		revenue += line.Amount
+	}
+	for _, line := range statement.Expenses {
+		expenses += line.Amount
+		assert.Equal(t, byCategory[line.Category], line.Amount, line.Code)
+	}
+	assert.Equal(t, revenue, statement.TotalRevenue)
+	assert.Equal(t, expenses, statement.TotalExpense)
+	assert.Equal(t, income, statement.TotalRevenue)
+	assert.Equal(t, expense, statement.TotalExpense)
+	assert.Equal(t, income-expense, statement.Surplus)
+
+	sheet, err := ledger.GetBalanceSheet(end)
+	require.NoError(t, err)
+	assert.True(t, sheet.Balanced)
+	assert.Equal(t, income-expense, sheet.CurrentSurplus)
+}
+
+func TestLedgerService_AssetsDepreciationAndBalanceSheet(t *testing.T) {
+	db := setupLedgerTestDB(t)
+	ledger := NewLedgerService(db)
+	assetService := NewAssetService(db)
+
+	funding := &models.JournalEntry{
+		Date:        time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local),
+		Description: "Pencairan dana pemerintah",
+		Lines: []models.JournalLine{
+			{AccountID: ledgerAccountID(t, db, AccountCodeCash), Debit: models.Rupiah(20000000)},
+			{AccountID: ledgerAccountID(t, db, AccountCodeGovernmentFunding), Credit: models.Rupiah(20000000)},
+		},
+	}
+	require.NoError(t, ledger.CreateJournalEntry(funding, 1))
+
+	unbalanced := &models.JournalEntry{
+		Date: time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local),
+		Lines: []models.JournalLine{
+			{AccountID: ledgerAccountID(t, db, AccountCodeCash), Debit: models.Rupiah(100)},
+			{AccountID: ledgerAccountID(t, db, AccountCodeOtherIncome), Credit: models.Rupiah(90)},
+		},
+	}
+	assert.ErrorIs(t, ledger.CreateJournalEntry(unbalanced, 1), ErrUnbalancedJournal)
+
+	asset := &models.KitchenAsset{
+		AssetCode:        "AST-001",
+		Name:             "Kompor Industri",
+		PurchaseDate:     time.Date(2026, 1, 15, 0, 0, 0, 0, time.Local),
+		PurchasePrice:    models.Rupiah(12000000),
+		DepreciationRate: 10,
+		Condition:        "good",
+	}
+	require.NoError(t, assetService.CreateAsset(asset))
+	assert.Equal(t, models.Rupiah(12000000), ledgerBalance(t, ledger, AccountCodeFixedAssets))
+	assert.Equal(t, models.Rupiah(8000000), ledgerBalance(t, ledger, AccountCodeCash))
+
+	require.NoError(t, assetService.AddMaintenanceRecord(asset.ID, &models.AssetMaintenance{
+		MaintenanceDate: time.Date(2026, 1, 20, 0, 0, 0, 0, time.Local),
+		Description:     "Ganti burner",
+		Cost:            models.Rupiah(500000),
+	}))
+	assert.Equal(t, models.Rupiah(500000), ledgerBalance(t, ledger, AccountCodeMaintenanceExpense))
+
+	result, err := ledger.PostDepreciation(2026, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Posted)
+	assert.Equal(t, models.Rupiah(100000), result.TotalAmount)
+
+	// Running the same month again does not post twice
+	result, err = ledger.PostDepreciation(2026, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Posted)
+	assert.Equal(t, 1, result.Skipped)
+
+	// Depreciation is not posted before the purchase month
+	result, err = ledger.PostDepreciation(2025, 12, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Posted+result.Skipped)
+
+	sheet, err := ledger.GetBalanceSheet(time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.True(t, sheet.Balanced)
+	assert.Equal(t, models.Rupiah(19400000), sheet.TotalAssets)
+	assert.Equal(t, models.Rupiah(19400000), sheet.CurrentSurplus)
+
+	statement, err := ledger.GetIncomeStatement(time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(20000000), statement.TotalRevenue)
+	assert.Equal(t, models.Rupiah(600000), statement.TotalExpense)
+
+	// Financial reports read the ledger, including non-cash depreciation
+	report, err := NewFinancialReportService(db).GenerateFinancialReport(
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local), false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(20000000), report.CashFlowSummary.TotalIncome)
+	assert.Equal(t, models.Rupiah(100000), report.CashFlowSummary.ByCategory["penyusutan"])
+	require.Len(t, report.CategoryBreakdown, 2)
+	assert.Equal(t, "pemeliharaan", report.CategoryBreakdown[0].Category)
+
+	// Reversing the manual journal removes the funding; it cannot be reversed twice
+	_, err = ledger.ReverseJournalEntry(funding.ID, "salah input", 1)
+	require.NoError(t, err)
+	_, err = ledger.ReverseJournalEntry(funding.ID, "", 1)
+	assert.ErrorIs(t, err, ErrJournalAlreadyReversed)
+	assert.Equal(t, models.Rupiah(-12500000), ledgerBalance(t, ledger, AccountCodeCash))
+
+	// Changing the purchase price corrects the acquisition journal
+	asset.PurchasePrice = models.Rupiah(15000000)
+	require.NoError(t, assetService.UpdateAsset(asset.ID, asset))
+	assert.Equal(t, models.Rupiah(15000000), ledgerBalance(t, ledger, AccountCodeFixedAssets))
+	require.NoError(t, assetService.DeleteAsset(asset.ID))
+	assert.Equal(t, models.Rupiah(0), ledgerBalance(t, ledger, AccountCodeFixedAssets))
+}
+
+func TestLedgerService_SyncExistingRecords(t *testing.T) {
+	db := setupLedgerTestDB(t)
+	ledger := NewLedgerService(db)
+
+	require.NoError(t, db.Create(&models.CashFlowEntry{
+		TransactionID: "TXN-OLD-0001", Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.Local),
+		Category: "utilitas", Type: "expense", Amount: models.Rupiah(750000), CreatedBy: 1,
+	}).Error)
+	require.NoError(t, db.Create(&models.KitchenAsset{
+		AssetCode: "AST-OLD", Name: "Kulkas", PurchaseDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local),
+		PurchasePrice: models.Rupiah(8000000), CurrentValue: models.Rupiah(8000000), Condition: "good",
+	}).Error)
+
+	posted, err := ledger.SyncExistingRecords()
+	require.NoError(t, err)
+	assert.Equal(t, 2, posted)
+
+	posted, err = ledger.SyncExistingRecords()
+	require.NoError(t, err)
+	assert.Equal(t, 0, posted)
+
+	// Existing assets are opening balances against net assets, not cash
+	assert.Equal(t, models.Rupiah(-8000000), ledgerBalance(t, ledger, AccountCodeNetAssets))
+	assert.Equal(t, models.Rupiah(-750000), ledgerBalance(t, ledger, AccountCodeCash))
+	assert.Equal(t, models.Rupiah(750000), ledgerBalance(t, ledger, AccountCodeUtilityExpense))
+
+	var account models.Account
+	require.NoError(t, db.Where("code = ?", AccountCodeCash).First(&account).Error)
+	_, err = ledger.UpdateAccount(account.ID, "", "", "", false, 1)
+	assert.ErrorIs(t, err, ErrSystemAccount)
+	assert.ErrorIs(t, ledger.CreateAccount(&models.Account{Code: AccountCodeCash, Name: "Kas Kecil", Type: AccountTypeAsset}, 1), ErrDuplicateAccountCode)
+
+	pettyCash := &models.Account{Code: "1-1110", Name: "Kas Kecil", Type: AccountTypeAsset}
+	require.NoError(t, ledger.CreateAccount(pettyCash, 1))
+	assert.Equal(t, NormalBalanceDebit, pettyCash.NormalBalance)
+}
@@ -0,0 +1,710 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMaintenancePlanNotFound       = errors.New("rencana pemeliharaan tidak ditemukan")
+	ErrMaintenancePlanInactive       = errors.New("rencana pemeliharaan tidak aktif")
+	ErrMaintenancePlanNameRequired   = errors.New("nama rencana pemeliharaan wajib diisi")
+	ErrInvalidMaintenanceInterval    = errors.New("interval pemeliharaan tidak valid")
+	ErrInvalidMaintenanceResponsible = errors.New("penanggung jawab pemeliharaan tidak ditemukan atau tidak aktif")
+	ErrInvalidMaintenanceCompletion  = errors.New("tanggal atau biaya pemeliharaan tidak valid")
+)
+
+// Maintenance interval types
+const (
+	MaintenanceIntervalDays  = "days"
+	MaintenanceIntervalUsage = "usage"
+)
+
+// Maintenance task statuses
+const (
+	MaintenanceTaskUpcoming = "upcoming"
+	MaintenanceTaskDue      = "due"
+	MaintenanceTaskOverdue  = "overdue"
+)
+
+// Asset alert reasons shown on the dashboard
+const (
+	AssetAlertPoorCondition            = "poor_condition"
+	AssetAlertOverdueSafetyMaintenance = "overdue_safety_maintenance"
+)
+
+// usageRateWindowDays is the period the average daily usage is taken from to
+// project the due date of a usage-based plan
+const usageRateWindowDays = 30
+
+// MaintenancePlanService handles preventive maintenance plans of kitchen
+// assets. Day-based plans are due a fixed number of days after the last
+// completion; usage-based plans once the usage recorded for the asset since
+// the last completion reaches the interval.
+type MaintenancePlanService struct {
+	db                  *gorm.DB
+	ledgerService       *LedgerService
+	notificationService *NotificationService
+}
+
+// NewMaintenancePlanService creates a new maintenance plan service.
+// notificationService may be nil, in which case reminders are stored without
+// a realtime push.
+func NewMaintenancePlanService(db *gorm.DB, notificationService *NotificationService) *MaintenancePlanService {
+	return &MaintenancePlanService{
+		db:                  db,
+		ledgerService:       NewLedgerService(db),
+		notificationService: notificationService,
+	}
+}
+
+// MaintenanceTask represents an occurrence of a maintenance plan on the calendar
+type MaintenanceTask struct {
+	PlanID              uint         `json:"plan_id"`
+	PlanName            string       `json:"plan_name"`
+	AssetID             uint         `json:"asset_id"`
+	AssetCode           string       `json:"asset_code"`
+	AssetName           string       `json:"asset_name"`
+	AssetCondition      string       `json:"asset_condition"`
+	IntervalType        string       `json:"interval_type"`
+	IsSafetyCritical    bool         `json:"is_safety_critical"`
+	ResponsibleUserID   uint         `json:"responsible_user_id"`
+	ResponsibleUserName string       `json:"responsible_user_name"`
+	DueDate             *time.Time   `json:"due_date"` // projected for usage plans, nil while the asset has no recent usage
+	Status              string       `json:"status"`   // upcoming, due, overdue
+	DaysOverdue         int          `json:"days_overdue"`
+	UnitsSinceLast      float64      `json:"units_since_last,omitempty"`
+	UnitsRemaining      float64      `json:"units_remaining,omitempty"`
+	EstimatedCost       models.Money `json:"estimated_cost"`
+}
+
+// MaintenanceCalendarFilter narrows the maintenance calendar
+type MaintenanceCalendarFilter struct {
+	AssetID           uint
+	ResponsibleUserID uint
+}
+
+// MaintenanceCompletion represents the completion of a planned maintenance task
+type MaintenanceCompletion struct {
+	Date        time.Time    `json:"date"`
+	Cost        models.Money `json:"cost"`
+	PerformedBy string       `json:"performed_by"`
+	Description string       `json:"description"`
+}
+
+// AssetAlert represents an asset flagged on the dashboard
+type AssetAlert struct {
+	AssetID     uint       `json:"asset_id"`
+	AssetCode   string     `json:"asset_code"`
+	AssetName   string     `json:"asset_name"`
+	Condition   string     `json:"condition"`
+	Reason      string     `json:"reason"` // poor_condition, overdue_safety_maintenance
+	PlanID      *uint      `json:"plan_id,omitempty"`
+	PlanName    string     `json:"plan_name,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	DaysOverdue int        `json:"days_overdue"`
+}
+
+// maintenanceSettings holds the configurable thresholds of the task statuses
+type maintenanceSettings struct {
+	reminderDays  int
+	usageDueRatio float64
+}
+
+// CreatePlan creates a maintenance plan for an active asset. Usage-based
+// plans start counting from the usage recorded so far.
+func (s *MaintenancePlanService) CreatePlan(plan *models.MaintenancePlan, userID uint) error {
+	if err := s.validatePlan(plan); err != nil {
+		return err
+	}
+	if _, err := s.activeAsset(s.db, plan.AssetID); err != nil {
+		return err
+	}
+
+	plan.ID = 0
+	if plan.StartDate.IsZero() {
+		plan.StartDate = time.Now()
+	}
+	plan.StartDate = startOfDay(plan.StartDate)
+	plan.LastCompletedAt = nil
+	plan.LastRemindedAt = nil
+	plan.IsActive = true
+	plan.CreatedBy = userID
+	if plan.IntervalType == MaintenanceIntervalUsage {
+		meter, err := s.usageMeter(s.db, plan.AssetID)
+		if err != nil {
+			return err
+		}
+		plan.LastCompletedUnits = meter
+	}
+	plan.NextDueDate = nextMaintenanceDueDate(plan)
+
+	if err := s.db.Create(plan).Error; err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "maintenance_plan", fmt.Sprint(plan.ID), nil, plan, "")
+	return nil
+}
+
+// UpdatePlan updates a maintenance plan. Switching a plan to usage-based
+// restarts its count from the usage recorded so far.
+func (s *MaintenancePlanService) UpdatePlan(id uint, updates *models.MaintenancePlan, userID uint) (*models.MaintenancePlan, error) {
+	if err := s.validatePlan(updates); err != nil {
+		return nil, err
+	}
+
+	var oldPlan, plan models.MaintenancePlan
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&plan, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMaintenancePlanNotFound
+			}
+			return err
+		}
+		oldPlan = plan
+
+		if updates.IntervalType == MaintenanceIntervalUsage && plan.IntervalType != MaintenanceIntervalUsage {
+			meter, err := s.usageMeter(tx, plan.AssetID)
+			if err != nil {
+				return err
+			}
+			plan.LastCompletedUnits = meter
+		}
+		plan.Name = updates.Name
+		plan.Description = updates.Description
+		plan.IntervalType = updates.IntervalType
+		plan.IntervalDays = updates.IntervalDays
+		plan.IntervalUnits = updates.IntervalUnits
+		plan.IsSafetyCritical = updates.IsSafetyCritical
+		plan.EstimatedCost = updates.EstimatedCost
+		plan.ResponsibleUserID = updates.ResponsibleUserID
+		plan.IsActive = updates.IsActive
+		plan.NextDueDate = nextMaintenanceDueDate(&plan)
+		return tx.Model(&models.MaintenancePlan{}).Where("id = ?", plan.ID).Updates(map[string]interface{}{
+			"name":                 plan.Name,
+			"description":          plan.Description,
+			"interval_type":        plan.IntervalType,
+			"interval_days":        plan.IntervalDays,
+			"interval_units":       plan.IntervalUnits,
+			"is_safety_critical":   plan.IsSafetyCritical,
+			"estimated_cost":       plan.EstimatedCost,
+			"responsible_user_id":  plan.ResponsibleUserID,
+			"is_active":            plan.IsActive,
+			"last_completed_units": plan.LastCompletedUnits,
+			"next_due_date":        plan.NextDueDate,
+			"updated_at":           time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "update", "maintenance_plan", fmt.Sprint(plan.ID), oldPlan, plan, "")
+	return s.GetPlan(plan.ID)
+}
+
+// GetPlans retrieves maintenance plans, optionally of one asset or one
+// responsible user
+func (s *MaintenancePlanService) GetPlans(assetID, responsibleUserID uint) ([]models.MaintenancePlan, error) {
+	query := s.db.Preload("Asset").Preload("ResponsibleUser")
+	if assetID > 0 {
+		query = query.Where("asset_id = ?", assetID)
+	}
+	if responsibleUserID > 0 {
+		query = query.Where("responsible_user_id = ?", responsibleUserID)
+	}
+
+	var plans []models.MaintenancePlan
+	err := query.Order("asset_id ASC, id ASC").Find(&plans).Error
+	return plans, err
+}
+
+// GetPlan retrieves a maintenance plan
+func (s *MaintenancePlanService) GetPlan(id uint) (*models.MaintenancePlan, error) {
+	var plan models.MaintenancePlan
+	if err := s.db.Preload("Asset").Preload("ResponsibleUser").First(&plan, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMaintenancePlanNotFound
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// GetPlanHistory retrieves the maintenance records completing a plan
+func (s *MaintenancePlanService) GetPlanHistory(id uint) ([]models.AssetMaintenance, error) {
+	if _, err := s.GetPlan(id); err != nil {
+		return nil, err
+	}
+
+	var records []models.AssetMaintenance
+	err := s.db.Where("plan_id = ?", id).Order("maintenance_date DESC, id DESC").Find(&records).Error
+	return records, err
+}
+
+// GetCalendar returns the maintenance tasks of the active plans between start
+// and end (inclusive), ordered by due date. Overdue tasks are always listed,
+// whatever the period, and day-based plans repeat within the period. Usage
+// plans appear once, at their projected due date.
+func (s *MaintenancePlanService) GetCalendar(start, end time.Time, filter MaintenanceCalendarFilter) ([]MaintenanceTask, error) {
+	plans, err := s.activePlans(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := s.settings()
+	today := startOfDay(time.Now())
+	start = startOfDay(start)
+	endExclusive := nextDay(end)
+
+	tasks := []MaintenanceTask{}
+	for i := range plans {
+		task, err := s.currentTask(&plans[i], today, settings)
+		if err != nil {
+			return nil, err
+		}
+
+		inPeriod := task.DueDate != nil && !task.DueDate.Before(start) && task.DueDate.Before(endExclusive)
+		if inPeriod || task.Status == MaintenanceTaskOverdue {
+			tasks = append(tasks, task)
+		}
+		if task.IntervalType != MaintenanceIntervalDays || task.DueDate == nil {
+			continue
+		}
+
+		// Later occurrences follow the current one, or today when it is overdue
+		due := *task.DueDate
+		if due.Before(today) {
+			due = today
+		}
+		for due = due.AddDate(0, 0, plans[i].IntervalDays); due.Before(endExclusive); due = due.AddDate(0, 0, plans[i].IntervalDays) {
+			if due.Before(start) {
+				continue
+			}
+			next := task
+			dueDate := due
+			next.DueDate = &dueDate
+			next.Status, next.DaysOverdue = dayTaskStatus(dueDate, today, settings)
+			tasks = append(tasks, next)
+		}
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i].DueDate, tasks[j].DueDate
+		switch {
+		case a == nil || b == nil:
+			return a != nil && b == nil
+		case !a.Equal(*b):
+			return a.Before(*b)
+		default:
+			return tasks[i].IsSafetyCritical && !tasks[j].IsSafetyCritical
+		}
+	})
+	return tasks, nil
+}
+
+// CompleteTask records the completion of the current task of a plan as an
+// asset maintenance record, posts its cost to the ledger and moves the plan
+// to its next interval
+func (s *MaintenancePlanService) CompleteTask(planID uint, completion MaintenanceCompletion, userID uint) (*models.AssetMaintenance, error) {
+	if completion.Date.IsZero() {
+		completion.Date = time.Now()
+	}
+	if completion.Cost < 0 || completion.Date.After(time.Now()) {
+		return nil, ErrInvalidMaintenanceCompletion
+	}
+
+	plan, err := s.GetPlan(planID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.IsActive {
+		return nil, ErrMaintenancePlanInactive
+	}
+	if plan.Asset.Status != AssetStatusActive {
+		return nil, ErrAssetNotActive
+	}
+
+	task, err := s.currentTask(plan, startOfDay(time.Now()), s.settings())
+	if err != nil {
+		return nil, err
+	}
+
+	description := strings.TrimSpace(completion.Description)
+	if description == "" {
+		description = plan.Name
+	}
+	oldPlan := *plan
+	maintenance := &models.AssetMaintenance{
+		AssetID:         plan.AssetID,
+		MaintenanceDate: completion.Date,
+		Description:     description,
+		Cost:            completion.Cost,
+		PerformedBy:     completion.PerformedBy,
+		PlanID:          &plan.ID,
+		DueDate:         task.DueDate,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(maintenance).Error; err != nil {
+			return err
+		}
+		if err := s.ledgerService.PostAssetMaintenanceWithTx(tx, maintenance, &plan.Asset, userID); err != nil {
+			return err
+		}
+
+		meter, err := s.usageMeter(tx, plan.AssetID)
+		if err != nil {
+			return err
+		}
+		completedAt := completion.Date
+		plan.LastCompletedAt = &completedAt
+		plan.LastCompletedUnits = meter
+		plan.NextDueDate = nextMaintenanceDueDate(plan)
+		return tx.Model(&models.MaintenancePlan{}).Where("id = ?", plan.ID).Updates(map[string]interface{}{
+			"last_completed_at":    plan.LastCompletedAt,
+			"last_completed_units": plan.LastCompletedUnits,
+			"next_due_date":        plan.NextDueDate,
+			"last_reminded_at":     nil,
+			"updated_at":           time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "complete", "maintenance_plan", fmt.Sprint(plan.ID), oldPlan, plan, "")
+	return maintenance, nil
+}
+
+// GetAssetAlerts returns the active assets in poor condition and the
+// safety-critical maintenance that is overdue
+func (s *MaintenancePlanService) GetAssetAlerts() ([]AssetAlert, error) {
+	alerts := []AssetAlert{}
+
+	var poorAssets []models.KitchenAsset
+	if err := s.db.Where("condition = ? AND status = ?", "poor", AssetStatusActive).
+		Order("asset_code ASC").Find(&poorAssets).Error; err != nil {
+		return nil, err
+	}
+	for _, asset := range poorAssets {
+		alerts = append(alerts, AssetAlert{
+			AssetID:   asset.ID,
+			AssetCode: asset.AssetCode,
+			AssetName: asset.Name,
+			Condition: asset.Condition,
+			Reason:    AssetAlertPoorCondition,
+		})
+	}
+
+	plans, err := s.activePlans(MaintenanceCalendarFilter{})
+	if err != nil {
+		return nil, err
+	}
+	settings := s.settings()
+	today := startOfDay(time.Now())
+	for i := range plans {
+		if !plans[i].IsSafetyCritical {
+			continue
+		}
+		task, err := s.currentTask(&plans[i], today, settings)
+		if err != nil {
+			return nil, err
+		}
+		if task.Status != MaintenanceTaskOverdue {
+			continue
+		}
+		planID := task.PlanID
+		alerts = append(alerts, AssetAlert{
+			AssetID:     task.AssetID,
+			AssetCode:   task.AssetCode,
+			AssetName:   task.AssetName,
+			Condition:   task.AssetCondition,
+			Reason:      AssetAlertOverdueSafetyMaintenance,
+			PlanID:      &planID,
+			PlanName:    task.PlanName,
+			DueDate:     task.DueDate,
+			DaysOverdue: task.DaysOverdue,
+		})
+	}
+	return alerts, nil
+}
+
+// SendDueReminders notifies the responsible user of every task that is due or
+// overdue, at most once a day per plan, and returns the number of reminders sent
+func (s *MaintenancePlanService) SendDueReminders(now time.Time) (int, error) {
+	plans, err := s.activePlans(MaintenanceCalendarFilter{})
+	if err != nil {
+		return 0, err
+	}
+
+	settings := s.settings()
+	today := startOfDay(now)
+	sent := 0
+	for i := range plans {
+		plan := &plans[i]
+		if plan.LastRemindedAt != nil && !plan.LastRemindedAt.Before(today) {
+			continue
+		}
+		task, err := s.currentTask(plan, today, settings)
+		if err != nil {
+			return sent, err
+		}
+		if task.Status == MaintenanceTaskUpcoming {
+			continue
+		}
+
+		s.notify(task)
+		if err := s.db.Model(&models.MaintenancePlan{}).Where("id = ?", plan.ID).
+			Update("last_reminded_at", now).Error; err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// StartReminderScheduler sends the due maintenance reminders periodically
+func (s *MaintenancePlanService) StartReminderScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := s.SendDueReminders(time.Now()); err != nil {
+			log.Printf("[MAINTENANCE] gagal mengirim pengingat pemeliharaan: %v", err)
+		} else if sent > 0 {
+			log.Printf("[MAINTENANCE] %d pengingat pemeliharaan aset dikirim", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// currentTask returns the next open task of a plan with its status as of today
+func (s *MaintenancePlanService) currentTask(plan *models.MaintenancePlan, today time.Time, settings maintenanceSettings) (MaintenanceTask, error) {
+	task := MaintenanceTask{
+		PlanID:              plan.ID,
+		PlanName:            plan.Name,
+		AssetID:             plan.AssetID,
+		AssetCode:           plan.Asset.AssetCode,
+		AssetName:           plan.Asset.Name,
+		AssetCondition:      plan.Asset.Condition,
+		IntervalType:        plan.IntervalType,
+		IsSafetyCritical:    plan.IsSafetyCritical,
+		ResponsibleUserID:   plan.ResponsibleUserID,
+		ResponsibleUserName: plan.ResponsibleUser.FullName,
+		EstimatedCost:       plan.EstimatedCost,
+	}
+
+	if plan.IntervalType == MaintenanceIntervalDays {
+		due := nextMaintenanceDueDate(plan)
+		task.DueDate = due
+		task.Status, task.DaysOverdue = dayTaskStatus(*due, today, settings)
+		return task, nil
+	}
+
+	var logs []models.AssetUsageLog
+	if err := s.db.Where("asset_id = ?", plan.AssetID).Order("usage_date ASC, id ASC").Find(&logs).Error; err != nil {
+		return task, err
+	}
+
+	// The task is overdue from the day the recorded usage passed the interval
+	threshold := plan.LastCompletedUnits + plan.IntervalUnits
+	windowStart := today.AddDate(0, 0, -usageRateWindowDays)
+	var meter, recentUsage float64
+	var reachedAt *time.Time
+	for _, usage := range logs {
+		meter += usage.Units
+		if reachedAt == nil && meter >= threshold-1e-9 {
+			reached := startOfDay(usage.UsageDate)
+			reachedAt = &reached
+		}
+		if !usage.UsageDate.Before(windowStart) {
+			recentUsage += usage.Units
+		}
+	}
+
+	task.UnitsSinceLast = math.Max(meter-plan.LastCompletedUnits, 0)
+	task.UnitsRemaining = math.Max(plan.IntervalUnits-task.UnitsSinceLast, 0)
+	if reachedAt != nil {
+		task.DueDate = reachedAt
+		task.Status = MaintenanceTaskOverdue
+		task.DaysOverdue = int(today.Sub(*reachedAt).Hours() / 24)
+		return task, nil
+	}
+
+	if dailyRate := recentUsage / usageRateWindowDays; dailyRate > 0 {
+		due := today.AddDate(0, 0, int(math.Ceil(task.UnitsRemaining/dailyRate)))
+		task.DueDate = &due
+	}
+	task.Status = MaintenanceTaskUpcoming
+	if task.UnitsRemaining <= plan.IntervalUnits*settings.usageDueRatio ||
+		(task.DueDate != nil && !task.DueDate.After(today.AddDate(0, 0, settings.reminderDays))) {
+		task.Status = MaintenanceTaskDue
+	}
+	return task, nil
+}
+
+// dayTaskStatus returns the status of a task due on the given day
+func dayTaskStatus(due, today time.Time, settings maintenanceSettings) (string, int) {
+	switch {
+	case due.Before(today):
+		return MaintenanceTaskOverdue, int(today.Sub(due).Hours() / 24)
+	case !due.After(today.AddDate(0, 0, settings.reminderDays)):
+		return MaintenanceTaskDue, 0
+	default:
+		return MaintenanceTaskUpcoming, 0
+	}
+}
+
+// nextMaintenanceDueDate returns the due date of a day-based plan, one
+// interval after its last completion or its start, and nil for usage plans
+func nextMaintenanceDueDate(plan *models.MaintenancePlan) *time.Time {
+	if plan.IntervalType != MaintenanceIntervalDays {
+		return nil
+	}
+	base := plan.StartDate
+	if plan.LastCompletedAt != nil {
+		base = *plan.LastCompletedAt
+	}
+	due := startOfDay(base).AddDate(0, 0, plan.IntervalDays)
+	return &due
+}
+
+// activePlans retrieves the active plans of active assets
+func (s *MaintenancePlanService) activePlans(filter MaintenanceCalendarFilter) ([]models.MaintenancePlan, error) {
+	query := s.db.Preload("Asset").Preload("ResponsibleUser").
+		Joins("JOIN kitchen_assets ON kitchen_assets.id = maintenance_plans.asset_id").
+		Where("maintenance_plans.is_active = ? AND kitchen_assets.status = ?", true, AssetStatusActive)
+	if filter.AssetID > 0 {
+		query = query.Where("maintenance_plans.asset_id = ?", filter.AssetID)
+	}
+	if filter.ResponsibleUserID > 0 {
+		query = query.Where("maintenance_plans.responsible_user_id = ?", filter.ResponsibleUserID)
+	}
+
+	var plans []models.MaintenancePlan
+	err := query.Order("maintenance_plans.id ASC").Find(&plans).Error
+	return plans, err
+}
+
+// validatePlan checks the name, interval and responsible user of a plan
+func (s *MaintenancePlanService) validatePlan(plan *models.MaintenancePlan) error {
+	plan.Name = strings.TrimSpace(plan.Name)
+	if plan.Name == "" {
+		return ErrMaintenancePlanNameRequired
+	}
+
+	switch plan.IntervalType {
+	case MaintenanceIntervalDays:
+		if plan.IntervalDays <= 0 {
+			return ErrInvalidMaintenanceInterval
+		}
+		plan.IntervalUnits = 0
+	case MaintenanceIntervalUsage:
+		if plan.IntervalUnits <= 0 {
+			return ErrInvalidMaintenanceInterval
+		}
+		plan.IntervalDays = 0
+	default:
+		return ErrInvalidMaintenanceInterval
+	}
+	if plan.EstimatedCost < 0 {
+		return ErrInvalidMaintenanceCompletion
+	}
+
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id = ? AND is_active = ?", plan.ResponsibleUserID, true).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrInvalidMaintenanceResponsible
+	}
+	return nil
+}
+
+// activeAsset retrieves an asset that has not been disposed or sold
+func (s *MaintenancePlanService) activeAsset(tx *gorm.DB, assetID uint) (*models.KitchenAsset, error) {
+	var asset models.KitchenAsset
+	if err := tx.First(&asset, assetID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssetNotFound
+		}
+		return nil, err
+	}
+	if asset.Status != AssetStatusActive {
+		return nil, ErrAssetNotActive
+	}
+	return &asset, nil
+}
+
+// usageMeter returns the total usage recorded for an asset
+func (s *MaintenancePlanService) usageMeter(tx *gorm.DB, assetID uint) (float64, error) {
+	var total float64
+	err := tx.Model(&models.AssetUsageLog{}).Where("asset_id = ?", assetID).
+		Select("COALESCE(SUM(units), 0)").Scan(&total).Error
+	return total, err
+}
+
+// settings reads the maintenance thresholds from the system config
+func (s *MaintenancePlanService) settings() maintenanceSettings {
+	configService := NewSystemConfigService(s.db)
+	return maintenanceSettings{
+		reminderDays:  configService.GetConfigInt("maintenance_reminder_days", 3),
+		usageDueRatio: configService.GetConfigFloat("maintenance_usage_due_ratio", 0.1),
+	}
+}
+
+// notify reminds the responsible user of a due or overdue task; failures are
+// logged and ignored
+func (s *MaintenancePlanService) notify(task MaintenanceTask) {
+	title := "Pemeliharaan Aset Jatuh Tempo"
+	if task.Status == MaintenanceTaskOverdue {
+		title = "Pemeliharaan Aset Terlambat"
+	}
+	if task.IsSafetyCritical {
+		title = "[Kritis] " + title
+	}
+
+	message := fmt.Sprintf("%s untuk %s (%s)", task.PlanName, task.AssetName, task.AssetCode)
+	switch {
+	case task.DueDate != nil && task.Status == MaintenanceTaskOverdue:
+		message += fmt.Sprintf(" terlambat sejak %s", task.DueDate.Format("02/01/2006"))
+	case task.DueDate != nil:
+		message += fmt.Sprintf(" jatuh tempo %s", task.DueDate.Format("02/01/2006"))
+	default:
+		message += fmt.Sprintf(" jatuh tempo dalam %.0f pemakaian", task.UnitsRemaining)
+	}
+
+	notification := &models.Notification{
+		UserID:  task.ResponsibleUserID,
+		Type:    NotificationTypeMaintenanceDue,
+		Title:   title,
+		Message: message,
+		Link:    "/assets/maintenance",
+	}
+
+	var err error
+	if s.notificationService != nil {
+		err = s.notificationService.CreateNotification(context.Background(), notification)
+	} else {
+		err = s.db.Create(notification).Error
+	}
+	if err != nil {
+		log.Printf("[MAINTENANCE] gagal mengirim notifikasi pemeliharaan: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type maintenanceFixture struct {
+	db      *gorm.DB
+	service *MaintenancePlanService
+	assets  *AssetService
+	chef    models.User
+	asset   *models.KitchenAsset
+	today   time.Time
+}
+
+func setupMaintenancePlanTest(t *testing.T) maintenanceFixture {
+	db, err := openTestDB(t, filepath.Join(t.TempDir(), "maintenance.db"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.KitchenAsset{}, &models.AssetMaintenance{}, &models.AssetUsageLog{},
+		&models.MaintenancePlan{}, &models.Account{}, &models.JournalEntry{}, &models.JournalLine{},
+		&models.AuditTrail{}, &models.Notification{}, &models.SystemConfig{})
+	require.NoError(t, err)
+	require.NoError(t, NewLedgerService(db).SeedDefaultAccounts())
+
+	chef := models.User{NIK: "MT001", Email: "chef@sppg.id", PasswordHash: "x", FullName: "Chef Dapur", Role: "chef", IsActive: true}
+	require.NoError(t, db.Create(&chef).Error)
+
+	assets := NewAssetService(db)
+	asset := &models.KitchenAsset{AssetCode: "AST-KMP-01", Name: "Kompor Gas", PurchaseDate: time.Now().AddDate(-1, 0, 0),
+		PurchasePrice: models.Rupiah(8000000), UsefulLifeMonths: 60, Condition: "good"}
+	require.NoError(t, assets.CreateAsset(asset))
+
+	return maintenanceFixture{
+		db:      db,
+		service: NewMaintenancePlanService(db, nil),
+		assets:  assets,
+		chef:    chef,
+		asset:   asset,
+		today:   startOfDay(time.Now()),
+	}
+}
+
+func TestMaintenancePlanService_DayPlan(t *testing.T) {
+	f := setupMaintenancePlanTest(t)
+
+	invalid := &models.MaintenancePlan{AssetID: f.asset.ID, Name: "Cek selang gas", IntervalType: MaintenanceIntervalDays, ResponsibleUserID: f.chef.ID}
+	assert.ErrorIs(t, f.service.CreatePlan(invalid, f.chef.ID), ErrInvalidMaintenanceInterval)
+	invalid.IntervalDays = 30
+	invalid.ResponsibleUserID = 999
+	assert.ErrorIs(t, f.service.CreatePlan(invalid, f.chef.ID), ErrInvalidMaintenanceResponsible)
+
+	plan := &models.MaintenancePlan{
+		AssetID:           f.asset.ID,
+		Name:              "Cek selang dan regulator gas",
+		IntervalType:      MaintenanceIntervalDays,
+		IntervalDays:      30,
+		IsSafetyCritical:  true,
+		EstimatedCost:     models.Rupiah(150000),
+		ResponsibleUserID: f.chef.ID,
+		StartDate:         f.today.AddDate(0, 0, -40),
+	}
+	require.NoError(t, f.service.CreatePlan(plan, f.chef.ID))
+	require.NotNil(t, plan.NextDueDate)
+	assert.True(t, plan.NextDueDate.Equal(f.today.AddDate(0, 0, -10)))
+
+	// The overdue task is listed before the period, followed by the repeats
+	tasks, err := f.service.GetCalendar(f.today, f.today.AddDate(0, 0, 70), MaintenanceCalendarFilter{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+	assert.Equal(t, MaintenanceTaskOverdue, tasks[0].Status)
+	assert.Equal(t, 10, tasks[0].DaysOverdue)
+	assert.True(t, tasks[1].DueDate.Equal(f.today.AddDate(0, 0, 30)))
+	assert.Equal(t, MaintenanceTaskUpcoming, tasks[1].Status)
+	assert.True(t, tasks[2].DueDate.Equal(f.today.AddDate(0, 0, 60)))
+
+	// Poor assets and overdue safety-critical maintenance are flagged
+	poor := &models.KitchenAsset{AssetCode: "AST-RC-02", Name: "Rice Cooker", PurchaseDate: time.Now().AddDate(-2, 0, 0),
+		PurchasePrice: models.Rupiah(3000000), UsefulLifeMonths: 36, Condition: "poor"}
+	require.NoError(t, f.assets.CreateAsset(poor))
+	alerts, err := f.service.GetAssetAlerts()
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, AssetAlertPoorCondition, alerts[0].Reason)
+	assert.Equal(t, poor.ID, alerts[0].AssetID)
+	assert.Equal(t, AssetAlertOverdueSafetyMaintenance, alerts[1].Reason)
+	assert.Equal(t, f.asset.ID, alerts[1].AssetID)
+
+	// The responsible user is reminded once a day
+	sent, err := f.service.SendDueReminders(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	sent, err = f.service.SendDueReminders(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	var notifications []models.Notification
+	require.NoError(t, f.db.Where("user_id = ? AND type = ?", f.chef.ID, NotificationTypeMaintenanceDue).Find(&notifications).Error)
+	require.Len(t, notifications, 1)
+	assert.Contains(t, notifications[0].Title, "[Kritis]")
+
+	// Completing the task records the maintenance, its cost and the next due date
+	maintenance, err := f.service.CompleteTask(plan.ID, MaintenanceCompletion{Cost: models.Rupiah(175000), PerformedBy: "Teknisi Gas"}, f.chef.ID)
+	require.NoError(t, err)
+	require.NotNil(t, maintenance.PlanID)
+	assert.Equal(t, plan.ID, *maintenance.PlanID)
+	require.NotNil(t, maintenance.DueDate)
+	assert.True(t, maintenance.DueDate.Equal(f.today.AddDate(0, 0, -10)))
+	assert.Equal(t, plan.Name, maintenance.Description)
+
+	var journal models.JournalEntry
+	require.NoError(t, f.db.Where("source_type = ? AND status = ?", JournalSourceAssetMaintenance, "posted").First(&journal).Error)
+	assert.Equal(t, models.Rupiah(175000), journal.TotalAmount)
+
+	updated, err := f.service.GetPlan(plan.ID)
+	require.NoError(t, err)
+	require.NotNil(t, updated.NextDueDate)
+	assert.True(t, updated.NextDueDate.Equal(f.today.AddDate(0, 0, 30)))
+	assert.Nil(t, updated.LastRemindedAt)
+
+	alerts, err = f.service.GetAssetAlerts()
+	require.NoError(t, err)
+	assert.Len(t, alerts, 1)
+
+	history, err := f.service.GetPlanHistory(plan.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+func TestMaintenancePlanService_UsagePlan(t *testing.T) {
+	f := setupMaintenancePlanTest(t)
+
+	record := func(daysAgo int, units float64) {
+		usage := &models.AssetUsageLog{UsageDate: f.today.AddDate(0, 0, -daysAgo), Units: units}
+		require.NoError(t, f.assets.RecordUsage(f.asset.ID, usage, f.chef.ID))
+	}
+
+	// Usage recorded before the plan does not count towards it
+	record(25, 500)
+	plan := &models.MaintenancePlan{
+		AssetID:           f.asset.ID,
+		Name:              "Servis burner",
+		IntervalType:      MaintenanceIntervalUsage,
+		IntervalUnits:     1000,
+		ResponsibleUserID: f.chef.ID,
+	}
+	require.NoError(t, f.service.CreatePlan(plan, f.chef.ID))
+	assert.Equal(t, 500.0, plan.LastCompletedUnits)
+	assert.Nil(t, plan.NextDueDate)
+
+	record(20, 400)
+	record(10, 450)
+
+	// 150 units left at 45 units a day over the last 30 days
+	tasks, err := f.service.GetCalendar(f.today, f.today.AddDate(0, 0, 30), MaintenanceCalendarFilter{AssetID: f.asset.ID})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, 850.0, tasks[0].UnitsSinceLast)
+	assert.Equal(t, 150.0, tasks[0].UnitsRemaining)
+	require.NotNil(t, tasks[0].DueDate)
+	assert.True(t, tasks[0].DueDate.Equal(f.today.AddDate(0, 0, 4)))
+	assert.Equal(t, MaintenanceTaskUpcoming, tasks[0].Status)
+
+	// The task is overdue from the day the interval was used up
+	record(2, 200)
+	tasks, err = f.service.GetCalendar(f.today, f.today, MaintenanceCalendarFilter{ResponsibleUserID: f.chef.ID})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, MaintenanceTaskOverdue, tasks[0].Status)
+	assert.True(t, tasks[0].DueDate.Equal(f.today.AddDate(0, 0, -2)))
+	assert.Equal(t, 2, tasks[0].DaysOverdue)
+
+	// Not safety-critical, so nothing is flagged
+	alerts, err := f.service.GetAssetAlerts()
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	_, err = f.service.CompleteTask(plan.ID, MaintenanceCompletion{Cost: models.Rupiah(-1)}, f.chef.ID)
+	assert.ErrorIs(t, err, ErrInvalidMaintenanceCompletion)
+	_, err = f.service.CompleteTask(plan.ID, MaintenanceCompletion{Description: "Servis oleh teknisi internal"}, f.chef.ID)
+	require.NoError(t, err)
+
+	// The count restarts from the usage at completion
+	tasks, err = f.service.GetCalendar(f.today, f.today.AddDate(0, 0, 365), MaintenanceCalendarFilter{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, 0.0, tasks[0].UnitsSinceLast)
+	assert.Equal(t, MaintenanceTaskUpcoming, tasks[0].Status)
+
+	// Plans of disposed assets leave the calendar
+	_, err = f.assets.DisposeAsset(f.asset.ID, AssetDisposal{Date: time.Now()}, f.chef.ID)
+	require.NoError(t, err)
+	tasks, err = f.service.GetCalendar(f.today, f.today.AddDate(0, 0, 365), MaintenanceCalendarFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+	_, err = f.service.CompleteTask(plan.ID, MaintenanceCompletion{}, f.chef.ID)
+	assert.ErrorIs(t, err, ErrAssetNotActive)
+}
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/erp-sppg/backend/internal/models"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -16,7 +15,7 @@ import (
 func setupConcurrentTestDB(t *testing.T) *gorm.DB {
 	// Use file-based database with WAL mode for better concurrent access
 	// WAL (Write-Ahead Logging) allows multiple readers and one writer simultaneously
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+	db, err := openTestDB(t, "file::memory:?cache=shared", &gorm.Config{
 		Logger: logger.Discard,
 	})
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -170,12 +171,17 @@ func (s *MenuPlanningService) ApproveMenu(id uint, approverID uint) error {
 
 	// Update status
 	now := time.Now()
-	return s.db.Model(&models.MenuPlan{}).Where("id = ?", id).Updates(map[string]interface{}{
+	if err := s.db.Model(&models.MenuPlan{}).Where("id = ?", id).Updates(map[string]interface{}{
 		"status":      "approved",
 		"approved_by": approverID,
 		"approved_at": now,
 		"updated_at":  now,
-	}).Error
+	}).Error; err != nil {
+		return err
+	}
+
+	events.Publish(events.MenuApproved{MenuPlanID: id, ApprovedBy: approverID})
+	return nil
 }
 
 // UpdateMenuPlan updates an existing menu plan (only if not approved)
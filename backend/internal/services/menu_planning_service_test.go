@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/erp-sppg/backend/internal/models"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -268,7 +267,7 @@ func TestCreateMenuItemWithAllocations_InvalidSum(t *testing.T) {
 // Test helper functions for menu planning tests
 
 func setupMenuPlanningTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+	db, err := openTestDB(t, ":memory:", &gorm.Config{
 		Logger: logger.Discard,
 	})
 	if err != nil {
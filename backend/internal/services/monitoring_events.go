@@ -0,0 +1,174 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/events"
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// SubscribeEvents moves delivery records along when the kitchen and packing
+// report progress. The handlers run synchronously, so the monitoring view is
+// up to date when the KDS request returns.
+func (s *MonitoringService) SubscribeEvents(bus *events.Bus) {
+	bus.Subscribe(events.CookingStartedEvent, func(e events.Event) {
+		event := e.(events.CookingStarted)
+		s.startCooking(event.MenuItemID, event.UserID)
+	})
+	bus.Subscribe(events.CookingCompletedEvent, func(e events.Event) {
+		event := e.(events.CookingCompleted)
+		s.completeCooking(event.MenuItemID, event.UserID)
+	})
+	bus.Subscribe(events.PackingStartedEvent, func(e events.Event) {
+		event := e.(events.PackingStarted)
+		s.updateSchoolRecords(event.SchoolID, event.Date, "siap_dipacking", event.UserID, "Packing started for school")
+	})
+	bus.Subscribe(events.PackingCompletedEvent, func(e events.Event) {
+		event := e.(events.PackingCompleted)
+		s.updateSchoolRecords(event.SchoolID, event.Date, "selesai_dipacking", event.UserID, "Packing completed, ready for driver pickup")
+	})
+}
+
+// loadMenuItemAllocations loads a menu item with its school allocations
+func (s *MonitoringService) loadMenuItemAllocations(menuItemID uint) (*models.MenuItem, error) {
+	var menuItem models.MenuItem
+	if err := s.db.Preload("SchoolAllocations").First(&menuItem, menuItemID).Error; err != nil {
+		return nil, err
+	}
+	return &menuItem, nil
+}
+
+// startCooking creates the delivery records of the schools a menu item is
+// allocated to at stage 2 (sedang_dimasak), or moves existing ones there.
+// Failures are logged and do not block the cooking workflow.
+func (s *MonitoringService) startCooking(menuItemID, userID uint) {
+	menuItem, err := s.loadMenuItemAllocations(menuItemID)
+	if err != nil {
+		log.Printf("Warning: failed to load menu item %d for monitoring: %v", menuItemID, err)
+		return
+	}
+
+	// Group allocations by school to calculate portions_small and portions_large
+	type schoolPortions struct {
+		small int
+		large int
+		total int
+	}
+	portionsBySchool := make(map[uint]schoolPortions)
+	for _, alloc := range menuItem.SchoolAllocations {
+		portions := portionsBySchool[alloc.SchoolID]
+		if alloc.PortionSize == "small" {
+			portions.small += alloc.Portions
+		} else if alloc.PortionSize == "large" {
+			portions.large += alloc.Portions
+		}
+		portions.total += alloc.Portions
+		portionsBySchool[alloc.SchoolID] = portions
+	}
+
+	dayStart, dayEnd := localDayRange(menuItem.Date)
+	for schoolID, portions := range portionsBySchool {
+		var existingRecord models.DeliveryRecord
+		err := s.db.
+			Where("menu_item_id = ? AND school_id = ? AND delivery_date >= ? AND delivery_date < ?",
+				menuItem.ID, schoolID, dayStart, dayEnd).
+			First(&existingRecord).Error
+
+		if err == nil {
+			if err := s.UpdateDeliveryStatus(existingRecord.ID, "sedang_dimasak", userID, "Cooking started from KDS"); err != nil {
+				log.Printf("Warning: failed to update delivery status for record %d: %v", existingRecord.ID, err)
+			}
+			continue
+		}
+		if err != gorm.ErrRecordNotFound {
+			log.Printf("Warning: failed to find delivery record for school %d: %v", schoolID, err)
+			continue
+		}
+
+		now := time.Now()
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			record := models.DeliveryRecord{
+				DeliveryDate:  menuItem.Date,
+				SchoolID:      schoolID,
+				DriverID:      nil, // Driver assigned later at stage 4
+				MenuItemID:    menuItem.ID,
+				Portions:      portions.total,
+				PortionsSmall: portions.small,
+				PortionsLarge: portions.large,
+				CurrentStatus: "sedang_dimasak",
+				CurrentStage:  2,
+				OmprengCount:  portions.total, // Assume 1 ompreng per portion
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			}
+			if err := tx.Create(&record).Error; err != nil {
+				return err
+			}
+
+			return tx.Create(&models.StatusTransition{
+				DeliveryRecordID: record.ID,
+				FromStatus:       "",
+				ToStatus:         "sedang_dimasak",
+				Stage:            2,
+				TransitionedAt:   now,
+				TransitionedBy:   userID,
+				Notes:            "Cooking started from KDS",
+			}).Error
+		})
+		if err != nil {
+			log.Printf("Warning: failed to create delivery record for school %d: %v", schoolID, err)
+		}
+	}
+}
+
+// completeCooking moves the delivery records of a menu item to stage 3
+// (selesai_dimasak), once per school
+func (s *MonitoringService) completeCooking(menuItemID, userID uint) {
+	menuItem, err := s.loadMenuItemAllocations(menuItemID)
+	if err != nil {
+		log.Printf("Warning: failed to load menu item %d for monitoring: %v", menuItemID, err)
+		return
+	}
+
+	// SD schools have an allocation per portion size but one delivery record
+	dayStart, dayEnd := localDayRange(menuItem.Date)
+	updatedSchools := make(map[uint]bool)
+	for _, alloc := range menuItem.SchoolAllocations {
+		if updatedSchools[alloc.SchoolID] {
+			continue
+		}
+		updatedSchools[alloc.SchoolID] = true
+
+		var record models.DeliveryRecord
+		err := s.db.
+			Where("menu_item_id = ? AND school_id = ? AND delivery_date >= ? AND delivery_date < ?",
+				menuItem.ID, alloc.SchoolID, dayStart, dayEnd).
+			First(&record).Error
+		if err != nil {
+			log.Printf("Warning: delivery record not found for school %d: %v", alloc.SchoolID, err)
+			continue
+		}
+
+		if err := s.UpdateDeliveryStatus(record.ID, "selesai_dimasak", userID, "Cooking completed, ready for packing"); err != nil {
+			log.Printf("Warning: failed to update delivery status for record %d: %v", record.ID, err)
+		}
+	}
+}
+
+// updateSchoolRecords moves every delivery record of a school on a date to
+// the given status
+func (s *MonitoringService) updateSchoolRecords(schoolID uint, date time.Time, status string, userID uint, notes string) {
+	records, err := s.GetDeliveryRecords(date, map[string]interface{}{"school_id": schoolID})
+	if err != nil {
+		log.Printf("Warning: failed to get delivery records for monitoring update: %v", err)
+		return
+	}
+
+	for _, record := range records {
+		if err := s.UpdateDeliveryStatus(record.ID, status, userID, notes); err != nil {
+			log.Printf("Warning: failed to update monitoring status for delivery record %d: %v", record.ID, err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/events"
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitoringService_FollowsKitchenAndPackingEvents(t *testing.T) {
+	db := setupMonitoringTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.MenuItemSchoolAllocation{}))
+	service := newTestMonitoringService(db)
+	bus := events.NewBus()
+	service.SubscribeEvents(bus)
+
+	school := &models.School{Name: "SD Event", Category: "SD", IsActive: true}
+	require.NoError(t, db.Create(school).Error)
+	today := time.Now().Truncate(24 * time.Hour)
+	menuItem := &models.MenuItem{MenuPlanID: 1, Date: today, RecipeID: 1, Portions: 100}
+	require.NoError(t, db.Create(menuItem).Error)
+	require.NoError(t, db.Create(&[]models.MenuItemSchoolAllocation{
+		{MenuItemID: menuItem.ID, SchoolID: school.ID, Portions: 40, PortionSize: "small", Date: today},
+		{MenuItemID: menuItem.ID, SchoolID: school.ID, Portions: 60, PortionSize: "large", Date: today},
+	}).Error)
+
+	bus.Publish(events.CookingStarted{MenuItemID: menuItem.ID, RecipeID: 1, Date: today, UserID: 1})
+
+	var records []models.DeliveryRecord
+	require.NoError(t, db.Where("menu_item_id = ?", menuItem.ID).Find(&records).Error)
+	require.Len(t, records, 1, "one delivery record per school")
+	assert.Equal(t, "sedang_dimasak", records[0].CurrentStatus)
+	assert.Equal(t, 40, records[0].PortionsSmall)
+	assert.Equal(t, 60, records[0].PortionsLarge)
+
+	bus.Publish(events.CookingCompleted{MenuItemID: menuItem.ID, RecipeID: 1, Date: today, UserID: 1})
+	bus.Publish(events.PackingStarted{SchoolID: school.ID, Date: today, UserID: 2})
+
+	var record models.DeliveryRecord
+	require.NoError(t, db.First(&record, records[0].ID).Error)
+	assert.Equal(t, "siap_dipacking", record.CurrentStatus)
+
+	var transitions int64
+	db.Model(&models.StatusTransition{}).Where("delivery_record_id = ?", record.ID).Count(&transitions)
+	assert.Equal(t, int64(3), transitions)
+}
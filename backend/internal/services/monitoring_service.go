@@ -6,47 +6,34 @@ import (
 	"log"
 	"time"
 
-	firebase "firebase.google.com/go/v4"
-	"firebase.google.com/go/v4/db"
 	"gorm.io/gorm"
 
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/realtime"
 )
 
-// syncRetryItem represents an item in the retry queue
-type syncRetryItem struct {
-	recordID    uint
-	attempt     int
-	nextRetryAt time.Time
-}
-
 // MonitoringService handles logistics monitoring operations
 type MonitoringService struct {
-	db          *gorm.DB
-	firebaseApp *firebase.App
-	dbClient    *db.Client
-	retryQueue  chan syncRetryItem
+	db        *gorm.DB
+	publisher realtime.RealtimePublisher
 }
 
 // NewMonitoringService creates a new monitoring service
-func NewMonitoringService(database *gorm.DB, firebaseApp *firebase.App) (*MonitoringService, error) {
-	ctx := context.Background()
-	dbClient, err := firebaseApp.Database(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get Firebase database client: %w", err)
-	}
-
-	service := &MonitoringService{
-		db:          database,
-		firebaseApp: firebaseApp,
-		dbClient:    dbClient,
-		retryQueue:  make(chan syncRetryItem, 100), // Buffer for 100 retry items
-	}
-
-	// Start retry worker goroutine
-	go service.retryWorker()
+func NewMonitoringService(database *gorm.DB, publisher realtime.RealtimePublisher) (*MonitoringService, error) {
+	return &MonitoringService{
+		db:        database,
+		publisher: publisher,
+	}, nil
+}
 
-	return service, nil
+// RegisterOutboxHandlers lets the outbox dispatcher deliver the realtime
+// syncs of delivery records
+func (s *MonitoringService) RegisterOutboxHandlers(outbox *OutboxService) {
+	outbox.RegisterHandler(OutboxEventDeliverySync, func(ctx context.Context, event *models.OutboxEvent) error {
+		// The current state is published, so a late retry never overwrites newer data
+		return s.syncToFirebase(&models.DeliveryRecord{ID: event.AggregateID})
+	})
 }
 
 // getJakartaTime returns current time in Asia/Jakarta timezone
@@ -94,7 +81,8 @@ func (s *MonitoringService) GetDeliveryRecords(date time.Time, filters map[strin
 	var records []models.DeliveryRecord
 
 	// Start with base query filtering by date
-	query := s.db.Where("DATE(delivery_date) = DATE(?)", date)
+	dayStart, dayEnd := localDayRange(date)
+	query := s.db.Where("delivery_date >= ? AND delivery_date < ?", dayStart, dayEnd)
 
 	// Apply optional filters
 	if schoolID, ok := filters["school_id"]; ok {
@@ -148,7 +136,7 @@ func (s *MonitoringService) GetDeliveryRecordDetail(recordID uint) (*models.Deli
 // 3. Validates the stage sequence using ValidateStageSequence
 // 4. Updates the delivery record's current_status and current_stage in a database transaction
 // 5. Creates a StatusTransition record with timestamp and user attribution
-// 6. Triggers Firebase sync asynchronously (non-blocking)
+// 6. Records the realtime sync in the outbox within the same transaction
 //
 // Parameters:
 //   - recordID: The ID of the delivery record to update
@@ -164,6 +152,13 @@ func (s *MonitoringService) GetDeliveryRecordDetail(recordID uint) (*models.Deli
 //
 // Requirements: 2.1-2.8, 3.1-3.5, 9.1, 13.1-13.5
 func (s *MonitoringService) UpdateDeliveryStatus(recordID uint, newStatus string, userID uint, notes string) error {
+	return s.UpdateDeliveryStatusAt(recordID, newStatus, userID, notes, time.Time{})
+}
+
+// UpdateDeliveryStatusAt updates the status like UpdateDeliveryStatus, recording
+// occurredAt as the transition time. Offline clients pass the time the action
+// really happened; a zero occurredAt means now.
+func (s *MonitoringService) UpdateDeliveryStatusAt(recordID uint, newStatus string, userID uint, notes string, occurredAt time.Time) error {
 	// Step 1: Retrieve the current delivery record
 	var record models.DeliveryRecord
 	if err := s.db.First(&record, recordID).Error; err != nil {
@@ -186,6 +181,7 @@ func (s *MonitoringService) UpdateDeliveryStatus(recordID uint, newStatus string
 	stageNumber := getStageNumberFromStatus(newStatus)
 
 	// Step 4 & 5: Update delivery record and create status transition in a transaction
+	var published []events.Event
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Update delivery record's current_status and current_stage
 		if err := tx.Model(&record).Updates(map[string]interface{}{
@@ -195,13 +191,18 @@ func (s *MonitoringService) UpdateDeliveryStatus(recordID uint, newStatus string
 			return err
 		}
 
+		transitionedAt, err := offlineEventTime(tx, recordID, occurredAt, getJakartaTime())
+		if err != nil {
+			return err
+		}
+
 		// Create StatusTransition record
 		transition := models.StatusTransition{
 			DeliveryRecordID: recordID,
 			FromStatus:       currentStatus,
 			ToStatus:         newStatus,
 			Stage:            stageNumber,
-			TransitionedAt:   getJakartaTime(),
+			TransitionedAt:   transitionedAt,
 			TransitionedBy:   userID,
 			Notes:            notes,
 		}
@@ -210,13 +211,27 @@ func (s *MonitoringService) UpdateDeliveryStatus(recordID uint, newStatus string
 			return err
 		}
 
-		return nil
+		// Record the realtime sync in the same transaction; the outbox
+		// dispatcher delivers it with retries
+		if s.publisher != nil {
+			if err := EnqueueOutboxEvent(tx, OutboxEventDeliverySync, "delivery_record", recordID, map[string]interface{}{
+				"status": newStatus,
+				"stage":  stageNumber,
+			}); err != nil {
+				return err
+			}
+		}
+
+		published = deliveryStatusEvents(&record, newStatus, transitionedAt)
+		return events.PublishTx(tx, published...)
 	})
 
 	if err != nil {
 		return err
 	}
 
+	events.Notify(published...)
+
 	// Step 6: Check for automatic pickup task completion
 	// If the delivery record just transitioned to stage 13 and is part of a pickup task,
 	// check if all other delivery records in the same pickup task are also at stage 13
@@ -227,28 +242,39 @@ func (s *MonitoringService) UpdateDeliveryStatus(recordID uint, newStatus string
 		}
 	}
 
-	// Step 7: Trigger Firebase sync asynchronously (non-blocking)
-	// Update the record's current status for Firebase sync
-	record.CurrentStatus = newStatus
-	record.CurrentStage = stageNumber
-
-	// Only spawn goroutine if Firebase is configured
-	if s.dbClient != nil {
-		go func() {
-			if syncErr := s.syncToFirebaseWithRetry(&record, 0); syncErr != nil {
-				// Log error but don't block the main operation
-				log.Printf("Firebase sync failed for delivery record %d after all retries: %v", record.ID, syncErr)
-			}
-		}()
-	}
+	return nil
+}
 
+// deliveryStatusEvents returns the domain event of a delivery status other
+// modules and partner systems react to, if the status has one
+func deliveryStatusEvents(record *models.DeliveryRecord, status string, at time.Time) []events.Event {
+	switch status {
+	case "sudah_sampai_sekolah":
+		return []events.Event{events.DeliveryArrived{
+			DeliveryRecordID: record.ID,
+			SchoolID:         record.SchoolID,
+			DriverID:         record.DriverID,
+			ArrivedAt:        at,
+		}}
+	case "sudah_diterima_pihak_sekolah":
+		return []events.Event{events.DeliveryCompleted{
+			DeliveryRecordID: record.ID,
+			SchoolID:         record.SchoolID,
+			DriverID:         record.DriverID,
+			DeliveryDate:     record.DeliveryDate,
+			Portions:         record.Portions,
+			PortionsSmall:    record.PortionsSmall,
+			PortionsLarge:    record.PortionsLarge,
+			ReceivedAt:       at,
+		}}
+	}
 	return nil
 }
 
 // GetActivityLog retrieves the activity log (status transition history) for a delivery record.
 // It performs the following steps:
 // 1. Queries status_transitions table where delivery_record_id = recordID
-// 2. Orders results by transitioned_at in chronological order (ASC)
+// 2. Orders results by transitioned_at, newest first (DESC)
 // 3. Preloads User association for each transition to get transitioned_by user details
 // 4. Returns the complete activity log with all transitions
 //
@@ -259,7 +285,7 @@ func (s *MonitoringService) UpdateDeliveryStatus(recordID uint, newStatus string
 //   - recordID: The ID of the delivery record to get activity log for
 //
 // Returns:
-//   - []models.StatusTransition: Array of status transitions in chronological order
+//   - []models.StatusTransition: Array of status transitions, newest first
 //   - error: Error if query fails or record not found
 //
 // Requirements: 1.5, 9.2, 9.3
@@ -267,11 +293,12 @@ func (s *MonitoringService) GetActivityLog(recordID uint) ([]models.StatusTransi
 	var transitions []models.StatusTransition
 
 	// Query status_transitions for the delivery record
-	// Order by transitioned_at in descending order (newest first)
+	// Order by transitioned_at in descending order (newest first), by id within
+	// the same time so the order is stable
 	// Preload User association for transitioned_by
 	err := s.db.
 		Where("delivery_record_id = ?", recordID).
-		Order("transitioned_at DESC").
+		Order("transitioned_at DESC, id DESC").
 		Preload("User").
 		Find(&transitions).Error
 
@@ -303,11 +330,12 @@ func (s *MonitoringService) GetDailySummary(date time.Time) (*models.DailySummar
 
 	// Initialize status counts map
 	summary.StatusCounts = make(map[string]int)
+	dayStart, dayEnd := localDayRange(date)
 
 	// Count total delivery records for the date
 	var totalCount int64
 	if err := s.db.Model(&models.DeliveryRecord{}).
-		Where("DATE(delivery_date) = DATE(?)", date).
+		Where("delivery_date >= ? AND delivery_date < ?", dayStart, dayEnd).
 		Count(&totalCount).Error; err != nil {
 		return nil, err
 	}
@@ -320,7 +348,7 @@ func (s *MonitoringService) GetDailySummary(date time.Time) (*models.DailySummar
 	}
 	if err := s.db.Model(&models.DeliveryRecord{}).
 		Select("current_status, COUNT(*) as count").
-		Where("DATE(delivery_date) = DATE(?)", date).
+		Where("delivery_date >= ? AND delivery_date < ?", dayStart, dayEnd).
 		Group("current_status").
 		Scan(&statusResults).Error; err != nil {
 		return nil, err
@@ -334,7 +362,7 @@ func (s *MonitoringService) GetDailySummary(date time.Time) (*models.DailySummar
 	// Count completed deliveries (status = "sudah_diterima_pihak_sekolah")
 	var completedCount int64
 	if err := s.db.Model(&models.DeliveryRecord{}).
-		Where("DATE(delivery_date) = DATE(?) AND current_status = ?", date, "sudah_diterima_pihak_sekolah").
+		Where("delivery_date >= ? AND delivery_date < ? AND current_status = ?", dayStart, dayEnd, "sudah_diterima_pihak_sekolah").
 		Count(&completedCount).Error; err != nil {
 		return nil, err
 	}
@@ -343,7 +371,7 @@ func (s *MonitoringService) GetDailySummary(date time.Time) (*models.DailySummar
 	// Count ompreng in cleaning (status = "ompreng_proses_pencucian")
 	var cleaningCount int64
 	if err := s.db.Model(&models.DeliveryRecord{}).
-		Where("DATE(delivery_date) = DATE(?) AND current_status = ?", date, "ompreng_proses_pencucian").
+		Where("delivery_date >= ? AND delivery_date < ? AND current_status = ?", dayStart, dayEnd, "ompreng_proses_pencucian").
 		Count(&cleaningCount).Error; err != nil {
 		return nil, err
 	}
@@ -352,7 +380,7 @@ func (s *MonitoringService) GetDailySummary(date time.Time) (*models.DailySummar
 	// Count ompreng cleaned (status = "ompreng_selesai_dicuci")
 	var cleanedCount int64
 	if err := s.db.Model(&models.DeliveryRecord{}).
-		Where("DATE(delivery_date) = DATE(?) AND current_status = ?", date, "ompreng_selesai_dicuci").
+		Where("delivery_date >= ? AND delivery_date < ? AND current_status = ?", dayStart, dayEnd, "ompreng_selesai_dicuci").
 		Count(&cleanedCount).Error; err != nil {
 		return nil, err
 	}
@@ -366,17 +394,10 @@ func (s *MonitoringService) GetDailySummary(date time.Time) (*models.DailySummar
 // /monitoring/deliveries/{date}/record_{id} path.
 //
 // This method formats the delivery record data and writes it to Firebase.
-// Errors are returned but should be handled gracefully by the caller,
-// typically with retry logic.
+// Errors are returned to the outbox dispatcher, which retries the sync.
 //
 // Requirements: 1.1
 func (s *MonitoringService) syncToFirebase(record *models.DeliveryRecord) error {
-	// Format date as YYYY-MM-DD for Firebase path
-	dateStr := record.DeliveryDate.Format("2006-01-02")
-
-	// Construct Firebase path: /monitoring/deliveries/{date}/record_{id}
-	firebasePath := fmt.Sprintf("/monitoring/deliveries/%s/record_%d", dateStr, record.ID)
-
 	// Prepare data for Firebase
 	// Note: We need to preload associations if they're not already loaded
 	var fullRecord models.DeliveryRecord
@@ -387,6 +408,12 @@ func (s *MonitoringService) syncToFirebase(record *models.DeliveryRecord) error
 		return err
 	}
 
+	// Format date as YYYY-MM-DD for Firebase path
+	dateStr := fullRecord.DeliveryDate.Format("2006-01-02")
+
+	// Construct Firebase path: /monitoring/deliveries/{date}/record_{id}
+	firebasePath := fmt.Sprintf("/monitoring/deliveries/%s/record_%d", dateStr, fullRecord.ID)
+
 	// Format data for Firebase
 	data := map[string]interface{}{
 		"id":             fullRecord.ID,
@@ -398,11 +425,9 @@ func (s *MonitoringService) syncToFirebase(record *models.DeliveryRecord) error
 		"last_updated":   time.Now().Unix(),
 	}
 
-	// Write to Firebase
-	if s.dbClient != nil {
-		ref := s.dbClient.NewRef(firebasePath)
-		ctx := context.Background()
-		if err := ref.Set(ctx, data); err != nil {
+	// Publish to connected clients
+	if s.publisher != nil {
+		if err := s.publisher.Set(context.Background(), firebasePath, data); err != nil {
 			return err
 		}
 	}
@@ -410,112 +435,6 @@ func (s *MonitoringService) syncToFirebase(record *models.DeliveryRecord) error
 	return nil
 }
 
-// syncToFirebaseWithRetry attempts to sync to Firebase with exponential backoff retry logic.
-// It implements the following retry strategy:
-// - Attempt 1: Immediate
-// - Attempt 2: 1 second delay
-// - Attempt 3: 2 seconds delay
-// - Attempt 4: 4 seconds delay
-// - Attempt 5: 8 seconds delay
-// - Attempt 6: 16 seconds delay
-// - Maximum retry attempts: 5 (total 6 attempts including initial)
-//
-// After max retries, the error is logged for admin alerting.
-//
-// Parameters:
-//   - record: The delivery record to sync
-//   - attempt: Current attempt number (0-based)
-//
-// Returns:
-//   - error: Error if all retry attempts fail
-//
-// Requirements: 1.1
-func (s *MonitoringService) syncToFirebaseWithRetry(record *models.DeliveryRecord, attempt int) error {
-	const maxRetries = 5
-
-	// Attempt to sync to Firebase
-	err := s.syncToFirebase(record)
-
-	if err == nil {
-		// Success
-		return nil
-	}
-
-	// Check if we've exceeded max retries
-	if attempt >= maxRetries {
-		// Log error for admin alerting
-		log.Printf("ERROR: Firebase sync failed for delivery record %d after %d attempts: %v",
-			record.ID, attempt+1, err)
-		return fmt.Errorf("firebase sync failed after %d attempts: %w", attempt+1, err)
-	}
-
-	// Calculate exponential backoff delay: 1s, 2s, 4s, 8s, 16s
-	backoffSeconds := 1 << attempt // 2^attempt
-	delay := time.Duration(backoffSeconds) * time.Second
-
-	log.Printf("Firebase sync failed for delivery record %d (attempt %d/%d), retrying in %v: %v",
-		record.ID, attempt+1, maxRetries+1, delay, err)
-
-	// Queue for retry with delay
-	go func() {
-		time.Sleep(delay)
-		if retryErr := s.syncToFirebaseWithRetry(record, attempt+1); retryErr != nil {
-			// Final error already logged in the recursive call
-			_ = retryErr
-		}
-	}()
-
-	return nil
-}
-
-// retryWorker processes items from the retry queue
-// This is a background worker that handles delayed retries
-func (s *MonitoringService) retryWorker() {
-	for item := range s.retryQueue {
-		// Wait until it's time to retry
-		now := time.Now()
-		if item.nextRetryAt.After(now) {
-			time.Sleep(item.nextRetryAt.Sub(now))
-		}
-
-		// Retrieve the delivery record
-		var record models.DeliveryRecord
-		if err := s.db.First(&record, item.recordID).Error; err != nil {
-			log.Printf("Failed to retrieve delivery record %d for retry: %v", item.recordID, err)
-			continue
-		}
-
-		// Attempt sync with retry
-		go func() {
-			if err := s.syncToFirebaseWithRetry(&record, item.attempt); err != nil {
-				log.Printf("Retry failed for delivery record %d: %v", record.ID, err)
-			}
-		}()
-	}
-}
-
-// queueForRetry adds a delivery record to the retry queue
-func (s *MonitoringService) queueForRetry(recordID uint, attempt int) {
-	// Calculate next retry time with exponential backoff
-	backoffSeconds := 1 << attempt // 2^attempt
-	nextRetryAt := time.Now().Add(time.Duration(backoffSeconds) * time.Second)
-
-	item := syncRetryItem{
-		recordID:    recordID,
-		attempt:     attempt,
-		nextRetryAt: nextRetryAt,
-	}
-
-	// Non-blocking send to queue
-	select {
-	case s.retryQueue <- item:
-		log.Printf("Queued delivery record %d for retry (attempt %d) at %v",
-			recordID, attempt+1, nextRetryAt)
-	default:
-		log.Printf("WARNING: Retry queue full, dropping retry for delivery record %d", recordID)
-	}
-}
-
 // checkAndCompletePickupTask checks if all delivery records in a pickup task are at stage 13
 // and automatically updates the pickup task status to 'completed' if so.
 // This method is called after a delivery record transitions to stage 13.
@@ -6,12 +6,11 @@ import (
 
 	"github.com/erp-sppg/backend/internal/models"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 func setupMonitoringTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
 	if err != nil {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
@@ -37,10 +36,8 @@ func setupMonitoringTestDB(t *testing.T) *gorm.DB {
 // Helper function to create a monitoring service for tests (without Firebase)
 func newTestMonitoringService(db *gorm.DB) *MonitoringService {
 	return &MonitoringService{
-		db:          db,
-		firebaseApp: nil,
-		dbClient:    nil,
-		retryQueue:  make(chan syncRetryItem, 100),
+		db:        db,
+		publisher: nil,
 	}
 }
 
@@ -742,19 +739,19 @@ func TestMonitoringService_GetActivityLog_Success(t *testing.T) {
 	assert.NotNil(t, activityLog)
 	assert.Equal(t, 3, len(activityLog))
 
-	// Verify chronological order (ASC)
-	assert.Equal(t, transition1.ID, activityLog[0].ID)
+	// Verify newest first (DESC)
+	assert.Equal(t, transition3.ID, activityLog[0].ID)
 	assert.Equal(t, transition2.ID, activityLog[1].ID)
-	assert.Equal(t, transition3.ID, activityLog[2].ID)
+	assert.Equal(t, transition1.ID, activityLog[2].ID)
 
 	// Verify first transition
-	assert.Equal(t, "", activityLog[0].FromStatus)
-	assert.Equal(t, "sedang_dimasak", activityLog[0].ToStatus)
-	assert.Equal(t, "Started cooking", activityLog[0].Notes)
-	assert.Equal(t, chef.ID, activityLog[0].TransitionedBy)
-	assert.NotNil(t, activityLog[0].User)
-	assert.Equal(t, "Chef Test", activityLog[0].User.FullName)
-	assert.Equal(t, "chef", activityLog[0].User.Role)
+	assert.Equal(t, "", activityLog[2].FromStatus)
+	assert.Equal(t, "sedang_dimasak", activityLog[2].ToStatus)
+	assert.Equal(t, "Started cooking", activityLog[2].Notes)
+	assert.Equal(t, chef.ID, activityLog[2].TransitionedBy)
+	assert.NotNil(t, activityLog[2].User)
+	assert.Equal(t, "Chef Test", activityLog[2].User.FullName)
+	assert.Equal(t, "chef", activityLog[2].User.Role)
 
 	// Verify second transition
 	assert.Equal(t, "sedang_dimasak", activityLog[1].FromStatus)
@@ -765,15 +762,15 @@ func TestMonitoringService_GetActivityLog_Success(t *testing.T) {
 	assert.Equal(t, "Chef Test", activityLog[1].User.FullName)
 
 	// Verify third transition
-	assert.Equal(t, "selesai_dimasak", activityLog[2].FromStatus)
-	assert.Equal(t, "siap_dipacking", activityLog[2].ToStatus)
-	assert.Equal(t, "Ready for packing", activityLog[2].Notes)
+	assert.Equal(t, "selesai_dimasak", activityLog[0].FromStatus)
+	assert.Equal(t, "siap_dipacking", activityLog[0].ToStatus)
+	assert.Equal(t, "Ready for packing", activityLog[0].Notes)
 
 	// Verify elapsed time can be calculated
-	elapsed1 := activityLog[1].TransitionedAt.Sub(activityLog[0].TransitionedAt)
+	elapsed1 := activityLog[1].TransitionedAt.Sub(activityLog[2].TransitionedAt)
 	assert.Equal(t, 90*time.Minute, elapsed1) // 1.5 hours
 
-	elapsed2 := activityLog[2].TransitionedAt.Sub(activityLog[1].TransitionedAt)
+	elapsed2 := activityLog[0].TransitionedAt.Sub(activityLog[1].TransitionedAt)
 	assert.Equal(t, 30*time.Minute, elapsed2) // 30 minutes
 }
 
@@ -1020,20 +1017,20 @@ func TestMonitoringService_GetActivityLog_MultipleUsers(t *testing.T) {
 	assert.Equal(t, 5, len(activityLog))
 
 	// Verify User associations are preloaded with correct users
-	assert.Equal(t, "Chef Test", activityLog[0].User.FullName)
-	assert.Equal(t, "chef", activityLog[0].User.Role)
+	assert.Equal(t, "Chef Test", activityLog[4].User.FullName)
+	assert.Equal(t, "chef", activityLog[4].User.Role)
 
-	assert.Equal(t, "Chef Test", activityLog[1].User.FullName)
-	assert.Equal(t, "chef", activityLog[1].User.Role)
+	assert.Equal(t, "Chef Test", activityLog[3].User.FullName)
+	assert.Equal(t, "chef", activityLog[3].User.Role)
 
 	assert.Equal(t, "Packing Staff", activityLog[2].User.FullName)
 	assert.Equal(t, "packing", activityLog[2].User.Role)
 
-	assert.Equal(t, "Packing Staff", activityLog[3].User.FullName)
-	assert.Equal(t, "packing", activityLog[3].User.Role)
+	assert.Equal(t, "Packing Staff", activityLog[1].User.FullName)
+	assert.Equal(t, "packing", activityLog[1].User.Role)
 
-	assert.Equal(t, "Driver Test", activityLog[4].User.FullName)
-	assert.Equal(t, "driver", activityLog[4].User.Role)
+	assert.Equal(t, "Driver Test", activityLog[0].User.FullName)
+	assert.Equal(t, "driver", activityLog[0].User.Role)
 }
 
 func TestMonitoringService_GetDailySummary_Success(t *testing.T) {
@@ -1624,3 +1621,48 @@ func TestMonitoringService_AutoCompletePickupTask_NoPickupTask(t *testing.T) {
 	assert.Equal(t, 13, updatedRecord.CurrentStage)
 	assert.Equal(t, "driver_tiba_di_sppg", updatedRecord.CurrentStatus)
 }
+
+func TestMonitoringService_GetDailySummary_LocalDayBoundaries(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skipf("zona waktu tidak tersedia: %v", err)
+	}
+	previousLocal := time.Local
+	time.Local = jakarta
+	t.Cleanup(func() { time.Local = previousLocal })
+
+	db := setupMonitoringTestDB(t)
+	service := newTestMonitoringService(db)
+
+	school := &models.School{Name: "SD Negeri 1", Category: "SD", StudentCount: 150, IsActive: true}
+	db.Create(school)
+
+	for _, deliveryDate := range []time.Time{
+		// Still 9 March in UTC
+		time.Date(2024, 3, 10, 0, 0, 0, 0, jakarta),
+		time.Date(2024, 3, 10, 6, 30, 0, 0, jakarta),
+		// A date-only value parsed as UTC
+		time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 10, 23, 59, 0, 0, jakarta),
+		// Outside the day
+		time.Date(2024, 3, 9, 23, 59, 0, 0, jakarta),
+		time.Date(2024, 3, 11, 0, 0, 0, 0, jakarta),
+	} {
+		db.Create(&models.DeliveryRecord{
+			DeliveryDate:  deliveryDate,
+			SchoolID:      school.ID,
+			Portions:      10,
+			CurrentStatus: "sedang_dimasak",
+			CurrentStage:  1,
+		})
+	}
+
+	day, _ := time.Parse("2006-01-02", "2024-03-10")
+	summary, err := service.GetDailySummary(day)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, summary.TotalDeliveries)
+
+	records, err := service.GetDeliveryRecords(day, map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+}
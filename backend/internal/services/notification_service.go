@@ -3,17 +3,19 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
 	"time"
 
-	firebase "firebase.google.com/go/v4"
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/realtime"
 	"gorm.io/gorm"
 )
 
 // NotificationService handles notification operations
 type NotificationService struct {
-	db          *gorm.DB
-	firebaseSync *FirebaseSyncService
+	db           *gorm.DB
+	realtimeSync *RealtimeSyncService
 }
 
 // NotificationType constants
@@ -22,18 +24,24 @@ const (
 	NotificationTypePOApproval       = "po_approval"
 	NotificationTypePackingComplete  = "packing_complete"
 	NotificationTypeDeliveryComplete = "delivery_complete"
+	NotificationTypeBudgetAlert      = "budget_alert"
+	NotificationTypePettyCash        = "petty_cash"
+	NotificationTypeMaintenanceDue   = "maintenance_due"
+	NotificationTypeAssetAudit       = "asset_audit"
+	NotificationTypeStokOpname       = "stok_opname"
 )
 
-// NewNotificationService creates a new notification service
-func NewNotificationService(db *gorm.DB, firebaseApp *firebase.App) (*NotificationService, error) {
-	firebaseSync, err := NewFirebaseSyncService(firebaseApp)
-	if err != nil {
-		return nil, err
-	}
+// notificationSubscriber names the notification handlers in the event outbox
+const notificationSubscriber = "notifications"
 
+// lowStockRecipientRoles are notified when an ingredient runs low
+var lowStockRecipientRoles = []string{"kepala_sppg", "pengadaan"}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(db *gorm.DB, publisher realtime.RealtimePublisher) (*NotificationService, error) {
 	return &NotificationService{
-		db:          db,
-		firebaseSync: firebaseSync,
+		db:           db,
+		realtimeSync: NewRealtimeSyncService(publisher),
 	}, nil
 }
 
@@ -46,10 +54,10 @@ func (s *NotificationService) CreateNotification(ctx context.Context, notificati
 		return fmt.Errorf("gagal membuat notifikasi: %w", err)
 	}
 
-	// Push to Firebase for real-time notification
-	if err := s.pushNotificationToFirebase(ctx, notification); err != nil {
+	// Push to connected clients for real-time notification
+	if err := s.pushNotificationRealtime(ctx, notification); err != nil {
 		// Log error but don't fail the operation
-		fmt.Printf("Peringatan: gagal mengirim notifikasi ke Firebase: %v\n", err)
+		fmt.Printf("Peringatan: gagal mengirim notifikasi realtime: %v\n", err)
 	}
 
 	return nil
@@ -69,12 +77,12 @@ func (s *NotificationService) SendLowStockNotification(ctx context.Context, user
 }
 
 // SendPOApprovalNotification sends a PO approval request notification
-func (s *NotificationService) SendPOApprovalNotification(ctx context.Context, userID uint, poNumber string, supplierName string, totalAmount float64) error {
+func (s *NotificationService) SendPOApprovalNotification(ctx context.Context, userID uint, poNumber string, supplierName string, totalAmount models.Money) error {
 	notification := &models.Notification{
 		UserID:  userID,
 		Type:    NotificationTypePOApproval,
 		Title:   "Permintaan Persetujuan Purchase Order",
-		Message: fmt.Sprintf("PO %s dari %s memerlukan persetujuan. Total: Rp %.2f", poNumber, supplierName, totalAmount),
+		Message: fmt.Sprintf("PO %s dari %s memerlukan persetujuan. Total: Rp %s", poNumber, supplierName, totalAmount),
 		Link:    fmt.Sprintf("/purchase-orders/%s", poNumber),
 	}
 
@@ -107,6 +115,49 @@ func (s *NotificationService) SendDeliveryCompleteNotification(ctx context.Conte
 	return s.CreateNotification(ctx, notification)
 }
 
+// SendStokOpnameApprovedNotification tells the creator of a stok opname form
+// that it was approved and the stock adjusted
+func (s *NotificationService) SendStokOpnameApprovedNotification(ctx context.Context, userID uint, formID uint, formNumber string) error {
+	notification := &models.Notification{
+		UserID:  userID,
+		Type:    NotificationTypeStokOpname,
+		Title:   "Stok Opname Disetujui",
+		Message: fmt.Sprintf("Form stok opname %s telah disetujui dan stok sudah disesuaikan", formNumber),
+		Link:    fmt.Sprintf("/inventory/stok-opname/%d", formID),
+	}
+
+	return s.CreateNotification(ctx, notification)
+}
+
+// SubscribeEvents sends notifications for domain events. The subscriptions
+// are durable: the events are kept in the outbox and retried until the
+// notifications are created.
+func (s *NotificationService) SubscribeEvents(bus *events.Bus) {
+	bus.SubscribeDurable(events.StockBelowThresholdEvent, notificationSubscriber, func(ctx context.Context, e events.Event) error {
+		event := e.(events.StockBelowThreshold)
+
+		var userIDs []uint
+		if err := s.db.Model(&models.User{}).
+			Where("role IN ? AND is_active = ?", lowStockRecipientRoles, true).
+			Pluck("id", &userIDs).Error; err != nil {
+			return fmt.Errorf("gagal mengambil penerima notifikasi stok: %w", err)
+		}
+
+		for _, userID := range userIDs {
+			if err := s.SendLowStockNotification(ctx, userID, event.IngredientName, event.Quantity, event.MinThreshold); err != nil {
+				// Not retried, so the other recipients are not notified twice
+				log.Printf("Peringatan: gagal mengirim notifikasi stok menipis ke user %d: %v", userID, err)
+			}
+		}
+		return nil
+	})
+
+	bus.SubscribeDurable(events.StokOpnameApprovedEvent, notificationSubscriber, func(ctx context.Context, e events.Event) error {
+		event := e.(events.StokOpnameApproved)
+		return s.SendStokOpnameApprovedNotification(ctx, event.CreatedBy, event.FormID, event.FormNumber)
+	})
+}
+
 // GetUserNotifications retrieves all notifications for a user
 func (s *NotificationService) GetUserNotifications(userID uint, limit, offset int) ([]models.Notification, int64, error) {
 	var notifications []models.Notification
@@ -153,13 +204,13 @@ func (s *NotificationService) MarkAsRead(ctx context.Context, notificationID, us
 		return fmt.Errorf("notifikasi tidak ditemukan")
 	}
 
-	// Update Firebase
+	// Update connected clients
 	path := fmt.Sprintf("/notifications/%d/%d", userID, notificationID)
-	if err := s.firebaseSync.UpdateField(ctx, path, map[string]interface{}{
+	if err := s.realtimeSync.UpdateField(ctx, path, map[string]interface{}{
 		"is_read": true,
 	}); err != nil {
 		// Log error but don't fail the operation
-		fmt.Printf("Peringatan: gagal mengupdate notifikasi di Firebase: %v\n", err)
+		fmt.Printf("Peringatan: gagal mengupdate notifikasi realtime: %v\n", err)
 	}
 
 	return nil
@@ -189,18 +240,18 @@ func (s *NotificationService) DeleteNotification(ctx context.Context, notificati
 		return fmt.Errorf("notifikasi tidak ditemukan")
 	}
 
-	// Delete from Firebase
+	// Delete from connected clients
 	path := fmt.Sprintf("/notifications/%d/%d", userID, notificationID)
-	if err := s.firebaseSync.DeletePath(ctx, path); err != nil {
+	if err := s.realtimeSync.DeletePath(ctx, path); err != nil {
 		// Log error but don't fail the operation
-		fmt.Printf("Peringatan: gagal menghapus notifikasi dari Firebase: %v\n", err)
+		fmt.Printf("Peringatan: gagal menghapus notifikasi realtime: %v\n", err)
 	}
 
 	return nil
 }
 
-// pushNotificationToFirebase pushes notification to Firebase for real-time delivery
-func (s *NotificationService) pushNotificationToFirebase(ctx context.Context, notification *models.Notification) error {
+// pushNotificationRealtime pushes a notification to connected clients for real-time delivery
+func (s *NotificationService) pushNotificationRealtime(ctx context.Context, notification *models.Notification) error {
 	path := fmt.Sprintf("/notifications/%d/%d", notification.UserID, notification.ID)
 	
 	data := map[string]interface{}{
@@ -213,5 +264,5 @@ func (s *NotificationService) pushNotificationToFirebase(ctx context.Context, no
 		"created_at": notification.CreatedAt.Unix(),
 	}
 
-	return s.firebaseSync.PushUpdate(ctx, path, data)
+	return s.realtimeSync.PushUpdate(ctx, path, data)
 }
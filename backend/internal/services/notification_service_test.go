@@ -1,17 +1,19 @@
 package services
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/realtime"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
 func setupNotificationTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
 	if err != nil {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
@@ -251,3 +253,33 @@ func TestNotificationTypes(t *testing.T) {
 	assert.Equal(t, "packing_complete", NotificationTypePackingComplete)
 	assert.Equal(t, "delivery_complete", NotificationTypeDeliveryComplete)
 }
+
+func TestNotificationService_PublishesToUserTopic(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	hub := realtime.NewHub()
+	service, err := NewNotificationService(db, hub)
+	require.NoError(t, err)
+
+	mine := hub.Subscribe(realtime.NotificationsTopic(7))
+	defer mine.Close()
+	other := hub.Subscribe(realtime.NotificationsTopic(8))
+	defer other.Close()
+	<-mine.Events
+	<-other.Events
+
+	require.NoError(t, service.SendLowStockNotification(context.Background(), 7, "Beras", 5, 10))
+
+	select {
+	case event := <-mine.Events:
+		assert.Equal(t, realtime.EventSet, event.Type)
+		assert.Contains(t, string(event.Data), "Peringatan Stok Menipis")
+	case <-time.After(time.Second):
+		t.Fatal("notification was not published to the user topic")
+	}
+
+	select {
+	case event := <-other.Events:
+		t.Fatalf("unexpected event for another user: %+v", event)
+	default:
+	}
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Operation types accepted by the batch sync
+const (
+	SyncOpDeliveryStatus = "delivery_status"
+	SyncOpPickupStage    = "pickup_stage"
+	SyncOpEPOD           = "epod"
+)
+
+// Results reported for operations that were not decided. The client keeps
+// them in its queue and sends them again with the same idempotency key.
+const (
+	SyncResultFailed  = "failed"  // a server error, the change was rolled back
+	SyncResultSkipped = "skipped" // not processed because an earlier operation failed
+)
+
+// syncClaimTimeout is how long a pending operation blocks a resend with the
+// same key; older claims were left behind by a crashed request
+const syncClaimTimeout = 5 * time.Minute
+
+// SyncOperationInput is an operation queued by an offline client
+type SyncOperationInput struct {
+	IdempotencyKey  string          `json:"idempotency_key"`
+	Type            string          `json:"type"`
+	ClientTimestamp time.Time       `json:"client_timestamp"`
+	Payload         json.RawMessage `json:"payload"`
+}
+
+// SyncOperationResult is the outcome of one synced operation. Applied,
+// conflict and rejected operations are final; failed and skipped ones should
+// be sent again.
+type SyncOperationResult struct {
+	IdempotencyKey string          `json:"idempotency_key"`
+	Type           string          `json:"type"`
+	Status         string          `json:"status"`
+	ErrorCode      string          `json:"error_code,omitempty"`
+	Message        string          `json:"message,omitempty"`
+	Data           json.RawMessage `json:"data,omitempty"`
+	Replayed       bool            `json:"replayed,omitempty"`
+}
+
+// DeliveryStatusSyncPayload mirrors PUT /monitoring/deliveries/:id/status
+type DeliveryStatusSyncPayload struct {
+	DeliveryRecordID uint   `json:"delivery_record_id"`
+	Status           string `json:"status"`
+	Notes            string `json:"notes"`
+}
+
+// PickupStageSyncPayload mirrors PUT /pickup-tasks/:id/delivery-records/:delivery_record_id/stage
+type PickupStageSyncPayload struct {
+	PickupTaskID            uint   `json:"pickup_task_id"`
+	DeliveryRecordID        uint   `json:"delivery_record_id"`
+	Stage                   int    `json:"stage"`
+	Status                  string `json:"status"`
+	OmprengReceived         *int   `json:"ompreng_received"`
+	OmprengDifferenceReason string `json:"ompreng_difference_reason"`
+}
+
+// EPODSyncPayload mirrors POST /epod; photo and signature are uploaded
+// separately once the ePOD exists
+type EPODSyncPayload struct {
+	DeliveryTaskID uint    `json:"delivery_task_id"`
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	RecipientName  string  `json:"recipient_name"`
+	OmprengDropOff int     `json:"ompreng_drop_off"`
+	OmprengPickUp  int     `json:"ompreng_pick_up"`
+}
+
+// OfflineSyncService applies the operations drivers queue while they have no
+// signal. Operations go through the same services and transition validation
+// as live requests, in the order the client queued them, and record the time
+// the client performed them.
+type OfflineSyncService struct {
+	db                *gorm.DB
+	monitoringService *MonitoringService
+	pickupTaskService *PickupTaskService
+	epodService       *EPODService
+	permissionService *PermissionService
+}
+
+// NewOfflineSyncService creates a new offline sync service
+func NewOfflineSyncService(db *gorm.DB, monitoringService *MonitoringService, pickupTaskService *PickupTaskService, permissionService *PermissionService) *OfflineSyncService {
+	return &OfflineSyncService{
+		db:                db,
+		monitoringService: monitoringService,
+		pickupTaskService: pickupTaskService,
+		epodService:       NewEPODService(db),
+		permissionService: permissionService,
+	}
+}
+
+// syncOutcome is the decision for one operation before it is stored
+type syncOutcome struct {
+	status    string
+	errorCode string
+	message   string
+	data      interface{}
+}
+
+// ApplyBatch applies the operations in order and returns one result per
+// operation. A conflict does not stop the batch, but a server error does:
+// the operations after it are skipped so they are never applied out of order.
+func (s *OfflineSyncService) ApplyBatch(userID uint, role string, ops []SyncOperationInput) []SyncOperationResult {
+	results := make([]SyncOperationResult, 0, len(ops))
+	stopped := false
+
+	for _, op := range ops {
+		if stopped {
+			results = append(results, SyncOperationResult{
+				IdempotencyKey: op.IdempotencyKey,
+				Type:           op.Type,
+				Status:         SyncResultSkipped,
+				ErrorCode:      "NOT_PROCESSED",
+				Message:        "Operasi belum diproses karena operasi sebelumnya gagal",
+			})
+			continue
+		}
+
+		result := s.applyOperation(userID, role, op)
+		if result.Status == SyncResultFailed {
+			stopped = true
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// applyOperation claims the idempotency key, applies the operation and stores
+// its result. A key that was already decided replays the stored result.
+func (s *OfflineSyncService) applyOperation(userID uint, role string, op SyncOperationInput) SyncOperationResult {
+	result := SyncOperationResult{IdempotencyKey: op.IdempotencyKey, Type: op.Type}
+
+	claim := models.SyncOperation{
+		UserID:          userID,
+		IdempotencyKey:  op.IdempotencyKey,
+		Type:            op.Type,
+		Status:          models.SyncStatusPending,
+		ClientTimestamp: op.ClientTimestamp,
+	}
+	claimed, err := s.claim(&claim)
+	if err != nil {
+		log.Printf("[SYNC] failed to claim operation %s: %v", op.IdempotencyKey, err)
+		return failedSyncResult(result)
+	}
+	if !claimed {
+		return s.replay(claim, result)
+	}
+
+	outcome := s.dispatch(userID, role, op)
+	if outcome.status == SyncResultFailed {
+		// Release the key so the resent operation is applied
+		if err := s.db.Delete(&claim).Error; err != nil {
+			log.Printf("[SYNC] failed to release operation %s: %v", op.IdempotencyKey, err)
+		}
+		return failedSyncResult(result)
+	}
+
+	result.Status = outcome.status
+	result.ErrorCode = outcome.errorCode
+	result.Message = outcome.message
+	if outcome.data != nil {
+		if data, err := json.Marshal(outcome.data); err == nil {
+			result.Data = data
+		}
+	}
+
+	stored, err := json.Marshal(result)
+	if err == nil {
+		err = s.db.Model(&claim).Updates(map[string]interface{}{
+			"status": outcome.status,
+			"result": string(stored),
+		}).Error
+	}
+	if err != nil {
+		// The operation is applied; a resend of a stale claim conflicts
+		// with the new state instead of applying twice
+		log.Printf("[SYNC] failed to store result of operation %s: %v", op.IdempotencyKey, err)
+	}
+
+	return result
+}
+
+// claim inserts the pending operation. It returns false with the existing
+// operation loaded when the key was already used, unless that claim is stale.
+func (s *OfflineSyncService) claim(op *models.SyncOperation) (bool, error) {
+	res := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(op)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil
+	}
+
+	var existing models.SyncOperation
+	if err := s.db.Where("user_id = ? AND idempotency_key = ?", op.UserID, op.IdempotencyKey).First(&existing).Error; err != nil {
+		return false, err
+	}
+
+	if existing.Status == models.SyncStatusPending && time.Since(existing.UpdatedAt) > syncClaimTimeout {
+		// Take over the stale claim; the update only matches once if two
+		// requests race for it
+		res := s.db.Model(&models.SyncOperation{}).
+			Where("id = ? AND status = ? AND updated_at = ?", existing.ID, models.SyncStatusPending, existing.UpdatedAt).
+			Updates(map[string]interface{}{
+				"type":             op.Type,
+				"client_timestamp": op.ClientTimestamp,
+				"updated_at":       time.Now(),
+			})
+		if res.Error != nil {
+			return false, res.Error
+		}
+		if res.RowsAffected == 1 {
+			op.ID = existing.ID
+			return true, nil
+		}
+	}
+
+	*op = existing
+	return false, nil
+}
+
+// replay returns the stored result of an operation that was already synced
+func (s *OfflineSyncService) replay(existing models.SyncOperation, result SyncOperationResult) SyncOperationResult {
+	if existing.Status == models.SyncStatusPending {
+		result.Status = SyncResultFailed
+		result.ErrorCode = "OPERATION_IN_PROGRESS"
+		result.Message = "Operasi dengan kunci ini sedang diproses"
+		return result
+	}
+
+	if existing.Type != result.Type {
+		result.Status = models.SyncStatusRejected
+		result.ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+		result.Message = "Kunci idempotensi sudah dipakai untuk operasi lain"
+		return result
+	}
+
+	var stored SyncOperationResult
+	if err := json.Unmarshal([]byte(existing.Result), &stored); err != nil {
+		stored = SyncOperationResult{IdempotencyKey: existing.IdempotencyKey, Type: existing.Type, Status: existing.Status}
+	}
+	stored.Replayed = true
+	return stored
+}
+
+// dispatch authorizes and applies one operation
+func (s *OfflineSyncService) dispatch(userID uint, role string, op SyncOperationInput) syncOutcome {
+	if op.ClientTimestamp.IsZero() {
+		return rejectedSyncOutcome("VALIDATION_ERROR", "Waktu operasi wajib diisi")
+	}
+
+	switch op.Type {
+	case SyncOpDeliveryStatus:
+		var payload DeliveryStatusSyncPayload
+		if err := json.Unmarshal(op.Payload, &payload); err != nil || payload.DeliveryRecordID == 0 || payload.Status == "" {
+			return rejectedSyncOutcome("VALIDATION_ERROR", "Data operasi tidak valid")
+		}
+		if !s.permissionService.HasPermission(userID, role, "monitoring") ||
+			!s.permissionService.CanUpdateStatus(userID, role, payload.Status) {
+			return rejectedSyncOutcome("FORBIDDEN", "Anda tidak memiliki izin untuk mengubah status ini")
+		}
+		return s.applyDeliveryStatus(userID, payload, op.ClientTimestamp)
+
+	case SyncOpPickupStage:
+		var payload PickupStageSyncPayload
+		if err := json.Unmarshal(op.Payload, &payload); err != nil || payload.PickupTaskID == 0 || payload.DeliveryRecordID == 0 {
+			return rejectedSyncOutcome("VALIDATION_ERROR", "Data operasi tidak valid")
+		}
+		if !s.permissionService.HasPermission(userID, role, "pickup_tasks") {
+			return rejectedSyncOutcome("FORBIDDEN", "Anda tidak memiliki izin untuk mengakses fitur ini")
+		}
+		return s.applyPickupStage(userID, payload, op.ClientTimestamp)
+
+	case SyncOpEPOD:
+		var payload EPODSyncPayload
+		if err := json.Unmarshal(op.Payload, &payload); err != nil || payload.DeliveryTaskID == 0 || payload.RecipientName == "" {
+			return rejectedSyncOutcome("VALIDATION_ERROR", "Data operasi tidak valid")
+		}
+		if !s.permissionService.HasPermission(userID, role, "delivery_tasks") {
+			return rejectedSyncOutcome("FORBIDDEN", "Anda tidak memiliki izin untuk mengakses fitur ini")
+		}
+		if err := s.epodService.ValidateGeotagging(payload.Latitude, payload.Longitude); err != nil {
+			return rejectedSyncOutcome("VALIDATION_ERROR", err.Error())
+		}
+		return s.applyEPOD(payload, op.ClientTimestamp)
+
+	default:
+		return rejectedSyncOutcome("UNKNOWN_OPERATION", fmt.Sprintf("Jenis operasi %q tidak dikenal", op.Type))
+	}
+}
+
+func (s *OfflineSyncService) applyDeliveryStatus(userID uint, payload DeliveryStatusSyncPayload, occurredAt time.Time) syncOutcome {
+	err := s.monitoringService.UpdateDeliveryStatusAt(payload.DeliveryRecordID, payload.Status, userID, payload.Notes, occurredAt)
+
+	var transitionErr *InvalidTransitionError
+	var sequenceErr *StageSequenceError
+	switch {
+	case err == nil:
+		return syncOutcome{
+			status: models.SyncStatusApplied,
+			data:   map[string]interface{}{"delivery_record_id": payload.DeliveryRecordID, "status": payload.Status},
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return rejectedSyncOutcome("RECORD_NOT_FOUND", "Delivery record tidak ditemukan")
+	case errors.As(err, &transitionErr), errors.As(err, &sequenceErr):
+		return syncOutcome{status: models.SyncStatusConflict, errorCode: "INVALID_TRANSITION", message: err.Error()}
+	default:
+		log.Printf("[SYNC] failed to update delivery record %d: %v", payload.DeliveryRecordID, err)
+		return syncOutcome{status: SyncResultFailed}
+	}
+}
+
+func (s *OfflineSyncService) applyPickupStage(userID uint, payload PickupStageSyncPayload, occurredAt time.Time) syncOutcome {
+	record, err := s.pickupTaskService.UpdateDeliveryRecordStageAt(payload.PickupTaskID, payload.DeliveryRecordID, payload.Stage,
+		payload.Status, userID, payload.OmprengReceived, payload.OmprengDifferenceReason, occurredAt)
+	if err == nil {
+		return syncOutcome{
+			status: models.SyncStatusApplied,
+			data:   map[string]interface{}{"delivery_record_id": record.ID, "stage": record.CurrentStage},
+		}
+	}
+
+	var stageErr *StageUpdateError
+	if !errors.As(err, &stageErr) {
+		log.Printf("[SYNC] failed to update stage of delivery record %d: %v", payload.DeliveryRecordID, err)
+		return syncOutcome{status: SyncResultFailed}
+	}
+
+	switch stageErr.Reason {
+	case StageUpdateConflict:
+		return syncOutcome{status: models.SyncStatusConflict, errorCode: "INVALID_STAGE_TRANSITION", message: stageErr.Message}
+	case StageUpdateNotFound:
+		return rejectedSyncOutcome("DELIVERY_RECORD_NOT_FOUND", stageErr.Message)
+	default:
+		return rejectedSyncOutcome("INVALID_STAGE_TRANSITION", stageErr.Message)
+	}
+}
+
+func (s *OfflineSyncService) applyEPOD(payload EPODSyncPayload, occurredAt time.Time) syncOutcome {
+	epod := &models.ElectronicPOD{
+		DeliveryTaskID: payload.DeliveryTaskID,
+		Latitude:       payload.Latitude,
+		Longitude:      payload.Longitude,
+		RecipientName:  payload.RecipientName,
+		OmprengDropOff: payload.OmprengDropOff,
+		OmprengPickUp:  payload.OmprengPickUp,
+		CompletedAt:    occurredAt,
+	}
+
+	err := s.epodService.CreateEPOD(epod)
+	switch {
+	case err == nil:
+		return syncOutcome{
+			status: models.SyncStatusApplied,
+			data:   map[string]interface{}{"epod_id": epod.ID, "delivery_task_id": epod.DeliveryTaskID},
+		}
+	case errors.Is(err, ErrEPODAlreadyExists), errors.Is(err, ErrDeliveryTaskCompleted):
+		return syncOutcome{status: models.SyncStatusConflict, errorCode: "EPOD_CONFLICT", message: err.Error()}
+	case errors.Is(err, ErrDeliveryTaskNotFound):
+		return rejectedSyncOutcome("NOT_FOUND", err.Error())
+	default:
+		log.Printf("[SYNC] failed to create ePOD for delivery task %d: %v", payload.DeliveryTaskID, err)
+		return syncOutcome{status: SyncResultFailed}
+	}
+}
+
+func rejectedSyncOutcome(code, message string) syncOutcome {
+	return syncOutcome{status: models.SyncStatusRejected, errorCode: code, message: message}
+}
+
+func failedSyncResult(result SyncOperationResult) SyncOperationResult {
+	result.Status = SyncResultFailed
+	result.ErrorCode = "SYNC_FAILED"
+	result.Message = "Operasi gagal diproses, akan dicoba lagi"
+	return result
+}
+
+// offlineEventTime returns the time to record for a status transition.
+// A zero occurredAt means the change happens now. Actions synced from offline
+// clients keep the time they were performed, but never later than now and
+// never before the record's previous transition, so the activity log stays
+// in order.
+func offlineEventTime(tx *gorm.DB, recordID uint, occurredAt, now time.Time) (time.Time, error) {
+	if occurredAt.IsZero() || occurredAt.After(now) {
+		return now, nil
+	}
+
+	var last models.StatusTransition
+	err := tx.Where("delivery_record_id = ?", recordID).Order("transitioned_at DESC").First(&last).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, err
+	}
+	if err == nil && occurredAt.Before(last.TransitionedAt) {
+		return last.TransitionedAt, nil
+	}
+
+	return occurredAt.In(now.Location()), nil
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupOfflineSyncTestDB(t *testing.T) *gorm.DB {
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.AutoMigrate(
+		&models.User{},
+		&models.School{},
+		&models.DeliveryRecord{},
+		&models.PickupTask{},
+		&models.StatusTransition{},
+		&models.OmprengCleaning{},
+		&models.SyncOperation{},
+		&models.Role{},
+		&models.Permission{},
+		&models.RolePermission{},
+		&models.UserPermissionOverride{},
+		&models.AuditTrail{},
+	))
+	return db
+}
+
+func newTestOfflineSyncService(t *testing.T, db *gorm.DB) *OfflineSyncService {
+	permissionService := NewPermissionService(db)
+	require.NoError(t, permissionService.SeedDefaults())
+	pickupTaskService := NewPickupTaskService(db, NewActivityTrackerService(db))
+	return NewOfflineSyncService(db, newTestMonitoringService(db), pickupTaskService, permissionService)
+}
+
+// createSyncTestDriver creates the driver who sends the offline queue
+func createSyncTestDriver(t *testing.T, db *gorm.DB) *models.User {
+	driver := &models.User{FullName: "Sync Driver", Role: "driver", PhoneNumber: "081200000001", IsActive: true}
+	require.NoError(t, db.Create(driver).Error)
+	return driver
+}
+
+// createSyncTestDeliveryRecord creates a delivery record for a new school
+func createSyncTestDeliveryRecord(t *testing.T, db *gorm.DB, schoolName string, stage int, status string) *models.DeliveryRecord {
+	school := &models.School{Name: schoolName, Address: "Jl. Sync", Category: "SD", StudentCount: 100, IsActive: true}
+	require.NoError(t, db.Create(school).Error)
+
+	record := &models.DeliveryRecord{
+		DeliveryDate:  time.Now(),
+		SchoolID:      school.ID,
+		CurrentStage:  stage,
+		CurrentStatus: status,
+		OmprengCount:  15,
+	}
+	require.NoError(t, db.Create(record).Error)
+	return record
+}
+
+// createSyncTestPickupTask assigns the delivery records to an active pickup task in route order
+func createSyncTestPickupTask(t *testing.T, db *gorm.DB, driverID uint, records ...*models.DeliveryRecord) *models.PickupTask {
+	task := &models.PickupTask{TaskDate: time.Now(), DriverID: driverID, Status: "active"}
+	require.NoError(t, db.Create(task).Error)
+	for i, record := range records {
+		require.NoError(t, db.Model(record).Updates(map[string]interface{}{
+			"pickup_task_id": task.ID,
+			"route_order":    i + 1,
+		}).Error)
+	}
+	return task
+}
+
+func syncTestOperation(t *testing.T, key, opType string, at time.Time, payload interface{}) SyncOperationInput {
+	raw, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return SyncOperationInput{IdempotencyKey: key, Type: opType, ClientTimestamp: at, Payload: raw}
+}
+
+func TestOfflineSyncService_RecordsClientTimeAndReplaysResent(t *testing.T) {
+	db := setupOfflineSyncTestDB(t)
+	service := newTestOfflineSyncService(t, db)
+
+	driver := createSyncTestDriver(t, db)
+	record := createSyncTestDeliveryRecord(t, db, "SD Sync 1", 6, "siap_dikirim")
+
+	departedAt := time.Now().Add(-40 * time.Minute).Truncate(time.Second)
+	arrivedAt := departedAt.Add(25 * time.Minute)
+	ops := []SyncOperationInput{
+		syncTestOperation(t, "op-1", SyncOpDeliveryStatus, departedAt,
+			DeliveryStatusSyncPayload{DeliveryRecordID: record.ID, Status: "diperjalanan"}),
+		syncTestOperation(t, "op-2", SyncOpDeliveryStatus, arrivedAt,
+			DeliveryStatusSyncPayload{DeliveryRecordID: record.ID, Status: "sudah_sampai_sekolah"}),
+	}
+
+	results := service.ApplyBatch(driver.ID, "driver", ops)
+	require.Len(t, results, 2)
+	assert.Equal(t, models.SyncStatusApplied, results[0].Status)
+	assert.Equal(t, models.SyncStatusApplied, results[1].Status)
+
+	var transitions []models.StatusTransition
+	require.NoError(t, db.Where("delivery_record_id = ?", record.ID).Order("id").Find(&transitions).Error)
+	require.Len(t, transitions, 2)
+	assert.True(t, transitions[0].TransitionedAt.Equal(departedAt), "transition keeps the time it happened offline")
+	assert.True(t, transitions[1].TransitionedAt.Equal(arrivedAt))
+
+	// The client did not get the response and sends the same queue again
+	results = service.ApplyBatch(driver.ID, "driver", ops)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.Equal(t, models.SyncStatusApplied, result.Status)
+		assert.True(t, result.Replayed)
+	}
+
+	var count int64
+	db.Model(&models.StatusTransition{}).Where("delivery_record_id = ?", record.ID).Count(&count)
+	assert.Equal(t, int64(2), count, "resent operations must not be applied twice")
+}
+
+func TestOfflineSyncService_ReportsConflictsPerOperation(t *testing.T) {
+	db := setupOfflineSyncTestDB(t)
+	service := newTestOfflineSyncService(t, db)
+
+	driver := createSyncTestDriver(t, db)
+	first := createSyncTestDeliveryRecord(t, db, "SD Sync 2", 10, "driver_menuju_lokasi_pengambilan")
+	second := createSyncTestDeliveryRecord(t, db, "SD Sync 3", 10, "driver_menuju_lokasi_pengambilan")
+	task := createSyncTestPickupTask(t, db, driver.ID, first, second)
+
+	at := time.Now().Add(-10 * time.Minute)
+	results := service.ApplyBatch(driver.ID, "driver", []SyncOperationInput{
+		// Skips stage 11
+		syncTestOperation(t, "skip", SyncOpPickupStage, at, PickupStageSyncPayload{
+			PickupTaskID: task.ID, DeliveryRecordID: first.ID, Stage: 12, Status: "driver_kembali_ke_sppg",
+		}),
+		syncTestOperation(t, "arrive", SyncOpPickupStage, at, PickupStageSyncPayload{
+			PickupTaskID: task.ID, DeliveryRecordID: second.ID, Stage: 11, Status: "driver_tiba_di_lokasi_pengambilan",
+		}),
+		syncTestOperation(t, "unknown", "teleport", at, map[string]interface{}{}),
+		syncTestOperation(t, "forbidden", SyncOpDeliveryStatus, at,
+			DeliveryStatusSyncPayload{DeliveryRecordID: second.ID, Status: "sedang_dimasak"}),
+	})
+
+	require.Len(t, results, 4)
+	assert.Equal(t, models.SyncStatusConflict, results[0].Status)
+	assert.Equal(t, "INVALID_STAGE_TRANSITION", results[0].ErrorCode)
+	assert.Equal(t, models.SyncStatusApplied, results[1].Status, "a conflict does not stop the batch")
+	assert.Equal(t, models.SyncStatusRejected, results[2].Status)
+	assert.Equal(t, "UNKNOWN_OPERATION", results[2].ErrorCode)
+	assert.Equal(t, models.SyncStatusRejected, results[3].Status)
+	assert.Equal(t, "FORBIDDEN", results[3].ErrorCode)
+
+	var skipped, arrived models.DeliveryRecord
+	require.NoError(t, db.First(&skipped, first.ID).Error)
+	assert.Equal(t, 10, skipped.CurrentStage)
+	require.NoError(t, db.First(&arrived, second.ID).Error)
+	assert.Equal(t, 11, arrived.CurrentStage)
+
+	// A key reused for another operation type is rejected, not replayed
+	results = service.ApplyBatch(driver.ID, "driver", []SyncOperationInput{
+		syncTestOperation(t, "arrive", SyncOpEPOD, at, EPODSyncPayload{DeliveryTaskID: 1, RecipientName: "Guru"}),
+	})
+	require.Len(t, results, 1)
+	assert.Equal(t, "IDEMPOTENCY_KEY_REUSED", results[0].ErrorCode)
+}
+
+func TestOfflineEventTime(t *testing.T) {
+	db := setupOfflineSyncTestDB(t)
+	now := time.Now().Truncate(time.Second)
+
+	got, err := offlineEventTime(db, 1, time.Time{}, now)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(now), "zero time means now")
+
+	got, err = offlineEventTime(db, 1, now.Add(time.Hour), now)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(now), "client clocks ahead of the server are clamped to now")
+
+	previous := now.Add(-10 * time.Minute)
+	require.NoError(t, db.Create(&models.StatusTransition{
+		DeliveryRecordID: 1, FromStatus: "siap_dikirim", ToStatus: "diperjalanan", Stage: 7,
+		TransitionedAt: previous, TransitionedBy: 1,
+	}).Error)
+
+	got, err = offlineEventTime(db, 1, now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(previous), "never earlier than the previous transition")
+
+	got, err = offlineEventTime(db, 1, now.Add(-5*time.Minute), now)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(now.Add(-5*time.Minute)))
+}
@@ -0,0 +1,414 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/events"
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Outbox event types
+const (
+	OutboxEventDeliverySync = "realtime.delivery_record"
+	OutboxEventCleaningSync = "realtime.ompreng_cleaning"
+
+	// OutboxEventDomainPrefix prefixes the durable subscriber name in the
+	// event type of domain events stored for that subscriber; the aggregate
+	// type holds the domain event name
+	OutboxEventDomainPrefix = "event."
+)
+
+// Outbox errors
+var (
+	ErrOutboxEventNotFound      = errors.New("event outbox tidak ditemukan")
+	ErrOutboxEventNotReplayable = errors.New("event yang sedang diproses atau sudah terkirim tidak dapat diputar ulang")
+)
+
+const (
+	// outboxMaxAttempts is how often an event is tried before it is dead-lettered
+	outboxMaxAttempts = 8
+	// outboxBaseBackoff doubles with every failed attempt up to outboxMaxBackoff
+	outboxBaseBackoff = time.Second
+	outboxMaxBackoff  = 10 * time.Minute
+	// outboxLockDuration is how long a claimed event stays with its dispatcher;
+	// events of a dispatcher that died are picked up again afterwards
+	outboxLockDuration = time.Minute
+	outboxBatchSize    = 50
+	outboxWorkers      = 4
+	// outboxRetention is how long delivered events are kept for inspection
+	outboxRetention = 7 * 24 * time.Hour
+)
+
+// OutboxHandler delivers one outbox event. Returning an error schedules a retry.
+type OutboxHandler func(ctx context.Context, event *models.OutboxEvent) error
+
+// OutboxStats counts the outbox events per status
+type OutboxStats struct {
+	Pending    int64 `json:"pending"`
+	Processing int64 `json:"processing"`
+	Delivered  int64 `json:"delivered"`
+	Dead       int64 `json:"dead"`
+}
+
+// EnqueueOutboxEvent records an event in tx, which must be the transaction of
+// the change that causes it, so the event exists exactly when the change was
+// committed
+func EnqueueOutboxEvent(tx *gorm.DB, eventType, aggregateType string, aggregateID uint, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan payload event %s: %w", eventType, err)
+	}
+
+	event := models.OutboxEvent{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		Status:        models.OutboxStatusPending,
+		MaxAttempts:   outboxMaxAttempts,
+		NextAttemptAt: time.Now(),
+	}
+	return tx.Create(&event).Error
+}
+
+// OutboxBackoff returns the delay before the next attempt after the given
+// number of failed attempts: 1s, 2s, 4s, ... capped at 10 minutes
+func OutboxBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return delay
+}
+
+// OutboxService dispatches outbox events to their handlers and lets
+// administrators inspect and replay them
+type OutboxService struct {
+	db       *gorm.DB
+	mu       sync.RWMutex
+	handlers map[string]OutboxHandler
+	bus      *events.Bus
+}
+
+// NewOutboxService creates a new outbox service
+func NewOutboxService(db *gorm.DB) *OutboxService {
+	return &OutboxService{
+		db:       db,
+		handlers: make(map[string]OutboxHandler),
+	}
+}
+
+// RegisterHandler sets the handler that delivers events of a type
+func (s *OutboxService) RegisterHandler(eventType string, handler OutboxHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[eventType] = handler
+}
+
+// AttachEventBus makes the outbox the store of the bus: events for durable
+// subscribers are saved here and delivered to the subscriber with retries
+func (s *OutboxService) AttachEventBus(bus *events.Bus) {
+	s.mu.Lock()
+	s.bus = bus
+	s.mu.Unlock()
+	bus.SetStore(s)
+}
+
+// Save implements events.Store
+func (s *OutboxService) Save(tx *gorm.DB, subscriber string, event events.Event) error {
+	if tx == nil {
+		tx = s.db
+	}
+	return EnqueueOutboxEvent(tx, OutboxEventDomainPrefix+subscriber, event.EventName(), 0, event)
+}
+
+// deliverDomainEvent hands a stored domain event to its durable subscriber
+func (s *OutboxService) deliverDomainEvent(ctx context.Context, bus *events.Bus, event *models.OutboxEvent) error {
+	domainEvent, err := events.Decode(event.AggregateType, []byte(event.Payload))
+	if err != nil {
+		return err
+	}
+	return bus.Deliver(ctx, strings.TrimPrefix(event.EventType, OutboxEventDomainPrefix), domainEvent)
+}
+
+// StartDispatcher delivers due events every interval until ctx is done and
+// purges delivered events once they are older than a week
+func (s *OutboxService) StartDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastPurge time.Time
+	for {
+		if _, err := s.DispatchDue(ctx); err != nil {
+			log.Printf("[OUTBOX] dispatch failed: %v", err)
+		}
+		if time.Since(lastPurge) > time.Hour {
+			if _, err := s.PurgeDelivered(time.Now().Add(-outboxRetention)); err != nil {
+				log.Printf("[OUTBOX] purge failed: %v", err)
+			}
+			lastPurge = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue claims the events that are due and delivers them, a few at a
+// time so one slow event does not hold up the others. It returns the number
+// of events that were delivered.
+func (s *OutboxService) DispatchDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	var candidates []models.OutboxEvent
+	if err := s.dueQuery(s.db, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(outboxBatchSize).
+		Find(&candidates).Error; err != nil {
+		return 0, err
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+	)
+	slots := make(chan struct{}, outboxWorkers)
+	for i := range candidates {
+		event := candidates[i]
+		// Claim only once a worker is free, so the lock does not run out while waiting
+		slots <- struct{}{}
+		if !s.claim(&event, time.Now()) {
+			// Taken by another dispatcher in the meantime
+			<-slots
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if s.deliver(ctx, &event) {
+				mu.Lock()
+				delivered++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return delivered, nil
+}
+
+// dueQuery selects pending events whose time has come and events whose
+// dispatcher stopped before finishing them
+func (s *OutboxService) dueQuery(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Model(&models.OutboxEvent{}).
+		Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+			models.OutboxStatusPending, now, models.OutboxStatusProcessing, now)
+}
+
+// claim marks an event as processing unless someone else got to it first
+func (s *OutboxService) claim(event *models.OutboxEvent, now time.Time) bool {
+	lockedUntil := now.Add(outboxLockDuration)
+	result := s.dueQuery(s.db, now).
+		Where("id = ?", event.ID).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxStatusProcessing,
+			"locked_until": lockedUntil,
+		})
+	if result.Error != nil || result.RowsAffected != 1 {
+		return false
+	}
+	event.Status = models.OutboxStatusProcessing
+	event.LockedUntil = &lockedUntil
+	return true
+}
+
+// deliver runs the handler of a claimed event and records the outcome
+func (s *OutboxService) deliver(ctx context.Context, event *models.OutboxEvent) bool {
+	s.mu.RLock()
+	handler, ok := s.handlers[event.EventType]
+	bus := s.bus
+	s.mu.RUnlock()
+
+	if !ok && bus != nil && strings.HasPrefix(event.EventType, OutboxEventDomainPrefix) {
+		handler = func(ctx context.Context, event *models.OutboxEvent) error {
+			return s.deliverDomainEvent(ctx, bus, event)
+		}
+		ok = true
+	}
+
+	var err error
+	if !ok {
+		err = fmt.Errorf("handler untuk event %s belum terdaftar", event.EventType)
+	} else {
+		handlerCtx, cancel := context.WithTimeout(ctx, outboxLockDuration/2)
+		err = s.runHandler(handlerCtx, handler, event)
+		cancel()
+	}
+
+	now := time.Now()
+	attempts := event.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":     attempts,
+		"locked_until": nil,
+	}
+
+	if err == nil {
+		updates["status"] = models.OutboxStatusDelivered
+		updates["delivered_at"] = now
+		updates["last_error"] = ""
+	} else if attempts >= event.MaxAttempts {
+		updates["status"] = models.OutboxStatusDead
+		updates["last_error"] = err.Error()
+		// Logged for admin alerting; the event stays available for replay
+		log.Printf("ERROR: outbox event %d (%s %s #%d) dead-lettered after %d attempts: %v",
+			event.ID, event.EventType, event.AggregateType, event.AggregateID, attempts, err)
+	} else {
+		delay := OutboxBackoff(attempts)
+		updates["status"] = models.OutboxStatusPending
+		updates["next_attempt_at"] = now.Add(delay)
+		updates["last_error"] = err.Error()
+		log.Printf("[OUTBOX] event %d (%s) failed (attempt %d/%d), retrying in %v: %v",
+			event.ID, event.EventType, attempts, event.MaxAttempts, delay, err)
+	}
+
+	if dbErr := s.db.Model(&models.OutboxEvent{}).Where("id = ?", event.ID).Updates(updates).Error; dbErr != nil {
+		log.Printf("[OUTBOX] failed to record the outcome of event %d: %v", event.ID, dbErr)
+	}
+	return err == nil
+}
+
+// runHandler turns a panicking handler into a failed attempt
+func (s *OutboxService) runHandler(ctx context.Context, handler OutboxHandler, event *models.OutboxEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// ListEvents lists outbox events, newest first. Status and event type are optional filters.
+func (s *OutboxService) ListEvents(status, eventType string, limit, offset int) ([]models.OutboxEvent, int64, error) {
+	query := s.db.Model(&models.OutboxEvent{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if eventType != "" {
+		query = query.Where("event_type = ?", eventType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []models.OutboxEvent
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// GetStats counts the outbox events per status
+func (s *OutboxService) GetStats() (*OutboxStats, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := s.db.Model(&models.OutboxEvent{}).
+		Select("status, COUNT(*) as count").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &OutboxStats{}
+	for _, row := range rows {
+		switch row.Status {
+		case models.OutboxStatusPending:
+			stats.Pending = row.Count
+		case models.OutboxStatusProcessing:
+			stats.Processing = row.Count
+		case models.OutboxStatusDelivered:
+			stats.Delivered = row.Count
+		case models.OutboxStatusDead:
+			stats.Dead = row.Count
+		}
+	}
+	return stats, nil
+}
+
+// ReplayEvent puts a dead or pending event back in the queue with a fresh
+// set of attempts, to be delivered on the next dispatch
+func (s *OutboxService) ReplayEvent(id uint) (*models.OutboxEvent, error) {
+	var event models.OutboxEvent
+	if err := s.db.First(&event, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutboxEventNotFound
+		}
+		return nil, err
+	}
+
+	if event.Status != models.OutboxStatusDead && event.Status != models.OutboxStatusPending {
+		return nil, ErrOutboxEventNotReplayable
+	}
+
+	result := s.db.Model(&models.OutboxEvent{}).
+		Where("id = ? AND status IN ?", id, []string{models.OutboxStatusDead, models.OutboxStatusPending}).
+		Updates(replayUpdates())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrOutboxEventNotReplayable
+	}
+
+	if err := s.db.First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// ReplayDeadEvents puts every dead-lettered event back in the queue and
+// returns how many there were
+func (s *OutboxService) ReplayDeadEvents() (int64, error) {
+	result := s.db.Model(&models.OutboxEvent{}).
+		Where("status = ?", models.OutboxStatusDead).
+		Updates(replayUpdates())
+	return result.RowsAffected, result.Error
+}
+
+func replayUpdates() map[string]interface{} {
+	return map[string]interface{}{
+		"status":          models.OutboxStatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+		"locked_until":    nil,
+	}
+}
+
+// PurgeDelivered deletes delivered events older than before
+func (s *OutboxService) PurgeDelivered(before time.Time) (int64, error) {
+	result := s.db.Where("status = ? AND delivered_at < ?", models.OutboxStatusDelivered, before).
+		Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,436 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/events"
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/realtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupOutboxTestDB(t *testing.T) *gorm.DB {
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
+	require.NoError(t, err)
+
+	// The dispatcher works from several goroutines; keep them on the one in-memory database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	require.NoError(t, db.AutoMigrate(
+		&models.OutboxEvent{},
+		&models.DeliveryRecord{},
+		&models.StatusTransition{},
+		&models.OmprengCleaning{},
+		&models.School{},
+		&models.User{},
+		&models.Recipe{},
+		&models.MenuPlan{},
+		&models.MenuItem{},
+	))
+	return db
+}
+
+func enqueueTestOutboxEvent(t *testing.T, db *gorm.DB, eventType string, aggregateID uint) models.OutboxEvent {
+	require.NoError(t, EnqueueOutboxEvent(db, eventType, "test", aggregateID, map[string]interface{}{"n": aggregateID}))
+	var event models.OutboxEvent
+	require.NoError(t, db.Order("id DESC").First(&event).Error)
+	return event
+}
+
+func reloadOutboxEvent(t *testing.T, db *gorm.DB, id uint) models.OutboxEvent {
+	var event models.OutboxEvent
+	require.NoError(t, db.First(&event, id).Error)
+	return event
+}
+
+// makeOutboxEventDue moves the next attempt of an event into the past
+func makeOutboxEventDue(t *testing.T, db *gorm.DB, id uint) {
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Where("id = ?", id).
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, OutboxBackoff(0))
+	assert.Equal(t, time.Second, OutboxBackoff(1))
+	assert.Equal(t, 2*time.Second, OutboxBackoff(2))
+	assert.Equal(t, 4*time.Second, OutboxBackoff(3))
+	assert.Equal(t, 64*time.Second, OutboxBackoff(7))
+	assert.Equal(t, 10*time.Minute, OutboxBackoff(20))
+	assert.Equal(t, 10*time.Minute, OutboxBackoff(1000))
+}
+
+func TestOutboxService_RetriesWithBackoffUntilDelivered(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	service := NewOutboxService(db)
+
+	calls := 0
+	service.RegisterHandler("test.event", func(ctx context.Context, event *models.OutboxEvent) error {
+		calls++
+		if calls == 1 {
+			return errors.New("publisher tidak tersedia")
+		}
+		return nil
+	})
+	event := enqueueTestOutboxEvent(t, db, "test.event", 1)
+
+	delivered, err := service.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	failed := reloadOutboxEvent(t, db, event.ID)
+	assert.Equal(t, models.OutboxStatusPending, failed.Status)
+	assert.Equal(t, 1, failed.Attempts)
+	assert.Equal(t, "publisher tidak tersedia", failed.LastError)
+	assert.Nil(t, failed.LockedUntil)
+	assert.WithinDuration(t, time.Now().Add(time.Second), failed.NextAttemptAt, 500*time.Millisecond)
+
+	// Not due yet
+	delivered, err = service.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 1, calls)
+
+	makeOutboxEventDue(t, db, event.ID)
+	delivered, err = service.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	done := reloadOutboxEvent(t, db, event.ID)
+	assert.Equal(t, models.OutboxStatusDelivered, done.Status)
+	assert.Equal(t, 2, done.Attempts)
+	assert.NotNil(t, done.DeliveredAt)
+	assert.Empty(t, done.LastError)
+}
+
+func TestOutboxService_DeadLetterAndReplay(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	service := NewOutboxService(db)
+
+	healthy := false
+	service.RegisterHandler("test.event", func(ctx context.Context, event *models.OutboxEvent) error {
+		if !healthy {
+			return errors.New("gagal")
+		}
+		return nil
+	})
+	event := enqueueTestOutboxEvent(t, db, "test.event", 1)
+	require.NoError(t, db.Model(&event).Update("max_attempts", 2).Error)
+
+	_, err := service.DispatchDue(context.Background())
+	require.NoError(t, err)
+	makeOutboxEventDue(t, db, event.ID)
+	_, err = service.DispatchDue(context.Background())
+	require.NoError(t, err)
+
+	dead := reloadOutboxEvent(t, db, event.ID)
+	assert.Equal(t, models.OutboxStatusDead, dead.Status)
+	assert.Equal(t, 2, dead.Attempts)
+
+	// Dead events are never picked up again on their own
+	makeOutboxEventDue(t, db, event.ID)
+	delivered, err := service.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	stats, err := service.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Dead)
+
+	events, total, err := service.ListEvents(models.OutboxStatusDead, "", 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, event.ID, events[0].ID)
+
+	replayed, err := service.ReplayEvent(event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OutboxStatusPending, replayed.Status)
+	assert.Equal(t, 0, replayed.Attempts)
+	assert.Equal(t, "gagal", replayed.LastError, "the last error stays visible until the next attempt")
+
+	healthy = true
+	delivered, err = service.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	// Delivered events cannot be replayed
+	_, err = service.ReplayEvent(event.ID)
+	assert.ErrorIs(t, err, ErrOutboxEventNotReplayable)
+	_, err = service.ReplayEvent(9999)
+	assert.ErrorIs(t, err, ErrOutboxEventNotFound)
+}
+
+func TestOutboxService_ReplayDeadEvents(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	service := NewOutboxService(db)
+
+	first := enqueueTestOutboxEvent(t, db, "test.event", 1)
+	second := enqueueTestOutboxEvent(t, db, "test.event", 2)
+	enqueueTestOutboxEvent(t, db, "test.event", 3)
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Where("id IN ?", []uint{first.ID, second.ID}).
+		Updates(map[string]interface{}{"status": models.OutboxStatusDead, "attempts": 8}).Error)
+
+	count, err := service.ReplayDeadEvents()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	stats, err := service.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Pending)
+	assert.Equal(t, int64(0), stats.Dead)
+}
+
+func TestOutboxService_UnregisteredAndPanickingHandlersFail(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	service := NewOutboxService(db)
+	service.RegisterHandler("test.panic", func(ctx context.Context, event *models.OutboxEvent) error {
+		panic("boom")
+	})
+
+	unknown := enqueueTestOutboxEvent(t, db, "test.unknown", 1)
+	panicking := enqueueTestOutboxEvent(t, db, "test.panic", 2)
+
+	delivered, err := service.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	assert.Contains(t, reloadOutboxEvent(t, db, unknown.ID).LastError, "belum terdaftar")
+	failed := reloadOutboxEvent(t, db, panicking.ID)
+	assert.Equal(t, models.OutboxStatusPending, failed.Status)
+	assert.Contains(t, failed.LastError, "boom")
+}
+
+func TestOutboxService_ReclaimsEventsOfStoppedDispatcher(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	service := NewOutboxService(db)
+	service.RegisterHandler("test.event", func(ctx context.Context, event *models.OutboxEvent) error { return nil })
+
+	stale := enqueueTestOutboxEvent(t, db, "test.event", 1)
+	busy := enqueueTestOutboxEvent(t, db, "test.event", 2)
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Where("id = ?", stale.ID).
+		Updates(map[string]interface{}{"status": models.OutboxStatusProcessing, "locked_until": time.Now().Add(-time.Second)}).Error)
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Where("id = ?", busy.ID).
+		Updates(map[string]interface{}{"status": models.OutboxStatusProcessing, "locked_until": time.Now().Add(time.Minute)}).Error)
+
+	delivered, err := service.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, models.OutboxStatusDelivered, reloadOutboxEvent(t, db, stale.ID).Status)
+	assert.Equal(t, models.OutboxStatusProcessing, reloadOutboxEvent(t, db, busy.ID).Status)
+}
+
+func TestOutboxService_SlowEventDoesNotBlockOthers(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	service := NewOutboxService(db)
+
+	otherDelivered := make(chan struct{})
+	service.RegisterHandler("test.slow", func(ctx context.Context, event *models.OutboxEvent) error {
+		select {
+		case <-otherDelivered:
+			return nil
+		case <-time.After(2 * time.Second):
+			return errors.New("event lain tertahan")
+		}
+	})
+	service.RegisterHandler("test.fast", func(ctx context.Context, event *models.OutboxEvent) error {
+		close(otherDelivered)
+		return nil
+	})
+
+	slow := enqueueTestOutboxEvent(t, db, "test.slow", 1)
+	enqueueTestOutboxEvent(t, db, "test.fast", 2)
+
+	delivered, err := service.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, models.OutboxStatusDelivered, reloadOutboxEvent(t, db, slow.ID).Status)
+}
+
+func TestOutboxService_PurgeDelivered(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	service := NewOutboxService(db)
+
+	old := enqueueTestOutboxEvent(t, db, "test.event", 1)
+	recent := enqueueTestOutboxEvent(t, db, "test.event", 2)
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Where("id = ?", old.ID).
+		Updates(map[string]interface{}{"status": models.OutboxStatusDelivered, "delivered_at": time.Now().AddDate(0, 0, -8)}).Error)
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Where("id = ?", recent.ID).
+		Updates(map[string]interface{}{"status": models.OutboxStatusDelivered, "delivered_at": time.Now()}).Error)
+
+	count, err := service.PurgeDelivered(time.Now().AddDate(0, 0, -7))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	var remaining int64
+	db.Model(&models.OutboxEvent{}).Count(&remaining)
+	assert.Equal(t, int64(1), remaining)
+}
+
+func TestEnqueueOutboxEvent_RolledBackWithTransaction(t *testing.T) {
+	db := setupOutboxTestDB(t)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := EnqueueOutboxEvent(tx, OutboxEventDeliverySync, "delivery_record", 1, nil); err != nil {
+			return err
+		}
+		return errors.New("status gagal disimpan")
+	})
+	require.Error(t, err)
+
+	var count int64
+	db.Model(&models.OutboxEvent{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func createOutboxTestDeliveryRecord(t *testing.T, db *gorm.DB, status string) models.DeliveryRecord {
+	school := models.School{Name: "SD Negeri 5"}
+	require.NoError(t, db.Create(&school).Error)
+	driver := models.User{NIK: "9001", FullName: "Driver", Email: "driver@sppg.com", Role: "driver"}
+	require.NoError(t, db.Create(&driver).Error)
+
+	record := models.DeliveryRecord{
+		DeliveryDate:  time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local),
+		SchoolID:      school.ID,
+		DriverID:      &driver.ID,
+		MenuItemID:    1,
+		Portions:      150,
+		CurrentStatus: status,
+		OmprengCount:  15,
+	}
+	require.NoError(t, db.Create(&record).Error)
+	return record
+}
+
+func TestMonitoringService_StatusUpdateSyncsThroughOutbox(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	hub := realtime.NewHub()
+	monitoring, err := NewMonitoringService(db, hub)
+	require.NoError(t, err)
+	outbox := NewOutboxService(db)
+	monitoring.RegisterOutboxHandlers(outbox)
+
+	record := createOutboxTestDeliveryRecord(t, db, "sedang_dimasak")
+	require.NoError(t, monitoring.UpdateDeliveryStatus(record.ID, "selesai_dimasak", 1, ""))
+
+	var event models.OutboxEvent
+	require.NoError(t, db.Where("event_type = ?", OutboxEventDeliverySync).First(&event).Error)
+	assert.Equal(t, record.ID, event.AggregateID)
+	assert.Equal(t, models.OutboxStatusPending, event.Status)
+	assert.JSONEq(t, `{"status":"selesai_dimasak","stage":3}`, event.Payload)
+
+	delivered, err := outbox.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	var published map[string]interface{}
+	require.NoError(t, hub.Get(context.Background(), "monitoring/deliveries/2026-03-02/record_1", &published))
+	assert.Equal(t, "selesai_dimasak", published["current_status"])
+	assert.Equal(t, "SD Negeri 5", published["school_name"])
+
+	// A rejected transition records nothing
+	require.Error(t, monitoring.UpdateDeliveryStatus(record.ID, "sudah_diterima_pihak_sekolah", 1, ""))
+	var count int64
+	db.Model(&models.OutboxEvent{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestCleaningService_CleaningSyncsThroughOutbox(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	hub := realtime.NewHub()
+	cleaningService, err := NewCleaningService(db, hub)
+	require.NoError(t, err)
+	outbox := NewOutboxService(db)
+	cleaningService.RegisterOutboxHandlers(outbox)
+
+	record := createOutboxTestDeliveryRecord(t, db, "driver_tiba_di_sppg")
+	cleaning := models.OmprengCleaning{DeliveryRecordID: record.ID, OmprengCount: 15, CleaningStatus: "pending"}
+	require.NoError(t, db.Create(&cleaning).Error)
+
+	require.NoError(t, cleaningService.StartCleaning(cleaning.ID, 1))
+	require.NoError(t, cleaningService.CompleteCleaning(cleaning.ID, 1))
+
+	var events []models.OutboxEvent
+	require.NoError(t, db.Where("event_type = ?", OutboxEventCleaningSync).Order("id").Find(&events).Error)
+	require.Len(t, events, 2)
+	assert.JSONEq(t, `{"status":"in_progress"}`, events[0].Payload)
+	assert.JSONEq(t, `{"status":"completed"}`, events[1].Payload)
+
+	delivered, err := outbox.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+
+	var published map[string]interface{}
+	require.NoError(t, hub.Get(context.Background(), "cleaning/pending/cleaning_1", &published))
+	assert.Equal(t, "completed", published["status"])
+}
+
+func TestOutboxService_DeliversDurableDomainEvents(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Notification{}))
+
+	bus := events.NewBus()
+	outbox := NewOutboxService(db)
+	outbox.AttachEventBus(bus)
+	notifications, err := NewNotificationService(db, realtime.NewHub())
+	require.NoError(t, err)
+	notifications.SubscribeEvents(bus)
+
+	creator := &models.User{FullName: "Staf Gudang", Role: "pengadaan", IsActive: true}
+	require.NoError(t, db.Create(creator).Error)
+
+	bus.Publish(events.StokOpnameApproved{FormID: 4, FormNumber: "SO-004", CreatedBy: creator.ID, ApprovedBy: 1})
+
+	// Publishing only records the event for the subscriber
+	var stored models.OutboxEvent
+	require.NoError(t, db.Where("event_type = ?", OutboxEventDomainPrefix+notificationSubscriber).First(&stored).Error)
+	assert.Equal(t, events.StokOpnameApprovedEvent, stored.AggregateType)
+	var count int64
+	db.Model(&models.Notification{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	delivered, err := outbox.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	var notification models.Notification
+	require.NoError(t, db.Where("user_id = ?", creator.ID).First(&notification).Error)
+	assert.Equal(t, NotificationTypeStokOpname, notification.Type)
+	assert.Contains(t, notification.Message, "SO-004")
+	assert.Equal(t, models.OutboxStatusDelivered, reloadOutboxEvent(t, db, stored.ID).Status)
+}
+
+func TestOutboxService_DurableEventsFollowTheTransaction(t *testing.T) {
+	db := setupOutboxTestDB(t)
+
+	bus := events.NewBus()
+	outbox := NewOutboxService(db)
+	outbox.AttachEventBus(bus)
+	bus.SubscribeDurable(events.PurchaseOrderApprovedEvent, "webhooks", func(context.Context, events.Event) error { return nil })
+
+	// A rolled back change leaves no event behind
+	err := db.Transaction(func(tx *gorm.DB) error {
+		require.NoError(t, bus.PublishTx(tx, events.PurchaseOrderApproved{PurchaseOrderID: 1}))
+		return errors.New("gagal")
+	})
+	require.Error(t, err)
+	var count int64
+	db.Model(&models.OutboxEvent{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return bus.PublishTx(tx, events.PurchaseOrderApproved{PurchaseOrderID: 2})
+	}))
+	bus.Notify(events.PurchaseOrderApproved{PurchaseOrderID: 2})
+
+	var stored []models.OutboxEvent
+	require.NoError(t, db.Find(&stored).Error)
+	require.Len(t, stored, 1, "Notify does not save the event again")
+	assert.Equal(t, OutboxEventDomainPrefix+"webhooks", stored[0].EventType)
+	assert.Contains(t, stored[0].Payload, `"purchase_order_id":2`)
+}
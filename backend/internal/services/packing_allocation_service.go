@@ -6,33 +6,23 @@ import (
 	"sort"
 	"time"
 
-	firebase "firebase.google.com/go/v4"
-	"firebase.google.com/go/v4/db"
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/realtime"
 	"gorm.io/gorm"
 )
 
 // PackingAllocationService handles packing allocation operations
 type PackingAllocationService struct {
-	db                *gorm.DB
-	firebaseApp       *firebase.App
-	dbClient          *db.Client
-	monitoringService *MonitoringService
+	db        *gorm.DB
+	publisher realtime.RealtimePublisher
 }
 
 // NewPackingAllocationService creates a new packing allocation service instance
-func NewPackingAllocationService(database *gorm.DB, firebaseApp *firebase.App, monitoringService *MonitoringService) (*PackingAllocationService, error) {
-	ctx := context.Background()
-	dbClient, err := firebaseApp.Database(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get Firebase database client: %w", err)
-	}
-
+func NewPackingAllocationService(database *gorm.DB, publisher realtime.RealtimePublisher) (*PackingAllocationService, error) {
 	return &PackingAllocationService{
-		db:                database,
-		firebaseApp:       firebaseApp,
-		dbClient:          dbClient,
-		monitoringService: monitoringService,
+		db:        database,
+		publisher: publisher,
 	}, nil
 }
 
@@ -67,12 +57,13 @@ func (s *PackingAllocationService) CalculatePackingAllocations(ctx context.Conte
 	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
 
 	// Get menu item school allocations for the date
+	dayStart, dayEnd := localDayRange(startOfDay)
 	var menuAllocations []models.MenuItemSchoolAllocation
 	err := s.db.WithContext(ctx).
 		Preload("School").
 		Preload("MenuItem").
 		Preload("MenuItem.Recipe").
-		Where("date = ?", startOfDay).
+		Where("date >= ? AND date < ?", dayStart, dayEnd).
 		Find(&menuAllocations).Error
 	
 	if err != nil {
@@ -168,10 +159,10 @@ func (s *PackingAllocationService) GetPackingAllocations(ctx context.Context, da
 	dateStr := startOfDay.Format("2006-01-02")
 	firebasePath := fmt.Sprintf("/kds/cooking/%s", dateStr)
 	var cookingData map[string]interface{}
-	err := s.dbClient.NewRef(firebasePath).Get(ctx, &cookingData)
+	err := s.publisher.Get(ctx, firebasePath, &cookingData)
 	if err != nil {
 		// If Firebase read fails, return empty list (no recipes are ready yet)
-		fmt.Printf("[Packing] Warning: failed to read cooking status from Firebase: %v\n", err)
+		fmt.Printf("[Packing] Warning: failed to read cooking status from realtime backend: %v\n", err)
 		return []SchoolAllocation{}, nil
 	}
 
@@ -202,6 +193,7 @@ func (s *PackingAllocationService) GetPackingAllocations(ctx context.Context, da
 	}
 
 	// Get menu item school allocations for the date, filtered by ready recipes
+	dayStart, dayEnd := localDayRange(startOfDay)
 	var menuAllocations []models.MenuItemSchoolAllocation
 	err = s.db.WithContext(ctx).
 		Preload("School").
@@ -210,7 +202,7 @@ func (s *PackingAllocationService) GetPackingAllocations(ctx context.Context, da
 		Preload("MenuItem.Recipe.RecipeItems").
 		Preload("MenuItem.Recipe.RecipeItems.SemiFinishedGoods").
 		Joins("JOIN menu_items ON menu_item_school_allocations.menu_item_id = menu_items.id").
-		Where("DATE(menu_items.date) = DATE(?)", startOfDay).
+		Where("menu_items.date >= ? AND menu_items.date < ?", dayStart, dayEnd).
 		Find(&menuAllocations).Error
 	
 	if err != nil {
@@ -355,7 +347,7 @@ func (s *PackingAllocationService) GetPackingAllocations(ctx context.Context, da
 	// Get packing statuses from Firebase
 	packingPath := fmt.Sprintf("/kds/packing/%s", dateStr)
 	var packingData map[string]interface{}
-	err = s.dbClient.NewRef(packingPath).Get(ctx, &packingData)
+	err = s.publisher.Get(ctx, packingPath, &packingData)
 	if err != nil {
 		// If Firebase read fails, just log and continue with default status
 		fmt.Printf("[Packing] Warning: failed to read packing status from Firebase: %v\n", err)
@@ -422,52 +414,20 @@ func (s *PackingAllocationService) UpdatePackingStatus(ctx context.Context, scho
 		"updated_at":  time.Now().Unix(),
 	}
 
-	err = s.dbClient.NewRef(firebasePath).Set(ctx, updateData)
+	err = s.publisher.Set(ctx, firebasePath, updateData)
 	if err != nil {
-		return fmt.Errorf("failed to update Firebase: %w", err)
+		return fmt.Errorf("failed to publish packing status: %w", err)
 	}
 
-	// Trigger monitoring system update for packing stages
+	// Monitoring moves the school's delivery records to the packing stages
 	// Requirements: 6.1, 6.2, 6.4
-	if s.monitoringService != nil {
-		// Get today's date for querying delivery records
-		loc, _ := time.LoadLocation("Asia/Jakarta")
-		todayDate := time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 0, 0, 0, 0, loc)
-		
-		// Find delivery records for this school and date
-		filters := map[string]interface{}{
-			"school_id": schoolID,
-		}
-		deliveryRecords, err := s.monitoringService.GetDeliveryRecords(todayDate, filters)
-		if err != nil {
-			// Log error but don't fail the packing status update
-			fmt.Printf("Warning: failed to get delivery records for monitoring update: %v\n", err)
-		} else if len(deliveryRecords) > 0 {
-			// Update monitoring status based on packing status
-			var monitoringStatus string
-			var notes string
-			
-			if status == "packing" {
-				// Stage 3: siap_dipacking (ready for packing)
-				monitoringStatus = "siap_dipacking"
-				notes = "Packing started for school"
-			} else if status == "ready" {
-				// Stage 4: selesai_dipacking (packing completed)
-				monitoringStatus = "selesai_dipacking"
-				notes = "Packing completed, ready for driver pickup"
-			}
-			
-			// Update all delivery records for this school
-			if monitoringStatus != "" {
-				for _, record := range deliveryRecords {
-					err := s.monitoringService.UpdateDeliveryStatus(record.ID, monitoringStatus, userID, notes)
-					if err != nil {
-						// Log error but don't fail the packing status update
-						fmt.Printf("Warning: failed to update monitoring status for delivery record %d: %v\n", record.ID, err)
-					}
-				}
-			}
-		}
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	todayDate := time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 0, 0, 0, 0, loc)
+	switch status {
+	case "packing":
+		events.Publish(events.PackingStarted{SchoolID: schoolID, Date: todayDate, UserID: userID})
+	case "ready":
+		events.Publish(events.PackingCompleted{SchoolID: schoolID, Date: todayDate, UserID: userID})
 	}
 
 	// If all schools are ready, send notification to logistics team
@@ -488,9 +448,9 @@ func (s *PackingAllocationService) checkAllSchoolsReady(ctx context.Context) err
 	firebasePath := fmt.Sprintf("/kds/packing/%s", today)
 	
 	var packingData map[string]interface{}
-	err := s.dbClient.NewRef(firebasePath).Get(ctx, &packingData)
+	err := s.publisher.Get(ctx, firebasePath, &packingData)
 	if err != nil {
-		return fmt.Errorf("failed to get packing data from Firebase: %w", err)
+		return fmt.Errorf("failed to get packing data: %w", err)
 	}
 
 	// Check if all schools have status "ready"
@@ -512,7 +472,7 @@ func (s *PackingAllocationService) checkAllSchoolsReady(ctx context.Context) err
 			"date":       today,
 			"timestamp":  time.Now().Unix(),
 		}
-		_, err = s.dbClient.NewRef(notificationPath).Push(ctx, notificationData)
+		_, err = s.publisher.Push(ctx, notificationPath, notificationData)
 		if err != nil {
 			return fmt.Errorf("failed to send notification: %w", err)
 		}
@@ -547,9 +507,9 @@ func (s *PackingAllocationService) SyncPackingAllocationsToFirebase(ctx context.
 		}
 	}
 
-	err = s.dbClient.NewRef(firebasePath).Set(ctx, firebaseData)
+	err = s.publisher.Set(ctx, firebasePath, firebaseData)
 	if err != nil {
-		return fmt.Errorf("failed to sync to Firebase: %w", err)
+		return fmt.Errorf("failed to sync to realtime backend: %w", err)
 	}
 
 	return nil
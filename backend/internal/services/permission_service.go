@@ -0,0 +1,739 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRoleNotFound       = errors.New("role tidak ditemukan")
+	ErrRoleAlreadyExists  = errors.New("role dengan nama tersebut sudah ada")
+	ErrSystemRole         = errors.New("role sistem tidak dapat dihapus")
+	ErrRoleInUse          = errors.New("role masih digunakan oleh pengguna")
+	ErrInvalidRole        = errors.New("role tidak valid")
+	ErrInvalidRoleName    = errors.New("nama role hanya boleh berisi huruf kecil, angka, dan garis bawah")
+	ErrPermissionNotFound = errors.New("permission tidak ditemukan")
+)
+
+// Permission categories
+const (
+	PermissionCategoryFeature = "feature"
+	PermissionCategoryStatus  = "status"
+)
+
+// statusPermissionPrefix prefixes the permission code of a delivery status
+const statusPermissionPrefix = "status."
+
+// permissionCacheTTL bounds how long another instance's changes can take to apply
+const permissionCacheTTL = 30 * time.Second
+
+// StatusPermissionCode returns the permission code required to set a delivery status
+func StatusPermissionCode(status string) string {
+	return statusPermissionPrefix + status
+}
+
+// PermissionDefinition describes a built-in permission and the roles that get
+// it when the permission is first seeded
+type PermissionDefinition struct {
+	Code        string
+	Category    string
+	Description string
+	Roles       []string
+}
+
+var (
+	allStaffRoles      = []string{"kepala_sppg", "kepala_yayasan", "akuntan", "ahli_gizi", "pengadaan", "chef", "packing", "driver", "asisten_lapangan", "kebersihan"}
+	allButCleaningRole = []string{"kepala_sppg", "kepala_yayasan", "akuntan", "ahli_gizi", "pengadaan", "chef", "packing", "driver", "asisten_lapangan"}
+)
+
+// DefaultFeaturePermissions are the built-in feature permissions
+var DefaultFeaturePermissions = []PermissionDefinition{
+	{"dashboard", PermissionCategoryFeature, "Melihat dashboard dan KPI", allStaffRoles},
+	{"dashboard_executive", PermissionCategoryFeature, "Dashboard eksekutif, sinkronisasi dan ekspor", []string{"kepala_sppg", "kepala_yayasan"}},
+	{"financial_reports", PermissionCategoryFeature, "Melihat laporan keuangan, aset dan arus kas", []string{"kepala_sppg", "kepala_yayasan", "akuntan"}},
+	{"finance_management", PermissionCategoryFeature, "Mengelola aset dan arus kas", []string{"kepala_sppg", "akuntan"}},
+	{"asset_maintenance", PermissionCategoryFeature, "Melihat jadwal dan mencatat pemeliharaan aset", []string{"kepala_sppg", "kepala_yayasan", "akuntan", "chef", "driver", "asisten_lapangan"}},
+	{"asset_audit", PermissionCategoryFeature, "Melakukan audit fisik aset dengan pemindaian label", []string{"kepala_sppg", "akuntan", "pengadaan", "chef", "asisten_lapangan"}},
+	{"asset_audit_approve", PermissionCategoryFeature, "Menyetujui audit fisik aset", []string{"kepala_sppg"}},
+	{"petty_cash", PermissionCategoryFeature, "Mencatat pengeluaran dan mengajukan penggantian kas kecil", []string{"kepala_sppg", "akuntan", "pengadaan", "chef", "asisten_lapangan"}},
+	{"petty_cash_approve", PermissionCategoryFeature, "Menyetujui penggantian kas kecil", []string{"kepala_sppg"}},
+	{"menu_planning_view", PermissionCategoryFeature, "Melihat rencana menu", allStaffRoles},
+	{"menu_planning", PermissionCategoryFeature, "Menyusun rencana menu", []string{"kepala_sppg", "ahli_gizi"}},
+	{"menu_planning_approve", PermissionCategoryFeature, "Menyetujui rencana menu", []string{"kepala_sppg", "kepala_yayasan"}},
+	{"recipe_view", PermissionCategoryFeature, "Melihat resep dan bahan", allButCleaningRole},
+	{"recipe_management", PermissionCategoryFeature, "Mengelola resep dan bahan", []string{"kepala_sppg", "ahli_gizi"}},
+	{"semi_finished_production", PermissionCategoryFeature, "Mengelola dan memproduksi barang setengah jadi", []string{"kepala_sppg", "ahli_gizi", "chef"}},
+	{"kitchen_display", PermissionCategoryFeature, "Kitchen display memasak dan packing", []string{"kepala_sppg", "ahli_gizi", "chef", "packing"}},
+	{"procurement_view", PermissionCategoryFeature, "Melihat supplier, PO dan penerimaan barang", []string{"kepala_sppg", "kepala_yayasan", "akuntan", "pengadaan"}},
+	{"procurement", PermissionCategoryFeature, "Mengelola supplier, PO dan penerimaan barang", []string{"kepala_sppg", "pengadaan"}},
+	{"procurement_approve", PermissionCategoryFeature, "Menyetujui purchase order", []string{"kepala_sppg"}},
+	{"inventory_view", PermissionCategoryFeature, "Melihat stok dan pergerakan inventaris", []string{"kepala_sppg", "akuntan", "pengadaan", "ahli_gizi", "chef"}},
+	{"inventory", PermissionCategoryFeature, "Inventaris dan stok opname", []string{"kepala_sppg", "akuntan", "pengadaan"}},
+	{"stok_opname_approve", PermissionCategoryFeature, "Menyetujui atau menolak stok opname", []string{"kepala_sppg"}},
+	{"school_view", PermissionCategoryFeature, "Melihat data sekolah", allButCleaningRole},
+	{"school_management", PermissionCategoryFeature, "Mengelola data sekolah", []string{"kepala_sppg"}},
+	{"delivery_tasks", PermissionCategoryFeature, "Tugas pengiriman, e-POD dan ompreng", []string{"kepala_sppg", "kepala_yayasan", "driver", "asisten_lapangan"}},
+	{"delivery_task_management", PermissionCategoryFeature, "Membuat, mengubah dan menghapus tugas pengiriman", []string{"kepala_sppg"}},
+	{"pickup_tasks", PermissionCategoryFeature, "Tugas pengambilan ompreng", []string{"kepala_sppg", "kepala_yayasan", "driver", "asisten_lapangan"}},
+	{"reviews", PermissionCategoryFeature, "Ulasan pengiriman", []string{"kepala_sppg", "kepala_yayasan", "akuntan", "driver", "asisten_lapangan"}},
+	{"attendance", PermissionCategoryFeature, "Absensi pribadi", allStaffRoles},
+	{"attendance_report", PermissionCategoryFeature, "Laporan dan ekspor absensi", []string{"kepala_sppg", "akuntan"}},
+	{"employee_view", PermissionCategoryFeature, "Melihat data karyawan", allStaffRoles},
+	{"hrm_management", PermissionCategoryFeature, "Mengelola karyawan dan konfigurasi absensi", []string{"kepala_sppg", "akuntan"}},
+	{"monitoring", PermissionCategoryFeature, "Monitoring logistik", allButCleaningRole},
+	{"cleaning", PermissionCategoryFeature, "Modul pencucian ompreng", []string{"kebersihan", "kepala_sppg", "kepala_yayasan"}},
+	{"activity_tracker", PermissionCategoryFeature, "Activity tracker pesanan", []string{"kepala_sppg", "kepala_yayasan", "akuntan"}},
+	{"audit_trail", PermissionCategoryFeature, "Melihat audit trail", []string{"kepala_sppg", "kepala_yayasan"}},
+	{"system_config", PermissionCategoryFeature, "Mengubah konfigurasi sistem", []string{"kepala_sppg"}},
+	{"role_management", PermissionCategoryFeature, "Mengelola role dan permission", []string{"kepala_sppg"}},
+}
+
+// defaultStatusRoles lists which roles may set each delivery status.
+// kepala_sppg and kepala_yayasan can override every status.
+var defaultStatusRoles = map[string][]string{
+	// Cooking statuses - chef role
+	"sedang_dimasak":  {"chef", "kepala_sppg", "kepala_yayasan"},
+	"selesai_dimasak": {"chef", "kepala_sppg", "kepala_yayasan"},
+
+	// Packing statuses - packing role
+	"siap_dipacking":    {"packing", "kepala_sppg", "kepala_yayasan"},
+	"selesai_dipacking": {"packing", "kepala_sppg", "kepala_yayasan"},
+
+	// Delivery statuses - driver role
+	"siap_dikirim":                 {"driver", "kepala_sppg", "kepala_yayasan"},
+	"diperjalanan":                 {"driver", "kepala_sppg", "kepala_yayasan"},
+	"sudah_sampai_sekolah":         {"driver", "kepala_sppg", "kepala_yayasan"},
+	"sudah_diterima_pihak_sekolah": {"driver", "kepala_sppg", "kepala_yayasan"},
+
+	// Collection statuses - driver role
+	"driver_ditugaskan_mengambil_ompreng": {"driver", "kepala_sppg", "kepala_yayasan"},
+	"driver_menuju_sekolah":               {"driver", "kepala_sppg", "kepala_yayasan"},
+	"driver_sampai_di_sekolah":            {"driver", "kepala_sppg", "kepala_yayasan"},
+	"ompreng_telah_diambil":               {"driver", "kepala_sppg", "kepala_yayasan"},
+	"ompreng_sampai_di_sppg":              {"driver", "kepala_sppg", "kepala_yayasan"},
+
+	// Cleaning statuses - kebersihan role
+	"ompreng_proses_pencucian": {"kebersihan", "kepala_sppg", "kepala_yayasan"},
+	"ompreng_selesai_dicuci":   {"kebersihan", "kepala_sppg", "kepala_yayasan"},
+}
+
+// roleDisplayNames are the display names of the built-in roles
+var roleDisplayNames = map[string]string{
+	"kepala_sppg":      "Kepala SPPG",
+	"kepala_yayasan":   "Kepala Yayasan",
+	"akuntan":          "Akuntan",
+	"ahli_gizi":        "Ahli Gizi",
+	"pengadaan":        "Pengadaan",
+	"chef":             "Chef",
+	"packing":          "Packing",
+	"driver":           "Driver",
+	"asisten_lapangan": "Asisten Lapangan",
+	"kebersihan":       "Kebersihan",
+}
+
+// DefaultPermissions returns every built-in permission, features first
+func DefaultPermissions() []PermissionDefinition {
+	defs := make([]PermissionDefinition, 0, len(DefaultFeaturePermissions)+len(defaultStatusRoles))
+	defs = append(defs, DefaultFeaturePermissions...)
+
+	statuses := make([]string, 0, len(defaultStatusRoles))
+	for status := range defaultStatusRoles {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		defs = append(defs, PermissionDefinition{
+			Code:        StatusPermissionCode(status),
+			Category:    PermissionCategoryStatus,
+			Description: "Mengubah status pengiriman menjadi " + status,
+			Roles:       defaultStatusRoles[status],
+		})
+	}
+	return defs
+}
+
+// EffectivePermissions is the resolved permission set of a user
+type EffectivePermissions struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	Statuses    []string `json:"statuses"` // delivery statuses the user may set
+}
+
+// RoleWithPermissions is a role together with its permission codes
+type RoleWithPermissions struct {
+	models.Role
+	Permissions []string `json:"permissions"`
+	UserCount   int64    `json:"user_count"`
+}
+
+// PermissionService stores roles and permissions in the database and answers
+// permission checks from a short-lived in-memory snapshot
+type PermissionService struct {
+	db *gorm.DB
+
+	mu        sync.RWMutex
+	loadedAt  time.Time
+	rolePerms map[string]map[string]bool // role name -> permission codes, active roles only
+	overrides map[uint]map[string]bool   // user ID -> permission code -> granted
+}
+
+// NewPermissionService creates a new permission service
+func NewPermissionService(db *gorm.DB) *PermissionService {
+	return &PermissionService{db: db}
+}
+
+// SeedDefaults creates the built-in roles and permissions that do not exist yet.
+// Role grants are only added for newly created roles or permissions so that
+// changes made by administrators survive restarts.
+func (s *PermissionService) SeedDefaults() error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		roleIDs := make(map[string]uint)
+		newRoles := make(map[string]bool)
+		for _, name := range models.UserRoles {
+			var role models.Role
+			err := tx.Where("name = ?", name).First(&role).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				role = models.Role{
+					Name:        name,
+					DisplayName: roleDisplayNames[name],
+					IsSystem:    true,
+					IsActive:    true,
+				}
+				if err := tx.Create(&role).Error; err != nil {
+					return err
+				}
+				newRoles[name] = true
+			} else if err != nil {
+				return err
+			}
+			roleIDs[name] = role.ID
+		}
+
+		for _, def := range DefaultPermissions() {
+			var permission models.Permission
+			err := tx.Where("code = ?", def.Code).First(&permission).Error
+			isNew := false
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				permission = models.Permission{
+					Code:        def.Code,
+					Category:    def.Category,
+					Description: def.Description,
+				}
+				if err := tx.Create(&permission).Error; err != nil {
+					return err
+				}
+				isNew = true
+			} else if err != nil {
+				return err
+			}
+
+			for _, roleName := range def.Roles {
+				if !isNew && !newRoles[roleName] {
+					continue
+				}
+				roleID, ok := roleIDs[roleName]
+				if !ok {
+					continue
+				}
+				grant := models.RolePermission{RoleID: roleID, PermissionID: permission.ID}
+				if err := tx.Where(grant).FirstOrCreate(&grant).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Invalidate()
+	return nil
+}
+
+// Invalidate drops the cached snapshot so the next check reads the database
+func (s *PermissionService) Invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// HasPermission reports whether the user may use the permission.
+// A user override wins over the role's grants.
+func (s *PermissionService) HasPermission(userID uint, role, code string) bool {
+	if err := s.ensureLoaded(); err != nil {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if granted, ok := s.overrides[userID][code]; ok {
+		return granted
+	}
+	return s.rolePerms[role][code]
+}
+
+// CanUpdateStatus reports whether the user may set a delivery status
+func (s *PermissionService) CanUpdateStatus(userID uint, role, status string) bool {
+	return s.HasPermission(userID, role, StatusPermissionCode(status))
+}
+
+// GetEffectivePermissions resolves the role grants and user overrides
+func (s *PermissionService) GetEffectivePermissions(userID uint, role string) (*EffectivePermissions, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	codes := make(map[string]bool, len(s.rolePerms[role]))
+	for code := range s.rolePerms[role] {
+		codes[code] = true
+	}
+	for code, granted := range s.overrides[userID] {
+		codes[code] = granted
+	}
+	s.mu.RUnlock()
+
+	result := &EffectivePermissions{
+		Role:        role,
+		Permissions: []string{},
+		Statuses:    []string{},
+	}
+	for code, granted := range codes {
+		if !granted {
+			continue
+		}
+		result.Permissions = append(result.Permissions, code)
+		if strings.HasPrefix(code, statusPermissionPrefix) {
+			result.Statuses = append(result.Statuses, strings.TrimPrefix(code, statusPermissionPrefix))
+		}
+	}
+	sort.Strings(result.Permissions)
+	sort.Strings(result.Statuses)
+	return result, nil
+}
+
+// ValidateRole checks that the role exists and is active. Before the roles
+// table is seeded the built-in roles are accepted.
+func (s *PermissionService) ValidateRole(name string) error {
+	return validateRole(s.db, name)
+}
+
+// validateRole is shared with services that create users
+func validateRole(db *gorm.DB, name string) error {
+	roles, err := activeRoleNames(db)
+	if err != nil {
+		return err
+	}
+	if !roles[name] {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
+// activeRoleNames returns the names of all active roles, or the built-in
+// roles while the roles table is still empty
+func activeRoleNames(db *gorm.DB) (map[string]bool, error) {
+	var names []string
+	if err := db.Model(&models.Role{}).Where("is_active = ?", true).Pluck("name", &names).Error; err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		var total int64
+		if err := db.Model(&models.Role{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		if total == 0 {
+			names = models.UserRoles
+		}
+	}
+
+	roles := make(map[string]bool, len(names))
+	for _, name := range names {
+		roles[name] = true
+	}
+	return roles, nil
+}
+
+// ListPermissions returns every permission ordered by category and code
+func (s *PermissionService) ListPermissions() ([]models.Permission, error) {
+	var permissions []models.Permission
+	if err := s.db.Order("category ASC, code ASC").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// ListRoles returns all roles with their permissions and number of users
+func (s *PermissionService) ListRoles() ([]RoleWithPermissions, error) {
+	var roles []models.Role
+	if err := s.db.Order("is_system DESC, name ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]RoleWithPermissions, 0, len(roles))
+	for _, role := range roles {
+		detail, err := s.describeRole(role)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *detail)
+	}
+	return result, nil
+}
+
+// GetRole returns a role with its permissions
+func (s *PermissionService) GetRole(id uint) (*RoleWithPermissions, error) {
+	role, err := s.findRole(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.describeRole(*role)
+}
+
+// CreateRole creates a custom role with the given permissions
+func (s *PermissionService) CreateRole(role *models.Role, permissionCodes []string, createdBy uint) error {
+	role.Name = strings.TrimSpace(role.Name)
+	if !isValidRoleName(role.Name) {
+		return ErrInvalidRoleName
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Role{}).Where("name = ?", role.Name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRoleAlreadyExists
+	}
+
+	role.ID = 0
+	role.IsSystem = false
+	role.IsActive = true
+	if role.DisplayName == "" {
+		role.DisplayName = role.Name
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(role).Error; err != nil {
+			return err
+		}
+		return replaceRolePermissions(tx, role.ID, permissionCodes)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Invalidate()
+	NewAuditTrailService(s.db).RecordAction(createdBy, "create", "role", role.Name, nil, map[string]interface{}{
+		"display_name": role.DisplayName,
+		"permissions":  permissionCodes,
+	}, "")
+	return nil
+}
+
+// UpdateRole changes the display name, description and active flag of a role.
+// The name is immutable because users reference roles by name.
+func (s *PermissionService) UpdateRole(id uint, displayName, description string, isActive bool, updatedBy uint) (*models.Role, error) {
+	role, err := s.findRole(id)
+	if err != nil {
+		return nil, err
+	}
+
+	old := map[string]interface{}{"display_name": role.DisplayName, "description": role.Description, "is_active": role.IsActive}
+	updates := map[string]interface{}{"description": description, "is_active": isActive}
+	if displayName != "" {
+		updates["display_name"] = displayName
+	}
+	if err := s.db.Model(role).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	s.Invalidate()
+	NewAuditTrailService(s.db).RecordAction(updatedBy, "update", "role", role.Name, old, updates, "")
+	return s.findRole(id)
+}
+
+// DeleteRole deletes a custom role that no user has
+func (s *PermissionService) DeleteRole(id uint, deletedBy uint) error {
+	role, err := s.findRole(id)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return ErrSystemRole
+	}
+
+	var users int64
+	if err := s.db.Model(&models.User{}).Where("role = ?", role.Name).Count(&users).Error; err != nil {
+		return err
+	}
+	if users > 0 {
+		return ErrRoleInUse
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(role).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Invalidate()
+	NewAuditTrailService(s.db).RecordAction(deletedBy, "delete", "role", role.Name, nil, nil, "")
+	return nil
+}
+
+// SetRolePermissions replaces the permissions of a role
+func (s *PermissionService) SetRolePermissions(id uint, permissionCodes []string, updatedBy uint) error {
+	role, err := s.findRole(id)
+	if err != nil {
+		return err
+	}
+
+	before, err := s.rolePermissionCodes(role.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRolePermissions(tx, role.ID, permissionCodes)
+	}); err != nil {
+		return err
+	}
+
+	s.Invalidate()
+	NewAuditTrailService(s.db).RecordAction(updatedBy, "update_permissions", "role", role.Name,
+		map[string]interface{}{"permissions": before},
+		map[string]interface{}{"permissions": permissionCodes}, "")
+	return nil
+}
+
+// ListUserOverrides returns the permission overrides of a user
+func (s *PermissionService) ListUserOverrides(userID uint) ([]models.UserPermissionOverride, error) {
+	var overrides []models.UserPermissionOverride
+	if err := s.db.Preload("Permission").Where("user_id = ?", userID).Order("id ASC").Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// SetUserOverride grants or revokes one permission for a user
+func (s *PermissionService) SetUserOverride(userID uint, code string, granted bool, reason string, createdBy uint) (*models.UserPermissionOverride, error) {
+	if err := s.db.First(&models.User{}, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	var permission models.Permission
+	if err := s.db.Where("code = ?", code).First(&permission).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPermissionNotFound
+		}
+		return nil, err
+	}
+
+	var override models.UserPermissionOverride
+	err := s.db.Where("user_id = ? AND permission_id = ?", userID, permission.ID).First(&override).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	override.UserID = userID
+	override.PermissionID = permission.ID
+	override.Granted = granted
+	override.Reason = reason
+	override.CreatedBy = createdBy
+	if err := s.db.Save(&override).Error; err != nil {
+		return nil, err
+	}
+	override.Permission = permission
+
+	s.Invalidate()
+	NewAuditTrailService(s.db).RecordAction(createdBy, "permission_override", "user", strconv.FormatUint(uint64(userID), 10), nil, map[string]interface{}{
+		"permission": code,
+		"granted":    granted,
+		"reason":     reason,
+	}, "")
+	return &override, nil
+}
+
+// RemoveUserOverride deletes a user's override so the role grant applies again
+func (s *PermissionService) RemoveUserOverride(userID uint, code string, removedBy uint) error {
+	var permission models.Permission
+	if err := s.db.Where("code = ?", code).First(&permission).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPermissionNotFound
+		}
+		return err
+	}
+
+	result := s.db.Where("user_id = ? AND permission_id = ?", userID, permission.ID).Delete(&models.UserPermissionOverride{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPermissionNotFound
+	}
+
+	s.Invalidate()
+	NewAuditTrailService(s.db).RecordAction(removedBy, "remove_permission_override", "user", strconv.FormatUint(uint64(userID), 10), nil, map[string]interface{}{
+		"permission": code,
+	}, "")
+	return nil
+}
+
+// ensureLoaded refreshes the snapshot when it is older than permissionCacheTTL
+func (s *PermissionService) ensureLoaded() error {
+	s.mu.RLock()
+	fresh := !s.loadedAt.IsZero() && time.Since(s.loadedAt) < permissionCacheTTL
+	s.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	type grantRow struct {
+		RoleName string
+		Code     string
+	}
+	var grants []grantRow
+	if err := s.db.Table("role_permissions").
+		Select("roles.name AS role_name, permissions.code AS code").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("roles.is_active = ?", true).
+		Scan(&grants).Error; err != nil {
+		return err
+	}
+
+	type overrideRow struct {
+		UserID  uint
+		Code    string
+		Granted bool
+	}
+	var overrideRows []overrideRow
+	if err := s.db.Table("user_permission_overrides").
+		Select("user_permission_overrides.user_id, permissions.code, user_permission_overrides.granted").
+		Joins("JOIN permissions ON permissions.id = user_permission_overrides.permission_id").
+		Scan(&overrideRows).Error; err != nil {
+		return err
+	}
+
+	rolePerms := make(map[string]map[string]bool)
+	for _, g := range grants {
+		if rolePerms[g.RoleName] == nil {
+			rolePerms[g.RoleName] = make(map[string]bool)
+		}
+		rolePerms[g.RoleName][g.Code] = true
+	}
+	overrides := make(map[uint]map[string]bool)
+	for _, o := range overrideRows {
+		if overrides[o.UserID] == nil {
+			overrides[o.UserID] = make(map[string]bool)
+		}
+		overrides[o.UserID][o.Code] = o.Granted
+	}
+
+	s.mu.Lock()
+	s.rolePerms = rolePerms
+	s.overrides = overrides
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// findRole loads a role by ID
+func (s *PermissionService) findRole(id uint) (*models.Role, error) {
+	var role models.Role
+	if err := s.db.First(&role, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// describeRole adds permission codes and the user count to a role
+func (s *PermissionService) describeRole(role models.Role) (*RoleWithPermissions, error) {
+	codes, err := s.rolePermissionCodes(role.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var users int64
+	if err := s.db.Model(&models.User{}).Where("role = ?", role.Name).Count(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return &RoleWithPermissions{Role: role, Permissions: codes, UserCount: users}, nil
+}
+
+// rolePermissionCodes returns the sorted permission codes of a role
+func (s *PermissionService) rolePermissionCodes(roleID uint) ([]string, error) {
+	codes := []string{}
+	if err := s.db.Table("role_permissions").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("role_permissions.role_id = ?", roleID).
+		Order("permissions.code ASC").
+		Pluck("permissions.code", &codes).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// replaceRolePermissions swaps the grants of a role inside a transaction
+func replaceRolePermissions(tx *gorm.DB, roleID uint, codes []string) error {
+	var permissions []models.Permission
+	if len(codes) > 0 {
+		if err := tx.Where("code IN ?", codes).Find(&permissions).Error; err != nil {
+			return err
+		}
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		found[p.Code] = true
+	}
+	for _, code := range codes {
+		if !found[code] {
+			return ErrPermissionNotFound
+		}
+	}
+
+	if err := tx.Where("role_id = ?", roleID).Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	for _, p := range permissions {
+		if err := tx.Create(&models.RolePermission{RoleID: roleID, PermissionID: p.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isValidRoleName accepts snake_case identifiers like the built-in roles
+func isValidRoleName(name string) bool {
+	if name == "" || len(name) > 50 {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
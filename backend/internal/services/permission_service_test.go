@@ -0,0 +1,134 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupPermissionTestDB(t *testing.T) *gorm.DB {
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Role{}, &models.Permission{}, &models.RolePermission{},
+		&models.UserPermissionOverride{}, &models.AuditTrail{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestPermissionService_SeedDefaultsMatchesBuiltInRules(t *testing.T) {
+	db := setupPermissionTestDB(t)
+	service := NewPermissionService(db)
+	require.NoError(t, service.SeedDefaults())
+
+	assert.True(t, service.HasPermission(1, "kepala_sppg", "hrm_management"))
+	assert.True(t, service.HasPermission(1, "akuntan", "financial_reports"))
+	assert.False(t, service.HasPermission(1, "chef", "financial_reports"))
+	assert.False(t, service.HasPermission(1, "kebersihan", "monitoring"))
+	assert.False(t, service.HasPermission(1, "chef", "unknown_feature"))
+
+	// Screens every role reached before the permission checks stay readable
+	for _, role := range []string{"chef", "packing", "driver", "asisten_lapangan", "kebersihan"} {
+		assert.True(t, service.HasPermission(1, role, "dashboard"), role)
+		assert.True(t, service.HasPermission(1, role, "menu_planning_view"), role)
+		assert.True(t, service.HasPermission(1, role, "employee_view"), role)
+		assert.False(t, service.HasPermission(1, role, "hrm_management"), role)
+	}
+	assert.True(t, service.HasPermission(1, "chef", "inventory_view"))
+	assert.False(t, service.HasPermission(1, "chef", "inventory"))
+
+	assert.True(t, service.CanUpdateStatus(1, "chef", "sedang_dimasak"))
+	assert.False(t, service.CanUpdateStatus(1, "chef", "diperjalanan"))
+	assert.True(t, service.CanUpdateStatus(1, "kepala_yayasan", "ompreng_selesai_dicuci"))
+	assert.False(t, service.CanUpdateStatus(1, "kepala_sppg", "status_tidak_dikenal"))
+
+	// Seeding again must not restore grants removed by an administrator
+	var chef models.Role
+	require.NoError(t, db.Where("name = ?", "chef").First(&chef).Error)
+	require.NoError(t, service.SetRolePermissions(chef.ID, []string{"kitchen_display"}, 1))
+	require.NoError(t, service.SeedDefaults())
+	assert.False(t, service.CanUpdateStatus(1, "chef", "sedang_dimasak"))
+	assert.True(t, service.HasPermission(1, "chef", "kitchen_display"))
+
+	var roles int64
+	db.Model(&models.Role{}).Count(&roles)
+	assert.Equal(t, int64(len(models.UserRoles)), roles)
+}
+
+func TestPermissionService_UserOverrides(t *testing.T) {
+	db := setupPermissionTestDB(t)
+	service := NewPermissionService(db)
+	require.NoError(t, service.SeedDefaults())
+
+	user := models.User{NIK: "3201010101010001", Email: "chef@example.com", PasswordHash: "x", FullName: "Chef", Role: "chef", IsActive: true}
+	require.NoError(t, db.Create(&user).Error)
+
+	_, err := service.SetUserOverride(user.ID, "inventory", true, "membantu stok opname", 1)
+	require.NoError(t, err)
+	_, err = service.SetUserOverride(user.ID, "kitchen_display", false, "", 1)
+	require.NoError(t, err)
+
+	assert.True(t, service.HasPermission(user.ID, "chef", "inventory"))
+	assert.False(t, service.HasPermission(user.ID, "chef", "kitchen_display"))
+	// Other chefs keep the role defaults
+	assert.False(t, service.HasPermission(user.ID+1, "chef", "inventory"))
+	assert.True(t, service.HasPermission(user.ID+1, "chef", "kitchen_display"))
+
+	effective, err := service.GetEffectivePermissions(user.ID, "chef")
+	require.NoError(t, err)
+	assert.Contains(t, effective.Permissions, "inventory")
+	assert.NotContains(t, effective.Permissions, "kitchen_display")
+	assert.Contains(t, effective.Statuses, "sedang_dimasak")
+
+	require.NoError(t, service.RemoveUserOverride(user.ID, "kitchen_display", 1))
+	assert.True(t, service.HasPermission(user.ID, "chef", "kitchen_display"))
+	assert.ErrorIs(t, service.RemoveUserOverride(user.ID, "kitchen_display", 1), ErrPermissionNotFound)
+
+	_, err = service.SetUserOverride(user.ID, "tidak_ada", true, "", 1)
+	assert.ErrorIs(t, err, ErrPermissionNotFound)
+	_, err = service.SetUserOverride(9999, "inventory", true, "", 1)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestPermissionService_CustomRoles(t *testing.T) {
+	db := setupPermissionTestDB(t)
+	service := NewPermissionService(db)
+	require.NoError(t, service.SeedDefaults())
+
+	role := &models.Role{Name: "gudang", DisplayName: "Staf Gudang"}
+	require.NoError(t, service.CreateRole(role, []string{"inventory"}, 1))
+	assert.True(t, service.HasPermission(2, "gudang", "inventory"))
+	assert.NoError(t, service.ValidateRole("gudang"))
+
+	assert.ErrorIs(t, service.CreateRole(&models.Role{Name: "gudang"}, nil, 1), ErrRoleAlreadyExists)
+	assert.ErrorIs(t, service.CreateRole(&models.Role{Name: "Staf Gudang"}, nil, 1), ErrInvalidRoleName)
+	assert.ErrorIs(t, service.CreateRole(&models.Role{Name: "dapur"}, []string{"tidak_ada"}, 1), ErrPermissionNotFound)
+
+	// Deactivated roles lose their permissions and cannot be assigned
+	_, err := service.UpdateRole(role.ID, "", "", false, 1)
+	require.NoError(t, err)
+	assert.False(t, service.HasPermission(2, "gudang", "inventory"))
+	assert.ErrorIs(t, service.ValidateRole("gudang"), ErrInvalidRole)
+
+	user := models.User{NIK: "3201010101010002", Email: "gudang@example.com", PasswordHash: "x", FullName: "Gudang", Role: "gudang", IsActive: true}
+	require.NoError(t, db.Create(&user).Error)
+	assert.ErrorIs(t, service.DeleteRole(role.ID, 1), ErrRoleInUse)
+	require.NoError(t, db.Delete(&user).Error)
+	require.NoError(t, service.DeleteRole(role.ID, 1))
+
+	var chef models.Role
+	require.NoError(t, db.Where("name = ?", "chef").First(&chef).Error)
+	assert.ErrorIs(t, service.DeleteRole(chef.ID, 1), ErrSystemRole)
+}
+
+func TestPermissionService_ValidateRoleBeforeSeeding(t *testing.T) {
+	db := setupPermissionTestDB(t)
+	service := NewPermissionService(db)
+
+	assert.NoError(t, service.ValidateRole("chef"))
+	assert.ErrorIs(t, service.ValidateRole("admin"), ErrInvalidRole)
+}
@@ -0,0 +1,564 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/events"
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPettyCashFundNotFound          = errors.New("dana kas kecil tidak ditemukan")
+	ErrPettyCashFundInactive          = errors.New("dana kas kecil tidak aktif")
+	ErrInvalidPettyCashFloat          = errors.New("plafon kas kecil harus lebih dari nol")
+	ErrPettyCashFloatBelowSpent       = errors.New("plafon kas kecil tidak boleh lebih kecil dari pengeluaran yang belum diganti")
+	ErrInvalidPettyCashCustodian      = errors.New("pemegang kas kecil tidak ditemukan atau tidak aktif")
+	ErrNotPettyCashCustodian          = errors.New("hanya pemegang kas kecil yang dapat mencatat pengeluaran")
+	ErrInvalidPettyCashAmount         = errors.New("jumlah pengeluaran harus lebih dari nol")
+	ErrInsufficientPettyCash          = errors.New("saldo kas kecil tidak mencukupi")
+	ErrReceiptPhotoRequired           = errors.New("foto nota wajib dilampirkan")
+	ErrInvalidPettyCashQuantity       = errors.New("jumlah bahan harus lebih dari nol")
+	ErrPettyCashReplenishmentNotFound = errors.New("permintaan penggantian kas kecil tidak ditemukan")
+	ErrNothingToReplenish             = errors.New("tidak ada pengeluaran kas kecil yang perlu diganti")
+	ErrReplenishmentPending           = errors.New("masih ada permintaan penggantian kas kecil yang menunggu persetujuan")
+	ErrReplenishmentNotPending        = errors.New("permintaan penggantian kas kecil sudah diproses")
+	ErrSelfApproval                   = errors.New("permintaan tidak dapat disetujui oleh pengaju sendiri")
+	ErrRejectionReasonRequired        = errors.New("alasan penolakan wajib diisi")
+)
+
+// Petty cash replenishment statuses
+const (
+	ReplenishmentStatusPending  = "pending"
+	ReplenishmentStatusApproved = "approved"
+	ReplenishmentStatusRejected = "rejected"
+)
+
+// PettyCashService handles petty cash funds, expenses and replenishments.
+// Expenses are booked as cash flow expenses when they are spent; food
+// purchases tagged with an ingredient also add stock. Replenishments only
+// restore the fund balance, since the cash was already booked.
+type PettyCashService struct {
+	db                  *gorm.DB
+	cashFlowService     *CashFlowService
+	inventoryService    *InventoryService
+	notificationService *NotificationService
+}
+
+// NewPettyCashService creates a new petty cash service. notificationService may
+// be nil, in which case notifications are stored without a realtime push.
+func NewPettyCashService(db *gorm.DB, cashFlowService *CashFlowService, notificationService *NotificationService) *PettyCashService {
+	return &PettyCashService{
+		db:                  db,
+		cashFlowService:     cashFlowService,
+		inventoryService:    NewInventoryService(db),
+		notificationService: notificationService,
+	}
+}
+
+// CreateFund creates a petty cash fund holding its full float
+func (s *PettyCashService) CreateFund(fund *models.PettyCashFund, userID uint) error {
+	fund.Name = strings.TrimSpace(fund.Name)
+	if fund.FloatAmount <= 0 {
+		return ErrInvalidPettyCashFloat
+	}
+	if err := s.validateCustodian(fund.CustodianID); err != nil {
+		return err
+	}
+
+	fund.ID = 0
+	fund.Balance = fund.FloatAmount
+	fund.IsActive = true
+	fund.CreatedBy = userID
+	if err := s.db.Create(fund).Error; err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "petty_cash_fund", fmt.Sprint(fund.ID), nil, fund, "")
+	return nil
+}
+
+// UpdateFund updates the name, custodian, float and active flag of a fund.
+// Changing the float moves the balance by the same difference.
+func (s *PettyCashService) UpdateFund(id uint, updates *models.PettyCashFund, userID uint) (*models.PettyCashFund, error) {
+	if updates.FloatAmount <= 0 {
+		return nil, ErrInvalidPettyCashFloat
+	}
+	if err := s.validateCustodian(updates.CustodianID); err != nil {
+		return nil, err
+	}
+
+	var oldFund, fund models.PettyCashFund
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&fund, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPettyCashFundNotFound
+			}
+			return err
+		}
+		oldFund = fund
+
+		floatAmount := updates.FloatAmount
+		balance := fund.Balance + floatAmount - fund.FloatAmount
+		if balance < 0 {
+			return ErrPettyCashFloatBelowSpent
+		}
+
+		fund.Name = strings.TrimSpace(updates.Name)
+		fund.CustodianID = updates.CustodianID
+		fund.FloatAmount = floatAmount
+		fund.Balance = balance
+		fund.IsActive = updates.IsActive
+		return tx.Model(&models.PettyCashFund{}).Where("id = ?", fund.ID).Updates(map[string]interface{}{
+			"name":         fund.Name,
+			"custodian_id": fund.CustodianID,
+			"float_amount": fund.FloatAmount,
+			"balance":      fund.Balance,
+			"is_active":    fund.IsActive,
+			"updated_at":   time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "update", "petty_cash_fund", fmt.Sprint(fund.ID), oldFund, fund, "")
+	return s.GetFund(fund.ID)
+}
+
+// GetFunds retrieves petty cash funds, optionally only those held by a custodian
+func (s *PettyCashService) GetFunds(custodianID uint) ([]models.PettyCashFund, error) {
+	query := s.db.Preload("Custodian")
+	if custodianID > 0 {
+		query = query.Where("custodian_id = ?", custodianID)
+	}
+
+	var funds []models.PettyCashFund
+	err := query.Order("name ASC").Find(&funds).Error
+	return funds, err
+}
+
+// GetFund retrieves a petty cash fund by ID
+func (s *PettyCashService) GetFund(id uint) (*models.PettyCashFund, error) {
+	var fund models.PettyCashFund
+	if err := s.db.Preload("Custodian").First(&fund, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPettyCashFundNotFound
+		}
+		return nil, err
+	}
+	return &fund, nil
+}
+
+// RecordExpense records a purchase paid from a fund by its custodian. The
+// expense is booked as a cash flow expense; when it is tagged with an
+// ingredient the purchased quantity is also added to stock.
+func (s *PettyCashService) RecordExpense(fundID uint, expense *models.PettyCashExpense, userID uint) error {
+	expense.Description = strings.TrimSpace(expense.Description)
+	if expense.ReceiptPhoto == "" {
+		return ErrReceiptPhotoRequired
+	}
+	if expense.Amount <= 0 {
+		return ErrInvalidPettyCashAmount
+	}
+	if expense.IngredientID != nil {
+		if expense.Quantity <= 0 {
+			return ErrInvalidPettyCashQuantity
+		}
+		// Food purchases are raw material expenses
+		expense.Category = "bahan_baku"
+	} else {
+		expense.Quantity = 0
+	}
+	if expense.ExpenseDate.IsZero() {
+		expense.ExpenseDate = time.Now()
+	}
+
+	var entry models.CashFlowEntry
+	var stockChanged events.StockChanged
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var fund models.PettyCashFund
+		if err := tx.First(&fund, fundID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPettyCashFundNotFound
+			}
+			return err
+		}
+		if !fund.IsActive {
+			return ErrPettyCashFundInactive
+		}
+		if fund.CustodianID != userID {
+			return ErrNotPettyCashCustodian
+		}
+		if expense.Amount > fund.Balance {
+			return ErrInsufficientPettyCash
+		}
+
+		expenseNumber, err := s.generateExpenseNumber(tx, expense.ExpenseDate)
+		if err != nil {
+			return err
+		}
+		expense.ID = 0
+		expense.ExpenseNumber = expenseNumber
+		expense.FundID = fund.ID
+		expense.ReplenishmentID = nil
+		expense.CreatedBy = userID
+
+		entry = models.CashFlowEntry{
+			Date:        expense.ExpenseDate,
+			Category:    expense.Category,
+			Type:        "expense",
+			Amount:      expense.Amount,
+			Description: fmt.Sprintf("Kas kecil %s: %s", fund.Name, expense.Description),
+			Reference:   expenseNumber,
+			CreatedBy:   userID,
+		}
+		if err := s.cashFlowService.CreateCashFlowEntryWithTx(tx, &entry); err != nil {
+			return err
+		}
+		expense.CashFlowEntryID = &entry.ID
+
+		if expense.IngredientID != nil {
+			var ingredient models.Ingredient
+			if err := tx.First(&ingredient, *expense.IngredientID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrIngredientNotFound
+				}
+				return err
+			}
+			if err := s.inventoryService.UpdateStockWithTx(tx, ingredient.ID, expense.Quantity, "in", expenseNumber, userID,
+				"Pembelian kas kecil: "+expense.Description); err != nil {
+				return err
+			}
+
+			var movement models.InventoryMovement
+			if err := tx.Where("reference = ? AND ingredient_id = ?", expenseNumber, ingredient.ID).
+				Order("id DESC").First(&movement).Error; err != nil {
+				return err
+			}
+			expense.InventoryMovementID = &movement.ID
+
+			stockChanged = events.StockChanged{IngredientIDs: []uint{ingredient.ID}, Reason: "in", Reference: expenseNumber}
+			if err := events.PublishTx(tx, stockChanged); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(expense).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.PettyCashFund{}).Where("id = ?", fund.ID).Updates(map[string]interface{}{
+			"balance":    fund.Balance - expense.Amount,
+			"updated_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cashFlowService.CheckBudgetAlerts(&entry)
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "petty_cash_expense", expense.ExpenseNumber, nil, expense, "")
+	if expense.IngredientID != nil {
+		events.Notify(stockChanged)
+	}
+	return nil
+}
+
+// GetExpenses retrieves the expenses of a fund within an optional date range
+func (s *PettyCashService) GetExpenses(fundID uint, startDate, endDate *time.Time) ([]models.PettyCashExpense, error) {
+	query := s.db.Preload("Ingredient").Where("fund_id = ?", fundID)
+	if startDate != nil {
+		query = query.Where("expense_date >= ?", startOfDay(*startDate))
+	}
+	if endDate != nil {
+		query = query.Where("expense_date < ?", nextDay(*endDate))
+	}
+
+	var expenses []models.PettyCashExpense
+	err := query.Order("expense_date DESC, id DESC").Find(&expenses).Error
+	return expenses, err
+}
+
+// RequestReplenishment requests a top-up of a fund for all expenses not yet
+// covered by a replenishment. The Kepala SPPG is notified for approval.
+func (s *PettyCashService) RequestReplenishment(fundID uint, notes string, userID uint) (*models.PettyCashReplenishment, error) {
+	var replenishment models.PettyCashReplenishment
+	var fundName string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var fund models.PettyCashFund
+		if err := tx.First(&fund, fundID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPettyCashFundNotFound
+			}
+			return err
+		}
+		if !fund.IsActive {
+			return ErrPettyCashFundInactive
+		}
+		fundName = fund.Name
+
+		var pending int64
+		if err := tx.Model(&models.PettyCashReplenishment{}).
+			Where("fund_id = ? AND status = ?", fund.ID, ReplenishmentStatusPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrReplenishmentPending
+		}
+
+		var expenses []models.PettyCashExpense
+		if err := tx.Where("fund_id = ? AND replenishment_id IS NULL", fund.ID).Find(&expenses).Error; err != nil {
+			return err
+		}
+		if len(expenses) == 0 {
+			return ErrNothingToReplenish
+		}
+
+		var amount models.Money
+		expenseIDs := make([]uint, 0, len(expenses))
+		for _, expense := range expenses {
+			amount += expense.Amount
+			expenseIDs = append(expenseIDs, expense.ID)
+		}
+
+		replenishmentNumber, err := s.generateReplenishmentNumber(tx, time.Now())
+		if err != nil {
+			return err
+		}
+		replenishment = models.PettyCashReplenishment{
+			ReplenishmentNumber: replenishmentNumber,
+			FundID:              fund.ID,
+			Amount:              amount,
+			Status:              ReplenishmentStatusPending,
+			Notes:               strings.TrimSpace(notes),
+			RequestedBy:         userID,
+		}
+		if err := tx.Create(&replenishment).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.PettyCashExpense{}).Where("id IN ?", expenseIDs).
+			Update("replenishment_id", replenishment.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "create", "petty_cash_replenishment", replenishment.ReplenishmentNumber, nil, replenishment, "")
+
+	var approverIDs []uint
+	if err := s.db.Model(&models.User{}).Where("role = ? AND is_active = ?", "kepala_sppg", true).Pluck("id", &approverIDs).Error; err == nil {
+		for _, approverID := range approverIDs {
+			s.notify(approverID, "Penggantian Kas Kecil Menunggu Persetujuan",
+				fmt.Sprintf("Permintaan penggantian %s untuk kas kecil %s sebesar Rp %s menunggu persetujuan Anda", replenishment.ReplenishmentNumber, fundName, replenishment.Amount))
+		}
+	}
+
+	return s.GetReplenishment(replenishment.ID)
+}
+
+// ApproveReplenishment approves a pending replenishment and restores the fund
+// balance by its amount. Only the Kepala SPPG may approve.
+func (s *PettyCashService) ApproveReplenishment(id uint, approverID uint) (*models.PettyCashReplenishment, error) {
+	if err := s.validateApprover(approverID); err != nil {
+		return nil, err
+	}
+
+	var replenishment models.PettyCashReplenishment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.loadPendingReplenishment(tx, id, approverID, &replenishment); err != nil {
+			return err
+		}
+
+		var fund models.PettyCashFund
+		if err := tx.First(&fund, replenishment.FundID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.PettyCashReplenishment{}).Where("id = ?", replenishment.ID).Updates(map[string]interface{}{
+			"status":      ReplenishmentStatusApproved,
+			"approved_by": approverID,
+			"approved_at": now,
+			"updated_at":  now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.PettyCashFund{}).Where("id = ?", fund.ID).Updates(map[string]interface{}{
+			"balance":    fund.Balance + replenishment.Amount,
+			"updated_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(approverID, "approve", "petty_cash_replenishment", replenishment.ReplenishmentNumber, nil, nil, "")
+	s.notify(replenishment.RequestedBy, "Penggantian Kas Kecil Disetujui",
+		fmt.Sprintf("Permintaan penggantian %s sebesar Rp %s telah disetujui", replenishment.ReplenishmentNumber, replenishment.Amount))
+
+	return s.GetReplenishment(replenishment.ID)
+}
+
+// RejectReplenishment rejects a pending replenishment. Its expenses are
+// released so that they can be included in a new request.
+func (s *PettyCashService) RejectReplenishment(id uint, approverID uint, reason string) (*models.PettyCashReplenishment, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrRejectionReasonRequired
+	}
+	if err := s.validateApprover(approverID); err != nil {
+		return nil, err
+	}
+
+	var replenishment models.PettyCashReplenishment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.loadPendingReplenishment(tx, id, approverID, &replenishment); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.PettyCashReplenishment{}).Where("id = ?", replenishment.ID).Updates(map[string]interface{}{
+			"status":           ReplenishmentStatusRejected,
+			"approved_by":      approverID,
+			"approved_at":      now,
+			"rejection_reason": reason,
+			"updated_at":       now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.PettyCashExpense{}).Where("replenishment_id = ?", replenishment.ID).
+			Update("replenishment_id", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(approverID, "reject", "petty_cash_replenishment", replenishment.ReplenishmentNumber, nil, nil, reason)
+	s.notify(replenishment.RequestedBy, "Penggantian Kas Kecil Ditolak",
+		fmt.Sprintf("Permintaan penggantian %s ditolak: %s", replenishment.ReplenishmentNumber, reason))
+
+	return s.GetReplenishment(replenishment.ID)
+}
+
+// GetReplenishments retrieves replenishment requests, optionally filtered by fund and status
+func (s *PettyCashService) GetReplenishments(fundID uint, status string) ([]models.PettyCashReplenishment, error) {
+	query := s.db.Preload("Fund")
+	if fundID > 0 {
+		query = query.Where("fund_id = ?", fundID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var replenishments []models.PettyCashReplenishment
+	err := query.Order("created_at DESC, id DESC").Find(&replenishments).Error
+	return replenishments, err
+}
+
+// GetReplenishment retrieves a replenishment request with its expenses
+func (s *PettyCashService) GetReplenishment(id uint) (*models.PettyCashReplenishment, error) {
+	var replenishment models.PettyCashReplenishment
+	err := s.db.Preload("Fund.Custodian").
+		Preload("Expenses", func(db *gorm.DB) *gorm.DB { return db.Order("expense_date ASC, id ASC") }).
+		Preload("Expenses.Ingredient").
+		First(&replenishment, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPettyCashReplenishmentNotFound
+		}
+		return nil, err
+	}
+	return &replenishment, nil
+}
+
+// loadPendingReplenishment loads a replenishment that can still be decided by approverID
+func (s *PettyCashService) loadPendingReplenishment(tx *gorm.DB, id, approverID uint, replenishment *models.PettyCashReplenishment) error {
+	if err := tx.First(replenishment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPettyCashReplenishmentNotFound
+		}
+		return err
+	}
+	if replenishment.Status != ReplenishmentStatusPending {
+		return ErrReplenishmentNotPending
+	}
+	if replenishment.RequestedBy == approverID {
+		return ErrSelfApproval
+	}
+	return nil
+}
+
+// validateApprover checks that the user is an active Kepala SPPG
+func (s *PettyCashService) validateApprover(userID uint) error {
+	var approver models.User
+	if err := s.db.First(&approver, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUnauthorized
+		}
+		return err
+	}
+	if approver.Role != "kepala_sppg" || !approver.IsActive {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// validateCustodian checks that the custodian is an existing active user
+func (s *PettyCashService) validateCustodian(userID uint) error {
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id = ? AND is_active = ?", userID, true).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrInvalidPettyCashCustodian
+	}
+	return nil
+}
+
+// notify sends a petty cash notification; failures are logged and ignored
+func (s *PettyCashService) notify(userID uint, title, message string) {
+	notification := &models.Notification{
+		UserID:  userID,
+		Type:    NotificationTypePettyCash,
+		Title:   title,
+		Message: message,
+		Link:    "/petty-cash",
+	}
+
+	var err error
+	if s.notificationService != nil {
+		err = s.notificationService.CreateNotification(context.Background(), notification)
+	} else {
+		err = s.db.Create(notification).Error
+	}
+	if err != nil {
+		fmt.Printf("Peringatan: gagal mengirim notifikasi kas kecil: %v\n", err)
+	}
+}
+
+// generateExpenseNumber generates an expense number in the format KK-YYYYMMDD-XXXX
+func (s *PettyCashService) generateExpenseNumber(tx *gorm.DB, date time.Time) (string, error) {
+	prefix := fmt.Sprintf("KK-%s-", date.Format("20060102"))
+
+	var count int64
+	if err := tx.Model(&models.PettyCashExpense{}).Where("expense_number LIKE ?", prefix+"%").Count(&count).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%04d", prefix, count+1), nil
+}
+
+// generateReplenishmentNumber generates a replenishment number in the format RKK-YYYYMMDD-XXXX
+func (s *PettyCashService) generateReplenishmentNumber(tx *gorm.DB, date time.Time) (string, error) {
+	prefix := fmt.Sprintf("RKK-%s-", date.Format("20060102"))
+
+	var count int64
+	if err := tx.Model(&models.PettyCashReplenishment{}).Where("replenishment_number LIKE ?", prefix+"%").Count(&count).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%04d", prefix, count+1), nil
+}
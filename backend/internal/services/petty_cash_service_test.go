@@ -0,0 +1,196 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type pettyCashFixture struct {
+	db        *gorm.DB
+	service   *PettyCashService
+	kepala    models.User
+	custodian models.User
+	fund      *models.PettyCashFund
+}
+
+func setupPettyCashTest(t *testing.T) pettyCashFixture {
+	db, err := openTestDB(t, filepath.Join(t.TempDir(), "petty_cash.db"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Ingredient{}, &models.InventoryItem{}, &models.InventoryMovement{},
+		&models.CashFlowEntry{}, &models.Account{}, &models.JournalEntry{}, &models.JournalLine{},
+		&models.AuditTrail{}, &models.Notification{},
+		&models.PettyCashFund{}, &models.PettyCashExpense{}, &models.PettyCashReplenishment{})
+	require.NoError(t, err)
+	require.NoError(t, NewLedgerService(db).SeedDefaultAccounts())
+
+	kepala := models.User{NIK: "PC001", Email: "kepala@sppg.id", PasswordHash: "x", FullName: "Kepala SPPG", Role: "kepala_sppg", IsActive: true}
+	custodian := models.User{NIK: "PC002", Email: "chef@sppg.id", PasswordHash: "x", FullName: "Chef Pasar", Role: "chef", IsActive: true}
+	require.NoError(t, db.Create(&kepala).Error)
+	require.NoError(t, db.Create(&custodian).Error)
+
+	service := NewPettyCashService(db, NewCashFlowService(db), nil)
+	fund := &models.PettyCashFund{Name: "Kas Kecil Dapur", CustodianID: custodian.ID, FloatAmount: models.Rupiah(2000000)}
+	require.NoError(t, service.CreateFund(fund, kepala.ID))
+
+	return pettyCashFixture{db: db, service: service, kepala: kepala, custodian: custodian, fund: fund}
+}
+
+func TestPettyCashService_RecordExpense(t *testing.T) {
+	f := setupPettyCashTest(t)
+	assert.Equal(t, models.Rupiah(2000000), f.fund.Balance)
+
+	ingredient := models.Ingredient{Name: "Bayam", Unit: "kg"}
+	require.NoError(t, f.db.Create(&ingredient).Error)
+
+	date := time.Date(2026, 3, 10, 7, 0, 0, 0, time.Local)
+	vegetables := &models.PettyCashExpense{
+		ExpenseDate:  date,
+		Category:     "lainnya",
+		Description:  "Bayam pasar pagi",
+		Amount:       models.Rupiah(150000),
+		ReceiptPhoto: "/uploads/receipts/receipt_1.jpg",
+		IngredientID: &ingredient.ID,
+		Quantity:     12.5,
+	}
+	require.NoError(t, f.service.RecordExpense(f.fund.ID, vegetables, f.custodian.ID))
+	assert.Equal(t, "KK-20260310-0001", vegetables.ExpenseNumber)
+	assert.Equal(t, "bahan_baku", vegetables.Category)
+
+	// Food purchases are booked as raw material expenses and add stock
+	require.NotNil(t, vegetables.CashFlowEntryID)
+	var entry models.CashFlowEntry
+	require.NoError(t, f.db.First(&entry, *vegetables.CashFlowEntryID).Error)
+	assert.Equal(t, "expense", entry.Type)
+	assert.Equal(t, "bahan_baku", entry.Category)
+	assert.Equal(t, models.Rupiah(150000), entry.Amount)
+	assert.Equal(t, vegetables.ExpenseNumber, entry.Reference)
+
+	require.NotNil(t, vegetables.InventoryMovementID)
+	var movement models.InventoryMovement
+	require.NoError(t, f.db.First(&movement, *vegetables.InventoryMovementID).Error)
+	assert.Equal(t, "in", movement.MovementType)
+	assert.Equal(t, 12.5, movement.Quantity)
+	var item models.InventoryItem
+	require.NoError(t, f.db.Where("ingredient_id = ?", ingredient.ID).First(&item).Error)
+	assert.Equal(t, 12.5, item.Quantity)
+
+	gas := &models.PettyCashExpense{
+		ExpenseDate:  date,
+		Category:     "operasional",
+		Description:  "Isi ulang gas 12 kg",
+		Amount:       models.Rupiah(210000),
+		ReceiptPhoto: "/uploads/receipts/receipt_2.jpg",
+	}
+	require.NoError(t, f.service.RecordExpense(f.fund.ID, gas, f.custodian.ID))
+	assert.Nil(t, gas.InventoryMovementID)
+
+	fund, err := f.service.GetFund(f.fund.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(1640000), fund.Balance)
+
+	// Only the custodian may spend, only with a receipt and within the balance
+	other := &models.PettyCashExpense{Category: "operasional", Description: "Plastik", Amount: models.Rupiah(10000), ReceiptPhoto: "/uploads/receipts/x.jpg"}
+	assert.ErrorIs(t, f.service.RecordExpense(f.fund.ID, other, f.kepala.ID), ErrNotPettyCashCustodian)
+	other.ReceiptPhoto = ""
+	assert.ErrorIs(t, f.service.RecordExpense(f.fund.ID, other, f.custodian.ID), ErrReceiptPhotoRequired)
+	other.ReceiptPhoto = "/uploads/receipts/x.jpg"
+	other.Amount = models.Rupiah(5000000)
+	assert.ErrorIs(t, f.service.RecordExpense(f.fund.ID, other, f.custodian.ID), ErrInsufficientPettyCash)
+	other.Amount = models.Rupiah(10000)
+	other.IngredientID = &ingredient.ID
+	assert.ErrorIs(t, f.service.RecordExpense(f.fund.ID, other, f.custodian.ID), ErrInvalidPettyCashQuantity)
+
+	expenses, err := f.service.GetExpenses(f.fund.ID, nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, expenses, 2)
+}
+
+func TestPettyCashService_Replenishment(t *testing.T) {
+	f := setupPettyCashTest(t)
+
+	_, err := f.service.RequestReplenishment(f.fund.ID, "", f.custodian.ID)
+	assert.ErrorIs(t, err, ErrNothingToReplenish)
+
+	for _, amount := range []models.Money{models.Rupiah(300000), models.Rupiah(450000)} {
+		expense := &models.PettyCashExpense{Category: "operasional", Description: "Gas", Amount: amount, ReceiptPhoto: "/uploads/receipts/gas.jpg"}
+		require.NoError(t, f.service.RecordExpense(f.fund.ID, expense, f.custodian.ID))
+	}
+
+	replenishment, err := f.service.RequestReplenishment(f.fund.ID, "Minggu pertama", f.custodian.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ReplenishmentStatusPending, replenishment.Status)
+	assert.Equal(t, models.Rupiah(750000), replenishment.Amount)
+	assert.Len(t, replenishment.Expenses, 2)
+
+	_, err = f.service.RequestReplenishment(f.fund.ID, "", f.custodian.ID)
+	assert.ErrorIs(t, err, ErrReplenishmentPending)
+
+	// The Kepala SPPG is asked for approval
+	var notifications []models.Notification
+	require.NoError(t, f.db.Where("user_id = ? AND type = ?", f.kepala.ID, NotificationTypePettyCash).Find(&notifications).Error)
+	assert.Len(t, notifications, 1)
+
+	// Only the Kepala SPPG may approve
+	_, err = f.service.ApproveReplenishment(replenishment.ID, f.custodian.ID)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	approved, err := f.service.ApproveReplenishment(replenishment.ID, f.kepala.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ReplenishmentStatusApproved, approved.Status)
+	require.NotNil(t, approved.ApprovedBy)
+	assert.Equal(t, f.kepala.ID, *approved.ApprovedBy)
+
+	fund, err := f.service.GetFund(f.fund.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(2000000), fund.Balance)
+
+	_, err = f.service.ApproveReplenishment(replenishment.ID, f.kepala.ID)
+	assert.ErrorIs(t, err, ErrReplenishmentNotPending)
+
+	// Replenishing does not book the expenses a second time
+	var expenseTotal models.Money
+	require.NoError(t, f.db.Model(&models.CashFlowEntry{}).Where("type = ?", "expense").
+		Select("COALESCE(SUM(amount), 0)").Scan(&expenseTotal).Error)
+	assert.Equal(t, models.Rupiah(750000), expenseTotal)
+}
+
+func TestPettyCashService_RejectReplenishmentReleasesExpenses(t *testing.T) {
+	f := setupPettyCashTest(t)
+
+	expense := &models.PettyCashExpense{Category: "operasional", Description: "Sabun cuci", Amount: models.Rupiah(80000), ReceiptPhoto: "/uploads/receipts/sabun.jpg"}
+	require.NoError(t, f.service.RecordExpense(f.fund.ID, expense, f.custodian.ID))
+
+	replenishment, err := f.service.RequestReplenishment(f.fund.ID, "", f.custodian.ID)
+	require.NoError(t, err)
+
+	_, err = f.service.RejectReplenishment(replenishment.ID, f.kepala.ID, "")
+	assert.ErrorIs(t, err, ErrRejectionReasonRequired)
+
+	rejected, err := f.service.RejectReplenishment(replenishment.ID, f.kepala.ID, "Nota tidak terbaca")
+	require.NoError(t, err)
+	assert.Equal(t, ReplenishmentStatusRejected, rejected.Status)
+	assert.Empty(t, rejected.Expenses)
+
+	fund, err := f.service.GetFund(f.fund.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(1920000), fund.Balance)
+
+	// The expense can be requested again
+	again, err := f.service.RequestReplenishment(f.fund.ID, "Nota diganti", f.custodian.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(80000), again.Amount)
+
+	// Lowering the float below what was spent is refused
+	_, err = f.service.UpdateFund(f.fund.ID, &models.PettyCashFund{Name: fund.Name, CustodianID: f.custodian.ID, FloatAmount: models.Rupiah(50000), IsActive: true}, f.kepala.ID)
+	assert.ErrorIs(t, err, ErrPettyCashFloatBelowSpent)
+	updated, err := f.service.UpdateFund(f.fund.ID, &models.PettyCashFund{Name: fund.Name, CustodianID: f.custodian.ID, FloatAmount: models.Rupiah(2500000), IsActive: true}, f.kepala.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Rupiah(2420000), updated.Balance)
+}
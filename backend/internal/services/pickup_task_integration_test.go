@@ -358,7 +358,7 @@ func TestIntegration_CompletePickupWorkflow_EndToEnd(t *testing.T) {
 	// Step 13: Verify completed task is not in active tasks list
 	t.Log("Step 13: Verifying completed task is not in active tasks list")
 	
-	activeTasks, err := service.GetActivePickupTasks(deliveryDate, nil)
+	activeTasks, err := service.GetActivePickupTasks(deliveryDate, nil, "active")
 	require.NoError(t, err)
 	
 	// Should not include completed task
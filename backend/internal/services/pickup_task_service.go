@@ -22,6 +22,28 @@ func NewPickupTaskService(db *gorm.DB, ats *ActivityTrackerService) *PickupTaskS
 	}
 }
 
+// Reasons UpdateDeliveryRecordStage rejects an update
+const (
+	StageUpdateInvalid  = "invalid"   // the stage and status do not match
+	StageUpdateNotFound = "not_found" // the delivery record does not exist
+	StageUpdateConflict = "conflict"  // the record is not at a stage the update applies to
+)
+
+// StageUpdateError is returned when a delivery record stage update is rejected
+// by validation rather than failing
+type StageUpdateError struct {
+	Reason  string
+	Message string
+}
+
+func (e *StageUpdateError) Error() string {
+	return e.Message
+}
+
+func newStageUpdateError(reason, format string, args ...interface{}) *StageUpdateError {
+	return &StageUpdateError{Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
 // EligibleOrderResponse represents a delivery record eligible for pickup
 type EligibleOrderResponse struct {
 	DeliveryRecordID uint      `json:"delivery_record_id"`
@@ -157,9 +179,8 @@ func (s *PickupTaskService) GetActivePickupTasks(date time.Time, driverID *uint,
 
 	// Filter by date if provided (compare only date part, not time)
 	if !date.IsZero() {
-		// Format date to YYYY-MM-DD for comparison
-		dateStr := date.Format("2006-01-02")
-		query = query.Where("DATE(task_date) = ?", dateStr)
+		dayStart, dayEnd := localDayRange(date)
+		query = query.Where("task_date >= ? AND task_date < ?", dayStart, dayEnd)
 	}
 
 	// Filter by driver_id if provided
@@ -459,6 +480,13 @@ func (s *PickupTaskService) CancelPickupTask(id uint, userID uint) error {
 // Calls ActivityTrackerService to transition to new stage, updates delivery record current_stage and current_status
 // Checks if all delivery records in pickup task are at stage 13, and if so, automatically updates pickup task status to 'completed'
 func (s *PickupTaskService) UpdateDeliveryRecordStage(pickupTaskID uint, deliveryRecordID uint, stage int, status string, userID uint, omprengReceived *int, omprengDifferenceReason string) (*models.DeliveryRecord, error) {
+	return s.UpdateDeliveryRecordStageAt(pickupTaskID, deliveryRecordID, stage, status, userID, omprengReceived, omprengDifferenceReason, time.Time{})
+}
+
+// UpdateDeliveryRecordStageAt updates the stage like UpdateDeliveryRecordStage,
+// recording occurredAt as the transition time. Offline clients pass the time
+// the action really happened; a zero occurredAt means now.
+func (s *PickupTaskService) UpdateDeliveryRecordStageAt(pickupTaskID uint, deliveryRecordID uint, stage int, status string, userID uint, omprengReceived *int, omprengDifferenceReason string, occurredAt time.Time) (*models.DeliveryRecord, error) {
 	// Define stage-status mapping
 	stageStatusMap := map[int]string{
 		11: "driver_tiba_di_lokasi_pengambilan",
@@ -469,10 +497,10 @@ func (s *PickupTaskService) UpdateDeliveryRecordStage(pickupTaskID uint, deliver
 	// Validate stage-status mapping
 	expectedStatus, validStage := stageStatusMap[stage]
 	if !validStage {
-		return nil, fmt.Errorf("invalid stage: %d. Must be 11, 12, or 13", stage)
+		return nil, newStageUpdateError(StageUpdateInvalid, "invalid stage: %d. Must be 11, 12, or 13", stage)
 	}
 	if status != expectedStatus {
-		return nil, fmt.Errorf("invalid status for stage %d: expected '%s', got '%s'", stage, expectedStatus, status)
+		return nil, newStageUpdateError(StageUpdateInvalid, "invalid status for stage %d: expected '%s', got '%s'", stage, expectedStatus, status)
 	}
 
 	// Begin database transaction
@@ -488,7 +516,7 @@ func (s *PickupTaskService) UpdateDeliveryRecordStage(pickupTaskID uint, deliver
 	if err := tx.Where("id = ?", deliveryRecordID).First(&deliveryRecord).Error; err != nil {
 		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("delivery record %d not found", deliveryRecordID)
+			return nil, newStageUpdateError(StageUpdateNotFound, "delivery record %d not found", deliveryRecordID)
 		}
 		return nil, fmt.Errorf("failed to fetch delivery record: %w", err)
 	}
@@ -496,13 +524,13 @@ func (s *PickupTaskService) UpdateDeliveryRecordStage(pickupTaskID uint, deliver
 	// Validate delivery record belongs to specified pickup task
 	if deliveryRecord.PickupTaskID == nil || *deliveryRecord.PickupTaskID != pickupTaskID {
 		tx.Rollback()
-		return nil, fmt.Errorf("delivery record %d is not part of pickup task %d", deliveryRecordID, pickupTaskID)
+		return nil, newStageUpdateError(StageUpdateConflict, "delivery record %d is not part of pickup task %d", deliveryRecordID, pickupTaskID)
 	}
 
 	// Validate current stage is between 10 and 12 (stage 13 is final)
 	if deliveryRecord.CurrentStage < 10 || deliveryRecord.CurrentStage > 12 {
 		tx.Rollback()
-		return nil, fmt.Errorf("cannot update stage: current stage is %d (must be between 10 and 12)", deliveryRecord.CurrentStage)
+		return nil, newStageUpdateError(StageUpdateConflict, "cannot update stage: current stage is %d (must be between 10 and 12)", deliveryRecord.CurrentStage)
 	}
 
 	// Validate new stage is exactly current_stage + 1 (no skipping)
@@ -510,17 +538,23 @@ func (s *PickupTaskService) UpdateDeliveryRecordStage(pickupTaskID uint, deliver
 	if stage != expectedNextStage {
 		tx.Rollback()
 		allowedNextStatus := stageStatusMap[expectedNextStage]
-		return nil, fmt.Errorf("cannot skip stages. Current stage is %d, attempted stage is %d. Allowed next stage: %d (%s)",
+		return nil, newStageUpdateError(StageUpdateConflict, "cannot skip stages. Current stage is %d, attempted stage is %d. Allowed next stage: %d (%s)",
 			deliveryRecord.CurrentStage, stage, expectedNextStage, allowedNextStatus)
 	}
 
+	transitionedAt, err := offlineEventTime(tx, deliveryRecordID, occurredAt, time.Now())
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to resolve transition time: %w", err)
+	}
+
 	// Create status transition record
 	transition := models.StatusTransition{
 		DeliveryRecordID: deliveryRecordID,
 		FromStatus:       deliveryRecord.CurrentStatus,
 		ToStatus:         status,
 		Stage:            stage,
-		TransitionedAt:   time.Now(),
+		TransitionedAt:   transitionedAt,
 		TransitionedBy:   userID,
 		Notes:            fmt.Sprintf("Stage updated via pickup task %d", pickupTaskID),
 	}
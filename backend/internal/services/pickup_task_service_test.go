@@ -7,13 +7,12 @@ import (
 	"github.com/erp-sppg/backend/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 // setupPickupTaskTestDB creates an in-memory SQLite database for testing
 func setupPickupTaskTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
 	require.NoError(t, err, "Failed to open test database")
 
 	// Auto-migrate all required models
@@ -120,7 +119,7 @@ func TestUpdateDeliveryRecordStage_Success(t *testing.T) {
 	service := NewPickupTaskService(db, ats)
 
 	// Update stage from 10 to 11
-	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 11, "driver_tiba_di_lokasi_pengambilan")
+	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 11, "driver_tiba_di_lokasi_pengambilan", driver.ID, nil, "")
 
 	// Assertions
 	require.NoError(t, err, "Expected no error")
@@ -152,17 +151,17 @@ func TestUpdateDeliveryRecordStage_SequentialTransitions(t *testing.T) {
 	service := NewPickupTaskService(db, ats)
 
 	// Transition 10 -> 11
-	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 11, "driver_tiba_di_lokasi_pengambilan")
+	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 11, "driver_tiba_di_lokasi_pengambilan", driver.ID, nil, "")
 	require.NoError(t, err)
 	assert.Equal(t, 11, result.CurrentStage)
 
 	// Transition 11 -> 12
-	result, err = service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 12, "driver_kembali_ke_sppg")
+	result, err = service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 12, "driver_kembali_ke_sppg", driver.ID, nil, "")
 	require.NoError(t, err)
 	assert.Equal(t, 12, result.CurrentStage)
 
 	// Transition 12 -> 13
-	result, err = service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 13, "driver_tiba_di_sppg")
+	result, err = service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 13, "driver_tiba_di_sppg", driver.ID, nil, "")
 	require.NoError(t, err)
 	assert.Equal(t, 13, result.CurrentStage)
 
@@ -189,7 +188,7 @@ func TestUpdateDeliveryRecordStage_SkipStage(t *testing.T) {
 	service := NewPickupTaskService(db, ats)
 
 	// Attempt to skip from stage 10 to 12
-	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 12, "driver_kembali_ke_sppg")
+	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 12, "driver_kembali_ke_sppg", driver.ID, nil, "")
 
 	// Assertions
 	require.Error(t, err, "Expected error when skipping stages")
@@ -221,7 +220,7 @@ func TestUpdateDeliveryRecordStage_InvalidStageStatusMapping(t *testing.T) {
 	service := NewPickupTaskService(db, ats)
 
 	// Attempt to use wrong status for stage 11
-	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 11, "driver_kembali_ke_sppg")
+	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 11, "driver_kembali_ke_sppg", driver.ID, nil, "")
 
 	// Assertions
 	require.Error(t, err, "Expected error for invalid stage-status mapping")
@@ -247,7 +246,7 @@ func TestUpdateDeliveryRecordStage_DeliveryRecordNotInTask(t *testing.T) {
 	service := NewPickupTaskService(db, ats)
 
 	// Attempt to update delivery record 2 which is not in the pickup task
-	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord2.ID, 11, "driver_tiba_di_lokasi_pengambilan")
+	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord2.ID, 11, "driver_tiba_di_lokasi_pengambilan", driver.ID, nil, "")
 
 	// Assertions
 	require.Error(t, err, "Expected error when delivery record not in task")
@@ -272,7 +271,7 @@ func TestUpdateDeliveryRecordStage_FinalStage(t *testing.T) {
 
 	// Attempt to update from stage 13 (should fail because stage must be between 10 and 12)
 	// We'll try to transition to stage 14 which is invalid
-	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 14, "some_status")
+	result, err := service.UpdateDeliveryRecordStage(pickupTask.ID, deliveryRecord.ID, 14, "some_status", driver.ID, nil, "")
 
 	// Assertions
 	require.Error(t, err, "Expected error when trying to update final stage")
@@ -308,11 +307,11 @@ func TestUpdateDeliveryRecordStage_MultipleRecordsAutoComplete(t *testing.T) {
 
 	// Transition all records to stage 13
 	// Record 1: 10 -> 11 -> 12 -> 13
-	_, err := service.UpdateDeliveryRecordStage(pickupTask.ID, dr1.ID, 11, "driver_tiba_di_lokasi_pengambilan")
+	_, err := service.UpdateDeliveryRecordStage(pickupTask.ID, dr1.ID, 11, "driver_tiba_di_lokasi_pengambilan", driver.ID, nil, "")
 	require.NoError(t, err)
-	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr1.ID, 12, "driver_kembali_ke_sppg")
+	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr1.ID, 12, "driver_kembali_ke_sppg", driver.ID, nil, "")
 	require.NoError(t, err)
-	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr1.ID, 13, "driver_tiba_di_sppg")
+	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr1.ID, 13, "driver_tiba_di_sppg", driver.ID, nil, "")
 	require.NoError(t, err)
 
 	// Verify pickup task is still active (not all records at stage 13)
@@ -322,11 +321,11 @@ func TestUpdateDeliveryRecordStage_MultipleRecordsAutoComplete(t *testing.T) {
 	assert.Equal(t, "active", task1.Status, "Expected pickup task to still be active")
 
 	// Record 2: 10 -> 11 -> 12 -> 13
-	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr2.ID, 11, "driver_tiba_di_lokasi_pengambilan")
+	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr2.ID, 11, "driver_tiba_di_lokasi_pengambilan", driver.ID, nil, "")
 	require.NoError(t, err)
-	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr2.ID, 12, "driver_kembali_ke_sppg")
+	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr2.ID, 12, "driver_kembali_ke_sppg", driver.ID, nil, "")
 	require.NoError(t, err)
-	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr2.ID, 13, "driver_tiba_di_sppg")
+	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr2.ID, 13, "driver_tiba_di_sppg", driver.ID, nil, "")
 	require.NoError(t, err)
 
 	// Verify pickup task is still active
@@ -336,11 +335,11 @@ func TestUpdateDeliveryRecordStage_MultipleRecordsAutoComplete(t *testing.T) {
 	assert.Equal(t, "active", task2.Status, "Expected pickup task to still be active")
 
 	// Record 3: 10 -> 11 -> 12 -> 13 (this should trigger auto-completion)
-	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr3.ID, 11, "driver_tiba_di_lokasi_pengambilan")
+	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr3.ID, 11, "driver_tiba_di_lokasi_pengambilan", driver.ID, nil, "")
 	require.NoError(t, err)
-	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr3.ID, 12, "driver_kembali_ke_sppg")
+	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr3.ID, 12, "driver_kembali_ke_sppg", driver.ID, nil, "")
 	require.NoError(t, err)
-	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr3.ID, 13, "driver_tiba_di_sppg")
+	_, err = service.UpdateDeliveryRecordStage(pickupTask.ID, dr3.ID, 13, "driver_tiba_di_sppg", driver.ID, nil, "")
 	require.NoError(t, err)
 
 	// Verify pickup task is now completed
@@ -370,7 +369,7 @@ func TestUpdateDeliveryRecordStage_IndependentStageTracking(t *testing.T) {
 	service := NewPickupTaskService(db, ats)
 
 	// Update only record 1 to stage 11
-	_, err := service.UpdateDeliveryRecordStage(pickupTask.ID, dr1.ID, 11, "driver_tiba_di_lokasi_pengambilan")
+	_, err := service.UpdateDeliveryRecordStage(pickupTask.ID, dr1.ID, 11, "driver_tiba_di_lokasi_pengambilan", driver.ID, nil, "")
 	require.NoError(t, err)
 
 	// Verify record 1 is at stage 11
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -49,7 +50,7 @@ func (s *PurchaseOrderService) CreatePurchaseOrder(po *models.PurchaseOrder, ite
 	}
 
 	// Calculate total amount and validate items
-	var totalAmount float64
+	var totalAmount models.Money
 	for i := range items {
 		// Validate ingredient exists
 		var ingredient models.Ingredient
@@ -61,7 +62,7 @@ func (s *PurchaseOrderService) CreatePurchaseOrder(po *models.PurchaseOrder, ite
 		}
 
 		// Calculate subtotal
-		items[i].Subtotal = items[i].Quantity * items[i].UnitPrice
+		items[i].Subtotal = items[i].UnitPrice.Mul(items[i].Quantity)
 		totalAmount += items[i].Subtotal
 	}
 
@@ -163,7 +164,7 @@ func (s *PurchaseOrderService) UpdatePurchaseOrder(id uint, updates *models.Purc
 	}
 
 	// Calculate total amount
-	var totalAmount float64
+	var totalAmount models.Money
 	for i := range items {
 		// Validate ingredient exists
 		var ingredient models.Ingredient
@@ -175,7 +176,7 @@ func (s *PurchaseOrderService) UpdatePurchaseOrder(id uint, updates *models.Purc
 		}
 
 		// Calculate subtotal
-		items[i].Subtotal = items[i].Quantity * items[i].UnitPrice
+		items[i].Subtotal = items[i].UnitPrice.Mul(items[i].Quantity)
 		totalAmount += items[i].Subtotal
 	}
 
@@ -231,14 +232,26 @@ func (s *PurchaseOrderService) ApprovePurchaseOrder(id uint, approverID uint) er
 		return ErrInvalidPOStatus
 	}
 
-	// Update status to approved
+	// Update status to approved; partner webhooks are saved with the change
 	now := time.Now()
-	return s.db.Model(&models.PurchaseOrder{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"status":      "approved",
-		"approved_by": approverID,
-		"approved_at": now,
-		"updated_at":  now,
-	}).Error
+	event := events.PurchaseOrderApproved{PurchaseOrderID: id, SupplierID: po.SupplierID, ApprovedBy: approverID}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PurchaseOrder{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":      "approved",
+			"approved_by": approverID,
+			"approved_at": now,
+			"updated_at":  now,
+		}).Error; err != nil {
+			return err
+		}
+		return events.PublishTx(tx, event)
+	})
+	if err != nil {
+		return err
+	}
+
+	events.Notify(event)
+	return nil
 }
 
 // CancelPurchaseOrder cancels a purchase order
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/realtime"
+)
+
+// RealtimeSyncService handles real-time data synchronization with connected
+// clients through the configured realtime backend (Firebase or the built-in hub)
+type RealtimeSyncService struct {
+	publisher realtime.RealtimePublisher
+}
+
+// NewRealtimeSyncService creates a new realtime sync service
+func NewRealtimeSyncService(publisher realtime.RealtimePublisher) *RealtimeSyncService {
+	return &RealtimeSyncService{
+		publisher: publisher,
+	}
+}
+
+// PushUpdate pushes data to a realtime path
+func (s *RealtimeSyncService) PushUpdate(ctx context.Context, path string, data interface{}) error {
+	if err := s.publisher.Set(ctx, path, data); err != nil {
+		return fmt.Errorf("gagal mengirim update ke path %s: %w", path, err)
+	}
+	return nil
+}
+
+// PushUpdateWithTimestamp pushes data with an updated_at timestamp
+func (s *RealtimeSyncService) PushUpdateWithTimestamp(ctx context.Context, path string, data interface{}) error {
+	// Add timestamp to data
+	dataMap := make(map[string]interface{})
+
+	// Convert data to map
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("gagal mengkonversi data: %w", err)
+	}
+
+	if err := json.Unmarshal(jsonData, &dataMap); err != nil {
+		return fmt.Errorf("gagal mengkonversi data ke map: %w", err)
+	}
+
+	// Add timestamp
+	dataMap["updated_at"] = time.Now().Unix()
+
+	return s.PushUpdate(ctx, path, dataMap)
+}
+
+// DeletePath removes data from a realtime path
+func (s *RealtimeSyncService) DeletePath(ctx context.Context, path string) error {
+	if err := s.publisher.Delete(ctx, path); err != nil {
+		return fmt.Errorf("gagal menghapus data dari path %s: %w", path, err)
+	}
+	return nil
+}
+
+// GetData retrieves data from a realtime path
+func (s *RealtimeSyncService) GetData(ctx context.Context, path string, result interface{}) error {
+	if err := s.publisher.Get(ctx, path, result); err != nil {
+		return fmt.Errorf("gagal mengambil data dari path %s: %w", path, err)
+	}
+	return nil
+}
+
+// UpdateField updates a specific field in a realtime path
+func (s *RealtimeSyncService) UpdateField(ctx context.Context, path string, updates map[string]interface{}) error {
+	if err := s.publisher.Update(ctx, path, updates); err != nil {
+		return fmt.Errorf("gagal mengupdate field di path %s: %w", path, err)
+	}
+	return nil
+}
+
+// PushKDSCookingUpdate pushes cooking status update to connected clients
+func (s *RealtimeSyncService) PushKDSCookingUpdate(ctx context.Context, date string, recipeID uint, data interface{}) error {
+	path := fmt.Sprintf("/kds/cooking/%s/%d", date, recipeID)
+	return s.PushUpdateWithTimestamp(ctx, path, data)
+}
+
+// PushKDSPackingUpdate pushes packing status update to connected clients
+func (s *RealtimeSyncService) PushKDSPackingUpdate(ctx context.Context, date string, schoolID uint, data interface{}) error {
+	path := fmt.Sprintf("/kds/packing/%s/%d", date, schoolID)
+	return s.PushUpdateWithTimestamp(ctx, path, data)
+}
+
+// PushDashboardUpdate pushes dashboard data to connected clients
+func (s *RealtimeSyncService) PushDashboardUpdate(ctx context.Context, dashboardType string, data interface{}) error {
+	path := fmt.Sprintf("/dashboard/%s", dashboardType)
+	return s.PushUpdateWithTimestamp(ctx, path, data)
+}
+
+// PushInventoryUpdate pushes inventory update to connected clients
+func (s *RealtimeSyncService) PushInventoryUpdate(ctx context.Context, ingredientID uint, data interface{}) error {
+	path := fmt.Sprintf("/inventory/%d", ingredientID)
+	return s.PushUpdateWithTimestamp(ctx, path, data)
+}
+
+// PushDeliveryUpdate pushes delivery status update to connected clients
+func (s *RealtimeSyncService) PushDeliveryUpdate(ctx context.Context, taskID uint, data interface{}) error {
+	path := fmt.Sprintf("/delivery/%d", taskID)
+	return s.PushUpdateWithTimestamp(ctx, path, data)
+}
+
+// HandleConflict resolves conflicts by using server data (server wins strategy)
+func (s *RealtimeSyncService) HandleConflict(ctx context.Context, path string, serverData interface{}) error {
+	// Server data always wins in conflict resolution
+	return s.PushUpdateWithTimestamp(ctx, path, serverData)
+}
+
+// ClearKDSData clears all KDS-related data from the realtime backend
+func (s *RealtimeSyncService) ClearKDSData(ctx context.Context) error {
+	// List of paths to clear
+	paths := []string{
+		"/kds/cooking",
+		"/kds/packing",
+		"/delivery_tasks",
+		"/delivery_records",
+		"/monitoring",
+		"/activity_tracker",
+	}
+
+	for _, path := range paths {
+		if err := s.DeletePath(ctx, path); err != nil {
+			// Log error but continue with other paths
+			fmt.Printf("Peringatan: gagal menghapus realtime path %s: %v\n", path, err)
+		}
+	}
+
+	return nil
+}
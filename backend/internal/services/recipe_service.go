@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -68,7 +69,7 @@ func (s *RecipeService) CreateRecipe(recipe *models.Recipe, items []models.Recip
 	}
 
 	// Create recipe in transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// Create recipe
 		if err := tx.Create(recipe).Error; err != nil {
 			return err
@@ -84,6 +85,12 @@ func (s *RecipeService) CreateRecipe(recipe *models.Recipe, items []models.Recip
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	events.Publish(events.RecipeChanged{RecipeID: recipe.ID})
+	return nil
 }
 
 // GetRecipeByID retrieves a recipe by ID with semi-finished goods items
@@ -146,7 +153,7 @@ func (s *RecipeService) UpdateRecipe(id uint, updates *models.Recipe, items []mo
 	}
 
 	// Update recipe in transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// Save current version to history before updating
 		recipeVersion := &models.RecipeVersion{
 			RecipeID:      existingRecipe.ID,
@@ -199,6 +206,12 @@ func (s *RecipeService) UpdateRecipe(id uint, updates *models.Recipe, items []mo
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	events.Publish(events.RecipeChanged{RecipeID: id})
+	return nil
 }
 
 // DeleteRecipe soft deletes a recipe (sets is_active to false)
@@ -210,6 +223,8 @@ func (s *RecipeService) DeleteRecipe(id uint) error {
 	if result.RowsAffected == 0 {
 		return ErrRecipeNotFound
 	}
+
+	events.Publish(events.RecipeChanged{RecipeID: id})
 	return nil
 }
 
@@ -370,7 +385,12 @@ func (s *RecipeService) CreateIngredient(ingredient *models.Ingredient) error {
 		}
 		ingredient.Code = code
 	}
-	return s.db.Create(ingredient).Error
+	if err := s.db.Create(ingredient).Error; err != nil {
+		return err
+	}
+
+	events.Publish(events.RecipeChanged{})
+	return nil
 }
 
 // GenerateIngredientCode generates a unique code for ingredient (B-XXXX format)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -219,7 +220,8 @@ func (s *SemiFinishedService) calculateRecipeNutrition(ingredients []models.Semi
 
 // ProduceSemiFinishedGoods produces semi-finished goods from raw ingredients
 func (s *SemiFinishedService) ProduceSemiFinishedGoods(goodsID uint, quantity float64, userID uint, notes string) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var consumedIDs []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Get the semi-finished goods with recipe
 		var goods models.SemiFinishedGoods
 		if err := tx.Preload("Recipe.Ingredients").First(&goods, goodsID).Error; err != nil {
@@ -267,6 +269,7 @@ func (s *SemiFinishedService) ProduceSemiFinishedGoods(goodsID uint, quantity fl
 			if err := tx.Create(&movement).Error; err != nil {
 				return err
 			}
+			consumedIDs = append(consumedIDs, recipeIng.IngredientID)
 		}
 
 		// Add to semi-finished inventory
@@ -318,8 +321,19 @@ func (s *SemiFinishedService) ProduceSemiFinishedGoods(goodsID uint, quantity fl
 			return err
 		}
 
+		if len(consumedIDs) > 0 {
+			return events.PublishTx(tx, events.StockChanged{IngredientIDs: consumedIDs, Reason: "out"})
+		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if len(consumedIDs) > 0 {
+		events.Notify(events.StockChanged{IngredientIDs: consumedIDs, Reason: "out"})
+	}
+	return nil
 }
 
 // GetSemiFinishedInventory retrieves all semi-finished inventory
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSessionNotFound     = errors.New("sesi tidak ditemukan")
+	ErrSessionRevoked      = errors.New("sesi sudah dicabut")
+	ErrSessionExpired      = errors.New("sesi sudah berakhir")
+	ErrInvalidRefreshToken = errors.New("refresh token tidak valid")
+	ErrRefreshTokenReused  = errors.New("refresh token sudah pernah digunakan")
+	ErrRefreshTokenExpired = errors.New("refresh token sudah kadaluarsa")
+)
+
+// Session revocation reasons
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedByUser         = "revoked_by_user"
+	SessionRevokedIdle           = "idle_timeout"
+	SessionRevokedTokenReuse     = "refresh_token_reuse"
+	SessionRevokedPasswordChange = "password_change"
+	SessionRevokedPasswordReset  = "password_reset"
+	SessionRevokedDeactivated    = "user_deactivated"
+)
+
+// sessionTouchInterval limits how often LastActivityAt is written per session
+const sessionTouchInterval = time.Minute
+
+// DeviceInfo describes the client that opens a session
+type DeviceInfo struct {
+	UserAgent  string
+	DeviceName string
+	IPAddress  string
+}
+
+// SessionService manages database-backed login sessions and refresh tokens
+type SessionService struct {
+	db              *gorm.DB
+	refreshTokenTTL time.Duration
+	idleTimeout     time.Duration
+}
+
+// NewSessionService creates a new session service.
+// A zero idleTimeout disables the inactivity check.
+func NewSessionService(db *gorm.DB, refreshTokenTTL, idleTimeout time.Duration) *SessionService {
+	return &SessionService{
+		db:              db,
+		refreshTokenTTL: refreshTokenTTL,
+		idleTimeout:     idleTimeout,
+	}
+}
+
+// CreateSession opens a new session for the user and returns it with its first refresh token
+func (s *SessionService) CreateSession(userID uint, device DeviceInfo) (*models.UserSession, string, error) {
+	now := time.Now()
+	session := &models.UserSession{
+		SessionID:      uuid.New().String(),
+		UserID:         userID,
+		UserAgent:      truncateString(device.UserAgent, 255),
+		DeviceName:     truncateString(device.DeviceName, 100),
+		IPAddress:      device.IPAddress,
+		LastActivityAt: now,
+		ExpiresAt:      now.Add(s.refreshTokenTTL),
+	}
+
+	var refreshToken string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		var err error
+		refreshToken, err = s.issueRefreshToken(tx, session, now)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return session, refreshToken, nil
+}
+
+// RotateRefreshToken consumes a refresh token and returns its session with a new token.
+// Presenting a token that was already used revokes the whole session.
+func (s *SessionService) RotateRefreshToken(rawToken string) (*models.UserSession, string, error) {
+	now := time.Now()
+
+	var stored models.RefreshToken
+	if err := s.db.Preload("UserSession").Where("token_hash = ?", hashRefreshToken(rawToken)).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrInvalidRefreshToken
+		}
+		return nil, "", err
+	}
+	session := stored.UserSession
+
+	if stored.UsedAt != nil {
+		// Someone replayed an old token: assume it leaked and kill the session
+		if err := s.RevokeSession(session.SessionID, SessionRevokedTokenReuse); err != nil && err != ErrSessionRevoked {
+			return nil, "", err
+		}
+		NewAuditTrailService(s.db).RecordAction(session.UserID, "refresh_token_reuse", "user_session", session.SessionID, nil, nil, "")
+		return nil, "", ErrRefreshTokenReused
+	}
+	if session.RevokedAt != nil {
+		return nil, "", ErrSessionRevoked
+	}
+	if now.After(stored.ExpiresAt) || now.After(session.ExpiresAt) {
+		return nil, "", ErrRefreshTokenExpired
+	}
+	if s.isIdle(&session, now) {
+		s.RevokeSession(session.SessionID, SessionRevokedIdle)
+		return nil, "", ErrSessionExpired
+	}
+
+	var newToken string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Guard against two concurrent refreshes with the same token
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", stored.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		if err := tx.Model(&models.UserSession{}).Where("id = ?", session.ID).
+			Update("last_activity_at", now).Error; err != nil {
+			return err
+		}
+		session.LastActivityAt = now
+
+		var err error
+		newToken, err = s.issueRefreshToken(tx, &session, now)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &session, newToken, nil
+}
+
+// ValidateSession checks that the session exists, belongs to the user and is
+// neither revoked nor idle, and records activity on it
+func (s *SessionService) ValidateSession(sessionID string, userID uint) error {
+	var session models.UserSession
+	if err := s.db.Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	if session.RevokedAt != nil {
+		return ErrSessionRevoked
+	}
+
+	now := time.Now()
+	if now.After(session.ExpiresAt) {
+		return ErrSessionExpired
+	}
+	if s.isIdle(&session, now) {
+		s.RevokeSession(sessionID, SessionRevokedIdle)
+		return ErrSessionExpired
+	}
+
+	if now.Sub(session.LastActivityAt) >= sessionTouchInterval {
+		s.db.Model(&models.UserSession{}).Where("id = ?", session.ID).Update("last_activity_at", now)
+	}
+
+	return nil
+}
+
+// RevokeSession revokes a single session
+func (s *SessionService) RevokeSession(sessionID, reason string) error {
+	result := s.db.Model(&models.UserSession{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		s.db.Model(&models.UserSession{}).Where("session_id = ?", sessionID).Count(&count)
+		if count == 0 {
+			return ErrSessionNotFound
+		}
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// RevokeUserSession revokes one of the user's own sessions by its session ID
+func (s *SessionService) RevokeUserSession(userID uint, sessionID, reason string) error {
+	var count int64
+	if err := s.db.Model(&models.UserSession{}).
+		Where("session_id = ? AND user_id = ?", sessionID, userID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrSessionNotFound
+	}
+	return s.RevokeSession(sessionID, reason)
+}
+
+// RevokeAllUserSessions revokes every active session of a user except the
+// optional keepSessionID and returns the number of revoked sessions
+func (s *SessionService) RevokeAllUserSessions(userID uint, reason, keepSessionID string) (int64, error) {
+	query := s.db.Model(&models.UserSession{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if keepSessionID != "" {
+		query = query.Where("session_id <> ?", keepSessionID)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"revoked_at":     time.Now(),
+		"revoked_reason": reason,
+	})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		NewAuditTrailService(s.db).RecordAction(userID, "revoke_sessions", "user", strconv.FormatUint(uint64(userID), 10), nil, map[string]interface{}{
+			"reason":  reason,
+			"revoked": result.RowsAffected,
+		}, "")
+	}
+
+	return result.RowsAffected, nil
+}
+
+// GetActiveSessions lists the user's sessions that are still usable, newest activity first
+func (s *SessionService) GetActiveSessions(userID uint) ([]models.UserSession, error) {
+	now := time.Now()
+	query := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now)
+	if s.idleTimeout > 0 {
+		query = query.Where("last_activity_at > ?", now.Add(-s.idleTimeout))
+	}
+
+	var sessions []models.UserSession
+	if err := query.Order("last_activity_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// CleanupExpiredSessions deletes sessions and refresh tokens that can no longer be used
+func (s *SessionService) CleanupExpiredSessions(olderThan time.Time) (int64, error) {
+	var ids []uint
+	if err := s.db.Model(&models.UserSession{}).
+		Where("expires_at < ? OR (revoked_at IS NOT NULL AND revoked_at < ?)", olderThan, olderThan).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_session_id IN ?", ids).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Delete(&models.UserSession{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+// issueRefreshToken creates and stores a new refresh token for the session
+func (s *SessionService) issueRefreshToken(tx *gorm.DB, session *models.UserSession, now time.Time) (string, error) {
+	rawToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	token := &models.RefreshToken{
+		UserSessionID: session.ID,
+		TokenHash:     hashRefreshToken(rawToken),
+		ExpiresAt:     session.ExpiresAt,
+		CreatedAt:     now,
+	}
+	if err := tx.Create(token).Error; err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// isIdle reports whether the session exceeded the inactivity timeout
+func (s *SessionService) isIdle(session *models.UserSession, now time.Time) bool {
+	return s.idleTimeout > 0 && now.Sub(session.LastActivityAt) > s.idleTimeout
+}
+
+// hashRefreshToken returns the hex SHA-256 digest stored for a refresh token
+func hashRefreshToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncateString shortens s to at most max bytes
+func truncateString(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
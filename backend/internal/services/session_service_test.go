@@ -0,0 +1,145 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionService_RefreshTokenRotation(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+
+	_, tokens, err := authService.LoginWithDevice(user.NIK, "Rahasia123", DeviceInfo{UserAgent: "test-agent", DeviceName: "Tablet Dapur"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.Equal(t, int(DefaultAccessTokenTTL.Seconds()), tokens.ExpiresIn)
+
+	claims, err := authService.ValidateToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, tokens.SessionID, claims.SessionID)
+
+	refreshed, err := authService.RefreshToken(tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, tokens.SessionID, refreshed.SessionID)
+	assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+
+	// The new refresh token keeps working
+	_, err = authService.RefreshToken(refreshed.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = authService.RefreshToken("tidak-ada")
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestSessionService_ReuseRevokesSession(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+
+	_, tokens, err := authService.LoginWithDevice(user.NIK, "Rahasia123", DeviceInfo{})
+	require.NoError(t, err)
+
+	refreshed, err := authService.RefreshToken(tokens.RefreshToken)
+	require.NoError(t, err)
+
+	// Replaying the consumed token kills the session, including the newer token
+	_, err = authService.RefreshToken(tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	_, err = authService.RefreshToken(refreshed.RefreshToken)
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+
+	err = authService.Sessions().ValidateSession(tokens.SessionID, user.ID)
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+
+	var audit models.AuditTrail
+	require.NoError(t, db.Where("action = ? AND user_id = ?", "refresh_token_reuse", user.ID).First(&audit).Error)
+}
+
+func TestSessionService_IdleTimeout(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret").ConfigureSessions(time.Minute, time.Hour, 30*time.Minute)
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+
+	_, tokens, err := authService.LoginWithDevice(user.NIK, "Rahasia123", DeviceInfo{})
+	require.NoError(t, err)
+	require.NoError(t, authService.Sessions().ValidateSession(tokens.SessionID, user.ID))
+
+	require.NoError(t, db.Model(&models.UserSession{}).Where("session_id = ?", tokens.SessionID).
+		Update("last_activity_at", time.Now().Add(-31*time.Minute)).Error)
+
+	err = authService.Sessions().ValidateSession(tokens.SessionID, user.ID)
+	assert.ErrorIs(t, err, ErrSessionExpired)
+
+	_, err = authService.RefreshToken(tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+}
+
+func TestSessionService_ListAndRevokeOwnSessions(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+
+	_, laptop, err := authService.LoginWithDevice(user.NIK, "Rahasia123", DeviceInfo{DeviceName: "Laptop"})
+	require.NoError(t, err)
+	_, phone, err := authService.LoginWithDevice(user.NIK, "Rahasia123", DeviceInfo{DeviceName: "HP"})
+	require.NoError(t, err)
+
+	sessions, err := authService.Sessions().GetActiveSessions(user.ID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	// Another user's ID cannot revoke the session
+	err = authService.Sessions().RevokeUserSession(user.ID+1, phone.SessionID, SessionRevokedByUser)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	require.NoError(t, authService.Sessions().RevokeUserSession(user.ID, phone.SessionID, SessionRevokedByUser))
+	assert.ErrorIs(t, authService.Sessions().ValidateSession(phone.SessionID, user.ID), ErrSessionRevoked)
+	assert.NoError(t, authService.Sessions().ValidateSession(laptop.SessionID, user.ID))
+
+	require.NoError(t, authService.Logout(laptop.SessionID))
+	sessions, err = authService.Sessions().GetActiveSessions(user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestSessionService_PasswordChangeAndDeactivationRevokeSessions(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	employeeService := NewEmployeeService(db, authService)
+
+	employee := &models.Employee{
+		NIK:      "3201010101010003",
+		FullName: "Karyawan Sesi",
+		Email:    "sesi@sppg.test",
+		Position: "Staff",
+		JoinDate: time.Now(),
+		IsActive: true,
+	}
+	_, password, err := employeeService.CreateEmployee(employee, "chef")
+	require.NoError(t, err)
+
+	user, current, err := authService.LoginWithDevice(employee.NIK, password, DeviceInfo{})
+	require.NoError(t, err)
+	_, other, err := authService.LoginWithDevice(employee.NIK, password, DeviceInfo{})
+	require.NoError(t, err)
+
+	newToken, err := authService.ChangePassword(user.ID, current.SessionID, password, "PasswordBaru1")
+	require.NoError(t, err)
+	claims, err := authService.ValidateToken(newToken)
+	require.NoError(t, err)
+	assert.Equal(t, current.SessionID, claims.SessionID)
+
+	assert.NoError(t, authService.Sessions().ValidateSession(current.SessionID, user.ID))
+	assert.ErrorIs(t, authService.Sessions().ValidateSession(other.SessionID, user.ID), ErrSessionRevoked)
+
+	require.NoError(t, employeeService.DeactivateEmployee(employee.ID))
+	assert.ErrorIs(t, authService.Sessions().ValidateSession(current.SessionID, user.ID), ErrSessionRevoked)
+	_, err = authService.RefreshToken(current.RefreshToken)
+	assert.Error(t, err)
+}
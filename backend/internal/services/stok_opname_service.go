@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
 	"github.com/jung-kurt/gofpdf"
 	"github.com/xuri/excelize/v2"
@@ -301,14 +302,25 @@ func (s *stokOpnameServiceImpl) AddItem(formID uint, ingredientID uint, physical
 		return err
 	}
 	
-	// 4. Fetch the current system stock for the ingredient from inventory_items table
+	// 4. Fetch the current system stock for the ingredient from inventory_items table.
+	// An ingredient without an inventory record has no stock yet; approving the
+	// form creates the record.
+	systemStock := 0.0
 	inventoryItem, err := (*s.inventoryService).GetInventoryItem(ingredientID)
-	if err != nil {
+	if err == nil {
+		systemStock = inventoryItem.Quantity
+	} else if errors.Is(err, ErrInventoryNotFound) {
+		var ingredient models.Ingredient
+		if err := s.db.First(&ingredient, ingredientID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrIngredientNotFound
+			}
+			return err
+		}
+	} else {
 		return err
 	}
 	
-	systemStock := inventoryItem.Quantity
-	
 	// 5. Calculate difference = physical_count - system_stock
 	difference := physicalCount - systemStock
 	
@@ -460,8 +472,8 @@ func (s *stokOpnameServiceImpl) ApproveForm(formID uint, approverID uint) error
 		return err
 	}
 
-	// 3. Validate approver has "kepala_sppg" role
-	if approver.Role != "kepala_sppg" {
+	// 3. Validate approver has "kepala_sppg" role and did not create the form
+	if approver.Role != "kepala_sppg" || approverID == form.CreatedBy {
 		return ErrUnauthorized
 	}
 
@@ -571,11 +583,35 @@ func (s *stokOpnameServiceImpl) ApproveForm(formID uint, approverID uint) error
 		}
 	}
 
+	// 8. Save the events for durable subscribers with the adjustments
+	ingredientIDs := make([]uint, 0, len(form.Items))
+	for _, item := range form.Items {
+		if item.Difference != 0 {
+			ingredientIDs = append(ingredientIDs, item.IngredientID)
+		}
+	}
+	var published []events.Event
+	if len(ingredientIDs) > 0 {
+		published = append(published, events.StockChanged{IngredientIDs: ingredientIDs, Reason: "stok_opname", Reference: form.FormNumber})
+	}
+	published = append(published, events.StokOpnameApproved{
+		FormID:        form.ID,
+		FormNumber:    form.FormNumber,
+		CreatedBy:     form.CreatedBy,
+		ApprovedBy:    approverID,
+		IngredientIDs: ingredientIDs,
+	})
+	if err := events.PublishTx(tx, published...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// 9. Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		return err
 	}
 
+	events.Notify(published...)
 	return nil
 }
 
@@ -7,13 +7,13 @@ import (
 
 	"github.com/erp-sppg/backend/internal/models"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
 // setupTestDB creates an in-memory SQLite database for testing
 func setupStokOpnameTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
 	if err != nil {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
@@ -790,7 +790,7 @@ func TestDeleteForm_CascadeDeleteItems(t *testing.T) {
 func TestAddItem_Success(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Create test user
 	user := createStokOpnameTestUser(db, "1234567890", "Test User", "test@example.com", "staff")
@@ -839,7 +839,7 @@ func TestAddItem_Success(t *testing.T) {
 func TestAddItem_FormNotFound(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Try to add item to non-existent form
 	err := service.AddItem(999, 1, 100.0, "notes")
@@ -852,7 +852,7 @@ func TestAddItem_FormNotFound(t *testing.T) {
 func TestAddItem_FormNotPending(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Create test users
 	creator := createStokOpnameTestUser(db, "1234567890", "Creator User", "creator@example.com", "staff")
@@ -901,7 +901,7 @@ func TestAddItem_FormNotPending(t *testing.T) {
 func TestAddItem_DuplicateIngredient(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Create test user
 	user := createStokOpnameTestUser(db, "1234567890", "Test User", "test@example.com", "staff")
@@ -947,7 +947,7 @@ func TestAddItem_DuplicateIngredient(t *testing.T) {
 func TestAddItem_SystemStockCapture(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Create test user
 	user := createStokOpnameTestUser(db, "1234567890", "Test User", "test@example.com", "staff")
@@ -990,7 +990,7 @@ func TestAddItem_SystemStockCapture(t *testing.T) {
 func TestAddItem_DifferenceCalculation(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Create test user
 	user := createStokOpnameTestUser(db, "1234567890", "Test User", "test@example.com", "staff")
@@ -1071,7 +1071,7 @@ func TestAddItem_DifferenceCalculation(t *testing.T) {
 func TestAddItem_MultipleItems(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Create test user
 	user := createStokOpnameTestUser(db, "1234567890", "Test User", "test@example.com", "staff")
@@ -1133,7 +1133,7 @@ func TestAddItem_MultipleItems(t *testing.T) {
 func TestUpdateItem_Success(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Create test user
 	user := createStokOpnameTestUser(db, "1234567890", "Test User", "test@example.com", "staff")
@@ -1189,7 +1189,7 @@ func TestUpdateItem_Success(t *testing.T) {
 func TestUpdateItem_ItemNotFound(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Try to update non-existent item
 	err := service.UpdateItem(999, 100.0, "notes")
@@ -1202,7 +1202,7 @@ func TestUpdateItem_ItemNotFound(t *testing.T) {
 func TestUpdateItem_FormNotPending(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Create test users
 	creator := createStokOpnameTestUser(db, "1234567890", "Creator User", "creator@example.com", "staff")
@@ -1260,7 +1260,7 @@ func TestUpdateItem_FormNotPending(t *testing.T) {
 func TestUpdateItem_RejectedForm(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Create test users
 	creator := createStokOpnameTestUser(db, "1234567890", "Creator User", "creator@example.com", "staff")
@@ -1319,7 +1319,7 @@ func TestUpdateItem_RejectedForm(t *testing.T) {
 func TestUpdateItem_DifferenceRecalculation(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Create test user
 	user := createStokOpnameTestUser(db, "1234567890", "Test User", "test@example.com", "staff")
@@ -1383,7 +1383,7 @@ func TestUpdateItem_DifferenceRecalculation(t *testing.T) {
 func TestUpdateItem_EmptyNotes(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
 	inventoryService := NewInventoryService(db)
-	service := NewStokOpnameService(db, &inventoryService, nil)
+	service := NewStokOpnameService(db, inventoryService, nil)
 
 	// Create test user
 	user := createStokOpnameTestUser(db, "1234567890", "Test User", "test@example.com", "staff")
@@ -1745,7 +1745,7 @@ func TestApproveForm_WithNewIngredient(t *testing.T) {
 	var movements []models.InventoryMovement
 	err = db.Where("reference LIKE ?", "Stok Opname: %").Find(&movements).Error
 	assert.NoError(t, err)
-	assert.Equal(t, 1, len(movements))
+	require.Len(t, movements, 1)
 	assert.Equal(t, "adjustment", movements[0].MovementType)
 	assert.Equal(t, 50.0, movements[0].Quantity)
 }
@@ -1979,34 +1979,28 @@ func TestExportForm_ExcelFormat(t *testing.T) {
 
 	// Create ingredients and inventory items
 	ingredient1 := &models.Ingredient{
-		Name:        "Beras",
-		Unit:        "kg",
-		Category:    "Bahan Pokok",
-		Description: "Beras putih",
+		Name:     "Beras",
+		Unit:     "kg",
+		Category: "Bahan Pokok",
 	}
 	db.Create(ingredient1)
 
 	inventoryItem1 := &models.InventoryItem{
 		IngredientID: ingredient1.ID,
 		Quantity:     100.0,
-		Unit:         "kg",
-		Location:     "Gudang A",
 	}
 	db.Create(inventoryItem1)
 
 	ingredient2 := &models.Ingredient{
-		Name:        "Gula",
-		Unit:        "kg",
-		Category:    "Bahan Pokok",
-		Description: "Gula pasir",
+		Name:     "Gula",
+		Unit:     "kg",
+		Category: "Bahan Pokok",
 	}
 	db.Create(ingredient2)
 
 	inventoryItem2 := &models.InventoryItem{
 		IngredientID: ingredient2.ID,
 		Quantity:     50.0,
-		Unit:         "kg",
-		Location:     "Gudang A",
 	}
 	db.Create(inventoryItem2)
 
@@ -2043,18 +2037,15 @@ func TestExportForm_PDFFormat(t *testing.T) {
 
 	// Create ingredient and inventory item
 	ingredient := &models.Ingredient{
-		Name:        "Beras",
-		Unit:        "kg",
-		Category:    "Bahan Pokok",
-		Description: "Beras putih",
+		Name:     "Beras",
+		Unit:     "kg",
+		Category: "Bahan Pokok",
 	}
 	db.Create(ingredient)
 
 	inventoryItem := &models.InventoryItem{
 		IngredientID: ingredient.ID,
 		Quantity:     100.0,
-		Unit:         "kg",
-		Location:     "Gudang A",
 	}
 	db.Create(inventoryItem)
 
@@ -2105,6 +2096,7 @@ func TestExportForm_FormNotFound(t *testing.T) {
 
 func TestExportForm_WithApprovalInfo(t *testing.T) {
 	db := setupStokOpnameTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.InventoryMovement{}))
 	inventoryService := NewInventoryService(db)
 	service := NewStokOpnameService(db, inventoryService, nil)
 
@@ -2118,18 +2110,15 @@ func TestExportForm_WithApprovalInfo(t *testing.T) {
 
 	// Create ingredient and inventory item
 	ingredient := &models.Ingredient{
-		Name:        "Beras",
-		Unit:        "kg",
-		Category:    "Bahan Pokok",
-		Description: "Beras putih",
+		Name:     "Beras",
+		Unit:     "kg",
+		Category: "Bahan Pokok",
 	}
 	db.Create(ingredient)
 
 	inventoryItem := &models.InventoryItem{
 		IngredientID: ingredient.ID,
 		Quantity:     100.0,
-		Unit:         "kg",
-		Location:     "Gudang A",
 	}
 	db.Create(inventoryItem)
 
@@ -2163,18 +2152,15 @@ func TestExportForm_WithRejectionInfo(t *testing.T) {
 
 	// Create ingredient and inventory item
 	ingredient := &models.Ingredient{
-		Name:        "Beras",
-		Unit:        "kg",
-		Category:    "Bahan Pokok",
-		Description: "Beras putih",
+		Name:     "Beras",
+		Unit:     "kg",
+		Category: "Bahan Pokok",
 	}
 	db.Create(ingredient)
 
 	inventoryItem := &models.InventoryItem{
 		IngredientID: ingredient.ID,
 		Quantity:     100.0,
-		Unit:         "kg",
-		Location:     "Gudang A",
 	}
 	db.Create(inventoryItem)
 
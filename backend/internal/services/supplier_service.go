@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/erp-sppg/backend/internal/events"
 	"github.com/erp-sppg/backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -43,7 +44,12 @@ func (s *SupplierService) CreateSupplier(supplier *models.Supplier) error {
 	supplier.OnTimeDelivery = 0
 	supplier.QualityRating = 0
 
-	return s.db.Create(supplier).Error
+	if err := s.db.Create(supplier).Error; err != nil {
+		return err
+	}
+
+	events.Publish(events.SupplierChanged{SupplierID: supplier.ID})
+	return nil
 }
 
 // GetSupplierByID retrieves a supplier by ID
@@ -93,7 +99,7 @@ func (s *SupplierService) UpdateSupplier(id uint, updates *models.Supplier) erro
 	}
 
 	// Update supplier
-	return s.db.Model(&models.Supplier{}).Where("id = ?", id).Updates(map[string]interface{}{
+	fields := map[string]interface{}{
 		"name":             updates.Name,
 		"contact_person":   updates.ContactPerson,
 		"phone_number":     updates.PhoneNumber,
@@ -102,7 +108,16 @@ func (s *SupplierService) UpdateSupplier(id uint, updates *models.Supplier) erro
 		"product_category": updates.ProductCategory,
 		"is_active":        updates.IsActive,
 		"updated_at":       time.Now(),
-	}).Error
+	}
+	if updates.PaymentTermDays > 0 {
+		fields["payment_term_days"] = updates.PaymentTermDays
+	}
+	if err := s.db.Model(&models.Supplier{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+		return err
+	}
+
+	events.Publish(events.SupplierChanged{SupplierID: id})
+	return nil
 }
 
 // DeactivateSupplier marks a supplier as inactive
@@ -114,6 +129,8 @@ func (s *SupplierService) DeactivateSupplier(id uint) error {
 	if result.RowsAffected == 0 {
 		return ErrSupplierNotFound
 	}
+
+	events.Publish(events.SupplierChanged{SupplierID: id})
 	return nil
 }
 
@@ -126,6 +143,8 @@ func (s *SupplierService) ActivateSupplier(id uint) error {
 	if result.RowsAffected == 0 {
 		return ErrSupplierNotFound
 	}
+
+	events.Publish(events.SupplierChanged{SupplierID: id})
 	return nil
 }
 
@@ -138,17 +157,17 @@ type SupplierPerformance struct {
 	OnTimeDeliveries int                 `json:"on_time_deliveries"`
 	OnTimeRate       float64             `json:"on_time_rate"`
 	QualityRating    float64             `json:"quality_rating"`
-	TotalAmount      float64             `json:"total_amount"`
+	TotalAmount      models.Money        `json:"total_amount"`
 	LastOrderDate    *time.Time          `json:"last_order_date"`
 	Transactions     []TransactionSummary `json:"transactions"`
 }
 
 // TransactionSummary represents a summary of a purchase order transaction
 type TransactionSummary struct {
-	PONumber  string    `json:"po_number"`
-	OrderDate time.Time `json:"order_date"`
-	Amount    float64   `json:"amount"`
-	Status    string    `json:"status"`
+	PONumber  string       `json:"po_number"`
+	OrderDate time.Time    `json:"order_date"`
+	Amount    models.Money `json:"amount"`
+	Status    string       `json:"status"`
 }
 
 // GetSupplierPerformance retrieves performance metrics for a supplier
@@ -199,7 +218,7 @@ func (s *SupplierService) GetSupplierPerformance(id uint) (*SupplierPerformance,
 	performance.QualityRating = avgRating
 
 	// Calculate total amount
-	var totalAmount float64
+	var totalAmount models.Money
 	s.db.Model(&models.PurchaseOrder{}).
 		Where("supplier_id = ? AND status IN ?", id, []string{"approved", "received"}).
 		Select("COALESCE(SUM(total_amount), 0)").
@@ -256,7 +275,12 @@ func (s *SupplierService) UpdateSupplierRating(id uint, rating float64, notes st
 
 	// Update the rating (simple average for now)
 	// In a full implementation, we might want to track individual ratings
-	return s.db.Model(&models.Supplier{}).Where("id = ?", id).Update("quality_rating", rating).Error
+	if err := s.db.Model(&models.Supplier{}).Where("id = ?", id).Update("quality_rating", rating).Error; err != nil {
+		return err
+	}
+
+	events.Publish(events.SupplierChanged{SupplierID: id})
+	return nil
 }
 
 // SearchSuppliers searches suppliers by name or product category
@@ -282,7 +306,7 @@ func (s *SupplierService) SearchSuppliers(query string, productCategory string,
 // SupplierStats represents overall supplier statistics
 type SupplierStats struct {
 	TotalSuppliers   int              `json:"total_suppliers"`
-	TotalSpending    float64          `json:"total_spending"`
+	TotalSpending    models.Money     `json:"total_spending"`
 	ActiveSuppliers  int              `json:"active_suppliers"`
 	AverageRating    float64          `json:"average_rating"`
 	TopSuppliers     []TopSupplier    `json:"top_suppliers"`
@@ -290,10 +314,10 @@ type SupplierStats struct {
 
 // TopSupplier represents a top performing supplier
 type TopSupplier struct {
-	ID          uint    `json:"id"`
-	Name        string  `json:"name"`
-	TotalOrders int     `json:"total_orders"`
-	TotalAmount float64 `json:"total_amount"`
+	ID          uint         `json:"id"`
+	Name        string       `json:"name"`
+	TotalOrders int          `json:"total_orders"`
+	TotalAmount models.Money `json:"total_amount"`
 }
 
 // GetSupplierStats retrieves overall supplier statistics
@@ -325,7 +349,7 @@ func (s *SupplierService) GetSupplierStats() (*SupplierStats, error) {
 	stats.AverageRating = avgRating
 
 	// Get total spending from purchase orders (approved and received)
-	var totalSpending float64
+	var totalSpending models.Money
 	if err := s.db.Model(&models.PurchaseOrder{}).
 		Where("status IN ?", []string{"approved", "received"}).
 		Select("COALESCE(SUM(total_amount), 0)").
@@ -339,7 +363,7 @@ func (s *SupplierService) GetSupplierStats() (*SupplierStats, error) {
 		SupplierID  uint
 		SupplierName string
 		OrderCount  int
-		TotalAmount float64
+		TotalAmount models.Money
 	}
 
 	var topSupplierStats []SupplierOrderStats
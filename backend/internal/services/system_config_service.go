@@ -232,6 +232,29 @@ func (s *SystemConfigService) InitializeDefaultConfigs() error {
 		{"security_max_login_attempts", "5", "int", "security"},
 		{"security_lockout_duration", "15", "int", "security"},
 		{"security_strong_password", "true", "bool", "security"},
+		{"security_password_history", "5", "int", "security"},
+		{"security_password_expiry_days", "90", "int", "security"},
+		{"security_2fa_required_roles", defaultTwoFactorRoles, "string", "security"},
+		
+		// Budget alerts
+		{"budget_alert_thresholds", defaultBudgetAlertThresholds, "string", "finance"},
+		{"budget_alert_roles", defaultBudgetAlertRoles, "string", "finance"},
+		
+		// Accounts payable three-way match tolerances (percent)
+		{"ap_match_quantity_tolerance_percent", "2.0", "float", "finance"},
+		{"ap_match_price_tolerance_percent", "2.0", "float", "finance"},
+		{"ap_match_amount_tolerance_percent", "2.0", "float", "finance"},
+		
+		// Bank reconciliation: days a bank mutation may differ from its book date
+		{"bank_match_date_tolerance_days", "3", "int", "finance"},
+		
+		// Post the previous month's asset depreciation automatically
+		{"depreciation_auto_post", "true", "bool", "finance"},
+		
+		// Preventive maintenance: days before the due date a task is reminded, and
+		// the share of a usage interval left when a usage-based task becomes due
+		{"maintenance_reminder_days", "3", "int", "finance"},
+		{"maintenance_usage_due_ratio", "0.1", "float", "finance"},
 		
 		// System operations
 		{"system_backup_schedule", "daily", "string", "system"},
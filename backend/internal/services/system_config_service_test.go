@@ -5,12 +5,11 @@ import (
 
 	"github.com/erp-sppg/backend/internal/models"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 func setupSystemConfigTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := openTestDB(t, ":memory:", &gorm.Config{})
 	assert.NoError(t, err)
 
 	err = db.AutoMigrate(&models.SystemConfig{})
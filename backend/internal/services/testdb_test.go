@@ -0,0 +1,71 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/erp-sppg/backend/internal/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testPostgresDSNEnv selects the database engine of the service tests. When it
+// is unset every test opens its own SQLite database; when it holds a
+// PostgreSQL DSN every test gets a schema of its own on that server, e.g.
+//
+//	TEST_POSTGRES_DSN="host=localhost user=postgres dbname=erp_sppg_test sslmode=disable" go test ./internal/services/
+const testPostgresDSNEnv = "TEST_POSTGRES_DSN"
+
+var testSchemaSeq uint64
+
+// openTestDB opens the database of one test: the given SQLite database, or a
+// fresh PostgreSQL schema when TEST_POSTGRES_DSN is set. The schema is dropped
+// when the test ends.
+func openTestDB(t *testing.T, sqliteDSN string, config *gorm.Config) (*gorm.DB, error) {
+	dsn := os.Getenv(testPostgresDSNEnv)
+	if dsn == "" {
+		return gorm.Open(database.SQLiteDialector(sqliteDSN), config)
+	}
+	t.Helper()
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		return nil, err
+	}
+	schema := fmt.Sprintf("test_%d_%d", os.Getpid(), atomic.AddUint64(&testSchemaSeq, 1))
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(postgres.Open(withSearchPath(dsn, schema)), config)
+	t.Cleanup(func() {
+		if db != nil {
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		}
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Logf("schema %s tidak terhapus: %v", schema, err)
+		}
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db, err
+}
+
+// withSearchPath points a keyword or URL DSN at the given schema
+func withSearchPath(dsn, schema string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		return dsn + separator + "search_path=" + schema
+	}
+	return dsn + " search_path=" + schema
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTwoFactorRequired         = errors.New("verifikasi dua langkah diperlukan")
+	ErrTwoFactorNotEnrolled      = errors.New("verifikasi dua langkah belum diaktifkan")
+	ErrTwoFactorAlreadyEnabled   = errors.New("verifikasi dua langkah sudah aktif")
+	ErrInvalidTwoFactorCode      = errors.New("kode verifikasi tidak valid")
+	ErrTwoFactorEnforced         = errors.New("verifikasi dua langkah wajib untuk role ini dan tidak dapat dinonaktifkan")
+	ErrInvalidTwoFactorChallenge = errors.New("sesi verifikasi dua langkah tidak valid atau sudah kadaluarsa")
+)
+
+const (
+	// TwoFactorIssuer is the account issuer shown in authenticator apps
+	TwoFactorIssuer = "ERP SPPG"
+	// recoveryCodeCount is the number of recovery codes issued at once
+	recoveryCodeCount = 10
+	// twoFactorSkew is the number of 30 second steps accepted for clock drift
+	twoFactorSkew = 1
+	// defaultTwoFactorRoles are the privileged roles that must use 2FA unless
+	// security_2fa_required_roles says otherwise
+	defaultTwoFactorRoles = "kepala_sppg,kepala_yayasan,akuntan"
+)
+
+// TwoFactorEnrollment holds what the user needs to add the account to an authenticator app
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorStatus describes the 2FA state of a user
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	EnabledAt              *time.Time `json:"enabled_at"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorService manages TOTP enrollment, verification and recovery codes
+type TwoFactorService struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorService creates a new two-factor authentication service
+func NewTwoFactorService(db *gorm.DB) *TwoFactorService {
+	return &TwoFactorService{db: db}
+}
+
+// RequiredRoles returns the roles that must enroll in 2FA.
+// An empty security_2fa_required_roles value disables enforcement.
+func (s *TwoFactorService) RequiredRoles() []string {
+	value := NewSystemConfigService(s.db).GetConfigString("security_2fa_required_roles", defaultTwoFactorRoles)
+
+	var roles []string
+	for _, role := range strings.Split(value, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// IsRequiredForRole reports whether users with the role must use 2FA
+func (s *TwoFactorService) IsRequiredForRole(role string) bool {
+	for _, r := range s.RequiredRoles() {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// GetStatus returns the 2FA status of the user
+func (s *TwoFactorService) GetStatus(user *models.User) (*TwoFactorStatus, error) {
+	status := &TwoFactorStatus{
+		Enabled:   user.TwoFactorEnabled,
+		Required:  s.IsRequiredForRole(user.Role),
+		EnabledAt: user.TwoFactorEnabledAt,
+	}
+
+	if user.TwoFactorEnabled {
+		if err := s.db.Model(&models.TwoFactorRecoveryCode{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Count(&status.RecoveryCodesRemaining).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+// BeginEnrollment generates a new secret for the user. 2FA stays disabled
+// until the user proves possession with ConfirmEnrollment.
+func (s *TwoFactorService) BeginEnrollment(user *models.User) (*TwoFactorEnrollment, error) {
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"two_factor_secret":    secret,
+		"two_factor_last_step": 0,
+	}).Error; err != nil {
+		return nil, err
+	}
+	user.TwoFactorSecret = secret
+	user.TwoFactorLastStep = 0
+
+	account := user.Email
+	if account == "" {
+		account = user.NIK
+	}
+
+	return &TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(TwoFactorIssuer, account, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables 2FA when the code matches the pending secret and
+// returns the recovery codes. The codes are only ever shown this once.
+func (s *TwoFactorService) ConfirmEnrollment(user *models.User, code string) ([]string, error) {
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	now := time.Now()
+	step, ok := utils.ValidateTOTPCode(user.TwoFactorSecret, code, now, twoFactorSkew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"two_factor_enabled":    true,
+			"two_factor_enabled_at": now,
+			"two_factor_last_step":  step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorEnabled = true
+	user.TwoFactorEnabledAt = &now
+	user.TwoFactorLastStep = step
+
+	NewAuditTrailService(s.db).RecordAction(user.ID, "enable_2fa", "user", strconv.FormatUint(uint64(user.ID), 10), nil, nil, "")
+	return codes, nil
+}
+
+// VerifyCode accepts either a current TOTP code or an unused recovery code.
+// A TOTP code can only be used once and a recovery code is consumed.
+func (s *TwoFactorService) VerifyCode(user *models.User, code string) error {
+	if !user.TwoFactorEnabled || user.TwoFactorSecret == "" {
+		return ErrTwoFactorNotEnrolled
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := utils.ValidateTOTPCode(user.TwoFactorSecret, code, time.Now(), twoFactorSkew); ok {
+		// Only move forward so an intercepted code cannot be replayed
+		result := s.db.Model(&models.User{}).
+			Where("id = ? AND two_factor_last_step < ?", user.ID, step).
+			Update("two_factor_last_step", step)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidTwoFactorCode
+		}
+		user.TwoFactorLastStep = step
+		return nil
+	}
+
+	return s.useRecoveryCode(user.ID, code)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after verifying a code
+func (s *TwoFactorService) RegenerateRecoveryCodes(user *models.User, code string) ([]string, error) {
+	if err := s.VerifyCode(user, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(user.ID, "regenerate_recovery_codes", "user", strconv.FormatUint(uint64(user.ID), 10), nil, nil, "")
+	return codes, nil
+}
+
+// Disable turns 2FA off after verifying a code. Users whose role requires
+// 2FA cannot disable it themselves.
+func (s *TwoFactorService) Disable(user *models.User, code string) error {
+	if s.IsRequiredForRole(user.Role) {
+		return ErrTwoFactorEnforced
+	}
+	if err := s.VerifyCode(user, code); err != nil {
+		return err
+	}
+
+	if err := s.Reset(user.ID); err != nil {
+		return err
+	}
+
+	user.TwoFactorEnabled = false
+	user.TwoFactorSecret = ""
+	user.TwoFactorEnabledAt = nil
+	return nil
+}
+
+// Reset removes the 2FA enrollment of a user, e.g. when an administrator
+// handles a lost authenticator device. The user has to enroll again.
+func (s *TwoFactorService) Reset(userID uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"two_factor_enabled":    false,
+			"two_factor_secret":     "",
+			"two_factor_last_step":  0,
+			"two_factor_enabled_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "disable_2fa", "user", strconv.FormatUint(uint64(userID), 10), nil, nil, "")
+	return nil
+}
+
+// useRecoveryCode consumes a matching unused recovery code
+func (s *TwoFactorService) useRecoveryCode(userID uint, code string) error {
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return ErrInvalidTwoFactorCode
+	}
+
+	result := s.db.Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRecoveryCode(normalized)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+
+	NewAuditTrailService(s.db).RecordAction(userID, "use_recovery_code", "user", strconv.FormatUint(uint64(userID), 10), nil, nil, "")
+	return nil
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores a new set
+func (s *TwoFactorService) replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.TwoFactorRecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, models.TwoFactorRecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code formatted as xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	raw := make([]byte, 7)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	encoded := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// normalizeRecoveryCode makes recovery codes case and separator insensitive
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// hashRecoveryCode returns the hex SHA-256 digest stored for a recovery code
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/erp-sppg/backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func currentTOTPCode(t *testing.T, secret string, offset int64) string {
+	code, err := utils.GenerateTOTPCode(secret, utils.TOTPStep(time.Now())+offset)
+	require.NoError(t, err)
+	return code
+}
+
+func enrollTwoFactor(t *testing.T, authService *AuthService, user *models.User) []string {
+	enrollment, err := authService.TwoFactor().BeginEnrollment(user)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+
+	codes, err := authService.TwoFactor().ConfirmEnrollment(user, currentTOTPCode(t, enrollment.Secret, -1))
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	return codes
+}
+
+func TestTwoFactorService_EnrollmentAndLogin(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+
+	// Required for akuntan by default, so the token asks for enrollment
+	_, tokens, err := authService.LoginWithDevice(user.NIK, "Rahasia123", DeviceInfo{})
+	require.NoError(t, err)
+	claims, err := authService.ValidateToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, claims.TwoFactorSetup)
+
+	_, err = authService.TwoFactor().ConfirmEnrollment(user, "000000")
+	assert.ErrorIs(t, err, ErrTwoFactorNotEnrolled)
+
+	enrollTwoFactor(t, authService, user)
+
+	token, err := authService.ReissueAccessToken(user.ID, tokens.SessionID)
+	require.NoError(t, err)
+	claims, err = authService.ValidateToken(token)
+	require.NoError(t, err)
+	assert.False(t, claims.TwoFactorSetup)
+
+	// Password alone no longer opens a session
+	loggedIn, tokens, err := authService.LoginWithDevice(user.NIK, "Rahasia123", DeviceInfo{})
+	assert.ErrorIs(t, err, ErrTwoFactorRequired)
+	assert.Nil(t, tokens)
+
+	challenge, err := authService.IssueTwoFactorChallenge(loggedIn, "Laptop")
+	require.NoError(t, err)
+
+	// The challenge is not an access token
+	_, err = authService.ValidateToken(challenge)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, _, err = authService.CompleteTwoFactorLogin(challenge, "123", DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	_, tokens, err = authService.CompleteTwoFactorLogin(challenge, currentTOTPCode(t, user.TwoFactorSecret, 0), DeviceInfo{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	var session models.UserSession
+	require.NoError(t, db.Where("session_id = ?", tokens.SessionID).First(&session).Error)
+	assert.Equal(t, "Laptop", session.DeviceName)
+
+	// The same TOTP code cannot be replayed
+	_, _, err = authService.CompleteTwoFactorLogin(challenge, currentTOTPCode(t, user.TwoFactorSecret, 0), DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	_, _, err = authService.CompleteTwoFactorLogin("bukan-token", "123456", DeviceInfo{})
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorChallenge)
+}
+
+func TestTwoFactorService_RecoveryCodesAreSingleUse(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+	codes := enrollTwoFactor(t, authService, user)
+
+	require.NoError(t, authService.TwoFactor().VerifyCode(user, codes[0]))
+	assert.ErrorIs(t, authService.TwoFactor().VerifyCode(user, codes[0]), ErrInvalidTwoFactorCode)
+
+	// Case and separators do not matter
+	require.NoError(t, authService.TwoFactor().VerifyCode(user, strings.ToUpper(codes[1][:5]+" "+codes[1][6:])))
+
+	status, err := authService.TwoFactor().GetStatus(user)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.True(t, status.Required)
+	assert.Equal(t, int64(recoveryCodeCount-2), status.RecoveryCodesRemaining)
+
+	newCodes, err := authService.TwoFactor().RegenerateRecoveryCodes(user, codes[2])
+	require.NoError(t, err)
+	assert.Len(t, newCodes, recoveryCodeCount)
+	assert.ErrorIs(t, authService.TwoFactor().VerifyCode(user, codes[3]), ErrInvalidTwoFactorCode)
+}
+
+func TestTwoFactorService_WrongCodesLockAccount(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	require.NoError(t, NewSystemConfigService(db).SetConfig("security_max_login_attempts", "3", "int", "security", 1))
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+	enrollTwoFactor(t, authService, user)
+
+	var err error
+	for i := 0; i < 3; i++ {
+		// Re-entering the correct password does not reset the counter
+		var loggedIn *models.User
+		loggedIn, _, err = authService.LoginWithDevice(user.NIK, "Rahasia123", DeviceInfo{})
+		require.ErrorIs(t, err, ErrTwoFactorRequired)
+		challenge, cErr := authService.IssueTwoFactorChallenge(loggedIn, "")
+		require.NoError(t, cErr)
+		_, _, err = authService.CompleteTwoFactorLogin(challenge, "zzzzz-zzzzz", DeviceInfo{})
+	}
+	assert.ErrorIs(t, err, ErrAccountLocked)
+}
+
+func TestTwoFactorService_DisableRespectsRolePolicy(t *testing.T) {
+	db := setupPasswordPolicyTestDB(t)
+	authService := NewAuthService(db, "test-secret")
+	user := createPolicyTestUser(t, db, authService, "Rahasia123")
+	codes := enrollTwoFactor(t, authService, user)
+
+	assert.ErrorIs(t, authService.TwoFactor().Disable(user, codes[0]), ErrTwoFactorEnforced)
+
+	// Enforcement is configured per role
+	require.NoError(t, NewSystemConfigService(db).SetConfig("security_2fa_required_roles", "kepala_sppg", "string", "security", 1))
+	assert.False(t, authService.TwoFactor().IsRequiredForRole("akuntan"))
+	require.NoError(t, authService.TwoFactor().Disable(user, codes[0]))
+
+	reloaded, err := authService.GetUserByID(user.ID)
+	require.NoError(t, err)
+	assert.False(t, reloaded.TwoFactorEnabled)
+	assert.Empty(t, reloaded.TwoFactorSecret)
+
+	var remaining int64
+	db.Model(&models.TwoFactorRecoveryCode{}).Where("user_id = ?", user.ID).Count(&remaining)
+	assert.Equal(t, int64(0), remaining)
+}
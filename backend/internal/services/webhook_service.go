@@ -0,0 +1,437 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/events"
+	"github.com/erp-sppg/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Webhook errors
+var (
+	ErrWebhookNotFound           = errors.New("webhook tidak ditemukan")
+	ErrWebhookDeliveryNotFound   = errors.New("pengiriman webhook tidak ditemukan")
+	ErrWebhookDeliveryInProgress = errors.New("pengiriman webhook masih dalam antrean")
+	ErrWebhookInvalidURL         = errors.New("URL webhook harus berupa URL http atau https yang lengkap")
+	ErrWebhookInvalidEventType   = errors.New("tipe event webhook tidak dikenal")
+)
+
+// Headers sent with every webhook request. The signature is
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// OutboxEventWebhookDelivery is the outbox event that sends one webhook delivery
+const OutboxEventWebhookDelivery = "webhook.delivery"
+
+const (
+	// webhookSubscriber names the webhook fan-out in the event outbox
+	webhookSubscriber = "webhooks"
+	// webhookTimeout bounds one request to a partner system
+	webhookTimeout = 10 * time.Second
+	// maxWebhookResponseBody is how much of the partner response is logged
+	maxWebhookResponseBody = 1024
+)
+
+// WebhookEventTypes are the domain events partner systems can subscribe to
+var WebhookEventTypes = []string{
+	events.DeliveryCompletedEvent,
+	events.StokOpnameApprovedEvent,
+	events.PurchaseOrderApprovedEvent,
+}
+
+// WebhookSubscriptionInput is the data an administrator enters for a webhook.
+// An empty secret keeps the current one, or generates one for a new webhook.
+type WebhookSubscriptionInput struct {
+	Name       string   `json:"name" binding:"required,max=100"`
+	TargetURL  string   `json:"target_url" binding:"required,max=500"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+	Secret     string   `json:"secret" binding:"omitempty,min=16,max=128"`
+	IsActive   *bool    `json:"is_active"`
+}
+
+// WebhookService manages webhook subscriptions and sends domain events to them
+type WebhookService struct {
+	db     *gorm.DB
+	client *http.Client
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{
+		db:     db,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// SubscribeEvents records a delivery for every active subscription that wants
+// an event. The fan-out is a durable subscriber, so no event is lost when the
+// database is briefly unavailable.
+func (s *WebhookService) SubscribeEvents(bus *events.Bus) {
+	for _, name := range WebhookEventTypes {
+		bus.SubscribeDurable(name, webhookSubscriber, s.fanOut)
+	}
+}
+
+// RegisterOutboxHandlers lets the outbox dispatcher send webhook deliveries,
+// retrying with backoff until the partner accepts them
+func (s *WebhookService) RegisterOutboxHandlers(outbox *OutboxService) {
+	outbox.RegisterHandler(OutboxEventWebhookDelivery, func(ctx context.Context, event *models.OutboxEvent) error {
+		return s.send(ctx, event.AggregateID, event.Attempts+1 >= event.MaxAttempts)
+	})
+}
+
+// fanOut creates the deliveries of an event in one transaction, each with the
+// outbox event that sends it
+func (s *WebhookService) fanOut(ctx context.Context, event events.Event) error {
+	var subscriptions []models.WebhookSubscription
+	if err := s.db.WithContext(ctx).Where("is_active = ?", true).Find(&subscriptions).Error; err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan payload webhook: %w", err)
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, subscription := range subscriptions {
+			if !webhookWantsEvent(subscription, event.EventName()) {
+				continue
+			}
+
+			delivery := models.WebhookDelivery{
+				SubscriptionID: subscription.ID,
+				EventType:      event.EventName(),
+				Payload:        string(payload),
+				Status:         models.WebhookDeliveryPending,
+			}
+			if err := tx.Create(&delivery).Error; err != nil {
+				return err
+			}
+			if err := EnqueueOutboxEvent(tx, OutboxEventWebhookDelivery, "webhook_delivery", delivery.ID, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func webhookWantsEvent(subscription models.WebhookSubscription, name string) bool {
+	for _, eventType := range strings.Split(subscription.EventTypes, ",") {
+		if eventType == name {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookEnvelope is the JSON body sent to partner systems
+type webhookEnvelope struct {
+	ID         uint            `json:"id"`
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// SignWebhookPayload returns the signature partner systems check to verify a
+// webhook request came from this system
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// send makes one attempt to deliver a webhook and logs the outcome. An error
+// makes the outbox retry; lastAttempt marks the delivery as failed instead.
+func (s *WebhookService) send(ctx context.Context, deliveryID uint, lastAttempt bool) error {
+	var delivery models.WebhookDelivery
+	if err := s.db.WithContext(ctx).Preload("Subscription").First(&delivery, deliveryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Removed together with its subscription; nothing left to send
+			return nil
+		}
+		return err
+	}
+
+	if !delivery.Subscription.IsActive {
+		return s.recordAttempt(&delivery, 0, "", errors.New("webhook tidak aktif"), true)
+	}
+
+	body, err := json.Marshal(webhookEnvelope{
+		ID:         delivery.ID,
+		Event:      delivery.EventType,
+		OccurredAt: delivery.CreatedAt,
+		Data:       json.RawMessage(delivery.Payload),
+	})
+	if err != nil {
+		return s.recordAttempt(&delivery, 0, "", err, true)
+	}
+
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Subscription.TargetURL, bytes.NewReader(body))
+	if err != nil {
+		return s.recordAttempt(&delivery, 0, "", err, true)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ERP-SPPG-Webhook/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Subscription.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return s.recordAttempt(&delivery, 0, "", err, lastAttempt)
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err = fmt.Errorf("partner menjawab dengan status %d", resp.StatusCode)
+	}
+	return s.recordAttempt(&delivery, resp.StatusCode, string(responseBody), err, lastAttempt)
+}
+
+// recordAttempt logs an attempt on the delivery and returns the error that
+// should reach the outbox: nil when delivered or when retrying is pointless
+func (s *WebhookService) recordAttempt(delivery *models.WebhookDelivery, statusCode int, responseBody string, sendErr error, final bool) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_attempt_at": now,
+		"response_status": statusCode,
+		"response_body":   responseBody,
+	}
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.WebhookDeliveryDelivered
+		updates["delivered_at"] = now
+		updates["last_error"] = ""
+	case final:
+		updates["status"] = models.WebhookDeliveryFailed
+		updates["last_error"] = sendErr.Error()
+	default:
+		updates["status"] = models.WebhookDeliveryRetrying
+		updates["last_error"] = sendErr.Error()
+	}
+
+	if err := s.db.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	if sendErr != nil && !final {
+		return sendErr
+	}
+	return nil
+}
+
+// ListSubscriptions lists all webhook subscriptions
+func (s *WebhookService) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := s.db.Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// GetSubscription retrieves a webhook subscription by ID
+func (s *WebhookService) GetSubscription(id uint) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	if err := s.db.First(&subscription, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// CreateSubscription registers a webhook and returns it with its signing
+// secret, which is not shown again
+func (s *WebhookService) CreateSubscription(input WebhookSubscriptionInput, userID uint) (*models.WebhookSubscription, string, error) {
+	eventTypes, err := validateWebhookInput(input)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	subscription := models.WebhookSubscription{
+		Name:       input.Name,
+		TargetURL:  input.TargetURL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		IsActive:   input.IsActive == nil || *input.IsActive,
+		CreatedBy:  userID,
+	}
+	if err := s.db.Create(&subscription).Error; err != nil {
+		return nil, "", err
+	}
+	return &subscription, secret, nil
+}
+
+// UpdateSubscription changes a webhook. A new secret is used for the next
+// requests, including retries of earlier events.
+func (s *WebhookService) UpdateSubscription(id uint, input WebhookSubscriptionInput) (*models.WebhookSubscription, error) {
+	subscription, err := s.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+
+	eventTypes, err := validateWebhookInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"name":        input.Name,
+		"target_url":  input.TargetURL,
+		"event_types": eventTypes,
+	}
+	if input.Secret != "" {
+		updates["secret"] = input.Secret
+	}
+	if input.IsActive != nil {
+		updates["is_active"] = *input.IsActive
+	}
+	if err := s.db.Model(subscription).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return s.GetSubscription(id)
+}
+
+// DeleteSubscription removes a webhook with its delivery log. Queued
+// deliveries are dropped.
+func (s *WebhookService) DeleteSubscription(id uint) error {
+	if _, err := s.GetSubscription(id); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.WebhookSubscription{}, id).Error
+	})
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first.
+// Status is an optional filter.
+func (s *WebhookService) ListDeliveries(subscriptionID uint, status string, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.GetSubscription(subscriptionID); err != nil {
+		return nil, 0, err
+	}
+
+	query := s.db.Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// Redeliver sends a delivered or failed webhook delivery again, with a fresh
+// set of attempts
+func (s *WebhookService) Redeliver(deliveryID uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&delivery, deliveryID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWebhookDeliveryNotFound
+			}
+			return err
+		}
+
+		result := tx.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND status IN ?", deliveryID, []string{models.WebhookDeliveryDelivered, models.WebhookDeliveryFailed}).
+			Update("status", models.WebhookDeliveryPending)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWebhookDeliveryInProgress
+		}
+
+		if err := EnqueueOutboxEvent(tx, OutboxEventWebhookDelivery, "webhook_delivery", deliveryID, nil); err != nil {
+			return err
+		}
+		return tx.First(&delivery, deliveryID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// validateWebhookInput checks the target URL and event types and returns the
+// event types as stored
+func validateWebhookInput(input WebhookSubscriptionInput) (string, error) {
+	target, err := url.Parse(input.TargetURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return "", ErrWebhookInvalidURL
+	}
+
+	seen := make(map[string]bool)
+	var eventTypes []string
+	for _, eventType := range input.EventTypes {
+		if !isWebhookEventType(eventType) {
+			return "", fmt.Errorf("%w: %s", ErrWebhookInvalidEventType, eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	return strings.Join(eventTypes, ","), nil
+}
+
+func isWebhookEventType(name string) bool {
+	for _, eventType := range WebhookEventTypes {
+		if eventType == name {
+			return true
+		}
+	}
+	return false
+}
+
+// generateWebhookSecret returns a random 256-bit signing secret
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("gagal membuat secret webhook: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
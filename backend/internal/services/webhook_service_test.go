@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erp-sppg/backend/internal/events"
+	"github.com/erp-sppg/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// webhookReceiver is a partner endpoint that fails the first failures requests
+type webhookReceiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte(`{"ok":true}`))
+}
+
+func setupWebhookTest(t *testing.T, receiver *webhookReceiver) (*gorm.DB, *WebhookService, *OutboxService, *events.Bus, *models.WebhookSubscription, string) {
+	db := setupOutboxTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.WebhookSubscription{}, &models.WebhookDelivery{}))
+
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	bus := events.NewBus()
+	outbox := NewOutboxService(db)
+	outbox.AttachEventBus(bus)
+	service := NewWebhookService(db)
+	service.RegisterOutboxHandlers(outbox)
+	service.SubscribeEvents(bus)
+
+	subscription, secret, err := service.CreateSubscription(WebhookSubscriptionInput{
+		Name:       "Dinas Pendidikan",
+		TargetURL:  server.URL + "/hooks/sppg",
+		EventTypes: []string{events.DeliveryCompletedEvent},
+	}, 1)
+	require.NoError(t, err)
+	return db, service, outbox, bus, subscription, secret
+}
+
+// dispatchAll runs the outbox until nothing is due
+func dispatchAll(t *testing.T, outbox *OutboxService) {
+	for i := 0; i < 5; i++ {
+		n, err := outbox.DispatchDue(context.Background())
+		require.NoError(t, err)
+		if n == 0 {
+			return
+		}
+	}
+}
+
+func TestWebhookService_SendsSignedDeliveries(t *testing.T) {
+	receiver := &webhookReceiver{}
+	db, _, outbox, bus, subscription, secret := setupWebhookTest(t, receiver)
+	assert.Len(t, secret, 64)
+
+	bus.Publish(events.DeliveryCompleted{DeliveryRecordID: 7, SchoolID: 3, Portions: 120, PortionsSmall: 50, PortionsLarge: 70})
+	// Not subscribed, so no delivery
+	bus.Publish(events.PurchaseOrderApproved{PurchaseOrderID: 9})
+	dispatchAll(t, outbox)
+
+	require.Len(t, receiver.requests, 1)
+	req, body := receiver.requests[0], receiver.bodies[0]
+	assert.Equal(t, "/hooks/sppg", req.URL.Path)
+	assert.Equal(t, events.DeliveryCompletedEvent, req.Header.Get(WebhookEventHeader))
+	timestamp, err := strconv.ParseInt(req.Header.Get(WebhookTimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, SignWebhookPayload(secret, timestamp, body), req.Header.Get(WebhookSignatureHeader))
+	assert.NotEqual(t, SignWebhookPayload("salah", timestamp, body), req.Header.Get(WebhookSignatureHeader))
+
+	var envelope struct {
+		ID    uint                     `json:"id"`
+		Event string                   `json:"event"`
+		Data  events.DeliveryCompleted `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &envelope))
+	assert.Equal(t, events.DeliveryCompletedEvent, envelope.Event)
+	assert.Equal(t, 120, envelope.Data.Portions)
+	assert.Equal(t, strconv.FormatUint(uint64(envelope.ID), 10), req.Header.Get(WebhookDeliveryHeader))
+
+	var delivery models.WebhookDelivery
+	require.NoError(t, db.Where("subscription_id = ?", subscription.ID).First(&delivery).Error)
+	assert.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+	assert.Equal(t, `{"ok":true}`, delivery.ResponseBody)
+	assert.NotNil(t, delivery.DeliveredAt)
+}
+
+func TestWebhookService_RetriesFailedDeliveries(t *testing.T) {
+	receiver := &webhookReceiver{failures: 1}
+	db, _, outbox, bus, _, _ := setupWebhookTest(t, receiver)
+
+	bus.Publish(events.DeliveryCompleted{DeliveryRecordID: 7, SchoolID: 3, Portions: 120})
+	dispatchAll(t, outbox)
+
+	var delivery models.WebhookDelivery
+	require.NoError(t, db.First(&delivery).Error)
+	assert.Equal(t, models.WebhookDeliveryRetrying, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatus)
+	assert.Contains(t, delivery.LastError, "503")
+
+	// The outbox backs off; make the retry due now
+	require.NoError(t, db.Model(&models.OutboxEvent{}).
+		Where("event_type = ?", OutboxEventWebhookDelivery).
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+	dispatchAll(t, outbox)
+
+	require.NoError(t, db.First(&delivery, delivery.ID).Error)
+	assert.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Empty(t, delivery.LastError)
+	assert.Len(t, receiver.requests, 2)
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	receiver := &webhookReceiver{}
+	db, service, outbox, bus, _, _ := setupWebhookTest(t, receiver)
+
+	bus.Publish(events.DeliveryCompleted{DeliveryRecordID: 7, SchoolID: 3})
+
+	var delivery models.WebhookDelivery
+	dispatchAll(t, outbox)
+	require.NoError(t, db.First(&delivery).Error)
+	require.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+
+	redelivered, err := service.Redeliver(delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, redelivered.Status)
+
+	// A pending delivery cannot be queued twice
+	_, err = service.Redeliver(delivery.ID)
+	assert.ErrorIs(t, err, ErrWebhookDeliveryInProgress)
+
+	dispatchAll(t, outbox)
+	require.NoError(t, db.First(&delivery, delivery.ID).Error)
+	assert.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	require.Len(t, receiver.requests, 2)
+	assert.Equal(t, receiver.requests[0].Header.Get(WebhookDeliveryHeader), receiver.requests[1].Header.Get(WebhookDeliveryHeader))
+
+	_, err = service.Redeliver(999)
+	assert.ErrorIs(t, err, ErrWebhookDeliveryNotFound)
+}
+
+func TestWebhookService_ValidatesSubscriptions(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.WebhookSubscription{}, &models.WebhookDelivery{}))
+	service := NewWebhookService(db)
+
+	_, _, err := service.CreateSubscription(WebhookSubscriptionInput{
+		Name: "Partner", TargetURL: "ftp://partner.example", EventTypes: []string{events.DeliveryCompletedEvent},
+	}, 1)
+	assert.ErrorIs(t, err, ErrWebhookInvalidURL)
+
+	_, _, err = service.CreateSubscription(WebhookSubscriptionInput{
+		Name: "Partner", TargetURL: "https://partner.example/hook", EventTypes: []string{events.CookingStartedEvent},
+	}, 1)
+	assert.ErrorIs(t, err, ErrWebhookInvalidEventType)
+
+	subscription, _, err := service.CreateSubscription(WebhookSubscriptionInput{
+		Name:       "Partner",
+		TargetURL:  "https://partner.example/hook",
+		EventTypes: []string{events.DeliveryCompletedEvent, events.DeliveryCompletedEvent, events.StokOpnameApprovedEvent},
+		Secret:     "rahasia-partner-1234",
+	}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, events.DeliveryCompletedEvent+","+events.StokOpnameApprovedEvent, subscription.EventTypes)
+	assert.True(t, subscription.IsActive)
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Backend names accepted by STORAGE_BACKEND
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// FilesPath is the public endpoint serving signed file URLs. Stored file
+// references are this path followed by the object key.
+const FilesPath = "/api/v1/files/"
+
+// legacyUploadsPath is the prefix of references saved before the blob store,
+// when uploads were served straight from the ./uploads directory
+const legacyUploadsPath = "/uploads/"
+
+// Storage errors
+var (
+	ErrNotFound         = errors.New("berkas tidak ditemukan")
+	ErrInvalidKey       = errors.New("kunci berkas tidak valid")
+	ErrInvalidSignature = errors.New("tautan berkas tidak valid atau sudah kedaluwarsa")
+)
+
+// BlobStore stores uploaded files by key, a slash separated path such as
+// epod/epod-photo-12-1700000000.jpg
+type BlobStore interface {
+	// Put stores size bytes read from r under key
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open reads the object stored under key; ErrNotFound when there is none
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that serves the object without further
+	// authentication until ttl has passed
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// ValidKey reports whether key is a clean relative path that cannot escape
+// the store, e.g. no "..", no leading slash and no empty segments
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	if path.Clean(key) != key {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// RefForKey returns the reference saved in the database for a stored object
+func RefForKey(key string) string {
+	return FilesPath + key
+}
+
+// KeyFromRef extracts the object key from a stored reference. References
+// saved before the blob store (/uploads/...) map to the same keys, since the
+// local store keeps its files in the old uploads directory.
+func KeyFromRef(ref string) (string, bool) {
+	// Signed URLs carry a query string, only the path is the reference
+	if i := strings.IndexAny(ref, "?#"); i >= 0 {
+		ref = ref[:i]
+	}
+
+	var key string
+	switch {
+	case strings.HasPrefix(ref, FilesPath):
+		key = strings.TrimPrefix(ref, FilesPath)
+	case strings.HasPrefix(ref, legacyUploadsPath):
+		key = strings.TrimPrefix(ref, legacyUploadsPath)
+	default:
+		return "", false
+	}
+	return key, ValidKey(key)
+}
+
+// escapeKey escapes every segment of a key for use in a URL path
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
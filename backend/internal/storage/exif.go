@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	jpegExifHeader = []byte("Exif\x00\x00")
+	jpegXMPHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	pngSignature   = []byte("\x89PNG\r\n\x1a\n")
+)
+
+const exifGPSInfoTag = 0x8825
+
+// StripGPS removes the GPS location from JPEG and PNG images. Other content
+// types are returned unchanged.
+func StripGPS(data []byte, contentType string) []byte {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEGGPS(data)
+	case "image/png":
+		return stripPNGExif(data)
+	}
+	return data
+}
+
+// stripJPEGGPS clears the GPS directory of the EXIF segment and drops XMP
+// segments, which can repeat the location. A malformed EXIF segment is
+// dropped as a whole.
+func stripJPEGGPS(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return data
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			break
+		}
+		marker := data[pos+1]
+		// Start of scan: the rest is image data
+		if marker == 0xDA {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+		segment := data[pos:end]
+		payload := segment[4:]
+
+		if marker == 0xE1 {
+			switch {
+			case bytes.HasPrefix(payload, jpegExifHeader):
+				cleaned := append([]byte(nil), segment...)
+				if err := clearGPSIFD(cleaned[4+len(jpegExifHeader):]); err != nil {
+					pos = end
+					continue
+				}
+				out = append(out, cleaned...)
+				pos = end
+				continue
+			case bytes.HasPrefix(payload, jpegXMPHeader):
+				pos = end
+				continue
+			}
+		}
+
+		out = append(out, segment...)
+		pos = end
+	}
+	return append(out, data[pos:]...)
+}
+
+// clearGPSIFD zeroes the GPS directory of a TIFF structure in place, both
+// its entries and the values stored outside of them
+func clearGPSIFD(tiff []byte) error {
+	errMalformed := errors.New("malformed exif")
+	if len(tiff) < 8 {
+		return errMalformed
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return errMalformed
+	}
+
+	ifd0 := int(order.Uint32(tiff[4:8]))
+	entries, err := ifdEntries(tiff, ifd0, order)
+	if err != nil {
+		return err
+	}
+
+	gps := -1
+	for _, entry := range entries {
+		if order.Uint16(tiff[entry:]) == exifGPSInfoTag {
+			gps = int(order.Uint32(tiff[entry+8:]))
+		}
+	}
+	if gps < 0 {
+		return nil
+	}
+
+	gpsEntries, err := ifdEntries(tiff, gps, order)
+	if err != nil {
+		return err
+	}
+	for _, entry := range gpsEntries {
+		size := exifTypeSize(order.Uint16(tiff[entry+2:])) * int(order.Uint32(tiff[entry+4:]))
+		if size > 4 {
+			offset := int(order.Uint32(tiff[entry+8:]))
+			if offset >= 0 && size <= len(tiff) && offset <= len(tiff)-size {
+				clear(tiff[offset : offset+size])
+			}
+		}
+		clear(tiff[entry : entry+12])
+	}
+	// An empty directory; its next-directory offset now reads as zero too
+	order.PutUint16(tiff[gps:], 0)
+	return nil
+}
+
+// ifdEntries returns the offsets of the 12 byte entries of the directory at offset
+func ifdEntries(tiff []byte, offset int, order binary.ByteOrder) ([]int, error) {
+	if offset < 8 || offset+2 > len(tiff) {
+		return nil, errors.New("malformed exif directory")
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	if offset+2+count*12 > len(tiff) {
+		return nil, errors.New("malformed exif directory")
+	}
+	entries := make([]int, count)
+	for i := range entries {
+		entries[i] = offset + 2 + i*12
+	}
+	return entries, nil
+}
+
+func exifTypeSize(typ uint16) int {
+	switch typ {
+	case 1, 2, 6, 7:
+		return 1
+	case 3, 8:
+		return 2
+	case 4, 9, 11:
+		return 4
+	case 5, 10, 12:
+		return 8
+	}
+	return 0
+}
+
+// stripPNGExif drops the eXIf chunks, where PNG keeps EXIF data
+func stripPNGExif(data []byte) []byte {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return data
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	pos := len(pngSignature)
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			break
+		}
+		if string(data[pos+4:pos+8]) != "eXIf" {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	return append(out, data[pos:]...)
+}
@@ -0,0 +1,320 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Upload categories. The category is the first segment of the object key.
+const (
+	CategoryEPODPhoto         = "epod"
+	CategoryEPODSignature     = "signatures"
+	CategoryInvoice           = "invoices"
+	CategoryCooperationLetter = "cooperation-letters"
+	CategoryStagePhoto        = "stage-photos"
+	CategoryStageVideo        = "stage-videos"
+	CategoryRecipePhoto       = "recipes"
+	CategoryPettyCashReceipt  = "receipts"
+	CategoryReviewPhoto       = "reviews"
+)
+
+const (
+	thumbnailPrefix      = "thumbs/"
+	contentTypeDOC       = "application/msword"
+	contentTypeDOCX      = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	contentTypeQuickTime = "video/quicktime"
+	defaultSignedURLTTL  = 15 * time.Minute
+)
+
+// Upload errors
+var (
+	ErrFileTooLarge       = errors.New("ukuran file melebihi batas")
+	ErrFileTypeNotAllowed = errors.New("tipe file tidak diizinkan")
+	ErrUnknownCategory    = errors.New("kategori upload tidak dikenal")
+)
+
+// Policy limits what may be uploaded in a category
+type Policy struct {
+	MaxSize      int64
+	AllowedTypes []string
+	// Thumbnail stores a small JPEG preview next to uploaded images
+	Thumbnail bool
+}
+
+var imageTypes = []string{"image/jpeg", "image/png"}
+
+// Policies lists the upload rules of every category
+var Policies = map[string]Policy{
+	CategoryEPODPhoto:         {MaxSize: 5 << 20, AllowedTypes: imageTypes, Thumbnail: true},
+	CategoryEPODSignature:     {MaxSize: 2 << 20, AllowedTypes: imageTypes},
+	CategoryInvoice:           {MaxSize: 5 << 20, AllowedTypes: append([]string{"application/pdf"}, imageTypes...), Thumbnail: true},
+	CategoryCooperationLetter: {MaxSize: 5 << 20, AllowedTypes: append([]string{"application/pdf", contentTypeDOC, contentTypeDOCX}, imageTypes...)},
+	CategoryStagePhoto:        {MaxSize: 10 << 20, AllowedTypes: imageTypes, Thumbnail: true},
+	CategoryStageVideo:        {MaxSize: 50 << 20, AllowedTypes: []string{"video/mp4", "video/webm", contentTypeQuickTime}},
+	CategoryRecipePhoto:       {MaxSize: 2 << 20, AllowedTypes: imageTypes, Thumbnail: true},
+	CategoryPettyCashReceipt:  {MaxSize: 5 << 20, AllowedTypes: imageTypes, Thumbnail: true},
+	CategoryReviewPhoto:       {MaxSize: 5 << 20, AllowedTypes: imageTypes, Thumbnail: true},
+}
+
+// DefaultStripGPSCategories are the categories whose images lose their GPS
+// location on upload. ePOD photos and stage media keep it, their location is
+// evidence of where the delivery or activity happened.
+var DefaultStripGPSCategories = []string{
+	CategoryInvoice,
+	CategoryCooperationLetter,
+	CategoryRecipePhoto,
+	CategoryPettyCashReceipt,
+	CategoryReviewPhoto,
+}
+
+var extensions = map[string]string{
+	"image/jpeg":         ".jpg",
+	"image/png":          ".png",
+	"application/pdf":    ".pdf",
+	contentTypeDOC:       ".doc",
+	contentTypeDOCX:      ".docx",
+	"video/mp4":          ".mp4",
+	"video/webm":         ".webm",
+	contentTypeQuickTime: ".mov",
+}
+
+// StoredFile describes an uploaded file
+type StoredFile struct {
+	Key          string `json:"key"`
+	Ref          string `json:"ref"`
+	ThumbnailRef string `json:"thumbnail_ref,omitempty"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+}
+
+// SignedFile is a short-lived URL of a stored file
+type SignedFile struct {
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// AllowedFormats lists the accepted formats for messages, e.g. "JPG atau PNG"
+func (p Policy) AllowedFormats() string {
+	names := make([]string, 0, len(p.AllowedTypes))
+	for _, contentType := range p.AllowedTypes {
+		names = append(names, strings.ToUpper(strings.TrimPrefix(extensions[contentType], ".")))
+	}
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " atau " + names[len(names)-1]
+}
+
+// Uploader validates uploads against the policy of their category, prepares
+// them and puts them in the blob store
+type Uploader struct {
+	store    BlobStore
+	stripGPS map[string]bool
+	urlTTL   time.Duration
+}
+
+// NewUploader creates an uploader. Images in the stripGPS categories lose
+// their GPS location; signed URLs are valid for urlTTL.
+func NewUploader(store BlobStore, stripGPS []string, urlTTL time.Duration) *Uploader {
+	if urlTTL <= 0 {
+		urlTTL = defaultSignedURLTTL
+	}
+	categories := make(map[string]bool, len(stripGPS))
+	for _, category := range stripGPS {
+		categories[strings.TrimSpace(category)] = true
+	}
+	return &Uploader{store: store, stripGPS: categories, urlTTL: urlTTL}
+}
+
+// Store returns the underlying blob store
+func (u *Uploader) Store() BlobStore {
+	return u.store
+}
+
+// SaveMultipart validates and stores an uploaded form file
+func (u *Uploader) SaveMultipart(ctx context.Context, category, name string, header *multipart.FileHeader) (*StoredFile, error) {
+	policy, ok := Policies[category]
+	if !ok {
+		return nil, ErrUnknownCategory
+	}
+	// Reject by the declared size before reading anything
+	if header.Size > policy.MaxSize {
+		return nil, ErrFileTooLarge
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, policy.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	return u.Save(ctx, category, name, filepath.Ext(header.Filename), data)
+}
+
+// Save validates and stores data. The content type is sniffed from the data,
+// the original extension only tells DOCX apart from other ZIP files.
+func (u *Uploader) Save(ctx context.Context, category, name, originalExt string, data []byte) (*StoredFile, error) {
+	policy, ok := Policies[category]
+	if !ok {
+		return nil, ErrUnknownCategory
+	}
+	if int64(len(data)) > policy.MaxSize {
+		return nil, ErrFileTooLarge
+	}
+
+	contentType := DetectContentType(data, originalExt)
+	if !allowed(policy.AllowedTypes, contentType) {
+		return nil, ErrFileTypeNotAllowed
+	}
+
+	if u.stripGPS[category] {
+		data = StripGPS(data, contentType)
+	}
+
+	key := fmt.Sprintf("%s/%s-%d%s", category, sanitizeName(name), time.Now().UnixNano(), extensions[contentType])
+	if err := u.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+
+	stored := &StoredFile{
+		Key:         key,
+		Ref:         RefForKey(key),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+
+	if policy.Thumbnail && strings.HasPrefix(contentType, "image/") {
+		// A missing thumbnail only costs the preview, the upload itself succeeded
+		if thumb, err := Thumbnail(data); err != nil {
+			log.Printf("[STORAGE] thumbnail for %s failed: %v", key, err)
+		} else {
+			thumbKey := ThumbnailKey(key)
+			if err := u.store.Put(ctx, thumbKey, bytes.NewReader(thumb), int64(len(thumb)), "image/jpeg"); err != nil {
+				log.Printf("[STORAGE] storing thumbnail %s failed: %v", thumbKey, err)
+			} else {
+				stored.ThumbnailRef = RefForKey(thumbKey)
+			}
+		}
+	}
+
+	return stored, nil
+}
+
+// Delete removes a file of a category and its thumbnail. References that do
+// not point into the store are ignored, files of other categories rejected.
+func (u *Uploader) Delete(ctx context.Context, category, ref string) error {
+	key, ok := KeyFromRef(ref)
+	if !ok {
+		return nil
+	}
+	if !strings.HasPrefix(key, category+"/") {
+		return ErrInvalidKey
+	}
+	if err := u.store.Delete(ctx, key); err != nil {
+		return err
+	}
+	return u.store.Delete(ctx, ThumbnailKey(key))
+}
+
+// Open reads a stored file by its reference
+func (u *Uploader) Open(ctx context.Context, ref string) (io.ReadCloser, error) {
+	key, ok := KeyFromRef(ref)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	return u.store.Open(ctx, key)
+}
+
+// Sign returns a short-lived URL of a stored file
+func (u *Uploader) Sign(ctx context.Context, ref string) (*SignedFile, error) {
+	key, ok := KeyFromRef(ref)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	expiresAt := time.Now().Add(u.urlTTL)
+	signed, err := u.store.SignedURL(ctx, key, u.urlTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedFile{URL: signed, ExpiresAt: expiresAt}, nil
+}
+
+// SignWithThumbnail signs a file and, when given, its thumbnail
+func (u *Uploader) SignWithThumbnail(ctx context.Context, ref, thumbnailRef string) (*SignedFile, error) {
+	signed, err := u.Sign(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if thumbnailRef != "" {
+		thumb, err := u.Sign(ctx, thumbnailRef)
+		if err != nil {
+			return nil, err
+		}
+		signed.ThumbnailURL = thumb.URL
+	}
+	return signed, nil
+}
+
+// ThumbnailKey returns the key of the thumbnail of an object
+func ThumbnailKey(key string) string {
+	return thumbnailPrefix + strings.TrimSuffix(key, filepath.Ext(key)) + ".jpg"
+}
+
+// DetectContentType sniffs the content type of data. Word documents are
+// recognised by their signature, DOCX being a ZIP file only when the
+// original extension says so.
+func DetectContentType(data []byte, originalExt string) string {
+	if bytes.HasPrefix(data, []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")) {
+		return contentTypeDOC
+	}
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" && string(data[8:10]) == "qt" {
+		return contentTypeQuickTime
+	}
+
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	if contentType == "application/zip" && strings.EqualFold(originalExt, ".docx") {
+		return contentTypeDOCX
+	}
+	return contentType
+}
+
+func allowed(types []string, contentType string) bool {
+	for _, t := range types {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// sanitizeName keeps a key readable without letting user input shape it
+func sanitizeName(name string) string {
+	name = strings.Trim(unsafeNameChars.ReplaceAllString(name, "-"), "-")
+	if name == "" {
+		return "file"
+	}
+	if len(name) > 60 {
+		name = name[:60]
+	}
+	return name
+}
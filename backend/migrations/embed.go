@@ -0,0 +1,17 @@
+// Package migrations holds the versioned SQL migrations of each database
+// driver, embedded in the server binary. The loose .sql files next to this
+// file are the manual scripts used before versioned migrations.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// For returns the migrations of a database driver, "postgres" or "sqlite"
+func For(driver string) (fs.FS, error) {
+	return fs.Sub(files, driver)
+}
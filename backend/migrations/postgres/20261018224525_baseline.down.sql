@@ -0,0 +1,78 @@
+DROP TABLE IF EXISTS "webhook_deliveries" CASCADE;
+DROP TABLE IF EXISTS "webhook_subscriptions" CASCADE;
+DROP TABLE IF EXISTS "idempotency_keys" CASCADE;
+DROP TABLE IF EXISTS "sync_operations" CASCADE;
+DROP TABLE IF EXISTS "outbox_events" CASCADE;
+DROP TABLE IF EXISTS "notifications" CASCADE;
+DROP TABLE IF EXISTS "system_configs" CASCADE;
+DROP TABLE IF EXISTS "petty_cash_replenishments" CASCADE;
+DROP TABLE IF EXISTS "petty_cash_expenses" CASCADE;
+DROP TABLE IF EXISTS "petty_cash_funds" CASCADE;
+DROP TABLE IF EXISTS "bank_statement_lines" CASCADE;
+DROP TABLE IF EXISTS "bank_statements" CASCADE;
+DROP TABLE IF EXISTS "funding_tranche_schools" CASCADE;
+DROP TABLE IF EXISTS "funding_tranches" CASCADE;
+DROP TABLE IF EXISTS "supplier_payments" CASCADE;
+DROP TABLE IF EXISTS "supplier_invoice_items" CASCADE;
+DROP TABLE IF EXISTS "supplier_invoices" CASCADE;
+DROP TABLE IF EXISTS "journal_lines" CASCADE;
+DROP TABLE IF EXISTS "journal_entries" CASCADE;
+DROP TABLE IF EXISTS "accounts" CASCADE;
+DROP TABLE IF EXISTS "budget_alerts" CASCADE;
+DROP TABLE IF EXISTS "budget_targets" CASCADE;
+DROP TABLE IF EXISTS "cash_flow_entries" CASCADE;
+DROP TABLE IF EXISTS "depreciation_run_lines" CASCADE;
+DROP TABLE IF EXISTS "depreciation_runs" CASCADE;
+DROP TABLE IF EXISTS "asset_usage_logs" CASCADE;
+DROP TABLE IF EXISTS "asset_audit_items" CASCADE;
+DROP TABLE IF EXISTS "asset_audits" CASCADE;
+DROP TABLE IF EXISTS "maintenance_plans" CASCADE;
+DROP TABLE IF EXISTS "asset_maintenances" CASCADE;
+DROP TABLE IF EXISTS "kitchen_assets" CASCADE;
+DROP TABLE IF EXISTS "gps_configs" CASCADE;
+DROP TABLE IF EXISTS "wi_fi_configs" CASCADE;
+DROP TABLE IF EXISTS "attendances" CASCADE;
+DROP TABLE IF EXISTS "employees" CASCADE;
+DROP TABLE IF EXISTS "delivery_reviews" CASCADE;
+DROP TABLE IF EXISTS "pickup_tasks" CASCADE;
+DROP TABLE IF EXISTS "ompreng_cleanings" CASCADE;
+DROP TABLE IF EXISTS "status_transitions" CASCADE;
+DROP TABLE IF EXISTS "delivery_records" CASCADE;
+DROP TABLE IF EXISTS "ompreng_inventories" CASCADE;
+DROP TABLE IF EXISTS "ompreng_trackings" CASCADE;
+DROP TABLE IF EXISTS "electronic_pods" CASCADE;
+DROP TABLE IF EXISTS "delivery_menu_items" CASCADE;
+DROP TABLE IF EXISTS "delivery_tasks" CASCADE;
+DROP TABLE IF EXISTS "schools" CASCADE;
+DROP TABLE IF EXISTS "stok_opname_items" CASCADE;
+DROP TABLE IF EXISTS "stok_opname_forms" CASCADE;
+DROP TABLE IF EXISTS "inventory_movements" CASCADE;
+DROP TABLE IF EXISTS "inventory_items" CASCADE;
+DROP TABLE IF EXISTS "goods_receipt_items" CASCADE;
+DROP TABLE IF EXISTS "goods_receipts" CASCADE;
+DROP TABLE IF EXISTS "purchase_order_items" CASCADE;
+DROP TABLE IF EXISTS "purchase_orders" CASCADE;
+DROP TABLE IF EXISTS "suppliers" CASCADE;
+DROP TABLE IF EXISTS "menu_item_school_allocations" CASCADE;
+DROP TABLE IF EXISTS "menu_items" CASCADE;
+DROP TABLE IF EXISTS "menu_plans" CASCADE;
+DROP TABLE IF EXISTS "recipe_versions" CASCADE;
+DROP TABLE IF EXISTS "recipe_items" CASCADE;
+DROP TABLE IF EXISTS "recipes" CASCADE;
+DROP TABLE IF EXISTS "semi_finished_movements" CASCADE;
+DROP TABLE IF EXISTS "semi_finished_production_logs" CASCADE;
+DROP TABLE IF EXISTS "semi_finished_inventories" CASCADE;
+DROP TABLE IF EXISTS "semi_finished_recipe_ingredients" CASCADE;
+DROP TABLE IF EXISTS "semi_finished_recipes" CASCADE;
+DROP TABLE IF EXISTS "semi_finished_goods" CASCADE;
+DROP TABLE IF EXISTS "ingredients" CASCADE;
+DROP TABLE IF EXISTS "user_permission_overrides" CASCADE;
+DROP TABLE IF EXISTS "role_permissions" CASCADE;
+DROP TABLE IF EXISTS "permissions" CASCADE;
+DROP TABLE IF EXISTS "roles" CASCADE;
+DROP TABLE IF EXISTS "two_factor_recovery_codes" CASCADE;
+DROP TABLE IF EXISTS "refresh_tokens" CASCADE;
+DROP TABLE IF EXISTS "user_sessions" CASCADE;
+DROP TABLE IF EXISTS "password_histories" CASCADE;
+DROP TABLE IF EXISTS "audit_trails" CASCADE;
+DROP TABLE IF EXISTS "users" CASCADE;
@@ -0,0 +1,443 @@
+CREATE TABLE "users" ("id" bigserial,"nik" varchar(20) NOT NULL,"email" varchar(100) NOT NULL,"password_hash" varchar(255) NOT NULL,"full_name" varchar(100) NOT NULL,"phone_number" varchar(20),"role" varchar(50) NOT NULL,"is_active" boolean DEFAULT true,"must_change_password" boolean DEFAULT false,"password_changed_at" timestamptz,"failed_login_attempts" bigint DEFAULT 0,"locked_until" timestamptz,"two_factor_enabled" boolean DEFAULT false,"two_factor_secret" varchar(64),"two_factor_last_step" bigint DEFAULT 0,"two_factor_enabled_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_users_is_active" ON "users" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_users_role" ON "users" ("role");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_nik" ON "users" ("nik");
+CREATE TABLE "audit_trails" ("id" bigserial,"user_id" bigint NOT NULL,"timestamp" timestamptz NOT NULL,"action" varchar(50) NOT NULL,"entity" varchar(100) NOT NULL,"entity_id" varchar(100),"old_value" text,"new_value" text,"ip_address" varchar(45),PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_audit_trails_action" ON "audit_trails" ("action");
+CREATE INDEX IF NOT EXISTS "idx_audit_trails_entity" ON "audit_trails" ("entity");
+CREATE INDEX IF NOT EXISTS "idx_audit_trails_timestamp" ON "audit_trails" ("timestamp");
+CREATE INDEX IF NOT EXISTS "idx_audit_trails_user_id" ON "audit_trails" ("user_id");
+CREATE TABLE "password_histories" ("id" bigserial,"user_id" bigint NOT NULL,"password_hash" varchar(255) NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_password_histories_created_at" ON "password_histories" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_password_histories_user_id" ON "password_histories" ("user_id");
+CREATE TABLE "user_sessions" ("id" bigserial,"session_id" varchar(64) NOT NULL,"user_id" bigint NOT NULL,"user_agent" varchar(255),"device_name" varchar(100),"ip_address" varchar(45),"last_activity_at" timestamptz NOT NULL,"expires_at" timestamptz NOT NULL,"revoked_at" timestamptz,"revoked_reason" varchar(50),"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_user_sessions_expires_at" ON "user_sessions" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_user_sessions_revoked_at" ON "user_sessions" ("revoked_at");
+CREATE INDEX IF NOT EXISTS "idx_user_sessions_user_id" ON "user_sessions" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_sessions_session_id" ON "user_sessions" ("session_id");
+CREATE TABLE "refresh_tokens" ("id" bigserial,"user_session_id" bigint NOT NULL,"token_hash" varchar(64) NOT NULL,"expires_at" timestamptz NOT NULL,"used_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_session_id" ON "refresh_tokens" ("user_session_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_token_hash" ON "refresh_tokens" ("token_hash");
+CREATE TABLE "two_factor_recovery_codes" ("id" bigserial,"user_id" bigint NOT NULL,"code_hash" varchar(64) NOT NULL,"used_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_two_factor_recovery_codes_code_hash" ON "two_factor_recovery_codes" ("code_hash");
+CREATE INDEX IF NOT EXISTS "idx_two_factor_recovery_codes_user_id" ON "two_factor_recovery_codes" ("user_id");
+CREATE TABLE "roles" ("id" bigserial,"name" varchar(50) NOT NULL,"display_name" varchar(100) NOT NULL,"description" text,"is_system" boolean DEFAULT false,"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_roles_is_active" ON "roles" ("is_active");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_roles_name" ON "roles" ("name");
+CREATE TABLE "permissions" ("id" bigserial,"code" varchar(100) NOT NULL,"category" varchar(20) NOT NULL,"description" varchar(255),"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_permissions_category" ON "permissions" ("category");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_permissions_code" ON "permissions" ("code");
+CREATE TABLE "role_permissions" ("id" bigserial,"role_id" bigint NOT NULL,"permission_id" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_role_permission" ON "role_permissions" ("role_id","permission_id");
+CREATE TABLE "user_permission_overrides" ("id" bigserial,"user_id" bigint NOT NULL,"permission_id" bigint NOT NULL,"granted" boolean NOT NULL,"reason" varchar(255),"created_by" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_permission_override" ON "user_permission_overrides" ("user_id","permission_id");
+CREATE TABLE "ingredients" ("id" bigserial,"code" varchar(20) DEFAULT '',"name" varchar(100) NOT NULL,"category" varchar(50),"unit" varchar(20) NOT NULL,"calories_per100g" decimal DEFAULT 0,"protein_per100g" decimal DEFAULT 0,"carbs_per100g" decimal DEFAULT 0,"fat_per100g" decimal DEFAULT 0,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_ingredients_category" ON "ingredients" ("category");
+CREATE INDEX IF NOT EXISTS "idx_ingredients_code" ON "ingredients" ("code");
+CREATE INDEX IF NOT EXISTS "idx_ingredients_name" ON "ingredients" ("name");
+CREATE TABLE "semi_finished_goods" ("id" bigserial,"name" varchar(100) NOT NULL,"unit" varchar(20) NOT NULL,"category" varchar(50),"description" text,"calories_per100g" decimal NOT NULL,"protein_per100g" decimal NOT NULL,"carbs_per100g" decimal NOT NULL,"fat_per100g" decimal NOT NULL,"quantity_per_portion_small" decimal DEFAULT 0,"quantity_per_portion_large" decimal DEFAULT 0,"stock_quantity" decimal DEFAULT 0,"min_threshold" decimal DEFAULT 10,"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_goods_category" ON "semi_finished_goods" ("category");
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_goods_is_active" ON "semi_finished_goods" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_goods_name" ON "semi_finished_goods" ("name");
+CREATE TABLE "semi_finished_recipes" ("id" bigserial,"semi_finished_goods_id" bigint NOT NULL,"name" varchar(200) NOT NULL,"instructions" text,"yield_amount" decimal NOT NULL,"is_active" boolean DEFAULT true,"created_by" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_semi_finished_recipes_semi_finished_goods_id" ON "semi_finished_recipes" ("semi_finished_goods_id");
+CREATE TABLE "semi_finished_recipe_ingredients" ("id" bigserial,"semi_finished_recipe_id" bigint NOT NULL,"ingredient_id" bigint NOT NULL,"quantity" decimal NOT NULL,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_recipe_ingredients_ingredient_id" ON "semi_finished_recipe_ingredients" ("ingredient_id");
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_recipe_ingredients_semi_finished_recipe_id" ON "semi_finished_recipe_ingredients" ("semi_finished_recipe_id");
+CREATE TABLE "semi_finished_inventories" ("id" bigserial,"semi_finished_goods_id" bigint NOT NULL,"quantity" decimal NOT NULL,"min_threshold" decimal NOT NULL,"last_updated" timestamptz NOT NULL,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_inventories_last_updated" ON "semi_finished_inventories" ("last_updated");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_semi_finished_inventories_semi_finished_goods_id" ON "semi_finished_inventories" ("semi_finished_goods_id");
+CREATE TABLE "semi_finished_production_logs" ("id" bigserial,"semi_finished_goods_id" bigint NOT NULL,"quantity" decimal NOT NULL,"production_date" timestamptz NOT NULL,"created_by" bigint NOT NULL,"notes" text,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_production_logs_production_date" ON "semi_finished_production_logs" ("production_date");
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_production_logs_semi_finished_goods_id" ON "semi_finished_production_logs" ("semi_finished_goods_id");
+CREATE TABLE "semi_finished_movements" ("id" bigserial,"semi_finished_goods_id" bigint NOT NULL,"movement_type" varchar(20) NOT NULL,"quantity" decimal NOT NULL,"reference" varchar(100),"movement_date" timestamptz NOT NULL,"created_by" bigint NOT NULL,"notes" text,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_movements_created_by" ON "semi_finished_movements" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_movements_movement_date" ON "semi_finished_movements" ("movement_date");
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_movements_movement_type" ON "semi_finished_movements" ("movement_type");
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_movements_reference" ON "semi_finished_movements" ("reference");
+CREATE INDEX IF NOT EXISTS "idx_semi_finished_movements_semi_finished_goods_id" ON "semi_finished_movements" ("semi_finished_goods_id");
+CREATE TABLE "recipes" ("id" bigserial,"name" varchar(200) NOT NULL,"category" varchar(50),"photo_url" varchar(500),"instructions" text,"total_calories" decimal NOT NULL,"total_protein" decimal NOT NULL,"total_carbs" decimal NOT NULL,"total_fat" decimal NOT NULL,"version" bigint NOT NULL DEFAULT 1,"is_active" boolean DEFAULT true,"created_by" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_recipes_category" ON "recipes" ("category");
+CREATE INDEX IF NOT EXISTS "idx_recipes_created_by" ON "recipes" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_recipes_is_active" ON "recipes" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_recipes_name" ON "recipes" ("name");
+CREATE TABLE "recipe_items" ("id" bigserial,"recipe_id" bigint NOT NULL,"semi_finished_goods_id" bigint NOT NULL,"quantity" decimal NOT NULL,"quantity_per_portion_small" decimal DEFAULT 0,"quantity_per_portion_large" decimal DEFAULT 0,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_recipe_items_recipe_id" ON "recipe_items" ("recipe_id");
+CREATE INDEX IF NOT EXISTS "idx_recipe_items_semi_finished_goods_id" ON "recipe_items" ("semi_finished_goods_id");
+CREATE TABLE "recipe_versions" ("id" bigserial,"recipe_id" bigint NOT NULL,"version" bigint NOT NULL,"name" varchar(200) NOT NULL,"category" varchar(50),"photo_url" varchar(500),"instructions" text,"total_calories" decimal NOT NULL,"total_protein" decimal NOT NULL,"total_carbs" decimal NOT NULL,"total_fat" decimal NOT NULL,"changes" text,"created_by" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_recipe_versions_recipe_id" ON "recipe_versions" ("recipe_id");
+CREATE INDEX IF NOT EXISTS "idx_recipe_versions_version" ON "recipe_versions" ("version");
+CREATE TABLE "menu_plans" ("id" bigserial,"week_start" timestamptz NOT NULL,"week_end" timestamptz NOT NULL,"status" varchar(20) NOT NULL,"approved_by" bigint,"approved_at" timestamptz,"created_by" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_menu_plans_approved_by" ON "menu_plans" ("approved_by");
+CREATE INDEX IF NOT EXISTS "idx_menu_plans_created_by" ON "menu_plans" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_menu_plans_status" ON "menu_plans" ("status");
+CREATE INDEX IF NOT EXISTS "idx_menu_plans_week_start" ON "menu_plans" ("week_start");
+CREATE TABLE "menu_items" ("id" bigserial,"menu_plan_id" bigint NOT NULL,"date" timestamptz NOT NULL,"recipe_id" bigint NOT NULL,"portions" bigint NOT NULL,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_menu_items_date" ON "menu_items" ("date");
+CREATE INDEX IF NOT EXISTS "idx_menu_items_menu_plan_id" ON "menu_items" ("menu_plan_id");
+CREATE INDEX IF NOT EXISTS "idx_menu_items_recipe_id" ON "menu_items" ("recipe_id");
+CREATE TABLE "menu_item_school_allocations" ("id" bigserial,"menu_item_id" bigint NOT NULL,"school_id" bigint NOT NULL,"portions" bigint NOT NULL,"portion_size" varchar(10) NOT NULL,"date" timestamptz NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "chk_menu_item_school_allocations_portions" CHECK (portions > 0),CONSTRAINT "chk_menu_item_school_allocations_portion_size" CHECK (portion_size IN ('small', 'large')));
+CREATE INDEX IF NOT EXISTS "idx_menu_item_school_allocations_date" ON "menu_item_school_allocations" ("date");
+CREATE INDEX IF NOT EXISTS "idx_menu_item_school_allocations_menu_item_id" ON "menu_item_school_allocations" ("menu_item_id");
+CREATE INDEX IF NOT EXISTS "idx_menu_item_school_allocations_school_id" ON "menu_item_school_allocations" ("school_id");
+CREATE TABLE "suppliers" ("id" bigserial,"name" varchar(200) NOT NULL,"contact_person" varchar(100),"phone_number" varchar(20),"email" varchar(100),"address" text,"product_category" varchar(100),"is_active" boolean DEFAULT true,"on_time_delivery" decimal DEFAULT 0,"quality_rating" decimal DEFAULT 0,"payment_term_days" bigint DEFAULT 30,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_suppliers_is_active" ON "suppliers" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_suppliers_name" ON "suppliers" ("name");
+CREATE INDEX IF NOT EXISTS "idx_suppliers_product_category" ON "suppliers" ("product_category");
+CREATE TABLE "purchase_orders" ("id" bigserial,"po_number" varchar(50) NOT NULL,"supplier_id" bigint NOT NULL,"order_date" timestamptz NOT NULL,"expected_delivery" timestamptz,"status" varchar(20) NOT NULL,"total_amount" bigint NOT NULL,"approved_by" bigint,"approved_at" timestamptz,"created_by" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_approved_by" ON "purchase_orders" ("approved_by");
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_created_by" ON "purchase_orders" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_expected_delivery" ON "purchase_orders" ("expected_delivery");
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_order_date" ON "purchase_orders" ("order_date");
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_status" ON "purchase_orders" ("status");
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_supplier_id" ON "purchase_orders" ("supplier_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_purchase_orders_po_number" ON "purchase_orders" ("po_number");
+CREATE TABLE "purchase_order_items" ("id" bigserial,"po_id" bigint NOT NULL,"ingredient_id" bigint NOT NULL,"quantity" decimal NOT NULL,"unit_price" bigint NOT NULL,"subtotal" bigint NOT NULL,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_purchase_order_items_ingredient_id" ON "purchase_order_items" ("ingredient_id");
+CREATE INDEX IF NOT EXISTS "idx_purchase_order_items_po_id" ON "purchase_order_items" ("po_id");
+CREATE TABLE "goods_receipts" ("id" bigserial,"grn_number" varchar(50) NOT NULL,"po_id" bigint NOT NULL,"receipt_date" timestamptz NOT NULL,"invoice_photo" varchar(500),"received_by" bigint NOT NULL,"notes" text,"quality_rating" decimal DEFAULT 0,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_goods_receipts_po_id" ON "goods_receipts" ("po_id");
+CREATE INDEX IF NOT EXISTS "idx_goods_receipts_receipt_date" ON "goods_receipts" ("receipt_date");
+CREATE INDEX IF NOT EXISTS "idx_goods_receipts_received_by" ON "goods_receipts" ("received_by");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_goods_receipts_grn_number" ON "goods_receipts" ("grn_number");
+CREATE TABLE "goods_receipt_items" ("id" bigserial,"grn_id" bigint NOT NULL,"ingredient_id" bigint NOT NULL,"ordered_quantity" decimal NOT NULL,"received_quantity" decimal NOT NULL,"expiry_date" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_goods_receipt_items_expiry_date" ON "goods_receipt_items" ("expiry_date");
+CREATE INDEX IF NOT EXISTS "idx_goods_receipt_items_grn_id" ON "goods_receipt_items" ("grn_id");
+CREATE INDEX IF NOT EXISTS "idx_goods_receipt_items_ingredient_id" ON "goods_receipt_items" ("ingredient_id");
+CREATE TABLE "inventory_items" ("id" bigserial,"ingredient_id" bigint NOT NULL,"quantity" decimal NOT NULL,"min_threshold" decimal NOT NULL,"last_updated" timestamptz NOT NULL,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_inventory_items_last_updated" ON "inventory_items" ("last_updated");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_inventory_items_ingredient_id" ON "inventory_items" ("ingredient_id");
+CREATE TABLE "inventory_movements" ("id" bigserial,"ingredient_id" bigint NOT NULL,"movement_type" varchar(20) NOT NULL,"quantity" decimal NOT NULL,"reference" varchar(100),"movement_date" timestamptz NOT NULL,"created_by" bigint NOT NULL,"notes" text,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_inventory_movements_created_by" ON "inventory_movements" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_inventory_movements_ingredient_id" ON "inventory_movements" ("ingredient_id");
+CREATE INDEX IF NOT EXISTS "idx_inventory_movements_movement_date" ON "inventory_movements" ("movement_date");
+CREATE INDEX IF NOT EXISTS "idx_inventory_movements_movement_type" ON "inventory_movements" ("movement_type");
+CREATE INDEX IF NOT EXISTS "idx_inventory_movements_reference" ON "inventory_movements" ("reference");
+CREATE TABLE "stok_opname_forms" ("id" bigserial,"form_number" varchar(50) NOT NULL,"created_by" bigint NOT NULL,"created_at" timestamptz NOT NULL,"status" varchar(20) NOT NULL,"notes" text,"approved_by" bigint,"approved_at" timestamptz,"rejection_reason" text,"is_processed" boolean DEFAULT false,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_stok_opname_forms_approved_by" ON "stok_opname_forms" ("approved_by");
+CREATE INDEX IF NOT EXISTS "idx_stok_opname_forms_created_at" ON "stok_opname_forms" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_stok_opname_forms_created_by" ON "stok_opname_forms" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_stok_opname_forms_is_processed" ON "stok_opname_forms" ("is_processed");
+CREATE INDEX IF NOT EXISTS "idx_stok_opname_forms_status" ON "stok_opname_forms" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_stok_opname_forms_form_number" ON "stok_opname_forms" ("form_number");
+CREATE TABLE "stok_opname_items" ("id" bigserial,"form_id" bigint NOT NULL,"ingredient_id" bigint NOT NULL,"system_stock" decimal NOT NULL,"physical_count" decimal NOT NULL,"difference" decimal NOT NULL,"item_notes" text,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_stok_opname_items_form_id" ON "stok_opname_items" ("form_id");
+CREATE INDEX IF NOT EXISTS "idx_stok_opname_items_ingredient_id" ON "stok_opname_items" ("ingredient_id");
+CREATE TABLE "schools" ("id" bigserial,"name" varchar(200) NOT NULL,"address" text,"latitude" decimal NOT NULL,"longitude" decimal NOT NULL,"contact_person" varchar(100),"phone_number" varchar(20),"student_count" bigint NOT NULL,"category" varchar(10),"student_count_grade13" bigint DEFAULT 0,"student_count_grade46" bigint DEFAULT 0,"staff_count" bigint DEFAULT 0,"npsn" varchar(50),"principal_name" varchar(255),"school_email" varchar(255),"school_phone" varchar(50),"committee_count" bigint DEFAULT 0,"cooperation_letter_url" varchar(500),"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_schools_is_active" ON "schools" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_schools_name" ON "schools" ("name");
+CREATE TABLE "delivery_tasks" ("id" bigserial,"task_date" timestamptz NOT NULL,"driver_id" bigint NOT NULL,"school_id" bigint NOT NULL,"portions" bigint NOT NULL,"status" varchar(20) NOT NULL,"current_stage" bigint NOT NULL DEFAULT 1,"route_order" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_delivery_tasks_current_stage" ON "delivery_tasks" ("current_stage");
+CREATE INDEX IF NOT EXISTS "idx_delivery_tasks_driver_id" ON "delivery_tasks" ("driver_id");
+CREATE INDEX IF NOT EXISTS "idx_delivery_tasks_school_id" ON "delivery_tasks" ("school_id");
+CREATE INDEX IF NOT EXISTS "idx_delivery_tasks_status" ON "delivery_tasks" ("status");
+CREATE INDEX IF NOT EXISTS "idx_delivery_tasks_task_date" ON "delivery_tasks" ("task_date");
+CREATE TABLE "delivery_menu_items" ("id" bigserial,"delivery_task_id" bigint NOT NULL,"recipe_id" bigint NOT NULL,"portions" bigint NOT NULL,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_delivery_menu_items_delivery_task_id" ON "delivery_menu_items" ("delivery_task_id");
+CREATE INDEX IF NOT EXISTS "idx_delivery_menu_items_recipe_id" ON "delivery_menu_items" ("recipe_id");
+CREATE TABLE "electronic_pods" ("id" bigserial,"delivery_task_id" bigint NOT NULL,"photo_url" varchar(500),"signature_url" varchar(500),"latitude" decimal NOT NULL,"longitude" decimal NOT NULL,"recipient_name" varchar(100),"ompreng_drop_off" bigint NOT NULL,"ompreng_pick_up" bigint NOT NULL,"completed_at" timestamptz NOT NULL,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_electronic_pods_completed_at" ON "electronic_pods" ("completed_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_electronic_pods_delivery_task_id" ON "electronic_pods" ("delivery_task_id");
+CREATE TABLE "ompreng_trackings" ("id" bigserial,"school_id" bigint NOT NULL,"date" timestamptz NOT NULL,"drop_off" bigint NOT NULL,"pick_up" bigint NOT NULL,"balance" bigint NOT NULL,"recorded_by" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_ompreng_trackings_date" ON "ompreng_trackings" ("date");
+CREATE INDEX IF NOT EXISTS "idx_ompreng_trackings_recorded_by" ON "ompreng_trackings" ("recorded_by");
+CREATE INDEX IF NOT EXISTS "idx_ompreng_trackings_school_id" ON "ompreng_trackings" ("school_id");
+CREATE TABLE "ompreng_inventories" ("id" bigserial,"total_owned" bigint NOT NULL,"at_kitchen" bigint NOT NULL,"in_circulation" bigint NOT NULL,"missing" bigint NOT NULL,"last_updated" timestamptz NOT NULL,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_ompreng_inventories_last_updated" ON "ompreng_inventories" ("last_updated");
+CREATE TABLE "delivery_records" ("id" bigserial,"delivery_date" timestamptz NOT NULL,"school_id" bigint NOT NULL,"driver_id" bigint,"menu_item_id" bigint NOT NULL,"portions" bigint NOT NULL,"portions_small" bigint NOT NULL DEFAULT 0,"portions_large" bigint NOT NULL DEFAULT 0,"current_status" varchar(50) NOT NULL,"current_stage" bigint NOT NULL DEFAULT 1,"ompreng_count" bigint NOT NULL,"ompreng_received" bigint,"ompreng_difference_reason" varchar(500),"pickup_task_id" bigint,"route_order" bigint DEFAULT 0,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_delivery_records_current_stage" ON "delivery_records" ("current_stage");
+CREATE INDEX IF NOT EXISTS "idx_delivery_records_current_status" ON "delivery_records" ("current_status");
+CREATE INDEX IF NOT EXISTS "idx_delivery_records_delivery_date" ON "delivery_records" ("delivery_date");
+CREATE INDEX IF NOT EXISTS "idx_delivery_records_driver_id" ON "delivery_records" ("driver_id");
+CREATE INDEX IF NOT EXISTS "idx_delivery_records_menu_item_id" ON "delivery_records" ("menu_item_id");
+CREATE INDEX IF NOT EXISTS "idx_delivery_records_pickup_task_id" ON "delivery_records" ("pickup_task_id");
+CREATE INDEX IF NOT EXISTS "idx_delivery_records_school_id" ON "delivery_records" ("school_id");
+CREATE TABLE "status_transitions" ("id" bigserial,"delivery_record_id" bigint NOT NULL,"from_status" varchar(50),"to_status" varchar(50) NOT NULL,"stage" bigint NOT NULL,"transitioned_at" timestamptz NOT NULL,"transitioned_by" bigint NOT NULL,"notes" text,"media_url" varchar(500),"media_type" varchar(20),"thumbnail_url" varchar(500),PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_status_transitions_delivery_record_id" ON "status_transitions" ("delivery_record_id");
+CREATE INDEX IF NOT EXISTS "idx_status_transitions_stage" ON "status_transitions" ("stage");
+CREATE INDEX IF NOT EXISTS "idx_status_transitions_transitioned_at" ON "status_transitions" ("transitioned_at");
+CREATE INDEX IF NOT EXISTS "idx_status_transitions_transitioned_by" ON "status_transitions" ("transitioned_by");
+CREATE TABLE "ompreng_cleanings" ("id" bigserial,"delivery_record_id" bigint NOT NULL,"ompreng_count" bigint NOT NULL,"cleaning_status" varchar(30) NOT NULL,"started_at" timestamptz,"completed_at" timestamptz,"cleaned_by" bigint,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_ompreng_cleanings_cleaned_by" ON "ompreng_cleanings" ("cleaned_by");
+CREATE INDEX IF NOT EXISTS "idx_ompreng_cleanings_delivery_record_id" ON "ompreng_cleanings" ("delivery_record_id");
+CREATE TABLE "pickup_tasks" ("id" bigserial,"task_date" timestamptz NOT NULL,"driver_id" bigint NOT NULL,"status" varchar(20) NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_pickup_tasks_driver_id" ON "pickup_tasks" ("driver_id");
+CREATE INDEX IF NOT EXISTS "idx_pickup_tasks_status" ON "pickup_tasks" ("status");
+CREATE INDEX IF NOT EXISTS "idx_pickup_tasks_task_date" ON "pickup_tasks" ("task_date");
+CREATE TABLE "delivery_reviews" ("id" bigserial,"delivery_record_id" bigint NOT NULL,"school_id" bigint NOT NULL,"reviewer_name" varchar(100),"reviewer_role" varchar(50),"rating_food_taste" bigint NOT NULL,"rating_food_cleanliness" bigint NOT NULL,"rating_menu_accuracy" bigint NOT NULL,"rating_portion_size" bigint NOT NULL,"rating_menu_variety" bigint NOT NULL,"rating_delivery_time" bigint NOT NULL,"rating_driver_attitude" bigint NOT NULL,"rating_food_condition" bigint NOT NULL,"rating_driver_tidiness" bigint NOT NULL,"rating_service_consistency" bigint NOT NULL,"average_menu_rating" decimal NOT NULL,"average_service_rating" decimal NOT NULL,"overall_rating" decimal NOT NULL,"comments" text,"photo_url" varchar(500),"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_delivery_reviews_created_at" ON "delivery_reviews" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_delivery_reviews_school_id" ON "delivery_reviews" ("school_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_delivery_reviews_delivery_record_id" ON "delivery_reviews" ("delivery_record_id");
+CREATE TABLE "employees" ("id" bigserial,"user_id" bigint NOT NULL,"nik" varchar(20) NOT NULL,"full_name" varchar(100) NOT NULL,"email" varchar(100) NOT NULL,"phone_number" varchar(20),"position" varchar(100),"join_date" timestamptz NOT NULL,"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_employees_full_name" ON "employees" ("full_name");
+CREATE INDEX IF NOT EXISTS "idx_employees_is_active" ON "employees" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_employees_position" ON "employees" ("position");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_employees_email" ON "employees" ("email");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_employees_nik" ON "employees" ("nik");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_employees_user_id" ON "employees" ("user_id");
+CREATE TABLE "attendances" ("id" bigserial,"employee_id" bigint NOT NULL,"date" timestamptz NOT NULL,"check_in" timestamptz NOT NULL,"check_out" timestamptz,"work_hours" decimal DEFAULT 0,"ss_id" varchar(100),"bss_id" varchar(100),"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_attendances_date" ON "attendances" ("date");
+CREATE INDEX IF NOT EXISTS "idx_attendances_employee_id" ON "attendances" ("employee_id");
+CREATE TABLE "wi_fi_configs" ("id" bigserial,"ss_id" varchar(100) NOT NULL,"bss_id" varchar(100) NOT NULL,"location" varchar(200),"ip_range" varchar(100),"allowed_ips" text[],"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_wi_fi_configs_bss_id" ON "wi_fi_configs" ("bss_id");
+CREATE INDEX IF NOT EXISTS "idx_wi_fi_configs_is_active" ON "wi_fi_configs" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_wi_fi_configs_ss_id" ON "wi_fi_configs" ("ss_id");
+CREATE TABLE "gps_configs" ("id" bigserial,"name" varchar(100) NOT NULL,"latitude" decimal NOT NULL,"longitude" decimal NOT NULL,"radius" bigint NOT NULL DEFAULT 100,"address" varchar(500),"description" varchar(500),"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_gps_configs_is_active" ON "gps_configs" ("is_active");
+CREATE TABLE "kitchen_assets" ("id" bigserial,"asset_code" varchar(50) NOT NULL,"name" varchar(200) NOT NULL,"category" varchar(50),"purchase_date" timestamptz NOT NULL,"purchase_price" bigint NOT NULL,"current_value" bigint NOT NULL,"depreciation_method" varchar(30) NOT NULL DEFAULT 'straight_line',"depreciation_rate" decimal NOT NULL,"useful_life_months" bigint DEFAULT 0,"salvage_value" bigint DEFAULT 0,"total_units" decimal DEFAULT 0,"accumulated_depreciation" bigint DEFAULT 0,"condition" varchar(50),"location" varchar(100),"status" varchar(20) NOT NULL DEFAULT 'active',"disposal_date" timestamptz,"disposal_proceeds" bigint DEFAULT 0,"disposal_gain_loss" bigint DEFAULT 0,"disposal_notes" text,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_kitchen_assets_category" ON "kitchen_assets" ("category");
+CREATE INDEX IF NOT EXISTS "idx_kitchen_assets_condition" ON "kitchen_assets" ("condition");
+CREATE INDEX IF NOT EXISTS "idx_kitchen_assets_name" ON "kitchen_assets" ("name");
+CREATE INDEX IF NOT EXISTS "idx_kitchen_assets_purchase_date" ON "kitchen_assets" ("purchase_date");
+CREATE INDEX IF NOT EXISTS "idx_kitchen_assets_status" ON "kitchen_assets" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_kitchen_assets_asset_code" ON "kitchen_assets" ("asset_code");
+CREATE TABLE "asset_maintenances" ("id" bigserial,"asset_id" bigint NOT NULL,"maintenance_date" timestamptz NOT NULL,"description" text,"cost" bigint NOT NULL,"performed_by" varchar(100),"plan_id" bigint,"due_date" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_asset_maintenances_asset_id" ON "asset_maintenances" ("asset_id");
+CREATE INDEX IF NOT EXISTS "idx_asset_maintenances_maintenance_date" ON "asset_maintenances" ("maintenance_date");
+CREATE INDEX IF NOT EXISTS "idx_asset_maintenances_plan_id" ON "asset_maintenances" ("plan_id");
+CREATE TABLE "maintenance_plans" ("id" bigserial,"asset_id" bigint NOT NULL,"name" varchar(200) NOT NULL,"description" text,"interval_type" varchar(20) NOT NULL,"interval_days" bigint DEFAULT 0,"interval_units" decimal DEFAULT 0,"is_safety_critical" boolean DEFAULT false,"estimated_cost" bigint DEFAULT 0,"responsible_user_id" bigint NOT NULL,"start_date" timestamptz NOT NULL,"last_completed_at" timestamptz,"last_completed_units" decimal DEFAULT 0,"next_due_date" timestamptz,"last_reminded_at" timestamptz,"is_active" boolean DEFAULT true,"created_by" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_maintenance_plans_asset_id" ON "maintenance_plans" ("asset_id");
+CREATE INDEX IF NOT EXISTS "idx_maintenance_plans_created_by" ON "maintenance_plans" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_maintenance_plans_is_active" ON "maintenance_plans" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_maintenance_plans_is_safety_critical" ON "maintenance_plans" ("is_safety_critical");
+CREATE INDEX IF NOT EXISTS "idx_maintenance_plans_next_due_date" ON "maintenance_plans" ("next_due_date");
+CREATE INDEX IF NOT EXISTS "idx_maintenance_plans_responsible_user_id" ON "maintenance_plans" ("responsible_user_id");
+CREATE TABLE "asset_audits" ("id" bigserial,"audit_number" varchar(50) NOT NULL,"location" varchar(100),"status" varchar(20) NOT NULL,"notes" text,"created_by" bigint NOT NULL,"submitted_at" timestamptz,"approved_by" bigint,"approved_at" timestamptz,"rejection_reason" text,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_asset_audits_approved_by" ON "asset_audits" ("approved_by");
+CREATE INDEX IF NOT EXISTS "idx_asset_audits_created_at" ON "asset_audits" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_asset_audits_created_by" ON "asset_audits" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_asset_audits_status" ON "asset_audits" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_asset_audits_audit_number" ON "asset_audits" ("audit_number");
+CREATE TABLE "asset_audit_items" ("id" bigserial,"audit_id" bigint NOT NULL,"asset_id" bigint NOT NULL,"expected_location" varchar(100),"expected_condition" varchar(50),"found_location" varchar(100),"found_condition" varchar(50),"result" varchar(20) NOT NULL,"scanned_by" bigint,"scanned_at" timestamptz,"notes" text,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_asset_audit_items_asset_id" ON "asset_audit_items" ("asset_id");
+CREATE INDEX IF NOT EXISTS "idx_asset_audit_items_audit_id" ON "asset_audit_items" ("audit_id");
+CREATE INDEX IF NOT EXISTS "idx_asset_audit_items_result" ON "asset_audit_items" ("result");
+CREATE TABLE "asset_usage_logs" ("id" bigserial,"asset_id" bigint NOT NULL,"usage_date" timestamptz NOT NULL,"units" decimal NOT NULL,"notes" text,"created_by" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_asset_usage_logs_asset_id" ON "asset_usage_logs" ("asset_id");
+CREATE INDEX IF NOT EXISTS "idx_asset_usage_logs_created_by" ON "asset_usage_logs" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_asset_usage_logs_usage_date" ON "asset_usage_logs" ("usage_date");
+CREATE TABLE "depreciation_runs" ("id" bigserial,"year" bigint NOT NULL,"month" bigint NOT NULL,"status" varchar(20) NOT NULL,"asset_count" bigint NOT NULL,"total_amount" bigint NOT NULL,"posted_by" bigint,"reversed_by" bigint,"reversed_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_depreciation_run_period" ON "depreciation_runs" ("year","month");
+CREATE INDEX IF NOT EXISTS "idx_depreciation_runs_posted_by" ON "depreciation_runs" ("posted_by");
+CREATE INDEX IF NOT EXISTS "idx_depreciation_runs_status" ON "depreciation_runs" ("status");
+CREATE TABLE "depreciation_run_lines" ("id" bigserial,"run_id" bigint NOT NULL,"asset_id" bigint NOT NULL,"method" varchar(30) NOT NULL,"amount" bigint NOT NULL,"units_used" decimal DEFAULT 0,"book_value_before" bigint NOT NULL,"book_value_after" bigint NOT NULL,"journal_entry_id" bigint,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_depreciation_run_lines_asset_id" ON "depreciation_run_lines" ("asset_id");
+CREATE INDEX IF NOT EXISTS "idx_depreciation_run_lines_journal_entry_id" ON "depreciation_run_lines" ("journal_entry_id");
+CREATE INDEX IF NOT EXISTS "idx_depreciation_run_lines_run_id" ON "depreciation_run_lines" ("run_id");
+CREATE TABLE "cash_flow_entries" ("id" bigserial,"transaction_id" varchar(50) NOT NULL,"date" timestamptz NOT NULL,"category" varchar(50) NOT NULL,"type" varchar(20) NOT NULL,"amount" bigint NOT NULL,"description" text,"reference" varchar(100),"is_payable" boolean DEFAULT false,"funding_tranche_id" bigint,"created_by" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_cash_flow_entries_category" ON "cash_flow_entries" ("category");
+CREATE INDEX IF NOT EXISTS "idx_cash_flow_entries_created_by" ON "cash_flow_entries" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_cash_flow_entries_date" ON "cash_flow_entries" ("date");
+CREATE INDEX IF NOT EXISTS "idx_cash_flow_entries_funding_tranche_id" ON "cash_flow_entries" ("funding_tranche_id");
+CREATE INDEX IF NOT EXISTS "idx_cash_flow_entries_reference" ON "cash_flow_entries" ("reference");
+CREATE INDEX IF NOT EXISTS "idx_cash_flow_entries_type" ON "cash_flow_entries" ("type");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cash_flow_entries_transaction_id" ON "cash_flow_entries" ("transaction_id");
+CREATE TABLE "budget_targets" ("id" bigserial,"year" bigint NOT NULL,"month" bigint NOT NULL,"category" varchar(50) NOT NULL,"target" bigint NOT NULL,"actual" bigint DEFAULT 0,"notes" text,"created_by" bigint,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_budget_targets_category" ON "budget_targets" ("category");
+CREATE INDEX IF NOT EXISTS "idx_budget_targets_created_by" ON "budget_targets" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_budget_targets_month" ON "budget_targets" ("month");
+CREATE INDEX IF NOT EXISTS "idx_budget_targets_year" ON "budget_targets" ("year");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_budget_period" ON "budget_targets" ("year","month","category");
+CREATE TABLE "budget_alerts" ("id" bigserial,"budget_target_id" bigint NOT NULL,"threshold" bigint NOT NULL,"actual" bigint,"target" bigint,"absorption_rate" decimal,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_budget_alert" ON "budget_alerts" ("budget_target_id","threshold");
+CREATE TABLE "accounts" ("id" bigserial,"code" varchar(20) NOT NULL,"name" varchar(150) NOT NULL,"type" varchar(20) NOT NULL,"normal_balance" varchar(10) NOT NULL,"category" varchar(50),"description" text,"is_system" boolean DEFAULT false,"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_accounts_category" ON "accounts" ("category");
+CREATE INDEX IF NOT EXISTS "idx_accounts_is_active" ON "accounts" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_accounts_type" ON "accounts" ("type");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_accounts_code" ON "accounts" ("code");
+CREATE TABLE "journal_entries" ("id" bigserial,"entry_number" varchar(50) NOT NULL,"date" timestamptz NOT NULL,"description" text,"source_type" varchar(30) NOT NULL,"source_ref" varchar(100),"reference" varchar(100),"status" varchar(20) NOT NULL,"reversal_of_id" bigint,"total_amount" bigint NOT NULL,"created_by" bigint,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_journal_entries_created_by" ON "journal_entries" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_journal_entries_date" ON "journal_entries" ("date");
+CREATE INDEX IF NOT EXISTS "idx_journal_entries_reference" ON "journal_entries" ("reference");
+CREATE INDEX IF NOT EXISTS "idx_journal_entries_reversal_of_id" ON "journal_entries" ("reversal_of_id");
+CREATE INDEX IF NOT EXISTS "idx_journal_entries_status" ON "journal_entries" ("status");
+CREATE INDEX IF NOT EXISTS "idx_journal_source" ON "journal_entries" ("source_type","source_ref");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_journal_entries_entry_number" ON "journal_entries" ("entry_number");
+CREATE TABLE "journal_lines" ("id" bigserial,"journal_entry_id" bigint NOT NULL,"account_id" bigint NOT NULL,"debit" bigint NOT NULL DEFAULT 0,"credit" bigint NOT NULL DEFAULT 0,"description" varchar(255),PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_journal_lines_account_id" ON "journal_lines" ("account_id");
+CREATE INDEX IF NOT EXISTS "idx_journal_lines_journal_entry_id" ON "journal_lines" ("journal_entry_id");
+CREATE TABLE "supplier_invoices" ("id" bigserial,"invoice_number" varchar(100) NOT NULL,"supplier_id" bigint NOT NULL,"po_id" bigint NOT NULL,"grn_id" bigint NOT NULL,"invoice_date" timestamptz NOT NULL,"due_date" timestamptz NOT NULL,"subtotal" bigint NOT NULL,"tax_amount" bigint DEFAULT 0,"total_amount" bigint NOT NULL,"received_amount" bigint DEFAULT 0,"paid_amount" bigint DEFAULT 0,"status" varchar(20) NOT NULL,"match_status" varchar(20) NOT NULL,"match_notes" text,"invoice_photo" varchar(500),"notes" text,"approved_by" bigint,"approved_at" timestamptz,"created_by" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_supplier_invoice_number" ON "supplier_invoices" ("invoice_number","supplier_id");
+CREATE INDEX IF NOT EXISTS "idx_supplier_invoices_approved_by" ON "supplier_invoices" ("approved_by");
+CREATE INDEX IF NOT EXISTS "idx_supplier_invoices_created_by" ON "supplier_invoices" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_supplier_invoices_due_date" ON "supplier_invoices" ("due_date");
+CREATE INDEX IF NOT EXISTS "idx_supplier_invoices_grn_id" ON "supplier_invoices" ("grn_id");
+CREATE INDEX IF NOT EXISTS "idx_supplier_invoices_invoice_date" ON "supplier_invoices" ("invoice_date");
+CREATE INDEX IF NOT EXISTS "idx_supplier_invoices_match_status" ON "supplier_invoices" ("match_status");
+CREATE INDEX IF NOT EXISTS "idx_supplier_invoices_po_id" ON "supplier_invoices" ("po_id");
+CREATE INDEX IF NOT EXISTS "idx_supplier_invoices_status" ON "supplier_invoices" ("status");
+CREATE TABLE "supplier_invoice_items" ("id" bigserial,"invoice_id" bigint NOT NULL,"ingredient_id" bigint NOT NULL,"quantity" decimal NOT NULL,"unit_price" bigint NOT NULL,"subtotal" bigint NOT NULL,"ordered_quantity" decimal DEFAULT 0,"received_quantity" decimal DEFAULT 0,"po_unit_price" bigint DEFAULT 0,"quantity_variance" decimal DEFAULT 0,"price_variance" decimal DEFAULT 0,"match_status" varchar(20),"match_notes" text,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_supplier_invoice_items_ingredient_id" ON "supplier_invoice_items" ("ingredient_id");
+CREATE INDEX IF NOT EXISTS "idx_supplier_invoice_items_invoice_id" ON "supplier_invoice_items" ("invoice_id");
+CREATE TABLE "supplier_payments" ("id" bigserial,"payment_number" varchar(50) NOT NULL,"invoice_id" bigint NOT NULL,"supplier_id" bigint NOT NULL,"payment_date" timestamptz NOT NULL,"amount" bigint NOT NULL,"method" varchar(20) NOT NULL,"bank_reference" varchar(100),"bank_account" varchar(100),"notes" text,"created_by" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_supplier_payments_bank_reference" ON "supplier_payments" ("bank_reference");
+CREATE INDEX IF NOT EXISTS "idx_supplier_payments_created_by" ON "supplier_payments" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_supplier_payments_invoice_id" ON "supplier_payments" ("invoice_id");
+CREATE INDEX IF NOT EXISTS "idx_supplier_payments_payment_date" ON "supplier_payments" ("payment_date");
+CREATE INDEX IF NOT EXISTS "idx_supplier_payments_supplier_id" ON "supplier_payments" ("supplier_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_supplier_payments_payment_number" ON "supplier_payments" ("payment_number");
+CREATE TABLE "funding_tranches" ("id" bigserial,"tranche_number" varchar(50) NOT NULL,"source" varchar(150) NOT NULL,"reference_number" varchar(100),"received_date" timestamptz NOT NULL,"amount" bigint NOT NULL,"period_start" timestamptz NOT NULL,"period_end" timestamptz NOT NULL,"target_portions" bigint DEFAULT 0,"description" text,"cash_flow_entry_id" bigint,"created_by" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_funding_tranches_cash_flow_entry_id" ON "funding_tranches" ("cash_flow_entry_id");
+CREATE INDEX IF NOT EXISTS "idx_funding_tranches_created_by" ON "funding_tranches" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_funding_tranches_period_end" ON "funding_tranches" ("period_end");
+CREATE INDEX IF NOT EXISTS "idx_funding_tranches_period_start" ON "funding_tranches" ("period_start");
+CREATE INDEX IF NOT EXISTS "idx_funding_tranches_received_date" ON "funding_tranches" ("received_date");
+CREATE INDEX IF NOT EXISTS "idx_funding_tranches_reference_number" ON "funding_tranches" ("reference_number");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_funding_tranches_tranche_number" ON "funding_tranches" ("tranche_number");
+CREATE TABLE "funding_tranche_schools" ("id" bigserial,"tranche_id" bigint NOT NULL,"school_id" bigint NOT NULL,"target_portions" bigint DEFAULT 0,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_tranche_school" ON "funding_tranche_schools" ("tranche_id","school_id");
+CREATE TABLE "bank_statements" ("id" bigserial,"bank_name" varchar(50),"account_number" varchar(50),"format" varchar(20) NOT NULL,"file_name" varchar(255),"period_start" timestamptz,"period_end" timestamptz,"opening_balance" bigint DEFAULT 0,"closing_balance" bigint DEFAULT 0,"total_credit" bigint DEFAULT 0,"total_debit" bigint DEFAULT 0,"line_count" bigint DEFAULT 0,"imported_by" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_bank_statements_account_number" ON "bank_statements" ("account_number");
+CREATE INDEX IF NOT EXISTS "idx_bank_statements_imported_by" ON "bank_statements" ("imported_by");
+CREATE INDEX IF NOT EXISTS "idx_bank_statements_period_end" ON "bank_statements" ("period_end");
+CREATE INDEX IF NOT EXISTS "idx_bank_statements_period_start" ON "bank_statements" ("period_start");
+CREATE TABLE "bank_statement_lines" ("id" bigserial,"statement_id" bigint NOT NULL,"account_number" varchar(50),"line_number" bigint NOT NULL,"transaction_date" timestamptz NOT NULL,"description" text,"reference" varchar(100),"direction" varchar(10) NOT NULL,"amount" bigint NOT NULL,"balance" bigint,"dedup_key" varchar(64) NOT NULL,"match_status" varchar(20) DEFAULT 'unmatched',"match_type" varchar(20),"cash_flow_entry_id" bigint,"supplier_payment_id" bigint,"matched_by" bigint,"matched_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_bank_statement_lines_account_number" ON "bank_statement_lines" ("account_number");
+CREATE INDEX IF NOT EXISTS "idx_bank_statement_lines_match_status" ON "bank_statement_lines" ("match_status");
+CREATE INDEX IF NOT EXISTS "idx_bank_statement_lines_matched_by" ON "bank_statement_lines" ("matched_by");
+CREATE INDEX IF NOT EXISTS "idx_bank_statement_lines_reference" ON "bank_statement_lines" ("reference");
+CREATE INDEX IF NOT EXISTS "idx_bank_statement_lines_statement_id" ON "bank_statement_lines" ("statement_id");
+CREATE INDEX IF NOT EXISTS "idx_bank_statement_lines_transaction_date" ON "bank_statement_lines" ("transaction_date");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_bank_statement_lines_cash_flow_entry_id" ON "bank_statement_lines" ("cash_flow_entry_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_bank_statement_lines_dedup_key" ON "bank_statement_lines" ("dedup_key");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_bank_statement_lines_supplier_payment_id" ON "bank_statement_lines" ("supplier_payment_id");
+CREATE TABLE "petty_cash_funds" ("id" bigserial,"name" varchar(100) NOT NULL,"custodian_id" bigint NOT NULL,"float_amount" bigint NOT NULL,"balance" bigint NOT NULL,"is_active" boolean DEFAULT true,"created_by" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_funds_created_by" ON "petty_cash_funds" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_funds_custodian_id" ON "petty_cash_funds" ("custodian_id");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_funds_is_active" ON "petty_cash_funds" ("is_active");
+CREATE TABLE "petty_cash_expenses" ("id" bigserial,"expense_number" varchar(50) NOT NULL,"fund_id" bigint NOT NULL,"expense_date" timestamptz NOT NULL,"category" varchar(50) NOT NULL,"description" text NOT NULL,"amount" bigint NOT NULL,"receipt_photo" varchar(500) NOT NULL,"ingredient_id" bigint,"quantity" decimal DEFAULT 0,"cash_flow_entry_id" bigint,"inventory_movement_id" bigint,"replenishment_id" bigint,"created_by" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_expenses_cash_flow_entry_id" ON "petty_cash_expenses" ("cash_flow_entry_id");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_expenses_category" ON "petty_cash_expenses" ("category");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_expenses_created_by" ON "petty_cash_expenses" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_expenses_expense_date" ON "petty_cash_expenses" ("expense_date");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_expenses_fund_id" ON "petty_cash_expenses" ("fund_id");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_expenses_ingredient_id" ON "petty_cash_expenses" ("ingredient_id");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_expenses_inventory_movement_id" ON "petty_cash_expenses" ("inventory_movement_id");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_expenses_replenishment_id" ON "petty_cash_expenses" ("replenishment_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_petty_cash_expenses_expense_number" ON "petty_cash_expenses" ("expense_number");
+CREATE TABLE "petty_cash_replenishments" ("id" bigserial,"replenishment_number" varchar(50) NOT NULL,"fund_id" bigint NOT NULL,"amount" bigint NOT NULL,"status" varchar(20) NOT NULL,"notes" text,"requested_by" bigint NOT NULL,"approved_by" bigint,"approved_at" timestamptz,"rejection_reason" text,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_replenishments_approved_by" ON "petty_cash_replenishments" ("approved_by");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_replenishments_fund_id" ON "petty_cash_replenishments" ("fund_id");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_replenishments_requested_by" ON "petty_cash_replenishments" ("requested_by");
+CREATE INDEX IF NOT EXISTS "idx_petty_cash_replenishments_status" ON "petty_cash_replenishments" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_petty_cash_replenishments_replenishment_number" ON "petty_cash_replenishments" ("replenishment_number");
+CREATE TABLE "system_configs" ("id" bigserial,"key" varchar(100) NOT NULL,"value" text NOT NULL,"data_type" varchar(20) NOT NULL,"category" varchar(50),"updated_by" bigint NOT NULL,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_system_configs_category" ON "system_configs" ("category");
+CREATE INDEX IF NOT EXISTS "idx_system_configs_updated_by" ON "system_configs" ("updated_by");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_system_configs_key" ON "system_configs" ("key");
+CREATE TABLE "notifications" ("id" bigserial,"user_id" bigint NOT NULL,"type" varchar(50) NOT NULL,"title" varchar(200) NOT NULL,"message" text NOT NULL,"is_read" boolean DEFAULT false,"link" varchar(500),"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_notifications_created_at" ON "notifications" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_notifications_is_read" ON "notifications" ("is_read");
+CREATE INDEX IF NOT EXISTS "idx_notifications_type" ON "notifications" ("type");
+CREATE INDEX IF NOT EXISTS "idx_notifications_user_id" ON "notifications" ("user_id");
+CREATE TABLE "outbox_events" ("id" bigserial,"event_type" varchar(100) NOT NULL,"aggregate_type" varchar(50),"aggregate_id" bigint,"payload" text,"status" varchar(20) NOT NULL DEFAULT 'pending',"attempts" bigint NOT NULL DEFAULT 0,"max_attempts" bigint NOT NULL DEFAULT 8,"next_attempt_at" timestamptz NOT NULL,"locked_until" timestamptz,"last_error" text,"delivered_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_aggregate" ON "outbox_events" ("aggregate_type","aggregate_id");
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_created_at" ON "outbox_events" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_due" ON "outbox_events" ("status","next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_event_type" ON "outbox_events" ("event_type");
+CREATE TABLE "sync_operations" ("id" bigserial,"user_id" bigint NOT NULL,"idempotency_key" varchar(100) NOT NULL,"type" varchar(50) NOT NULL,"status" varchar(20) NOT NULL DEFAULT 'pending',"client_timestamp" timestamptz NOT NULL,"result" text,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_sync_operations_created_at" ON "sync_operations" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_sync_operations_status" ON "sync_operations" ("status");
+CREATE INDEX IF NOT EXISTS "idx_sync_operations_type" ON "sync_operations" ("type");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_sync_operations_user_key" ON "sync_operations" ("user_id","idempotency_key");
+CREATE TABLE "idempotency_keys" ("id" bigserial,"user_id" bigint NOT NULL,"key" varchar(255) NOT NULL,"fingerprint" varchar(64) NOT NULL,"completed" boolean NOT NULL DEFAULT false,"status_code" bigint,"content_type" varchar(100),"body" bytea,"expires_at" timestamptz NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_idempotency_keys_user_key" ON "idempotency_keys" ("user_id","key");
+CREATE TABLE "webhook_subscriptions" ("id" bigserial,"name" varchar(100) NOT NULL,"target_url" varchar(500) NOT NULL,"event_types" text NOT NULL,"secret" varchar(128) NOT NULL,"is_active" boolean NOT NULL DEFAULT true,"created_by" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_webhook_subscriptions_is_active" ON "webhook_subscriptions" ("is_active");
+CREATE TABLE "webhook_deliveries" ("id" bigserial,"subscription_id" bigint NOT NULL,"event_type" varchar(100) NOT NULL,"payload" text NOT NULL,"status" varchar(20) NOT NULL DEFAULT 'pending',"attempts" bigint NOT NULL DEFAULT 0,"response_status" bigint,"response_body" text,"last_error" text,"last_attempt_at" timestamptz,"delivered_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_event_type" ON "webhook_deliveries" ("event_type");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_status" ON "webhook_deliveries" ("status");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_subscription" ON "webhook_deliveries" ("subscription_id","created_at");
+ALTER TABLE "asset_audit_items" ADD CONSTRAINT "fk_asset_audit_items_asset" FOREIGN KEY ("asset_id") REFERENCES "kitchen_assets"("id");
+ALTER TABLE "asset_audit_items" ADD CONSTRAINT "fk_asset_audits_items" FOREIGN KEY ("audit_id") REFERENCES "asset_audits"("id");
+ALTER TABLE "asset_audits" ADD CONSTRAINT "fk_asset_audits_approver" FOREIGN KEY ("approved_by") REFERENCES "users"("id");
+ALTER TABLE "asset_audits" ADD CONSTRAINT "fk_asset_audits_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "asset_maintenances" ADD CONSTRAINT "fk_kitchen_assets_maintenance_records" FOREIGN KEY ("asset_id") REFERENCES "kitchen_assets"("id");
+ALTER TABLE "attendances" ADD CONSTRAINT "fk_attendances_employee" FOREIGN KEY ("employee_id") REFERENCES "employees"("id");
+ALTER TABLE "audit_trails" ADD CONSTRAINT "fk_audit_trails_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "bank_statement_lines" ADD CONSTRAINT "fk_bank_statement_lines_cash_flow_entry" FOREIGN KEY ("cash_flow_entry_id") REFERENCES "cash_flow_entries"("id");
+ALTER TABLE "bank_statement_lines" ADD CONSTRAINT "fk_bank_statement_lines_supplier_payment" FOREIGN KEY ("supplier_payment_id") REFERENCES "supplier_payments"("id");
+ALTER TABLE "bank_statement_lines" ADD CONSTRAINT "fk_bank_statements_lines" FOREIGN KEY ("statement_id") REFERENCES "bank_statements"("id");
+ALTER TABLE "cash_flow_entries" ADD CONSTRAINT "fk_cash_flow_entries_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "delivery_menu_items" ADD CONSTRAINT "fk_delivery_menu_items_recipe" FOREIGN KEY ("recipe_id") REFERENCES "recipes"("id");
+ALTER TABLE "delivery_menu_items" ADD CONSTRAINT "fk_delivery_tasks_menu_items" FOREIGN KEY ("delivery_task_id") REFERENCES "delivery_tasks"("id");
+ALTER TABLE "delivery_records" ADD CONSTRAINT "fk_delivery_records_driver" FOREIGN KEY ("driver_id") REFERENCES "users"("id");
+ALTER TABLE "delivery_records" ADD CONSTRAINT "fk_delivery_records_menu_item" FOREIGN KEY ("menu_item_id") REFERENCES "menu_items"("id");
+ALTER TABLE "delivery_records" ADD CONSTRAINT "fk_delivery_records_school" FOREIGN KEY ("school_id") REFERENCES "schools"("id");
+ALTER TABLE "delivery_reviews" ADD CONSTRAINT "fk_delivery_reviews_delivery_record" FOREIGN KEY ("delivery_record_id") REFERENCES "delivery_records"("id");
+ALTER TABLE "delivery_reviews" ADD CONSTRAINT "fk_delivery_reviews_school" FOREIGN KEY ("school_id") REFERENCES "schools"("id");
+ALTER TABLE "delivery_tasks" ADD CONSTRAINT "fk_delivery_tasks_driver" FOREIGN KEY ("driver_id") REFERENCES "users"("id");
+ALTER TABLE "delivery_tasks" ADD CONSTRAINT "fk_delivery_tasks_school" FOREIGN KEY ("school_id") REFERENCES "schools"("id");
+ALTER TABLE "depreciation_run_lines" ADD CONSTRAINT "fk_depreciation_run_lines_asset" FOREIGN KEY ("asset_id") REFERENCES "kitchen_assets"("id");
+ALTER TABLE "depreciation_run_lines" ADD CONSTRAINT "fk_depreciation_runs_lines" FOREIGN KEY ("run_id") REFERENCES "depreciation_runs"("id");
+ALTER TABLE "electronic_pods" ADD CONSTRAINT "fk_electronic_pods_delivery_task" FOREIGN KEY ("delivery_task_id") REFERENCES "delivery_tasks"("id");
+ALTER TABLE "employees" ADD CONSTRAINT "fk_employees_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "funding_tranche_schools" ADD CONSTRAINT "fk_funding_tranche_schools_school" FOREIGN KEY ("school_id") REFERENCES "schools"("id");
+ALTER TABLE "funding_tranche_schools" ADD CONSTRAINT "fk_funding_tranches_schools" FOREIGN KEY ("tranche_id") REFERENCES "funding_tranches"("id");
+ALTER TABLE "goods_receipt_items" ADD CONSTRAINT "fk_goods_receipt_items_ingredient" FOREIGN KEY ("ingredient_id") REFERENCES "ingredients"("id");
+ALTER TABLE "goods_receipt_items" ADD CONSTRAINT "fk_goods_receipts_grn_items" FOREIGN KEY ("grn_id") REFERENCES "goods_receipts"("id");
+ALTER TABLE "goods_receipts" ADD CONSTRAINT "fk_goods_receipts_purchase_order" FOREIGN KEY ("po_id") REFERENCES "purchase_orders"("id");
+ALTER TABLE "goods_receipts" ADD CONSTRAINT "fk_goods_receipts_receiver" FOREIGN KEY ("received_by") REFERENCES "users"("id");
+ALTER TABLE "inventory_items" ADD CONSTRAINT "fk_inventory_items_ingredient" FOREIGN KEY ("ingredient_id") REFERENCES "ingredients"("id");
+ALTER TABLE "inventory_movements" ADD CONSTRAINT "fk_inventory_movements_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "inventory_movements" ADD CONSTRAINT "fk_inventory_movements_ingredient" FOREIGN KEY ("ingredient_id") REFERENCES "ingredients"("id");
+ALTER TABLE "journal_lines" ADD CONSTRAINT "fk_journal_entries_lines" FOREIGN KEY ("journal_entry_id") REFERENCES "journal_entries"("id");
+ALTER TABLE "journal_lines" ADD CONSTRAINT "fk_journal_lines_account" FOREIGN KEY ("account_id") REFERENCES "accounts"("id");
+ALTER TABLE "maintenance_plans" ADD CONSTRAINT "fk_maintenance_plans_asset" FOREIGN KEY ("asset_id") REFERENCES "kitchen_assets"("id");
+ALTER TABLE "maintenance_plans" ADD CONSTRAINT "fk_maintenance_plans_responsible_user" FOREIGN KEY ("responsible_user_id") REFERENCES "users"("id");
+ALTER TABLE "menu_item_school_allocations" ADD CONSTRAINT "fk_menu_item_school_allocations_school" FOREIGN KEY ("school_id") REFERENCES "schools"("id") ON DELETE RESTRICT;
+ALTER TABLE "menu_item_school_allocations" ADD CONSTRAINT "fk_menu_items_school_allocations" FOREIGN KEY ("menu_item_id") REFERENCES "menu_items"("id");
+ALTER TABLE "menu_items" ADD CONSTRAINT "fk_menu_items_recipe" FOREIGN KEY ("recipe_id") REFERENCES "recipes"("id");
+ALTER TABLE "menu_items" ADD CONSTRAINT "fk_menu_plans_menu_items" FOREIGN KEY ("menu_plan_id") REFERENCES "menu_plans"("id");
+ALTER TABLE "menu_plans" ADD CONSTRAINT "fk_menu_plans_approver" FOREIGN KEY ("approved_by") REFERENCES "users"("id");
+ALTER TABLE "menu_plans" ADD CONSTRAINT "fk_menu_plans_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "notifications" ADD CONSTRAINT "fk_notifications_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "ompreng_cleanings" ADD CONSTRAINT "fk_ompreng_cleanings_cleaner" FOREIGN KEY ("cleaned_by") REFERENCES "users"("id");
+ALTER TABLE "ompreng_cleanings" ADD CONSTRAINT "fk_ompreng_cleanings_delivery_record" FOREIGN KEY ("delivery_record_id") REFERENCES "delivery_records"("id");
+ALTER TABLE "ompreng_trackings" ADD CONSTRAINT "fk_ompreng_trackings_recorder" FOREIGN KEY ("recorded_by") REFERENCES "users"("id");
+ALTER TABLE "ompreng_trackings" ADD CONSTRAINT "fk_ompreng_trackings_school" FOREIGN KEY ("school_id") REFERENCES "schools"("id");
+ALTER TABLE "petty_cash_expenses" ADD CONSTRAINT "fk_petty_cash_expenses_ingredient" FOREIGN KEY ("ingredient_id") REFERENCES "ingredients"("id");
+ALTER TABLE "petty_cash_funds" ADD CONSTRAINT "fk_petty_cash_funds_custodian" FOREIGN KEY ("custodian_id") REFERENCES "users"("id");
+ALTER TABLE "petty_cash_replenishments" ADD CONSTRAINT "fk_petty_cash_replenishments_fund" FOREIGN KEY ("fund_id") REFERENCES "petty_cash_funds"("id");
+ALTER TABLE "pickup_tasks" ADD CONSTRAINT "fk_pickup_tasks_driver" FOREIGN KEY ("driver_id") REFERENCES "users"("id");
+ALTER TABLE "purchase_order_items" ADD CONSTRAINT "fk_purchase_order_items_ingredient" FOREIGN KEY ("ingredient_id") REFERENCES "ingredients"("id");
+ALTER TABLE "purchase_order_items" ADD CONSTRAINT "fk_purchase_orders_po_items" FOREIGN KEY ("po_id") REFERENCES "purchase_orders"("id");
+ALTER TABLE "purchase_orders" ADD CONSTRAINT "fk_purchase_orders_approver" FOREIGN KEY ("approved_by") REFERENCES "users"("id");
+ALTER TABLE "purchase_orders" ADD CONSTRAINT "fk_purchase_orders_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "purchase_orders" ADD CONSTRAINT "fk_purchase_orders_supplier" FOREIGN KEY ("supplier_id") REFERENCES "suppliers"("id");
+ALTER TABLE "recipe_items" ADD CONSTRAINT "fk_recipe_items_semi_finished_goods" FOREIGN KEY ("semi_finished_goods_id") REFERENCES "semi_finished_goods"("id");
+ALTER TABLE "recipe_items" ADD CONSTRAINT "fk_recipes_recipe_items" FOREIGN KEY ("recipe_id") REFERENCES "recipes"("id");
+ALTER TABLE "recipe_versions" ADD CONSTRAINT "fk_recipe_versions_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "recipes" ADD CONSTRAINT "fk_recipes_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "refresh_tokens" ADD CONSTRAINT "fk_refresh_tokens_user_session" FOREIGN KEY ("user_session_id") REFERENCES "user_sessions"("id");
+ALTER TABLE "role_permissions" ADD CONSTRAINT "fk_role_permissions_permission" FOREIGN KEY ("permission_id") REFERENCES "permissions"("id");
+ALTER TABLE "role_permissions" ADD CONSTRAINT "fk_role_permissions_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id");
+ALTER TABLE "semi_finished_inventories" ADD CONSTRAINT "fk_semi_finished_inventories_semi_finished_goods" FOREIGN KEY ("semi_finished_goods_id") REFERENCES "semi_finished_goods"("id");
+ALTER TABLE "semi_finished_movements" ADD CONSTRAINT "fk_semi_finished_movements_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "semi_finished_movements" ADD CONSTRAINT "fk_semi_finished_movements_semi_finished_goods" FOREIGN KEY ("semi_finished_goods_id") REFERENCES "semi_finished_goods"("id");
+ALTER TABLE "semi_finished_production_logs" ADD CONSTRAINT "fk_semi_finished_production_logs_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "semi_finished_production_logs" ADD CONSTRAINT "fk_semi_finished_production_logs_semi_finished_goods" FOREIGN KEY ("semi_finished_goods_id") REFERENCES "semi_finished_goods"("id");
+ALTER TABLE "semi_finished_recipe_ingredients" ADD CONSTRAINT "fk_semi_finished_recipe_ingredients_ingredient" FOREIGN KEY ("ingredient_id") REFERENCES "ingredients"("id");
+ALTER TABLE "semi_finished_recipe_ingredients" ADD CONSTRAINT "fk_semi_finished_recipes_ingredients" FOREIGN KEY ("semi_finished_recipe_id") REFERENCES "semi_finished_recipes"("id");
+ALTER TABLE "semi_finished_recipes" ADD CONSTRAINT "fk_semi_finished_goods_recipe" FOREIGN KEY ("semi_finished_goods_id") REFERENCES "semi_finished_goods"("id");
+ALTER TABLE "semi_finished_recipes" ADD CONSTRAINT "fk_semi_finished_recipes_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "status_transitions" ADD CONSTRAINT "fk_status_transitions_delivery_record" FOREIGN KEY ("delivery_record_id") REFERENCES "delivery_records"("id");
+ALTER TABLE "status_transitions" ADD CONSTRAINT "fk_status_transitions_user" FOREIGN KEY ("transitioned_by") REFERENCES "users"("id");
+ALTER TABLE "stok_opname_forms" ADD CONSTRAINT "fk_stok_opname_forms_approver" FOREIGN KEY ("approved_by") REFERENCES "users"("id");
+ALTER TABLE "stok_opname_forms" ADD CONSTRAINT "fk_stok_opname_forms_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "stok_opname_items" ADD CONSTRAINT "fk_stok_opname_forms_items" FOREIGN KEY ("form_id") REFERENCES "stok_opname_forms"("id");
+ALTER TABLE "stok_opname_items" ADD CONSTRAINT "fk_stok_opname_items_ingredient" FOREIGN KEY ("ingredient_id") REFERENCES "ingredients"("id");
+ALTER TABLE "supplier_invoice_items" ADD CONSTRAINT "fk_supplier_invoice_items_ingredient" FOREIGN KEY ("ingredient_id") REFERENCES "ingredients"("id");
+ALTER TABLE "supplier_invoice_items" ADD CONSTRAINT "fk_supplier_invoices_items" FOREIGN KEY ("invoice_id") REFERENCES "supplier_invoices"("id");
+ALTER TABLE "supplier_invoices" ADD CONSTRAINT "fk_supplier_invoices_goods_receipt" FOREIGN KEY ("grn_id") REFERENCES "goods_receipts"("id");
+ALTER TABLE "supplier_invoices" ADD CONSTRAINT "fk_supplier_invoices_purchase_order" FOREIGN KEY ("po_id") REFERENCES "purchase_orders"("id");
+ALTER TABLE "supplier_invoices" ADD CONSTRAINT "fk_supplier_invoices_supplier" FOREIGN KEY ("supplier_id") REFERENCES "suppliers"("id");
+ALTER TABLE "supplier_payments" ADD CONSTRAINT "fk_supplier_invoices_payments" FOREIGN KEY ("invoice_id") REFERENCES "supplier_invoices"("id");
+ALTER TABLE "supplier_payments" ADD CONSTRAINT "fk_supplier_payments_supplier" FOREIGN KEY ("supplier_id") REFERENCES "suppliers"("id");
+ALTER TABLE "sync_operations" ADD CONSTRAINT "fk_sync_operations_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "system_configs" ADD CONSTRAINT "fk_system_configs_updater" FOREIGN KEY ("updated_by") REFERENCES "users"("id");
+ALTER TABLE "user_permission_overrides" ADD CONSTRAINT "fk_user_permission_overrides_permission" FOREIGN KEY ("permission_id") REFERENCES "permissions"("id");
+ALTER TABLE "user_sessions" ADD CONSTRAINT "fk_user_sessions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "webhook_deliveries" ADD CONSTRAINT "fk_webhook_deliveries_subscription" FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions"("id");
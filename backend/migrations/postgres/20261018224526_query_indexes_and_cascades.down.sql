@@ -0,0 +1,37 @@
+ALTER TABLE stok_opname_items DROP CONSTRAINT IF EXISTS fk_stok_opname_items_form;
+ALTER TABLE stok_opname_items ADD CONSTRAINT fk_stok_opname_forms_items FOREIGN KEY (form_id) REFERENCES stok_opname_forms(id);
+ALTER TABLE menu_item_school_allocations DROP CONSTRAINT IF EXISTS fk_menu_item_school_allocations_menu_item;
+ALTER TABLE menu_item_school_allocations ADD CONSTRAINT fk_menu_items_school_allocations FOREIGN KEY (menu_item_id) REFERENCES menu_items(id);
+
+DROP INDEX IF EXISTS idx_stok_opname_items_form_ingredient;
+DROP INDEX IF EXISTS idx_menu_item_school_allocation_unique;
+
+DROP INDEX IF EXISTS idx_notifications_unread;
+DROP INDEX IF EXISTS idx_delivery_tasks_pending;
+DROP INDEX IF EXISTS idx_schools_active;
+DROP INDEX IF EXISTS idx_recipes_active;
+DROP INDEX IF EXISTS idx_suppliers_active;
+
+DROP INDEX IF EXISTS idx_semi_finished_movements_goods_date;
+DROP INDEX IF EXISTS idx_semi_finished_movements_date;
+DROP INDEX IF EXISTS idx_semi_finished_movements_type;
+DROP INDEX IF EXISTS idx_semi_finished_movements_goods_id;
+DROP INDEX IF EXISTS idx_notifications_user_read;
+DROP INDEX IF EXISTS idx_asset_maintenance_asset_date;
+DROP INDEX IF EXISTS idx_ompreng_tracking_school_date;
+DROP INDEX IF EXISTS idx_goods_receipt_po_date;
+DROP INDEX IF EXISTS idx_purchase_order_supplier_date;
+DROP INDEX IF EXISTS idx_purchase_order_status_date;
+DROP INDEX IF EXISTS idx_inventory_movement_type_date;
+DROP INDEX IF EXISTS idx_inventory_movement_ingredient_date;
+DROP INDEX IF EXISTS idx_cash_flow_type_date;
+DROP INDEX IF EXISTS idx_cash_flow_date_category;
+DROP INDEX IF EXISTS idx_attendance_employee_date;
+DROP INDEX IF EXISTS idx_delivery_task_status_date;
+DROP INDEX IF EXISTS idx_delivery_task_date_driver;
+DROP INDEX IF EXISTS idx_menu_item_school_allocation_date;
+DROP INDEX IF EXISTS idx_menu_item_school_allocation_school;
+DROP INDEX IF EXISTS idx_menu_item_school_allocation_menu_item;
+DROP INDEX IF EXISTS idx_menu_item_date_plan;
+DROP INDEX IF EXISTS idx_audit_trail_entity_action;
+DROP INDEX IF EXISTS idx_audit_trail_user_timestamp;
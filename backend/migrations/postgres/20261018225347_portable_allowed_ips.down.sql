@@ -0,0 +1 @@
+ALTER TABLE wi_fi_configs ALTER COLUMN allowed_ips TYPE text[] USING translate(allowed_ips, '[]', '{}')::text[];
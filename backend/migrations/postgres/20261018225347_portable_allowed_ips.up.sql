@@ -0,0 +1,5 @@
+-- wi_fi_configs.allowed_ips becomes a JSON array in a text column
+-- (models.StringList) so the same schema works on SQLite. The cast through
+-- text[] also covers databases where AutoMigrate already turned the array
+-- into its text form while adopting the baseline.
+ALTER TABLE wi_fi_configs ALTER COLUMN allowed_ips TYPE text USING array_to_json(allowed_ips::text[])::text;
@@ -0,0 +1,78 @@
+DROP TABLE IF EXISTS `webhook_deliveries`;
+DROP TABLE IF EXISTS `webhook_subscriptions`;
+DROP TABLE IF EXISTS `idempotency_keys`;
+DROP TABLE IF EXISTS `sync_operations`;
+DROP TABLE IF EXISTS `outbox_events`;
+DROP TABLE IF EXISTS `notifications`;
+DROP TABLE IF EXISTS `system_configs`;
+DROP TABLE IF EXISTS `petty_cash_replenishments`;
+DROP TABLE IF EXISTS `petty_cash_expenses`;
+DROP TABLE IF EXISTS `petty_cash_funds`;
+DROP TABLE IF EXISTS `bank_statement_lines`;
+DROP TABLE IF EXISTS `bank_statements`;
+DROP TABLE IF EXISTS `funding_tranche_schools`;
+DROP TABLE IF EXISTS `funding_tranches`;
+DROP TABLE IF EXISTS `supplier_payments`;
+DROP TABLE IF EXISTS `supplier_invoice_items`;
+DROP TABLE IF EXISTS `supplier_invoices`;
+DROP TABLE IF EXISTS `journal_lines`;
+DROP TABLE IF EXISTS `journal_entries`;
+DROP TABLE IF EXISTS `accounts`;
+DROP TABLE IF EXISTS `budget_alerts`;
+DROP TABLE IF EXISTS `budget_targets`;
+DROP TABLE IF EXISTS `cash_flow_entries`;
+DROP TABLE IF EXISTS `depreciation_run_lines`;
+DROP TABLE IF EXISTS `depreciation_runs`;
+DROP TABLE IF EXISTS `asset_usage_logs`;
+DROP TABLE IF EXISTS `asset_audit_items`;
+DROP TABLE IF EXISTS `asset_audits`;
+DROP TABLE IF EXISTS `maintenance_plans`;
+DROP TABLE IF EXISTS `asset_maintenances`;
+DROP TABLE IF EXISTS `kitchen_assets`;
+DROP TABLE IF EXISTS `gps_configs`;
+DROP TABLE IF EXISTS `wi_fi_configs`;
+DROP TABLE IF EXISTS `attendances`;
+DROP TABLE IF EXISTS `employees`;
+DROP TABLE IF EXISTS `delivery_reviews`;
+DROP TABLE IF EXISTS `pickup_tasks`;
+DROP TABLE IF EXISTS `ompreng_cleanings`;
+DROP TABLE IF EXISTS `status_transitions`;
+DROP TABLE IF EXISTS `delivery_records`;
+DROP TABLE IF EXISTS `ompreng_inventories`;
+DROP TABLE IF EXISTS `ompreng_trackings`;
+DROP TABLE IF EXISTS `electronic_pods`;
+DROP TABLE IF EXISTS `delivery_menu_items`;
+DROP TABLE IF EXISTS `delivery_tasks`;
+DROP TABLE IF EXISTS `schools`;
+DROP TABLE IF EXISTS `stok_opname_items`;
+DROP TABLE IF EXISTS `stok_opname_forms`;
+DROP TABLE IF EXISTS `inventory_movements`;
+DROP TABLE IF EXISTS `inventory_items`;
+DROP TABLE IF EXISTS `goods_receipt_items`;
+DROP TABLE IF EXISTS `goods_receipts`;
+DROP TABLE IF EXISTS `purchase_order_items`;
+DROP TABLE IF EXISTS `purchase_orders`;
+DROP TABLE IF EXISTS `suppliers`;
+DROP TABLE IF EXISTS `menu_item_school_allocations`;
+DROP TABLE IF EXISTS `menu_items`;
+DROP TABLE IF EXISTS `menu_plans`;
+DROP TABLE IF EXISTS `recipe_versions`;
+DROP TABLE IF EXISTS `recipe_items`;
+DROP TABLE IF EXISTS `recipes`;
+DROP TABLE IF EXISTS `semi_finished_movements`;
+DROP TABLE IF EXISTS `semi_finished_production_logs`;
+DROP TABLE IF EXISTS `semi_finished_inventories`;
+DROP TABLE IF EXISTS `semi_finished_recipe_ingredients`;
+DROP TABLE IF EXISTS `semi_finished_recipes`;
+DROP TABLE IF EXISTS `semi_finished_goods`;
+DROP TABLE IF EXISTS `ingredients`;
+DROP TABLE IF EXISTS `user_permission_overrides`;
+DROP TABLE IF EXISTS `role_permissions`;
+DROP TABLE IF EXISTS `permissions`;
+DROP TABLE IF EXISTS `roles`;
+DROP TABLE IF EXISTS `two_factor_recovery_codes`;
+DROP TABLE IF EXISTS `refresh_tokens`;
+DROP TABLE IF EXISTS `user_sessions`;
+DROP TABLE IF EXISTS `password_histories`;
+DROP TABLE IF EXISTS `audit_trails`;
+DROP TABLE IF EXISTS `users`;
@@ -0,0 +1,352 @@
+CREATE TABLE `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`nik` text NOT NULL,`email` text NOT NULL,`password_hash` text NOT NULL,`full_name` text NOT NULL,`phone_number` text,`role` text NOT NULL,`is_active` numeric DEFAULT true,`must_change_password` numeric DEFAULT false,`password_changed_at` datetime,`failed_login_attempts` integer DEFAULT 0,`locked_until` datetime,`two_factor_enabled` numeric DEFAULT false,`two_factor_secret` text,`two_factor_last_step` integer DEFAULT 0,`two_factor_enabled_at` datetime,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_users_is_active` ON `users`(`is_active`);
+CREATE INDEX `idx_users_role` ON `users`(`role`);
+CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`);
+CREATE UNIQUE INDEX `idx_users_nik` ON `users`(`nik`);
+CREATE TABLE `audit_trails` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`timestamp` datetime NOT NULL,`action` text NOT NULL,`entity` text NOT NULL,`entity_id` text,`old_value` text,`new_value` text,`ip_address` text,CONSTRAINT `fk_audit_trails_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_audit_trails_action` ON `audit_trails`(`action`);
+CREATE INDEX `idx_audit_trails_entity` ON `audit_trails`(`entity`);
+CREATE INDEX `idx_audit_trails_timestamp` ON `audit_trails`(`timestamp`);
+CREATE INDEX `idx_audit_trails_user_id` ON `audit_trails`(`user_id`);
+CREATE TABLE `password_histories` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`password_hash` text NOT NULL,`created_at` datetime);
+CREATE INDEX `idx_password_histories_created_at` ON `password_histories`(`created_at`);
+CREATE INDEX `idx_password_histories_user_id` ON `password_histories`(`user_id`);
+CREATE TABLE `user_sessions` (`id` integer PRIMARY KEY AUTOINCREMENT,`session_id` text NOT NULL,`user_id` integer NOT NULL,`user_agent` text,`device_name` text,`ip_address` text,`last_activity_at` datetime NOT NULL,`expires_at` datetime NOT NULL,`revoked_at` datetime,`revoked_reason` text,`created_at` datetime,CONSTRAINT `fk_user_sessions_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_user_sessions_expires_at` ON `user_sessions`(`expires_at`);
+CREATE INDEX `idx_user_sessions_revoked_at` ON `user_sessions`(`revoked_at`);
+CREATE INDEX `idx_user_sessions_user_id` ON `user_sessions`(`user_id`);
+CREATE UNIQUE INDEX `idx_user_sessions_session_id` ON `user_sessions`(`session_id`);
+CREATE TABLE `refresh_tokens` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_session_id` integer NOT NULL,`token_hash` text NOT NULL,`expires_at` datetime NOT NULL,`used_at` datetime,`created_at` datetime,CONSTRAINT `fk_refresh_tokens_user_session` FOREIGN KEY (`user_session_id`) REFERENCES `user_sessions`(`id`));
+CREATE INDEX `idx_refresh_tokens_user_session_id` ON `refresh_tokens`(`user_session_id`);
+CREATE UNIQUE INDEX `idx_refresh_tokens_token_hash` ON `refresh_tokens`(`token_hash`);
+CREATE TABLE `two_factor_recovery_codes` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`code_hash` text NOT NULL,`used_at` datetime,`created_at` datetime);
+CREATE INDEX `idx_two_factor_recovery_codes_code_hash` ON `two_factor_recovery_codes`(`code_hash`);
+CREATE INDEX `idx_two_factor_recovery_codes_user_id` ON `two_factor_recovery_codes`(`user_id`);
+CREATE TABLE `roles` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`display_name` text NOT NULL,`description` text,`is_system` numeric DEFAULT false,`is_active` numeric DEFAULT true,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_roles_is_active` ON `roles`(`is_active`);
+CREATE UNIQUE INDEX `idx_roles_name` ON `roles`(`name`);
+CREATE TABLE `permissions` (`id` integer PRIMARY KEY AUTOINCREMENT,`code` text NOT NULL,`category` text NOT NULL,`description` text,`created_at` datetime);
+CREATE INDEX `idx_permissions_category` ON `permissions`(`category`);
+CREATE UNIQUE INDEX `idx_permissions_code` ON `permissions`(`code`);
+CREATE TABLE `role_permissions` (`id` integer PRIMARY KEY AUTOINCREMENT,`role_id` integer NOT NULL,`permission_id` integer NOT NULL,`created_at` datetime,CONSTRAINT `fk_role_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `permissions`(`id`),CONSTRAINT `fk_role_permissions_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`));
+CREATE UNIQUE INDEX `idx_role_permission` ON `role_permissions`(`role_id`,`permission_id`);
+CREATE TABLE `user_permission_overrides` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`permission_id` integer NOT NULL,`granted` numeric NOT NULL,`reason` text,`created_by` integer NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_user_permission_overrides_permission` FOREIGN KEY (`permission_id`) REFERENCES `permissions`(`id`));
+CREATE UNIQUE INDEX `idx_user_permission_override` ON `user_permission_overrides`(`user_id`,`permission_id`);
+CREATE TABLE `ingredients` (`id` integer PRIMARY KEY AUTOINCREMENT,`code` text DEFAULT "",`name` text NOT NULL,`category` text,`unit` text NOT NULL,`calories_per100g` real DEFAULT 0,`protein_per100g` real DEFAULT 0,`carbs_per100g` real DEFAULT 0,`fat_per100g` real DEFAULT 0,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_ingredients_category` ON `ingredients`(`category`);
+CREATE INDEX `idx_ingredients_code` ON `ingredients`(`code`);
+CREATE INDEX `idx_ingredients_name` ON `ingredients`(`name`);
+CREATE TABLE `semi_finished_goods` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`unit` text NOT NULL,`category` text,`description` text,`calories_per100g` real NOT NULL,`protein_per100g` real NOT NULL,`carbs_per100g` real NOT NULL,`fat_per100g` real NOT NULL,`quantity_per_portion_small` real DEFAULT 0,`quantity_per_portion_large` real DEFAULT 0,`stock_quantity` real DEFAULT 0,`min_threshold` real DEFAULT 10,`is_active` numeric DEFAULT true,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_semi_finished_goods_category` ON `semi_finished_goods`(`category`);
+CREATE INDEX `idx_semi_finished_goods_is_active` ON `semi_finished_goods`(`is_active`);
+CREATE INDEX `idx_semi_finished_goods_name` ON `semi_finished_goods`(`name`);
+CREATE TABLE `semi_finished_recipes` (`id` integer PRIMARY KEY AUTOINCREMENT,`semi_finished_goods_id` integer NOT NULL,`name` text NOT NULL,`instructions` text,`yield_amount` real NOT NULL,`is_active` numeric DEFAULT true,`created_by` integer NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_semi_finished_goods_recipe` FOREIGN KEY (`semi_finished_goods_id`) REFERENCES `semi_finished_goods`(`id`),CONSTRAINT `fk_semi_finished_recipes_creator` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`));
+CREATE UNIQUE INDEX `idx_semi_finished_recipes_semi_finished_goods_id` ON `semi_finished_recipes`(`semi_finished_goods_id`);
+CREATE TABLE `semi_finished_recipe_ingredients` (`id` integer PRIMARY KEY AUTOINCREMENT,`semi_finished_recipe_id` integer NOT NULL,`ingredient_id` integer NOT NULL,`quantity` real NOT NULL,CONSTRAINT `fk_semi_finished_recipe_ingredients_ingredient` FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients`(`id`),CONSTRAINT `fk_semi_finished_recipes_ingredients` FOREIGN KEY (`semi_finished_recipe_id`) REFERENCES `semi_finished_recipes`(`id`));
+CREATE INDEX `idx_semi_finished_recipe_ingredients_ingredient_id` ON `semi_finished_recipe_ingredients`(`ingredient_id`);
+CREATE INDEX `idx_semi_finished_recipe_ingredients_semi_finished_recipe_id` ON `semi_finished_recipe_ingredients`(`semi_finished_recipe_id`);
+CREATE TABLE `semi_finished_inventories` (`id` integer PRIMARY KEY AUTOINCREMENT,`semi_finished_goods_id` integer NOT NULL,`quantity` real NOT NULL,`min_threshold` real NOT NULL,`last_updated` datetime NOT NULL,CONSTRAINT `fk_semi_finished_inventories_semi_finished_goods` FOREIGN KEY (`semi_finished_goods_id`) REFERENCES `semi_finished_goods`(`id`));
+CREATE INDEX `idx_semi_finished_inventories_last_updated` ON `semi_finished_inventories`(`last_updated`);
+CREATE UNIQUE INDEX `idx_semi_finished_inventories_semi_finished_goods_id` ON `semi_finished_inventories`(`semi_finished_goods_id`);
+CREATE TABLE `semi_finished_production_logs` (`id` integer PRIMARY KEY AUTOINCREMENT,`semi_finished_goods_id` integer NOT NULL,`quantity` real NOT NULL,`production_date` datetime NOT NULL,`created_by` integer NOT NULL,`notes` text,`created_at` datetime,CONSTRAINT `fk_semi_finished_production_logs_creator` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`),CONSTRAINT `fk_semi_finished_production_logs_semi_finished_goods` FOREIGN KEY (`semi_finished_goods_id`) REFERENCES `semi_finished_goods`(`id`));
+CREATE INDEX `idx_semi_finished_production_logs_production_date` ON `semi_finished_production_logs`(`production_date`);
+CREATE INDEX `idx_semi_finished_production_logs_semi_finished_goods_id` ON `semi_finished_production_logs`(`semi_finished_goods_id`);
+CREATE TABLE `semi_finished_movements` (`id` integer PRIMARY KEY AUTOINCREMENT,`semi_finished_goods_id` integer NOT NULL,`movement_type` text NOT NULL,`quantity` real NOT NULL,`reference` text,`movement_date` datetime NOT NULL,`created_by` integer NOT NULL,`notes` text,CONSTRAINT `fk_semi_finished_movements_creator` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`),CONSTRAINT `fk_semi_finished_movements_semi_finished_goods` FOREIGN KEY (`semi_finished_goods_id`) REFERENCES `semi_finished_goods`(`id`));
+CREATE INDEX `idx_semi_finished_movements_created_by` ON `semi_finished_movements`(`created_by`);
+CREATE INDEX `idx_semi_finished_movements_movement_date` ON `semi_finished_movements`(`movement_date`);
+CREATE INDEX `idx_semi_finished_movements_movement_type` ON `semi_finished_movements`(`movement_type`);
+CREATE INDEX `idx_semi_finished_movements_reference` ON `semi_finished_movements`(`reference`);
+CREATE INDEX `idx_semi_finished_movements_semi_finished_goods_id` ON `semi_finished_movements`(`semi_finished_goods_id`);
+CREATE TABLE `recipes` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`category` text,`photo_url` text,`instructions` text,`total_calories` real NOT NULL,`total_protein` real NOT NULL,`total_carbs` real NOT NULL,`total_fat` real NOT NULL,`version` integer NOT NULL DEFAULT 1,`is_active` numeric DEFAULT true,`created_by` integer NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_recipes_creator` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_recipes_category` ON `recipes`(`category`);
+CREATE INDEX `idx_recipes_created_by` ON `recipes`(`created_by`);
+CREATE INDEX `idx_recipes_is_active` ON `recipes`(`is_active`);
+CREATE INDEX `idx_recipes_name` ON `recipes`(`name`);
+CREATE TABLE `recipe_items` (`id` integer PRIMARY KEY AUTOINCREMENT,`recipe_id` integer NOT NULL,`semi_finished_goods_id` integer NOT NULL,`quantity` real NOT NULL,`quantity_per_portion_small` real DEFAULT 0,`quantity_per_portion_large` real DEFAULT 0,CONSTRAINT `fk_recipe_items_semi_finished_goods` FOREIGN KEY (`semi_finished_goods_id`) REFERENCES `semi_finished_goods`(`id`),CONSTRAINT `fk_recipes_recipe_items` FOREIGN KEY (`recipe_id`) REFERENCES `recipes`(`id`));
+CREATE INDEX `idx_recipe_items_recipe_id` ON `recipe_items`(`recipe_id`);
+CREATE INDEX `idx_recipe_items_semi_finished_goods_id` ON `recipe_items`(`semi_finished_goods_id`);
+CREATE TABLE `recipe_versions` (`id` integer PRIMARY KEY AUTOINCREMENT,`recipe_id` integer NOT NULL,`version` integer NOT NULL,`name` text NOT NULL,`category` text,`photo_url` text,`instructions` text,`total_calories` real NOT NULL,`total_protein` real NOT NULL,`total_carbs` real NOT NULL,`total_fat` real NOT NULL,`changes` text,`created_by` integer NOT NULL,`created_at` datetime,CONSTRAINT `fk_recipe_versions_creator` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_recipe_versions_recipe_id` ON `recipe_versions`(`recipe_id`);
+CREATE INDEX `idx_recipe_versions_version` ON `recipe_versions`(`version`);
+CREATE TABLE `menu_plans` (`id` integer PRIMARY KEY AUTOINCREMENT,`week_start` datetime NOT NULL,`week_end` datetime NOT NULL,`status` text NOT NULL,`approved_by` integer,`approved_at` datetime,`created_by` integer NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_menu_plans_approver` FOREIGN KEY (`approved_by`) REFERENCES `users`(`id`),CONSTRAINT `fk_menu_plans_creator` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_menu_plans_approved_by` ON `menu_plans`(`approved_by`);
+CREATE INDEX `idx_menu_plans_created_by` ON `menu_plans`(`created_by`);
+CREATE INDEX `idx_menu_plans_status` ON `menu_plans`(`status`);
+CREATE INDEX `idx_menu_plans_week_start` ON `menu_plans`(`week_start`);
+CREATE TABLE `menu_items` (`id` integer PRIMARY KEY AUTOINCREMENT,`menu_plan_id` integer NOT NULL,`date` datetime NOT NULL,`recipe_id` integer NOT NULL,`portions` integer NOT NULL,CONSTRAINT `fk_menu_items_recipe` FOREIGN KEY (`recipe_id`) REFERENCES `recipes`(`id`),CONSTRAINT `fk_menu_plans_menu_items` FOREIGN KEY (`menu_plan_id`) REFERENCES `menu_plans`(`id`));
+CREATE INDEX `idx_menu_items_date` ON `menu_items`(`date`);
+CREATE INDEX `idx_menu_items_menu_plan_id` ON `menu_items`(`menu_plan_id`);
+CREATE INDEX `idx_menu_items_recipe_id` ON `menu_items`(`recipe_id`);
+CREATE TABLE `menu_item_school_allocations` (`id` integer PRIMARY KEY AUTOINCREMENT,`menu_item_id` integer NOT NULL,`school_id` integer NOT NULL,`portions` integer NOT NULL,`portion_size` text NOT NULL,`date` datetime NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `chk_menu_item_school_allocations_portion_size` CHECK (portion_size IN ('small', 'large')),CONSTRAINT `chk_menu_item_school_allocations_portions` CHECK (portions > 0),CONSTRAINT `fk_menu_item_school_allocations_school` FOREIGN KEY (`school_id`) REFERENCES `schools`(`id`) ON DELETE RESTRICT,CONSTRAINT `fk_menu_items_school_allocations` FOREIGN KEY (`menu_item_id`) REFERENCES `menu_items`(`id`) ON DELETE CASCADE);
+CREATE INDEX `idx_menu_item_school_allocations_date` ON `menu_item_school_allocations`(`date`);
+CREATE INDEX `idx_menu_item_school_allocations_menu_item_id` ON `menu_item_school_allocations`(`menu_item_id`);
+CREATE INDEX `idx_menu_item_school_allocations_school_id` ON `menu_item_school_allocations`(`school_id`);
+CREATE TABLE `suppliers` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`contact_person` text,`phone_number` text,`email` text,`address` text,`product_category` text,`is_active` numeric DEFAULT true,`on_time_delivery` real DEFAULT 0,`quality_rating` real DEFAULT 0,`payment_term_days` integer DEFAULT 30,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_suppliers_is_active` ON `suppliers`(`is_active`);
+CREATE INDEX `idx_suppliers_name` ON `suppliers`(`name`);
+CREATE INDEX `idx_suppliers_product_category` ON `suppliers`(`product_category`);
+CREATE TABLE `purchase_orders` (`id` integer PRIMARY KEY AUTOINCREMENT,`po_number` text NOT NULL,`supplier_id` integer NOT NULL,`order_date` datetime NOT NULL,`expected_delivery` datetime,`status` text NOT NULL,`total_amount` integer NOT NULL,`approved_by` integer,`approved_at` datetime,`created_by` integer NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_purchase_orders_approver` FOREIGN KEY (`approved_by`) REFERENCES `users`(`id`),CONSTRAINT `fk_purchase_orders_creator` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`),CONSTRAINT `fk_purchase_orders_supplier` FOREIGN KEY (`supplier_id`) REFERENCES `suppliers`(`id`));
+CREATE INDEX `idx_purchase_orders_approved_by` ON `purchase_orders`(`approved_by`);
+CREATE INDEX `idx_purchase_orders_created_by` ON `purchase_orders`(`created_by`);
+CREATE INDEX `idx_purchase_orders_expected_delivery` ON `purchase_orders`(`expected_delivery`);
+CREATE INDEX `idx_purchase_orders_order_date` ON `purchase_orders`(`order_date`);
+CREATE INDEX `idx_purchase_orders_status` ON `purchase_orders`(`status`);
+CREATE INDEX `idx_purchase_orders_supplier_id` ON `purchase_orders`(`supplier_id`);
+CREATE UNIQUE INDEX `idx_purchase_orders_po_number` ON `purchase_orders`(`po_number`);
+CREATE TABLE `purchase_order_items` (`id` integer PRIMARY KEY AUTOINCREMENT,`po_id` integer NOT NULL,`ingredient_id` integer NOT NULL,`quantity` real NOT NULL,`unit_price` integer NOT NULL,`subtotal` integer NOT NULL,CONSTRAINT `fk_purchase_order_items_ingredient` FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients`(`id`),CONSTRAINT `fk_purchase_orders_po_items` FOREIGN KEY (`po_id`) REFERENCES `purchase_orders`(`id`));
+CREATE INDEX `idx_purchase_order_items_ingredient_id` ON `purchase_order_items`(`ingredient_id`);
+CREATE INDEX `idx_purchase_order_items_po_id` ON `purchase_order_items`(`po_id`);
+CREATE TABLE `goods_receipts` (`id` integer PRIMARY KEY AUTOINCREMENT,`grn_number` text NOT NULL,`po_id` integer NOT NULL,`receipt_date` datetime NOT NULL,`invoice_photo` text,`received_by` integer NOT NULL,`notes` text,`quality_rating` real DEFAULT 0,`created_at` datetime,CONSTRAINT `fk_goods_receipts_purchase_order` FOREIGN KEY (`po_id`) REFERENCES `purchase_orders`(`id`),CONSTRAINT `fk_goods_receipts_receiver` FOREIGN KEY (`received_by`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_goods_receipts_po_id` ON `goods_receipts`(`po_id`);
+CREATE INDEX `idx_goods_receipts_receipt_date` ON `goods_receipts`(`receipt_date`);
+CREATE INDEX `idx_goods_receipts_received_by` ON `goods_receipts`(`received_by`);
+CREATE UNIQUE INDEX `idx_goods_receipts_grn_number` ON `goods_receipts`(`grn_number`);
+CREATE TABLE `goods_receipt_items` (`id` integer PRIMARY KEY AUTOINCREMENT,`grn_id` integer NOT NULL,`ingredient_id` integer NOT NULL,`ordered_quantity` real NOT NULL,`received_quantity` real NOT NULL,`expiry_date` datetime,CONSTRAINT `fk_goods_receipt_items_ingredient` FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients`(`id`),CONSTRAINT `fk_goods_receipts_grn_items` FOREIGN KEY (`grn_id`) REFERENCES `goods_receipts`(`id`));
+CREATE INDEX `idx_goods_receipt_items_expiry_date` ON `goods_receipt_items`(`expiry_date`);
+CREATE INDEX `idx_goods_receipt_items_grn_id` ON `goods_receipt_items`(`grn_id`);
+CREATE INDEX `idx_goods_receipt_items_ingredient_id` ON `goods_receipt_items`(`ingredient_id`);
+CREATE TABLE `inventory_items` (`id` integer PRIMARY KEY AUTOINCREMENT,`ingredient_id` integer NOT NULL,`quantity` real NOT NULL,`min_threshold` real NOT NULL,`last_updated` datetime NOT NULL,CONSTRAINT `fk_inventory_items_ingredient` FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients`(`id`));
+CREATE INDEX `idx_inventory_items_last_updated` ON `inventory_items`(`last_updated`);
+CREATE UNIQUE INDEX `idx_inventory_items_ingredient_id` ON `inventory_items`(`ingredient_id`);
+CREATE TABLE `inventory_movements` (`id` integer PRIMARY KEY AUTOINCREMENT,`ingredient_id` integer NOT NULL,`movement_type` text NOT NULL,`quantity` real NOT NULL,`reference` text,`movement_date` datetime NOT NULL,`created_by` integer NOT NULL,`notes` text,CONSTRAINT `fk_inventory_movements_creator` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`),CONSTRAINT `fk_inventory_movements_ingredient` FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients`(`id`));
+CREATE INDEX `idx_inventory_movements_created_by` ON `inventory_movements`(`created_by`);
+CREATE INDEX `idx_inventory_movements_ingredient_id` ON `inventory_movements`(`ingredient_id`);
+CREATE INDEX `idx_inventory_movements_movement_date` ON `inventory_movements`(`movement_date`);
+CREATE INDEX `idx_inventory_movements_movement_type` ON `inventory_movements`(`movement_type`);
+CREATE INDEX `idx_inventory_movements_reference` ON `inventory_movements`(`reference`);
+CREATE TABLE `stok_opname_forms` (`id` integer PRIMARY KEY AUTOINCREMENT,`form_number` text NOT NULL,`created_by` integer NOT NULL,`created_at` datetime NOT NULL,`status` text NOT NULL,`notes` text,`approved_by` integer,`approved_at` datetime,`rejection_reason` text,`is_processed` numeric DEFAULT false,`updated_at` datetime,CONSTRAINT `fk_stok_opname_forms_approver` FOREIGN KEY (`approved_by`) REFERENCES `users`(`id`),CONSTRAINT `fk_stok_opname_forms_creator` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_stok_opname_forms_approved_by` ON `stok_opname_forms`(`approved_by`);
+CREATE INDEX `idx_stok_opname_forms_created_at` ON `stok_opname_forms`(`created_at`);
+CREATE INDEX `idx_stok_opname_forms_created_by` ON `stok_opname_forms`(`created_by`);
+CREATE INDEX `idx_stok_opname_forms_is_processed` ON `stok_opname_forms`(`is_processed`);
+CREATE INDEX `idx_stok_opname_forms_status` ON `stok_opname_forms`(`status`);
+CREATE UNIQUE INDEX `idx_stok_opname_forms_form_number` ON `stok_opname_forms`(`form_number`);
+CREATE TABLE `stok_opname_items` (`id` integer PRIMARY KEY AUTOINCREMENT,`form_id` integer NOT NULL,`ingredient_id` integer NOT NULL,`system_stock` real NOT NULL,`physical_count` real NOT NULL,`difference` real NOT NULL,`item_notes` text,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_stok_opname_forms_items` FOREIGN KEY (`form_id`) REFERENCES `stok_opname_forms`(`id`) ON DELETE CASCADE,CONSTRAINT `fk_stok_opname_items_ingredient` FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients`(`id`));
+CREATE INDEX `idx_stok_opname_items_form_id` ON `stok_opname_items`(`form_id`);
+CREATE INDEX `idx_stok_opname_items_ingredient_id` ON `stok_opname_items`(`ingredient_id`);
+CREATE TABLE `schools` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`address` text,`latitude` real NOT NULL,`longitude` real NOT NULL,`contact_person` text,`phone_number` text,`student_count` integer NOT NULL,`category` text,`student_count_grade13` integer DEFAULT 0,`student_count_grade46` integer DEFAULT 0,`staff_count` integer DEFAULT 0,`npsn` text,`principal_name` text,`school_email` text,`school_phone` text,`committee_count` integer DEFAULT 0,`cooperation_letter_url` text,`is_active` numeric DEFAULT true,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_schools_is_active` ON `schools`(`is_active`);
+CREATE INDEX `idx_schools_name` ON `schools`(`name`);
+CREATE TABLE `delivery_tasks` (`id` integer PRIMARY KEY AUTOINCREMENT,`task_date` datetime NOT NULL,`driver_id` integer NOT NULL,`school_id` integer NOT NULL,`portions` integer NOT NULL,`status` text NOT NULL,`current_stage` integer NOT NULL DEFAULT 1,`route_order` integer NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_delivery_tasks_driver` FOREIGN KEY (`driver_id`) REFERENCES `users`(`id`),CONSTRAINT `fk_delivery_tasks_school` FOREIGN KEY (`school_id`) REFERENCES `schools`(`id`));
+CREATE INDEX `idx_delivery_tasks_current_stage` ON `delivery_tasks`(`current_stage`);
+CREATE INDEX `idx_delivery_tasks_driver_id` ON `delivery_tasks`(`driver_id`);
+CREATE INDEX `idx_delivery_tasks_school_id` ON `delivery_tasks`(`school_id`);
+CREATE INDEX `idx_delivery_tasks_status` ON `delivery_tasks`(`status`);
+CREATE INDEX `idx_delivery_tasks_task_date` ON `delivery_tasks`(`task_date`);
+CREATE TABLE `delivery_menu_items` (`id` integer PRIMARY KEY AUTOINCREMENT,`delivery_task_id` integer NOT NULL,`recipe_id` integer NOT NULL,`portions` integer NOT NULL,CONSTRAINT `fk_delivery_menu_items_recipe` FOREIGN KEY (`recipe_id`) REFERENCES `recipes`(`id`),CONSTRAINT `fk_delivery_tasks_menu_items` FOREIGN KEY (`delivery_task_id`) REFERENCES `delivery_tasks`(`id`));
+CREATE INDEX `idx_delivery_menu_items_delivery_task_id` ON `delivery_menu_items`(`delivery_task_id`);
+CREATE INDEX `idx_delivery_menu_items_recipe_id` ON `delivery_menu_items`(`recipe_id`);
+CREATE TABLE `electronic_pods` (`id` integer PRIMARY KEY AUTOINCREMENT,`delivery_task_id` integer NOT NULL,`photo_url` text,`signature_url` text,`latitude` real NOT NULL,`longitude` real NOT NULL,`recipient_name` text,`ompreng_drop_off` integer NOT NULL,`ompreng_pick_up` integer NOT NULL,`completed_at` datetime NOT NULL,CONSTRAINT `fk_electronic_pods_delivery_task` FOREIGN KEY (`delivery_task_id`) REFERENCES `delivery_tasks`(`id`));
+CREATE INDEX `idx_electronic_pods_completed_at` ON `electronic_pods`(`completed_at`);
+CREATE UNIQUE INDEX `idx_electronic_pods_delivery_task_id` ON `electronic_pods`(`delivery_task_id`);
+CREATE TABLE `ompreng_trackings` (`id` integer PRIMARY KEY AUTOINCREMENT,`school_id` integer NOT NULL,`date` datetime NOT NULL,`drop_off` integer NOT NULL,`pick_up` integer NOT NULL,`balance` integer NOT NULL,`recorded_by` integer NOT NULL,`created_at` datetime,CONSTRAINT `fk_ompreng_trackings_recorder` FOREIGN KEY (`recorded_by`) REFERENCES `users`(`id`),CONSTRAINT `fk_ompreng_trackings_school` FOREIGN KEY (`school_id`) REFERENCES `schools`(`id`));
+CREATE INDEX `idx_ompreng_trackings_date` ON `ompreng_trackings`(`date`);
+CREATE INDEX `idx_ompreng_trackings_recorded_by` ON `ompreng_trackings`(`recorded_by`);
+CREATE INDEX `idx_ompreng_trackings_school_id` ON `ompreng_trackings`(`school_id`);
+CREATE TABLE `ompreng_inventories` (`id` integer PRIMARY KEY AUTOINCREMENT,`total_owned` integer NOT NULL,`at_kitchen` integer NOT NULL,`in_circulation` integer NOT NULL,`missing` integer NOT NULL,`last_updated` datetime NOT NULL);
+CREATE INDEX `idx_ompreng_inventories_last_updated` ON `ompreng_inventories`(`last_updated`);
+CREATE TABLE `delivery_records` (`id` integer PRIMARY KEY AUTOINCREMENT,`delivery_date` datetime NOT NULL,`school_id` integer NOT NULL,`driver_id` integer,`menu_item_id` integer NOT NULL,`portions` integer NOT NULL,`portions_small` integer NOT NULL DEFAULT 0,`portions_large` integer NOT NULL DEFAULT 0,`current_status` text NOT NULL,`current_stage` integer NOT NULL DEFAULT 1,`ompreng_count` integer NOT NULL,`ompreng_received` integer,`ompreng_difference_reason` text,`pickup_task_id` integer,`route_order` integer DEFAULT 0,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_delivery_records_driver` FOREIGN KEY (`driver_id`) REFERENCES `users`(`id`),CONSTRAINT `fk_delivery_records_menu_item` FOREIGN KEY (`menu_item_id`) REFERENCES `menu_items`(`id`),CONSTRAINT `fk_delivery_records_school` FOREIGN KEY (`school_id`) REFERENCES `schools`(`id`));
+CREATE INDEX `idx_delivery_records_current_stage` ON `delivery_records`(`current_stage`);
+CREATE INDEX `idx_delivery_records_current_status` ON `delivery_records`(`current_status`);
+CREATE INDEX `idx_delivery_records_delivery_date` ON `delivery_records`(`delivery_date`);
+CREATE INDEX `idx_delivery_records_driver_id` ON `delivery_records`(`driver_id`);
+CREATE INDEX `idx_delivery_records_menu_item_id` ON `delivery_records`(`menu_item_id`);
+CREATE INDEX `idx_delivery_records_pickup_task_id` ON `delivery_records`(`pickup_task_id`);
+CREATE INDEX `idx_delivery_records_school_id` ON `delivery_records`(`school_id`);
+CREATE TABLE `status_transitions` (`id` integer PRIMARY KEY AUTOINCREMENT,`delivery_record_id` integer NOT NULL,`from_status` text,`to_status` text NOT NULL,`stage` integer NOT NULL,`transitioned_at` datetime NOT NULL,`transitioned_by` integer NOT NULL,`notes` text,`media_url` text,`media_type` text,`thumbnail_url` text,CONSTRAINT `fk_status_transitions_delivery_record` FOREIGN KEY (`delivery_record_id`) REFERENCES `delivery_records`(`id`),CONSTRAINT `fk_status_transitions_user` FOREIGN KEY (`transitioned_by`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_status_transitions_delivery_record_id` ON `status_transitions`(`delivery_record_id`);
+CREATE INDEX `idx_status_transitions_stage` ON `status_transitions`(`stage`);
+CREATE INDEX `idx_status_transitions_transitioned_at` ON `status_transitions`(`transitioned_at`);
+CREATE INDEX `idx_status_transitions_transitioned_by` ON `status_transitions`(`transitioned_by`);
+CREATE TABLE `ompreng_cleanings` (`id` integer PRIMARY KEY AUTOINCREMENT,`delivery_record_id` integer NOT NULL,`ompreng_count` integer NOT NULL,`cleaning_status` text NOT NULL,`started_at` datetime,`completed_at` datetime,`cleaned_by` integer,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_ompreng_cleanings_cleaner` FOREIGN KEY (`cleaned_by`) REFERENCES `users`(`id`),CONSTRAINT `fk_ompreng_cleanings_delivery_record` FOREIGN KEY (`delivery_record_id`) REFERENCES `delivery_records`(`id`));
+CREATE INDEX `idx_ompreng_cleanings_cleaned_by` ON `ompreng_cleanings`(`cleaned_by`);
+CREATE INDEX `idx_ompreng_cleanings_delivery_record_id` ON `ompreng_cleanings`(`delivery_record_id`);
+CREATE TABLE `pickup_tasks` (`id` integer PRIMARY KEY AUTOINCREMENT,`task_date` datetime NOT NULL,`driver_id` integer NOT NULL,`status` text NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_pickup_tasks_driver` FOREIGN KEY (`driver_id`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_pickup_tasks_driver_id` ON `pickup_tasks`(`driver_id`);
+CREATE INDEX `idx_pickup_tasks_status` ON `pickup_tasks`(`status`);
+CREATE INDEX `idx_pickup_tasks_task_date` ON `pickup_tasks`(`task_date`);
+CREATE TABLE `delivery_reviews` (`id` integer PRIMARY KEY AUTOINCREMENT,`delivery_record_id` integer NOT NULL,`school_id` integer NOT NULL,`reviewer_name` text,`reviewer_role` text,`rating_food_taste` integer NOT NULL,`rating_food_cleanliness` integer NOT NULL,`rating_menu_accuracy` integer NOT NULL,`rating_portion_size` integer NOT NULL,`rating_menu_variety` integer NOT NULL,`rating_delivery_time` integer NOT NULL,`rating_driver_attitude` integer NOT NULL,`rating_food_condition` integer NOT NULL,`rating_driver_tidiness` integer NOT NULL,`rating_service_consistency` integer NOT NULL,`average_menu_rating` real NOT NULL,`average_service_rating` real NOT NULL,`overall_rating` real NOT NULL,`comments` text,`photo_url` text,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_delivery_reviews_delivery_record` FOREIGN KEY (`delivery_record_id`) REFERENCES `delivery_records`(`id`),CONSTRAINT `fk_delivery_reviews_school` FOREIGN KEY (`school_id`) REFERENCES `schools`(`id`));
+CREATE INDEX `idx_delivery_reviews_created_at` ON `delivery_reviews`(`created_at`);
+CREATE INDEX `idx_delivery_reviews_school_id` ON `delivery_reviews`(`school_id`);
+CREATE UNIQUE INDEX `idx_delivery_reviews_delivery_record_id` ON `delivery_reviews`(`delivery_record_id`);
+CREATE TABLE `employees` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`nik` text NOT NULL,`full_name` text NOT NULL,`email` text NOT NULL,`phone_number` text,`position` text,`join_date` datetime NOT NULL,`is_active` numeric DEFAULT true,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_employees_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_employees_full_name` ON `employees`(`full_name`);
+CREATE INDEX `idx_employees_is_active` ON `employees`(`is_active`);
+CREATE INDEX `idx_employees_position` ON `employees`(`position`);
+CREATE UNIQUE INDEX `idx_employees_email` ON `employees`(`email`);
+CREATE UNIQUE INDEX `idx_employees_nik` ON `employees`(`nik`);
+CREATE UNIQUE INDEX `idx_employees_user_id` ON `employees`(`user_id`);
+CREATE TABLE `attendances` (`id` integer PRIMARY KEY AUTOINCREMENT,`employee_id` integer NOT NULL,`date` datetime NOT NULL,`check_in` datetime NOT NULL,`check_out` datetime,`work_hours` real DEFAULT 0,`ss_id` text,`bss_id` text,`created_at` datetime,CONSTRAINT `fk_attendances_employee` FOREIGN KEY (`employee_id`) REFERENCES `employees`(`id`));
+CREATE INDEX `idx_attendances_date` ON `attendances`(`date`);
+CREATE INDEX `idx_attendances_employee_id` ON `attendances`(`employee_id`);
+CREATE TABLE `wi_fi_configs` (`id` integer PRIMARY KEY AUTOINCREMENT,`ss_id` text NOT NULL,`bss_id` text NOT NULL,`location` text,`ip_range` text,`allowed_ips` text,`is_active` numeric DEFAULT true,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_wi_fi_configs_bss_id` ON `wi_fi_configs`(`bss_id`);
+CREATE INDEX `idx_wi_fi_configs_is_active` ON `wi_fi_configs`(`is_active`);
+CREATE INDEX `idx_wi_fi_configs_ss_id` ON `wi_fi_configs`(`ss_id`);
+CREATE TABLE `gps_configs` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`latitude` real NOT NULL,`longitude` real NOT NULL,`radius` integer NOT NULL DEFAULT 100,`address` text,`description` text,`is_active` numeric DEFAULT true,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_gps_configs_is_active` ON `gps_configs`(`is_active`);
+CREATE TABLE `kitchen_assets` (`id` integer PRIMARY KEY AUTOINCREMENT,`asset_code` text NOT NULL,`name` text NOT NULL,`category` text,`purchase_date` datetime NOT NULL,`purchase_price` integer NOT NULL,`current_value` integer NOT NULL,`depreciation_method` text NOT NULL DEFAULT "straight_line",`depreciation_rate` real NOT NULL,`useful_life_months` integer DEFAULT 0,`salvage_value` integer DEFAULT 0,`total_units` real DEFAULT 0,`accumulated_depreciation` integer DEFAULT 0,`condition` text,`location` text,`status` text NOT NULL DEFAULT "active",`disposal_date` datetime,`disposal_proceeds` integer DEFAULT 0,`disposal_gain_loss` integer DEFAULT 0,`disposal_notes` text,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_kitchen_assets_category` ON `kitchen_assets`(`category`);
+CREATE INDEX `idx_kitchen_assets_condition` ON `kitchen_assets`(`condition`);
+CREATE INDEX `idx_kitchen_assets_name` ON `kitchen_assets`(`name`);
+CREATE INDEX `idx_kitchen_assets_purchase_date` ON `kitchen_assets`(`purchase_date`);
+CREATE INDEX `idx_kitchen_assets_status` ON `kitchen_assets`(`status`);
+CREATE UNIQUE INDEX `idx_kitchen_assets_asset_code` ON `kitchen_assets`(`asset_code`);
+CREATE TABLE `asset_maintenances` (`id` integer PRIMARY KEY AUTOINCREMENT,`asset_id` integer NOT NULL,`maintenance_date` datetime NOT NULL,`description` text,`cost` integer NOT NULL,`performed_by` text,`plan_id` integer,`due_date` datetime,`created_at` datetime,CONSTRAINT `fk_kitchen_assets_maintenance_records` FOREIGN KEY (`asset_id`) REFERENCES `kitchen_assets`(`id`));
+CREATE INDEX `idx_asset_maintenances_asset_id` ON `asset_maintenances`(`asset_id`);
+CREATE INDEX `idx_asset_maintenances_maintenance_date` ON `asset_maintenances`(`maintenance_date`);
+CREATE INDEX `idx_asset_maintenances_plan_id` ON `asset_maintenances`(`plan_id`);
+CREATE TABLE `maintenance_plans` (`id` integer PRIMARY KEY AUTOINCREMENT,`asset_id` integer NOT NULL,`name` text NOT NULL,`description` text,`interval_type` text NOT NULL,`interval_days` integer DEFAULT 0,`interval_units` real DEFAULT 0,`is_safety_critical` numeric DEFAULT false,`estimated_cost` integer DEFAULT 0,`responsible_user_id` integer NOT NULL,`start_date` datetime NOT NULL,`last_completed_at` datetime,`last_completed_units` real DEFAULT 0,`next_due_date` datetime,`last_reminded_at` datetime,`is_active` numeric DEFAULT true,`created_by` integer NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_maintenance_plans_asset` FOREIGN KEY (`asset_id`) REFERENCES `kitchen_assets`(`id`),CONSTRAINT `fk_maintenance_plans_responsible_user` FOREIGN KEY (`responsible_user_id`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_maintenance_plans_asset_id` ON `maintenance_plans`(`asset_id`);
+CREATE INDEX `idx_maintenance_plans_created_by` ON `maintenance_plans`(`created_by`);
+CREATE INDEX `idx_maintenance_plans_is_active` ON `maintenance_plans`(`is_active`);
+CREATE INDEX `idx_maintenance_plans_is_safety_critical` ON `maintenance_plans`(`is_safety_critical`);
+CREATE INDEX `idx_maintenance_plans_next_due_date` ON `maintenance_plans`(`next_due_date`);
+CREATE INDEX `idx_maintenance_plans_responsible_user_id` ON `maintenance_plans`(`responsible_user_id`);
+CREATE TABLE `asset_audits` (`id` integer PRIMARY KEY AUTOINCREMENT,`audit_number` text NOT NULL,`location` text,`status` text NOT NULL,`notes` text,`created_by` integer NOT NULL,`submitted_at` datetime,`approved_by` integer,`approved_at` datetime,`rejection_reason` text,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_asset_audits_approver` FOREIGN KEY (`approved_by`) REFERENCES `users`(`id`),CONSTRAINT `fk_asset_audits_creator` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_asset_audits_approved_by` ON `asset_audits`(`approved_by`);
+CREATE INDEX `idx_asset_audits_created_at` ON `asset_audits`(`created_at`);
+CREATE INDEX `idx_asset_audits_created_by` ON `asset_audits`(`created_by`);
+CREATE INDEX `idx_asset_audits_status` ON `asset_audits`(`status`);
+CREATE UNIQUE INDEX `idx_asset_audits_audit_number` ON `asset_audits`(`audit_number`);
+CREATE TABLE `asset_audit_items` (`id` integer PRIMARY KEY AUTOINCREMENT,`audit_id` integer NOT NULL,`asset_id` integer NOT NULL,`expected_location` text,`expected_condition` text,`found_location` text,`found_condition` text,`result` text NOT NULL,`scanned_by` integer,`scanned_at` datetime,`notes` text,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_asset_audit_items_asset` FOREIGN KEY (`asset_id`) REFERENCES `kitchen_assets`(`id`),CONSTRAINT `fk_asset_audits_items` FOREIGN KEY (`audit_id`) REFERENCES `asset_audits`(`id`));
+CREATE INDEX `idx_asset_audit_items_asset_id` ON `asset_audit_items`(`asset_id`);
+CREATE INDEX `idx_asset_audit_items_audit_id` ON `asset_audit_items`(`audit_id`);
+CREATE INDEX `idx_asset_audit_items_result` ON `asset_audit_items`(`result`);
+CREATE TABLE `asset_usage_logs` (`id` integer PRIMARY KEY AUTOINCREMENT,`asset_id` integer NOT NULL,`usage_date` datetime NOT NULL,`units` real NOT NULL,`notes` text,`created_by` integer NOT NULL,`created_at` datetime);
+CREATE INDEX `idx_asset_usage_logs_asset_id` ON `asset_usage_logs`(`asset_id`);
+CREATE INDEX `idx_asset_usage_logs_created_by` ON `asset_usage_logs`(`created_by`);
+CREATE INDEX `idx_asset_usage_logs_usage_date` ON `asset_usage_logs`(`usage_date`);
+CREATE TABLE `depreciation_runs` (`id` integer PRIMARY KEY AUTOINCREMENT,`year` integer NOT NULL,`month` integer NOT NULL,`status` text NOT NULL,`asset_count` integer NOT NULL,`total_amount` integer NOT NULL,`posted_by` integer,`reversed_by` integer,`reversed_at` datetime,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_depreciation_run_period` ON `depreciation_runs`(`year`,`month`);
+CREATE INDEX `idx_depreciation_runs_posted_by` ON `depreciation_runs`(`posted_by`);
+CREATE INDEX `idx_depreciation_runs_status` ON `depreciation_runs`(`status`);
+CREATE TABLE `depreciation_run_lines` (`id` integer PRIMARY KEY AUTOINCREMENT,`run_id` integer NOT NULL,`asset_id` integer NOT NULL,`method` text NOT NULL,`amount` integer NOT NULL,`units_used` real DEFAULT 0,`book_value_before` integer NOT NULL,`book_value_after` integer NOT NULL,`journal_entry_id` integer,CONSTRAINT `fk_depreciation_run_lines_asset` FOREIGN KEY (`asset_id`) REFERENCES `kitchen_assets`(`id`),CONSTRAINT `fk_depreciation_runs_lines` FOREIGN KEY (`run_id`) REFERENCES `depreciation_runs`(`id`));
+CREATE INDEX `idx_depreciation_run_lines_asset_id` ON `depreciation_run_lines`(`asset_id`);
+CREATE INDEX `idx_depreciation_run_lines_journal_entry_id` ON `depreciation_run_lines`(`journal_entry_id`);
+CREATE INDEX `idx_depreciation_run_lines_run_id` ON `depreciation_run_lines`(`run_id`);
+CREATE TABLE `cash_flow_entries` (`id` integer PRIMARY KEY AUTOINCREMENT,`transaction_id` text NOT NULL,`date` datetime NOT NULL,`category` text NOT NULL,`type` text NOT NULL,`amount` integer NOT NULL,`description` text,`reference` text,`is_payable` numeric DEFAULT false,`funding_tranche_id` integer,`created_by` integer NOT NULL,`created_at` datetime,CONSTRAINT `fk_cash_flow_entries_creator` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_cash_flow_entries_category` ON `cash_flow_entries`(`category`);
+CREATE INDEX `idx_cash_flow_entries_created_by` ON `cash_flow_entries`(`created_by`);
+CREATE INDEX `idx_cash_flow_entries_date` ON `cash_flow_entries`(`date`);
+CREATE INDEX `idx_cash_flow_entries_funding_tranche_id` ON `cash_flow_entries`(`funding_tranche_id`);
+CREATE INDEX `idx_cash_flow_entries_reference` ON `cash_flow_entries`(`reference`);
+CREATE INDEX `idx_cash_flow_entries_type` ON `cash_flow_entries`(`type`);
+CREATE UNIQUE INDEX `idx_cash_flow_entries_transaction_id` ON `cash_flow_entries`(`transaction_id`);
+CREATE TABLE `budget_targets` (`id` integer PRIMARY KEY AUTOINCREMENT,`year` integer NOT NULL,`month` integer NOT NULL,`category` text NOT NULL,`target` integer NOT NULL,`actual` integer DEFAULT 0,`notes` text,`created_by` integer,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_budget_targets_category` ON `budget_targets`(`category`);
+CREATE INDEX `idx_budget_targets_created_by` ON `budget_targets`(`created_by`);
+CREATE INDEX `idx_budget_targets_month` ON `budget_targets`(`month`);
+CREATE INDEX `idx_budget_targets_year` ON `budget_targets`(`year`);
+CREATE UNIQUE INDEX `idx_budget_period` ON `budget_targets`(`year`,`month`,`category`);
+CREATE TABLE `budget_alerts` (`id` integer PRIMARY KEY AUTOINCREMENT,`budget_target_id` integer NOT NULL,`threshold` integer NOT NULL,`actual` integer,`target` integer,`absorption_rate` real,`created_at` datetime);
+CREATE UNIQUE INDEX `idx_budget_alert` ON `budget_alerts`(`budget_target_id`,`threshold`);
+CREATE TABLE `accounts` (`id` integer PRIMARY KEY AUTOINCREMENT,`code` text NOT NULL,`name` text NOT NULL,`type` text NOT NULL,`normal_balance` text NOT NULL,`category` text,`description` text,`is_system` numeric DEFAULT false,`is_active` numeric DEFAULT true,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_accounts_category` ON `accounts`(`category`);
+CREATE INDEX `idx_accounts_is_active` ON `accounts`(`is_active`);
+CREATE INDEX `idx_accounts_type` ON `accounts`(`type`);
+CREATE UNIQUE INDEX `idx_accounts_code` ON `accounts`(`code`);
+CREATE TABLE `journal_entries` (`id` integer PRIMARY KEY AUTOINCREMENT,`entry_number` text NOT NULL,`date` datetime NOT NULL,`description` text,`source_type` text NOT NULL,`source_ref` text,`reference` text,`status` text NOT NULL,`reversal_of_id` integer,`total_amount` integer NOT NULL,`created_by` integer,`created_at` datetime);
+CREATE INDEX `idx_journal_entries_created_by` ON `journal_entries`(`created_by`);
+CREATE INDEX `idx_journal_entries_date` ON `journal_entries`(`date`);
+CREATE INDEX `idx_journal_entries_reference` ON `journal_entries`(`reference`);
+CREATE INDEX `idx_journal_entries_reversal_of_id` ON `journal_entries`(`reversal_of_id`);
+CREATE INDEX `idx_journal_entries_status` ON `journal_entries`(`status`);
+CREATE INDEX `idx_journal_source` ON `journal_entries`(`source_type`,`source_ref`);
+CREATE UNIQUE INDEX `idx_journal_entries_entry_number` ON `journal_entries`(`entry_number`);
+CREATE TABLE `journal_lines` (`id` integer PRIMARY KEY AUTOINCREMENT,`journal_entry_id` integer NOT NULL,`account_id` integer NOT NULL,`debit` integer NOT NULL DEFAULT 0,`credit` integer NOT NULL DEFAULT 0,`description` text,CONSTRAINT `fk_journal_entries_lines` FOREIGN KEY (`journal_entry_id`) REFERENCES `journal_entries`(`id`),CONSTRAINT `fk_journal_lines_account` FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`));
+CREATE INDEX `idx_journal_lines_account_id` ON `journal_lines`(`account_id`);
+CREATE INDEX `idx_journal_lines_journal_entry_id` ON `journal_lines`(`journal_entry_id`);
+CREATE TABLE `supplier_invoices` (`id` integer PRIMARY KEY AUTOINCREMENT,`invoice_number` text NOT NULL,`supplier_id` integer NOT NULL,`po_id` integer NOT NULL,`grn_id` integer NOT NULL,`invoice_date` datetime NOT NULL,`due_date` datetime NOT NULL,`subtotal` integer NOT NULL,`tax_amount` integer DEFAULT 0,`total_amount` integer NOT NULL,`received_amount` integer DEFAULT 0,`paid_amount` integer DEFAULT 0,`status` text NOT NULL,`match_status` text NOT NULL,`match_notes` text,`invoice_photo` text,`notes` text,`approved_by` integer,`approved_at` datetime,`created_by` integer NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_supplier_invoices_goods_receipt` FOREIGN KEY (`grn_id`) REFERENCES `goods_receipts`(`id`),CONSTRAINT `fk_supplier_invoices_purchase_order` FOREIGN KEY (`po_id`) REFERENCES `purchase_orders`(`id`),CONSTRAINT `fk_supplier_invoices_supplier` FOREIGN KEY (`supplier_id`) REFERENCES `suppliers`(`id`));
+CREATE INDEX `idx_supplier_invoice_number` ON `supplier_invoices`(`invoice_number`,`supplier_id`);
+CREATE INDEX `idx_supplier_invoices_approved_by` ON `supplier_invoices`(`approved_by`);
+CREATE INDEX `idx_supplier_invoices_created_by` ON `supplier_invoices`(`created_by`);
+CREATE INDEX `idx_supplier_invoices_due_date` ON `supplier_invoices`(`due_date`);
+CREATE INDEX `idx_supplier_invoices_grn_id` ON `supplier_invoices`(`grn_id`);
+CREATE INDEX `idx_supplier_invoices_invoice_date` ON `supplier_invoices`(`invoice_date`);
+CREATE INDEX `idx_supplier_invoices_match_status` ON `supplier_invoices`(`match_status`);
+CREATE INDEX `idx_supplier_invoices_po_id` ON `supplier_invoices`(`po_id`);
+CREATE INDEX `idx_supplier_invoices_status` ON `supplier_invoices`(`status`);
+CREATE TABLE `supplier_invoice_items` (`id` integer PRIMARY KEY AUTOINCREMENT,`invoice_id` integer NOT NULL,`ingredient_id` integer NOT NULL,`quantity` real NOT NULL,`unit_price` integer NOT NULL,`subtotal` integer NOT NULL,`ordered_quantity` real DEFAULT 0,`received_quantity` real DEFAULT 0,`po_unit_price` integer DEFAULT 0,`quantity_variance` real DEFAULT 0,`price_variance` real DEFAULT 0,`match_status` text,`match_notes` text,CONSTRAINT `fk_supplier_invoice_items_ingredient` FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients`(`id`),CONSTRAINT `fk_supplier_invoices_items` FOREIGN KEY (`invoice_id`) REFERENCES `supplier_invoices`(`id`));
+CREATE INDEX `idx_supplier_invoice_items_ingredient_id` ON `supplier_invoice_items`(`ingredient_id`);
+CREATE INDEX `idx_supplier_invoice_items_invoice_id` ON `supplier_invoice_items`(`invoice_id`);
+CREATE TABLE `supplier_payments` (`id` integer PRIMARY KEY AUTOINCREMENT,`payment_number` text NOT NULL,`invoice_id` integer NOT NULL,`supplier_id` integer NOT NULL,`payment_date` datetime NOT NULL,`amount` integer NOT NULL,`method` text NOT NULL,`bank_reference` text,`bank_account` text,`notes` text,`created_by` integer NOT NULL,`created_at` datetime,CONSTRAINT `fk_supplier_invoices_payments` FOREIGN KEY (`invoice_id`) REFERENCES `supplier_invoices`(`id`),CONSTRAINT `fk_supplier_payments_supplier` FOREIGN KEY (`supplier_id`) REFERENCES `suppliers`(`id`));
+CREATE INDEX `idx_supplier_payments_bank_reference` ON `supplier_payments`(`bank_reference`);
+CREATE INDEX `idx_supplier_payments_created_by` ON `supplier_payments`(`created_by`);
+CREATE INDEX `idx_supplier_payments_invoice_id` ON `supplier_payments`(`invoice_id`);
+CREATE INDEX `idx_supplier_payments_payment_date` ON `supplier_payments`(`payment_date`);
+CREATE INDEX `idx_supplier_payments_supplier_id` ON `supplier_payments`(`supplier_id`);
+CREATE UNIQUE INDEX `idx_supplier_payments_payment_number` ON `supplier_payments`(`payment_number`);
+CREATE TABLE `funding_tranches` (`id` integer PRIMARY KEY AUTOINCREMENT,`tranche_number` text NOT NULL,`source` text NOT NULL,`reference_number` text,`received_date` datetime NOT NULL,`amount` integer NOT NULL,`period_start` datetime NOT NULL,`period_end` datetime NOT NULL,`target_portions` integer DEFAULT 0,`description` text,`cash_flow_entry_id` integer,`created_by` integer NOT NULL,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_funding_tranches_cash_flow_entry_id` ON `funding_tranches`(`cash_flow_entry_id`);
+CREATE INDEX `idx_funding_tranches_created_by` ON `funding_tranches`(`created_by`);
+CREATE INDEX `idx_funding_tranches_period_end` ON `funding_tranches`(`period_end`);
+CREATE INDEX `idx_funding_tranches_period_start` ON `funding_tranches`(`period_start`);
+CREATE INDEX `idx_funding_tranches_received_date` ON `funding_tranches`(`received_date`);
+CREATE INDEX `idx_funding_tranches_reference_number` ON `funding_tranches`(`reference_number`);
+CREATE UNIQUE INDEX `idx_funding_tranches_tranche_number` ON `funding_tranches`(`tranche_number`);
+CREATE TABLE `funding_tranche_schools` (`id` integer PRIMARY KEY AUTOINCREMENT,`tranche_id` integer NOT NULL,`school_id` integer NOT NULL,`target_portions` integer DEFAULT 0,CONSTRAINT `fk_funding_tranche_schools_school` FOREIGN KEY (`school_id`) REFERENCES `schools`(`id`),CONSTRAINT `fk_funding_tranches_schools` FOREIGN KEY (`tranche_id`) REFERENCES `funding_tranches`(`id`));
+CREATE UNIQUE INDEX `idx_tranche_school` ON `funding_tranche_schools`(`tranche_id`,`school_id`);
+CREATE TABLE `bank_statements` (`id` integer PRIMARY KEY AUTOINCREMENT,`bank_name` text,`account_number` text,`format` text NOT NULL,`file_name` text,`period_start` datetime,`period_end` datetime,`opening_balance` integer DEFAULT 0,`closing_balance` integer DEFAULT 0,`total_credit` integer DEFAULT 0,`total_debit` integer DEFAULT 0,`line_count` integer DEFAULT 0,`imported_by` integer NOT NULL,`created_at` datetime);
+CREATE INDEX `idx_bank_statements_account_number` ON `bank_statements`(`account_number`);
+CREATE INDEX `idx_bank_statements_imported_by` ON `bank_statements`(`imported_by`);
+CREATE INDEX `idx_bank_statements_period_end` ON `bank_statements`(`period_end`);
+CREATE INDEX `idx_bank_statements_period_start` ON `bank_statements`(`period_start`);
+CREATE TABLE `bank_statement_lines` (`id` integer PRIMARY KEY AUTOINCREMENT,`statement_id` integer NOT NULL,`account_number` text,`line_number` integer NOT NULL,`transaction_date` datetime NOT NULL,`description` text,`reference` text,`direction` text NOT NULL,`amount` integer NOT NULL,`balance` integer,`dedup_key` text NOT NULL,`match_status` text DEFAULT "unmatched",`match_type` text,`cash_flow_entry_id` integer,`supplier_payment_id` integer,`matched_by` integer,`matched_at` datetime,`created_at` datetime,CONSTRAINT `fk_bank_statement_lines_cash_flow_entry` FOREIGN KEY (`cash_flow_entry_id`) REFERENCES `cash_flow_entries`(`id`),CONSTRAINT `fk_bank_statement_lines_supplier_payment` FOREIGN KEY (`supplier_payment_id`) REFERENCES `supplier_payments`(`id`),CONSTRAINT `fk_bank_statements_lines` FOREIGN KEY (`statement_id`) REFERENCES `bank_statements`(`id`));
+CREATE INDEX `idx_bank_statement_lines_account_number` ON `bank_statement_lines`(`account_number`);
+CREATE INDEX `idx_bank_statement_lines_match_status` ON `bank_statement_lines`(`match_status`);
+CREATE INDEX `idx_bank_statement_lines_matched_by` ON `bank_statement_lines`(`matched_by`);
+CREATE INDEX `idx_bank_statement_lines_reference` ON `bank_statement_lines`(`reference`);
+CREATE INDEX `idx_bank_statement_lines_statement_id` ON `bank_statement_lines`(`statement_id`);
+CREATE INDEX `idx_bank_statement_lines_transaction_date` ON `bank_statement_lines`(`transaction_date`);
+CREATE UNIQUE INDEX `idx_bank_statement_lines_cash_flow_entry_id` ON `bank_statement_lines`(`cash_flow_entry_id`);
+CREATE UNIQUE INDEX `idx_bank_statement_lines_dedup_key` ON `bank_statement_lines`(`dedup_key`);
+CREATE UNIQUE INDEX `idx_bank_statement_lines_supplier_payment_id` ON `bank_statement_lines`(`supplier_payment_id`);
+CREATE TABLE `petty_cash_funds` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`custodian_id` integer NOT NULL,`float_amount` integer NOT NULL,`balance` integer NOT NULL,`is_active` numeric DEFAULT true,`created_by` integer NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_petty_cash_funds_custodian` FOREIGN KEY (`custodian_id`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_petty_cash_funds_created_by` ON `petty_cash_funds`(`created_by`);
+CREATE INDEX `idx_petty_cash_funds_custodian_id` ON `petty_cash_funds`(`custodian_id`);
+CREATE INDEX `idx_petty_cash_funds_is_active` ON `petty_cash_funds`(`is_active`);
+CREATE TABLE `petty_cash_expenses` (`id` integer PRIMARY KEY AUTOINCREMENT,`expense_number` text NOT NULL,`fund_id` integer NOT NULL,`expense_date` datetime NOT NULL,`category` text NOT NULL,`description` text NOT NULL,`amount` integer NOT NULL,`receipt_photo` text NOT NULL,`ingredient_id` integer,`quantity` real DEFAULT 0,`cash_flow_entry_id` integer,`inventory_movement_id` integer,`replenishment_id` integer,`created_by` integer NOT NULL,`created_at` datetime,CONSTRAINT `fk_petty_cash_expenses_ingredient` FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients`(`id`));
+CREATE INDEX `idx_petty_cash_expenses_cash_flow_entry_id` ON `petty_cash_expenses`(`cash_flow_entry_id`);
+CREATE INDEX `idx_petty_cash_expenses_category` ON `petty_cash_expenses`(`category`);
+CREATE INDEX `idx_petty_cash_expenses_created_by` ON `petty_cash_expenses`(`created_by`);
+CREATE INDEX `idx_petty_cash_expenses_expense_date` ON `petty_cash_expenses`(`expense_date`);
+CREATE INDEX `idx_petty_cash_expenses_fund_id` ON `petty_cash_expenses`(`fund_id`);
+CREATE INDEX `idx_petty_cash_expenses_ingredient_id` ON `petty_cash_expenses`(`ingredient_id`);
+CREATE INDEX `idx_petty_cash_expenses_inventory_movement_id` ON `petty_cash_expenses`(`inventory_movement_id`);
+CREATE INDEX `idx_petty_cash_expenses_replenishment_id` ON `petty_cash_expenses`(`replenishment_id`);
+CREATE UNIQUE INDEX `idx_petty_cash_expenses_expense_number` ON `petty_cash_expenses`(`expense_number`);
+CREATE TABLE `petty_cash_replenishments` (`id` integer PRIMARY KEY AUTOINCREMENT,`replenishment_number` text NOT NULL,`fund_id` integer NOT NULL,`amount` integer NOT NULL,`status` text NOT NULL,`notes` text,`requested_by` integer NOT NULL,`approved_by` integer,`approved_at` datetime,`rejection_reason` text,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_petty_cash_replenishments_fund` FOREIGN KEY (`fund_id`) REFERENCES `petty_cash_funds`(`id`));
+CREATE INDEX `idx_petty_cash_replenishments_approved_by` ON `petty_cash_replenishments`(`approved_by`);
+CREATE INDEX `idx_petty_cash_replenishments_fund_id` ON `petty_cash_replenishments`(`fund_id`);
+CREATE INDEX `idx_petty_cash_replenishments_requested_by` ON `petty_cash_replenishments`(`requested_by`);
+CREATE INDEX `idx_petty_cash_replenishments_status` ON `petty_cash_replenishments`(`status`);
+CREATE UNIQUE INDEX `idx_petty_cash_replenishments_replenishment_number` ON `petty_cash_replenishments`(`replenishment_number`);
+CREATE TABLE `system_configs` (`id` integer PRIMARY KEY AUTOINCREMENT,`key` text NOT NULL,`value` text NOT NULL,`data_type` text NOT NULL,`category` text,`updated_by` integer NOT NULL,`updated_at` datetime,CONSTRAINT `fk_system_configs_updater` FOREIGN KEY (`updated_by`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_system_configs_category` ON `system_configs`(`category`);
+CREATE INDEX `idx_system_configs_updated_by` ON `system_configs`(`updated_by`);
+CREATE UNIQUE INDEX `idx_system_configs_key` ON `system_configs`(`key`);
+CREATE TABLE `notifications` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`type` text NOT NULL,`title` text NOT NULL,`message` text NOT NULL,`is_read` numeric DEFAULT false,`link` text,`created_at` datetime,CONSTRAINT `fk_notifications_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_notifications_created_at` ON `notifications`(`created_at`);
+CREATE INDEX `idx_notifications_is_read` ON `notifications`(`is_read`);
+CREATE INDEX `idx_notifications_type` ON `notifications`(`type`);
+CREATE INDEX `idx_notifications_user_id` ON `notifications`(`user_id`);
+CREATE TABLE `outbox_events` (`id` integer PRIMARY KEY AUTOINCREMENT,`event_type` text NOT NULL,`aggregate_type` text,`aggregate_id` integer,`payload` text,`status` text NOT NULL DEFAULT "pending",`attempts` integer NOT NULL DEFAULT 0,`max_attempts` integer NOT NULL DEFAULT 8,`next_attempt_at` datetime NOT NULL,`locked_until` datetime,`last_error` text,`delivered_at` datetime,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_outbox_events_aggregate` ON `outbox_events`(`aggregate_type`,`aggregate_id`);
+CREATE INDEX `idx_outbox_events_created_at` ON `outbox_events`(`created_at`);
+CREATE INDEX `idx_outbox_events_due` ON `outbox_events`(`status`,`next_attempt_at`);
+CREATE INDEX `idx_outbox_events_event_type` ON `outbox_events`(`event_type`);
+CREATE TABLE `sync_operations` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`idempotency_key` text NOT NULL,`type` text NOT NULL,`status` text NOT NULL DEFAULT "pending",`client_timestamp` datetime NOT NULL,`result` text,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_sync_operations_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
+CREATE INDEX `idx_sync_operations_created_at` ON `sync_operations`(`created_at`);
+CREATE INDEX `idx_sync_operations_status` ON `sync_operations`(`status`);
+CREATE INDEX `idx_sync_operations_type` ON `sync_operations`(`type`);
+CREATE UNIQUE INDEX `idx_sync_operations_user_key` ON `sync_operations`(`user_id`,`idempotency_key`);
+CREATE TABLE `idempotency_keys` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`key` text NOT NULL,`fingerprint` text NOT NULL,`completed` numeric NOT NULL DEFAULT false,`status_code` integer,`content_type` text,`body` blob,`expires_at` datetime NOT NULL,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_idempotency_keys_expires_at` ON `idempotency_keys`(`expires_at`);
+CREATE UNIQUE INDEX `idx_idempotency_keys_user_key` ON `idempotency_keys`(`user_id`,`key`);
+CREATE TABLE `webhook_subscriptions` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`target_url` text NOT NULL,`event_types` text NOT NULL,`secret` text NOT NULL,`is_active` numeric NOT NULL DEFAULT true,`created_by` integer NOT NULL,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_webhook_subscriptions_is_active` ON `webhook_subscriptions`(`is_active`);
+CREATE TABLE `webhook_deliveries` (`id` integer PRIMARY KEY AUTOINCREMENT,`subscription_id` integer NOT NULL,`event_type` text NOT NULL,`payload` text NOT NULL,`status` text NOT NULL DEFAULT "pending",`attempts` integer NOT NULL DEFAULT 0,`response_status` integer,`response_body` text,`last_error` text,`last_attempt_at` datetime,`delivered_at` datetime,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_webhook_deliveries_subscription` FOREIGN KEY (`subscription_id`) REFERENCES `webhook_subscriptions`(`id`));
+CREATE INDEX `idx_webhook_deliveries_event_type` ON `webhook_deliveries`(`event_type`);
+CREATE INDEX `idx_webhook_deliveries_status` ON `webhook_deliveries`(`status`);
+CREATE INDEX `idx_webhook_deliveries_subscription` ON `webhook_deliveries`(`subscription_id`,`created_at`);
@@ -0,0 +1,42 @@
+-- Indexes that used to be created by hand in database.Migrate on every
+-- start; the same as on PostgreSQL. The baseline already creates the
+-- cascading foreign keys.
+
+-- Composite indexes for common query patterns
+CREATE INDEX IF NOT EXISTS idx_audit_trail_user_timestamp ON audit_trails(user_id, timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_trail_entity_action ON audit_trails(entity, action, timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_menu_item_date_plan ON menu_items(date, menu_plan_id);
+CREATE INDEX IF NOT EXISTS idx_menu_item_school_allocation_menu_item ON menu_item_school_allocations(menu_item_id);
+CREATE INDEX IF NOT EXISTS idx_menu_item_school_allocation_school ON menu_item_school_allocations(school_id);
+CREATE INDEX IF NOT EXISTS idx_menu_item_school_allocation_date ON menu_item_school_allocations(date);
+CREATE INDEX IF NOT EXISTS idx_delivery_task_date_driver ON delivery_tasks(task_date, driver_id);
+CREATE INDEX IF NOT EXISTS idx_delivery_task_status_date ON delivery_tasks(status, task_date DESC);
+CREATE INDEX IF NOT EXISTS idx_attendance_employee_date ON attendances(employee_id, date DESC);
+CREATE INDEX IF NOT EXISTS idx_cash_flow_date_category ON cash_flow_entries(date DESC, category);
+CREATE INDEX IF NOT EXISTS idx_cash_flow_type_date ON cash_flow_entries(type, date DESC);
+CREATE INDEX IF NOT EXISTS idx_inventory_movement_ingredient_date ON inventory_movements(ingredient_id, movement_date DESC);
+CREATE INDEX IF NOT EXISTS idx_inventory_movement_type_date ON inventory_movements(movement_type, movement_date DESC);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_status_date ON purchase_orders(status, order_date DESC);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_supplier_date ON purchase_orders(supplier_id, order_date DESC);
+CREATE INDEX IF NOT EXISTS idx_goods_receipt_po_date ON goods_receipts(po_id, receipt_date DESC);
+CREATE INDEX IF NOT EXISTS idx_ompreng_tracking_school_date ON ompreng_trackings(school_id, date DESC);
+CREATE INDEX IF NOT EXISTS idx_asset_maintenance_asset_date ON asset_maintenances(asset_id, maintenance_date DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_read ON notifications(user_id, is_read, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_semi_finished_movements_goods_id ON semi_finished_movements(semi_finished_goods_id);
+CREATE INDEX IF NOT EXISTS idx_semi_finished_movements_type ON semi_finished_movements(movement_type);
+CREATE INDEX IF NOT EXISTS idx_semi_finished_movements_date ON semi_finished_movements(movement_date);
+CREATE INDEX IF NOT EXISTS idx_semi_finished_movements_goods_date ON semi_finished_movements(semi_finished_goods_id, movement_date DESC);
+
+-- Partial indexes for filtered queries
+CREATE INDEX IF NOT EXISTS idx_suppliers_active ON suppliers(name) WHERE is_active = true;
+CREATE INDEX IF NOT EXISTS idx_recipes_active ON recipes(name, category) WHERE is_active = true;
+CREATE INDEX IF NOT EXISTS idx_schools_active ON schools(name) WHERE is_active = true;
+CREATE INDEX IF NOT EXISTS idx_delivery_tasks_pending ON delivery_tasks(task_date, driver_id) WHERE status IN ('pending', 'in_progress');
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id, created_at DESC) WHERE is_read = false;
+
+-- One allocation per school and portion size (SD schools have small and large);
+-- older databases have this index without portion_size
+DROP INDEX IF EXISTS idx_menu_item_school_allocation_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_menu_item_school_allocation_unique ON menu_item_school_allocations(menu_item_id, school_id, portion_size);
+-- One line per ingredient in a stok opname form
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stok_opname_items_form_ingredient ON stok_opname_items(form_id, ingredient_id);
@@ -0,0 +1,31 @@
+import { useAuthStore } from '@/stores/auth'
+
+// Uploaded files are stored as references such as /api/v1/files/recipes/x.jpg
+// and are only served through short-lived signed URLs. fileUrl turns a
+// reference into a URL usable in img src: the backend checks the access
+// token and redirects to a freshly signed URL.
+
+const apiBaseUrl = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080/api/v1'
+// /uploads/ references were saved before the blob store and still resolve
+const STORED_PREFIXES = ['/api/v1/files/', '/uploads/']
+
+/**
+ * URL to display a stored file; other URLs are returned unchanged
+ * @param {string} value stored reference or URL
+ * @returns {string}
+ */
+export const fileUrl = (value) => {
+  if (!value || value.startsWith('data:') || value.startsWith('blob:')) return value || ''
+  const path = /^https?:\/\//.test(value) ? new URL(value).pathname : value
+  if (!STORED_PREFIXES.some((prefix) => path.startsWith(prefix))) return value
+
+  const authStore = useAuthStore()
+  const url = new URL(`${apiBaseUrl}/storage/open`, window.location.href)
+  url.searchParams.set('ref', path)
+  url.searchParams.set('access_token', authStore.token || '')
+  return url.toString()
+}
+
+export default {
+  fileUrl
+}
@@ -0,0 +1,53 @@
+import api from './api'
+
+const accountsPayableService = {
+  // Get supplier invoices, optionally filtered by supplier_id and status
+  async getInvoices(params = {}) {
+    const response = await api.get('/accounts-payable/invoices', { params })
+    return response.data
+  },
+
+  // Get single invoice with three-way match result and payments
+  async getInvoice(id) {
+    const response = await api.get(`/accounts-payable/invoices/${id}`)
+    return response.data
+  },
+
+  // Record supplier invoice for a goods receipt
+  async createInvoice(invoiceData) {
+    const response = await api.post('/accounts-payable/invoices', invoiceData)
+    return response.data
+  },
+
+  // Release an invoice held by the three-way match
+  async approveInvoice(id, reason) {
+    const response = await api.post(`/accounts-payable/invoices/${id}/approve`, { reason })
+    return response.data
+  },
+
+  // Cancel an unpaid invoice
+  async cancelInvoice(id, reason = '') {
+    const response = await api.post(`/accounts-payable/invoices/${id}/cancel`, { reason })
+    return response.data
+  },
+
+  // Record full or partial payment, transfers require bank_reference
+  async recordPayment(id, paymentData) {
+    const response = await api.post(`/accounts-payable/invoices/${id}/payments`, paymentData)
+    return response.data
+  },
+
+  // Get supplier payments with optional supplier and date filters
+  async getPayments(params = {}) {
+    const response = await api.get('/accounts-payable/payments', { params })
+    return response.data
+  },
+
+  // Get accounts payable aging per supplier at a date
+  async getAgingReport(asOf) {
+    const response = await api.get('/accounts-payable/aging', { params: { as_of: asOf } })
+    return response.data
+  }
+}
+
+export default accountsPayableService
@@ -0,0 +1,53 @@
+import api from './api'
+
+const assetAuditService = {
+  // Get asset audits, optionally filtered by status
+  async getAudits(params = {}) {
+    const response = await api.get('/asset-audits', { params })
+    return response.data
+  },
+
+  // Start audit of a location, or of all active assets when location is empty
+  async startAudit(data) {
+    const response = await api.post('/asset-audits', data)
+    return response.data
+  },
+
+  // Get single audit with its items
+  async getAudit(id) {
+    const response = await api.get(`/asset-audits/${id}`)
+    return response.data
+  },
+
+  // Get found, moved and missing assets of an audit
+  async getAuditReport(id) {
+    const response = await api.get(`/asset-audits/${id}/report`)
+    return response.data
+  },
+
+  // Record scanned QR label with asset_code, location, condition and notes
+  async scanAsset(id, data) {
+    const response = await api.post(`/asset-audits/${id}/scan`, data)
+    return response.data
+  },
+
+  // Submit audit for approval; unscanned assets are reported missing
+  async submitAudit(id) {
+    const response = await api.post(`/asset-audits/${id}/submit`)
+    return response.data
+  },
+
+  // Approve audit and apply found locations and conditions (Kepala SPPG)
+  async approveAudit(id) {
+    const response = await api.post(`/asset-audits/${id}/approve`)
+    return response.data
+  },
+
+  // Reject audit with reason (Kepala SPPG)
+  async rejectAudit(id, reason) {
+    const response = await api.post(`/asset-audits/${id}/reject`, { reason })
+    return response.data
+  }
+}
+
+export default assetAuditService
@@ -51,6 +51,48 @@ const assetService = {
     return response.data
   },
 
+  // Dispose or sell asset, recording the gain or loss against its book value
+  async disposeAsset(assetId, disposalData) {
+    const response = await api.post(`/assets/${assetId}/dispose`, disposalData)
+    return response.data
+  },
+
+  // Get usage records (units-of-production depreciation)
+  async getAssetUsage(assetId) {
+    const response = await api.get(`/assets/${assetId}/usage`)
+    return response.data
+  },
+
+  // Record asset usage for a period
+  async recordAssetUsage(assetId, usageData) {
+    const response = await api.post(`/assets/${assetId}/usage`, usageData)
+    return response.data
+  },
+
+  // Get monthly depreciation runs, optionally filtered by year
+  async getDepreciationRuns(params = {}) {
+    const response = await api.get('/assets/depreciation-runs', { params })
+    return response.data
+  },
+
+  // Get single depreciation run with its asset lines
+  async getDepreciationRun(id) {
+    const response = await api.get(`/assets/depreciation-runs/${id}`)
+    return response.data
+  },
+
+  // Post depreciation of all active assets for a month
+  async runDepreciation(year, month) {
+    const response = await api.post('/assets/depreciation-runs', { year, month })
+    return response.data
+  },
+
+  // Reverse the latest depreciation run
+  async reverseDepreciationRun(id, reason = '') {
+    const response = await api.post(`/assets/depreciation-runs/${id}/reverse`, { reason })
+    return response.data
+  },
+
   // Export asset report
   async exportAssetReport(format = 'excel') {
     const response = await api.post('/financial-reports/export', {
@@ -62,6 +104,15 @@ const assetService = {
       responseType: 'blob'
     })
     return response
+  },
+
+  // Download PDF sheet of QR labels; params: ids (comma separated), category, location
+  async getAssetLabels(params = {}) {
+    const response = await api.get('/assets/labels', {
+      params,
+      responseType: 'blob'
+    })
+    return response
   }
 }
 
@@ -14,6 +14,20 @@ const authService = {
     return response.data
   },
 
+  /**
+   * Complete a login with the TOTP or recovery code
+   * @param {string} challengeToken - challenge_token returned by login
+   * @param {string} code - 6 digit TOTP code or recovery code
+   * @returns {Promise<Object>} - { user, token, refresh_token }
+   */
+  async verifyTwoFactor(challengeToken, code) {
+    const response = await api.post('/auth/login/2fa', {
+      challenge_token: challengeToken,
+      code
+    })
+    return response.data
+  },
+
   /**
    * Logout current user
    * @returns {Promise<void>}
@@ -27,11 +41,12 @@ const authService = {
   },
 
   /**
-   * Refresh JWT token
-   * @returns {Promise<Object>} - { user, token }
+   * Exchange a refresh token for a new token pair
+   * @param {string} refreshToken - single-use refresh token from login or the previous refresh
+   * @returns {Promise<Object>} - { token, refresh_token, expires_in, session_id }
    */
-  async refreshToken() {
-    const response = await api.post('/auth/refresh')
+  async refreshToken(refreshToken) {
+    const response = await api.post('/auth/refresh', { refresh_token: refreshToken })
     return response.data
   },
 
@@ -42,6 +57,15 @@ const authService = {
   async getCurrentUser() {
     const response = await api.get('/auth/me')
     return response.data
+  },
+
+  /**
+   * Get the effective permissions of the current user (role grants plus overrides)
+   * @returns {Promise<Object>} - { data: { role, permissions, statuses } }
+   */
+  async getPermissions() {
+    const response = await api.get('/auth/permissions')
+    return response.data
   }
 }
 
@@ -0,0 +1,72 @@
+import api from './api'
+
+const bankReconciliationService = {
+  // Get imported bank statements, optionally filtered by account_number
+  async getStatements(params = {}) {
+    const response = await api.get('/bank-reconciliation/statements', { params })
+    return response.data
+  },
+
+  // Import bank statement file (CSV/XLSX export or MT940), lines are auto-matched
+  async importStatement(file, options = {}) {
+    const formData = new FormData()
+    formData.append('file', file)
+    formData.append('format', options.format || 'auto')
+    if (options.bankName) formData.append('bank_name', options.bankName)
+    if (options.accountNumber) formData.append('account_number', options.accountNumber)
+    const response = await api.post('/bank-reconciliation/statements/import', formData, {
+      headers: { 'Content-Type': 'multipart/form-data' }
+    })
+    return response.data
+  },
+
+  // Get single statement with its lines and matched book items
+  async getStatement(id) {
+    const response = await api.get(`/bank-reconciliation/statements/${id}`)
+    return response.data
+  },
+
+  // Delete statement, releasing its matches
+  async deleteStatement(id) {
+    const response = await api.delete(`/bank-reconciliation/statements/${id}`)
+    return response.data
+  },
+
+  // Re-run automatic matching of a statement
+  async autoMatch(id) {
+    const response = await api.post(`/bank-reconciliation/statements/${id}/auto-match`)
+    return response.data
+  },
+
+  // Get bank lines filtered by statement_id, account_number, status, start_date, end_date
+  async getLines(params = {}) {
+    const response = await api.get('/bank-reconciliation/lines', { params })
+    return response.data
+  },
+
+  // Get book items that can be matched with a bank line
+  async getMatchCandidates(lineId) {
+    const response = await api.get(`/bank-reconciliation/lines/${lineId}/candidates`)
+    return response.data
+  },
+
+  // Match bank line with a cash_flow entry or supplier_payment
+  async matchLine(lineId, bookType, bookId) {
+    const response = await api.post(`/bank-reconciliation/lines/${lineId}/match`, { book_type: bookType, book_id: bookId })
+    return response.data
+  },
+
+  // Release the match of a bank line
+  async unmatchLine(lineId) {
+    const response = await api.post(`/bank-reconciliation/lines/${lineId}/unmatch`)
+    return response.data
+  },
+
+  // Get monthly reconciliation report with unmatched items
+  async getReport(params = {}) {
+    const response = await api.get('/bank-reconciliation/report', { params })
+    return response.data
+  }
+}
+
+export default bankReconciliationService
@@ -0,0 +1,54 @@
+import api from './api'
+
+const budgetService = {
+  // Get budget targets with live realisation, optionally filtered by month and category
+  async getBudgetTargets(params = {}) {
+    const response = await api.get('/budget-targets', { params })
+    return response.data
+  },
+
+  // Get budget totals of a period (month 0 for the yearly budget)
+  async getBudgetSummary(year, month) {
+    const response = await api.get('/budget-targets/summary', {
+      params: { year, month }
+    })
+    return response.data
+  },
+
+  // Get single budget target
+  async getBudgetTarget(id) {
+    const response = await api.get(`/budget-targets/${id}`)
+    return response.data
+  },
+
+  // Create budget target
+  async createBudgetTarget(targetData) {
+    const response = await api.post('/budget-targets', targetData)
+    return response.data
+  },
+
+  // Update budget target amount and notes
+  async updateBudgetTarget(id, targetData) {
+    const response = await api.put(`/budget-targets/${id}`, targetData)
+    return response.data
+  },
+
+  // Delete budget target
+  async deleteBudgetTarget(id) {
+    const response = await api.delete(`/budget-targets/${id}`)
+    return response.data
+  },
+
+  // Import budget targets from XLSX/CSV, use dryRun to validate only
+  async importBudgetTargets(file, dryRun = false) {
+    const formData = new FormData()
+    formData.append('file', file)
+    const response = await api.post('/budget-targets/import', formData, {
+      params: { dry_run: dryRun },
+      headers: { 'Content-Type': 'multipart/form-data' }
+    })
+    return response.data
+  }
+}
+
+export default budgetService
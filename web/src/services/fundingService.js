@@ -0,0 +1,62 @@
+import api from './api'
+
+const fundingService = {
+  // Get funding tranches, optionally filtered by year
+  async getTranches(params = {}) {
+    const response = await api.get('/funding-tranches', { params })
+    return response.data
+  },
+
+  // Get single tranche with its target schools
+  async getTranche(id) {
+    const response = await api.get(`/funding-tranches/${id}`)
+    return response.data
+  },
+
+  // Record received tranche, also books the income entry
+  async createTranche(trancheData) {
+    const response = await api.post('/funding-tranches', trancheData)
+    return response.data
+  },
+
+  // Update tranche amount, period or target schools
+  async updateTranche(id, trancheData) {
+    const response = await api.put(`/funding-tranches/${id}`, trancheData)
+    return response.data
+  },
+
+  // Delete tranche and its income entry
+  async deleteTranche(id) {
+    const response = await api.delete(`/funding-tranches/${id}`)
+    return response.data
+  },
+
+  // Account cash flow expenses to a tranche
+  async assignExpenses(id, entryIds) {
+    const response = await api.post(`/funding-tranches/${id}/expenses`, { entry_ids: entryIds })
+    return response.data
+  },
+
+  // Remove an expense from a tranche
+  async unassignExpense(id, entryId) {
+    const response = await api.delete(`/funding-tranches/${id}/expenses/${entryId}`)
+    return response.data
+  },
+
+  // Get LPJ (laporan pertanggungjawaban) of a tranche
+  async getLPJ(id) {
+    const response = await api.get(`/funding-tranches/${id}/lpj`)
+    return response.data
+  },
+
+  // Download LPJ as pdf or excel
+  async exportLPJ(id, format = 'pdf') {
+    const response = await api.get(`/funding-tranches/${id}/lpj/export`, {
+      params: { format },
+      responseType: 'blob'
+    })
+    return response.data
+  }
+}
+
+export default fundingService
@@ -0,0 +1,81 @@
+import api from './api'
+
+const ledgerService = {
+  // Get chart of accounts, optionally filtered by type
+  async getAccounts(params = {}) {
+    const response = await api.get('/ledger/accounts', { params })
+    return response.data
+  },
+
+  // Create account
+  async createAccount(accountData) {
+    const response = await api.post('/ledger/accounts', accountData)
+    return response.data
+  },
+
+  // Update account name, category, description or active flag
+  async updateAccount(id, accountData) {
+    const response = await api.put(`/ledger/accounts/${id}`, accountData)
+    return response.data
+  },
+
+  // Get general ledger of an account for a date range
+  async getGeneralLedger(accountId, startDate, endDate) {
+    const response = await api.get(`/ledger/accounts/${accountId}/ledger`, {
+      params: { start_date: startDate, end_date: endDate }
+    })
+    return response.data
+  },
+
+  // Get journal entries with optional filters
+  async getJournalEntries(params = {}) {
+    const response = await api.get('/ledger/journals', { params })
+    return response.data
+  },
+
+  // Get single journal entry with lines
+  async getJournalEntry(id) {
+    const response = await api.get(`/ledger/journals/${id}`)
+    return response.data
+  },
+
+  // Post manual journal entry, lines must balance
+  async createJournalEntry(entryData) {
+    const response = await api.post('/ledger/journals', entryData)
+    return response.data
+  },
+
+  // Cancel journal entry with a reversing entry
+  async reverseJournalEntry(id, reason = '') {
+    const response = await api.post(`/ledger/journals/${id}/reverse`, { reason })
+    return response.data
+  },
+
+  // Get trial balance at a date
+  async getTrialBalance(asOf) {
+    const response = await api.get('/ledger/trial-balance', { params: { as_of: asOf } })
+    return response.data
+  },
+
+  // Get balance sheet at a date
+  async getBalanceSheet(asOf) {
+    const response = await api.get('/ledger/balance-sheet', { params: { as_of: asOf } })
+    return response.data
+  },
+
+  // Get income statement for a date range
+  async getIncomeStatement(startDate, endDate) {
+    const response = await api.get('/ledger/income-statement', {
+      params: { start_date: startDate, end_date: endDate }
+    })
+    return response.data
+  },
+
+  // Post monthly depreciation of all assets
+  async postDepreciation(year, month) {
+    const response = await api.post('/ledger/depreciation', { year, month })
+    return response.data
+  }
+}
+
+export default ledgerService
@@ -0,0 +1,53 @@
+import api from './api'
+
+const maintenanceService = {
+  // Get maintenance plans, optionally filtered by asset_id or responsible_user_id
+  async getPlans(params = {}) {
+    const response = await api.get('/maintenance/plans', { params })
+    return response.data
+  },
+
+  // Create plan with interval_type days (interval_days) or usage (interval_units)
+  async createPlan(data) {
+    const response = await api.post('/maintenance/plans', data)
+    return response.data
+  },
+
+  // Get single plan
+  async getPlan(id) {
+    const response = await api.get(`/maintenance/plans/${id}`)
+    return response.data
+  },
+
+  // Update plan interval, responsible user or active flag
+  async updatePlan(id, data) {
+    const response = await api.put(`/maintenance/plans/${id}`, data)
+    return response.data
+  },
+
+  // Get maintenance records completing a plan
+  async getPlanHistory(id) {
+    const response = await api.get(`/maintenance/plans/${id}/history`)
+    return response.data
+  },
+
+  // Complete the current task with date, cost, performed_by and description
+  async completeTask(id, data) {
+    const response = await api.post(`/maintenance/plans/${id}/complete`, data)
+    return response.data
+  },
+
+  // Get due, overdue and upcoming tasks; params: start_date, end_date, asset_id, mine
+  async getCalendar(params = {}) {
+    const response = await api.get('/maintenance/calendar', { params })
+    return response.data
+  },
+
+  // Get assets in poor condition or overdue for safety-critical maintenance
+  async getAlerts() {
+    const response = await api.get('/maintenance/alerts')
+    return response.data
+  }
+}
+
+export default maintenanceService
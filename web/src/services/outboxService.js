@@ -0,0 +1,29 @@
+import api from './api'
+
+const outboxService = {
+  // Get outbox events, optionally filtered by status (pending, processing, delivered, dead) or event_type
+  async getEvents(params = {}) {
+    const response = await api.get('/system/outbox', { params })
+    return response.data
+  },
+
+  // Get event counts per status
+  async getStats() {
+    const response = await api.get('/system/outbox/stats')
+    return response.data
+  },
+
+  // Put a dead or pending event back in the queue
+  async replayEvent(id) {
+    const response = await api.post(`/system/outbox/${id}/replay`)
+    return response.data
+  },
+
+  // Put every dead-lettered event back in the queue
+  async replayDeadEvents() {
+    const response = await api.post('/system/outbox/replay-dead')
+    return response.data
+  }
+}
+
+export default outboxService
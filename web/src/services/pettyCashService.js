@@ -0,0 +1,83 @@
+import api from './api'
+
+const pettyCashService = {
+  // Get petty cash funds, optionally filtered by custodian_id
+  async getFunds(params = {}) {
+    const response = await api.get('/petty-cash/funds', { params })
+    return response.data
+  },
+
+  // Create petty cash fund with custodian and float amount
+  async createFund(data) {
+    const response = await api.post('/petty-cash/funds', data)
+    return response.data
+  },
+
+  // Get single fund
+  async getFund(id) {
+    const response = await api.get(`/petty-cash/funds/${id}`)
+    return response.data
+  },
+
+  // Update fund name, custodian, float or active flag
+  async updateFund(id, data) {
+    const response = await api.put(`/petty-cash/funds/${id}`, data)
+    return response.data
+  },
+
+  // Get fund expenses, optionally filtered by start_date and end_date
+  async getExpenses(fundId, params = {}) {
+    const response = await api.get(`/petty-cash/funds/${fundId}/expenses`, { params })
+    return response.data
+  },
+
+  // Record expense with receipt photo; food purchases carry ingredient_id and quantity
+  async createExpense(fundId, data, receiptPhoto) {
+    const formData = new FormData()
+    formData.append('receipt_photo', receiptPhoto)
+    formData.append('category', data.category || '')
+    formData.append('description', data.description)
+    formData.append('amount', data.amount)
+    if (data.expense_date) formData.append('expense_date', data.expense_date)
+    if (data.ingredient_id) {
+      formData.append('ingredient_id', data.ingredient_id)
+      formData.append('quantity', data.quantity)
+    }
+    const response = await api.post(`/petty-cash/funds/${fundId}/expenses`, formData, {
+      headers: { 'Content-Type': 'multipart/form-data' }
+    })
+    return response.data
+  },
+
+  // Request replenishment for all expenses not yet replenished
+  async requestReplenishment(fundId, notes = '') {
+    const response = await api.post(`/petty-cash/funds/${fundId}/replenishments`, { notes })
+    return response.data
+  },
+
+  // Get replenishment requests, optionally filtered by fund_id and status
+  async getReplenishments(params = {}) {
+    const response = await api.get('/petty-cash/replenishments', { params })
+    return response.data
+  },
+
+  // Get single replenishment with its expenses
+  async getReplenishment(id) {
+    const response = await api.get(`/petty-cash/replenishments/${id}`)
+    return response.data
+  },
+
+  // Approve replenishment (kepala SPPG)
+  async approveReplenishment(id) {
+    const response = await api.post(`/petty-cash/replenishments/${id}/approve`)
+    return response.data
+  },
+
+  // Reject replenishment with reason (kepala SPPG)
+  async rejectReplenishment(id, reason) {
+    const response = await api.post(`/petty-cash/replenishments/${id}/reject`, { reason })
+    return response.data
+  }
+}
+
+export default pettyCashService
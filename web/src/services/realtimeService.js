@@ -0,0 +1,125 @@
+import { useAuthStore } from '@/stores/auth'
+
+// Client for the built-in realtime hub of the backend (REALTIME_BACKEND=hub).
+// subscribeTopic keeps the current value of a topic and calls the callback
+// with it after every change, like onValue does for Firebase.
+
+const apiBaseUrl = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080/api/v1'
+const RECONNECT_DELAY = 3000
+
+let socket = null
+let reconnectTimer = null
+const listeners = new Map() // topic -> { value, callbacks: Set }
+
+const socketUrl = () => {
+  const authStore = useAuthStore()
+  const url = new URL(`${apiBaseUrl}/realtime/ws`, window.location.href)
+  url.protocol = url.protocol === 'https:' ? 'wss:' : 'ws:'
+  url.searchParams.set('access_token', authStore.token || '')
+  return url.toString()
+}
+
+const splitPath = (path) => (path || '').split('/').filter(Boolean)
+
+// applyChange returns the new value of a topic after a change at path
+const applyChange = (value, topic, event) => {
+  if (event.type === 'snapshot') {
+    return event.data ?? null
+  }
+
+  const relative = splitPath(event.path).slice(splitPath(topic).length)
+  if (relative.length === 0) {
+    if (event.type === 'update') {
+      return { ...(value || {}), ...event.data }
+    }
+    return event.type === 'delete' ? null : event.data
+  }
+
+  const root = { ...(value || {}) }
+  let node = root
+  for (const segment of relative.slice(0, -1)) {
+    node[segment] = { ...(node[segment] || {}) }
+    node = node[segment]
+  }
+  const last = relative[relative.length - 1]
+  if (event.type === 'delete') {
+    delete node[last]
+  } else if (event.type === 'update') {
+    node[last] = { ...(node[last] || {}), ...event.data }
+  } else {
+    node[last] = event.data
+  }
+  return root
+}
+
+const send = (message) => {
+  if (socket && socket.readyState === WebSocket.OPEN) {
+    socket.send(JSON.stringify(message))
+  }
+}
+
+const connect = () => {
+  if (socket) return
+
+  socket = new WebSocket(socketUrl())
+  socket.onopen = () => {
+    listeners.forEach((_, topic) => send({ action: 'subscribe', topic }))
+  }
+  socket.onmessage = (message) => {
+    const event = JSON.parse(message.data)
+    const listener = listeners.get(event.topic)
+    if (!listener) return
+    if (event.type === 'error') {
+      console.error(`Realtime: ${event.data?.message || 'kesalahan'} (${event.topic})`)
+      return
+    }
+    listener.value = applyChange(listener.value, event.topic, event)
+    listener.callbacks.forEach((callback) => callback(listener.value))
+  }
+  socket.onclose = () => {
+    socket = null
+    if (listeners.size > 0 && !reconnectTimer) {
+      // The hub sends a fresh snapshot of every topic after reconnecting
+      reconnectTimer = setTimeout(() => {
+        reconnectTimer = null
+        connect()
+      }, RECONNECT_DELAY)
+    }
+  }
+}
+
+/**
+ * Subscribe to a realtime topic such as kds/cooking/2026-03-02 or notifications/7
+ * @param {string} topic
+ * @param {(value: any) => void} callback called with the current value of the topic
+ * @returns {() => void} unsubscribe function
+ */
+export const subscribeTopic = (topic, callback) => {
+  const key = splitPath(topic).join('/')
+  let listener = listeners.get(key)
+  if (!listener) {
+    listener = { value: null, callbacks: new Set() }
+    listeners.set(key, listener)
+    send({ action: 'subscribe', topic: key })
+  }
+  listener.callbacks.add(callback)
+  connect()
+
+  return () => {
+    listener.callbacks.delete(callback)
+    if (listener.callbacks.size === 0) {
+      listeners.delete(key)
+      send({ action: 'unsubscribe', topic: key })
+    }
+    if (listeners.size === 0 && socket) {
+      socket.close()
+    }
+  }
+}
+
+export const isHubBackend = () => import.meta.env.VITE_REALTIME_BACKEND === 'hub'
+
+export default {
+  subscribeTopic,
+  isHubBackend
+}
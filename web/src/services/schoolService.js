@@ -34,6 +34,20 @@ const schoolService = {
         active_only: activeOnly 
       } 
     })
+  },
+
+  // Upload a cooperation letter; the response carries its file_url
+  uploadCooperationLetter(file) {
+    const formData = new FormData()
+    formData.append('file', file)
+    return api.post('/schools/upload-cooperation-letter', formData, {
+      headers: { 'Content-Type': 'multipart/form-data' }
+    })
+  },
+
+  // Delete an uploaded cooperation letter
+  deleteCooperationLetter(fileUrl) {
+    return api.delete('/schools/delete-cooperation-letter', { params: { file_url: fileUrl } })
   }
 }
 
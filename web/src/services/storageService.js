@@ -0,0 +1,56 @@
+import api from './api'
+import { useAuthStore } from '@/stores/auth'
+
+// Uploaded files are stored as references such as /api/v1/files/epod/x.jpg
+// and are only served through short-lived signed URLs. fileUrl turns a
+// reference into a URL usable in img src and links: the backend checks the
+// access token and redirects to a freshly signed URL.
+
+const apiBaseUrl = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080/api/v1'
+// /uploads/ references were saved before the blob store and still resolve
+const STORED_PREFIXES = ['/api/v1/files/', '/uploads/']
+
+/**
+ * Return the stored reference of a file URL, or null for external URLs
+ * @param {string} value reference or absolute URL saved by older versions
+ */
+const storedRef = (value) => {
+  if (!value || value.startsWith('data:') || value.startsWith('blob:')) return null
+  let path = value
+  if (/^https?:\/\//.test(value)) {
+    path = new URL(value).pathname
+  }
+  return STORED_PREFIXES.some((prefix) => path.startsWith(prefix)) ? path : null
+}
+
+/**
+ * URL to display or download a stored file; other URLs are returned unchanged
+ * @param {string} value
+ * @returns {string}
+ */
+export const fileUrl = (value) => {
+  const ref = storedRef(value)
+  if (!ref) return value || ''
+
+  const authStore = useAuthStore()
+  const url = new URL(`${apiBaseUrl}/storage/open`, window.location.href)
+  url.searchParams.set('ref', ref)
+  url.searchParams.set('access_token', authStore.token || '')
+  return url.toString()
+}
+
+/**
+ * Signed URLs of a stored file and its thumbnail, valid for a few minutes
+ * @returns {Promise<{success: boolean, data: {url: string, thumbnail_url?: string, expires_at: string}}>}
+ */
+export const signFile = async (ref, thumbnailRef) => {
+  const response = await api.get('/storage/sign', {
+    params: { ref: storedRef(ref) || ref, thumbnail_ref: thumbnailRef ? storedRef(thumbnailRef) || thumbnailRef : undefined }
+  })
+  return response.data
+}
+
+export default {
+  fileUrl,
+  signFile
+}
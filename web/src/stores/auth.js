@@ -5,6 +5,8 @@ import authService from '@/services/authService'
 export const useAuthStore = defineStore('auth', () => {
   const user = ref(null)
   const token = ref(localStorage.getItem('token') || null)
+  const refreshTokenValue = ref(localStorage.getItem('refresh_token') || null)
+  const permissions = ref(JSON.parse(localStorage.getItem('permissions') || '[]'))
   const loading = ref(false)
   const error = ref(null)
 
@@ -27,7 +29,10 @@ export const useAuthStore = defineStore('auth', () => {
     error.value = null
     try {
       const response = await authService.login(credentials)
-      setAuth(response.user, response.token)
+      // Accounts with 2FA get a challenge instead of tokens
+      if (!response.two_factor_required) {
+        setAuth(response.user, response.token, response.refresh_token)
+      }
       return response
     } catch (err) {
       error.value = err.response?.data?.message || 'Login gagal. Silakan coba lagi.'
@@ -37,6 +42,21 @@ export const useAuthStore = defineStore('auth', () => {
     }
   }
 
+  const verifyTwoFactor = async (challengeToken, code) => {
+    loading.value = true
+    error.value = null
+    try {
+      const response = await authService.verifyTwoFactor(challengeToken, code)
+      setAuth(response.user, response.token, response.refresh_token)
+      return response
+    } catch (err) {
+      error.value = err.response?.data?.message || 'Kode verifikasi salah.'
+      throw err
+    } finally {
+      loading.value = false
+    }
+  }
+
   const logout = async () => {
     loading.value = true
     try {
@@ -51,8 +71,8 @@ export const useAuthStore = defineStore('auth', () => {
 
   const refreshToken = async () => {
     try {
-      const response = await authService.refreshToken()
-      setAuth(response.user, response.token)
+      const response = await authService.refreshToken(refreshTokenValue.value)
+      setAuth(user.value, response.token, response.refresh_token)
       return response
     } catch (err) {
       clearAuth()
@@ -72,18 +92,36 @@ export const useAuthStore = defineStore('auth', () => {
     }
   }
 
-  function setAuth(userData, authToken) {
+  const loadPermissions = async () => {
+    const response = await authService.getPermissions()
+    permissions.value = response.data?.permissions || []
+    localStorage.setItem('permissions', JSON.stringify(permissions.value))
+    return permissions.value
+  }
+
+  // can checks a permission code such as 'inventory' or 'status.sedang_dimasak'
+  const can = (code) => permissions.value.includes(code)
+
+  function setAuth(userData, authToken, newRefreshToken) {
     user.value = userData
     token.value = authToken
     localStorage.setItem('token', authToken)
     localStorage.setItem('user', JSON.stringify(userData))
+    if (newRefreshToken) {
+      refreshTokenValue.value = newRefreshToken
+      localStorage.setItem('refresh_token', newRefreshToken)
+    }
   }
 
   function clearAuth() {
     user.value = null
     token.value = null
+    refreshTokenValue.value = null
+    permissions.value = []
     localStorage.removeItem('token')
+    localStorage.removeItem('refresh_token')
     localStorage.removeItem('user')
+    localStorage.removeItem('permissions')
   }
 
   // Initialize on store creation
@@ -92,13 +130,17 @@ export const useAuthStore = defineStore('auth', () => {
   return {
     user,
     token,
+    permissions,
     loading,
     error,
     isAuthenticated,
     login,
+    verifyTwoFactor,
     logout,
     refreshToken,
     getCurrentUser,
+    loadPermissions,
+    can,
     setAuth,
     clearAuth
   }